@@ -0,0 +1,245 @@
+package beaconlight
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/libp2p/go-libp2p-core/helpers"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
+)
+
+// Constants of the epoch transition requests. A stream carries one request
+// and the epoch transitions of the reply, both RLP encoded. The peer resets
+// the stream when it cannot serve the request.
+const (
+	Protocol          = protocol.ID("/harmony/beaconlight/1.0.0")
+	MaxTransitions    = 16
+	maxMessageSize    = 4 * 1024 * 1024
+	requestTimeout    = 20 * time.Second
+	requestInterval   = 30 * time.Second
+	requestPeers      = 3
+	maxRequestsPerRun = 64
+)
+
+var requestsCounter = metrics.DefaultRegistry.NewCounter(
+	"beacon_light_requests_total", "Requests of beacon chain epoch transitions to the beacon peers by result", "result",
+)
+
+// Request asks a peer for the epoch transitions of the beacon chain, from
+// the transition of the epoch Epoch
+type Request struct {
+	Epoch uint64
+	Count uint64
+}
+
+// Host is the p2p host the epoch transitions are requested and served with
+type Host interface {
+	GetP2PHost() libp2p_host.Host
+	PeerHandshake(id libp2p_peer.ID) (p2p.Handshake, bool)
+}
+
+// Service is the embedded beacon light client of the shard nodes, which
+// requests the epoch transitions of the beacon chain to the beacon peers,
+// and the server of these requests on the beacon chain nodes.
+type Service struct {
+	host        Host
+	next        func() uint64
+	serve       func(Request) ([]*core.EpochTransition, error)
+	deliver     func([]*core.EpochTransition) error
+	stopChan    chan struct{}
+	stoppedChan chan struct{}
+	messageChan chan *msg_pb.Message
+}
+
+// New returns a beacon light client service. The epoch transitions from the
+// epoch returned by next are requested when next is not nil, and handed to
+// deliver. The requests of the peers are served when serve is not nil.
+func New(
+	host Host,
+	next func() uint64,
+	serve func(Request) ([]*core.EpochTransition, error),
+	deliver func([]*core.EpochTransition) error,
+) *Service {
+	return &Service{
+		host:    host,
+		next:    next,
+		serve:   serve,
+		deliver: deliver,
+	}
+}
+
+// StartService starts the beacon light client service.
+func (s *Service) StartService() {
+	utils.Logger().Info().Msg("Starting beacon light client service.")
+	s.stopChan = make(chan struct{})
+	s.stoppedChan = make(chan struct{})
+	if s.serve != nil {
+		s.host.GetP2PHost().SetStreamHandler(Protocol, s.handleStream)
+	}
+	if s.next == nil {
+		close(s.stoppedChan)
+		return
+	}
+	go s.Run()
+}
+
+// Run requests the epoch transitions at every interval until the service
+// is stopped.
+func (s *Service) Run() {
+	defer close(s.stoppedChan)
+	ticker := time.NewTicker(requestInterval)
+	defer ticker.Stop()
+	for {
+		// full replies are followed by the next request right away
+		for i := 0; i < maxRequestsPerRun; i++ {
+			if s.requestTransitions() < MaxTransitions {
+				break
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// beaconPeers returns the connected peers serving the beacon chain
+func (s *Service) beaconPeers() []libp2p_peer.ID {
+	peers := []libp2p_peer.ID{}
+	for _, id := range s.host.GetP2PHost().Network().Peers() {
+		if handshake, ok := s.host.PeerHandshake(id); ok {
+			if _, ok := handshake.Head(shard.BeaconChainShardID); ok {
+				peers = append(peers, id)
+			}
+		}
+	}
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	return peers
+}
+
+// requestTransitions requests the next epoch transitions to a few beacon
+// peers, and returns the number of transitions delivered
+func (s *Service) requestTransitions() int {
+	peers := s.beaconPeers()
+	if len(peers) > requestPeers {
+		peers = peers[:requestPeers]
+	}
+	request := Request{Epoch: s.next(), Count: MaxTransitions}
+	for _, id := range peers {
+		transitions, err := s.request(id, request)
+		switch {
+		case err != nil:
+			requestsCounter.Inc("failed")
+			utils.Logger().Debug().Err(err).
+				Str("peer", id.Pretty()).
+				Uint64("epoch", request.Epoch).
+				Msg("[BeaconLight] cannot request epoch transitions")
+			continue
+		case len(transitions) == 0:
+			requestsCounter.Inc("empty")
+			return 0
+		}
+		if err := s.deliver(transitions); err != nil {
+			requestsCounter.Inc("invalid")
+			utils.Logger().Warn().Err(err).
+				Str("peer", id.Pretty()).
+				Uint64("epoch", request.Epoch).
+				Msg("[BeaconLight] invalid epoch transitions")
+			continue
+		}
+		requestsCounter.Inc("received")
+		return len(transitions)
+	}
+	return 0
+}
+
+// request sends the request to the peer and returns the epoch transitions
+// of its reply
+func (s *Service) request(id libp2p_peer.ID, request Request) ([]*core.EpochTransition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	stream, err := s.host.GetP2PHost().NewStream(ctx, id, Protocol)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open beacon light stream")
+	}
+	stream.SetDeadline(time.Now().Add(requestTimeout))
+	if err := rlp.Encode(stream, request); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	transitions := []*core.EpochTransition{}
+	if err := rlp.NewStream(stream, maxMessageSize).Decode(&transitions); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	go helpers.FullClose(stream)
+	if uint64(len(transitions)) > request.Count {
+		return nil, errors.Errorf("%d epoch transitions not requested", len(transitions))
+	}
+	return transitions, nil
+}
+
+// handleStream serves the epoch transitions of a request of a peer
+func (s *Service) handleStream(stream libp2p_network.Stream) {
+	stream.SetDeadline(time.Now().Add(requestTimeout))
+	request := Request{}
+	if err := rlp.NewStream(stream, maxMessageSize).Decode(&request); err != nil {
+		stream.Reset()
+		return
+	}
+	if request.Count > MaxTransitions {
+		request.Count = MaxTransitions
+	}
+	transitions, err := s.serve(request)
+	if err != nil {
+		utils.Logger().Debug().Err(err).
+			Str("peer", stream.Conn().RemotePeer().Pretty()).
+			Msg("[BeaconLight] cannot serve epoch transitions")
+		stream.Reset()
+		return
+	}
+	if err := rlp.Encode(stream, transitions); err != nil {
+		stream.Reset()
+		return
+	}
+	go helpers.FullClose(stream)
+}
+
+// StopService stops the beacon light client service.
+func (s *Service) StopService() {
+	utils.Logger().Info().Msg("Stopping beacon light client service.")
+	if s.serve != nil {
+		s.host.GetP2PHost().RemoveStreamHandler(Protocol)
+	}
+	close(s.stopChan)
+	<-s.stoppedChan
+}
+
+// NotifyService notify service
+func (s *Service) NotifyService(params map[string]interface{}) {}
+
+// SetMessageChan sets up message channel to service.
+func (s *Service) SetMessageChan(messageChan chan *msg_pb.Message) {
+	s.messageChan = messageChan
+}
+
+// APIs for the services.
+func (s *Service) APIs() []rpc.API {
+	return nil
+}
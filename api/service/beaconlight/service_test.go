@@ -0,0 +1,64 @@
+package beaconlight
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/p2p"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// testHost is a host whose peers all serve the beacon chain
+type testHost struct {
+	libp2p_host.Host
+}
+
+func (h testHost) GetP2PHost() libp2p_host.Host {
+	return h.Host
+}
+
+func (h testHost) PeerHandshake(id libp2p_peer.ID) (p2p.Handshake, bool) {
+	return p2p.Handshake{Heads: []p2p.HeadState{{ShardID: 0}}}, true
+}
+
+func TestRequestTransitions(t *testing.T) {
+	net, err := mocknet.FullMeshConnected(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := net.Hosts()
+	// the beacon chain is at epoch 5
+	serve := func(request Request) ([]*core.EpochTransition, error) {
+		transitions := []*core.EpochTransition{}
+		for epoch := request.Epoch; epoch < request.Epoch+request.Count && epoch < 5; epoch++ {
+			transitions = append(transitions, &core.EpochTransition{
+				Header:       blockfactory.ForTest.NewHeader(new(big.Int).SetUint64(epoch)),
+				CommitSig:    []byte{1},
+				CommitBitmap: []byte{2},
+			})
+		}
+		return transitions, nil
+	}
+	server := New(testHost{hosts[0]}, nil, serve, nil)
+	server.StartService()
+	defer server.StopService()
+
+	delivered := []*core.EpochTransition{}
+	client := New(testHost{hosts[1]}, func() uint64 { return 2 }, nil,
+		func(transitions []*core.EpochTransition) error {
+			delivered = append(delivered, transitions...)
+			return nil
+		},
+	)
+	if n := client.requestTransitions(); n != 3 {
+		t.Fatalf("got %d transitions, expect 3", n)
+	}
+	if delivered[0].Header.Epoch().Uint64() != 2 || delivered[2].Header.Epoch().Uint64() != 4 {
+		t.Errorf("got transitions %v, expect the transitions of the epochs 2 to 4", delivered)
+	}
+}
@@ -0,0 +1,286 @@
+package crosslink
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p"
+	"github.com/libp2p/go-libp2p-core/helpers"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
+)
+
+// Constants of the crosslink requests. A stream carries one request and
+// the crosslinks of the reply, both RLP encoded. The peer resets the
+// stream when it cannot serve the request.
+const (
+	Protocol        = protocol.ID("/harmony/crosslink/1.0.0")
+	MaxCrossLinks   = 64
+	maxMessageSize  = 1024 * 1024
+	requestTimeout  = 20 * time.Second
+	requestInterval = 30 * time.Second
+	// a gap at the tip of the crosslinks is requested once stale, as the
+	// crosslinks of the latest blocks are usually still on the way
+	staleAfter = 2 * time.Minute
+	// peers asked for the crosslinks of a gap
+	requestPeers = 2
+)
+
+var requestsCounter = metrics.DefaultRegistry.NewCounter(
+	"crosslink_requests_total", "Requests of missing crosslinks to the shard peers by result", "result",
+)
+
+// Request asks a peer for the crosslinks of the blocks of a shard, from the
+// block From
+type Request struct {
+	ShardID uint32
+	From    uint64
+	Count   uint64
+}
+
+// Host is the p2p host the crosslinks are requested and served with
+type Host interface {
+	GetP2PHost() libp2p_host.Host
+	PeerHandshake(id libp2p_peer.ID) (p2p.Handshake, bool)
+}
+
+// Gap is the first block of a shard whose crosslink is missing from the
+// continuous crosslinks of the beacon chain
+type Gap struct {
+	ShardID uint32
+	From    uint64
+	Hole    bool // the crosslink of a later block is known
+}
+
+// staleGap is a gap at the tip of the crosslinks with the time it was seen
+type staleGap struct {
+	from  uint64
+	since time.Time
+}
+
+// Service requests the crosslinks of the gaps of the beacon chain to the
+// peers serving their shard, and serves the crosslinks of the chain of the
+// node to the beacon chain peers.
+type Service struct {
+	host        Host
+	gaps        func() []Gap
+	serve       func(Request) ([]types.CrossLink, error)
+	deliver     func([]types.CrossLink)
+	stale       map[uint32]staleGap
+	stopChan    chan struct{}
+	stoppedChan chan struct{}
+	messageChan chan *msg_pb.Message
+}
+
+// New returns a crosslink request service. The gaps of the beacon chain are
+// requested when gaps is not nil, and the received crosslinks are handed to
+// deliver. The requests of the peers are served when serve is not nil.
+func New(
+	host Host,
+	gaps func() []Gap,
+	serve func(Request) ([]types.CrossLink, error),
+	deliver func([]types.CrossLink),
+) *Service {
+	return &Service{
+		host:    host,
+		gaps:    gaps,
+		serve:   serve,
+		deliver: deliver,
+		stale:   map[uint32]staleGap{},
+	}
+}
+
+// StartService starts the crosslink request service.
+func (s *Service) StartService() {
+	utils.Logger().Info().Msg("Starting crosslink request service.")
+	s.stopChan = make(chan struct{})
+	s.stoppedChan = make(chan struct{})
+	if s.serve != nil {
+		s.host.GetP2PHost().SetStreamHandler(Protocol, s.handleStream)
+	}
+	if s.gaps == nil {
+		close(s.stoppedChan)
+		return
+	}
+	go s.Run()
+}
+
+// Run requests the crosslinks of the gaps at every interval until the
+// service is stopped.
+func (s *Service) Run() {
+	defer close(s.stoppedChan)
+	ticker := time.NewTicker(requestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, gap := range s.dueGaps(time.Now()) {
+				s.requestGap(gap)
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// dueGaps returns the gaps to request, the holes and the gaps at the tip
+// stale for staleAfter
+func (s *Service) dueGaps(now time.Time) []Gap {
+	gaps := s.gaps()
+	due := []Gap{}
+	stale := map[uint32]staleGap{}
+	for _, gap := range gaps {
+		if gap.Hole {
+			due = append(due, gap)
+			continue
+		}
+		seen, ok := s.stale[gap.ShardID]
+		if !ok || seen.from != gap.From {
+			seen = staleGap{gap.From, now}
+		}
+		stale[gap.ShardID] = seen
+		if now.Sub(seen.since) >= staleAfter {
+			due = append(due, gap)
+		}
+	}
+	s.stale = stale
+	return due
+}
+
+// shardPeers returns the connected peers serving the chain of the shard
+func (s *Service) shardPeers(shardID uint32) []libp2p_peer.ID {
+	peers := []libp2p_peer.ID{}
+	for _, id := range s.host.GetP2PHost().Network().Peers() {
+		if handshake, ok := s.host.PeerHandshake(id); ok {
+			if _, ok := handshake.Head(shardID); ok {
+				peers = append(peers, id)
+			}
+		}
+	}
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	return peers
+}
+
+// requestGap requests the crosslinks of the gap to a few peers of its shard
+func (s *Service) requestGap(gap Gap) {
+	peers := s.shardPeers(gap.ShardID)
+	if len(peers) > requestPeers {
+		peers = peers[:requestPeers]
+	}
+	request := Request{ShardID: gap.ShardID, From: gap.From, Count: MaxCrossLinks}
+	for _, id := range peers {
+		crossLinks, err := s.request(id, request)
+		switch {
+		case err != nil:
+			requestsCounter.Inc("failed")
+			utils.Logger().Debug().Err(err).
+				Str("peer", id.Pretty()).
+				Uint32("shardID", gap.ShardID).
+				Uint64("from", gap.From).
+				Msg("[CrossLinkRequest] cannot request missing crosslinks")
+			continue
+		case len(crossLinks) == 0:
+			requestsCounter.Inc("empty")
+			continue
+		}
+		requestsCounter.Inc("received")
+		utils.Logger().Info().
+			Str("peer", id.Pretty()).
+			Uint32("shardID", gap.ShardID).
+			Uint64("from", gap.From).
+			Int("crosslinks", len(crossLinks)).
+			Msg("[CrossLinkRequest] received missing crosslinks")
+		s.deliver(crossLinks)
+		return
+	}
+}
+
+// request sends the request to the peer and returns the crosslinks of its
+// reply
+func (s *Service) request(id libp2p_peer.ID, request Request) ([]types.CrossLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	stream, err := s.host.GetP2PHost().NewStream(ctx, id, Protocol)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open crosslink stream")
+	}
+	stream.SetDeadline(time.Now().Add(requestTimeout))
+	if err := rlp.Encode(stream, request); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	crossLinks := []types.CrossLink{}
+	if err := rlp.NewStream(stream, maxMessageSize).Decode(&crossLinks); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	go helpers.FullClose(stream)
+	for _, cl := range crossLinks {
+		if cl.ShardID() != request.ShardID || cl.BlockNum() < request.From ||
+			cl.BlockNum() >= request.From+request.Count {
+			return nil, errors.Errorf("crosslink of block %d of shard %d not requested", cl.BlockNum(), cl.ShardID())
+		}
+	}
+	return crossLinks, nil
+}
+
+// handleStream serves the crosslinks of a request of a peer
+func (s *Service) handleStream(stream libp2p_network.Stream) {
+	stream.SetDeadline(time.Now().Add(requestTimeout))
+	request := Request{}
+	if err := rlp.NewStream(stream, maxMessageSize).Decode(&request); err != nil {
+		stream.Reset()
+		return
+	}
+	if request.Count > MaxCrossLinks {
+		request.Count = MaxCrossLinks
+	}
+	crossLinks, err := s.serve(request)
+	if err != nil {
+		utils.Logger().Debug().Err(err).
+			Str("peer", stream.Conn().RemotePeer().Pretty()).
+			Msg("[CrossLinkRequest] cannot serve crosslinks")
+		stream.Reset()
+		return
+	}
+	if err := rlp.Encode(stream, crossLinks); err != nil {
+		stream.Reset()
+		return
+	}
+	go helpers.FullClose(stream)
+}
+
+// StopService stops the crosslink request service.
+func (s *Service) StopService() {
+	utils.Logger().Info().Msg("Stopping crosslink request service.")
+	if s.serve != nil {
+		s.host.GetP2PHost().RemoveStreamHandler(Protocol)
+	}
+	close(s.stopChan)
+	<-s.stoppedChan
+}
+
+// NotifyService notify service
+func (s *Service) NotifyService(params map[string]interface{}) {}
+
+// SetMessageChan sets up message channel to service.
+func (s *Service) SetMessageChan(messageChan chan *msg_pb.Message) {
+	s.messageChan = messageChan
+}
+
+// APIs for the services.
+func (s *Service) APIs() []rpc.API {
+	return nil
+}
@@ -0,0 +1,76 @@
+package crosslink
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/p2p"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// testHost is a host whose peers all serve the chain of shard 1
+type testHost struct {
+	libp2p_host.Host
+}
+
+func (h testHost) GetP2PHost() libp2p_host.Host {
+	return h.Host
+}
+
+func (h testHost) PeerHandshake(id libp2p_peer.ID) (p2p.Handshake, bool) {
+	return p2p.Handshake{Heads: []p2p.HeadState{{ShardID: 1}}}, true
+}
+
+func TestDueGaps(t *testing.T) {
+	gaps := []Gap{{ShardID: 1, From: 10}, {ShardID: 2, From: 20, Hole: true}}
+	s := New(nil, func() []Gap { return gaps }, nil, nil)
+	now := time.Now()
+	if due := s.dueGaps(now); len(due) != 1 || due[0].ShardID != 2 {
+		t.Errorf("got gaps %v, expect the hole of shard 2", due)
+	}
+	if due := s.dueGaps(now.Add(staleAfter)); len(due) != 2 {
+		t.Errorf("got gaps %v, expect the stale gap of shard 1 too", due)
+	}
+	// the gap moved with the new crosslinks
+	gaps[0].From = 11
+	if due := s.dueGaps(now.Add(staleAfter)); len(due) != 1 {
+		t.Errorf("got gaps %v, expect the new gap of shard 1 not stale", due)
+	}
+}
+
+func TestRequestGap(t *testing.T) {
+	net, err := mocknet.FullMeshConnected(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := net.Hosts()
+	serve := func(request Request) ([]types.CrossLink, error) {
+		cls := []types.CrossLink{}
+		for number := request.From; number < request.From+request.Count && number < 15; number++ {
+			cls = append(cls, types.CrossLink{
+				BlockNumberF: new(big.Int).SetUint64(number),
+				ViewIDF:      new(big.Int).SetUint64(number),
+				ShardIDF:     request.ShardID,
+				EpochF:       big.NewInt(1),
+			})
+		}
+		return cls, nil
+	}
+	server := New(testHost{hosts[0]}, nil, serve, nil)
+	server.StartService()
+	defer server.StopService()
+
+	delivered := []types.CrossLink{}
+	client := New(testHost{hosts[1]}, nil, nil, func(cls []types.CrossLink) {
+		delivered = append(delivered, cls...)
+	})
+	client.requestGap(Gap{ShardID: 1, From: 10})
+	if len(delivered) != 5 || delivered[0].BlockNum() != 10 || delivered[4].BlockNum() != 14 {
+		t.Errorf("got crosslinks %v, expect the crosslinks of the blocks 10 to 14", delivered)
+	}
+}
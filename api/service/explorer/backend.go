@@ -0,0 +1,67 @@
+package explorer
+
+import (
+	"github.com/pkg/errors"
+)
+
+// The backends of the explorer storage
+const (
+	BadgerBackend   = "badger"
+	PostgresBackend = "postgres"
+)
+
+var (
+	// ErrNotFound is returned by the backends for a block or transaction not
+	// indexed
+	ErrNotFound = errors.New("not found in the explorer database")
+	// ErrUnknownBackend is returned opening a backend of an unknown kind
+	ErrUnknownBackend = errors.New("unknown explorer database backend")
+)
+
+// Backend is the database the explorer storage writes the normalized records
+// of the blocks to, and the queries of the explorer are answered from. The
+// writes of a block are idempotent, so a block can be written again by the
+// backfill after it was indexed live.
+type Backend interface {
+	// WriteBlock writes the block and its records
+	WriteBlock(block *BlockRecord) error
+	// Block returns the block of the number
+	Block(number uint64) (*BlockRecord, error)
+	// Transaction returns the plain or staking transaction of the hash
+	Transaction(hash string) (*TransactionRecord, error)
+	// AddressTransactions returns the plain or staking transaction history of
+	// the address in block order, or reverse block order if desc, skipping
+	// offset records and returning up to limit records, all of them if limit
+	// is 0
+	AddressTransactions(address string, staking, desc bool, offset, limit int) (TxRecords, error)
+	// AddressTransfers returns the transfers from or to the address in
+	// reverse block order
+	AddressTransfers(address string, offset, limit int) ([]*TransferRecord, error)
+	// AddressStakingEvents returns the staking events of the address, as
+	// validator or delegator, in reverse block order
+	AddressStakingEvents(address string, offset, limit int) ([]*StakingEventRecord, error)
+	// Addresses returns up to size addresses of the transactions, from the
+	// prefix on
+	Addresses(size int, prefix string) ([]string, error)
+	// BackfillCursor returns the next block the backfill writes
+	BackfillCursor() (uint64, error)
+	// SetBackfillCursor records the next block the backfill writes
+	SetBackfillCursor(next uint64) error
+	// Compact reclaims the disk space of the database, if it is local
+	Compact() error
+	// Close closes the database
+	Close() error
+}
+
+// OpenBackend opens the backend of the kind at the source, the directory of
+// the badger database or the connection string of the postgres database
+func OpenBackend(kind, source string) (Backend, error) {
+	switch kind {
+	case BadgerBackend:
+		return openBadgerBackend(source)
+	case PostgresBackend:
+		return openPostgresBackend(source)
+	default:
+		return nil, errors.Wrapf(ErrUnknownBackend, "%q", kind)
+	}
+}
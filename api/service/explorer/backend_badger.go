@@ -0,0 +1,295 @@
+package explorer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/dgraph-io/badger"
+	"github.com/pkg/errors"
+)
+
+// The prefixes of the keys of the badger backend
+var (
+	badgerBlockPrefix    = []byte("b")
+	badgerTxPrefix       = []byte("t")
+	badgerAddrTxPrefix   = []byte("a")
+	badgerTransferPrefix = []byte("x")
+	badgerEventPrefix    = []byte("s")
+	badgerAddressPrefix  = []byte("d")
+	badgerCursorKey      = []byte("m/backfill")
+)
+
+// badgerGCDiscardRatio is the share of discardable data a value log file is
+// rewritten at by the compaction
+const badgerGCDiscardRatio = 0.5
+
+// badgerBackend keeps the records in an embedded badger database, the
+// history of an address under the prefix of the address, by block and index
+type badgerBackend struct {
+	db *badger.DB
+}
+
+func openBadgerBackend(dir string) (*badgerBackend, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open explorer database %s", dir)
+	}
+	return &badgerBackend{db}, nil
+}
+
+func badgerKey(prefix []byte, parts ...[]byte) []byte {
+	key := append([]byte{}, prefix...)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+// badgerAddressKey returns the prefix of the records of the address
+func badgerAddressKey(prefix []byte, address string) []byte {
+	return badgerKey(prefix, []byte(address), []byte("/"))
+}
+
+// badgerPosition returns the ordered position of a record in the chain
+func badgerPosition(number uint64, index uint32, role byte) []byte {
+	position := make([]byte, 13)
+	binary.BigEndian.PutUint64(position, number)
+	binary.BigEndian.PutUint32(position[8:], index)
+	position[12] = role
+	return position
+}
+
+func badgerBool(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func (b *badgerBackend) WriteBlock(block *BlockRecord) error {
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+	set := func(key []byte, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return batch.Set(key, data)
+	}
+	number := make([]byte, 8)
+	binary.BigEndian.PutUint64(number, block.Number)
+	if err := set(badgerKey(badgerBlockPrefix, number), block); err != nil {
+		return err
+	}
+
+	for _, tx := range block.Transactions {
+		if err := set(badgerKey(badgerTxPrefix, []byte(tx.Hash)), tx); err != nil {
+			return err
+		}
+	}
+	for address, records := range block.txRecords() {
+		if err := batch.Set(badgerKey(badgerAddressPrefix, []byte(address)), []byte{}); err != nil {
+			return err
+		}
+		for _, r := range records {
+			role := byte(0)
+			if r.record.Type == Received {
+				role = 1
+			}
+			key := badgerKey(
+				badgerAddressKey(badgerAddrTxPrefix, address), badgerBool(r.tx.Staking),
+				badgerPosition(r.tx.BlockNumber, r.tx.Index, role),
+			)
+			if err := set(key, r.record); err != nil {
+				return err
+			}
+		}
+	}
+	for _, transfer := range block.Transfers {
+		for role, address := range []string{transfer.From, transfer.To} {
+			if role == 1 && address == transfer.From {
+				continue
+			}
+			key := badgerKey(
+				badgerAddressKey(badgerTransferPrefix, address),
+				badgerPosition(transfer.BlockNumber, transfer.Index, byte(role)),
+			)
+			if err := set(key, transfer); err != nil {
+				return err
+			}
+		}
+	}
+	for _, event := range block.StakingEvents {
+		for role, address := range []string{event.Validator, event.Delegator} {
+			if address == "" || role == 1 && address == event.Validator {
+				continue
+			}
+			key := badgerKey(
+				badgerAddressKey(badgerEventPrefix, address),
+				badgerPosition(event.BlockNumber, event.Index, byte(role)),
+			)
+			if err := set(key, event); err != nil {
+				return err
+			}
+		}
+	}
+	return batch.Flush()
+}
+
+// get decodes the JSON value of the key into value
+func (b *badgerBackend) get(key []byte, value interface{}) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(data []byte) error {
+			return json.Unmarshal(data, value)
+		})
+	})
+}
+
+// scan calls fn with the values of the keys under the prefix, in key order or
+// reverse key order if desc, skipping offset of them and stopping after limit
+// of them if limit is not 0
+func (b *badgerBackend) scan(
+	prefix []byte, desc bool, offset, limit int, fn func(value []byte) error,
+) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix, opts.Reverse = prefix, desc
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		seek := prefix
+		if desc {
+			seek = badgerKey(prefix, []byte{0xff})
+		}
+		n := 0
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			if n++; n <= offset {
+				continue
+			}
+			if err := it.Item().Value(fn); err != nil {
+				return err
+			}
+			if limit > 0 && n-offset >= limit {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (b *badgerBackend) Block(number uint64) (*BlockRecord, error) {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, number)
+	block := &BlockRecord{}
+	if err := b.get(badgerKey(badgerBlockPrefix, key), block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (b *badgerBackend) Transaction(hash string) (*TransactionRecord, error) {
+	tx := &TransactionRecord{}
+	if err := b.get(badgerKey(badgerTxPrefix, []byte(hash)), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (b *badgerBackend) AddressTransactions(
+	address string, staking, desc bool, offset, limit int,
+) (TxRecords, error) {
+	records := TxRecords{}
+	prefix := badgerKey(badgerAddressKey(badgerAddrTxPrefix, address), badgerBool(staking))
+	err := b.scan(prefix, desc, offset, limit, func(value []byte) error {
+		record := &TxRecord{}
+		records = append(records, record)
+		return json.Unmarshal(value, record)
+	})
+	return records, err
+}
+
+func (b *badgerBackend) AddressTransfers(address string, offset, limit int) ([]*TransferRecord, error) {
+	transfers := []*TransferRecord{}
+	prefix := badgerAddressKey(badgerTransferPrefix, address)
+	err := b.scan(prefix, true, offset, limit, func(value []byte) error {
+		transfer := &TransferRecord{}
+		transfers = append(transfers, transfer)
+		return json.Unmarshal(value, transfer)
+	})
+	return transfers, err
+}
+
+func (b *badgerBackend) AddressStakingEvents(address string, offset, limit int) ([]*StakingEventRecord, error) {
+	events := []*StakingEventRecord{}
+	prefix := badgerAddressKey(badgerEventPrefix, address)
+	err := b.scan(prefix, true, offset, limit, func(value []byte) error {
+		event := &StakingEventRecord{}
+		events = append(events, event)
+		return json.Unmarshal(value, event)
+	})
+	return events, err
+}
+
+func (b *badgerBackend) Addresses(size int, prefix string) ([]string, error) {
+	addresses := []string{}
+	start := badgerKey(badgerAddressPrefix, []byte(prefix))
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(start); it.ValidForPrefix(badgerAddressPrefix) && len(addresses) < size; it.Next() {
+			addresses = append(addresses, string(it.Item().Key()[len(badgerAddressPrefix):]))
+		}
+		return nil
+	})
+	return addresses, err
+}
+
+func (b *badgerBackend) BackfillCursor() (uint64, error) {
+	next := uint64(0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerCursorKey)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(data []byte) error {
+			next = binary.BigEndian.Uint64(data)
+			return nil
+		})
+	})
+	return next, err
+}
+
+func (b *badgerBackend) SetBackfillCursor(next uint64) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, next)
+		return txn.Set(badgerCursorKey, data)
+	})
+}
+
+func (b *badgerBackend) Compact() error {
+	if err := b.db.Flatten(1); err != nil {
+		return err
+	}
+	// rewrites the value log files until none is worth it
+	for {
+		if err := b.db.RunValueLogGC(badgerGCDiscardRatio); err == badger.ErrNoRewrite {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (b *badgerBackend) Close() error {
+	return b.db.Close()
+}
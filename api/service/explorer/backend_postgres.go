@@ -0,0 +1,414 @@
+package explorer
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	// the postgres driver of database/sql
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// postgresSchema creates the tables of the records, the history of the
+// addresses read through the indexes of their senders and recipients
+var postgresSchema = []string{
+	`CREATE TABLE IF NOT EXISTS blocks (
+		number BIGINT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		parent_hash TEXT NOT NULL,
+		shard_id INTEGER NOT NULL,
+		epoch BIGINT NOT NULL,
+		view_id BIGINT NOT NULL,
+		timestamp BIGINT NOT NULL,
+		leader TEXT NOT NULL,
+		gas_used BIGINT NOT NULL,
+		gas_limit BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		hash TEXT PRIMARY KEY,
+		block_number BIGINT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		staking BOOLEAN NOT NULL,
+		type TEXT NOT NULL,
+		sender TEXT NOT NULL,
+		recipient TEXT NOT NULL,
+		value NUMERIC NOT NULL,
+		gas_price NUMERIC NOT NULL,
+		gas BIGINT NOT NULL,
+		nonce BIGINT NOT NULL,
+		shard_id INTEGER NOT NULL,
+		to_shard_id INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		timestamp BIGINT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS transactions_block ON transactions (block_number, tx_index)`,
+	`CREATE INDEX IF NOT EXISTS transactions_sender ON transactions (sender, staking, block_number, tx_index)`,
+	`CREATE INDEX IF NOT EXISTS transactions_recipient ON transactions (recipient, staking, block_number, tx_index)`,
+	`CREATE TABLE IF NOT EXISTS transfers (
+		tx_hash TEXT NOT NULL,
+		block_number BIGINT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		sender TEXT NOT NULL,
+		recipient TEXT NOT NULL,
+		value NUMERIC NOT NULL,
+		from_shard_id INTEGER NOT NULL,
+		to_shard_id INTEGER NOT NULL,
+		timestamp BIGINT NOT NULL,
+		PRIMARY KEY (block_number, tx_index)
+	)`,
+	`CREATE INDEX IF NOT EXISTS transfers_sender ON transfers (sender, block_number, tx_index)`,
+	`CREATE INDEX IF NOT EXISTS transfers_recipient ON transfers (recipient, block_number, tx_index)`,
+	`CREATE TABLE IF NOT EXISTS staking_events (
+		tx_hash TEXT PRIMARY KEY,
+		block_number BIGINT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		validator TEXT NOT NULL,
+		delegator TEXT NOT NULL,
+		amount NUMERIC NOT NULL,
+		timestamp BIGINT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS staking_events_validator ON staking_events (validator, block_number, tx_index)`,
+	`CREATE INDEX IF NOT EXISTS staking_events_delegator ON staking_events (delegator, block_number, tx_index)`,
+	`CREATE TABLE IF NOT EXISTS addresses (address TEXT PRIMARY KEY)`,
+	`CREATE TABLE IF NOT EXISTS explorer_meta (key TEXT PRIMARY KEY, value BIGINT NOT NULL)`,
+}
+
+const (
+	postgresTransactionColumns = `hash, block_number, tx_index, staking, type, sender, recipient,
+		value, gas_price, gas, nonce, shard_id, to_shard_id, data, timestamp`
+	postgresTransferColumns = `tx_hash, block_number, tx_index, sender, recipient, value,
+		from_shard_id, to_shard_id, timestamp`
+	postgresEventColumns = `tx_hash, block_number, tx_index, type, validator, delegator,
+		amount, timestamp`
+	postgresCursorKey = "backfill"
+)
+
+// postgresBackend keeps the records in the tables of an external postgres
+// database, shared by the explorer nodes of the shard
+type postgresBackend struct {
+	db *sql.DB
+}
+
+func openPostgresBackend(source string) (*postgresBackend, error) {
+	db, err := sql.Open("postgres", source)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open explorer database")
+	}
+	return newPostgresBackend(db)
+}
+
+// newPostgresBackend creates the schema of the records in the database, if
+// missing
+func newPostgresBackend(db *sql.DB) (*postgresBackend, error) {
+	for _, statement := range postgresSchema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "cannot create explorer database schema")
+		}
+	}
+	return &postgresBackend{db}, nil
+}
+
+// scanner is a sql row or rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// bigInt scans a NUMERIC column into a big integer
+type bigInt struct {
+	value **big.Int
+}
+
+func (b bigInt) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.Errorf("cannot scan %T into a big integer", src)
+	}
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return errors.Errorf("invalid big integer %q", s)
+	}
+	*b.value = value
+	return nil
+}
+
+// limit returns the LIMIT of a query, NULL for no limit
+func limit(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n > 0}
+}
+
+func (p *postgresBackend) WriteBlock(block *BlockRecord) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := writePostgresBlock(tx, block); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func writePostgresBlock(tx *sql.Tx, block *BlockRecord) error {
+	if _, err := tx.Exec(`INSERT INTO blocks VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (number) DO UPDATE SET hash = EXCLUDED.hash`,
+		block.Number, block.Hash, block.ParentHash, block.ShardID, block.Epoch,
+		block.ViewID, block.Timestamp, block.Leader, block.GasUsed, block.GasLimit,
+	); err != nil {
+		return errors.Wrap(err, "cannot write block")
+	}
+	addresses := map[string]struct{}{}
+	for _, t := range block.Transactions {
+		if _, err := tx.Exec(`INSERT INTO transactions (`+postgresTransactionColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			ON CONFLICT (hash) DO NOTHING`,
+			t.Hash, t.BlockNumber, t.Index, t.Staking, t.Type, t.From, t.To,
+			t.Value.String(), t.GasPrice.String(), t.Gas, t.Nonce, t.ShardID,
+			t.ToShardID, t.Data, t.Timestamp,
+		); err != nil {
+			return errors.Wrap(err, "cannot write transaction")
+		}
+		addresses[t.From] = struct{}{}
+		if t.To != "" {
+			addresses[t.To] = struct{}{}
+		}
+	}
+	for _, t := range block.Transfers {
+		if _, err := tx.Exec(`INSERT INTO transfers (`+postgresTransferColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (block_number, tx_index) DO NOTHING`,
+			t.TxHash, t.BlockNumber, t.Index, t.From, t.To, t.Value.String(),
+			t.FromShardID, t.ToShardID, t.Timestamp,
+		); err != nil {
+			return errors.Wrap(err, "cannot write transfer")
+		}
+	}
+	for _, e := range block.StakingEvents {
+		if _, err := tx.Exec(`INSERT INTO staking_events (`+postgresEventColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (tx_hash) DO NOTHING`,
+			e.TxHash, e.BlockNumber, e.Index, e.Type, e.Validator, e.Delegator,
+			e.Amount.String(), e.Timestamp,
+		); err != nil {
+			return errors.Wrap(err, "cannot write staking event")
+		}
+	}
+	for address := range addresses {
+		if _, err := tx.Exec(
+			`INSERT INTO addresses VALUES ($1) ON CONFLICT (address) DO NOTHING`, address,
+		); err != nil {
+			return errors.Wrap(err, "cannot write address")
+		}
+	}
+	return nil
+}
+
+func scanTransaction(row scanner) (*TransactionRecord, error) {
+	t := &TransactionRecord{}
+	err := row.Scan(
+		&t.Hash, &t.BlockNumber, &t.Index, &t.Staking, &t.Type, &t.From, &t.To,
+		bigInt{&t.Value}, bigInt{&t.GasPrice}, &t.Gas, &t.Nonce, &t.ShardID,
+		&t.ToShardID, &t.Data, &t.Timestamp,
+	)
+	return t, err
+}
+
+func scanTransfer(row scanner) (*TransferRecord, error) {
+	t := &TransferRecord{}
+	err := row.Scan(
+		&t.TxHash, &t.BlockNumber, &t.Index, &t.From, &t.To, bigInt{&t.Value},
+		&t.FromShardID, &t.ToShardID, &t.Timestamp,
+	)
+	return t, err
+}
+
+func scanEvent(row scanner) (*StakingEventRecord, error) {
+	e := &StakingEventRecord{}
+	err := row.Scan(
+		&e.TxHash, &e.BlockNumber, &e.Index, &e.Type, &e.Validator, &e.Delegator,
+		bigInt{&e.Amount}, &e.Timestamp,
+	)
+	return e, err
+}
+
+// query calls fn with the rows of the query
+func (p *postgresBackend) query(fn func(scanner) error, query string, args ...interface{}) error {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (p *postgresBackend) Block(number uint64) (*BlockRecord, error) {
+	b := &BlockRecord{
+		Transactions:  []*TransactionRecord{},
+		Transfers:     []*TransferRecord{},
+		StakingEvents: []*StakingEventRecord{},
+	}
+	err := p.db.QueryRow(`SELECT number, hash, parent_hash, shard_id, epoch, view_id,
+		timestamp, leader, gas_used, gas_limit FROM blocks WHERE number = $1`, number,
+	).Scan(
+		&b.Number, &b.Hash, &b.ParentHash, &b.ShardID, &b.Epoch, &b.ViewID,
+		&b.Timestamp, &b.Leader, &b.GasUsed, &b.GasLimit,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if err := p.query(func(row scanner) error {
+		t, err := scanTransaction(row)
+		b.Transactions = append(b.Transactions, t)
+		return err
+	}, `SELECT `+postgresTransactionColumns+` FROM transactions
+		WHERE block_number = $1 ORDER BY tx_index`, number); err != nil {
+		return nil, err
+	}
+	if err := p.query(func(row scanner) error {
+		t, err := scanTransfer(row)
+		b.Transfers = append(b.Transfers, t)
+		return err
+	}, `SELECT `+postgresTransferColumns+` FROM transfers
+		WHERE block_number = $1 ORDER BY tx_index`, number); err != nil {
+		return nil, err
+	}
+	if err := p.query(func(row scanner) error {
+		e, err := scanEvent(row)
+		b.StakingEvents = append(b.StakingEvents, e)
+		return err
+	}, `SELECT `+postgresEventColumns+` FROM staking_events
+		WHERE block_number = $1 ORDER BY tx_index`, number); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (p *postgresBackend) Transaction(hash string) (*TransactionRecord, error) {
+	t, err := scanTransaction(p.db.QueryRow(
+		`SELECT `+postgresTransactionColumns+` FROM transactions WHERE hash = $1`, hash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return t, err
+}
+
+func (p *postgresBackend) AddressTransactions(
+	address string, staking, desc bool, offset, n int,
+) (TxRecords, error) {
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	records := TxRecords{}
+	err := p.query(func(row scanner) error {
+		var hash string
+		var timestamp int64
+		var received bool
+		if err := row.Scan(&hash, &timestamp, &received); err != nil {
+			return err
+		}
+		record := &TxRecord{Hash: hash, Type: Sent, Timestamp: fmt.Sprint(timestamp * 1000)}
+		if received {
+			record.Type = Received
+		}
+		records = append(records, record)
+		return nil
+	}, fmt.Sprintf(`SELECT hash, timestamp, received FROM (
+			SELECT hash, timestamp, block_number, tx_index, FALSE AS received
+			FROM transactions WHERE sender = $1 AND staking = $2
+			UNION ALL
+			SELECT hash, timestamp, block_number, tx_index, TRUE AS received
+			FROM transactions WHERE recipient = $1 AND staking = $2
+		) history ORDER BY block_number %[1]s, tx_index %[1]s, received %[1]s
+		OFFSET $3 LIMIT $4`, order),
+		address, staking, offset, limit(n),
+	)
+	return records, err
+}
+
+func (p *postgresBackend) AddressTransfers(address string, offset, n int) ([]*TransferRecord, error) {
+	transfers := []*TransferRecord{}
+	err := p.query(func(row scanner) error {
+		t, err := scanTransfer(row)
+		transfers = append(transfers, t)
+		return err
+	}, `SELECT `+postgresTransferColumns+` FROM transfers
+		WHERE sender = $1 OR recipient = $1
+		ORDER BY block_number DESC, tx_index DESC OFFSET $2 LIMIT $3`,
+		address, offset, limit(n),
+	)
+	return transfers, err
+}
+
+func (p *postgresBackend) AddressStakingEvents(address string, offset, n int) ([]*StakingEventRecord, error) {
+	events := []*StakingEventRecord{}
+	err := p.query(func(row scanner) error {
+		e, err := scanEvent(row)
+		events = append(events, e)
+		return err
+	}, `SELECT `+postgresEventColumns+` FROM staking_events
+		WHERE validator = $1 OR delegator = $1
+		ORDER BY block_number DESC, tx_index DESC OFFSET $2 LIMIT $3`,
+		address, offset, limit(n),
+	)
+	return events, err
+}
+
+func (p *postgresBackend) Addresses(size int, prefix string) ([]string, error) {
+	addresses := []string{}
+	err := p.query(func(row scanner) error {
+		var address string
+		if err := row.Scan(&address); err != nil {
+			return err
+		}
+		addresses = append(addresses, address)
+		return nil
+	}, `SELECT address FROM addresses WHERE address >= $1 ORDER BY address LIMIT $2`,
+		prefix, size,
+	)
+	return addresses, err
+}
+
+func (p *postgresBackend) BackfillCursor() (uint64, error) {
+	next := uint64(0)
+	err := p.db.QueryRow(
+		`SELECT value FROM explorer_meta WHERE key = $1`, postgresCursorKey,
+	).Scan(&next)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return next, err
+}
+
+func (p *postgresBackend) SetBackfillCursor(next uint64) error {
+	_, err := p.db.Exec(`INSERT INTO explorer_meta VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`,
+		postgresCursorKey, next,
+	)
+	return err
+}
+
+// Compact leaves the vacuuming of the tables to the autovacuum of the
+// database
+func (p *postgresBackend) Compact() error {
+	return nil
+}
+
+func (p *postgresBackend) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,227 @@
+package explorer
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockPostgresBackend returns a postgres backend of a mock database whose
+// schema is created
+func newMockPostgresBackend(t *testing.T) (*postgresBackend, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range postgresSchema {
+		mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	backend, err := newPostgresBackend(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend, mock
+}
+
+// like matches the queries containing the fragment
+func like(fragment string) string {
+	return regexp.QuoteMeta(fragment)
+}
+
+// newRegexpMock returns a mock database matching the queries by regexp
+func newRegexpMock(t *testing.T) (*postgresBackend, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &postgresBackend{db}, mock
+}
+
+func TestPostgresSchema(t *testing.T) {
+	_, mock := newMockPostgresBackend(t)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// the database is closed on a schema error
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(postgresSchema[0]).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(postgresSchema[1]).WillReturnError(errors.New("syntax error"))
+	mock.ExpectClose()
+	_, err = newPostgresBackend(db)
+	assert.NotNil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func newTestBlockRecord() *BlockRecord {
+	return &BlockRecord{
+		Number: 314, Hash: "0xb", ParentHash: "0xa", ShardID: 1, Epoch: 2,
+		ViewID: 315, Timestamp: 1600000000, Leader: "one1leader", GasUsed: 21000,
+		GasLimit: 80000000,
+		Transactions: []*TransactionRecord{{
+			Hash: "0xt", BlockNumber: 314, Index: 0, Type: "", From: "one1from",
+			To: "one1to", Value: big.NewInt(100), GasPrice: big.NewInt(1),
+			Gas: 21000, Nonce: 7, ShardID: 1, ToShardID: 1, Data: "0x",
+			Timestamp: 1600000000,
+		}},
+		Transfers: []*TransferRecord{{
+			TxHash: "0xt", BlockNumber: 314, Index: 0, From: "one1from",
+			To: "one1to", Value: big.NewInt(100), FromShardID: 1, ToShardID: 1,
+			Timestamp: 1600000000,
+		}},
+		StakingEvents: []*StakingEventRecord{{
+			TxHash: "0xs", BlockNumber: 314, Index: 1, Type: "Delegate",
+			Validator: "one1validator", Delegator: "one1from",
+			Amount: big.NewInt(5), Timestamp: 1600000000,
+		}},
+	}
+}
+
+func TestPostgresWriteBlock(t *testing.T) {
+	backend, mock := newRegexpMock(t)
+	block := newTestBlockRecord()
+	tx, transfer, event := block.Transactions[0], block.Transfers[0], block.StakingEvents[0]
+
+	mock.ExpectBegin()
+	mock.ExpectExec(like("INSERT INTO blocks VALUES")).WithArgs(
+		int64(314), "0xb", "0xa", int64(1), int64(2), int64(315), int64(1600000000),
+		"one1leader", int64(21000), int64(80000000),
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO transactions")).WithArgs(
+		tx.Hash, int64(314), int64(0), false, "", "one1from", "one1to", "100", "1",
+		int64(21000), int64(7), int64(1), int64(1), "0x", int64(1600000000),
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO transfers")).WithArgs(
+		transfer.TxHash, int64(314), int64(0), "one1from", "one1to", "100",
+		int64(1), int64(1), int64(1600000000),
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO staking_events")).WithArgs(
+		event.TxHash, int64(314), int64(1), "Delegate", "one1validator", "one1from",
+		"5", int64(1600000000),
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO addresses")).WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO addresses")).WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.Nil(t, backend.WriteBlock(block))
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// a failed write is rolled back
+	mock.ExpectBegin()
+	mock.ExpectExec(like("INSERT INTO blocks VALUES")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(like("INSERT INTO transactions")).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+	assert.NotNil(t, backend.WriteBlock(block))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresBlock(t *testing.T) {
+	backend, mock := newRegexpMock(t)
+	expected := newTestBlockRecord()
+	tx, transfer, event := expected.Transactions[0], expected.Transfers[0], expected.StakingEvents[0]
+
+	mock.ExpectQuery(like("FROM blocks WHERE number = $1")).WithArgs(int64(314)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"number", "hash", "parent_hash", "shard_id", "epoch", "view_id",
+			"timestamp", "leader", "gas_used", "gas_limit",
+		}).AddRow(314, "0xb", "0xa", 1, 2, 315, 1600000000, "one1leader", 21000, 80000000))
+	mock.ExpectQuery(like("FROM transactions\n\t\tWHERE block_number = $1 ORDER BY tx_index")).
+		WithArgs(int64(314)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"hash", "block_number", "tx_index", "staking", "type", "sender",
+			"recipient", "value", "gas_price", "gas", "nonce", "shard_id",
+			"to_shard_id", "data", "timestamp",
+		}).AddRow(
+			tx.Hash, 314, 0, false, "", "one1from", "one1to", []byte("100"), "1",
+			21000, 7, 1, 1, "0x", 1600000000,
+		))
+	mock.ExpectQuery(like("FROM transfers\n\t\tWHERE block_number = $1 ORDER BY tx_index")).
+		WithArgs(int64(314)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"tx_hash", "block_number", "tx_index", "sender", "recipient", "value",
+			"from_shard_id", "to_shard_id", "timestamp",
+		}).AddRow(transfer.TxHash, 314, 0, "one1from", "one1to", "100", 1, 1, 1600000000))
+	mock.ExpectQuery(like("FROM staking_events\n\t\tWHERE block_number = $1 ORDER BY tx_index")).
+		WithArgs(int64(314)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"tx_hash", "block_number", "tx_index", "type", "validator", "delegator",
+			"amount", "timestamp",
+		}).AddRow(event.TxHash, 314, 1, "Delegate", "one1validator", "one1from", "5", 1600000000))
+	block, err := backend.Block(314)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, block)
+
+	mock.ExpectQuery(like("FROM blocks WHERE number = $1")).WithArgs(int64(315)).
+		WillReturnError(sql.ErrNoRows)
+	_, err = backend.Block(315)
+	assert.Equal(t, ErrNotFound, err)
+
+	// a NUMERIC column which is not an integer
+	mock.ExpectQuery(like("FROM transactions WHERE hash = $1")).WithArgs("0xt").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"hash", "block_number", "tx_index", "staking", "type", "sender",
+			"recipient", "value", "gas_price", "gas", "nonce", "shard_id",
+			"to_shard_id", "data", "timestamp",
+		}).AddRow(
+			tx.Hash, 314, 0, false, "", "one1from", "one1to", "1.5", "1",
+			21000, 7, 1, 1, "0x", 1600000000,
+		))
+	_, err = backend.Transaction("0xt")
+	assert.NotNil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresAddressTransactions(t *testing.T) {
+	backend, mock := newRegexpMock(t)
+	columns := []string{"hash", "timestamp", "received"}
+
+	mock.ExpectQuery(like("ORDER BY block_number DESC, tx_index DESC, received DESC")).
+		WithArgs("one1to", true, int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("0xs", 1600000001, true).
+			AddRow("0xt", 1600000000, false))
+	records, err := backend.AddressTransactions("one1to", true, true, 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, TxRecords{
+		{Hash: "0xs", Type: Received, Timestamp: "1600000001000"},
+		{Hash: "0xt", Type: Sent, Timestamp: "1600000000000"},
+	}, records)
+
+	// no limit
+	mock.ExpectQuery(like("ORDER BY block_number ASC, tx_index ASC, received ASC")).
+		WithArgs("one1to", false, int64(0), nil).
+		WillReturnRows(sqlmock.NewRows(columns))
+	records, err = backend.AddressTransactions("one1to", false, false, 0, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresBackfillCursor(t *testing.T) {
+	backend, mock := newRegexpMock(t)
+
+	mock.ExpectQuery(like("SELECT value FROM explorer_meta WHERE key = $1")).
+		WithArgs(postgresCursorKey).WillReturnError(sql.ErrNoRows)
+	next, err := backend.BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), next)
+
+	mock.ExpectExec(like("ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value")).
+		WithArgs(postgresCursorKey, int64(1234)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.Nil(t, backend.SetBackfillCursor(1234))
+
+	mock.ExpectQuery(like("SELECT value FROM explorer_meta WHERE key = $1")).
+		WithArgs(postgresCursorKey).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1234))
+	next, err = backend.BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), next)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
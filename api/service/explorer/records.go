@@ -0,0 +1,302 @@
+package explorer
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	core2 "github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// BlockRecord is the normalized record of a block of the shard, with the
+// records of its transactions, transfers and staking events
+type BlockRecord struct {
+	Number        uint64                `json:"number"`
+	Hash          string                `json:"hash"`
+	ParentHash    string                `json:"parentHash"`
+	ShardID       uint32                `json:"shardID"`
+	Epoch         uint64                `json:"epoch"`
+	ViewID        uint64                `json:"viewID"`
+	Timestamp     int64                 `json:"timestamp"`
+	Leader        string                `json:"leader"`
+	GasUsed       uint64                `json:"gasUsed"`
+	GasLimit      uint64                `json:"gasLimit"`
+	Transactions  []*TransactionRecord  `json:"transactions"`
+	Transfers     []*TransferRecord     `json:"transfers"`
+	StakingEvents []*StakingEventRecord `json:"stakingEvents"`
+}
+
+// TransactionRecord is the normalized record of a plain or staking
+// transaction. Type is the directive of the staking transactions, To the
+// validator of the delegations and undelegations.
+type TransactionRecord struct {
+	Hash        string   `json:"hash"`
+	BlockNumber uint64   `json:"blockNumber"`
+	Index       uint32   `json:"index"`
+	Staking     bool     `json:"staking"`
+	Type        string   `json:"type"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Value       *big.Int `json:"value"`
+	GasPrice    *big.Int `json:"gasPrice"`
+	Gas         uint64   `json:"gas"`
+	Nonce       uint64   `json:"nonce"`
+	ShardID     uint32   `json:"shardID"`
+	ToShardID   uint32   `json:"toShardID"`
+	Data        string   `json:"data"`
+	Timestamp   int64    `json:"timestamp"`
+}
+
+// TransferRecord is the normalized record of a transfer of ONE by a plain
+// transaction, or of the credit of a cross-shard transfer on its destination
+// shard, whose TxHash is the hash of the transaction on the source shard
+type TransferRecord struct {
+	TxHash      string   `json:"txHash"`
+	BlockNumber uint64   `json:"blockNumber"`
+	Index       uint32   `json:"index"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Value       *big.Int `json:"value"`
+	FromShardID uint32   `json:"fromShardID"`
+	ToShardID   uint32   `json:"toShardID"`
+	Timestamp   int64    `json:"timestamp"`
+}
+
+// StakingEventRecord is the normalized record of a staking transaction, by
+// the validator and delegator it is about
+type StakingEventRecord struct {
+	TxHash      string   `json:"txHash"`
+	BlockNumber uint64   `json:"blockNumber"`
+	Index       uint32   `json:"index"`
+	Type        string   `json:"type"`
+	Validator   string   `json:"validator"`
+	Delegator   string   `json:"delegator"`
+	Amount      *big.Int `json:"amount"`
+	Timestamp   int64    `json:"timestamp"`
+}
+
+// NewBlockRecord normalizes the block into its records
+func NewBlockRecord(block *types.Block) (*BlockRecord, error) {
+	header := block.Header()
+	leader, err := common2.AddressToBech32(header.Coinbase())
+	if err != nil {
+		return nil, err
+	}
+	record := &BlockRecord{
+		Number:        block.NumberU64(),
+		Hash:          block.Hash().Hex(),
+		ParentHash:    block.ParentHash().Hex(),
+		ShardID:       block.ShardID(),
+		Epoch:         block.Epoch().Uint64(),
+		ViewID:        header.ViewID().Uint64(),
+		Timestamp:     block.Time().Int64(),
+		Leader:        leader,
+		GasUsed:       block.GasUsed(),
+		GasLimit:      block.GasLimit(),
+		Transactions:  []*TransactionRecord{},
+		Transfers:     []*TransferRecord{},
+		StakingEvents: []*StakingEventRecord{},
+	}
+
+	index := uint32(0)
+	for _, tx := range block.Transactions() {
+		txRecord, err := newTransactionRecord(tx, block, index)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transaction %s", tx.Hash().Hex())
+		}
+		record.Transactions = append(record.Transactions, txRecord)
+		if txRecord.Value.Sign() > 0 && txRecord.To != "" {
+			record.Transfers = append(record.Transfers, &TransferRecord{
+				TxHash:      txRecord.Hash,
+				BlockNumber: txRecord.BlockNumber,
+				Index:       index,
+				From:        txRecord.From,
+				To:          txRecord.To,
+				Value:       txRecord.Value,
+				FromShardID: txRecord.ShardID,
+				ToShardID:   txRecord.ToShardID,
+				Timestamp:   txRecord.Timestamp,
+			})
+		}
+		index++
+	}
+	for _, tx := range block.StakingTransactions() {
+		txRecord, event, err := newStakingRecords(tx, block, index)
+		if err != nil {
+			return nil, errors.Wrapf(err, "staking transaction %s", tx.Hash().Hex())
+		}
+		record.Transactions = append(record.Transactions, txRecord)
+		record.StakingEvents = append(record.StakingEvents, event)
+		index++
+	}
+	// the cross-shard transfers credited by the block, indexed after its
+	// transactions
+	for _, proof := range block.IncomingReceipts() {
+		for _, cx := range proof.Receipts {
+			if cx.To == nil {
+				continue
+			}
+			from, err := common2.AddressToBech32(cx.From)
+			if err != nil {
+				return nil, err
+			}
+			to, err := common2.AddressToBech32(*cx.To)
+			if err != nil {
+				return nil, err
+			}
+			record.Transfers = append(record.Transfers, &TransferRecord{
+				TxHash:      cx.TxHash.Hex(),
+				BlockNumber: record.Number,
+				Index:       index,
+				From:        from,
+				To:          to,
+				Value:       cx.Amount,
+				FromShardID: cx.ShardID,
+				ToShardID:   cx.ToShardID,
+				Timestamp:   record.Timestamp,
+			})
+			index++
+		}
+	}
+	return record, nil
+}
+
+func newTransactionRecord(
+	tx *types.Transaction, block *types.Block, index uint32,
+) (*TransactionRecord, error) {
+	msg, err := tx.AsMessage(types.NewEIP155Signer(tx.ChainID()))
+	if err != nil {
+		return nil, err
+	}
+	from, err := common2.AddressToBech32(msg.From())
+	if err != nil {
+		return nil, err
+	}
+	to := ""
+	if msg.To() != nil {
+		if to, err = common2.AddressToBech32(*msg.To()); err != nil {
+			return nil, err
+		}
+	}
+	return &TransactionRecord{
+		Hash:        tx.Hash().Hex(),
+		BlockNumber: block.NumberU64(),
+		Index:       index,
+		From:        from,
+		To:          to,
+		Value:       msg.Value(),
+		GasPrice:    tx.GasPrice(),
+		Gas:         tx.Gas(),
+		Nonce:       tx.Nonce(),
+		ShardID:     tx.ShardID(),
+		ToShardID:   tx.ToShardID(),
+		Data:        hex.EncodeToString(tx.Data()),
+		Timestamp:   block.Time().Int64(),
+	}, nil
+}
+
+func newStakingRecords(
+	tx *staking.StakingTransaction, block *types.Block, index uint32,
+) (*TransactionRecord, *StakingEventRecord, error) {
+	msg, err := core2.StakingToMessage(tx, block.Header().Number())
+	if err != nil {
+		return nil, nil, err
+	}
+	stkMsg, err := staking.RLPDecodeStakeMsg(tx.Data(), tx.StakingType())
+	if err != nil {
+		return nil, nil, err
+	}
+	var validator, delegator *common.Address
+	amount := new(big.Int)
+	switch m := stkMsg.(type) {
+	case *staking.CreateValidator:
+		validator, amount = &m.ValidatorAddress, m.Amount
+	case *staking.EditValidator:
+		validator = &m.ValidatorAddress
+	case *staking.Delegate:
+		validator, delegator, amount = &m.ValidatorAddress, &m.DelegatorAddress, m.Amount
+	case *staking.Undelegate:
+		validator, delegator, amount = &m.ValidatorAddress, &m.DelegatorAddress, m.Amount
+	case *staking.CollectRewards:
+		delegator = &m.DelegatorAddress
+	case *staking.BindSlotKey:
+		validator = &m.ValidatorAddress
+	}
+
+	from, err := common2.AddressToBech32(msg.From())
+	if err != nil {
+		return nil, nil, err
+	}
+	bech32 := func(addr *common.Address) (string, error) {
+		if addr == nil {
+			return "", nil
+		}
+		return common2.AddressToBech32(*addr)
+	}
+	event := &StakingEventRecord{
+		TxHash:      tx.Hash().Hex(),
+		BlockNumber: block.NumberU64(),
+		Index:       index,
+		Type:        tx.StakingType().String(),
+		Amount:      amount,
+		Timestamp:   block.Time().Int64(),
+	}
+	if event.Validator, err = bech32(validator); err != nil {
+		return nil, nil, err
+	}
+	if event.Delegator, err = bech32(delegator); err != nil {
+		return nil, nil, err
+	}
+	txRecord := &TransactionRecord{
+		Hash:        event.TxHash,
+		BlockNumber: event.BlockNumber,
+		Index:       index,
+		Staking:     true,
+		Type:        event.Type,
+		From:        from,
+		Value:       msg.Value(),
+		GasPrice:    tx.GasPrice(),
+		Gas:         tx.Gas(),
+		Nonce:       tx.Nonce(),
+		ShardID:     tx.ShardID(),
+		ToShardID:   tx.ShardID(),
+		Data:        hex.EncodeToString(msg.Data()),
+		Timestamp:   event.Timestamp,
+	}
+	// as GetStakingTransaction, the delegations and undelegations are
+	// received by their validator
+	switch tx.StakingType() {
+	case staking.DirectiveDelegate, staking.DirectiveUndelegate:
+		txRecord.To = event.Validator
+	}
+	return txRecord, event, nil
+}
+
+// txRecords returns the address history records of the transactions of the
+// block, as sent by their sender and received by their recipient
+func (b *BlockRecord) txRecords() map[string][]*addressTxRecord {
+	records := map[string][]*addressTxRecord{}
+	for _, tx := range b.Transactions {
+		timestamp := strconv.FormatInt(tx.Timestamp*1000, 10)
+		records[tx.From] = append(records[tx.From], &addressTxRecord{
+			tx, &TxRecord{Hash: tx.Hash, Type: Sent, Timestamp: timestamp},
+		})
+		if tx.To != "" {
+			records[tx.To] = append(records[tx.To], &addressTxRecord{
+				tx, &TxRecord{Hash: tx.Hash, Type: Received, Timestamp: timestamp},
+			})
+		}
+	}
+	return records
+}
+
+// addressTxRecord is a record of the transaction history of an address
+type addressTxRecord struct {
+	tx     *TransactionRecord
+	record *TxRecord
+}
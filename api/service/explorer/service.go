@@ -13,10 +13,11 @@ import (
 	"github.com/gorilla/mux"
 
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
-	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/common/denominations"
 	"github.com/harmony-one/harmony/internal/utils"
-	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/staking/network"
+	"github.com/pkg/errors"
 )
 
 // Constants for explorer service.
@@ -24,7 +25,8 @@ const (
 	explorerPortDifference = 4000
 	defaultPageSize        = "1000"
 	maxAddresses           = 100000
-	totalSupply            = 12600000000
+	defaultRecordsLimit    = 100
+	maxRecordsLimit        = 1000
 )
 
 // HTTPError is an HTTP error.
@@ -41,17 +43,18 @@ type Service struct {
 	Storage     *Storage
 	server      *http.Server
 	messageChan chan *msg_pb.Message
+	supply      func() (*network.Supply, error)
 }
 
-// New returns explorer service.
-func New(selfPeer *p2p.Peer) *Service {
-	return &Service{IP: selfPeer.IP, Port: selfPeer.Port}
+// New returns explorer service, serving the supply of ONE computed by supply.
+func New(selfPeer *p2p.Peer, supply func() (*network.Supply, error)) *Service {
+	return &Service{IP: selfPeer.IP, Port: selfPeer.Port, supply: supply}
 }
 
 // StartService starts explorer service.
 func (s *Service) StartService() {
 	utils.Logger().Info().Msg("Starting explorer service.")
-	s.Init(true)
+	s.Init()
 	s.server = s.Run()
 }
 
@@ -75,8 +78,8 @@ func GetExplorerPort(nodePort string) string {
 }
 
 // Init is to initialize for ExplorerService.
-func (s *Service) Init(remove bool) {
-	s.Storage = GetStorageInstance(s.IP, s.Port, remove)
+func (s *Service) Init() {
+	s.Storage = GetStorageInstance(s.IP, s.Port)
 }
 
 // Run is to run serving explorer.
@@ -92,6 +95,15 @@ func (s *Service) Run() *http.Server {
 	s.router.Path("/addresses").Queries("size", "{[0-9]*?}", "prefix", "{[a-zA-Z0-9]*?}").HandlerFunc(s.GetAddresses).Methods("GET")
 	s.router.Path("/addresses").HandlerFunc(s.GetAddresses)
 
+	// Set up routers for the records of the explorer database.
+	// The histories of the addresses accept the parameters offset and limit,
+	// and the transactions the parameters staking and order.
+	s.router.Path("/blocks/{number:[0-9]+}").HandlerFunc(s.GetBlock).Methods("GET")
+	s.router.Path("/transactions/{hash}").HandlerFunc(s.GetTransaction).Methods("GET")
+	s.router.Path("/addresses/{address}/transactions").HandlerFunc(s.GetAddressTransactions).Methods("GET")
+	s.router.Path("/addresses/{address}/transfers").HandlerFunc(s.GetAddressTransfers).Methods("GET")
+	s.router.Path("/addresses/{address}/staking-events").HandlerFunc(s.GetAddressStakingEvents).Methods("GET")
+
 	// Set up router for node count.
 	s.router.Path("/circulating-supply").Queries().HandlerFunc(s.GetCirculatingSupply).Methods("GET")
 	s.router.Path("/circulating-supply").HandlerFunc(s.GetCirculatingSupply)
@@ -145,12 +157,110 @@ func (s *Service) GetAddresses(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetBlock serves end-point /blocks/{number}, returns the block with its
+// transactions, transfers and staking events.
+func (s *Service) GetBlock(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.ParseUint(mux.Vars(r)["number"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	block, err := s.Storage.Backend().Block(number)
+	writeRecords(w, block, err)
+}
+
+// GetTransaction serves end-point /transactions/{hash}, returns the plain or
+// staking transaction of the hash.
+func (s *Service) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	tx, err := s.Storage.Backend().Transaction(mux.Vars(r)["hash"])
+	writeRecords(w, tx, err)
+}
+
+// GetAddressTransactions serves end-point /addresses/{address}/transactions,
+// returns the transactions sent and received by the address, the staking ones
+// if staking is true, in reverse block order unless order is ASC.
+func (s *Service) GetAddressTransactions(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	staking := r.FormValue("staking") == "true"
+	desc := r.FormValue("order") != "ASC"
+	records, err := s.Storage.Backend().AddressTransactions(
+		mux.Vars(r)["address"], staking, desc, offset, limit,
+	)
+	writeRecords(w, records, err)
+}
+
+// GetAddressTransfers serves end-point /addresses/{address}/transfers,
+// returns the transfers from and to the address in reverse block order.
+func (s *Service) GetAddressTransfers(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	transfers, err := s.Storage.Backend().AddressTransfers(mux.Vars(r)["address"], offset, limit)
+	writeRecords(w, transfers, err)
+}
+
+// GetAddressStakingEvents serves end-point /addresses/{address}/staking-events,
+// returns the staking events of the address as validator or delegator in
+// reverse block order.
+func (s *Service) GetAddressStakingEvents(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	events, err := s.Storage.Backend().AddressStakingEvents(mux.Vars(r)["address"], offset, limit)
+	writeRecords(w, events, err)
+}
+
+// pagination parses the offset and limit parameters of the request.
+func pagination(r *http.Request) (offset, limit int, err error) {
+	limit = defaultRecordsLimit
+	if value := r.FormValue("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, errors.Errorf("invalid offset %q", value)
+		}
+	}
+	if value := r.FormValue("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxRecordsLimit {
+			return 0, 0, errors.Errorf("invalid limit %q", value)
+		}
+	}
+	return offset, limit, nil
+}
+
+// writeRecords writes the JSON of the records read from the explorer
+// database, or the status of the error reading them.
+func writeRecords(w http.ResponseWriter, records interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if errors.Cause(err) == ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.Logger().Warn().Err(err).Msg("cannot read the explorer database")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		utils.Logger().Warn().Err(err).Msg("cannot JSON-encode explorer records")
+	}
+}
+
 // GetCirculatingSupply serves /circulating-supply end-point.
 func (s *Service) GetCirculatingSupply(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	timestamp := time.Now().Unix()
-	circulatingSupply := reward.PercentageForTimeStamp(timestamp).Mul(numeric.NewDec(totalSupply))
-	if err := json.NewEncoder(w).Encode(circulatingSupply); err != nil {
+	supply, err := s.supply()
+	if err != nil {
+		utils.Logger().Warn().Err(err).Msg("cannot compute circulating supply")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(supply.Circulating.QuoInt64(denominations.One)); err != nil {
 		utils.Logger().Warn().Msg("cannot JSON-encode circulating supply")
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -159,7 +269,13 @@ func (s *Service) GetCirculatingSupply(w http.ResponseWriter, r *http.Request) {
 // GetTotalSupply serves /total-supply end-point.
 func (s *Service) GetTotalSupply(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(totalSupply); err != nil {
+	supply, err := s.supply()
+	if err != nil {
+		utils.Logger().Warn().Err(err).Msg("cannot compute total supply")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(supply.Total.QuoInt64(denominations.One)); err != nil {
 		utils.Logger().Warn().Msg("cannot JSON-encode total supply")
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -1,242 +1,119 @@
 package explorer
 
 import (
-	"fmt"
-	"math/big"
-	"os"
 	"path"
 	"sync"
 
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/core/types"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/filter"
-	"github.com/syndtr/goleveldb/leveldb/opt"
-	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/pkg/errors"
 )
 
-// Constants for storage.
-const (
-	AddressPrefix    = "ad"
-	CheckpointPrefix = "dc"
-	PrefixLen        = 3
-)
-
-// GetAddressKey ...
-func GetAddressKey(address string) string {
-	return fmt.Sprintf("%s_%s", AddressPrefix, address)
-}
-
-// GetCheckpointKey ...
-func GetCheckpointKey(blockNum *big.Int) string {
-	return fmt.Sprintf("%s_%x", CheckpointPrefix, blockNum)
-}
+// backfillCursorInterval is the number of blocks the backfill indexes between
+// two records of its cursor
+const backfillCursorInterval = 100
 
 var storage *Storage
 var once sync.Once
 
-// Storage dump the block info into leveldb.
+// Storage indexes the blocks of the shard into the explorer database.
 type Storage struct {
-	db   *leveldb.DB
-	lock sync.Mutex
+	backend Backend
 }
 
-// GetStorageInstance returns attack model by using singleton pattern.
-func GetStorageInstance(ip, port string, remove bool) *Storage {
+// GetStorageInstance returns the storage of the explorer node by using
+// singleton pattern, opening the database of the explorer config the first
+// time.
+func GetStorageInstance(ip, port string) *Storage {
 	once.Do(func() {
 		storage = &Storage{}
-		storage.Init(ip, port, remove)
+		storage.Init(ip, port)
 	})
 	return storage
 }
 
-// Init initializes the block update.
-func (storage *Storage) Init(ip, port string, remove bool) {
-	dbFileName := path.Join(nodeconfig.GetDefaultConfig().DBDir, "explorer_storage_"+ip+"_"+port)
-	utils.Logger().Info().Msg("explorer storage folder: " + dbFileName)
-	var err error
-	if remove {
-		var err = os.RemoveAll(dbFileName)
-		if err != nil {
-			utils.Logger().Error().Err(err).Msg("Failed to remove existing database files")
-		}
-	}
-	// https://github.com/ethereum/go-ethereum/blob/master/ethdb/leveldb/leveldb.go#L98 options.
-	// We had 0 for handles and cache params before, so set 0s for all of them. Filter opt is the same.
-	options := &opt.Options{
-		OpenFilesCacheCapacity: 0,
-		BlockCacheCapacity:     0,
-		WriteBuffer:            0,
-		Filter:                 filter.NewBloomFilter(10),
+// Init opens the database of the explorer config, the badger database under
+// the db directory or the external postgres database.
+func (storage *Storage) Init(ip, port string) {
+	config := nodeconfig.GetExplorerDBConfig()
+	source := config.Source
+	if config.Kind == BadgerBackend {
+		source = path.Join(nodeconfig.GetDefaultConfig().DBDir, "explorer_db_"+ip+"_"+port)
+		utils.Logger().Info().Msg("explorer storage folder: " + source)
 	}
-	if storage.db, err = leveldb.OpenFile(dbFileName, options); err != nil {
-		utils.Logger().Error().Err(err).Msg("Failed to create new database")
+	var err error
+	if storage.backend, err = OpenBackend(config.Kind, source); err != nil {
+		utils.Logger().Error().Err(err).Msg("Failed to open the explorer database")
 	}
 }
 
-// GetDB returns the LDBDatabase of the storage.
-func (storage *Storage) GetDB() *leveldb.DB {
-	return storage.db
+// Backend returns the database of the storage the explorer queries are
+// answered from.
+func (storage *Storage) Backend() Backend {
+	return storage.backend
 }
 
-// Dump extracts information from block and index them into lvdb for explorer.
-func (storage *Storage) Dump(block *types.Block, height uint64) {
-	storage.lock.Lock()
-	defer storage.lock.Unlock()
+// Dump normalizes the block and writes its records into the explorer
+// database. Dumping a block again rewrites the same records.
+func (storage *Storage) Dump(block *types.Block) error {
 	if block == nil {
-		return
+		return nil
 	}
-
-	// Skip dump for redundant blocks with lower block number than the checkpoint block number
-	blockCheckpoint := GetCheckpointKey(block.Header().Number())
-	if _, err := storage.GetDB().Get([]byte(blockCheckpoint), nil); err == nil {
-		return
+	record, err := NewBlockRecord(block)
+	if err != nil {
+		return errors.Wrapf(err, "cannot normalize block %d", block.NumberU64())
 	}
-
-	acntsTxns, acntsStakingTxns := computeAccountsTransactionsMapForBlock(block)
-
-	for address, txRecords := range acntsTxns {
-		storage.UpdateTxAddressStorage(address, txRecords, false /* isStaking */)
-	}
-	for address, txRecords := range acntsStakingTxns {
-		storage.UpdateTxAddressStorage(address, txRecords, true /* isStaking */)
+	if err := storage.backend.WriteBlock(record); err != nil {
+		return errors.Wrapf(err, "cannot write block %d", block.NumberU64())
 	}
-
-	// save checkpoint of block dumped
-	storage.GetDB().Put([]byte(blockCheckpoint), []byte{}, nil)
+	return nil
 }
 
-// UpdateTxAddressStorage updates specific addr tx Address.
-func (storage *Storage) UpdateTxAddressStorage(addr string, txRecords TxRecords, isStaking bool) {
-	var address Address
-	key := GetAddressKey(addr)
-	if data, err := storage.GetDB().Get([]byte(key), nil); err == nil {
-		if err = rlp.DecodeBytes(data, &address); err != nil {
-			utils.Logger().Error().
-				Bool("isStaking", isStaking).Err(err).Msg("Failed due to error")
-		}
-	}
-
-	address.ID = addr
-	if isStaking {
-		address.StakingTXs = append(address.StakingTXs, txRecords...)
-	} else {
-		address.TXs = append(address.TXs, txRecords...)
-	}
-	encoded, err := rlp.EncodeToBytes(address)
-	if err == nil {
-		storage.GetDB().Put([]byte(key), encoded, nil)
-	} else {
-		utils.Logger().Error().
-			Bool("isStaking", isStaking).Err(err).Msg("cannot encode address")
-	}
+// ChainReader is the part of the blockchain the backfill reads the blocks of
+type ChainReader interface {
+	CurrentBlock() *types.Block
+	GetBlockByNumber(number uint64) *types.Block
 }
 
-// GetAddresses returns size of addresses from address with prefix.
-func (storage *Storage) GetAddresses(size int, prefix string) ([]string, error) {
-	db := storage.GetDB()
-	key := GetAddressKey(prefix)
-	iterator := db.NewIterator(&util.Range{Start: []byte(key)}, nil)
-	addresses := make([]string, 0)
-	read := 0
-	for iterator.Next() && read < size {
-		address := string(iterator.Key())
-		read++
-		if len(address) < PrefixLen {
-			utils.Logger().Info().Msgf("address len < 3 %s", address)
-			continue
-		}
-		addresses = append(addresses, address[PrefixLen:])
-	}
-	iterator.Release()
-	if err := iterator.Error(); err != nil {
-		utils.Logger().Error().Err(err).Msg("iterator error")
-		return nil, err
+// Backfill dumps the blocks of the chain from the backfill cursor up to the
+// head of the chain, recording the cursor as it goes so that the backfill of
+// a restarted node resumes from the last blocks it dumped. The backfill stops
+// at the first block it cannot dump, the cursor not moving past the block.
+func (storage *Storage) Backfill(chain ChainReader) error {
+	next, err := storage.backend.BackfillCursor()
+	if err != nil {
+		return errors.Wrap(err, "cannot read the backfill cursor")
 	}
-	return addresses, nil
-}
-
-func computeAccountsTransactionsMapForBlock(
-	block *types.Block,
-) (map[string]TxRecords, map[string]TxRecords) {
-	// mapping from account address to TxRecords for txns in the block
-	var acntsTxns map[string]TxRecords = make(map[string]TxRecords)
-	// mapping from account address to TxRecords for staking txns in the block
-	var acntsStakingTxns map[string]TxRecords = make(map[string]TxRecords)
-
-	// Store txs
-	for _, tx := range block.Transactions() {
-		explorerTransaction, err := GetTransaction(tx, block)
-		if err != nil {
-			utils.Logger().Error().Err(err).Str("txHash", tx.Hash().String()).
-				Msg("[Explorer Storage] Failed to get GetTransaction mapping")
-			continue
+	utils.Logger().Info().Uint64("from", next).
+		Uint64("to", chain.CurrentBlock().NumberU64()).
+		Msg("[Explorer Storage] backfilling")
+	for ; next <= chain.CurrentBlock().NumberU64(); next++ {
+		block := chain.GetBlockByNumber(next)
+		if block == nil {
+			return errors.Errorf("block %d not found", next)
 		}
-
-		// store as sent transaction with from address
-		txRecord := &TxRecord{
-			Hash:      explorerTransaction.ID,
-			Type:      Sent,
-			Timestamp: explorerTransaction.Timestamp,
-		}
-		acntTxns, ok := acntsTxns[explorerTransaction.From]
-		if !ok {
-			acntTxns = make(TxRecords, 0)
+		if err := storage.Dump(block); err != nil {
+			if cursorErr := storage.backend.SetBackfillCursor(next); cursorErr != nil {
+				utils.Logger().Error().Err(cursorErr).Uint64("next", next).
+					Msg("[Explorer Storage] cannot record the backfill cursor")
+			}
+			return err
 		}
-		acntTxns = append(acntTxns, txRecord)
-		acntsTxns[explorerTransaction.From] = acntTxns
-
-		// store as received transaction with to address
-		txRecord = &TxRecord{
-			Hash:      explorerTransaction.ID,
-			Type:      Received,
-			Timestamp: explorerTransaction.Timestamp,
+		if (next+1)%backfillCursorInterval == 0 {
+			if err := storage.backend.SetBackfillCursor(next + 1); err != nil {
+				return errors.Wrap(err, "cannot record the backfill cursor")
+			}
 		}
-		acntTxns, ok = acntsTxns[explorerTransaction.To]
-		if !ok {
-			acntTxns = make(TxRecords, 0)
-		}
-		acntTxns = append(acntTxns, txRecord)
-		acntsTxns[explorerTransaction.To] = acntTxns
 	}
-
-	// Store staking txns
-	for _, tx := range block.StakingTransactions() {
-		explorerTransaction, err := GetStakingTransaction(tx, block)
-		if err != nil {
-			utils.Logger().Error().Err(err).Str("txHash", tx.Hash().String()).
-				Msg("[Explorer Storage] Failed to get StakingTransaction mapping")
-			continue
-		}
-
-		// store as sent staking transaction with from address
-		txRecord := &TxRecord{
-			Hash:      explorerTransaction.ID,
-			Type:      Sent,
-			Timestamp: explorerTransaction.Timestamp,
-		}
-		acntStakingTxns, ok := acntsStakingTxns[explorerTransaction.From]
-		if !ok {
-			acntStakingTxns = make(TxRecords, 0)
-		}
-		acntStakingTxns = append(acntStakingTxns, txRecord)
-		acntsStakingTxns[explorerTransaction.From] = acntStakingTxns
-
-		// For delegate/undelegate, also store as received staking transaction with to address
-		txRecord = &TxRecord{
-			Hash:      explorerTransaction.ID,
-			Type:      Received,
-			Timestamp: explorerTransaction.Timestamp,
-		}
-		acntStakingTxns, ok = acntsStakingTxns[explorerTransaction.To]
-		if !ok {
-			acntStakingTxns = make(TxRecords, 0)
-		}
-		acntStakingTxns = append(acntStakingTxns, txRecord)
-		acntsStakingTxns[explorerTransaction.To] = acntStakingTxns
+	if err := storage.backend.SetBackfillCursor(next); err != nil {
+		return errors.Wrap(err, "cannot record the backfill cursor")
 	}
+	utils.Logger().Info().Uint64("next", next).Msg("[Explorer Storage] backfill done")
+	return nil
+}
 
-	return acntsTxns, acntsStakingTxns
+// GetAddresses returns size of addresses from address with prefix.
+func (storage *Storage) GetAddresses(size int, prefix string) ([]string, error) {
+	return storage.backend.Addresses(size, prefix)
 }
@@ -1,26 +1,204 @@
 package explorer
 
 import (
-	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+	common2 "github.com/harmony-one/harmony/internal/common"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
-// Test for GetAddressKey
+// Test for badgerAddressKey
 func TestGetAddressKey(t *testing.T) {
-	assert.Equal(t, GetAddressKey("abcd"), "ad_abcd", "error")
+	assert.Equal(t, "dabcd/", string(badgerAddressKey(badgerAddressPrefix, "abcd")), "error")
 }
 
 // TestInit ..
 func TestInit(t *testing.T) {
-	nodeconfig.GetDefaultConfig().DBDir = "/tmp"
-	ins := GetStorageInstance("1.1.1.1", "3333", true)
-	if err := ins.GetDB().Put([]byte{1}, []byte{2}, nil); err != nil {
-		t.Fatal("(*LDBDatabase).Put failed:", err)
+	dir, err := ioutil.TempDir("", "explorer")
+	if err != nil {
+		t.Fatal(err)
 	}
-	value, err := ins.GetDB().Get([]byte{1}, nil)
-	assert.Equal(t, bytes.Compare(value, []byte{2}), 0, "value should be []byte{2}")
+	defer os.RemoveAll(dir)
+	nodeconfig.GetDefaultConfig().DBDir = dir
+	ins := GetStorageInstance("1.1.1.1", "3333")
+	defer ins.Backend().Close()
+	assert.Equal(t, ins, GetStorageInstance("1.1.1.1", "3333"), "storage should be a singleton")
+	if err := ins.Backend().SetBackfillCursor(2); err != nil {
+		t.Fatal("SetBackfillCursor failed:", err)
+	}
+	next, err := ins.Backend().BackfillCursor()
+	assert.Equal(t, uint64(2), next, "cursor should be 2")
 	assert.Nil(t, err, "error should be nil")
 }
+
+func newTestStorage(t *testing.T) (*Storage, func()) {
+	dir, err := ioutil.TempDir("", "explorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeconfig.GetDefaultConfig().DBDir = dir
+	storage := &Storage{}
+	storage.Init("1.1.1.1", "3333")
+	if storage.Backend() == nil {
+		t.Fatal("cannot open the explorer database")
+	}
+	return storage, func() {
+		storage.Backend().Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// TestDump checks the records of a dumped block, and that dumping it again
+// rewrites the same records
+func TestDump(t *testing.T) {
+	storage, done := newTestStorage(t)
+	defer done()
+
+	key, _ := crypto.GenerateKey()
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	to := common.BytesToAddress([]byte{0x11})
+	txs := []*types.Transaction{}
+	for nonce, value := range []int64{100, 0} {
+		tx, err := types.SignTx(types.NewTransaction(
+			uint64(nonce), to, 0, big.NewInt(value), 21000, big.NewInt(1), nil,
+		), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+	}
+	header := blockfactory.NewTestHeader().With().Number(big.NewInt(314)).Header()
+	block := types.NewBlock(header, txs, types.Receipts{&types.Receipt{}, &types.Receipt{}}, nil, nil, nil)
+	from := common2.MustAddressToBech32(crypto.PubkeyToAddress(key.PublicKey))
+	recipient := common2.MustAddressToBech32(to)
+
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, storage.Dump(block))
+
+		record, err := storage.Backend().Block(314)
+		assert.Nil(t, err)
+		assert.Equal(t, block.Hash().Hex(), record.Hash)
+		assert.Len(t, record.Transactions, 2)
+
+		tx, err := storage.Backend().Transaction(txs[1].Hash().Hex())
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(1), tx.Index)
+		assert.Equal(t, recipient, tx.To)
+
+		sent, err := storage.Backend().AddressTransactions(from, false, true, 0, 0)
+		assert.Nil(t, err)
+		if assert.Len(t, sent, 2) {
+			assert.Equal(t, txs[1].Hash().Hex(), sent[0].Hash)
+			assert.Equal(t, Sent, sent[0].Type)
+		}
+		received, err := storage.Backend().AddressTransactions(recipient, false, false, 1, 1)
+		assert.Nil(t, err)
+		if assert.Len(t, received, 1) {
+			assert.Equal(t, txs[1].Hash().Hex(), received[0].Hash)
+			assert.Equal(t, Received, received[0].Type)
+		}
+
+		// the transaction without value transfers nothing
+		transfers, err := storage.Backend().AddressTransfers(recipient, 0, 0)
+		assert.Nil(t, err)
+		if assert.Len(t, transfers, 1) {
+			assert.Equal(t, big.NewInt(100), transfers[0].Value)
+		}
+
+		addresses, err := storage.GetAddresses(10, "")
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{from, recipient}, addresses)
+	}
+
+	_, err := storage.Backend().Block(315)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+// TestBackfillCursor checks the backfill cursor is recorded
+func TestBackfillCursor(t *testing.T) {
+	storage, done := newTestStorage(t)
+	defer done()
+
+	next, err := storage.Backend().BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), next)
+	assert.Nil(t, storage.Backend().SetBackfillCursor(1234))
+	next, err = storage.Backend().BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1234), next)
+}
+
+// testChain is a chain of empty blocks
+type testChain []*types.Block
+
+func newTestChain(length int) testChain {
+	chain := testChain{}
+	for i := 0; i < length; i++ {
+		header := blockfactory.NewTestHeader().With().Number(big.NewInt(int64(i))).Header()
+		chain = append(chain, types.NewBlock(header, nil, nil, nil, nil, nil))
+	}
+	return chain
+}
+
+func (c testChain) CurrentBlock() *types.Block {
+	return c[len(c)-1]
+}
+
+func (c testChain) GetBlockByNumber(number uint64) *types.Block {
+	if number >= uint64(len(c)) {
+		return nil
+	}
+	return c[number]
+}
+
+// failingBackend fails to write the block of the number
+type failingBackend struct {
+	Backend
+	fail uint64
+}
+
+func (b *failingBackend) WriteBlock(block *BlockRecord) error {
+	if block.Number == b.fail {
+		return errors.New("transient failure")
+	}
+	return b.Backend.WriteBlock(block)
+}
+
+// TestBackfill checks the backfill stops at a block it cannot write, and
+// resumes from the block
+func TestBackfill(t *testing.T) {
+	storage, done := newTestStorage(t)
+	defer done()
+	chain := newTestChain(2*backfillCursorInterval + 10)
+	backend := &failingBackend{Backend: storage.backend, fail: backfillCursorInterval + 5}
+	storage.backend = backend
+
+	err := storage.Backfill(chain)
+	assert.NotNil(t, err)
+	next, err := backend.BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, backend.fail, next)
+	_, err = backend.Block(backend.fail)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = backend.Block(backend.fail + 1)
+	assert.Equal(t, ErrNotFound, err)
+
+	backend.fail = uint64(len(chain))
+	assert.Nil(t, storage.Backfill(chain))
+	next, err = backend.BackfillCursor()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(chain)), next)
+	for _, block := range chain {
+		_, err := backend.Block(block.NumberU64())
+		assert.Nil(t, err)
+	}
+}
@@ -26,6 +26,10 @@ const (
 	Consensus
 	BlockProposal
 	NetworkInfo
+	Telemetry
+	CrossLinkRequest
+	BeaconLight
+	Profiler
 )
 
 func (t Type) String() string {
@@ -40,6 +44,14 @@ func (t Type) String() string {
 		return "BlockProposal"
 	case NetworkInfo:
 		return "NetworkInfo"
+	case Telemetry:
+		return "Telemetry"
+	case CrossLinkRequest:
+		return "CrossLinkRequest"
+	case BeaconLight:
+		return "BeaconLight"
+	case Profiler:
+		return "Profiler"
 	default:
 		return "Unknown"
 	}
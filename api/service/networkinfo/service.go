@@ -32,10 +32,13 @@ type Service struct {
 	stopChan    chan struct{}
 	stoppedChan chan struct{}
 	peerChan    chan p2p.Peer
-	peerInfo    <-chan libp2p_peer.AddrInfo
 	discovery   *libp2pdis.RoutingDiscovery
 	messageChan chan *msg_pb.Message
 	started     bool
+	epoch       func() uint64 // current epoch of the shard, nil to not discover by epoch
+	// epoch whose rendezvous is advertised, and the cancellation of its advertisement
+	advertisedEpoch uint64
+	cancelEpoch     context.CancelFunc
 }
 
 // ConnectionRetry set the number of retry of connection to bootnode in case the initial connection is failed
@@ -53,15 +56,19 @@ const (
 
 	// register to bootnode every ticker
 	dhtTicker = 6 * time.Hour
+	// check for a new epoch to advertise every ticker
+	epochTicker = time.Minute
 
 	discoveryLimit = 32
 )
 
 // New returns role conversion service.  If dataStorePath is not empty, it
-// points to a persistent database directory to use.
+// points to a persistent database directory to use.  If epoch is not nil, the
+// service also advertises and looks up the rendezvous of the current epoch of
+// the shard, so that the validators of the shard find each other directly.
 func New(
 	h p2p.Host, rendezvous nodeconfig.GroupID, peerChan chan p2p.Peer,
-	bootnodes p2p.AddrList, dataStorePath string,
+	bootnodes p2p.AddrList, dataStorePath string, epoch func() uint64,
 ) (*Service, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	var dhtOpts []libp2pdhtopts.Option
@@ -92,6 +99,7 @@ func New(
 		peerChan:    peerChan,
 		bootnodes:   bootnodes,
 		discovery:   nil,
+		epoch:       epoch,
 		started:     false,
 	}, nil
 }
@@ -99,9 +107,9 @@ func New(
 // MustNew is a panic-on-error version of New.
 func MustNew(
 	h p2p.Host, rendezvous nodeconfig.GroupID, peerChan chan p2p.Peer,
-	bootnodes p2p.AddrList, dataStorePath string,
+	bootnodes p2p.AddrList, dataStorePath string, epoch func() uint64,
 ) *Service {
-	service, err := New(h, rendezvous, peerChan, bootnodes, dataStorePath)
+	service, err := New(h, rendezvous, peerChan, bootnodes, dataStorePath, epoch)
 	if err != nil {
 		panic(err)
 	}
@@ -171,11 +179,41 @@ func (s *Service) Init() error {
 	// Everyone is beacon client, which means everyone is connected via beacon client topic
 	// 0 is beacon chain FIXME: use a constant
 	libp2pdis.Advertise(ctx, s.discovery, string(nodeconfig.NewClientGroupIDByShardID(0)))
+	s.advertiseEpoch()
 	utils.Logger().Info().Msg("Successfully announced!")
 
 	return nil
 }
 
+// epochRendezvous returns the rendezvous of the validators of the group in
+// the epoch
+func epochRendezvous(group nodeconfig.GroupID, epoch uint64) string {
+	return fmt.Sprintf("%s/epoch/%d", group, epoch)
+}
+
+// advertiseEpoch advertises the rendezvous of the current epoch once the
+// epoch changes, and stops advertising the one of the previous epoch.
+// It returns whether a new epoch is advertised.
+func (s *Service) advertiseEpoch() bool {
+	if s.epoch == nil {
+		return false
+	}
+	epoch := s.epoch()
+	if s.cancelEpoch != nil {
+		if epoch == s.advertisedEpoch {
+			return false
+		}
+		s.cancelEpoch()
+	}
+	var ctx context.Context
+	ctx, s.cancelEpoch = context.WithCancel(context.Background())
+	s.advertisedEpoch = epoch
+	rendezvous := epochRendezvous(s.Rendezvous, epoch)
+	libp2pdis.Advertise(ctx, s.discovery, rendezvous)
+	utils.Logger().Info().Str("Rendezvous", rendezvous).Msg("Announcing ourselves in epoch")
+	return true
+}
+
 // Run runs network info.
 func (s *Service) Run() {
 	defer close(s.stoppedChan)
@@ -193,6 +231,13 @@ func (s *Service) DoService() {
 	defer tick.Stop()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	epochTick := time.NewTicker(epochTicker)
+	defer epochTick.Stop()
+	defer func() {
+		if s.cancelEpoch != nil {
+			s.cancelEpoch()
+		}
+	}()
 	peerInterval := minFindPeerInterval
 	intervalTick := time.NewTicker(time.Duration(peerInterval) * time.Second)
 	defer intervalTick.Stop()
@@ -200,6 +245,13 @@ func (s *Service) DoService() {
 		select {
 		case <-s.stopChan:
 			return
+		case <-epochTick.C:
+			if s.advertiseEpoch() {
+				// the committee changed, look for the new validators of the shard
+				peerInterval = minFindPeerInterval
+				intervalTick.Stop()
+				intervalTick = time.NewTicker(time.Duration(peerInterval) * time.Second)
+			}
 		case <-tick.C:
 			var g sync.WaitGroup
 			g.Add(2) // 2 Advertise call
@@ -217,8 +269,18 @@ func (s *Service) DoService() {
 				Str("Rendezvous", string(s.Rendezvous)).
 				Msg("Successfully announced!")
 		case <-intervalTick.C:
-			var err error
-			s.peerInfo, err = s.discovery.FindPeers(
+			// the validators of the shard in the epoch are looked up first
+			if s.cancelEpoch != nil {
+				peerInfo, err := s.discovery.FindPeers(
+					ctx, epochRendezvous(s.Rendezvous, s.advertisedEpoch), coredis.Limit(discoveryLimit),
+				)
+				if err != nil {
+					utils.Logger().Error().Err(err).Msg("FindPeers")
+					return
+				}
+				go s.findPeers(ctx, peerInfo)
+			}
+			peerInfo, err := s.discovery.FindPeers(
 				ctx, string(s.Rendezvous), coredis.Limit(discoveryLimit),
 			)
 			if err != nil {
@@ -231,18 +293,18 @@ func (s *Service) DoService() {
 				intervalTick = time.NewTicker(time.Duration(peerInterval) * time.Second)
 			}
 
-			go s.findPeers(ctx)
+			go s.findPeers(ctx, peerInfo)
 		}
 	}
 }
 
-func (s *Service) findPeers(ctx context.Context) {
+func (s *Service) findPeers(ctx context.Context, peerInfo <-chan libp2p_peer.AddrInfo) {
 	_, cgnPrefix, err := net.ParseCIDR("100.64.0.0/10")
 	if err != nil {
 		utils.Logger().Error().Err(err).Msg("can't parse CIDR")
 		return
 	}
-	for peer := range s.peerInfo {
+	for peer := range peerInfo {
 		if peer.ID != s.Host.GetP2PHost().ID() && len(peer.ID) > 0 {
 			if err := s.Host.GetP2PHost().Connect(ctx, peer); err != nil {
 				utils.Logger().Warn().Err(err).Interface("peer", peer).Msg("can't connect to peer node")
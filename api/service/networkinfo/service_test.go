@@ -26,7 +26,7 @@ func TestService(t *testing.T) {
 		t.Fatal("unable to new host in harmony")
 	}
 
-	s, err := New(host, nodeconfig.GroupIDBeaconClient, nil, nil, "")
+	s, err := New(host, nodeconfig.GroupIDBeaconClient, nil, nil, "", nil)
 	if err != nil {
 		t.Fatalf("New() failed: %s", err)
 	}
@@ -35,3 +35,16 @@ func TestService(t *testing.T) {
 	time.Sleep(2 * time.Second)
 	s.StopService()
 }
+
+func TestEpochRendezvous(t *testing.T) {
+	group := nodeconfig.NewGroupIDByShardID(1)
+	if epochRendezvous(group, 5) == epochRendezvous(group, 6) {
+		t.Error("epochs share a rendezvous")
+	}
+	if epochRendezvous(group, 5) == epochRendezvous(nodeconfig.NewGroupIDByShardID(2), 5) {
+		t.Error("shards share a rendezvous")
+	}
+	if got, want := epochRendezvous(group, 5), string(group)+"/epoch/5"; got != want {
+		t.Errorf("got rendezvous %s, expect %s", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package profiler
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Profiles the service captures
+const (
+	CPU       = "cpu"
+	Heap      = "heap"
+	Goroutine = "goroutine"
+)
+
+// Profiles are the profiles the service can capture
+var Profiles = []string{CPU, Heap, Goroutine}
+
+// uploadTimeout bounds the delivery of a profile to the endpoint
+const uploadTimeout = time.Minute
+
+// profileExt is the extension of the gzipped protobuf profiles written to the
+// directory
+const profileExt = ".pb.gz"
+
+var profilesCounter = metrics.DefaultRegistry.NewCounter(
+	"profiler_profiles_total", "Profiles captured by the profiler by type and result", "type", "result",
+)
+
+// Service periodically captures the CPU, heap and goroutine profiles of the
+// node, and posts them to the endpoint and writes them to the directory of its
+// config, keeping the latest ones of each type there. The profiles are in the
+// gzipped protobuf format of pprof. They are posted with the type, the capture
+// time, the host and the shard of the node in the X-Profile-Type,
+// X-Profile-Time, X-Profile-Host and X-Profile-Shard headers.
+type Service struct {
+	config      nodeconfig.ProfilerConfig
+	shardID     uint32
+	host        string
+	client      *http.Client
+	stopChan    chan struct{}
+	stoppedChan chan struct{}
+	messageChan chan *msg_pb.Message
+}
+
+// New returns a profiler service of the node of the shard
+func New(config nodeconfig.ProfilerConfig, shardID uint32) *Service {
+	host, _ := os.Hostname()
+	return &Service{
+		config:  config,
+		shardID: shardID,
+		host:    host,
+		client:  &http.Client{Timeout: uploadTimeout},
+	}
+}
+
+// StartService starts the profiler service.
+func (s *Service) StartService() {
+	utils.Logger().Info().
+		Strs("profiles", s.config.Profiles).
+		Str("endpoint", s.config.Endpoint).
+		Str("dir", s.config.Dir).
+		Dur("interval", s.config.Interval).
+		Msg("Starting profiler service")
+	s.stopChan = make(chan struct{})
+	s.stoppedChan = make(chan struct{})
+	go s.Run()
+}
+
+// Run captures the profiles at every interval until the service is stopped.
+func (s *Service) Run() {
+	defer close(s.stoppedChan)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.captureAll()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// captureAll captures and ships each profile of the config
+func (s *Service) captureAll() {
+	for _, profile := range s.config.Profiles {
+		now := time.Now().UTC()
+		data, err := s.capture(profile)
+		if err == nil {
+			err = s.ship(profile, now, data)
+		}
+		if err != nil {
+			profilesCounter.Inc(profile, "failed")
+			utils.Logger().Warn().Err(err).Str("profile", profile).Msg("[Profiler] cannot ship profile")
+			continue
+		}
+		profilesCounter.Inc(profile, "shipped")
+	}
+}
+
+// capture returns the profile in the gzipped protobuf format, the CPU profile
+// being captured for the CPU duration of the config, or until the service is
+// stopped
+func (s *Service) capture(profile string) ([]byte, error) {
+	buf := bytes.Buffer{}
+	switch profile {
+	case CPU:
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			// another CPU profile, such as one of the pprof server, is running
+			return nil, errors.Wrap(err, "cannot start the CPU profile")
+		}
+		select {
+		case <-time.After(s.config.CPUDuration):
+		case <-s.stopChan:
+		}
+		pprof.StopCPUProfile()
+	case Heap, Goroutine:
+		if err := pprof.Lookup(profile).WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown profile %q", profile)
+	}
+	return buf.Bytes(), nil
+}
+
+// ship posts the profile to the endpoint and writes it to the directory
+func (s *Service) ship(profile string, captured time.Time, data []byte) error {
+	if s.config.Dir != "" {
+		if err := s.write(profile, captured, data); err != nil {
+			return err
+		}
+	}
+	if s.config.Endpoint != "" {
+		if err := s.post(profile, captured, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the profile into the directory, and deletes the oldest
+// profiles of its type over the number kept
+func (s *Service) write(profile string, captured time.Time, data []byte) error {
+	if err := os.MkdirAll(s.config.Dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s%s", profile, captured.Format("20060102T150405.000000000Z"), profileExt)
+	if err := ioutil.WriteFile(filepath.Join(s.config.Dir, name), data, 0644); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(s.config.Dir, profile+"-*"+profileExt))
+	if err != nil {
+		return err
+	}
+	// the names sort by the capture time
+	sort.Strings(files)
+	for len(files) > s.config.Keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// post posts the profile to the endpoint
+func (s *Service) post(profile string, captured time.Time, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.config.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Profile-Type", profile)
+	req.Header.Set("X-Profile-Time", captured.Format(time.RFC3339))
+	req.Header.Set("X-Profile-Host", s.host)
+	req.Header.Set("X-Profile-Shard", strconv.FormatUint(uint64(s.shardID), 10))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "cannot post profile")
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("endpoint replied %s", resp.Status)
+	}
+	return nil
+}
+
+// StopService stops the profiler service.
+func (s *Service) StopService() {
+	utils.Logger().Info().Msg("Stopping profiler service.")
+	close(s.stopChan)
+	<-s.stoppedChan
+}
+
+// NotifyService notify service
+func (s *Service) NotifyService(params map[string]interface{}) {}
+
+// SetMessageChan sets up message channel to service.
+func (s *Service) SetMessageChan(messageChan chan *msg_pb.Message) {
+	s.messageChan = messageChan
+}
+
+// APIs for the services.
+func (s *Service) APIs() []rpc.API {
+	return nil
+}
@@ -0,0 +1,67 @@
+package profiler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+func TestServicePostsProfiles(t *testing.T) {
+	profiles := make(chan string, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if len(data) == 0 || r.Header.Get("X-Profile-Shard") != "1" || r.Header.Get("X-Profile-Time") == "" {
+			t.Errorf("got a profile of %d bytes with headers %v", len(data), r.Header)
+		}
+		profiles <- r.Header.Get("X-Profile-Type")
+	}))
+	defer server.Close()
+
+	s := New(nodeconfig.ProfilerConfig{
+		Interval:    10 * time.Millisecond,
+		CPUDuration: 10 * time.Millisecond,
+		Profiles:    Profiles,
+		Endpoint:    server.URL,
+	}, 1)
+	s.StartService()
+	defer s.StopService()
+	for _, expect := range Profiles {
+		select {
+		case got := <-profiles:
+			if got != expect {
+				t.Errorf("got profile %q, expect %q", got, expect)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s profile received", expect)
+		}
+	}
+}
+
+func TestServiceRotatesProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := New(nodeconfig.ProfilerConfig{
+		Profiles: []string{Heap, Goroutine},
+		Dir:      dir,
+		Keep:     2,
+	}, 0)
+	for i := 0; i < 3; i++ {
+		s.captureAll()
+	}
+	for _, profile := range []string{Heap, Goroutine} {
+		files, _ := filepath.Glob(filepath.Join(dir, profile+"-*"+profileExt))
+		if len(files) != 2 {
+			t.Errorf("got %d %s profiles, expect 2", len(files), profile)
+		}
+	}
+}
@@ -6,20 +6,25 @@ import (
 	"time"
 
 	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"google.golang.org/grpc"
 )
 
+// stakingMetaMaxSize is the maximum size of a staking meta response
+const stakingMetaMaxSize = 256 * 1024 * 1024
+
 // Client is the client model for downloader package.
 type Client struct {
 	dlClient pb.DownloaderClient
 	opts     []grpc.DialOption
-	conn     *grpc.ClientConn
+	conn     *grpc.ClientConn // nil for clients over libp2p streams
+	target   string
 }
 
 // ClientSetup setups a Client given ip and port.
 func ClientSetup(ip, port string) *Client {
-	client := Client{}
+	client := Client{target: ip + ":" + port}
 	client.opts = append(client.opts, grpc.WithInsecure())
 	var err error
 	client.conn, err = grpc.Dial(fmt.Sprintf(ip+":"+port), client.opts...)
@@ -28,12 +33,19 @@ func ClientSetup(ip, port string) *Client {
 		return nil
 	}
 	utils.Logger().Debug().Str("ip", ip).Msg("[SYNC] grpc connect successfully")
-	client.dlClient = pb.NewDownloaderClient(client.conn)
+	dlClient := pb.NewDownloaderClient(client.conn)
+	if nodeconfig.GetSyncCompress() {
+		dlClient = newCompressedClient(dlClient)
+	}
+	client.dlClient = newThrottledClient(dlClient)
 	return &client
 }
 
 // Close closes the Client.
 func (client *Client) Close() {
+	if client.conn == nil {
+		return
+	}
 	err := client.conn.Close()
 	if err != nil {
 		utils.Logger().Info().Msg("[SYNC] unable to close connection")
@@ -49,7 +61,7 @@ func (client *Client) GetBlockHashes(startHash []byte, size uint32, ip, port str
 	request.Port = port
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] GetBlockHashes query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] GetBlockHashes query failed")
 	}
 	return response
 }
@@ -66,7 +78,7 @@ func (client *Client) GetBlockHeaders(hashes [][]byte) *pb.DownloaderResponse {
 	}
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] downloader/client.go:GetBlockHeaders query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetBlockHeaders query failed")
 	}
 	return response
 }
@@ -83,7 +95,7 @@ func (client *Client) GetBlocks(hashes [][]byte) *pb.DownloaderResponse {
 	}
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] downloader/client.go:GetBlocks query failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetBlocks query failed")
 	}
 	return response
 }
@@ -100,7 +112,7 @@ func (client *Client) Register(hash []byte, ip, port string) *pb.DownloaderRespo
 	request.Port = port
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil || response == nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Interface("response", response).Msg("[SYNC] client.go:Register failed")
+		utils.Logger().Error().Err(err).Str("target", client.target).Interface("response", response).Msg("[SYNC] client.go:Register failed")
 	}
 	return response
 }
@@ -122,7 +134,7 @@ func (client *Client) PushNewBlock(selfPeerHash [20]byte, blockHash []byte, time
 
 	response, err := client.dlClient.Query(ctx, request)
 	if err != nil {
-		utils.Logger().Error().Err(err).Str("target", client.conn.Target()).Msg("[SYNC] unable to send new block to unsync node")
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] unable to send new block to unsync node")
 	}
 	return response, err
 }
@@ -138,3 +150,52 @@ func (client *Client) GetBlockChainHeight() (*pb.DownloaderResponse, error) {
 	}
 	return response, nil
 }
+
+// GetStateRange gets a range of the leaves of the state trie with the given root, starting at origin.
+func (client *Client) GetStateRange(root, origin []byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_STATERANGE, Root: root, Origin: origin}
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetStateRange query failed")
+	}
+	return response
+}
+
+// GetByteCodes gets the contract codes with the given hashes.
+func (client *Client) GetByteCodes(hashes [][]byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_BYTECODES, Hashes: hashes}
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetByteCodes query failed")
+	}
+	return response
+}
+
+// GetReceipts gets the receipts of the blocks with the given hashes in serialization byte array.
+func (client *Client) GetReceipts(hashes [][]byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_RECEIPTS, Hashes: hashes}
+	response, err := client.dlClient.Query(ctx, request)
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetReceipts query failed")
+	}
+	return response
+}
+
+// GetStakingMeta gets the off-chain staking data of the snap sync pivot block with the given hash.
+func (client *Client) GetStakingMeta(blockHash []byte) *pb.DownloaderResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	request := &pb.DownloaderRequest{Type: pb.DownloaderRequest_STAKINGMETA, BlockHash: blockHash}
+	// the delegations of all validators exceed the default message size limit
+	response, err := client.dlClient.Query(ctx, request, grpc.MaxCallRecvMsgSize(stakingMetaMaxSize))
+	if err != nil {
+		utils.Logger().Error().Err(err).Str("target", client.target).Msg("[SYNC] downloader/client.go:GetStakingMeta query failed")
+	}
+	return response
+}
@@ -0,0 +1,180 @@
+package downloader
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+)
+
+// Errors of the sync requests refused over the caps
+var (
+	ErrClientRateLimited = errors.New("sync request rate of the client over the cap")
+	ErrClientConcurrency = errors.New("concurrent sync requests of the client over the cap")
+	ErrServerBusy        = errors.New("concurrent sync requests over the cap")
+)
+
+// clients idle for longer are forgotten by the limiter
+const clientIdleTimeout = time.Minute
+
+// Limiter caps the sync requests served per second and concurrently to each
+// client, and concurrently to all clients. The requests over the caps are
+// refused, so that a few aggressively syncing clients cannot saturate the
+// node.
+type Limiter struct {
+	lock      sync.Mutex
+	limits    nodeconfig.SyncServerLimits
+	clients   map[string]*clientLimit
+	inFlight  int
+	lastSweep time.Time
+}
+
+type clientLimit struct {
+	rate     *Throttle
+	inFlight int
+	last     time.Time
+}
+
+// NewLimiter returns the limiter of the sync requests with the given caps
+func NewLimiter(limits nodeconfig.SyncServerLimits) *Limiter {
+	return &Limiter{limits: limits, clients: map[string]*clientLimit{}, lastSweep: time.Now()}
+}
+
+// SetLimits changes the caps of the requests, the requests in flight are
+// kept
+func (l *Limiter) SetLimits(limits nodeconfig.SyncServerLimits) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.limits = limits
+	for _, c := range l.clients {
+		c.rate.SetRate(limits.ClientRate)
+	}
+}
+
+// Acquire admits a request of the client, and returns the function to call
+// once the request is served
+func (l *Limiter) Acquire(client string) (func(), error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := time.Now()
+	if now.Sub(l.lastSweep) > clientIdleTimeout {
+		for key, c := range l.clients {
+			if c.inFlight == 0 && now.Sub(c.last) > clientIdleTimeout {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+	if l.limits.MaxConcurrency > 0 && l.inFlight >= l.limits.MaxConcurrency {
+		return nil, ErrServerBusy
+	}
+	c, ok := l.clients[client]
+	if !ok {
+		c = &clientLimit{rate: NewThrottle(l.limits.ClientRate)}
+		l.clients[client] = c
+	}
+	c.last = now
+	if l.limits.ClientConcurrency > 0 && c.inFlight >= l.limits.ClientConcurrency {
+		return nil, ErrClientConcurrency
+	}
+	if !c.rate.allow(1, now) {
+		return nil, ErrClientRateLimited
+	}
+	c.inFlight++
+	l.inFlight++
+	return func() {
+		l.lock.Lock()
+		c.inFlight--
+		l.inFlight--
+		l.lock.Unlock()
+	}, nil
+}
+
+// The limiter of the requests served by the node, configured once from
+// nodeconfig.GetSyncServerLimits
+var (
+	limiterOnce   sync.Once
+	serverLimiter *Limiter
+)
+
+func syncLimiter() *Limiter {
+	limiterOnce.Do(func() {
+		serverLimiter = NewLimiter(nodeconfig.GetSyncServerLimits())
+	})
+	return serverLimiter
+}
+
+// ReloadLimits applies the sync bandwidth caps and the sync server limits of
+// nodeconfig to the running sync servers and clients
+func ReloadLimits() {
+	upload, download := syncThrottles()
+	bandwidth := nodeconfig.GetSyncBandwidth()
+	upload.SetRate(bandwidth.Upload)
+	download.SetRate(bandwidth.Download)
+	syncLimiter().SetLimits(nodeconfig.GetSyncServerLimits())
+}
+
+// clientHost returns the host of a grpc peer address, the port of a client
+// changes with its connections
+func clientHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// refuseCompressed refuses the compressed requests of the grpc sync protocol
+// when the node does not compress its responses, which are compressed like
+// the requests. The clients retry without compression.
+func refuseCompressed(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if stream, ok := grpc.ServerTransportStreamFromContext(ctx).(interface{ RecvCompress() string }); ok {
+		if rc := stream.RecvCompress(); rc != "" && rc != encoding.Identity {
+			return nil, status.Errorf(codes.Unimplemented, "sync response compression disabled")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// compressedClient requests gzip compressed responses, until the server
+// refuses compression
+type compressedClient struct {
+	pb.DownloaderClient
+	lock     sync.Mutex
+	compress bool
+}
+
+func newCompressedClient(client pb.DownloaderClient) pb.DownloaderClient {
+	return &compressedClient{DownloaderClient: client, compress: true}
+}
+
+func (c *compressedClient) Query(
+	ctx context.Context, request *pb.DownloaderRequest, opts ...grpc.CallOption,
+) (*pb.DownloaderResponse, error) {
+	c.lock.Lock()
+	compress := c.compress
+	c.lock.Unlock()
+	if !compress {
+		return c.DownloaderClient.Query(ctx, request, opts...)
+	}
+	response, err := c.DownloaderClient.Query(
+		ctx, request, append(opts, grpc.UseCompressor(gzip.Name))...,
+	)
+	if status.Code(err) != codes.Unimplemented {
+		return response, err
+	}
+	c.lock.Lock()
+	c.compress = false
+	c.lock.Unlock()
+	return c.DownloaderClient.Query(ctx, request, opts...)
+}
@@ -0,0 +1,119 @@
+package downloader
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"google.golang.org/grpc"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(nodeconfig.SyncServerLimits{
+		ClientRate: 3, ClientConcurrency: 2, MaxConcurrency: 3,
+	})
+	release1, err := limiter.Acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release2, err := limiter.Acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.Acquire("a"); err != ErrClientConcurrency {
+		t.Errorf("got %v, expect %v", err, ErrClientConcurrency)
+	}
+	release3, err := limiter.Acquire("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.Acquire("c"); err != ErrServerBusy {
+		t.Errorf("got %v, expect %v", err, ErrServerBusy)
+	}
+	release1()
+	release2()
+	release3()
+
+	// the third request of a took its last token
+	release, err := limiter.Acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if _, err := limiter.Acquire("a"); err != ErrClientRateLimited {
+		t.Errorf("got %v, expect %v", err, ErrClientRateLimited)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if release, err = limiter.Acquire("a"); err != nil {
+		t.Errorf("request refused after the rate refilled: %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestLimiterSetLimits(t *testing.T) {
+	limiter := NewLimiter(nodeconfig.SyncServerLimits{ClientRate: 1, MaxConcurrency: 1})
+	release, err := limiter.Acquire("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.Acquire("b"); err != ErrServerBusy {
+		t.Errorf("got %v, expect %v", err, ErrServerBusy)
+	}
+	limiter.SetLimits(nodeconfig.SyncServerLimits{MaxConcurrency: 2})
+	if _, err := limiter.Acquire("b"); err != nil {
+		t.Errorf("request refused under the raised cap: %v", err)
+	}
+	release()
+	// the rate of a is no longer capped
+	if _, err := limiter.Acquire("a"); err != nil {
+		t.Errorf("request refused without a rate cap: %v", err)
+	}
+}
+
+// startTestServer serves the downloader requests over grpc with the sync
+// compression configured as compress
+func startTestServer(t *testing.T, compress bool) (pb.DownloaderClient, func()) {
+	nodeconfig.SetSyncCompress(compress)
+	defer nodeconfig.SetSyncCompress(false)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(serverOptions()...)
+	pb.RegisterDownloaderServer(server, NewServer(testDownloadInterface{}))
+	go server.Serve(listener)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb.NewDownloaderClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestCompressedClient(t *testing.T) {
+	for _, compress := range []bool{true, false} {
+		conn, stop := startTestServer(t, compress)
+		client := newCompressedClient(conn).(*compressedClient)
+		for i := 0; i < 2; i++ {
+			response, err := client.Query(
+				context.Background(), &pb.DownloaderRequest{Type: pb.DownloaderRequest_BLOCKHEIGHT},
+			)
+			if err != nil {
+				t.Fatalf("compress %v: %v", compress, err)
+			}
+			if response.BlockHeight != 42 {
+				t.Errorf("got block height %d, expect 42", response.BlockHeight)
+			}
+		}
+		if client.compress != compress {
+			t.Errorf("server compress %v: client compresses %v", compress, client.compress)
+		}
+		stop()
+	}
+}
@@ -35,17 +35,25 @@ const (
 	DownloaderRequest_REGISTERTIMEOUT DownloaderRequest_RequestType = 5
 	DownloaderRequest_UNKNOWN         DownloaderRequest_RequestType = 6
 	DownloaderRequest_BLOCKHEADER     DownloaderRequest_RequestType = 7
+	DownloaderRequest_STATERANGE      DownloaderRequest_RequestType = 8
+	DownloaderRequest_BYTECODES       DownloaderRequest_RequestType = 9
+	DownloaderRequest_RECEIPTS        DownloaderRequest_RequestType = 10
+	DownloaderRequest_STAKINGMETA     DownloaderRequest_RequestType = 11
 )
 
 var DownloaderRequest_RequestType_name = map[int32]string{
-	0: "BLOCKHASH",
-	1: "BLOCK",
-	2: "NEWBLOCK",
-	3: "BLOCKHEIGHT",
-	4: "REGISTER",
-	5: "REGISTERTIMEOUT",
-	6: "UNKNOWN",
-	7: "BLOCKHEADER",
+	0:  "BLOCKHASH",
+	1:  "BLOCK",
+	2:  "NEWBLOCK",
+	3:  "BLOCKHEIGHT",
+	4:  "REGISTER",
+	5:  "REGISTERTIMEOUT",
+	6:  "UNKNOWN",
+	7:  "BLOCKHEADER",
+	8:  "STATERANGE",
+	9:  "BYTECODES",
+	10: "RECEIPTS",
+	11: "STAKINGMETA",
 }
 
 var DownloaderRequest_RequestType_value = map[string]int32{
@@ -57,6 +65,10 @@ var DownloaderRequest_RequestType_value = map[string]int32{
 	"REGISTERTIMEOUT": 5,
 	"UNKNOWN":         6,
 	"BLOCKHEADER":     7,
+	"STATERANGE":      8,
+	"BYTECODES":       9,
+	"RECEIPTS":        10,
+	"STAKINGMETA":     11,
 }
 
 func (x DownloaderRequest_RequestType) String() string {
@@ -106,6 +118,8 @@ type DownloaderRequest struct {
 	Ip                   string   `protobuf:"bytes,5,opt,name=ip,proto3" json:"ip,omitempty"`
 	Port                 string   `protobuf:"bytes,6,opt,name=port,proto3" json:"port,omitempty"`
 	Size                 uint32   `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	Root                 []byte   `protobuf:"bytes,8,opt,name=root,proto3" json:"root,omitempty"`
+	Origin               []byte   `protobuf:"bytes,9,opt,name=origin,proto3" json:"origin,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -185,6 +199,20 @@ func (m *DownloaderRequest) GetSize() uint32 {
 	return 0
 }
 
+func (m *DownloaderRequest) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *DownloaderRequest) GetOrigin() []byte {
+	if m != nil {
+		return m.Origin
+	}
+	return nil
+}
+
 // DownloaderResponse is the generic response of DownloaderRequest.
 type DownloaderResponse struct {
 	// payload of Block.
@@ -255,33 +283,37 @@ func init() {
 }
 
 var fileDescriptor_6a99ec95c7ab1ff1 = []byte{
-	// 410 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x52, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xce, 0x3a, 0x8e, 0x93, 0x4c, 0xd2, 0x76, 0x19, 0x10, 0x5a, 0x55, 0x80, 0x2c, 0x9f, 0xcc,
-	0xc5, 0x87, 0xf6, 0xc4, 0x81, 0x43, 0x70, 0x4d, 0x6c, 0xb5, 0x38, 0x62, 0xed, 0x50, 0x71, 0x74,
-	0xe9, 0xaa, 0xb6, 0xa8, 0xea, 0xc5, 0xeb, 0x0a, 0x99, 0x17, 0xe0, 0x05, 0x79, 0x06, 0x9e, 0x03,
-	0x79, 0x9d, 0xd6, 0x96, 0x80, 0x9c, 0x76, 0xbf, 0x6f, 0x7e, 0x34, 0xf3, 0x7d, 0x03, 0xf4, 0xba,
-	0xfc, 0x7e, 0x77, 0x5b, 0x66, 0xd7, 0xa2, 0xf2, 0x64, 0x55, 0xd6, 0x25, 0x42, 0xcf, 0x38, 0xbf,
-	0x0d, 0x78, 0x72, 0xf6, 0x08, 0xb9, 0xf8, 0x76, 0x2f, 0x54, 0x8d, 0x6f, 0xc1, 0xac, 0x1b, 0x29,
-	0x18, 0xb1, 0x89, 0x7b, 0x78, 0xf2, 0xda, 0x1b, 0xb4, 0xf8, 0x2b, 0xd9, 0xdb, 0xbd, 0x69, 0x23,
-	0x05, 0xd7, 0x65, 0xf8, 0x1c, 0xac, 0x3c, 0x53, 0xb9, 0x50, 0xcc, 0xb0, 0xc7, 0xee, 0x92, 0xef,
-	0x10, 0x1e, 0xc3, 0x4c, 0x0a, 0x51, 0x85, 0x99, 0xca, 0xd9, 0xd8, 0x26, 0xee, 0x92, 0x3f, 0x62,
-	0x7c, 0x01, 0xf3, 0xab, 0xdb, 0xf2, 0xcb, 0x57, 0x1d, 0x34, 0x75, 0xb0, 0x27, 0xf0, 0x10, 0x8c,
-	0x42, 0xb2, 0x89, 0x4d, 0xdc, 0x39, 0x37, 0x0a, 0x89, 0x08, 0xa6, 0x2c, 0xab, 0x9a, 0x59, 0x9a,
-	0xd1, 0xff, 0x96, 0x53, 0xc5, 0x0f, 0xc1, 0xa6, 0x36, 0x71, 0x0f, 0xb8, 0xfe, 0x3b, 0x3f, 0x09,
-	0x2c, 0x06, 0xf3, 0xe1, 0x01, 0xcc, 0xdf, 0x5d, 0x6c, 0xfc, 0xf3, 0x70, 0x95, 0x84, 0x74, 0x84,
-	0x73, 0x98, 0x68, 0x48, 0x09, 0x2e, 0x61, 0x16, 0x07, 0x97, 0x1d, 0x32, 0xf0, 0x08, 0x16, 0x5d,
-	0x5e, 0x10, 0xad, 0xc3, 0x94, 0x8e, 0xdb, 0x30, 0x0f, 0xd6, 0x51, 0x92, 0x06, 0x9c, 0x9a, 0xf8,
-	0x14, 0x8e, 0x1e, 0x50, 0x1a, 0x7d, 0x08, 0x36, 0xdb, 0x94, 0x4e, 0x70, 0x01, 0xd3, 0x6d, 0x7c,
-	0x1e, 0x6f, 0x2e, 0x63, 0x6a, 0x0d, 0x1a, 0xac, 0xce, 0x02, 0x4e, 0xa7, 0xce, 0x2f, 0x02, 0x38,
-	0xd4, 0x4e, 0xc9, 0xf2, 0x4e, 0x09, 0x64, 0x30, 0x95, 0x59, 0xd3, 0x92, 0x8c, 0x68, 0xad, 0x1e,
-	0x20, 0xae, 0x77, 0x1e, 0x18, 0xda, 0x83, 0xd3, 0xff, 0x79, 0xd0, 0xf5, 0xf1, 0xb8, 0xb8, 0x29,
-	0x54, 0xdd, 0x13, 0x03, 0x37, 0x6c, 0x58, 0x74, 0x42, 0x8a, 0xe2, 0x26, 0xaf, 0xb5, 0xf0, 0x26,
-	0x1f, 0x52, 0xce, 0x1b, 0x78, 0xf6, 0xaf, 0xfa, 0x76, 0xa3, 0x64, 0xeb, 0xfb, 0x41, 0x92, 0xd0,
-	0x11, 0xce, 0xc0, 0x7c, 0xbf, 0x8a, 0x2e, 0x28, 0x41, 0x00, 0x2b, 0x8a, 0x93, 0xcf, 0xb1, 0x4f,
-	0x8d, 0x93, 0x4f, 0x00, 0xfd, 0x34, 0x18, 0xc2, 0xe4, 0xe3, 0xbd, 0xa8, 0x1a, 0x7c, 0xb9, 0xf7,
-	0x64, 0x8e, 0x5f, 0xed, 0xdf, 0xc6, 0x19, 0x5d, 0x59, 0xfa, 0x54, 0x4f, 0xff, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0x00, 0xaf, 0x33, 0x33, 0xbe, 0x02, 0x00, 0x00,
+	// 468 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x93, 0x4f, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0xb3, 0x8e, 0xf3, 0xc7, 0x93, 0x34, 0x5d, 0x06, 0x84, 0x56, 0x15, 0x20, 0x2b, 0xa7,
+	0x70, 0xc9, 0xa1, 0x3d, 0x71, 0xe0, 0x60, 0x9c, 0x25, 0xb1, 0xd2, 0x3a, 0xb0, 0xbb, 0xa1, 0xea,
+	0x31, 0xa5, 0xab, 0xc4, 0xa2, 0xca, 0x1a, 0xdb, 0x15, 0x0a, 0xdf, 0x8f, 0x3b, 0x07, 0x3e, 0x10,
+	0xf2, 0x3a, 0x6d, 0x2c, 0x41, 0x7b, 0xf2, 0xbc, 0xdf, 0xec, 0x3c, 0xcd, 0xfa, 0xd9, 0x40, 0x6f,
+	0xcc, 0x8f, 0xed, 0xad, 0x59, 0xdd, 0xe8, 0x6c, 0x9c, 0x66, 0xa6, 0x30, 0x08, 0x07, 0x32, 0xfc,
+	0xdd, 0x84, 0x67, 0x93, 0x07, 0x29, 0xf4, 0xf7, 0x3b, 0x9d, 0x17, 0xf8, 0x1e, 0xdc, 0x62, 0x97,
+	0x6a, 0x46, 0x7c, 0x32, 0x1a, 0x9c, 0xbe, 0x1d, 0xd7, 0x2c, 0xfe, 0x39, 0x3c, 0xde, 0x3f, 0xd5,
+	0x2e, 0xd5, 0xc2, 0x8e, 0xe1, 0x4b, 0x68, 0x6f, 0x56, 0xf9, 0x46, 0xe7, 0xcc, 0xf1, 0x9b, 0xa3,
+	0xbe, 0xd8, 0x2b, 0x3c, 0x81, 0x6e, 0xaa, 0x75, 0x36, 0x5b, 0xe5, 0x1b, 0xd6, 0xf4, 0xc9, 0xa8,
+	0x2f, 0x1e, 0x34, 0xbe, 0x02, 0xef, 0xfa, 0xd6, 0x7c, 0xfd, 0x66, 0x9b, 0xae, 0x6d, 0x1e, 0x00,
+	0x0e, 0xc0, 0x49, 0x52, 0xd6, 0xf2, 0xc9, 0xc8, 0x13, 0x4e, 0x92, 0x22, 0x82, 0x9b, 0x9a, 0xac,
+	0x60, 0x6d, 0x4b, 0x6c, 0x5d, 0xb2, 0x3c, 0xf9, 0xa9, 0x59, 0xc7, 0x27, 0xa3, 0x23, 0x61, 0xeb,
+	0x92, 0x65, 0xc6, 0x14, 0xac, 0x6b, 0x0d, 0x6d, 0x5d, 0x6e, 0x67, 0xb2, 0x64, 0x9d, 0x6c, 0x99,
+	0x67, 0xe9, 0x5e, 0x0d, 0x7f, 0x11, 0xe8, 0xd5, 0xee, 0x82, 0x47, 0xe0, 0x7d, 0x38, 0x5f, 0x84,
+	0xf3, 0x59, 0x20, 0x67, 0xb4, 0x81, 0x1e, 0xb4, 0xac, 0xa4, 0x04, 0xfb, 0xd0, 0x8d, 0xf9, 0x65,
+	0xa5, 0x1c, 0x3c, 0x86, 0x5e, 0x75, 0x8e, 0x47, 0xd3, 0x99, 0xa2, 0xcd, 0xb2, 0x2d, 0xf8, 0x34,
+	0x92, 0x8a, 0x0b, 0xea, 0xe2, 0x73, 0x38, 0xbe, 0x57, 0x2a, 0xba, 0xe0, 0x8b, 0xa5, 0xa2, 0x2d,
+	0xec, 0x41, 0x67, 0x19, 0xcf, 0xe3, 0xc5, 0x65, 0x4c, 0xdb, 0x35, 0x83, 0x60, 0xc2, 0x05, 0xed,
+	0xe0, 0x00, 0x40, 0xaa, 0x40, 0x71, 0x11, 0xc4, 0x53, 0x4e, 0xbb, 0x76, 0x93, 0x2b, 0xc5, 0xc3,
+	0xc5, 0x84, 0x4b, 0xea, 0x55, 0xfe, 0x21, 0x8f, 0x3e, 0x29, 0x49, 0xa1, 0x9c, 0x96, 0x2a, 0x98,
+	0x47, 0xf1, 0xf4, 0x82, 0xab, 0x80, 0xf6, 0x86, 0x7f, 0x08, 0x60, 0x3d, 0xa5, 0x3c, 0x35, 0xdb,
+	0x5c, 0x23, 0x83, 0x4e, 0xba, 0xda, 0x95, 0x90, 0x11, 0x9b, 0xca, 0xbd, 0xc4, 0xe9, 0x3e, 0x6d,
+	0xc7, 0xa6, 0x7d, 0xf6, 0x58, 0xda, 0x95, 0xcf, 0x58, 0xe8, 0x75, 0x92, 0x17, 0x07, 0x50, 0xcb,
+	0xdd, 0x87, 0x5e, 0x15, 0x99, 0x4e, 0xd6, 0x9b, 0xc2, 0x46, 0xec, 0x8a, 0x3a, 0x1a, 0xbe, 0x83,
+	0x17, 0xff, 0x9b, 0x2f, 0xdf, 0x87, 0x5c, 0x86, 0x21, 0x97, 0x92, 0x36, 0xb0, 0x0b, 0xee, 0xc7,
+	0x20, 0x3a, 0xa7, 0x04, 0x01, 0xda, 0x51, 0x2c, 0xaf, 0xe2, 0x90, 0x3a, 0xa7, 0x5f, 0x00, 0x0e,
+	0xdb, 0xe0, 0x0c, 0x5a, 0x9f, 0xef, 0x74, 0xb6, 0xc3, 0xd7, 0x4f, 0x7e, 0x9c, 0x27, 0x6f, 0x9e,
+	0xbe, 0xcd, 0xb0, 0x71, 0xdd, 0xb6, 0x3f, 0xc5, 0xd9, 0xdf, 0x01, 0x00, 0x4c, 0x51, 0x96, 0x0a,
+	0x28, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
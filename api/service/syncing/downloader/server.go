@@ -5,11 +5,15 @@ import (
 	"log"
 	"net"
 
+	"github.com/golang/protobuf/proto"
 	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 // Constants for downloader server.
@@ -21,6 +25,8 @@ const (
 type Server struct {
 	downloadInterface DownloadInterface
 	GrpcServer        *grpc.Server
+	throttle          *Throttle // caps the bandwidth of the responses
+	limiter           *Limiter  // caps the requests served
 }
 
 // Query returns the feature at the given point.
@@ -33,10 +39,18 @@ func (s *Server) Query(ctx context.Context, request *pb.DownloaderRequest) (*pb.
 	} else {
 		pinfo = p.Addr.String()
 	}
+	release, err := s.limiter.Acquire(clientHost(pinfo))
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer release()
 	response, err := s.downloadInterface.CalculateResponse(request, pinfo)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.throttle.Wait(ctx, proto.Size(response)); err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -47,8 +61,7 @@ func (s *Server) Start(ip, port string) (*grpc.Server, error) {
 	if err != nil {
 		log.Fatalf("[SYNC] failed to listen: %v", err)
 	}
-	var opts []grpc.ServerOption
-	grpcServer := grpc.NewServer(opts...)
+	grpcServer := grpc.NewServer(serverOptions()...)
 	pb.RegisterDownloaderServer(grpcServer, s)
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
@@ -61,8 +74,23 @@ func (s *Server) Start(ip, port string) (*grpc.Server, error) {
 	return grpcServer, nil
 }
 
+// serverOptions returns the options of the grpc server, which compresses
+// the responses to compressed requests if the sync compression is enabled
+func serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if !nodeconfig.GetSyncCompress() {
+		opts = append(opts, grpc.UnaryInterceptor(refuseCompressed))
+	}
+	return opts
+}
+
 // NewServer creates new Server which implements DownloadInterface.
 func NewServer(dlInterface DownloadInterface) *Server {
-	s := &Server{downloadInterface: dlInterface}
+	upload, _ := syncThrottles()
+	s := &Server{
+		downloadInterface: dlInterface,
+		throttle:          upload,
+		limiter:           syncLimiter(),
+	}
 	return s
 }
@@ -0,0 +1,186 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/libp2p/go-libp2p-core/helpers"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// Constants for the libp2p stream transport of the downloader protocol.
+// A stream carries one request and its response, each framed as its
+// uvarint length followed by the message. The response starts with a
+// status byte, streamOK followed by the response or streamError followed
+// by the error text.
+const (
+	maxStreamMessageSize = stakingMetaMaxSize
+	streamServeTimeout   = time.Minute
+	streamOK             = 0
+	streamError          = 1
+)
+
+// StreamProtocolVersion is the version of the libp2p stream transport of
+// the downloader protocol
+const StreamProtocolVersion = "1.0.0"
+
+// ProtocolID returns the libp2p protocol serving the downloader requests
+// for the given shard of the given network.
+func ProtocolID(network string, shardID uint32) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/harmony/%s/sync/%d/%s", network, shardID, StreamProtocolVersion))
+}
+
+// StreamClientSetup setups a Client sending its requests to the given peer
+// over libp2p streams of the given protocol.
+func StreamClientSetup(host libp2p_host.Host, peerID libp2p_peer.ID, protocolID protocol.ID) *Client {
+	return &Client{
+		dlClient: newThrottledClient(&streamClient{host: host, peerID: peerID, protocolID: protocolID}),
+		target:   peerID.Pretty(),
+	}
+}
+
+// streamClient implements pb.DownloaderClient over libp2p streams, the
+// call options of grpc do not apply to it
+type streamClient struct {
+	host       libp2p_host.Host
+	peerID     libp2p_peer.ID
+	protocolID protocol.ID
+}
+
+func (c *streamClient) Query(
+	ctx context.Context, request *pb.DownloaderRequest, opts ...grpc.CallOption,
+) (*pb.DownloaderResponse, error) {
+	encoded, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.host.NewStream(ctx, c.peerID, c.protocolID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open sync stream to %s", c.peerID.Pretty())
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+	if err := writeFrame(stream, encoded); err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	reply, err := readFrame(bufio.NewReader(stream))
+	if err != nil {
+		stream.Reset()
+		return nil, err
+	}
+	go helpers.FullClose(stream)
+
+	if len(reply) == 0 {
+		return nil, errors.New("empty sync stream reply")
+	}
+	if reply[0] == streamError {
+		return nil, errors.Errorf("peer %s: %s", c.peerID.Pretty(), reply[1:])
+	}
+	response := &pb.DownloaderResponse{}
+	if err := proto.Unmarshal(reply[1:], response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// StartStream serves the downloader requests received over libp2p streams
+// of the given protocol.
+func (s *Server) StartStream(host libp2p_host.Host, protocolID protocol.ID) {
+	host.SetStreamHandler(protocolID, s.handleStream)
+}
+
+// StopStream stops serving the downloader requests of the given protocol.
+func (s *Server) StopStream(host libp2p_host.Host, protocolID protocol.ID) {
+	host.RemoveStreamHandler(protocolID)
+}
+
+func (s *Server) handleStream(stream libp2p_network.Stream) {
+	remote := stream.Conn().RemotePeer().Pretty()
+	stream.SetDeadline(time.Now().Add(streamServeTimeout))
+	encoded, err := readFrame(bufio.NewReader(stream))
+	if err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote).Msg("[SYNC] cannot read sync stream request")
+		stream.Reset()
+		return
+	}
+	reply, err := s.serveStreamRequest(encoded, remote)
+	if err != nil {
+		reply = append([]byte{streamError}, err.Error()...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), streamServeTimeout)
+	defer cancel()
+	if err := s.throttle.Wait(ctx, len(reply)); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote).Msg("[SYNC] sync stream response over the upload cap")
+		stream.Reset()
+		return
+	}
+	if err := writeFrame(stream, reply); err != nil {
+		utils.Logger().Debug().Err(err).Str("peer", remote).Msg("[SYNC] cannot write sync stream response")
+		stream.Reset()
+		return
+	}
+	go helpers.FullClose(stream)
+}
+
+// serveStreamRequest returns the successful reply to the encoded request
+func (s *Server) serveStreamRequest(encoded []byte, remote string) ([]byte, error) {
+	request := &pb.DownloaderRequest{}
+	if err := proto.Unmarshal(encoded, request); err != nil {
+		return nil, err
+	}
+	release, err := s.limiter.Acquire(remote)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	response, err := s.downloadInterface.CalculateResponse(request, remote)
+	if err != nil {
+		return nil, err
+	}
+	out, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{streamOK}, out...), nil
+}
+
+// writeFrame writes data prefixed with its length
+func writeFrame(w io.Writer, data []byte) error {
+	prefix := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(prefix, uint64(len(data)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads data written by writeFrame
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxStreamMessageSize {
+		return nil, errors.Errorf("sync stream message of %d bytes exceeds the limit", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,49 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/pkg/errors"
+)
+
+type testDownloadInterface struct{}
+
+func (testDownloadInterface) CalculateResponse(
+	request *pb.DownloaderRequest, incomingPeer string,
+) (*pb.DownloaderResponse, error) {
+	if request.Type != pb.DownloaderRequest_BLOCKHEIGHT {
+		return nil, errors.New("unsupported request")
+	}
+	return &pb.DownloaderResponse{BlockHeight: 42}, nil
+}
+
+func TestStreamClient(t *testing.T) {
+	net, err := mocknet.FullMeshConnected(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := net.Hosts()
+	protocolID := ProtocolID("testnet", 1)
+	NewServer(testDownloadInterface{}).StartStream(hosts[0], protocolID)
+
+	client := StreamClientSetup(hosts[1], hosts[0].ID(), protocolID)
+	defer client.Close()
+	response, err := client.GetBlockChainHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.BlockHeight != 42 {
+		t.Errorf("got block height %d, expect 42", response.BlockHeight)
+	}
+	if response := client.GetBlocks([][]byte{{1}}); response != nil {
+		t.Errorf("got response %v to an unsupported request", response)
+	}
+
+	other := StreamClientSetup(hosts[1], hosts[0].ID(), ProtocolID("testnet", 2))
+	if _, err := other.GetBlockChainHeight(); err == nil {
+		t.Error("query of an unserved shard succeeded")
+	}
+}
@@ -0,0 +1,146 @@
+package downloader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"google.golang.org/grpc"
+)
+
+// Throttle caps a bandwidth in bytes per second, with bursts of up to one
+// second of bytes. The transfers over the cap are delayed, not dropped, a
+// transfer larger than the burst is delayed until the bytes it exceeds the
+// burst by are accrued.
+type Throttle struct {
+	lock   sync.Mutex
+	rate   float64 // bytes per second, 0 for no cap
+	tokens float64 // negative when the last transfers are still being paid for
+	last   time.Time
+}
+
+// NewThrottle returns a throttle capping the bandwidth at rate bytes per
+// second, it lets all transfers through when rate is 0
+func NewThrottle(rate int) *Throttle {
+	return &Throttle{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait before
+// transferring them
+func (t *Throttle) reserve(n int, now time.Time) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.rate == 0 {
+		return 0
+	}
+	t.refill(now)
+	t.tokens -= float64(n)
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// allow takes n from the bucket if it holds them, for the transfers which are
+// refused rather than delayed over the cap
+func (t *Throttle) allow(n int, now time.Time) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.rate == 0 {
+		return true
+	}
+	t.refill(now)
+	if t.tokens < float64(n) {
+		return false
+	}
+	t.tokens -= float64(n)
+	return true
+}
+
+// SetRate changes the cap to rate bytes per second, 0 lifting the cap
+func (t *Throttle) SetRate(rate int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	t.refill(now)
+	if t.rate == 0 {
+		// the bucket of an uncapped throttle starts full
+		t.tokens, t.last = float64(rate), now
+	}
+	t.rate = float64(rate)
+	if t.tokens > t.rate {
+		t.tokens = t.rate
+	}
+}
+
+// refill accrues the tokens since the last transfer
+func (t *Throttle) refill(now time.Time) {
+	if now.After(t.last) {
+		t.tokens += now.Sub(t.last).Seconds() * t.rate
+		if t.tokens > t.rate {
+			t.tokens = t.rate
+		}
+		t.last = now
+	}
+}
+
+// Wait blocks until n bytes may be transferred or ctx is done
+func (t *Throttle) Wait(ctx context.Context, n int) error {
+	delay := t.reserve(n, time.Now())
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// The throttles of the responses served and fetched by the node, configured
+// once from nodeconfig.GetSyncBandwidth
+var (
+	throttlesOnce    sync.Once
+	uploadThrottle   *Throttle
+	downloadThrottle *Throttle
+)
+
+func syncThrottles() (upload, download *Throttle) {
+	throttlesOnce.Do(func() {
+		bandwidth := nodeconfig.GetSyncBandwidth()
+		uploadThrottle = NewThrottle(bandwidth.Upload)
+		downloadThrottle = NewThrottle(bandwidth.Download)
+	})
+	return uploadThrottle, downloadThrottle
+}
+
+// throttledClient delays the requests of a client once the responses it
+// fetched exceed the download cap
+type throttledClient struct {
+	pb.DownloaderClient
+	throttle *Throttle
+}
+
+func newThrottledClient(client pb.DownloaderClient) pb.DownloaderClient {
+	_, download := syncThrottles()
+	return &throttledClient{client, download}
+}
+
+func (c *throttledClient) Query(
+	ctx context.Context, request *pb.DownloaderRequest, opts ...grpc.CallOption,
+) (*pb.DownloaderResponse, error) {
+	response, err := c.DownloaderClient.Query(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.throttle.Wait(ctx, proto.Size(response)); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
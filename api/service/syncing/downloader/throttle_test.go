@@ -0,0 +1,46 @@
+package downloader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottleReserve(t *testing.T) {
+	now := time.Now()
+	throttle := NewThrottle(1000)
+	throttle.last = now
+	if delay := throttle.reserve(600, now); delay != 0 {
+		t.Errorf("delayed a transfer within the burst by %v", delay)
+	}
+	if delay := throttle.reserve(600, now); delay != 200*time.Millisecond {
+		t.Errorf("delayed a transfer over the burst by %v, expect 200ms", delay)
+	}
+	// the debt is paid off before the next bytes accrue
+	if delay := throttle.reserve(100, now.Add(100*time.Millisecond)); delay != 200*time.Millisecond {
+		t.Errorf("delayed a transfer by %v, expect 200ms", delay)
+	}
+	// the bucket refills up to the burst
+	if delay := throttle.reserve(1000, now.Add(time.Hour)); delay != 0 {
+		t.Errorf("delayed a transfer after idling by %v", delay)
+	}
+}
+
+func TestThrottleUncapped(t *testing.T) {
+	throttle := NewThrottle(0)
+	for i := 0; i < 10; i++ {
+		if err := throttle.Wait(context.Background(), 1<<30); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestThrottleWaitCanceled(t *testing.T) {
+	throttle := NewThrottle(10)
+	throttle.Wait(context.Background(), 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := throttle.Wait(ctx, 1000); err != context.Canceled {
+		t.Errorf("got %v, expect the context error", err)
+	}
+}
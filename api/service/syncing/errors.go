@@ -13,4 +13,7 @@ var (
 	ErrDownloadBlocks        = errors.New("[SYNC]: get download blocks failed")
 	ErrUpdateBlockAndStatus  = errors.New("[SYNC]: update block and status failed")
 	ErrGenerateNewState      = errors.New("[SYNC]: get generate new state failed")
+	ErrSnapNoPeers           = errors.New("[SYNC]: no peers to snap sync from")
+	ErrSnapRequest           = errors.New("[SYNC]: snap sync request failed")
+	ErrSnapNoPivot           = errors.New("[SYNC]: no epoch block to snap sync to")
 )
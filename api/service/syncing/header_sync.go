@@ -0,0 +1,44 @@
+package syncing
+
+import (
+	"time"
+
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+// HeaderSyncLoop keeps the headers of the beacon chain bc in sync with the
+// peers, for shard nodes which follow only the beacon chain headers. The
+// headers carry the committees and cross links read by the shard chain, and
+// every header has its commit signature verified.
+func (ss *StateSync) HeaderSyncLoop(bc *core.BlockChain) {
+	ticker := time.NewTicker(SyncLoopFrequency * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if ss.syncConfig.RemoveFaultyPeers() > 0 && ss.GetActivePeerNumber() == 0 {
+			utils.Logger().Warn().Msg("[SYNC] all peers dropped, stopping the header sync loop")
+			break
+		}
+		otherHeight := ss.getMaxPeerHeight(true)
+		currentHeight := bc.CurrentHeader().Number().Uint64()
+		if currentHeight >= otherHeight {
+			utils.Logger().Info().
+				Msgf("[SYNC] Beacon headers are now IN SYNC! (otherHeight: %d, currentHeight: %d)",
+					otherHeight, currentHeight)
+			break
+		}
+		utils.Logger().Info().
+			Msgf("[SYNC] Beacon headers are OUT OF SYNC (otherHeight: %d, currentHeight: %d)",
+				otherHeight, currentHeight)
+		if err := ss.syncHeaderChain(bc, otherHeight, func(headers []*block.Header) error {
+			_, err := bc.InsertBeaconHeaders(headers)
+			return err
+		}); err != nil {
+			utils.Logger().Error().Err(err).
+				Msgf("[SYNC] beacon header sync failed (otherHeight: %d, currentHeight: %d)",
+					otherHeight, currentHeight)
+		}
+	}
+	ss.purgeAllBlocksFromCache()
+}
@@ -0,0 +1,183 @@
+package syncing
+
+import (
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// pipelineWorkers is the number of blocks verified concurrently ahead of the
+// execution of the blocks
+var pipelineWorkers = runtime.NumCPU()
+
+// insertBatchSize is the number of verified blocks inserted together, so that
+// the state of the next blocks is prefetched while executing each of them
+const insertBatchSize = 16
+
+// pendingChain is the chain with the headers of the blocks being inserted by
+// the pipeline, so that their children can be verified before the blocks are
+// inserted
+type pendingChain struct {
+	*core.BlockChain
+	headers map[common.Hash]*block.Header
+}
+
+// GetHeader returns the pending or inserted header with the hash and number
+func (c *pendingChain) GetHeader(hash common.Hash, number uint64) *block.Header {
+	if header, ok := c.headers[hash]; ok && header.Number().Uint64() == number {
+		return header
+	}
+	return c.BlockChain.GetHeader(hash, number)
+}
+
+// GetHeaderByHash returns the pending or inserted header with the hash
+func (c *pendingChain) GetHeaderByHash(hash common.Hash) *block.Header {
+	if header, ok := c.headers[hash]; ok {
+		return header
+	}
+	return c.BlockChain.GetHeaderByHash(hash)
+}
+
+// linkBlocks returns the chain of blocks extending the parent hash
+func linkBlocks(parentHash common.Hash, blocks []*types.Block) []*types.Block {
+	children := make(map[common.Hash]*types.Block, len(blocks))
+	for _, b := range blocks {
+		children[b.ParentHash()] = b
+	}
+	var chain []*types.Block
+	for b := children[parentHash]; b != nil; b = children[b.Hash()] {
+		chain = append(chain, b)
+	}
+	return chain
+}
+
+// epochSegments splits the chain of blocks after each last block of an epoch,
+// the blocks of the next epoch are signed by the committee it carries
+func epochSegments(blocks []*types.Block) [][]*types.Block {
+	var segments [][]*types.Block
+	start := 0
+	for i, b := range blocks {
+		if len(b.Header().ShardState()) > 0 || i == len(blocks)-1 {
+			segments = append(segments, blocks[start:i+1])
+			start = i + 1
+		}
+	}
+	return segments
+}
+
+// verifyBlock verifies the commit signature of the parent carried by the
+// block and its transaction root, and recovers the senders of its
+// transactions, which are then cached for the execution of the block
+func verifyBlock(chain *pendingChain, b *types.Block) error {
+	if b.NumberU64() > 1 {
+		if err := chain.Engine().VerifyHeader(chain, b.Header(), true); err != nil {
+			return errors.Wrapf(err, "cannot verify block %d", b.NumberU64())
+		}
+	}
+	if hash := types.DeriveSha(
+		b.Transactions(), b.StakingTransactions(),
+	); hash != b.Header().TxHash() {
+		return errors.Errorf(
+			"block %d: transaction root hash mismatch: have %x, want %x",
+			b.NumberU64(), hash, b.Header().TxHash(),
+		)
+	}
+	signer := types.MakeSigner(chain.Config(), b.Epoch())
+	for _, tx := range b.Transactions() {
+		if _, err := types.Sender(signer, tx); err != nil {
+			return errors.Wrapf(err, "block %d: invalid transaction sender", b.NumberU64())
+		}
+	}
+	for _, tx := range b.StakingTransactions() {
+		if _, err := tx.SenderAddress(); err != nil {
+			return errors.Wrapf(err, "block %d: invalid staking transaction sender", b.NumberU64())
+		}
+	}
+	return nil
+}
+
+// insertBlocks inserts the chain of blocks extending the current block in a
+// pipeline: the blocks are verified concurrently ahead of their sequential
+// execution. The blocks after the last block of an epoch are verified once
+// it is inserted, as its committee is needed to verify them. It returns the
+// number of inserted blocks.
+func insertBlocks(bc *core.BlockChain, blocks []*types.Block) (int, error) {
+	inserted := 0
+	for _, segment := range epochSegments(blocks) {
+		n, err := insertSegment(bc, segment)
+		inserted += n
+		if err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+// insertSegment verifies and inserts blocks signed by known committees
+func insertSegment(bc *core.BlockChain, blocks []*types.Block) (int, error) {
+	chain := &pendingChain{bc, make(map[common.Hash]*block.Header, len(blocks))}
+	for _, b := range blocks {
+		chain.headers[b.Hash()] = b.Header()
+	}
+	results := make([]chan error, len(blocks))
+	for i := range results {
+		results[i] = make(chan error, 1)
+	}
+	jobs := make(chan int)
+	abort := make(chan struct{})
+	defer close(abort)
+	go func() {
+		defer close(jobs)
+		for i := range blocks {
+			select {
+			case jobs <- i:
+			case <-abort:
+				return
+			}
+		}
+	}()
+	for w := 0; w < pipelineWorkers; w++ {
+		go func() {
+			for i := range jobs {
+				results[i] <- verifyBlock(chain, blocks[i])
+			}
+		}()
+	}
+
+	for start := 0; start < len(blocks); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		for i := start; i < end; i++ {
+			if err := <-results[i]; err != nil {
+				if i > start {
+					n, err := bc.InsertChain(blocks[start:i], false /* verifyHeaders */)
+					if err != nil {
+						return start + n, errors.Wrapf(err, "cannot insert block %d", blocks[start+n].NumberU64())
+					}
+				}
+				return i, err
+			}
+		}
+		// the state of the next blocks of the batch is prefetched while
+		// executing each block
+		if n, err := bc.InsertChain(blocks[start:end], false /* verifyHeaders */); err != nil {
+			return start + n, errors.Wrapf(err, "cannot insert block %d", blocks[start+n].NumberU64())
+		}
+		last := blocks[end-1]
+		utils.Logger().Info().
+			Uint64("blockHeight", last.NumberU64()).
+			Uint64("blockEpoch", last.Epoch().Uint64()).
+			Str("blockHex", last.Hash().Hex()).
+			Uint32("ShardID", last.ShardID()).
+			Int("blocks", end-start).
+			Msg("[SYNC] insertBlocks: New Blocks Added to Blockchain")
+	}
+	return len(blocks), nil
+}
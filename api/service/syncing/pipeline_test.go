@@ -0,0 +1,76 @@
+package syncing
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func newTestChain(parentHash common.Hash, n int, lastOfEpoch ...int) []*types.Block {
+	last := map[int]bool{}
+	for _, i := range lastOfEpoch {
+		last[i] = true
+	}
+	blocks := make([]*types.Block, n)
+	for i := range blocks {
+		header := blockfactory.NewTestHeader().With().
+			ParentHash(parentHash).
+			Number(big.NewInt(int64(i + 1))).
+			Header()
+		if last[i] {
+			header.SetShardState([]byte{0xc0})
+		}
+		blocks[i] = types.NewBlockWithHeader(header)
+		parentHash = blocks[i].Hash()
+	}
+	return blocks
+}
+
+func TestLinkBlocks(t *testing.T) {
+	genesis := common.HexToHash("0x1")
+	blocks := newTestChain(genesis, 5)
+	shuffled := []*types.Block{blocks[3], blocks[0], blocks[4], blocks[2], blocks[1]}
+	if chain := linkBlocks(genesis, shuffled); len(chain) != 5 {
+		t.Fatalf("linked %d blocks, expect 5", len(chain))
+	} else {
+		for i, b := range chain {
+			if b != blocks[i] {
+				t.Errorf("block %d is out of order", i)
+			}
+		}
+	}
+	// a missing block ends the chain
+	if chain := linkBlocks(genesis, append(blocks[:2:2], blocks[3:]...)); len(chain) != 2 {
+		t.Errorf("linked %d blocks, expect 2", len(chain))
+	}
+}
+
+func TestEpochSegments(t *testing.T) {
+	tests := []struct {
+		n           int
+		lastOfEpoch []int
+		lens        []int
+	}{
+		{0, nil, nil},
+		{5, nil, []int{5}},
+		{5, []int{1}, []int{2, 3}},
+		{5, []int{1, 4}, []int{2, 3}},
+		{5, []int{0, 1, 2}, []int{1, 1, 1, 2}},
+	}
+	for i, test := range tests {
+		segments := epochSegments(newTestChain(common.Hash{}, test.n, test.lastOfEpoch...))
+		if len(segments) != len(test.lens) {
+			t.Errorf("test %d: %d segments, expect %d", i, len(segments), len(test.lens))
+			continue
+		}
+		for j, segment := range segments {
+			if len(segment) != test.lens[j] {
+				t.Errorf("test %d: segment %d has %d blocks, expect %d",
+					i, j, len(segment), test.lens[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package snap implements the state part of snap sync: instead of executing
+// every block since genesis, a node downloads the leaves of the state trie of
+// a recent pivot block in ranges, together with the storage tries and the
+// contract codes the accounts refer to, and rebuilds the tries locally.
+//
+// Every range carries merkle proofs of its first and last leaf against the
+// requested root, so a peer cannot forge leaves. Each rebuilt trie must hash
+// to its expected root, which detects withheld leaves.
+package snap
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SoftResponseLimit is the size a range response stops growing at
+	SoftResponseLimit = 512 * 1024
+	// MaxCodesPerRequest is the maximum number of contract codes served in one response
+	MaxCodesPerRequest = 64
+)
+
+var (
+	// emptyRoot is the root of an empty trie
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	// emptyCode is the hash of empty contract code
+	emptyCode = crypto.Keccak256Hash(nil)
+)
+
+// Errors of range verification
+var (
+	ErrRangeMismatch = errors.New("range keys and values do not match")
+	ErrRangeOrder    = errors.New("range keys are not in ascending order")
+	ErrRangeProof    = errors.New("range boundaries do not match the proof")
+)
+
+// Range is a run of consecutive leaves of a trie starting at or after the
+// requested origin, with the trie nodes proving its first and last leaf.
+type Range struct {
+	Keys   [][]byte
+	Values [][]byte
+	Proof  [][]byte
+	More   bool // the trie holds leaves after the last key
+}
+
+// ServeRange returns the leaves of the trie with the given root starting at
+// origin, stopping once the response exceeds maxBytes.
+func ServeRange(db *trie.Database, root, origin common.Hash, maxBytes int) (*Range, error) {
+	tr, err := trie.New(root, db)
+	if err != nil {
+		return nil, err
+	}
+	r := &Range{Keys: [][]byte{}, Values: [][]byte{}, Proof: [][]byte{}}
+	it := trie.NewIterator(tr.NodeIterator(origin[:]))
+	size := 0
+	for it.Next() {
+		if size >= maxBytes {
+			r.More = true
+			break
+		}
+		r.Keys = append(r.Keys, common.CopyBytes(it.Key))
+		r.Values = append(r.Values, common.CopyBytes(it.Value))
+		size += len(it.Key) + len(it.Value)
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+	proof := ethdb.NewMemDatabase()
+	if len(r.Keys) == 0 {
+		// prove there is no leaf at origin
+		if err := tr.Prove(origin[:], 0, proof); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, key := range [][]byte{r.Keys[0], r.Keys[len(r.Keys)-1]} {
+			if err := tr.Prove(key, 0, proof); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, key := range proof.Keys() {
+		node, _ := proof.Get(key)
+		r.Proof = append(r.Proof, node)
+	}
+	return r, nil
+}
+
+// ServeByteCodes returns the contract codes with the given hashes, an empty
+// entry for each unknown code. At most MaxCodesPerRequest codes are served.
+func ServeByteCodes(db *trie.Database, hashes []common.Hash) [][]byte {
+	if len(hashes) > MaxCodesPerRequest {
+		hashes = hashes[:MaxCodesPerRequest]
+	}
+	codes := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		if hash == emptyCode {
+			codes[i] = []byte{}
+			continue
+		}
+		code, err := db.Node(hash)
+		if err != nil {
+			code = []byte{}
+		}
+		codes[i] = code
+	}
+	return codes
+}
+
+// VerifyRange checks that r was served for the trie with the given root
+// from origin. The keys must be ascending and start at or after origin, and
+// the first and last leaf must be proven by the proof of the range.
+func VerifyRange(root, origin common.Hash, r *Range) error {
+	if len(r.Keys) != len(r.Values) {
+		return ErrRangeMismatch
+	}
+	for i, key := range r.Keys {
+		if len(key) != common.HashLength || len(r.Values[i]) == 0 {
+			return ErrRangeMismatch
+		}
+		if i == 0 && bytes.Compare(key, origin[:]) < 0 {
+			return ErrRangeOrder
+		}
+		if i > 0 && bytes.Compare(r.Keys[i-1], key) >= 0 {
+			return ErrRangeOrder
+		}
+	}
+	proof := ethdb.NewMemDatabase()
+	for _, node := range r.Proof {
+		proof.Put(crypto.Keccak256(node), node)
+	}
+	if len(r.Keys) == 0 {
+		if r.More {
+			return ErrRangeMismatch
+		}
+		value, _, err := trie.VerifyProof(root, origin[:], proof)
+		if err != nil {
+			return errors.Wrap(ErrRangeProof, err.Error())
+		}
+		if len(value) != 0 {
+			return ErrRangeProof
+		}
+		return nil
+	}
+	for _, i := range []int{0, len(r.Keys) - 1} {
+		value, _, err := trie.VerifyProof(root, r.Keys[i], proof)
+		if err != nil {
+			return errors.Wrap(ErrRangeProof, err.Error())
+		}
+		if !bytes.Equal(value, r.Values[i]) {
+			return ErrRangeProof
+		}
+	}
+	return nil
+}
+
+// nextKey returns the key following key, false if key is the last possible key
+func nextKey(key []byte) (common.Hash, bool) {
+	next := common.BytesToHash(key)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, true
+		}
+	}
+	return next, false
+}
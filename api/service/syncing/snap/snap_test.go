@@ -0,0 +1,210 @@
+package snap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/pkg/errors"
+)
+
+// testFetcher serves ranges from a local trie database in small responses
+type testFetcher struct {
+	db       *trie.Database
+	maxBytes int
+	tamper   func(r *Range)
+}
+
+func (f *testFetcher) FetchRange(root, origin common.Hash) (*Range, error) {
+	r, err := ServeRange(f.db, root, origin, f.maxBytes)
+	if err == nil && f.tamper != nil {
+		f.tamper(r)
+	}
+	return r, err
+}
+
+func (f *testFetcher) FetchByteCodes(hashes []common.Hash) ([][]byte, error) {
+	return ServeByteCodes(f.db, hashes), nil
+}
+
+func makeTestState(t *testing.T) (state.Database, common.Hash) {
+	db := state.NewDatabase(ethdb.NewMemDatabase())
+	statedb, _ := state.New(common.Hash{}, db)
+	for i := 0; i < 200; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		statedb.AddBalance(addr, big.NewInt(int64(1000+i)))
+		statedb.SetNonce(addr, uint64(i))
+		if i%10 == 0 {
+			statedb.SetCode(addr, []byte{byte(i), 0x60, 0x00})
+			for j := 0; j < 20; j++ {
+				statedb.SetState(addr,
+					common.BigToHash(big.NewInt(int64(j))),
+					common.BigToHash(big.NewInt(int64(i*100+j+1))),
+				)
+			}
+		}
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	return db, root
+}
+
+func TestSync(t *testing.T) {
+	src, root := makeTestState(t)
+	dst := ethdb.NewMemDatabase()
+	syncer := NewSyncer(dst, root, &testFetcher{db: src.TrieDB(), maxBytes: 1024})
+	if err := syncer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if syncer.Accounts != 200 || syncer.Slots != 400 || syncer.Codes != 20 {
+		t.Errorf("got %d accounts %d slots %d codes, expect 200 400 20",
+			syncer.Accounts, syncer.Slots, syncer.Codes)
+	}
+	statedb, err := state.New(root, state.NewDatabase(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		if got := statedb.GetBalance(addr); got.Int64() != int64(1000+i) {
+			t.Errorf("account %d: got balance %d", i, got)
+		}
+		if i%10 != 0 {
+			continue
+		}
+		if code := statedb.GetCode(addr); len(code) != 3 || code[0] != byte(i) {
+			t.Errorf("account %d: got code %x", i, code)
+		}
+		got := statedb.GetState(addr, common.BigToHash(big.NewInt(19)))
+		if got != common.BigToHash(big.NewInt(int64(i*100+20))) {
+			t.Errorf("account %d: got storage %x", i, got)
+		}
+	}
+}
+
+func TestSyncRejectsTamperedRanges(t *testing.T) {
+	src, root := makeTestState(t)
+	tests := map[string]func(r *Range){
+		"withheld leaf": func(r *Range) {
+			if len(r.Keys) > 2 {
+				r.Keys = append(r.Keys[:1], r.Keys[2:]...)
+				r.Values = append(r.Values[:1], r.Values[2:]...)
+			}
+		},
+		"forged value": func(r *Range) {
+			if len(r.Values) > 0 {
+				r.Values[len(r.Values)-1] = []byte{0x01}
+			}
+		},
+		"truncated": func(r *Range) { r.More = false },
+	}
+	for name, tamper := range tests {
+		fetcher := &testFetcher{db: src.TrieDB(), maxBytes: 1024, tamper: tamper}
+		if err := NewSyncer(ethdb.NewMemDatabase(), root, fetcher).Sync(); err == nil {
+			t.Errorf("%s: sync succeeded", name)
+		}
+	}
+}
+
+func TestVerifyRange(t *testing.T) {
+	src, root := makeTestState(t)
+	r, err := ServeRange(src.TrieDB(), root, common.Hash{}, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRange(root, common.Hash{}, r); err != nil {
+		t.Fatal(err)
+	}
+	if !r.More {
+		t.Error("expect more leaves after the first range")
+	}
+	origin, _ := nextKey(r.Keys[0])
+	if err := VerifyRange(root, origin, r); err != ErrRangeOrder {
+		t.Errorf("got %v, expect %v for keys before origin", err, ErrRangeOrder)
+	}
+	r.Keys[0], r.Keys[1] = r.Keys[1], r.Keys[0]
+	if err := VerifyRange(root, common.Hash{}, r); err != ErrRangeOrder {
+		t.Errorf("got %v, expect %v for unordered keys", err, ErrRangeOrder)
+	}
+
+	last := common.HexToHash("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	empty, err := ServeRange(src.TrieDB(), root, last, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(empty.Keys) != 0 || empty.More {
+		t.Fatalf("got %d keys after the last key", len(empty.Keys))
+	}
+	if err := VerifyRange(root, last, empty); err != nil {
+		t.Error(err)
+	}
+}
+
+// failingFetcher fails all requests after the first limit ones
+type failingFetcher struct {
+	testFetcher
+	limit, requests int
+}
+
+func (f *failingFetcher) FetchRange(root, origin common.Hash) (*Range, error) {
+	if f.requests++; f.requests > f.limit {
+		return nil, errors.New("peer gone")
+	}
+	return f.testFetcher.FetchRange(root, origin)
+}
+
+func TestSyncResumes(t *testing.T) {
+	defer func(interval int) { commitInterval = interval }(commitInterval)
+	commitInterval = 50
+
+	src, root := makeTestState(t)
+	dst := ethdb.NewMemDatabase()
+	fetcher := &failingFetcher{testFetcher: testFetcher{db: src.TrieDB(), maxBytes: 1024}, limit: 10}
+	if err := NewSyncer(dst, root, fetcher).Sync(); err == nil {
+		t.Fatal("interrupted sync succeeded")
+	}
+	progress := rawdb.ReadSnapSyncTrie(dst)
+	if progress == nil || progress.Root != root {
+		t.Fatalf("got progress %+v, expect progress of the account trie", progress)
+	}
+
+	fetcher.limit, fetcher.requests = 1<<30, 0
+	syncer := NewSyncer(dst, root, fetcher)
+	if err := syncer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if syncer.Accounts != 200 || syncer.Slots != 400 {
+		t.Errorf("got %d accounts %d slots, expect 200 400", syncer.Accounts, syncer.Slots)
+	}
+	full := &failingFetcher{testFetcher: testFetcher{db: src.TrieDB(), maxBytes: 1024}, limit: 1 << 30}
+	if err := NewSyncer(ethdb.NewMemDatabase(), root, full).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.requests+10 > full.requests {
+		t.Errorf("resumed sync made %d requests, a full sync makes %d", fetcher.requests, full.requests)
+	}
+	if rawdb.ReadSnapSyncTrie(dst) != nil {
+		t.Error("progress of the synced trie is kept")
+	}
+
+	// a synced state is not downloaded again
+	fetcher.requests = 0
+	if err := NewSyncer(dst, root, fetcher).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.requests != 0 {
+		t.Errorf("synced state made %d requests", fetcher.requests)
+	}
+	if _, err := state.New(root, state.NewDatabase(dst)); err != nil {
+		t.Fatal(err)
+	}
+}
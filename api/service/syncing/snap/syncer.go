@@ -0,0 +1,282 @@
+package snap
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// fetchRetries is the number of times a failed request is repeated, the
+// Fetcher is expected to pick another peer for every attempt
+const fetchRetries = 5
+
+// commitInterval is the number of leaves inserted between two commits of the
+// trie being rebuilt, its progress is persisted with every commit so that an
+// interrupted sync resumes from there
+var commitInterval = 100000
+
+// Fetcher retrieves state ranges and contract codes from the network
+type Fetcher interface {
+	// FetchRange returns the leaves of the trie with the given root from origin
+	FetchRange(root, origin common.Hash) (*Range, error)
+	// FetchByteCodes returns the codes with the given hashes, in the same order
+	FetchByteCodes(hashes []common.Hash) ([][]byte, error)
+}
+
+// Syncer downloads the state of a pivot block into a database
+type Syncer struct {
+	db      ethdb.Database
+	triedb  *trie.Database
+	root    common.Hash
+	fetcher Fetcher
+
+	storageRoots []common.Hash
+	codeHashes   []common.Hash
+	seen         map[common.Hash]struct{}
+
+	// Accounts, Slots and Codes count the downloaded items
+	Accounts, Slots, Codes uint64
+}
+
+// NewSyncer returns a syncer downloading the state with the given root into db
+func NewSyncer(db ethdb.Database, root common.Hash, fetcher Fetcher) *Syncer {
+	return &Syncer{
+		db:      db,
+		triedb:  trie.NewDatabase(db),
+		root:    root,
+		fetcher: fetcher,
+		seen:    map[common.Hash]struct{}{},
+	}
+}
+
+// Sync downloads the account trie, the storage tries and the contract codes
+// of the state and writes them to the database. The tries and codes already
+// in the database are not downloaded again, and the download of a trie
+// resumes from its last commit, so that an interrupted sync is resumed.
+func (s *Syncer) Sync() error {
+	err := s.syncTrie(s.root, func(key, value []byte) error {
+		var account state.Account
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			return errors.Wrapf(err, "cannot decode account %x", key)
+		}
+		s.Accounts++
+		if account.Root != emptyRoot {
+			s.queue(&s.storageRoots, account.Root)
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != emptyCode {
+			s.queue(&s.codeHashes, codeHash)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	utils.Logger().Info().
+		Uint64("accounts", s.Accounts).
+		Int("storageTries", len(s.storageRoots)).
+		Int("codes", len(s.codeHashes)).
+		Msg("[SYNC] snap: account trie downloaded")
+
+	for _, root := range s.storageRoots {
+		if err := s.syncTrie(root, func(key, value []byte) error {
+			s.Slots++
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	var codeHashes []common.Hash
+	for _, hash := range s.codeHashes {
+		if ok, _ := s.db.Has(hash[:]); !ok {
+			codeHashes = append(codeHashes, hash)
+		}
+	}
+	for start := 0; start < len(codeHashes); start += MaxCodesPerRequest {
+		end := start + MaxCodesPerRequest
+		if end > len(codeHashes) {
+			end = len(codeHashes)
+		}
+		if err := s.syncCodes(codeHashes[start:end]); err != nil {
+			return err
+		}
+	}
+	utils.Logger().Info().
+		Uint64("accounts", s.Accounts).
+		Uint64("slots", s.Slots).
+		Uint64("codes", s.Codes).
+		Msg("[SYNC] snap: state downloaded")
+	return nil
+}
+
+// queue adds hash to the list unless it was queued before, accounts share
+// storage tries and codes
+func (s *Syncer) queue(list *[]common.Hash, hash common.Hash) {
+	if _, ok := s.seen[hash]; ok {
+		return
+	}
+	s.seen[hash] = struct{}{}
+	*list = append(*list, hash)
+}
+
+// fetchRange fetches and verifies the range of the trie with the given root
+// from origin, retrying failed attempts
+func (s *Syncer) fetchRange(root, origin common.Hash) (*Range, error) {
+	var err error
+	for i := 0; i < fetchRetries; i++ {
+		var r *Range
+		if r, err = s.fetcher.FetchRange(root, origin); err == nil {
+			if err = VerifyRange(root, origin, r); err == nil {
+				return r, nil
+			}
+		}
+		utils.Logger().Debug().Err(err).
+			Str("root", root.Hex()).
+			Str("origin", origin.Hex()).
+			Msg("[SYNC] snap: range request failed")
+	}
+	return nil, errors.Wrapf(err, "cannot fetch range %x of trie %x", origin, root)
+}
+
+// syncTrie rebuilds the trie with the given root from downloaded ranges,
+// calling onLeaf for every leaf. A trie already in the database is only
+// iterated, and the rebuilding of a trie interrupted after a commit resumes
+// from the leaves after the commit.
+func (s *Syncer) syncTrie(root common.Hash, onLeaf func(key, value []byte) error) error {
+	if root == emptyRoot {
+		return nil
+	}
+	if ok, _ := s.db.Has(root[:]); ok {
+		return s.iterateTrie(root, onLeaf)
+	}
+	partialRoot, origin := common.Hash{}, common.Hash{}
+	if progress := rawdb.ReadSnapSyncTrie(s.db); progress != nil && progress.Root == root {
+		partialRoot, origin = progress.PartialRoot, progress.Origin
+		// the leaves before origin are in the committed trie
+		if err := s.iterateTrie(partialRoot, onLeaf); err != nil {
+			return err
+		}
+		utils.Logger().Info().
+			Str("root", root.Hex()).
+			Str("origin", origin.Hex()).
+			Msg("[SYNC] snap: resuming trie download")
+	}
+	tr, err := trie.New(partialRoot, s.triedb)
+	if err != nil {
+		return err
+	}
+	leaves := 0
+	for {
+		r, err := s.fetchRange(root, origin)
+		if err != nil {
+			return err
+		}
+		for i := range r.Keys {
+			if err := tr.TryUpdate(r.Keys[i], r.Values[i]); err != nil {
+				return err
+			}
+			if err := onLeaf(r.Keys[i], r.Values[i]); err != nil {
+				return err
+			}
+		}
+		next, ok := common.Hash{}, false
+		if r.More && len(r.Keys) > 0 {
+			next, ok = nextKey(r.Keys[len(r.Keys)-1])
+		}
+		if !ok {
+			break
+		}
+		origin = next
+		if leaves += len(r.Keys); leaves >= commitInterval {
+			if err := s.commitPartialTrie(tr, root, origin); err != nil {
+				return err
+			}
+			leaves = 0
+		}
+	}
+	got, err := tr.Commit(nil)
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return errors.Errorf("rebuilt trie has root %x, expected %x", got, root)
+	}
+	if err := s.triedb.Commit(got, false); err != nil {
+		return err
+	}
+	return rawdb.DeleteSnapSyncTrie(s.db)
+}
+
+// commitPartialTrie writes the trie rebuilt from the leaves before origin to
+// the database, and the progress of the trie with the given root
+func (s *Syncer) commitPartialTrie(tr *trie.Trie, root, origin common.Hash) error {
+	partialRoot, err := tr.Commit(nil)
+	if err != nil {
+		return err
+	}
+	if err := s.triedb.Commit(partialRoot, false); err != nil {
+		return err
+	}
+	return rawdb.WriteSnapSyncTrie(s.db, &rawdb.SnapSyncTrie{
+		Root:        root,
+		PartialRoot: partialRoot,
+		Origin:      origin,
+	})
+}
+
+// iterateTrie calls onLeaf for every leaf of the trie in the database
+func (s *Syncer) iterateTrie(root common.Hash, onLeaf func(key, value []byte) error) error {
+	tr, err := trie.New(root, s.triedb)
+	if err != nil {
+		return err
+	}
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		if err := onLeaf(it.Key, it.Value); err != nil {
+			return err
+		}
+	}
+	return it.Err
+}
+
+// syncCodes downloads and writes the contract codes with the given hashes
+func (s *Syncer) syncCodes(hashes []common.Hash) error {
+	var err error
+	for i := 0; i < fetchRetries; i++ {
+		var codes [][]byte
+		if codes, err = s.fetcher.FetchByteCodes(hashes); err == nil {
+			if err = s.writeCodes(hashes, codes); err == nil {
+				return nil
+			}
+		}
+		utils.Logger().Debug().Err(err).
+			Int("codes", len(hashes)).
+			Msg("[SYNC] snap: code request failed")
+	}
+	return errors.Wrap(err, "cannot fetch contract codes")
+}
+
+func (s *Syncer) writeCodes(hashes []common.Hash, codes [][]byte) error {
+	if len(codes) != len(hashes) {
+		return errors.Errorf("got %d codes for %d hashes", len(codes), len(hashes))
+	}
+	batch := s.db.NewBatch()
+	for i, code := range codes {
+		if crypto.Keccak256Hash(code) != hashes[i] {
+			return errors.Errorf("code %x missing or invalid", hashes[i])
+		}
+		if err := batch.Put(hashes[i][:], code); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	s.Codes += uint64(len(codes))
+	return nil
+}
@@ -0,0 +1,407 @@
+package syncing
+
+import (
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/api/service/syncing/snap"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Constants for snap sync.
+const (
+	// SnapPivotDistance is the minimum number of blocks the pivot of a snap
+	// sync stays below the peers, whose state is then still committed
+	SnapPivotDistance = 128
+	snapHeaderBatch   = 100 // headers requested at once
+	snapBlockBatch    = 64  // blocks and receipts requested at once
+	snapRetries       = 5   // attempts of a request, each with another peer
+)
+
+// snapPeer returns the next sync peer serving the snap sync request, by
+// serves, or the next sync peer if none advertised serving it
+func (ss *StateSync) snapPeer(serves func(*SyncPeerConfig) bool) (*SyncPeerConfig, error) {
+	ss.syncConfig.mtx.RLock()
+	defer ss.syncConfig.mtx.RUnlock()
+	peers := ss.syncConfig.peers
+	if len(peers) == 0 {
+		return nil, ErrSnapNoPeers
+	}
+	i := int(atomic.AddUint32(&ss.snapPeerIndex, 1))
+	for j := 0; j < len(peers) && serves != nil; j++ {
+		if peer := peers[(i+j)%len(peers)]; serves(peer) {
+			return peer, nil
+		}
+	}
+	return peers[i%len(peers)], nil
+}
+
+// servesSnap selects the peers serving the state ranges of snap sync
+func servesSnap(peer *SyncPeerConfig) bool {
+	return peer.servesSnap()
+}
+
+// servesBlocks selects the peers serving the blocks from the given number
+func servesBlocks(from uint64) func(*SyncPeerConfig) bool {
+	return func(peer *SyncPeerConfig) bool {
+		return peer.servesBlock(from)
+	}
+}
+
+// snapRequest sends request to the sync peers serving it in turn until it
+// succeeds, all peers serve the request when serves is nil
+func (ss *StateSync) snapRequest(
+	name string, serves func(*SyncPeerConfig) bool, request func(*SyncPeerConfig) error,
+) error {
+	var err error
+	for i := 0; i < snapRetries; i++ {
+		var peer *SyncPeerConfig
+		if peer, err = ss.snapPeer(serves); err != nil {
+			return err
+		}
+		if err = request(peer); err == nil {
+			peer.reward()
+			return nil
+		}
+		peer.penalize()
+		utils.Logger().Warn().Err(err).
+			Str("peerIP", peer.ip).
+			Str("peerPort", peer.port).
+			Msgf("[SYNC] snap sync %s request failed", name)
+	}
+	return errors.Wrapf(err, "[SYNC] snap sync %s request failed", name)
+}
+
+// snapFetcher serves the state requests of snap.Syncer from the sync peers
+type snapFetcher struct {
+	ss *StateSync
+}
+
+func (f snapFetcher) FetchRange(root, origin common.Hash) (*snap.Range, error) {
+	peer, err := f.ss.snapPeer(servesSnap)
+	if err != nil {
+		return nil, err
+	}
+	response := peer.client.GetStateRange(root[:], origin[:])
+	if response == nil || len(response.Payload) != 1 {
+		peer.penalize()
+		return nil, ErrSnapRequest
+	}
+	r := &snap.Range{}
+	if err := rlp.DecodeBytes(response.Payload[0], r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (f snapFetcher) FetchByteCodes(hashes []common.Hash) ([][]byte, error) {
+	peer, err := f.ss.snapPeer(servesSnap)
+	if err != nil {
+		return nil, err
+	}
+	request := make([][]byte, len(hashes))
+	for i := range hashes {
+		request[i] = hashes[i][:]
+	}
+	response := peer.client.GetByteCodes(request)
+	if response == nil {
+		peer.penalize()
+		return nil, ErrSnapRequest
+	}
+	return response.Payload, nil
+}
+
+// SnapSync brings the empty chain bc to the state of an epoch block at least
+// SnapPivotDistance blocks below the peer height, the pivot. The headers up
+// to the peer height and the blocks and receipts up to the pivot are
+// downloaded without executing them, then the state of the pivot and the
+// off-chain staking data are downloaded and the pivot becomes the head.
+//
+// The pivot is persisted once chosen, so that a snap sync interrupted by a
+// restart resumes with the same pivot from the downloaded headers, blocks and
+// state rather than starting over.
+func (ss *StateSync) SnapSync(bc *core.BlockChain, peerHeight uint64) error {
+	pivot := snapResumedPivot(bc)
+	if pivot == nil {
+		if peerHeight <= SnapPivotDistance {
+			return ErrSnapNoPivot
+		}
+		var err error
+		if pivot, err = ss.snapSyncHeaders(bc, peerHeight-SnapPivotDistance+1); err != nil {
+			return err
+		}
+		if err := rawdb.WriteSnapSyncPivot(bc.ChainDb(), pivot.Hash()); err != nil {
+			return err
+		}
+		utils.Logger().Info().
+			Uint64("pivot", pivot.Number().Uint64()).
+			Uint64("epoch", pivot.Epoch().Uint64()).
+			Msg("[SYNC] snap sync headers downloaded")
+	} else {
+		utils.Logger().Info().
+			Uint64("pivot", pivot.Number().Uint64()).
+			Uint64("epoch", pivot.Epoch().Uint64()).
+			Uint64("blocks", bc.CurrentFastBlock().NumberU64()).
+			Msg("[SYNC] resuming snap sync")
+	}
+	if err := ss.snapSyncBlocks(bc, pivot.Number().Uint64()); err != nil {
+		return err
+	}
+	utils.Logger().Info().Msg("[SYNC] snap sync blocks downloaded")
+	if err := ss.snapSyncPivot(bc, pivot); err != nil {
+		return err
+	}
+	return rawdb.DeleteSnapSyncPivot(bc.ChainDb())
+}
+
+// snapResumedPivot returns the pivot of the unfinished snap sync of bc, nil
+// if there is none
+func snapResumedPivot(bc *core.BlockChain) *block.Header {
+	hash := rawdb.ReadSnapSyncPivot(bc.ChainDb())
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return bc.GetHeaderByHash(hash)
+}
+
+// CheckpointSync brings the empty chain bc to the trusted checkpoint. The
+// header of the checkpoint is checked against its configured hash, epoch and
+// state root, then its block, receipts, state and off-chain staking data are
+// downloaded as for a snap sync pivot. The blocks after the checkpoint are
+// synced with all their commit signatures verified.
+func (ss *StateSync) CheckpointSync(bc *core.BlockChain, checkpoint *nodeconfig.Checkpoint) error {
+	hashes := [][]byte{checkpoint.Hash[:]}
+	headers, err := ss.snapDownloadHeaders(hashes)
+	if err != nil {
+		return err
+	}
+	header := headers[0]
+	if header.ShardID() != bc.ShardID() {
+		return errors.Errorf("[SYNC] checkpoint of shard %d", header.ShardID())
+	}
+	if header.Epoch().Cmp(checkpoint.Epoch) != 0 {
+		return errors.Errorf(
+			"[SYNC] checkpoint of epoch %s, expected %s", header.Epoch(), checkpoint.Epoch,
+		)
+	}
+	if header.Root() != checkpoint.Root {
+		return errors.Errorf(
+			"[SYNC] checkpoint state root %x, expected %x", header.Root(), checkpoint.Root,
+		)
+	}
+	if err := bc.WriteCheckpointHeader(header); err != nil {
+		return err
+	}
+	blocks, err := ss.snapDownloadBlocks(hashes, header.Number().Uint64())
+	if err != nil {
+		return err
+	}
+	receipts, err := ss.snapDownloadReceipts(hashes, header.Number().Uint64())
+	if err != nil {
+		return err
+	}
+	if _, err := bc.InsertSnapBlocks(blocks, receipts); err != nil {
+		return err
+	}
+	return ss.snapSyncPivot(bc, header)
+}
+
+// snapSyncPivot downloads the state and the off-chain staking data of the
+// pivot, whose header and block are inserted, and makes it the head block
+func (ss *StateSync) snapSyncPivot(bc *core.BlockChain, pivot *block.Header) error {
+	if err := snap.NewSyncer(
+		bc.ChainDb(), pivot.Root(), snapFetcher{ss},
+	).Sync(); err != nil {
+		return err
+	}
+	meta := &core.SnapStakingMeta{}
+	if err := ss.snapRequest("staking meta", servesSnap, func(peer *SyncPeerConfig) error {
+		hash := pivot.Hash()
+		response := peer.client.GetStakingMeta(hash[:])
+		if response == nil || len(response.Payload) != 1 {
+			return ErrSnapRequest
+		}
+		return rlp.DecodeBytes(response.Payload[0], meta)
+	}); err != nil {
+		return err
+	}
+	if err := bc.WriteSnapStakingMeta(pivot, meta); err != nil {
+		return err
+	}
+	return bc.SnapSyncCommitHead(pivot.Hash())
+}
+
+// snapSyncHeaders downloads and inserts the headers up to the target
+// number and returns the last epoch block before it
+func (ss *StateSync) snapSyncHeaders(bc *core.BlockChain, target uint64) (*block.Header, error) {
+	var pivot *block.Header
+	if err := ss.syncHeaderChain(bc, target, func(headers []*block.Header) error {
+		if _, err := bc.InsertSnapHeaders(headers, int(verifyHeaderBatchSize)); err != nil {
+			return err
+		}
+		for _, header := range headers {
+			if len(header.ShardState()) > 0 && header.Number().Uint64() < target {
+				pivot = header
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if pivot == nil {
+		return nil, ErrSnapNoPivot
+	}
+	return pivot, nil
+}
+
+// syncHeaderChain downloads the canonical headers after the current header
+// of bc up to the target number, and passes them in batches to insert
+func (ss *StateSync) syncHeaderChain(
+	bc *core.BlockChain, target uint64, insert func([]*block.Header) error,
+) error {
+	for current := bc.CurrentHeader(); current.Number().Uint64() < target; current = bc.CurrentHeader() {
+		size := target - current.Number().Uint64()
+		if size > uint64(SyncLoopBatchSize) {
+			size = uint64(SyncLoopBatchSize)
+		}
+		startHash := current.Hash()
+		ss.getConsensusHashes(startHash[:], uint32(size))
+		var hashes [][]byte
+		ss.syncConfig.ForEachPeer(func(peer *SyncPeerConfig) (brk bool) {
+			hashes = peer.blockHashes
+			return true
+		})
+		ss.purgeOldBlocksFromCache()
+		// the hashes start with the start hash itself
+		if len(hashes) < 2 {
+			return ErrGetBlockHash
+		}
+		hashes = hashes[1:]
+		for start := 0; start < len(hashes); start += snapHeaderBatch {
+			end := start + snapHeaderBatch
+			if end > len(hashes) {
+				end = len(hashes)
+			}
+			headers, err := ss.snapDownloadHeaders(hashes[start:end])
+			if err != nil {
+				return err
+			}
+			if err := insert(headers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapDownloadHeaders downloads the headers with the given hashes
+func (ss *StateSync) snapDownloadHeaders(hashes [][]byte) ([]*block.Header, error) {
+	var headers []*block.Header
+	err := ss.snapRequest("headers", nil, func(peer *SyncPeerConfig) error {
+		response := peer.client.GetBlockHeaders(hashes)
+		if response == nil || len(response.Payload) != len(hashes) {
+			return ErrSnapRequest
+		}
+		headers = make([]*block.Header, len(hashes))
+		for i, payload := range response.Payload {
+			headers[i] = &block.Header{}
+			if err := rlp.DecodeBytes(payload, headers[i]); err != nil {
+				return err
+			}
+			if hash := headers[i].Hash(); common.BytesToHash(hashes[i]) != hash {
+				return errors.Errorf("got header %x, expected %x", hash, hashes[i])
+			}
+		}
+		return nil
+	})
+	return headers, err
+}
+
+// snapSyncBlocks downloads and inserts the blocks and receipts of the
+// canonical headers up to the pivot number
+func (ss *StateSync) snapSyncBlocks(bc *core.BlockChain, pivot uint64) error {
+	for number := bc.CurrentFastBlock().NumberU64() + 1; number <= pivot; {
+		hashes, from := [][]byte{}, number
+		for ; number <= pivot && len(hashes) < snapBlockBatch; number++ {
+			header := bc.GetHeaderByNumber(number)
+			if header == nil {
+				return errors.Errorf("[SYNC] missing header %d", number)
+			}
+			hash := header.Hash()
+			hashes = append(hashes, hash[:])
+		}
+		blocks, err := ss.snapDownloadBlocks(hashes, from)
+		if err != nil {
+			return err
+		}
+		receipts, err := ss.snapDownloadReceipts(hashes, from)
+		if err != nil {
+			return err
+		}
+		if _, err := bc.InsertSnapBlocks(blocks, receipts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapDownloadBlocks downloads the blocks with the given hashes, from the
+// given block number
+func (ss *StateSync) snapDownloadBlocks(hashes [][]byte, from uint64) (types.Blocks, error) {
+	var blocks types.Blocks
+	err := ss.snapRequest("blocks", servesBlocks(from), func(peer *SyncPeerConfig) error {
+		payload, err := peer.GetBlocks(hashes)
+		if err != nil {
+			return err
+		}
+		if len(payload) != len(hashes) {
+			return ErrSnapRequest
+		}
+		blocks = make(types.Blocks, len(hashes))
+		for i := range payload {
+			blocks[i] = &types.Block{}
+			if err := rlp.DecodeBytes(payload[i], blocks[i]); err != nil {
+				return err
+			}
+			if hash := blocks[i].Hash(); common.BytesToHash(hashes[i]) != hash {
+				return errors.Errorf("got block %x, expected %x", hash, hashes[i])
+			}
+		}
+		return nil
+	})
+	return blocks, err
+}
+
+// snapDownloadReceipts downloads the receipts of the blocks with the given
+// hashes, from the given block number. Peers may answer with the receipts of
+// the first blocks only.
+func (ss *StateSync) snapDownloadReceipts(hashes [][]byte, from uint64) ([]types.Receipts, error) {
+	receipts := make([]types.Receipts, 0, len(hashes))
+	for len(receipts) < len(hashes) {
+		err := ss.snapRequest("receipts", servesBlocks(from+uint64(len(receipts))), func(peer *SyncPeerConfig) error {
+			response := peer.client.GetReceipts(hashes[len(receipts):])
+			if response == nil || len(response.Payload) == 0 {
+				return ErrSnapRequest
+			}
+			for _, payload := range response.Payload {
+				var blockReceipts types.Receipts
+				if err := rlp.DecodeBytes(payload, &blockReceipts); err != nil {
+					return err
+				}
+				receipts = append(receipts, blockReceipts)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return receipts, nil
+}
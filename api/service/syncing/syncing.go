@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Workiva/go-datastructures/queue"
@@ -20,9 +21,13 @@ import (
 	"github.com/harmony-one/harmony/consensus/engine"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/node/worker"
 	"github.com/harmony-one/harmony/p2p"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/pkg/errors"
 )
 
@@ -42,6 +47,13 @@ const (
 	// shall be between numPeersLowBound and numPeersHighBound
 	NumPeersLowBound  = 3
 	numPeersHighBound = 5
+
+	// a peer gains peerScoreReward for every request it serves and loses
+	// peerScorePenalty for every one it fails, at minPeerScore it is dropped
+	peerScoreReward  = 1
+	peerScorePenalty = 5
+	maxPeerScore     = 20
+	minPeerScore     = -20
 )
 
 // SyncPeerConfig is peer config to sync.
@@ -53,6 +65,8 @@ type SyncPeerConfig struct {
 	blockHashes [][]byte       // block hashes before node doing sync
 	newBlocks   []*types.Block // blocks after node doing sync
 	mux         sync.Mutex
+	score       int32          // accessed atomically
+	head        *p2p.HeadState // blocks and states the peer serves, nil if not advertised
 }
 
 // GetClient returns client pointer of downloader.Client
@@ -60,6 +74,31 @@ func (peerConfig *SyncPeerConfig) GetClient() *downloader.Client {
 	return peerConfig.client
 }
 
+// reward raises the score of the peer after it served a request
+func (peerConfig *SyncPeerConfig) reward() {
+	if atomic.LoadInt32(&peerConfig.score) < maxPeerScore {
+		atomic.AddInt32(&peerConfig.score, peerScoreReward)
+	}
+}
+
+// penalize lowers the score of the peer after it failed to serve a request
+func (peerConfig *SyncPeerConfig) penalize() {
+	atomic.AddInt32(&peerConfig.score, -peerScorePenalty)
+}
+
+// servesBlock returns whether the peer serves the body and receipts of the
+// block, the peers which did not advertise their blocks are assumed to
+// serve all of them
+func (peerConfig *SyncPeerConfig) servesBlock(number uint64) bool {
+	return peerConfig.head == nil || peerConfig.head.ServesBlock(number)
+}
+
+// servesSnap returns whether the peer serves the state ranges of snap sync,
+// the peers which did not advertise it are assumed to serve them
+func (peerConfig *SyncPeerConfig) servesSnap() bool {
+	return peerConfig.head == nil || peerConfig.head.SnapServer
+}
+
 // SyncBlockTask is the task struct to sync a specific block.
 type SyncBlockTask struct {
 	index     int
@@ -116,6 +155,26 @@ type StateSync struct {
 	stateSyncTaskQueue *queue.Queue
 	syncMux            sync.Mutex
 	lastMileMux        sync.Mutex
+	lastPeerHeight     uint64 // accessed atomically
+	snapPeerIndex      uint32 // accessed atomically
+	streamHost         libp2p_host.Host
+	streamProtocol     protocol.ID
+	verifyAllSigs      bool // verify the commit signatures of all blocks
+	// peerHead returns the blocks and states the peer advertised serving
+	peerHead func(id libp2p_peer.ID) (p2p.HeadState, bool)
+}
+
+// EnableStreamSync makes the state sync connect to its peers over libp2p
+// streams of the given protocol, which requires the peer IDs of the peers.
+func (ss *StateSync) EnableStreamSync(host libp2p_host.Host, protocolID protocol.ID) {
+	ss.streamHost = host
+	ss.streamProtocol = protocolID
+}
+
+// SetPeerHeads sets the lookup of the blocks and states the peers advertised
+// serving, the requests are routed to the peers serving them
+func (ss *StateSync) SetPeerHeads(peerHead func(id libp2p_peer.ID) (p2p.HeadState, bool)) {
+	ss.peerHead = peerHead
 }
 
 func (ss *StateSync) purgeAllBlocksFromCache() {
@@ -166,6 +225,30 @@ func (sc *SyncConfig) CloseConnections() {
 	}
 }
 
+// RemoveFaultyPeers drops the peers whose score fell to minPeerScore and
+// returns the number of dropped peers.
+func (sc *SyncConfig) RemoveFaultyPeers() int {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	removed := 0
+	for i := 0; i < len(sc.peers); i++ {
+		if atomic.LoadInt32(&sc.peers[i].score) > minPeerScore {
+			continue
+		}
+		utils.Logger().Warn().
+			Str("peerIP", sc.peers[i].ip).
+			Str("peerPort", sc.peers[i].port).
+			Msg("[SYNC] dropping faulty peer")
+		sc.peers[i].client.Close()
+		copy(sc.peers[i:], sc.peers[i+1:])
+		sc.peers[len(sc.peers)-1] = nil
+		sc.peers = sc.peers[:len(sc.peers)-1]
+		i--
+		removed++
+	}
+	return removed
+}
+
 // FindPeerByHash returns the peer with the given hash, or nil if not found.
 func (sc *SyncConfig) FindPeerByHash(peerHash []byte) *SyncPeerConfig {
 	sc.mtx.RLock()
@@ -224,8 +307,10 @@ func CompareSyncPeerConfigByblockHashes(a *SyncPeerConfig, b *SyncPeerConfig) in
 func (peerConfig *SyncPeerConfig) GetBlocks(hashes [][]byte) ([][]byte, error) {
 	response := peerConfig.client.GetBlocks(hashes)
 	if response == nil {
+		peerConfig.penalize()
 		return nil, ErrGetBlock
 	}
+	peerConfig.reward()
 	return response.Payload, nil
 }
 
@@ -253,7 +338,12 @@ func (ss *StateSync) CreateSyncConfig(peers []p2p.Peer, isBeacon bool) error {
 		wg.Add(1)
 		go func(peer p2p.Peer) {
 			defer wg.Done()
-			client := downloader.ClientSetup(peer.IP, peer.Port)
+			var client *downloader.Client
+			if ss.streamHost != nil && peer.PeerID != "" {
+				client = downloader.StreamClientSetup(ss.streamHost, peer.PeerID, ss.streamProtocol)
+			} else {
+				client = downloader.ClientSetup(peer.IP, peer.Port)
+			}
 			if client == nil {
 				return
 			}
@@ -262,6 +352,11 @@ func (ss *StateSync) CreateSyncConfig(peers []p2p.Peer, isBeacon bool) error {
 				port:   peer.Port,
 				client: client,
 			}
+			if ss.peerHead != nil && peer.PeerID != "" {
+				if head, ok := ss.peerHead(peer.PeerID); ok {
+					peerConfig.head = &head
+				}
+			}
 			ss.syncConfig.AddPeer(peerConfig)
 		}(peer)
 	}
@@ -395,8 +490,10 @@ func (ss *StateSync) getConsensusHashes(startHash []byte, size uint32) {
 					Str("peerIP", peerConfig.ip).
 					Str("peerPort", peerConfig.port).
 					Msg("[SYNC] getConsensusHashes Nil Response")
+				peerConfig.penalize()
 				return
 			}
+			peerConfig.reward()
 			if len(response.Payload) > int(size+1) {
 				utils.Logger().Warn().
 					Uint32("requestSize", size).
@@ -432,12 +529,25 @@ func (ss *StateSync) generateStateSyncTaskQueue(bc *core.BlockChain) {
 	utils.Logger().Info().Int64("length", ss.stateSyncTaskQueue.Len()).Msg("[SYNC] generateStateSyncTaskQueue: finished")
 }
 
-// downloadBlocks downloads blocks from state sync task queue.
+// downloadBlocks downloads blocks from state sync task queue, from the
+// peers serving the blocks after the current block of bc, or from all peers
+// if none advertised serving them.
 func (ss *StateSync) downloadBlocks(bc *core.BlockChain) {
 	// Initialize blockchain
 	var wg sync.WaitGroup
 	count := 0
+	from := bc.CurrentBlock().NumberU64() + 1
+	servers := 0
+	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		if peerConfig.servesBlock(from) {
+			servers++
+		}
+		return
+	})
 	ss.syncConfig.ForEachPeer(func(peerConfig *SyncPeerConfig) (brk bool) {
+		if servers > 0 && !peerConfig.servesBlock(from) {
+			return
+		}
 		wg.Add(1)
 		go func(stateSyncTaskQueue *queue.Queue, bc *core.BlockChain) {
 			defer wg.Done()
@@ -472,6 +582,7 @@ func (ss *StateSync) downloadBlocks(bc *core.BlockChain) {
 
 				if err != nil {
 					count++
+					peerConfig.penalize()
 					utils.Logger().Error().Err(err).Msg("[SYNC] downloadBlocks: failed to DecodeBytes from received new block")
 					if count > downloadBlocksRetryLimit {
 						break
@@ -556,16 +667,6 @@ func (ss *StateSync) getMaxConsensusBlockFromParentHash(parentHash common.Hash)
 	return candidateBlocks[maxFirstID]
 }
 
-func (ss *StateSync) getBlockFromOldBlocksByParentHash(parentHash common.Hash) *types.Block {
-	for _, block := range ss.commonBlocks {
-		ph := block.ParentHash()
-		if bytes.Equal(ph[:], parentHash[:]) {
-			return block
-		}
-	}
-	return nil
-}
-
 func (ss *StateSync) getBlockFromLastMileBlocksByParentHash(parentHash common.Hash) *types.Block {
 	for _, block := range ss.lastMileBlocks {
 		ph := block.ParentHash()
@@ -634,33 +735,27 @@ func (ss *StateSync) UpdateBlockAndStatus(block *types.Block, bc *core.BlockChai
 
 // generateNewState will construct most recent state from downloaded blocks
 func (ss *StateSync) generateNewState(bc *core.BlockChain, worker *worker.Worker) error {
-	// update blocks created before node start sync
-	parentHash := bc.CurrentBlock().Hash()
-
-	var err error
-	for {
-		block := ss.getBlockFromOldBlocksByParentHash(parentHash)
-		if block == nil {
-			break
-		}
-		err = ss.UpdateBlockAndStatus(block, bc, worker, false)
-		if err != nil {
-			break
-		}
-		parentHash = block.Hash()
-	}
+	// update blocks created before node start sync, verifying them ahead of their execution
 	ss.syncMux.Lock()
+	commonBlocks := make([]*types.Block, 0, len(ss.commonBlocks))
+	for _, block := range ss.commonBlocks {
+		commonBlocks = append(commonBlocks, block)
+	}
 	ss.commonBlocks = make(map[int]*types.Block)
 	ss.syncMux.Unlock()
+	_, err := insertBlocks(bc, linkBlocks(bc.CurrentBlock().Hash(), commonBlocks))
+	if err != nil {
+		utils.Logger().Error().Err(err).Msg("[SYNC] generateNewState: cannot insert downloaded blocks")
+	}
 
 	// update blocks after node start sync
-	parentHash = bc.CurrentBlock().Hash()
+	parentHash := bc.CurrentBlock().Hash()
 	for {
 		block := ss.getMaxConsensusBlockFromParentHash(parentHash)
 		if block == nil {
 			break
 		}
-		err = ss.UpdateBlockAndStatus(block, bc, worker, false)
+		err = ss.UpdateBlockAndStatus(block, bc, worker, ss.verifyAllSigs)
 		if err != nil {
 			break
 		}
@@ -681,7 +776,7 @@ func (ss *StateSync) generateNewState(bc *core.BlockChain, worker *worker.Worker
 		if block == nil {
 			break
 		}
-		err = ss.UpdateBlockAndStatus(block, bc, worker, false)
+		err = ss.UpdateBlockAndStatus(block, bc, worker, ss.verifyAllSigs)
 		if err != nil {
 			break
 		}
@@ -764,6 +859,7 @@ func (ss *StateSync) getMaxPeerHeight(isBeacon bool) uint64 {
 			response, err := peerConfig.client.GetBlockChainHeight()
 			if err != nil {
 				utils.Logger().Warn().Err(err).Str("peerIP", peerConfig.ip).Str("peerPort", peerConfig.port).Msg("[Sync]GetBlockChainHeight failed")
+				peerConfig.penalize()
 				return
 			}
 			ss.syncMux.Lock()
@@ -775,9 +871,16 @@ func (ss *StateSync) getMaxPeerHeight(isBeacon bool) uint64 {
 		return
 	})
 	wg.Wait()
+	atomic.StoreUint64(&ss.lastPeerHeight, maxHeight)
 	return maxHeight
 }
 
+// LastPeerHeight returns the highest block height the sync peers reported at
+// the last check, 0 if no peer has been asked yet
+func (ss *StateSync) LastPeerHeight() uint64 {
+	return atomic.LoadUint64(&ss.lastPeerHeight)
+}
+
 // IsSameBlockchainHeight checks whether the node is out of sync from other peers
 func (ss *StateSync) IsSameBlockchainHeight(bc *core.BlockChain) (uint64, bool) {
 	otherHeight := ss.getMaxPeerHeight(false)
@@ -802,10 +905,41 @@ func (ss *StateSync) SyncLoop(bc *core.BlockChain, worker *worker.Worker, isBeac
 	if !isBeacon {
 		ss.RegisterNodeInfo()
 	}
+	// the checkpoint belongs to the shard of the node, not to the beacon chain it follows
+	checkpoint := nodeconfig.GetCheckpoint()
+	if isBeacon {
+		checkpoint = nil
+	}
+	ss.verifyAllSigs = checkpoint != nil
+	if checkpoint != nil && bc.CurrentBlock().NumberU64() == 0 {
+		err := ss.CheckpointSync(bc, checkpoint)
+		ss.purgeOldBlocksFromCache()
+		if err != nil {
+			// syncing from genesis would not honor the checkpoint, retry instead
+			utils.Logger().Error().Err(err).
+				Str("checkpoint", checkpoint.Hash.Hex()).
+				Msgf("[SYNC] checkpoint sync failed (ShardID: %d)", bc.ShardID())
+			return
+		}
+	} else if nodeconfig.GetSnapSync() && bc.CurrentBlock().NumberU64() == 0 {
+		otherHeight := ss.getMaxPeerHeight(isBeacon)
+		if otherHeight > SnapPivotDistance {
+			if err := ss.SnapSync(bc, otherHeight); err != nil {
+				utils.Logger().Warn().Err(err).
+					Msgf("[SYNC] snap sync failed, falling back to full sync (isBeacon: %t, ShardID: %d)",
+						isBeacon, bc.ShardID())
+			}
+			ss.purgeOldBlocksFromCache()
+		}
+	}
 	// remove SyncLoopFrequency
 	ticker := time.NewTicker(SyncLoopFrequency * time.Second)
 	defer ticker.Stop()
 	for range ticker.C {
+		if ss.syncConfig.RemoveFaultyPeers() > 0 && ss.GetActivePeerNumber() == 0 {
+			utils.Logger().Warn().Msg("[SYNC] all peers dropped, stopping the sync loop")
+			break
+		}
 		otherHeight := ss.getMaxPeerHeight(isBeacon)
 		currentHeight := bc.CurrentBlock().NumberU64()
 		if currentHeight >= otherHeight {
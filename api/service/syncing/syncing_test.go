@@ -118,3 +118,52 @@ func checkTestPeerDuplicity(ps []p2p.Peer) error {
 func makeTestPeerIP(i interface{}) string {
 	return fmt.Sprintf("%v", i)
 }
+
+func TestRemoveFaultyPeers(t *testing.T) {
+	good := CreateTestSyncPeerConfig(&downloader.Client{}, nil)
+	bad := CreateTestSyncPeerConfig(&downloader.Client{}, nil)
+	sc := &SyncConfig{}
+	sc.AddPeer(good)
+	sc.AddPeer(bad)
+
+	for i := 0; i < 2*maxPeerScore; i++ {
+		good.reward()
+	}
+	good.penalize()
+	assert.Equal(t, int32(maxPeerScore-peerScorePenalty), good.score, "reward is capped")
+	for i := 0; i < -minPeerScore/peerScorePenalty-1; i++ {
+		bad.penalize()
+	}
+	assert.Equal(t, 0, sc.RemoveFaultyPeers(), "no peer reached the minimum score")
+
+	bad.penalize()
+	assert.Equal(t, 1, sc.RemoveFaultyPeers())
+	assert.Equal(t, []*SyncPeerConfig{good}, sc.peers)
+}
+
+func TestSnapPeerRouting(t *testing.T) {
+	pruned := &SyncPeerConfig{head: &p2p.HeadState{OldestBlock: 100}}
+	snap := &SyncPeerConfig{head: &p2p.HeadState{OldestBlock: 50, SnapServer: true}}
+	ss := &StateSync{syncConfig: &SyncConfig{peers: []*SyncPeerConfig{pruned, snap}}}
+
+	for i := 0; i < 4; i++ {
+		if peer, err := ss.snapPeer(servesSnap); err != nil || peer != snap {
+			t.Errorf("got peer %v (%v), expect the snap server", peer, err)
+		}
+		if peer, err := ss.snapPeer(servesBlocks(60)); err != nil || peer != snap {
+			t.Errorf("got peer %v (%v), expect the peer serving block 60", peer, err)
+		}
+	}
+	// the peers are used in turn when none serves the request
+	seen := map[*SyncPeerConfig]bool{}
+	for i := 0; i < 2; i++ {
+		peer, err := ss.snapPeer(servesBlocks(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[peer] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("got %d peers, expect both peers used", len(seen))
+	}
+}
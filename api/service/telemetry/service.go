@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	msg_pb "github.com/harmony-one/harmony/api/proto/message"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// reportTimeout bounds the delivery of a report to the endpoint
+const reportTimeout = 30 * time.Second
+
+var reportsCounter = metrics.DefaultRegistry.NewCounter(
+	"telemetry_reports_total", "Telemetry reports sent to the endpoint by result", "result",
+)
+
+// ChainReport is the sync state of a chain tracked by the node
+type ChainReport struct {
+	ShardID uint32 `json:"shardID"`
+	Block   uint64 `json:"block"`
+	Lag     uint64 `json:"lag"` // blocks behind the sync peers
+}
+
+// Report is the health of a node sent to the telemetry endpoint. It is
+// anonymized: it carries no address, key or peer ID of the node, and the
+// instance ID is random and changes on every restart.
+type Report struct {
+	Instance     string        `json:"instance"`
+	Time         int64         `json:"time"`
+	Agent        string        `json:"agent"` // harmony/<version>/<role>
+	Network      string        `json:"network"`
+	ShardID      uint32        `json:"shardID"`
+	Chains       []ChainReport `json:"chains"`
+	Peers        int           `json:"peers"`
+	InCommittee  bool          `json:"inCommittee"`
+	SignedBlocks int           `json:"signedBlocks"` // recent blocks signed by the keys of the node
+	Blocks       int           `json:"blocks"`       // recent blocks checked for the signatures
+}
+
+// Service periodically sends the report of the node to the telemetry
+// endpoint the operator opted in to.
+type Service struct {
+	config      nodeconfig.TelemetryConfig
+	report      func() Report
+	instance    string
+	client      *http.Client
+	stopChan    chan struct{}
+	stoppedChan chan struct{}
+	messageChan chan *msg_pb.Message
+}
+
+// New returns a telemetry service sending the reports returned by report
+func New(config nodeconfig.TelemetryConfig, report func() Report) *Service {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &Service{
+		config:   config,
+		report:   report,
+		instance: hex.EncodeToString(id),
+		client:   &http.Client{Timeout: reportTimeout},
+	}
+}
+
+// StartService starts the telemetry service.
+func (s *Service) StartService() {
+	utils.Logger().Info().
+		Str("endpoint", s.config.Endpoint).
+		Dur("interval", s.config.Interval).
+		Msg("Starting telemetry service, reporting the version, sync lag, peer count and consensus participation")
+	s.stopChan = make(chan struct{})
+	s.stoppedChan = make(chan struct{})
+	go s.Run()
+}
+
+// Run sends a report at every interval until the service is stopped.
+func (s *Service) Run() {
+	defer close(s.stoppedChan)
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.send(); err != nil {
+				reportsCounter.Inc("failed")
+				utils.Logger().Debug().Err(err).Msg("[Telemetry] cannot send report")
+				continue
+			}
+			reportsCounter.Inc("sent")
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// send posts the report of the node to the endpoint
+func (s *Service) send() error {
+	report := s.report()
+	report.Instance = s.instance
+	report.Time = time.Now().Unix()
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.config.Endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "cannot post report")
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("endpoint replied %s", resp.Status)
+	}
+	return nil
+}
+
+// StopService stops the telemetry service.
+func (s *Service) StopService() {
+	utils.Logger().Info().Msg("Stopping telemetry service.")
+	close(s.stopChan)
+	<-s.stoppedChan
+}
+
+// NotifyService notify service
+func (s *Service) NotifyService(params map[string]interface{}) {}
+
+// SetMessageChan sets up message channel to service.
+func (s *Service) SetMessageChan(messageChan chan *msg_pb.Message) {
+	s.messageChan = messageChan
+}
+
+// APIs for the services.
+func (s *Service) APIs() []rpc.API {
+	return nil
+}
@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+func TestServiceSendsReports(t *testing.T) {
+	reports := make(chan Report, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Error(err)
+		}
+		reports <- report
+	}))
+	defer server.Close()
+
+	s := New(nodeconfig.TelemetryConfig{Endpoint: server.URL, Interval: 10 * time.Millisecond}, func() Report {
+		return Report{Network: "testnet", Peers: 3}
+	})
+	s.StartService()
+	defer s.StopService()
+	select {
+	case report := <-reports:
+		if report.Network != "testnet" || report.Peers != 3 {
+			t.Errorf("got report %+v, expect the report of the node", report)
+		}
+		if report.Instance != s.instance || len(report.Instance) != 32 || report.Time == 0 {
+			t.Errorf("got instance %q at %d, expect the random instance ID of the service", report.Instance, report.Time)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report received")
+	}
+}
+
+func TestServiceSendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	s := New(nodeconfig.TelemetryConfig{Endpoint: server.URL, Interval: time.Minute}, func() Report {
+		return Report{}
+	})
+	if err := s.send(); err == nil {
+		t.Error("expect the rejected report to fail")
+	}
+}
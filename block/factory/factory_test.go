@@ -4,6 +4,8 @@ import (
 	"math/big"
 	"testing"
 
+	v3 "github.com/harmony-one/harmony/block/v3"
+	v4 "github.com/harmony-one/harmony/block/v4"
 	"github.com/harmony-one/harmony/internal/params"
 )
 
@@ -44,3 +46,15 @@ func Test_factory_NewHeader(t *testing.T) {
 		})
 	}
 }
+
+func Test_factory_NewHeaderV4(t *testing.T) {
+	config := *params.TestChainConfig
+	config.HeaderV4Epoch = big.NewInt(3)
+	f := NewFactory(&config)
+	if _, ok := f.NewHeader(big.NewInt(2)).Header.(*v3.Header); !ok {
+		t.Error("no v3 header before the v4 header fork")
+	}
+	if _, ok := f.NewHeader(big.NewInt(3)).Header.(*v4.Header); !ok {
+		t.Error("no v4 header from the v4 header fork on")
+	}
+}
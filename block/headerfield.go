@@ -0,0 +1,106 @@
+package block
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	blockif "github.com/harmony-one/harmony/block/interface"
+	"github.com/pkg/errors"
+)
+
+// HeaderField is a field of the block header introduced by a fork after the
+// v3 header, carried at its index among the trailing fields of the v4 header.
+//
+// The trailing fields are in the order of their introduction, so the header
+// of a fork carries the fields of the forks before it; those left unset are
+// the RLP empty string, which decodes as the zero value of the integer, byte
+// slice and string fields. The trailing fields unknown to a node, introduced
+// by a fork it does not know of, are kept as they are, so the node agrees on
+// the header hash with the nodes which know them.
+type HeaderField struct {
+	// Name is the name of the field, unique among the header fields
+	Name string
+	// Index is the position of the field among the trailing fields
+	Index int
+}
+
+var (
+	// ErrHeaderNotExtensible is returned setting a header field of a header
+	// version without trailing fields
+	ErrHeaderNotExtensible = errors.New("header version has no trailing fields")
+
+	headerFieldsLock sync.Mutex
+	headerFields     = map[int]*HeaderField{}
+)
+
+// RegisterHeaderField registers the header field of the name at the index of
+// the trailing fields, and panics if the name or the index is registered.
+// The packages introducing header fields register them at init.
+func RegisterHeaderField(name string, index int) *HeaderField {
+	headerFieldsLock.Lock()
+	defer headerFieldsLock.Unlock()
+	if index < 0 {
+		panic(errors.Errorf("negative index %d of header field %s", index, name))
+	}
+	if f, ok := headerFields[index]; ok {
+		panic(errors.Errorf("index %d of header field %s taken by %s", index, name, f.Name))
+	}
+	for _, f := range headerFields {
+		if f.Name == name {
+			panic(errors.Errorf("header field %s registered twice", name))
+		}
+	}
+	f := &HeaderField{Name: name, Index: index}
+	headerFields[index] = f
+	return f
+}
+
+// HeaderFields returns the registered header fields, in index order.
+func HeaderFields() []*HeaderField {
+	headerFieldsLock.Lock()
+	defer headerFieldsLock.Unlock()
+	fields := make([]*HeaderField, 0, len(headerFields))
+	for _, f := range headerFields {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Index < fields[j].Index })
+	return fields
+}
+
+// Field decodes the header field into val, a pointer, and returns false if
+// the header does not carry the field.
+func (h *Header) Field(f *HeaderField, val interface{}) (bool, error) {
+	eh, ok := h.Header.(blockif.ExtensibleHeader)
+	if !ok {
+		return false, nil
+	}
+	fields := eh.ExtraFields()
+	if f.Index >= len(fields) {
+		return false, nil
+	}
+	if err := rlp.DecodeBytes(fields[f.Index], val); err != nil {
+		return true, errors.Wrapf(err, "cannot decode header field %s", f.Name)
+	}
+	return true, nil
+}
+
+// SetField sets the header field to val, the fields of a lower index not
+// carried by the header being left unset.
+func (h *Header) SetField(f *HeaderField, val interface{}) error {
+	eh, ok := h.Header.(blockif.ExtensibleHeader)
+	if !ok {
+		return errors.Wrapf(ErrHeaderNotExtensible, "cannot set header field %s", f.Name)
+	}
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode header field %s", f.Name)
+	}
+	fields := eh.ExtraFields()
+	for len(fields) <= f.Index {
+		fields = append(fields, rlp.RawValue{0x80})
+	}
+	fields[f.Index] = enc
+	eh.SetExtraFields(fields)
+	return nil
+}
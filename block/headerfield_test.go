@@ -0,0 +1,123 @@
+package block
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	v3 "github.com/harmony-one/harmony/block/v3"
+	v4 "github.com/harmony-one/harmony/block/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	testBaseFee = RegisterHeaderField("test-base-fee", 0)
+	testCerts   = RegisterHeaderField("test-availability-certs", 1)
+)
+
+func TestHeaderField(t *testing.T) {
+	h := &Header{v4.NewHeader()}
+	h.SetNumber(big.NewInt(42))
+	fee := new(big.Int)
+	if ok, err := h.Field(testBaseFee, fee); ok || err != nil {
+		t.Fatalf("got field %v, error %v of a header without trailing fields", ok, err)
+	}
+	certs := [][]byte{{1, 2}, {3}}
+	if err := h.SetField(testCerts, certs); err != nil {
+		t.Fatal(err)
+	}
+	// the base fee before the certificates is unset, zero
+	if ok, err := h.Field(testBaseFee, fee); !ok || err != nil || fee.Sign() != 0 {
+		t.Fatalf("got field %v, value %v, error %v of an unset field", ok, fee, err)
+	}
+	if err := h.SetField(testBaseFee, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := rlp.EncodeToBytes(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Header{}
+	if err := rlp.DecodeBytes(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != h.Hash() || decoded.Number().Uint64() != 42 {
+		t.Error("header changed by its encoding")
+	}
+	if ok, err := decoded.Field(testBaseFee, fee); !ok || err != nil || fee.Uint64() != 1000 {
+		t.Errorf("got field %v, value %v, error %v", ok, fee, err)
+	}
+	var decodedCerts [][]byte
+	if ok, err := decoded.Field(testCerts, &decodedCerts); !ok || err != nil ||
+		len(decodedCerts) != 2 || !bytes.Equal(decodedCerts[0], certs[0]) {
+		t.Errorf("got field %v, value %x, error %v", ok, decodedCerts, err)
+	}
+}
+
+func TestHeaderUnknownFields(t *testing.T) {
+	impl := v4.NewHeader()
+	impl.SetEpoch(big.NewInt(7))
+	// the fields of a later fork, unknown to the node
+	unknown := []rlp.RawValue{{0x80}, {0x82, 0x01, 0x02}, {0xc2, 0x01, 0x02}}
+	impl.SetExtraFields(unknown)
+	data, err := rlp.EncodeToBytes(&Header{impl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Header{}
+	if err := rlp.DecodeBytes(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	reencoded, err := rlp.EncodeToBytes(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, reencoded) {
+		t.Error("trailing fields not preserved")
+	}
+	if decoded.Hash() != (&Header{impl}).Hash() {
+		t.Error("hash changed by the trailing fields")
+	}
+
+	// an update of a known field keeps the unknown ones
+	if err := decoded.SetField(testBaseFee, big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	fields := decoded.Header.(*v4.Header).ExtraFields()
+	if len(fields) != 3 || !bytes.Equal(fields[2], unknown[2]) {
+		t.Errorf("unknown fields changed to %x", fields)
+	}
+}
+
+func TestHeaderFieldNotExtensible(t *testing.T) {
+	h := &Header{v3.NewHeader()}
+	if err := h.SetField(testBaseFee, big.NewInt(1)); errors.Cause(err) != ErrHeaderNotExtensible {
+		t.Errorf("got error %v setting a field of a v3 header", err)
+	}
+	if ok, err := h.Field(testBaseFee, new(big.Int)); ok || err != nil {
+		t.Errorf("got field %v, error %v of a v3 header", ok, err)
+	}
+}
+
+func TestRegisterHeaderField(t *testing.T) {
+	for _, register := range []func(){
+		func() { RegisterHeaderField("test-other", testBaseFee.Index) },
+		func() { RegisterHeaderField(testCerts.Name, 99) },
+		func() { RegisterHeaderField("test-negative", -1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("conflicting header field registered")
+				}
+			}()
+			register()
+		}()
+	}
+	fields := HeaderFields()
+	if len(fields) != 2 || fields[0] != testBaseFee || fields[1] != testCerts {
+		t.Errorf("got header fields %v", fields)
+	}
+}
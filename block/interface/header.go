@@ -5,6 +5,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/rs/zerolog"
 
 	"github.com/harmony-one/harmony/shard"
@@ -237,3 +238,18 @@ type Header interface {
 	// It stores a copy; the caller may freely modify the original.
 	SetSlashes(newSlashes []byte)
 }
+
+// ExtensibleHeader is a header which carries trailing fields after the fields
+// of its version, the fields introduced by the later forks.
+type ExtensibleHeader interface {
+	Header
+
+	// ExtraFields is the RLP encoding of the trailing fields, including
+	// those unknown to the node.
+	// The returned slice is a copy; the caller may do anything with it.
+	ExtraFields() []rlp.RawValue
+
+	// SetExtraFields sets the RLP encoding of the trailing fields.
+	// It stores a copy; the caller may freely modify the original.
+	SetExtraFields(newExtraFields []rlp.RawValue)
+}
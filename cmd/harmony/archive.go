@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/keyring"
+	"github.com/harmony-one/harmony/internal/snapshot"
+)
+
+const archiveUsage = `Usage: harmony archive <command> [flags]
+
+Creates and restores the archives of the chain databases of a node, the
+portable snapshots fast-bootstrapping new nodes. An archive is a directory of
+compressed chunk files and of their manifest, which has the checksums of the
+chunks and is signed with an ECDSA key of the keyring of its publisher.
+
+Commands:
+  create -db_dir dir -out dir -key id [-keyring dir] [-pass src] [-chunk_size MB]
+        archive the chain databases of the database directory at their head
+        blocks, the node using it must have been shut down
+  verify -in dir [-signers addresses]
+        verify the signature of the manifest of the archive and the checksums
+        of its chunks
+  restore -in dir -db_dir dir [-signers addresses]
+        verify the archive, and only then restore its chain databases into
+        the database directory, which must not have databases of the same
+        shards
+
+The signers are the addresses trusted to sign the archives, separated by
+commas, the archives of any signer are accepted if there are none.
+`
+
+// runArchiveCommand runs the archive subcommand of the node, and returns the
+// exit code
+func runArchiveCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, archiveUsage)
+		return 2
+	}
+	commands := map[string]func(*flag.FlagSet, []string) int{
+		"create":  runArchiveCreate,
+		"verify":  runArchiveVerify,
+		"restore": runArchiveRestore,
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, archiveUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("archive "+args[0], flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, archiveUsage) }
+	return run(flags, args[1:])
+}
+
+func runArchiveCreate(flags *flag.FlagSet, args []string) int {
+	dir := flags.String("db_dir", "", "blockchain database directory")
+	out := flags.String("out", "", "directory the archive is written to, it must be empty")
+	chunkSize := flags.Int64("chunk_size", 1024, "size of the chunks of the archive in megabytes")
+	keyringDir := flags.String("keyring", ".hmy/keyring", "keyring directory")
+	keyID := flags.String("key", "", "ECDSA key of the keyring signing the manifest")
+	passSrc := flags.String("pass", "", "source of the passphrase of the key")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *dir == "" || *out == "" || *keyID == "" || *chunkSize <= 0 {
+		fmt.Fprintln(os.Stderr, "ERROR expect -db_dir, -out, -key and a positive -chunk_size")
+		return 2
+	}
+	passphrase, err := readPassphrase(*passSrc, "Enter the passphrase of the key:")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the passphrase: %s\n", err)
+		return 1
+	}
+	key, err := keyring.New(*keyringDir).ECDSAKey(*keyID, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot unlock the key: %s\n", err)
+		return 1
+	}
+	m, err := snapshot.CreateArchive(*dir, *out, *chunkSize<<20, shortVersion(), key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create the archive: %s\n", err)
+		return 1
+	}
+	printManifest(m)
+	return 0
+}
+
+func runArchiveVerify(flags *flag.FlagSet, args []string) int {
+	in := flags.String("in", "", "directory of the archive")
+	signers := flags.String("signers", "", "addresses trusted to sign the archive, separated by commas")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "ERROR expect -in")
+		return 2
+	}
+	trusted, err := parseSigners(*signers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR invalid -signers: %s\n", err)
+		return 2
+	}
+	m, err := snapshot.LoadManifest(*in)
+	if err == nil {
+		err = m.Verify(trusted)
+	}
+	if err == nil {
+		err = snapshot.VerifyChunks(*in, m)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 1
+	}
+	printManifest(m)
+	return 0
+}
+
+func runArchiveRestore(flags *flag.FlagSet, args []string) int {
+	in := flags.String("in", "", "directory of the archive")
+	dir := flags.String("db_dir", "", "blockchain database directory the archive is restored into")
+	signers := flags.String("signers", "", "addresses trusted to sign the archive, separated by commas")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *in == "" || *dir == "" {
+		fmt.Fprintln(os.Stderr, "ERROR expect -in and -db_dir")
+		return 2
+	}
+	trusted, err := parseSigners(*signers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR invalid -signers: %s\n", err)
+		return 2
+	}
+	m, err := snapshot.RestoreArchive(*in, *dir, trusted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot restore the archive: %s\n", err)
+		return 1
+	}
+	printManifest(m)
+	return 0
+}
+
+// parseSigners parses the addresses of the trusted signers, separated by
+// commas
+func parseSigners(s string) ([]common.Address, error) {
+	var signers []common.Address
+	for _, signer := range strings.Split(s, ",") {
+		if signer = strings.TrimSpace(signer); signer == "" {
+			continue
+		}
+		address, err := common2.Bech32ToAddress(signer)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, address)
+	}
+	return signers, nil
+}
+
+// printManifest prints the sections and the signer of the archive
+func printManifest(m *snapshot.Manifest) {
+	for _, section := range m.Sections {
+		fmt.Println(section)
+	}
+	fmt.Printf("%d chunks, version %s, signed by %s\n", len(m.Chunks), m.Version, m.Signer)
+}
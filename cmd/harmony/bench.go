@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/bench"
+	"github.com/harmony-one/harmony/internal/chain"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/snapshot"
+	"github.com/harmony-one/harmony/shard"
+)
+
+const benchUsage = `Usage: harmony bench -fixture file [-shard_id id] [-blocks N] [-workloads list] [-network_type type] [-json]
+
+Runs the standardized workloads on the last N blocks of the chain database of
+the shard in the fixture, a snapshot file of an archival node as exported by
+-export_snapshot, and reports their throughput. The fixture is imported into a
+temporary directory, removed afterwards. The workloads are:
+
+  replay   execute the blocks again on the state of their parents
+  rewards  compute the block rewards over the cross-links of the beacon chain
+           blocks, on shard 0 only
+  import   rewind the chain below the blocks and insert them again, verifying
+           and executing them as the node does
+`
+
+// runBenchCommand runs the bench subcommand of the node, and returns the exit
+// code
+func runBenchCommand(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fixture := flags.String("fixture", "", "snapshot file of the chain databases the workloads run on")
+	shardID := flags.Uint("shard_id", 0, "Shard of the chain database the workloads run on")
+	numBlocks := flags.Uint64("blocks", 100, "Number of the last blocks of the chain the workloads run on")
+	workloads := flags.String("workloads", strings.Join(bench.Workloads, ","), "Workloads to run, separated by commas")
+	network := flags.String("network_type", "mainnet", "type of the network: mainnet, testnet, pangaea, partner, stressnet, devnet, localnet")
+	asJSON := flags.Bool("json", false, "print the results as JSON lines")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, benchUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *fixture == "" || *numBlocks == 0 || flags.NArg() > 0 {
+		fmt.Fprint(os.Stderr, benchUsage)
+		return 2
+	}
+	selected := map[string]bool{}
+	for _, workload := range strings.Split(*workloads, ",") {
+		if !isBenchWorkload(workload) {
+			fmt.Fprintf(os.Stderr, "ERROR unknown workload %q\n", workload)
+			return 2
+		}
+		selected[workload] = true
+	}
+	if err := setShardSchedule(*network); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 2
+	}
+
+	dir, err := ioutil.TempDir("", "harmony-bench")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot create the temporary directory: %s\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	if _, err := snapshot.Import(*fixture, dir); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot import the fixture: %s\n", err)
+		return 1
+	}
+	db, err := ethdb.NewLDBDatabase(snapshot.ChainDBDir(dir, uint32(*shardID)), 0, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR no chain database of shard %d in the fixture: %s\n", *shardID, err)
+		return 1
+	}
+	defer db.Close()
+	chainConfig := nodeconfig.NetworkType(*network).ChainConfig()
+	bc, err := core.NewBlockChain(db, nil, &chainConfig, chain.Engine, vm.Config{}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot load the chain: %s\n", err)
+		return 1
+	}
+	defer bc.Stop()
+
+	from := uint64(1)
+	if head := bc.CurrentBlock().NumberU64(); head > *numBlocks {
+		from = head - *numBlocks + 1
+	}
+	blocks, err := bench.Blocks(bc, from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 1
+	}
+	runners := map[string]func(*core.BlockChain, types.Blocks) (*bench.Result, error){
+		bench.Replay:  bench.RunReplay,
+		bench.Rewards: bench.RunRewards,
+		bench.Import:  bench.RunImport,
+	}
+	results := []*bench.Result{}
+	for _, workload := range bench.Workloads {
+		if !selected[workload] {
+			continue
+		}
+		if workload == bench.Rewards && bc.ShardID() != shard.BeaconChainShardID {
+			fmt.Fprintf(os.Stderr, "skipping the %s workload, shard %d is not the beacon chain\n", workload, *shardID)
+			continue
+		}
+		result, err := runners[workload](bc, blocks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR %s workload failed: %s\n", workload, err)
+			return 1
+		}
+		results = append(results, result)
+	}
+	if err := printBenchResults(results, *asJSON); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func isBenchWorkload(workload string) bool {
+	for _, w := range bench.Workloads {
+		if workload == w {
+			return true
+		}
+	}
+	return false
+}
+
+// printBenchResults prints the results as a table, or as JSON lines
+func printBenchResults(results []*bench.Result, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKLOAD\tBLOCKS\tTXS\tCROSS-LINKS\tELAPSED\tBLOCKS/S\tTXS/S\tMGAS/S\tCROSS-LINKS/S")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%.2f\t%.2f\t%.2f\t%.2f\n",
+			r.Workload, r.Blocks, r.Txs, r.CrossLinks, r.Elapsed,
+			r.BlocksPerSecond(), r.TxsPerSecond(), r.MGasPerSecond(), r.CrossLinksPerSecond(),
+		)
+	}
+	return w.Flush()
+}
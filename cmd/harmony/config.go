@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	viperconfig "github.com/harmony-one/harmony/internal/configs/viper"
+	"github.com/spf13/viper"
+)
+
+const defaultConfigFile = "./.hmy/nodeconfig.json"
+
+const configUsage = `Usage: harmony config migrate [-dry_run] [config file]
+
+Upgrades the node config file, ` + defaultConfigFile + ` by default, to the
+current config version, and reports the deprecated keys and the keys and
+values the node ignores. The original file is kept with the .bak extension.
+`
+
+// runConfigCommand runs the config subcommand of the node, and returns the
+// exit code
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "migrate" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("config migrate", flag.ContinueOnError)
+	dryRun := flags.Bool("dry_run", false, "Report the changes without writing the config file")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, configUsage) }
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	path := defaultConfigFile
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+
+	conf, err := viperconfig.ReadConfigFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read config file: %s\n", err)
+		return 1
+	}
+	version, _ := viperconfig.Version(conf)
+	warnings, err := viperconfig.MigrateConfig(conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot migrate %s: %s\n", path, err)
+		return 1
+	}
+	// the keys the node reads are known once it read them
+	resetFlagsFromConfig(viper.New(), viper.New())
+	warnings = append(warnings, viperconfig.CheckConfig(conf, flag.CommandLine)...)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING %s\n", warning)
+	}
+	if *dryRun {
+		fmt.Printf("%s: config version %d would be migrated to %d\n", path, version, viperconfig.ConfigVersion)
+		return 0
+	}
+	if err := os.Rename(path, path+".bak"); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot back up config file: %s\n", err)
+		return 1
+	}
+	if err := viperconfig.WriteConfigFile(path, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot write config file: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s: config version %d migrated to %d\n", path, version, viperconfig.ConfigVersion)
+	return 0
+}
+
+// checkConfigFile stops the node on a config file newer than the node, and
+// warns about an old config file and about the keys and values it ignores
+func checkConfigFile(configFileViper *viper.Viper) {
+	conf := configFileViper.AllSettings()
+	version, err := viperconfig.Version(conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		os.Exit(1)
+	}
+	if version > viperconfig.ConfigVersion {
+		fmt.Fprintf(os.Stderr,
+			"ERROR config version %d is newer than the version %d of this node\n",
+			version, viperconfig.ConfigVersion,
+		)
+		os.Exit(1)
+	}
+	if len(conf) > 0 && version < viperconfig.ConfigVersion {
+		fmt.Fprintf(os.Stderr,
+			"WARNING config version %d is older than %d, run `harmony config migrate`\n",
+			version, viperconfig.ConfigVersion,
+		)
+	}
+	for _, warning := range viperconfig.CheckConfig(conf, flag.CommandLine) {
+		fmt.Fprintf(os.Stderr, "WARNING %s\n", warning)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/migration"
+	"github.com/harmony-one/harmony/internal/snapshot"
+)
+
+const dbUsage = `Usage: harmony db <command> [-db_dir dir] [-shard_id id] [flags]
+
+Commands:
+  inspect
+        walk the chain databases of the database directory, and report the
+        number of items and their size per category: headers, bodies,
+        receipts, trie nodes, validator snapshots, staking data, indexes, and
+        so on
+  migrate [-to version] [-dry_run]
+        migrate the storage format of the chain databases to the schema
+        version, the latest one if -1, applying the migrations up or rolling
+        them back down, as the node does up to the latest one at startup.
+        -dry_run reports the changes of the migrations without writing them.
+        Rolling back is needed to run an older node on the databases.
+
+The node using the database directory must be stopped.
+`
+
+// runDBCommand runs the db subcommand of the node, and returns the exit code
+func runDBCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, dbUsage)
+		return 2
+	}
+	switch args[0] {
+	case "inspect":
+		return runDBInspect(args[1:])
+	case "migrate":
+		return runDBMigrate(args[1:])
+	}
+	fmt.Fprint(os.Stderr, dbUsage)
+	return 2
+}
+
+func runDBInspect(args []string) int {
+	flags := flag.NewFlagSet("db inspect", flag.ContinueOnError)
+	dir := flags.String("db_dir", "", "blockchain database directory")
+	shardID := flags.Int("shard_id", -1, "Inspect the chain database of this shard only, -1 for all of them")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, dbUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	shards, err := snapshot.ChainDBs(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot list chain databases: %s\n", err)
+		return 1
+	}
+	inspected := 0
+	for _, shard := range shards {
+		if *shardID >= 0 && uint32(*shardID) != shard {
+			continue
+		}
+		if err := inspectChainDB(*dir, shard); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot inspect the chain database of shard %d: %s\n", shard, err)
+			return 1
+		}
+		inspected++
+	}
+	if inspected == 0 {
+		fmt.Fprintf(os.Stderr, "ERROR no chain database to inspect in %q\n", *dir)
+		return 1
+	}
+	return 0
+}
+
+func runDBMigrate(args []string) int {
+	flags := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	dir := flags.String("db_dir", "", "blockchain database directory")
+	shardID := flags.Int("shard_id", -1, "Migrate the chain database of this shard only, -1 for all of them")
+	to := flags.Int64("to", -1, "schema version to migrate to, -1 for the latest one")
+	dryRun := flags.Bool("dry_run", false, "report the changes of the migrations without writing them")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, dbUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	version := migration.Latest(migration.Migrations)
+	if *to >= 0 {
+		version = uint64(*to)
+	}
+
+	shards, err := snapshot.ChainDBs(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot list chain databases: %s\n", err)
+		return 1
+	}
+	migrated := 0
+	for _, shard := range shards {
+		if *shardID >= 0 && uint32(*shardID) != shard {
+			continue
+		}
+		if err := migrateChainDB(*dir, shard, version, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot migrate the chain database of shard %d: %s\n", shard, err)
+			return 1
+		}
+		migrated++
+	}
+	if migrated == 0 {
+		fmt.Fprintf(os.Stderr, "ERROR no chain database to migrate in %q\n", *dir)
+		return 1
+	}
+	return 0
+}
+
+// migrateChainDB migrates the chain database of the shard to the version, and
+// prints the steps taken
+func migrateChainDB(dir string, shardID uint32, version uint64, dryRun bool) error {
+	db, err := ethdb.NewLDBDatabase(snapshot.ChainDBDir(dir, shardID), 0, 0)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	from := migration.Version(db)
+	steps, err := migration.Migrate(db, migration.Migrations, version, dryRun)
+	for _, step := range steps {
+		direction := "up"
+		if step.Down {
+			direction = "down"
+		}
+		fmt.Printf("shard %d: %s %d %s: %d puts, %d deletes\n",
+			shardID, direction, step.Version, step.Description, step.Puts, step.Deletes)
+	}
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("shard %d: would migrate from schema version %d to %d\n", shardID, from, version)
+	} else {
+		fmt.Printf("shard %d: migrated from schema version %d to %d\n", shardID, from, version)
+	}
+	return nil
+}
+
+// inspectChainDB prints the stats of the chain database of the shard
+func inspectChainDB(dir string, shardID uint32) error {
+	path := snapshot.ChainDBDir(dir, shardID)
+	db, err := ethdb.NewLDBDatabase(path, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := rawdb.InspectDatabase(db.NewIterator())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("shard %d: %s\n", shardID, path)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tITEMS\tSIZE")
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", stat.Category, stat.Count, common.StorageSize(stat.Size))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/keyring"
+	"github.com/harmony-one/harmony/internal/ledger"
+	"github.com/harmony-one/harmony/internal/utils"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+const keysUsage = `Usage: harmony keys <command> [-dir dir] [flags]
+
+Manages the BLS and the ECDSA keys of a validator in the keyring directory,
+the BLS keys in its bls folder, which is usable as the -blsfolder of the node,
+and the ECDSA keys in its ecdsa folder, as encrypted keystore files.
+
+Commands:
+  new -type bls|ecdsa [-pass src] [-save_pass]
+        generate a key encrypted with the passphrase, -save_pass writes the
+        passphrase file of a BLS key next to it
+  list
+        list the keys of the keyring
+  export -out file [-pass src] [id ...]
+        write the keys of the IDs, all of them if none, into a bundle
+        encrypted with the passphrase, to move them to another host
+  import [-pass src] file
+        import the keys of a bundle decrypted with the passphrase
+  ledger-address
+        print the address of the Ledger device
+  sign-staking -chain_id id (-key id [-pass src] | -ledger) file
+        sign the staking transaction, RLP encoded in hex in the file, with an
+        ECDSA key of the keyring or the Ledger device, and print it signed
+
+The passphrase sources are pass:passphrase, env:var, file:path, fd:number or
+stdin, the passphrase is prompted for if there is none.
+`
+
+// runKeysCommand runs the keys subcommand of the node, and returns the exit
+// code
+func runKeysCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, keysUsage)
+		return 2
+	}
+	commands := map[string]func(*flag.FlagSet, []string) int{
+		"new":            runKeysNew,
+		"list":           runKeysList,
+		"export":         runKeysExport,
+		"import":         runKeysImport,
+		"ledger-address": runKeysLedgerAddress,
+		"sign-staking":   runKeysSignStaking,
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, keysUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("keys "+args[0], flag.ContinueOnError)
+	flags.String("dir", ".hmy/keyring", "keyring directory")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, keysUsage) }
+	return run(flags, args[1:])
+}
+
+func runKeysNew(flags *flag.FlagSet, args []string) int {
+	keyType := flags.String("type", "", "type of the key, bls or ecdsa")
+	passSrc := flags.String("pass", "", "source of the passphrase of the key")
+	savePass := flags.Bool("save_pass", false, "write the passphrase file of the BLS key")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	passphrase, err := readPassphrase(*passSrc, "Enter the passphrase of the new key:")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the passphrase: %s\n", err)
+		return 1
+	}
+	var key *keyring.Key
+	switch keyring.KeyType(*keyType) {
+	case keyring.BLS:
+		key, err = flagKeyring(flags).NewBLSKey(passphrase, *savePass)
+	case keyring.ECDSA:
+		key, err = flagKeyring(flags).NewECDSAKey(passphrase)
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR invalid key type %q, expect bls or ecdsa\n", *keyType)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot generate the key: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s %s %s\n", key.Type, key.ID, key.Path)
+	return 0
+}
+
+func runKeysList(flags *flag.FlagSet, args []string) int {
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	keys, err := flagKeyring(flags).Keys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot list the keys: %s\n", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tID\tPASS FILE\tPATH")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", key.Type, key.ID, key.HasPass, key.Path)
+	}
+	if err := w.Flush(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runKeysExport(flags *flag.FlagSet, args []string) int {
+	out := flags.String("out", "", "file of the bundle")
+	passSrc := flags.String("pass", "", "source of the passphrase of the bundle")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "ERROR -out is required")
+		return 2
+	}
+	passphrase, err := readPassphrase(*passSrc, "Enter the passphrase of the bundle:")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the passphrase: %s\n", err)
+		return 1
+	}
+	data, err := flagKeyring(flags).Export(flags.Args(), passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot export the keys: %s\n", err)
+		return 1
+	}
+	if err := ioutil.WriteFile(*out, data, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot write the bundle: %s\n", err)
+		return 1
+	}
+	fmt.Printf("exported the keys into %s\n", *out)
+	return 0
+}
+
+func runKeysImport(flags *flag.FlagSet, args []string) int {
+	passSrc := flags.String("pass", "", "source of the passphrase of the bundle")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "ERROR expect the file of the bundle")
+		return 2
+	}
+	data, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the bundle: %s\n", err)
+		return 1
+	}
+	passphrase, err := readPassphrase(*passSrc, "Enter the passphrase of the bundle:")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the passphrase: %s\n", err)
+		return 1
+	}
+	keys, err := flagKeyring(flags).Import(data, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot import the keys: %s\n", err)
+		return 1
+	}
+	for _, key := range keys {
+		fmt.Printf("%s %s %s\n", key.Type, key.ID, key.Path)
+	}
+	return 0
+}
+
+func runKeysLedgerAddress(flags *flag.FlagSet, args []string) int {
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	nanos, err := ledger.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot open the Ledger device: %s\n", err)
+		return 1
+	}
+	defer nanos.Close()
+	address, err := nanos.Address()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the address: %s\n", err)
+		return 1
+	}
+	fmt.Println(common2.MustAddressToBech32(address))
+	return 0
+}
+
+func runKeysSignStaking(flags *flag.FlagSet, args []string) int {
+	chainID := flags.Uint64("chain_id", 0, "chain ID the transaction is signed for")
+	keyID := flags.String("key", "", "ECDSA key of the keyring signing the transaction")
+	passSrc := flags.String("pass", "", "source of the passphrase of the key")
+	useLedger := flags.Bool("ledger", false, "sign the transaction with the Ledger device")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *chainID == 0 || (*keyID == "") == !*useLedger {
+		fmt.Fprintln(os.Stderr, "ERROR expect -chain_id, one of -key or -ledger, and the transaction file")
+		return 2
+	}
+	data, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the transaction: %s\n", err)
+		return 1
+	}
+	encoded, err := hexutil.Decode(strings.TrimSpace(string(data)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR the transaction is not in hex: %s\n", err)
+		return 1
+	}
+	tx := &staking.StakingTransaction{}
+	if err := rlp.DecodeBytes(encoded, tx); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot decode the transaction: %s\n", err)
+		return 1
+	}
+
+	id := new(big.Int).SetUint64(*chainID)
+	var signed *staking.StakingTransaction
+	if *useLedger {
+		nanos, err := ledger.Open()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot open the Ledger device: %s\n", err)
+			return 1
+		}
+		defer nanos.Close()
+		fmt.Fprintln(os.Stderr, "Confirm the transaction on the Ledger device")
+		signed, err = nanos.SignStaking(tx, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot sign the transaction: %s\n", err)
+			return 1
+		}
+	} else {
+		passphrase, err := readPassphrase(*passSrc, "Enter the passphrase of the key:")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot read the passphrase: %s\n", err)
+			return 1
+		}
+		key, err := flagKeyring(flags).ECDSAKey(*keyID, passphrase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot decrypt the key: %s\n", err)
+			return 1
+		}
+		signed, err = staking.Sign(tx, staking.NewEIP155Signer(id), key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot sign the transaction: %s\n", err)
+			return 1
+		}
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot encode the transaction: %s\n", err)
+		return 1
+	}
+	fmt.Println(hexutil.Encode(raw))
+	return 0
+}
+
+// flagKeyring returns the keyring of the -dir flag of the parsed flags
+func flagKeyring(flags *flag.FlagSet) *keyring.Keyring {
+	return keyring.New(flags.Lookup("dir").Value.String())
+}
+
+// readPassphrase reads the passphrase from the source, or prompts for it if
+// there is no source
+func readPassphrase(src, prompt string) (string, error) {
+	if src == "" {
+		return utils.AskForPassphrase(prompt), nil
+	}
+	passphrase, err := utils.GetPassphraseFromSource(src)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(passphrase, "\r\n"), nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/harmony-one/harmony/internal/keyring"
+	"github.com/harmony-one/harmony/internal/localnet"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/p2p"
+	badger "github.com/ipfs/go-ds-badger"
+	kaddht "github.com/libp2p/go-libp2p-kad-dht"
+)
+
+const localnetUsage = `Usage: harmony localnet [-shards N] [-validators N] [-dir dir] [flags] [-- node flags]
+
+Runs a localnet of the validators assigned evenly to the shards, each in a
+node process of this binary managed by this command, which also runs their
+bootnode. The localnet has the fast epochs and the staking epoch of the
+localnet network type, each shard then also having -external_slots slots
+elected among the staking validators. Its BLS keys, its validator accounts
+and its developer accounts are generated into the keyring of the directory,
+encrypted with an empty passphrase, and the accounts are funded in the
+genesis blocks. The databases
+and the logs of the nodes are kept in the directory, so the localnet resumes
+when the command is run again, unless -reset is set. The node flags after --
+are passed to every node. Interrupting the command stops the localnet, which
+is also stopped when a node exits.
+
+Flags:
+`
+
+// localnetStopTimeout bounds the shutdown of the nodes of the localnet
+const localnetStopTimeout = time.Minute
+
+// localnetNode is a node process of the localnet
+type localnetNode struct {
+	shardID uint32
+	port    int
+	blsKey  string
+	logFile string
+	cmd     *exec.Cmd
+}
+
+// localnetExit is the exit of a node of the localnet
+type localnetExit struct {
+	node *localnetNode
+	err  error
+}
+
+// runLocalnetCommand runs the localnet subcommand of the node, and returns the
+// exit code
+func runLocalnetCommand(args []string) int {
+	flags := flag.NewFlagSet("localnet", flag.ContinueOnError)
+	shards := flags.Uint("shards", 2, "Number of shards of the localnet")
+	validators := flags.Int("validators", 8, "Number of genesis validators of the localnet, a multiple of -shards")
+	externalSlots := flags.Int("external_slots", 3, "Number of slots of each shard elected among the staking validators once staking is enabled")
+	devAccounts := flags.Int("dev_accounts", 4, "Number of funded developer accounts")
+	dir := flags.String("dir", ".localnet", "directory of the keys, the databases and the logs of the localnet")
+	basePort := flags.Int("base_port", 9000, "p2p port of the first node, the nodes having the next ones, and their HTTP RPC 500 above")
+	bootnodePort := flags.Int("bootnode_port", 19876, "p2p port of the bootnode")
+	verbosity := flags.Int("verbosity", 3, "Logging verbosity of the nodes: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail")
+	reset := flags.Bool("reset", false, "delete the localnet of the directory and generate a new one")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, localnetUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *shards == 0 || *validators <= 0 || *validators%int(*shards) != 0 {
+		fmt.Fprintf(os.Stderr, "ERROR cannot assign %d validators evenly to %d shards\n", *validators, *shards)
+		return 2
+	}
+
+	if *reset {
+		if err := os.RemoveAll(*dir); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot delete the localnet: %s\n", err)
+			return 1
+		}
+	}
+	specFile := filepath.Join(*dir, localnet.SpecFile)
+	var (
+		spec *localnet.Spec
+		err  error
+	)
+	if localnet.Exists(*dir) {
+		fmt.Printf("resuming the localnet of %s, -reset generates a new one\n", *dir)
+		spec, err = localnet.Load(specFile)
+	} else {
+		fmt.Printf("generating a localnet of %d shards and %d validators into %s\n", *shards, *validators, *dir)
+		spec, err = localnet.Generate(*dir, uint32(*shards), *validators, *externalSlots, *devAccounts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 1
+	}
+	for _, sub := range []string{"logs", "p2p"} {
+		if err := os.MkdirAll(filepath.Join(*dir, sub), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+			return 1
+		}
+	}
+
+	bootnode, err := startLocalnetBootnode(*dir, *bootnodePort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot start the bootnode: %s\n", err)
+		return 1
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 1
+	}
+	blsDir := localnet.Keyring(*dir).Dir(keyring.BLS)
+	nodes := make([]*localnetNode, 0, len(spec.Validators))
+	exits := make(chan localnetExit, len(spec.Validators))
+	for i, validator := range spec.Validators {
+		node := &localnetNode{
+			shardID: uint32(i) % spec.Shards,
+			port:    *basePort + i,
+			blsKey:  validator.BLSPublicKey,
+		}
+		nodeArgs := []string{
+			"-network_type", "localnet",
+			"-localnet_spec", specFile,
+			"-ip", "127.0.0.1",
+			"-port", strconv.Itoa(node.port),
+			"-bootnodes", bootnode,
+			"-dns=false",
+			"-min_peers", strconv.Itoa(len(spec.Validators) / int(spec.Shards)),
+			"-blskey_file", filepath.Join(blsDir, validator.BLSPublicKey+".key"),
+			"-blspass", "file:" + filepath.Join(blsDir, validator.BLSPublicKey+".pass"),
+			"-key", filepath.Join(*dir, "p2p", strconv.Itoa(node.port)+".key"),
+			"-db_dir", filepath.Join(*dir, "db", strconv.Itoa(node.port)),
+			"-log_folder", filepath.Join(*dir, "logs"),
+			"-verbosity", strconv.Itoa(*verbosity),
+		}
+		if err := node.start(exe, append(nodeArgs, flags.Args()...), *dir); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR cannot start the node of port %d: %s\n", node.port, err)
+			stopLocalnet(nodes, exits)
+			return 1
+		}
+		go func() {
+			exits <- localnetExit{node, node.cmd.Wait()}
+		}()
+		nodes = append(nodes, node)
+	}
+	printLocalnet(spec, nodes, *dir)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-signals:
+		fmt.Println("stopping the localnet")
+		stopLocalnet(nodes, exits)
+		return 0
+	case exit := <-exits:
+		fmt.Fprintf(os.Stderr, "ERROR the node of port %d exited: %v, see %s\n",
+			exit.node.port, exit.err, exit.node.logFile)
+		exit.node.cmd = nil
+		stopLocalnet(nodes, exits)
+		return 1
+	}
+}
+
+// start starts the process of the node, its output going into its log file
+// in the directory of the localnet
+func (n *localnetNode) start(exe string, args []string, dir string) error {
+	n.logFile = filepath.Join(dir, "logs", fmt.Sprintf("node-%d.out", n.port))
+	out, err := os.OpenFile(n.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n.cmd = exec.Command(exe, args...)
+	n.cmd.Stdout, n.cmd.Stderr = out, out
+	// the nodes are stopped by this command, not by the signals of the terminal
+	n.cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return n.cmd.Start()
+}
+
+// startLocalnetBootnode starts the bootnode of the localnet in this process,
+// and returns its multiaddress
+func startLocalnetBootnode(dir string, port int) (string, error) {
+	key, _, err := utils.LoadKeyFromFile(filepath.Join(dir, "bootnode.key"))
+	if err != nil {
+		return "", err
+	}
+	host, err := p2p.NewHost(&p2p.Peer{IP: "127.0.0.1", Port: strconv.Itoa(port)}, key)
+	if err != nil {
+		return "", err
+	}
+	dataStore, err := badger.NewDatastore(filepath.Join(dir, "dht"), nil)
+	if err != nil {
+		return "", err
+	}
+	dht := kaddht.NewDHT(context.Background(), host.GetP2PHost(), dataStore)
+	if err := dht.Bootstrap(context.Background()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", port, host.GetID().Pretty()), nil
+}
+
+// stopLocalnet signals the running nodes to stop, and kills those still
+// running after localnetStopTimeout
+func stopLocalnet(nodes []*localnetNode, exits chan localnetExit) {
+	running := 0
+	for _, node := range nodes {
+		if node.cmd == nil {
+			continue
+		}
+		// a node which exited already has its exit sent anyway
+		node.cmd.Process.Signal(syscall.SIGTERM)
+		running++
+	}
+	timeout := time.After(localnetStopTimeout)
+	for ; running > 0; running-- {
+		select {
+		case <-exits:
+		case <-timeout:
+			for _, node := range nodes {
+				if node.cmd != nil {
+					node.cmd.Process.Kill()
+				}
+			}
+			return
+		}
+	}
+}
+
+// printLocalnet prints the nodes and the developer accounts of the localnet
+func printLocalnet(spec *localnet.Spec, nodes []*localnetNode, dir string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "SHARD\tPORT\tRPC\tBLS KEY\tOUTPUT")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%d\t%d\thttp://127.0.0.1:%d\t%s\t%s\n",
+			node.shardID, node.port, node.port+500, node.blsKey, node.logFile)
+	}
+	w.Flush()
+	fmt.Printf("\ndeveloper accounts, in the keystore %s with an empty passphrase:\n",
+		localnet.Keyring(dir).Dir(keyring.ECDSA))
+	for _, account := range spec.DevAccounts {
+		fmt.Printf("  %s\n", account)
+	}
+	fmt.Println("\npress Ctrl-C to stop the localnet")
+}
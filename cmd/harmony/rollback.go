@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/chain"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/snapshot"
+)
+
+const rollbackUsage = `Usage: harmony rollback -to_block N -shard_id id [-db_dir dir] [-network_type type]
+
+Rewinds the chain database of the shard to the block N, which must have its
+state in the database. The blocks above it are deleted with their receipts,
+their validator snapshots and their off-chain indexes, and the new head block
+is verified against its roots. The node using the database directory must be
+stopped.
+`
+
+// runRollbackCommand runs the rollback subcommand of the node, and returns the
+// exit code
+func runRollbackCommand(args []string) int {
+	flags := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	toBlock := flags.Int64("to_block", -1, "Number of the block to rewind the chain to")
+	shardID := flags.Int("shard_id", -1, "Shard of the chain database to rewind")
+	dir := flags.String("db_dir", "", "blockchain database directory")
+	network := flags.String("network_type", "mainnet", "type of the network: mainnet, testnet, pangaea, partner, stressnet, devnet, localnet")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, rollbackUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *toBlock < 0 || *shardID < 0 || flags.NArg() > 0 {
+		fmt.Fprint(os.Stderr, rollbackUsage)
+		return 2
+	}
+	if err := setShardSchedule(*network); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR %s\n", err)
+		return 2
+	}
+
+	path := snapshot.ChainDBDir(*dir, uint32(*shardID))
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR no chain database of shard %d: %s\n", *shardID, err)
+		return 1
+	}
+	// the database is locked while the node runs
+	db, err := ethdb.NewLDBDatabase(path, 0, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot open %s, the node must be stopped: %s\n", path, err)
+		return 1
+	}
+	defer db.Close()
+	if rawdb.ReadCanonicalHash(db, 0) == (common.Hash{}) {
+		fmt.Fprintf(os.Stderr, "ERROR %s has no chain\n", path)
+		return 1
+	}
+
+	chainConfig := nodeconfig.NetworkType(*network).ChainConfig()
+	bc, err := core.NewBlockChain(db, nil, &chainConfig, chain.Engine, vm.Config{}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot load the chain: %s\n", err)
+		return 1
+	}
+	defer bc.Stop()
+	head := bc.CurrentBlock().NumberU64()
+	if err := bc.RollbackTo(uint64(*toBlock)); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot roll back shard %d: %s\n", *shardID, err)
+		return 1
+	}
+	fmt.Printf("shard %d rolled back from block %d to %d %s\n",
+		*shardID, head, *toBlock, bc.CurrentBlock().Hash().Hex())
+	return 0
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/harmony-one/harmony/consensus/signguard"
+)
+
+const slashingProtectionUsage = `Usage: harmony slashing-protection <command> -guard file [flags]
+
+Manages the slashing protection database of the validator keys of a node, the
+file of -signing_guard recording every vote signed with each BLS key. Moving
+keys to another node, export their votes from the database of the node they
+are moved from once it is stopped, and import them into the database of the
+node they are moved to before it is started, which then refuses to sign the
+votes conflicting with those signed before the move.
+
+Commands:
+  export -guard file -out file [-keys keys]
+        export the last votes of the keys, separated by commas, or of all the
+        keys of the database if there are none, to the interchange file
+  import -guard file -in file
+        import the votes of the interchange file into the database, the node
+        using it must be stopped
+  list -guard file
+        list the last votes of the keys of the database
+`
+
+// runSlashingProtectionCommand runs the slashing-protection subcommand of the
+// node, and returns the exit code
+func runSlashingProtectionCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, slashingProtectionUsage)
+		return 2
+	}
+	commands := map[string]func(*flag.FlagSet, []string) int{
+		"export": runSlashingProtectionExport,
+		"import": runSlashingProtectionImport,
+		"list":   runSlashingProtectionList,
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, slashingProtectionUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("slashing-protection "+args[0], flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, slashingProtectionUsage) }
+	return run(flags, args[1:])
+}
+
+// openGuard opens the slashing protection database, which must exist
+func openGuard(path string) (*signguard.Guard, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return signguard.New(path, false)
+}
+
+func runSlashingProtectionExport(flags *flag.FlagSet, args []string) int {
+	guardPath := flags.String("guard", "", "slashing protection database")
+	out := flags.String("out", "", "interchange file the votes are exported to")
+	keys := flags.String("keys", "", "serialized BLS public keys whose votes are exported, separated by commas")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *guardPath == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "ERROR expect -guard and -out")
+		return 2
+	}
+	var exported []string
+	for _, key := range strings.Split(*keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			exported = append(exported, key)
+		}
+	}
+	guard, err := openGuard(*guardPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot open the slashing protection database: %s\n", err)
+		return 1
+	}
+	ic := guard.Export(exported)
+	data, err := json.MarshalIndent(ic, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(*out, data, 0600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot write the interchange file: %s\n", err)
+		return 1
+	}
+	fmt.Printf("exported %d votes to %s\n", len(ic.Records), *out)
+	return 0
+}
+
+func runSlashingProtectionImport(flags *flag.FlagSet, args []string) int {
+	guardPath := flags.String("guard", "", "slashing protection database, created if needed")
+	in := flags.String("in", "", "interchange file of the imported votes")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *guardPath == "" || *in == "" {
+		fmt.Fprintln(os.Stderr, "ERROR expect -guard and -in")
+		return 2
+	}
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot read the interchange file: %s\n", err)
+		return 1
+	}
+	ic := &signguard.Interchange{}
+	if err := json.Unmarshal(data, ic); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR invalid interchange file: %s\n", err)
+		return 1
+	}
+	guard, err := signguard.New(*guardPath, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot open the slashing protection database: %s\n", err)
+		return 1
+	}
+	n, err := guard.Import(ic)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot import the votes: %s\n", err)
+		return 1
+	}
+	fmt.Printf("imported %d of the %d votes, the others are older than the recorded ones\n", n, len(ic.Records))
+	return 0
+}
+
+func runSlashingProtectionList(flags *flag.FlagSet, args []string) int {
+	guardPath := flags.String("guard", "", "slashing protection database")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *guardPath == "" {
+		fmt.Fprintln(os.Stderr, "ERROR expect -guard")
+		return 2
+	}
+	guard, err := openGuard(*guardPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR cannot open the slashing protection database: %s\n", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tPHASE\tBLOCK\tVIEW\tHASH")
+	for _, key := range guard.Keys() {
+		name := key
+		if key == signguard.AnyKey {
+			name = "(all keys)"
+		}
+		for _, phase := range signguard.Phases {
+			if vote, ok := guard.LastVote(phase, key); ok {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", name, phase, vote.BlockNum, vote.ViewID, vote.BlockHash.Hex())
+			}
+		}
+	}
+	w.Flush()
+	return 0
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
@@ -39,6 +40,8 @@ type Consensus struct {
 	blockNum uint64
 	// How long to delay sending commit messages.
 	delayCommit time.Duration
+	// Whether the node is leaving consensus, 0 is false, != 0 is true
+	leaving uint32
 	// Consensus rounds whose commit phase finished
 	commitFinishChan chan uint64
 	// 2 types of timeouts: normal and viewchange
@@ -75,6 +78,9 @@ type Consensus struct {
 	// private/public keys of current node
 	priKey *multibls.PrivateKey
 	PubKey *multibls.PublicKey
+	// SigningGuard records the votes signed with the keys and disables the
+	// signing on a standby node, nil if the keys are not guarded
+	SigningGuard *signguard.Guard
 	// the publickey of leader
 	LeaderPubKey *bls.PublicKey
 	viewID       uint64
@@ -98,6 +104,9 @@ type Consensus struct {
 	// The post-consensus processing func passed from Node object
 	// Called when consensus on a new block is done
 	OnConsensusDone func(*types.Block)
+	// The view change notification func passed from Node object, called
+	// with the leaders the view change is from and to
+	OnViewChange func(viewID uint64, from, to *bls.PublicKey)
 	// The verifier func passed from Node object
 	BlockVerifier func(*types.Block) error
 	// verified block to state sync broadcast
@@ -173,6 +182,17 @@ func (consensus *Consensus) GetConsensusLeaderPrivateKey() (*bls.SecretKey, erro
 	return consensus.GetLeaderPrivateKey(consensus.LeaderPubKey)
 }
 
+// SetPrivateKey replaces the bls keys signing the consensus messages, on a
+// reload of the keys
+func (consensus *Consensus) SetPrivateKey(multiBLSPriKey *multibls.PrivateKey) {
+	consensus.mutex.Lock()
+	defer consensus.mutex.Unlock()
+	consensus.priKey = multiBLSPriKey
+	consensus.PubKey = multiBLSPriKey.GetPublicKey()
+	utils.Logger().Info().
+		Str("publicKey", consensus.PubKey.SerializeToHexStr()).Msg("My Public Key")
+}
+
 // TODO: put shardId into chain reader's chain config
 
 // New create a new Consensus record
@@ -126,3 +126,16 @@ func (sender *MessageSender) StopAllRetriesExceptCommitted() {
 		return true
 	})
 }
+
+// Flush sends the messages being retried once more right away, so that they
+// are delivered before the node stops.
+func (sender *MessageSender) Flush() {
+	sender.messagesToRetry.Range(func(k, v interface{}) bool {
+		if msgRetry, ok := v.(*MessageRetry); ok && atomic.LoadUint32(&msgRetry.isActive) != 0 {
+			if err := sender.host.SendMessageToGroups(msgRetry.groups, msgRetry.p2pMsg); err != nil {
+				utils.Logger().Warn().Str("groupID[0]", msgRetry.groups[0].String()).Uint64("blockNum", msgRetry.blockNum).Str("MsgType", msgRetry.msgType.String()).Msg("[Flush] Failed re-sending consensus message")
+			}
+		}
+		return true
+	})
+}
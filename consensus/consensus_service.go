@@ -11,6 +11,7 @@ import (
 	"github.com/harmony-one/harmony/block"
 	consensus_engine "github.com/harmony-one/harmony/consensus/engine"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/crypto/hash"
 	"github.com/harmony-one/harmony/internal/chain"
@@ -131,9 +132,35 @@ func (consensus *Consensus) signMessage(message []byte, priKey *bls.SecretKey) [
 	return signature.Serialize()
 }
 
+// signingActive returns whether the node signs with its keys, which is not
+// the case of a standby node
+func (consensus *Consensus) signingActive() bool {
+	return consensus.SigningGuard == nil || consensus.SigningGuard.Active()
+}
+
+// guardVote records the vote of the phase in the slashing protection of the
+// keys before it is signed with them
+func (consensus *Consensus) guardVote(
+	phase signguard.Phase, keys []*bls.PublicKey, blockNum, viewID uint64, blockHash common.Hash,
+) error {
+	if consensus.SigningGuard == nil {
+		return nil
+	}
+	serialized := make([]string, len(keys))
+	for i, key := range keys {
+		serialized[i] = key.SerializeToHexStr()
+	}
+	return consensus.SigningGuard.Vote(phase, serialized, signguard.Vote{
+		BlockNum: blockNum, ViewID: viewID, BlockHash: blockHash,
+	})
+}
+
 // Sign on the consensus message signature field.
 func (consensus *Consensus) signConsensusMessage(message *msg_pb.Message,
 	priKey *bls.SecretKey) error {
+	if !consensus.signingActive() {
+		return signguard.ErrStandby
+	}
 	message.Signature = nil
 	// TODO: use custom serialization method rather than protobuf
 	marshaledMessage, err := protobuf.Marshal(message)
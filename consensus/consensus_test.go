@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"testing"
+	"time"
 
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/crypto/bls"
@@ -35,3 +36,46 @@ func TestNew(test *testing.T) {
 		test.Error("Consensus ReadySignal should be initialized")
 	}
 }
+
+func TestLeave(test *testing.T) {
+	leader := p2p.Peer{IP: "127.0.0.1", Port: "9903"}
+	priKey, _, _ := utils.GenKeyP2P("127.0.0.1", "9903")
+	host, err := p2p.NewHost(&leader, priKey)
+	if err != nil {
+		test.Fatalf("newhost failure: %v", err)
+	}
+	decider := quorum.NewDecider(
+		quorum.SuperMajorityVote, shard.BeaconChainShardID,
+	)
+	consensus, err := New(
+		host, shard.BeaconChainShardID, leader, multibls.GetPrivateKey(bls.RandPrivateKey()), decider,
+	)
+	if err != nil {
+		test.Fatalf("Cannot craeate consensus: %v", err)
+	}
+
+	// the round in progress ends while the node is leaving
+	consensus.switchPhase(FBFTCommit, true)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		consensus.mutex.Lock()
+		consensus.switchPhase(FBFTAnnounce, true)
+		consensus.mutex.Unlock()
+	}()
+	start := time.Now()
+	consensus.Leave(time.Minute)
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond || elapsed > 10*time.Second {
+		test.Errorf("Leave returned after %v, expected at the end of the round", elapsed)
+	}
+	if !consensus.isLeaving() {
+		test.Error("Consensus should be leaving")
+	}
+
+	// a round which does not end is left at the timeout
+	consensus.switchPhase(FBFTPrepare, true)
+	start = time.Now()
+	consensus.Leave(200 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 10*time.Second {
+		test.Errorf("Leave returned after %v, expected at the timeout", elapsed)
+	}
+}
@@ -396,6 +396,12 @@ func (consensus *Consensus) Start(
 				consensus.getLogger().Info().
 					Uint64("MsgBlockNum", newBlock.NumberU64()).
 					Msg("[ConsensusMainLoop] Received Proposed New Block!")
+				if consensus.isLeaving() {
+					consensus.getLogger().Info().
+						Uint64("MsgBlockNum", newBlock.NumberU64()).
+						Msg("[ConsensusMainLoop] Leaving consensus, not announcing the new block")
+					continue
+				}
 
 				//VRF/VDF is only generated in the beacon chain
 				if consensus.NeedsRandomNumberGeneration(newBlock.Header().Epoch()) {
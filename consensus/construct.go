@@ -7,6 +7,7 @@ import (
 	"github.com/harmony-one/harmony/api/proto"
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	"github.com/harmony-one/harmony/internal/utils"
 )
 
@@ -67,10 +68,20 @@ func (consensus *Consensus) construct(
 		buffer.Write(consensus.prepareBitmap.Bitmap)
 		consensusMsg.Payload = buffer.Bytes()
 	case msg_pb.MessageType_PREPARE:
+		if err := consensus.guardVote(
+			signguard.Prepare, []*bls.PublicKey{pubKey}, consensus.blockNum, consensus.viewID, consensus.blockHash,
+		); err != nil {
+			return nil, err
+		}
 		if s := priKey.SignHash(consensusMsg.BlockHash); s != nil {
 			consensusMsg.Payload = s.Serialize()
 		}
 	case msg_pb.MessageType_COMMIT:
+		if err := consensus.guardVote(
+			signguard.Commit, []*bls.PublicKey{pubKey}, consensus.blockNum, consensus.viewID, consensus.blockHash,
+		); err != nil {
+			return nil, err
+		}
 		if s := priKey.SignHash(payloadForSign); s != nil {
 			consensusMsg.Payload = s.Serialize()
 		}
@@ -8,6 +8,7 @@ import (
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/consensus/signature"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	"github.com/harmony-one/harmony/core/types"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/p2p"
@@ -56,6 +57,13 @@ func (consensus *Consensus) announce(block *types.Block) {
 	consensus.FBFTLog.AddBlock(block)
 
 	// Leader sign the block hash itself
+	if err := consensus.guardVote(
+		signguard.Prepare, consensus.PubKey.PublicKey,
+		block.NumberU64(), block.Header().ViewID().Uint64(), block.Hash(),
+	); err != nil {
+		consensus.getLogger().Warn().Err(err).Msg("[Announce] Leader prepare vote refused")
+		return
+	}
 	for i, key := range consensus.PubKey.PublicKey {
 		if err := consensus.prepareBitmap.SetKey(key, true); err != nil {
 			consensus.getLogger().Warn().Err(err).Msgf(
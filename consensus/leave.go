@@ -0,0 +1,42 @@
+package consensus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// how often a leaving node checks whether the round in progress ended
+const leaveCheckInterval = 100 * time.Millisecond
+
+// Leave makes the node leave consensus after the round in progress. The node
+// stops proposing and voting for new blocks, waits up to timeout for the
+// round in progress to end, then broadcasts the messages it holds for retry
+// so that the other nodes can end the round without it.
+func (consensus *Consensus) Leave(timeout time.Duration) {
+	atomic.StoreUint32(&consensus.leaving, 1)
+	deadline := time.Now().Add(timeout)
+	for consensus.roundInProgress() {
+		if time.Now().After(deadline) {
+			consensus.getLogger().Warn().
+				Uint64("blockNum", consensus.blockNum).
+				Msg("[Leave] Leaving consensus before the end of the round")
+			break
+		}
+		time.Sleep(leaveCheckInterval)
+	}
+	consensus.msgSender.Flush()
+	consensus.getLogger().Info().Msg("[Leave] Left consensus")
+}
+
+// isLeaving returns whether the node is leaving consensus
+func (consensus *Consensus) isLeaving() bool {
+	return atomic.LoadUint32(&consensus.leaving) != 0
+}
+
+// roundInProgress returns whether the node takes part in a round that did
+// not commit its block yet
+func (consensus *Consensus) roundInProgress() bool {
+	consensus.mutex.Lock()
+	defer consensus.mutex.Unlock()
+	return consensus.current.Mode() == Normal && consensus.phase != FBFTAnnounce
+}
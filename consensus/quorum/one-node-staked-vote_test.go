@@ -4,12 +4,14 @@ import (
 	"math/big"
 	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 
 	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/consensus/votepower"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
 )
@@ -69,7 +71,7 @@ func setupBaseCase() (Decider, *TallyResult, shard.SlotList, map[string]secretKe
 	decider := NewDecider(SuperMajorityStake, shard.BeaconChainShardID)
 	decider.UpdateParticipants(pubKeys)
 	tally, err := decider.SetVoters(&shard.Committee{
-		shard.BeaconChainShardID, slotList,
+		ShardID: shard.BeaconChainShardID, Slots: slotList,
 	}, big.NewInt(3))
 	if err != nil {
 		panic("Unable to SetVoters for Base Case")
@@ -96,7 +98,7 @@ func setupEdgeCase() (Decider, *TallyResult, shard.SlotList, secretKeyMap) {
 	decider := NewDecider(SuperMajorityStake, shard.BeaconChainShardID)
 	decider.UpdateParticipants(pubKeys)
 	tally, err := decider.SetVoters(&shard.Committee{
-		shard.BeaconChainShardID, slotList,
+		ShardID: shard.BeaconChainShardID, Slots: slotList,
 	}, big.NewInt(3))
 	if err != nil {
 		panic("Unable to SetVoters for Edge Case")
@@ -234,3 +236,44 @@ func Test33HarmonyNodes(t *testing.T) {
 			strconv.FormatBool(rewarded))
 	}
 }
+
+func TestSetRawStakeConcurrentCompute(t *testing.T) {
+	slotList := shard.SlotList{}
+	for i := 0; i < quorumNodes; i++ {
+		newSlot, _ := generateRandomSlot()
+		slotList = append(slotList, newSlot)
+	}
+	committee := &shard.Committee{
+		ShardID: shard.BeaconChainShardID, Slots: slotList,
+	}
+	decider := NewDecider(SuperMajorityStake, shard.BeaconChainShardID)
+	if _, err := decider.SetVoters(committee, big.NewInt(3)); err != nil {
+		t.Fatal(err)
+	}
+	setter := decider.(*stakedVoteWeight)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range slotList {
+			setter.SetRawStake(slotList[i].BLSPublicKey, numeric.NewDec(int64(i+1)))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range slotList {
+			roster, err := votepower.Compute(committee, big.NewInt(3))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for _, voter := range roster.Voters {
+				if !voter.RawStake.IsZero() {
+					t.Error("raw stake of a decider set on the cached roster")
+					return
+				}
+			}
+		}
+	}()
+	wg.Wait()
+}
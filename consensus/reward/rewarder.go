@@ -2,6 +2,7 @@ package reward
 
 import (
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/harmony/shard"
@@ -13,6 +14,18 @@ type Payout struct {
 	Addr        common.Address
 	NewlyEarned *big.Int
 	EarningKey  shard.BLSPublicKey
+	// Delegators is the split of NewlyEarned credited to the delegators of the
+	// validator, none if nothing was credited to them or the split was not
+	// asked for
+	Delegators []DelegatorPayout
+}
+
+// DelegatorPayout is the part of a payout credited to a delegator of the
+// validator, the commission and the rounding remainder going to the self
+// delegation
+type DelegatorPayout struct {
+	Delegator common.Address `json:"delegator"`
+	Amount    *big.Int       `json:"amount"`
 }
 
 // CompletedRound ..
@@ -20,10 +33,83 @@ type CompletedRound struct {
 	Total            *big.Int
 	BeaconchainAward []Payout
 	ShardChainAward  []Payout
+	// Remainder is the part of Total left by the rounding of the rewards of
+	// the beacon chain signers, given to the signer of the most voting power
+	Remainder *big.Int
 }
 
 // Reader ..
 type Reader interface {
 	ReadRoundResult() *CompletedRound
+	ReadBreakdown() *Breakdown
 	MissingSigners() shard.SlotList
 }
+
+// Breakdown is the payout of a round by shard, by signer of the blocks of the
+// shard and by delegator of the validator of the signer. It encodes to JSON
+// and RLP.
+type Breakdown struct {
+	Total     *big.Int       `json:"total"`
+	Remainder *big.Int       `json:"remainder"`
+	Shards    []ShardPayouts `json:"shards"`
+}
+
+// ShardPayouts is the payout of the signers of the blocks of a shard
+type ShardPayouts struct {
+	ShardID uint32         `json:"shard-id"`
+	Amount  *big.Int       `json:"amount"`
+	Signers []SignerPayout `json:"signers"`
+}
+
+// SignerPayout is the payout of a signing key of a validator
+type SignerPayout struct {
+	Validator  common.Address     `json:"validator"`
+	EarningKey shard.BLSPublicKey `json:"earning-key"`
+	Amount     *big.Int           `json:"amount"`
+	Delegators []DelegatorPayout  `json:"delegators"`
+}
+
+// NewBreakdown returns the breakdown of the payouts of the round, the shards
+// in ID order and the signers of each in payout order
+func NewBreakdown(round *CompletedRound) *Breakdown {
+	breakdown := &Breakdown{
+		Total: new(big.Int), Remainder: new(big.Int), Shards: []ShardPayouts{},
+	}
+	if round.Total != nil {
+		breakdown.Total.Set(round.Total)
+	}
+	if round.Remainder != nil {
+		breakdown.Remainder.Set(round.Remainder)
+	}
+	byShard := map[uint32]int{}
+	for _, payouts := range [...][]Payout{round.BeaconchainAward, round.ShardChainAward} {
+		for _, payout := range payouts {
+			i, ok := byShard[payout.ShardID]
+			if !ok {
+				i = len(breakdown.Shards)
+				byShard[payout.ShardID] = i
+				breakdown.Shards = append(breakdown.Shards, ShardPayouts{
+					ShardID: payout.ShardID, Amount: new(big.Int), Signers: []SignerPayout{},
+				})
+			}
+			shardPayouts := &breakdown.Shards[i]
+			shardPayouts.Amount.Add(shardPayouts.Amount, payout.NewlyEarned)
+			delegators := make([]DelegatorPayout, len(payout.Delegators))
+			for j, delegator := range payout.Delegators {
+				delegators[j] = DelegatorPayout{
+					Delegator: delegator.Delegator, Amount: new(big.Int).Set(delegator.Amount),
+				}
+			}
+			shardPayouts.Signers = append(shardPayouts.Signers, SignerPayout{
+				Validator:  payout.Addr,
+				EarningKey: payout.EarningKey,
+				Amount:     new(big.Int).Set(payout.NewlyEarned),
+				Delegators: delegators,
+			})
+		}
+	}
+	sort.SliceStable(breakdown.Shards, func(i, j int) bool {
+		return breakdown.Shards[i].ShardID < breakdown.Shards[j].ShardID
+	})
+	return breakdown
+}
@@ -0,0 +1,80 @@
+package reward
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestNewBreakdown(t *testing.T) {
+	validator, delegator := common.Address{0x01}, common.Address{0x02}
+	round := &CompletedRound{
+		Total: big.NewInt(600),
+		BeaconchainAward: []Payout{{
+			ShardID: 0, Addr: validator, NewlyEarned: big.NewInt(100),
+			EarningKey: shard.BLSPublicKey{0x01},
+			Delegators: []DelegatorPayout{
+				{Delegator: validator, Amount: big.NewInt(70)},
+				{Delegator: delegator, Amount: big.NewInt(30)},
+			},
+		}},
+		ShardChainAward: []Payout{
+			{ShardID: 2, Addr: validator, NewlyEarned: big.NewInt(200), EarningKey: shard.BLSPublicKey{0x02}},
+			{ShardID: 1, Addr: validator, NewlyEarned: big.NewInt(100), EarningKey: shard.BLSPublicKey{0x03}},
+			{ShardID: 2, Addr: delegator, NewlyEarned: big.NewInt(200), EarningKey: shard.BLSPublicKey{0x04}},
+		},
+	}
+	breakdown := NewBreakdown(round)
+	if breakdown.Total.Int64() != 600 || breakdown.Remainder.Sign() != 0 {
+		t.Errorf("got total %v remainder %v", breakdown.Total, breakdown.Remainder)
+	}
+	amounts := map[uint32]int64{0: 100, 1: 100, 2: 400}
+	signers := map[uint32]int{0: 1, 1: 1, 2: 2}
+	if len(breakdown.Shards) != 3 {
+		t.Fatalf("got shards %+v", breakdown.Shards)
+	}
+	for i, shardPayouts := range breakdown.Shards {
+		if shardPayouts.ShardID != uint32(i) ||
+			shardPayouts.Amount.Int64() != amounts[shardPayouts.ShardID] ||
+			len(shardPayouts.Signers) != signers[shardPayouts.ShardID] {
+			t.Errorf("got shard payouts %+v", shardPayouts)
+		}
+	}
+	if signer := breakdown.Shards[0].Signers[0]; len(signer.Delegators) != 2 ||
+		signer.Delegators[1].Delegator != delegator || signer.Delegators[1].Amount.Int64() != 30 {
+		t.Errorf("got signer payout %+v", signer)
+	}
+	// the breakdown does not share the amounts of the round
+	breakdown.Shards[0].Signers[0].Delegators[0].Amount.SetInt64(0)
+	if round.BeaconchainAward[0].Delegators[0].Amount.Int64() != 70 {
+		t.Error("breakdown changed the round")
+	}
+
+	encoded, err := rlp.EncodeToBytes(breakdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Breakdown{}
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if reencoded, _ := rlp.EncodeToBytes(decoded); !bytes.Equal(reencoded, encoded) {
+		t.Errorf("got RLP decoded %+v, expected %+v", decoded, breakdown)
+	}
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded = &Breakdown{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if remarshaled, _ := json.Marshal(decoded); !bytes.Equal(remarshaled, data) {
+		t.Errorf("got JSON decoded %s, expected %s", remarshaled, data)
+	}
+}
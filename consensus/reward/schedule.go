@@ -150,3 +150,19 @@ func PercentageForTimeStamp(ts int64) numeric.Dec {
 
 	return bucket.share
 }
+
+// Release is the share of the genesis supply of the token release schedule
+// released from Time on
+type Release struct {
+	Time  int64
+	Share numeric.Dec
+}
+
+// ReleasePlan returns the token release schedule of Harmony, in time order
+func ReleasePlan() []Release {
+	plan := make([]Release, len(sorted))
+	for i, p := range sorted {
+		plan[i] = Release{Time: p.ts, Share: p.share}
+	}
+	return plan
+}
@@ -0,0 +1,335 @@
+// Package signguard protects the BLS keys of a validator run by a node and
+// its hot standby from signing conflicting votes. The guard records every
+// vote of each key in a slashing protection database before it is signed,
+// and a leased guard only lets the node sign while it holds the signing
+// lease. The votes of the keys are exported and imported in an interchange
+// format, so the keys can be moved to another node without the risk of a
+// double sign.
+package signguard
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Phase is a consensus phase whose votes are recorded by the guard
+type Phase string
+
+// Phases of the recorded votes
+const (
+	Prepare Phase = "prepare"
+	Commit  Phase = "commit"
+)
+
+// Phases are the phases of the recorded votes
+var Phases = []Phase{Prepare, Commit}
+
+// AnyKey is the key of the votes recorded for all the keys, those of the
+// slashing protection files of the guards before the votes were recorded per
+// key
+const AnyKey = ""
+
+// maxRecords is the number of records of the database beyond which it is
+// compacted to the last vote of each key and phase
+const maxRecords = 1 << 18
+
+// Errors of the votes refused by the guard
+var (
+	ErrStandby         = errors.New("signing is disabled until the signing lease is held")
+	ErrConflictingVote = errors.New("vote conflicts with a signed vote of the same block and view")
+	ErrStaleVote       = errors.New("vote is older than a signed vote")
+)
+
+// Vote is a vote signed in a phase
+type Vote struct {
+	BlockNum  uint64      `json:"block-num"`
+	ViewID    uint64      `json:"view-id"`
+	BlockHash common.Hash `json:"block-hash"`
+}
+
+// before returns whether the vote is of an earlier block or view
+func (v Vote) before(other Vote) bool {
+	return v.BlockNum < other.BlockNum ||
+		v.BlockNum == other.BlockNum && v.ViewID < other.ViewID
+}
+
+// Record is a vote signed with a key, the records of the database
+type Record struct {
+	// Key is the serialized BLS public key in hex, AnyKey for all of them
+	Key   string `json:"key"`
+	Phase Phase  `json:"phase"`
+	Vote
+}
+
+// Guard is the slashing protection of the validator keys. Its database is a
+// file of the records of the votes, one JSON record per line, appended
+// before each vote is signed.
+type Guard struct {
+	lock        sync.Mutex
+	path        string
+	votes       map[string]map[Phase]Vote
+	records     int
+	leased      bool
+	activeUntil time.Time
+	now         func() time.Time
+}
+
+// New returns the guard recording the votes in the database file at path. A
+// leased guard refuses to sign until it is activated by the holder of the
+// signing lease, the other guards always sign.
+func New(path string, leased bool) (*Guard, error) {
+	g := &Guard{path: path, leased: leased, now: time.Now}
+	if err := g.Reload(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Reload reads the votes from the database of the guard, which was written
+// by the previous holder of the signing lease when the database is shared
+func (g *Guard) Reload() error {
+	records, err := readRecords(g.path)
+	if err != nil {
+		return err
+	}
+	votes := map[string]map[Phase]Vote{}
+	for _, r := range records {
+		addVote(votes, r)
+	}
+	g.lock.Lock()
+	g.votes, g.records = votes, len(records)
+	g.lock.Unlock()
+	return nil
+}
+
+// readRecords reads the records of the database file, the last votes of
+// each phase of a slashing protection file of the earlier guards being
+// records of AnyKey
+func readRecords(path string) ([]Record, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read slashing protection database")
+	}
+	legacy := map[Phase]Vote{}
+	if json.Unmarshal(data, &legacy) == nil {
+		records := []Record{}
+		for _, phase := range Phases {
+			if vote, ok := legacy[phase]; ok {
+				records = append(records, Record{Key: AnyKey, Phase: phase, Vote: vote})
+			}
+		}
+		return records, nil
+	}
+	records := []Record{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		r := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// a record torn by a crash while it was appended was not signed
+			if !bytes.HasSuffix(data, []byte("\n")) && line == bytes.Count(data, []byte("\n"))+1 {
+				break
+			}
+			return nil, errors.Wrapf(err, "invalid slashing protection database %s at line %d", path, line)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// addVote records the vote as the last one of its key and phase, unless it
+// is older than the last one
+func addVote(votes map[string]map[Phase]Vote, r Record) {
+	phases, ok := votes[r.Key]
+	if !ok {
+		phases = map[Phase]Vote{}
+		votes[r.Key] = phases
+	}
+	if last, ok := phases[r.Phase]; !ok || last.before(r.Vote) {
+		phases[r.Phase] = r.Vote
+	}
+}
+
+// ActivateUntil lets a leased guard sign until the expiry of the lease
+func (g *Guard) ActivateUntil(expiry time.Time) {
+	g.lock.Lock()
+	g.activeUntil = expiry
+	g.lock.Unlock()
+}
+
+// Deactivate stops a leased guard from signing
+func (g *Guard) Deactivate() {
+	g.ActivateUntil(time.Time{})
+}
+
+// Active returns whether the guard lets the node sign
+func (g *Guard) Active() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.active()
+}
+
+func (g *Guard) active() bool {
+	return !g.leased || g.now().Before(g.activeUntil)
+}
+
+// LastVote returns the last vote signed in the phase with the key, or with
+// all the keys by the earlier guards if it is later
+func (g *Guard) LastVote(phase Phase, key string) (Vote, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.lastVote(phase, key)
+}
+
+func (g *Guard) lastVote(phase Phase, key string) (Vote, bool) {
+	vote, ok := g.votes[key][phase]
+	if any, anyOK := g.votes[AnyKey][phase]; anyOK && (!ok || vote.before(any)) {
+		return any, true
+	}
+	return vote, ok
+}
+
+// Vote records the vote of the phase for the keys, which must only be
+// signed with them if no error is returned. A vote can be signed again, but
+// not a vote of another block at the same block number and view, nor a vote
+// older than the last one of a key.
+func (g *Guard) Vote(phase Phase, keys []string, vote Vote) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.active() {
+		return ErrStandby
+	}
+	records := []Record{}
+	for _, key := range keys {
+		last, ok := g.lastVote(phase, key)
+		if ok {
+			switch {
+			case vote.before(last):
+				return errors.Wrapf(ErrStaleVote, "%s of block %d view %d after block %d view %d",
+					phase, vote.BlockNum, vote.ViewID, last.BlockNum, last.ViewID)
+			case vote.BlockNum == last.BlockNum && vote.ViewID == last.ViewID:
+				if vote.BlockHash != last.BlockHash {
+					return errors.Wrapf(ErrConflictingVote, "%s of block %d view %d: signed %s, got %s",
+						phase, vote.BlockNum, vote.ViewID, last.BlockHash.Hex(), vote.BlockHash.Hex())
+				}
+				continue
+			}
+		}
+		records = append(records, Record{Key: key, Phase: phase, Vote: vote})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if err := g.append(records); err != nil {
+		return err
+	}
+	for _, r := range records {
+		addVote(g.votes, r)
+	}
+	return nil
+}
+
+// append appends the records to the database, which is compacted first if
+// it has too many records or is of the earlier format
+func (g *Guard) append(records []Record) error {
+	if g.records+len(records) > maxRecords || g.legacy() {
+		if err := g.compact(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0700); err != nil {
+		return errors.Wrap(err, "cannot create slashing protection directory")
+	}
+	f, err := os.OpenFile(g.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrap(err, "cannot open slashing protection database")
+	}
+	defer f.Close()
+	data, err := encodeRecords(records)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return errors.Wrap(err, "cannot write slashing protection database")
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Wrap(err, "cannot write slashing protection database")
+	}
+	g.records += len(records)
+	return nil
+}
+
+// legacy returns whether the database file is a slashing protection file of
+// the earlier guards, which is rewritten in the format of the database
+// before a vote is appended
+func (g *Guard) legacy() bool {
+	f, err := os.Open(g.path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 2)
+	n, _ := f.Read(head)
+	// the records are on one line, the earlier files are indented
+	return n == 2 && head[0] == '{' && head[1] == '\n'
+}
+
+// compact rewrites the database with the last vote of each key and phase
+func (g *Guard) compact() error {
+	records := []Record{}
+	for key, phases := range g.votes {
+		for _, phase := range Phases {
+			if vote, ok := phases[phase]; ok {
+				records = append(records, Record{Key: key, Phase: phase, Vote: vote})
+			}
+		}
+	}
+	if err := writeRecords(g.path, records); err != nil {
+		return err
+	}
+	g.records = len(records)
+	return nil
+}
+
+func encodeRecords(records []Record) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRecords replaces the database file with the records
+func writeRecords(path string, records []Record) error {
+	data, err := encodeRecords(records)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err, "cannot create slashing protection directory")
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "cannot write slashing protection database")
+	}
+	return os.Rename(tmp, path)
+}
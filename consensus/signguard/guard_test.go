@@ -0,0 +1,159 @@
+package signguard
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+func TestGuardVote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guard.json")
+	g, err := New(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := common.HexToHash("0xa"), common.HexToHash("0xb")
+	tests := []struct {
+		phase Phase
+		vote  Vote
+		err   error
+	}{
+		{Commit, Vote{10, 5, a}, nil},
+		{Commit, Vote{10, 5, a}, nil},
+		{Commit, Vote{10, 5, b}, ErrConflictingVote},
+		{Prepare, Vote{10, 5, b}, nil},
+		{Commit, Vote{10, 6, b}, nil},
+		{Commit, Vote{10, 5, a}, ErrStaleVote},
+		{Commit, Vote{9, 7, a}, ErrStaleVote},
+		{Commit, Vote{11, 6, a}, nil},
+	}
+	keys := []string{"k1", "k2"}
+	for i, test := range tests {
+		if err := g.Vote(test.phase, keys, test.vote); errors.Cause(err) != test.err {
+			t.Errorf("test %d: got error %v, expect %v", i, err, test.err)
+		}
+	}
+
+	reloaded, err := New(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if vote, _ := reloaded.LastVote(Commit, key); vote != (Vote{11, 6, a}) {
+			t.Errorf("got reloaded commit vote %+v of key %s", vote, key)
+		}
+		if vote, _ := reloaded.LastVote(Prepare, key); vote != (Vote{10, 5, b}) {
+			t.Errorf("got reloaded prepare vote %+v of key %s", vote, key)
+		}
+	}
+	if _, ok := reloaded.LastVote(Commit, "k3"); ok {
+		t.Error("got a vote of a key which did not vote")
+	}
+	if err := reloaded.Vote(Commit, []string{"k3"}, Vote{1, 1, a}); err != nil {
+		t.Errorf("got error %v voting with another key", err)
+	}
+}
+
+func TestLegacyGuard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guard.json")
+	legacy := []byte(`{
+  "commit": {
+    "block-num": 10,
+    "view-id": 5,
+    "block-hash": "0x000000000000000000000000000000000000000000000000000000000000000a"
+  }
+}`)
+	if err := ioutil.WriteFile(path, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Vote(Commit, []string{"k1"}, Vote{9, 9, common.HexToHash("0xb")}); errors.Cause(err) != ErrStaleVote {
+		t.Errorf("got error %v voting before a vote of the earlier guard", err)
+	}
+	if err := g.Vote(Commit, []string{"k1"}, Vote{11, 1, common.HexToHash("0xb")}); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := New(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vote, _ := reloaded.LastVote(Commit, "k2"); vote.BlockNum != 10 {
+		t.Errorf("got vote %+v of the earlier guard", vote)
+	}
+	if vote, _ := reloaded.LastVote(Commit, "k1"); vote.BlockNum != 11 {
+		t.Errorf("got vote %+v", vote)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	from, err := New(filepath.Join(dir, "from.json"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.HexToHash("0xa")
+	if err := from.Vote(Prepare, []string{"k1", "k2"}, Vote{10, 5, a}); err != nil {
+		t.Fatal(err)
+	}
+	if err := from.Vote(Commit, []string{"k1"}, Vote{10, 5, a}); err != nil {
+		t.Fatal(err)
+	}
+	ic := from.Export([]string{"k1"})
+	if len(ic.Records) != 2 {
+		t.Fatalf("got %d exported votes, expect 2", len(ic.Records))
+	}
+
+	to, err := New(filepath.Join(dir, "to.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := to.Import(ic); err != nil || n != 2 {
+		t.Fatalf("imported %d votes, error %v", n, err)
+	}
+	if n, err := to.Import(ic); err != nil || n != 0 {
+		t.Errorf("imported %d votes again, error %v", n, err)
+	}
+	to.ActivateUntil(time.Now().Add(time.Minute))
+	if err := to.Vote(Commit, []string{"k1"}, Vote{10, 5, common.HexToHash("0xb")}); errors.Cause(err) != ErrConflictingVote {
+		t.Errorf("got error %v voting against an imported vote", err)
+	}
+	if err := to.Vote(Prepare, []string{"k2"}, Vote{10, 5, common.HexToHash("0xb")}); err != nil {
+		t.Errorf("got error %v voting with a key which was not imported", err)
+	}
+	if _, err := to.Import(&Interchange{Version: 2}); errors.Cause(err) != ErrInterchangeVersion {
+		t.Errorf("got error %v importing an unknown version", err)
+	}
+}
+
+func TestLeasedGuard(t *testing.T) {
+	g, err := New(filepath.Join(t.TempDir(), "guard.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	vote := Vote{1, 1, common.HexToHash("0x1")}
+	if g.Active() || g.Vote(Commit, []string{"k"}, vote) != ErrStandby {
+		t.Fatal("inactive guard signed")
+	}
+	g.ActivateUntil(now.Add(time.Second))
+	if !g.Active() || g.Vote(Commit, []string{"k"}, vote) != nil {
+		t.Fatal("active guard did not sign")
+	}
+	now = now.Add(time.Second)
+	if g.Active() {
+		t.Error("guard signs after the expiry of the lease")
+	}
+	g.ActivateUntil(now.Add(time.Second))
+	g.Deactivate()
+	if g.Active() {
+		t.Error("deactivated guard signs")
+	}
+}
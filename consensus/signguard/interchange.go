@@ -0,0 +1,88 @@
+package signguard
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// InterchangeVersion is the version of the interchange format of the votes
+const InterchangeVersion = 1
+
+// ErrInterchangeVersion is the error of an interchange of an unknown version
+var ErrInterchangeVersion = errors.New("unknown slashing protection interchange version")
+
+// Interchange is the last votes of keys exported from the slashing protection
+// database of a node, to be imported into that of the node the keys are moved
+// to, which then refuses the votes conflicting with those signed by the keys
+// before they were moved.
+type Interchange struct {
+	Version int `json:"version"`
+	// Exported is the unix time the votes were exported at
+	Exported int64    `json:"exported"`
+	Records  []Record `json:"records"`
+}
+
+// Keys returns the keys with votes in the database, AnyKey being one of them
+// if the votes of the earlier guards were loaded
+func (g *Guard) Keys() []string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	keys := make([]string, 0, len(g.votes))
+	for key := range g.votes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Export returns the interchange of the last votes of the keys, or of all
+// the keys of the database if there are none. The votes recorded for all the
+// keys are exported as votes of each of the keys, or as they are when all
+// the keys are exported.
+func (g *Guard) Export(keys []string) *Interchange {
+	if len(keys) == 0 {
+		keys = g.Keys()
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	ic := &Interchange{Version: InterchangeVersion, Exported: g.now().Unix(), Records: []Record{}}
+	for _, key := range keys {
+		for _, phase := range Phases {
+			if vote, ok := g.lastVote(phase, key); ok {
+				ic.Records = append(ic.Records, Record{Key: key, Phase: phase, Vote: vote})
+			}
+		}
+	}
+	return ic
+}
+
+// Import records the votes of the interchange in the database, and returns
+// the number of votes later than those recorded, the other ones being
+// ignored. The leased guards import the votes while in standby.
+func (g *Guard) Import(ic *Interchange) (int, error) {
+	if ic.Version != InterchangeVersion {
+		return 0, errors.Wrapf(ErrInterchangeVersion, "version %d", ic.Version)
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	records := []Record{}
+	for _, r := range ic.Records {
+		if r.Phase != Prepare && r.Phase != Commit {
+			return 0, errors.Errorf("unknown phase %q of the votes of key %s", r.Phase, r.Key)
+		}
+		if last, ok := g.votes[r.Key][r.Phase]; !ok || last.before(r.Vote) {
+			records = append(records, r)
+		}
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err := g.append(records); err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		addVote(g.votes, r)
+	}
+	return len(records), nil
+}
@@ -0,0 +1,103 @@
+package signguard
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrLeaseHeld is returned when the signing lease is held by another node
+var ErrLeaseHeld = errors.New("signing lease is held by another node")
+
+// Lease is the right to sign with the validator keys, held by one of the
+// nodes sharing the lease file until it expires unless renewed
+type Lease struct {
+	path     string
+	owner    string
+	duration time.Duration
+}
+
+// leaseRecord is the content of the lease file
+type leaseRecord struct {
+	Owner  string    `json:"owner"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// NewLease returns the lease of the file at path, acquired by owner for the
+// given duration
+func NewLease(path, owner string, duration time.Duration) *Lease {
+	return &Lease{path: path, owner: owner, duration: duration}
+}
+
+// Duration returns the duration of the lease
+func (l *Lease) Duration() time.Duration {
+	return l.duration
+}
+
+// Acquire acquires or renews the lease, and returns its expiry. It returns
+// ErrLeaseHeld if another node holds the lease. Two nodes acquiring an
+// expired lease concurrently may both succeed, the lease is only held by the
+// node renewing it successfully.
+func (l *Lease) Acquire(now time.Time) (time.Time, error) {
+	record, err := l.read()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if record != nil && record.Owner != l.owner && now.Before(record.Expiry) {
+		return time.Time{}, ErrLeaseHeld
+	}
+	expiry := now.Add(l.duration)
+	if err := l.write(&leaseRecord{Owner: l.owner, Expiry: expiry}); err != nil {
+		return time.Time{}, err
+	}
+	if record, err = l.read(); err != nil {
+		return time.Time{}, err
+	}
+	if record == nil || record.Owner != l.owner {
+		return time.Time{}, ErrLeaseHeld
+	}
+	return expiry, nil
+}
+
+// Release gives the lease up if it is held, so that another node can
+// acquire it without waiting for its expiry
+func (l *Lease) Release() error {
+	record, err := l.read()
+	if err != nil || record == nil || record.Owner != l.owner {
+		return err
+	}
+	return l.write(&leaseRecord{Owner: l.owner})
+}
+
+func (l *Lease) read() (*leaseRecord, error) {
+	data, err := ioutil.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read signing lease")
+	}
+	record := &leaseRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, errors.Wrapf(err, "invalid signing lease %s", l.path)
+	}
+	return record, nil
+}
+
+// write replaces the lease file, through a temporary file of the owner as
+// the nodes sharing the lease may write it concurrently
+func (l *Lease) write(record *leaseRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + "." + strings.Replace(l.owner, string(os.PathSeparator), "_", -1) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "cannot write signing lease")
+	}
+	return os.Rename(tmp, l.path)
+}
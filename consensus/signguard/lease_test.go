@@ -0,0 +1,46 @@
+package signguard
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaseFailover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	primary := NewLease(path, "primary", 10*time.Second)
+	standby := NewLease(path, "standby", 10*time.Second)
+	now := time.Now()
+
+	expiry, err := primary.Acquire(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expiry.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("got expiry %v", expiry)
+	}
+	if _, err := standby.Acquire(now.Add(5 * time.Second)); err != ErrLeaseHeld {
+		t.Errorf("standby acquired a held lease: %v", err)
+	}
+	// renewed by the primary
+	if _, err := primary.Acquire(now.Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := standby.Acquire(now.Add(12 * time.Second)); err != ErrLeaseHeld {
+		t.Errorf("standby acquired a renewed lease: %v", err)
+	}
+	// the primary died
+	if _, err := standby.Acquire(now.Add(15 * time.Second)); err != nil {
+		t.Fatalf("standby cannot acquire an expired lease: %v", err)
+	}
+	if _, err := primary.Acquire(now.Add(16 * time.Second)); err != ErrLeaseHeld {
+		t.Errorf("primary acquired the lease of the standby: %v", err)
+	}
+
+	if err := standby.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := primary.Acquire(now.Add(17 * time.Second)); err != nil {
+		t.Errorf("primary cannot acquire a released lease: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/consensus/signature"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	"github.com/harmony-one/harmony/core/types"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
@@ -50,6 +51,12 @@ func (consensus *Consensus) didReachPrepareQuorum() error {
 
 	// so by this point, everyone has committed to the blockhash of this block
 	// in prepare and so this is the actual block.
+	if err := consensus.guardVote(
+		signguard.Commit, consensus.PubKey.PublicKey,
+		blockObj.NumberU64(), blockObj.Header().ViewID().Uint64(), blockObj.Hash(),
+	); err != nil {
+		return err
+	}
 	for i, key := range consensus.PubKey.PublicKey {
 		if err := consensus.commitBitmap.SetKey(key, true); err != nil {
 			consensus.getLogger().Warn().Msgf("[OnPrepare] Leader commit bitmap set failed for key at index %d", i)
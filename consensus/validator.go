@@ -36,6 +36,12 @@ func (consensus *Consensus) onAnnounce(msg *msg_pb.Message) {
 	consensus.FBFTLog.AddMessage(recvMsg)
 	consensus.mutex.Lock()
 	defer consensus.mutex.Unlock()
+	if consensus.isLeaving() {
+		consensus.getLogger().Info().
+			Uint64("MsgBlockNum", recvMsg.BlockNum).
+			Msg("[OnAnnounce] Leaving consensus, not voting for the new block")
+		return
+	}
 	consensus.blockHash = recvMsg.BlockHash
 	// we have already added message and block, skip check viewID
 	// and send prepare message if is in ViewChanging mode
@@ -190,7 +196,7 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 
 	// TODO: genesis account node delay for 1 second,
 	// this is a temp fix for allows FN nodes to earning reward
-	if consensus.delayCommit > 0 {
+	if consensus.delayCommit > 0 && !consensus.isLeaving() {
 		time.Sleep(consensus.delayCommit)
 	}
 
@@ -215,11 +221,17 @@ func (consensus *Consensus) onPrepared(msg *msg_pb.Message) {
 			continue
 		}
 
-		networkMessage, _ := consensus.construct(
+		networkMessage, err := consensus.construct(
 			msg_pb.MessageType_COMMIT,
 			commitPayload,
 			key, consensus.priKey.PrivateKey[i],
 		)
+		if err != nil {
+			consensus.getLogger().Err(err).
+				Str("message-type", msg_pb.MessageType_COMMIT.String()).
+				Msg("could not construct message")
+			continue
+		}
 
 		if consensus.current.Mode() != Listening {
 			if err := consensus.msgSender.SendWithoutRetry(
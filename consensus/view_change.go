@@ -15,6 +15,7 @@ import (
 	msg_pb "github.com/harmony-one/harmony/api/proto/message"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/consensus/signature"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
@@ -125,7 +126,11 @@ func (consensus *Consensus) startViewChange(viewID uint64) {
 	consensus.consensusTimeout[timeoutBootstrap].Stop()
 	consensus.current.SetMode(ViewChanging)
 	consensus.current.SetViewID(viewID)
+	oldLeader := consensus.LeaderPubKey
 	consensus.LeaderPubKey = consensus.GetNextLeaderKey()
+	if consensus.OnViewChange != nil {
+		consensus.OnViewChange(viewID, oldLeader, consensus.LeaderPubKey)
+	}
 
 	diff := int64(viewID - consensus.viewID)
 	duration := time.Duration(diff * diff * int64(viewChangeDuration))
@@ -136,7 +141,7 @@ func (consensus *Consensus) startViewChange(viewID uint64) {
 		Msg("[startViewChange]")
 
 	for i, key := range consensus.PubKey.PublicKey {
-		if !consensus.IsValidatorInCommittee(key) {
+		if !consensus.IsValidatorInCommittee(key) || !consensus.signingActive() {
 			continue
 		}
 		msgToSend := consensus.constructViewChangeMessage(key, consensus.priKey.PrivateKey[i])
@@ -422,6 +427,13 @@ func (consensus *Consensus) onViewChange(msg *msg_pb.Message) {
 			}
 			commitPayload := signature.ConstructCommitPayload(consensus.ChainReader,
 				block.Epoch(), block.Hash(), block.NumberU64(), block.Header().ViewID().Uint64())
+			if err := consensus.guardVote(
+				signguard.Commit, consensus.PubKey.PublicKey,
+				block.NumberU64(), block.Header().ViewID().Uint64(), block.Hash(),
+			); err != nil {
+				consensus.getLogger().Warn().Err(err).Msg("[onViewChange] New leader commit vote refused")
+				return
+			}
 			for i, key := range consensus.PubKey.PublicKey {
 				if err := consensus.commitBitmap.SetKey(key, true); err != nil {
 					consensus.getLogger().Warn().
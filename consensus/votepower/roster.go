@@ -3,8 +3,11 @@ package votepower
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"runtime"
 	"sort"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/bls/ffi/go/bls"
@@ -12,7 +15,9 @@ import (
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
@@ -153,11 +158,108 @@ func AggregateRosters(
 	return result
 }
 
-// Compute creates a new roster based off the shard.SlotList
+var (
+	rosterCache, _ = lru.New(rosterCacheSize)
+	rosterGroup    singleflight.Group
+)
+
+const (
+	// rosterCacheSize is the number of rosters cached, those of the shards
+	// of a few epochs
+	rosterCacheSize = 64
+	// parallelSlots is the number of slots from which their votes are
+	// computed in parallel
+	parallelSlots = 64
+)
+
+// rosterKey is the key of a cached roster, the hash of its committee and the
+// voting power shares of the epoch it is computed with
+type rosterKey struct {
+	committee       common.Hash
+	harmonyPercent  string
+	externalPercent string
+}
+
+// Precompute computes in the background the rosters of the committees of
+// the shard state of the epoch into the cache of Compute, for the first
+// blocks of the epoch not to wait for them. The returned channel is closed
+// once they are all computed.
+func Precompute(state *shard.State, epoch *big.Int) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range state.Shards {
+			if _, err := Compute(&state.Shards[i], epoch); err != nil {
+				utils.Logger().Warn().Err(err).
+					Uint32("shardID", state.Shards[i].ShardID).
+					Uint64("epoch", epoch.Uint64()).
+					Msg("[Precompute] cannot compute the voting power of the committee")
+			}
+		}
+	}()
+	return done
+}
+
+// Compute creates a new roster based off the shard.SlotList. The rosters are
+// cached by the hash of their committee, so the roster of a committee is
+// computed once whichever epoch or caller it is computed for; each caller gets
+// its own copy of the cached roster, free to modify it.
 func Compute(subComm *shard.Committee, epoch *big.Int) (*Roster, error) {
 	if epoch == nil {
 		return nil, errors.New("nil epoch for roster compute")
 	}
+	instance := shard.Schedule.InstanceForEpoch(epoch)
+	harmonyPercent := instance.HarmonyVotePercent()
+	externalPercent := instance.ExternalVotePercent()
+	key := rosterKey{
+		committee:       subComm.Hash(),
+		harmonyPercent:  harmonyPercent.String(),
+		externalPercent: externalPercent.String(),
+	}
+	if roster, ok := rosterCache.Get(key); ok {
+		return roster.(*Roster).deepCopy(), nil
+	}
+	roster, err, _ := rosterGroup.Do(fmt.Sprintf("%+v", key), func() (interface{}, error) {
+		roster, err := compute(subComm, harmonyPercent, externalPercent)
+		if err != nil {
+			return nil, err
+		}
+		rosterCache.Add(key, roster)
+		return roster, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roster.(*Roster).deepCopy(), nil
+}
+
+// deepCopy returns a copy of the roster sharing nothing with it, the voters
+// included, as the cached rosters are read by many callers at once
+func (r *Roster) deepCopy() *Roster {
+	c := &Roster{
+		Voters: make(map[shard.BLSPublicKey]*AccommodateHarmonyVote, len(r.Voters)),
+		topLevelRegistry: topLevelRegistry{
+			OurVotingPowerTotalPercentage:   r.OurVotingPowerTotalPercentage.Copy(),
+			TheirVotingPowerTotalPercentage: r.TheirVotingPowerTotalPercentage.Copy(),
+			TotalEffectiveStake:             r.TotalEffectiveStake.Copy(),
+			HMYSlotCount:                    r.HMYSlotCount,
+		},
+		ShardID: r.ShardID,
+	}
+	for key, voter := range r.Voters {
+		v := *voter
+		v.GroupPercent = voter.GroupPercent.Copy()
+		v.EffectiveStake = voter.EffectiveStake.Copy()
+		v.RawStake = voter.RawStake.Copy()
+		v.OverallPercent = voter.OverallPercent.Copy()
+		c.Voters[key] = &v
+	}
+	return c
+}
+
+func compute(
+	subComm *shard.Committee, harmonyPercent, externalPercent numeric.Dec,
+) (*Roster, error) {
 	roster, staked := NewRoster(subComm.ShardID), subComm.Slots
 
 	for i := range staked {
@@ -174,9 +276,11 @@ func Compute(subComm *shard.Committee, epoch *big.Int) (*Roster, error) {
 	theirPercentage := numeric.ZeroDec()
 	var lastStakedVoter *AccommodateHarmonyVote
 
-	harmonyPercent := shard.Schedule.InstanceForEpoch(epoch).HarmonyVotePercent()
-	externalPercent := shard.Schedule.InstanceForEpoch(epoch).ExternalVotePercent()
-	for i := range staked {
+	// The votes of the slots are independent of each other, only their sums
+	// are not, so they are computed in parallel across the slots of large
+	// committees
+	members := make([]AccommodateHarmonyVote, len(staked))
+	vote := func(i int) {
 		member := AccommodateHarmonyVote{
 			PureStakedVote: PureStakedVote{
 				EarningAccount: staked[i].EcdsaAddress,
@@ -194,17 +298,47 @@ func Compute(subComm *shard.Committee, epoch *big.Int) (*Roster, error) {
 			member.EffectiveStake = member.EffectiveStake.Add(*e)
 			member.GroupPercent = e.Quo(roster.TotalEffectiveStake)
 			member.OverallPercent = member.GroupPercent.Mul(externalPercent)
-			theirPercentage = theirPercentage.Add(member.OverallPercent)
-			lastStakedVoter = &member
 		} else { // Our node
 			member.IsHarmonyNode = true
 			member.OverallPercent = harmonyPercent.Quo(asDecHMYSlotCount)
 			member.GroupPercent = member.OverallPercent.Quo(harmonyPercent)
+		}
+		members[i] = member
+	}
+	if workers := runtime.NumCPU(); len(staked) < parallelSlots || workers == 1 {
+		for i := range staked {
+			vote(i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		chunk := (len(staked) + workers - 1) / workers
+		for start := 0; start < len(staked); start += chunk {
+			end := start + chunk
+			if end > len(staked) {
+				end = len(staked)
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				for i := start; i < end; i++ {
+					vote(i)
+				}
+			}(start, end)
+		}
+		wg.Wait()
+	}
+
+	for i := range members {
+		member := &members[i]
+		if member.IsHarmonyNode {
 			ourPercentage = ourPercentage.Add(member.OverallPercent)
+		} else {
+			theirPercentage = theirPercentage.Add(member.OverallPercent)
+			lastStakedVoter = member
 		}
 
 		if _, ok := roster.Voters[staked[i].BLSPublicKey]; !ok {
-			roster.Voters[staked[i].BLSPublicKey] = &member
+			roster.Voters[staked[i].BLSPublicKey] = member
 		} else {
 			utils.Logger().Debug().Str("blsKey", staked[i].BLSPublicKey.Hex()).Msg("Duplicate BLS key found")
 		}
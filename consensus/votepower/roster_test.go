@@ -98,7 +98,7 @@ func TestCompute(t *testing.T) {
 	expectedRoster.TheirVotingPowerTotalPercentage = theirPercentage
 
 	computedRoster, err := Compute(&shard.Committee{
-		shard.BeaconChainShardID, slotList,
+		ShardID: shard.BeaconChainShardID, Slots: slotList,
 	}, big.NewInt(3))
 	if err != nil {
 		t.Error("Computed Roster failed on vote summation to one")
@@ -144,3 +144,78 @@ func compareStakedVoter(a, b *AccommodateHarmonyVote) bool {
 		a.OverallPercent.Equal(b.OverallPercent) &&
 		a.EffectiveStake.Equal(b.EffectiveStake)
 }
+
+func TestComputeCache(t *testing.T) {
+	committee := &shard.Committee{ShardID: 1, Slots: slotList}
+	roster, err := Compute(committee, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachedCount := rosterCache.Len()
+	// an equivalent committee, of another epoch of the same voting power shares
+	equivalent := committee.DeepCopy()
+	cached, err := Compute(&equivalent, big.NewInt(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rosterCache.Len() != cachedCount || cached.String() != roster.String() {
+		t.Error("roster of an equivalent committee computed again")
+	}
+	// the callers get their own copy of the cached roster
+	for key := range cached.Voters {
+		cached.Voters[key].RawStake = numeric.NewDec(42)
+		if raw := roster.Voters[key].RawStake; !raw.IsNil() && raw.Equal(numeric.NewDec(42)) {
+			t.Error("roster shared by the callers of Compute")
+		}
+		break
+	}
+	equivalent.Slots = equivalent.Slots[1:]
+	other, err := Compute(&equivalent, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rosterCache.Len() != cachedCount+1 || other.HMYSlotCount != roster.HMYSlotCount-1 {
+		t.Error("roster of another committee not computed")
+	}
+}
+
+func TestPrecompute(t *testing.T) {
+	state := &shard.State{Epoch: big.NewInt(5)}
+	for i, slots := range []shard.SlotList{slotList[1:], slotList[2:]} {
+		state.Shards = append(state.Shards, shard.Committee{
+			ShardID: uint32(i), Slots: slots,
+		})
+	}
+	<-Precompute(state, state.Epoch)
+	instance := shard.Schedule.InstanceForEpoch(state.Epoch)
+	for i := range state.Shards {
+		key := rosterKey{
+			committee:       state.Shards[i].Hash(),
+			harmonyPercent:  instance.HarmonyVotePercent().String(),
+			externalPercent: instance.ExternalVotePercent().String(),
+		}
+		if !rosterCache.Contains(key) {
+			t.Errorf("roster of shard %d not precomputed", i)
+		}
+	}
+}
+
+func BenchmarkCompute(b *testing.B) {
+	var slots shard.SlotList
+	for i := 0; i < 400; i++ {
+		slot := generateRandomSlot()
+		if i%4 == 0 {
+			slot.EffectiveStake = nil
+		}
+		slots = append(slots, slot)
+	}
+	committee := &shard.Committee{ShardID: 1, Slots: slots}
+	percent := numeric.NewDecWithPrec(5, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := compute(committee, percent, percent); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
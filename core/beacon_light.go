@@ -0,0 +1,103 @@
+package core
+
+import (
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// EpochTransition is the last beacon chain block header of an epoch, which
+// carries the committees of the next epoch, with the commit signature and
+// bitmap of the committee of its epoch which finalized it.
+type EpochTransition struct {
+	Header       *block.Header
+	CommitSig    []byte
+	CommitBitmap []byte
+}
+
+// ReadEpochTransition returns the epoch transition of the given epoch from
+// the beacon chain, nil if the chain did not reach the end of the epoch.
+func (bc *BlockChain) ReadEpochTransition(epoch uint64) (*EpochTransition, error) {
+	if bc.ShardID() != shard.BeaconChainShardID {
+		return nil, errors.Errorf("shard %d is not the beacon chain", bc.ShardID())
+	}
+	number := shard.Schedule.EpochLastBlock(epoch)
+	header := bc.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, nil
+	}
+	if header.Epoch().Uint64() != epoch || len(header.ShardState()) == 0 {
+		return nil, errors.Errorf("block %d is not the last block of epoch %d", number, epoch)
+	}
+	// the commit signature of a block is carried by the next block
+	if next := bc.GetHeaderByNumber(number + 1); next != nil {
+		sig := next.LastCommitSignature()
+		return &EpochTransition{header, sig[:], next.LastCommitBitmap()}, nil
+	}
+	lastCommits, err := bc.ReadCommitSig(number)
+	if err != nil || len(lastCommits) < shard.BLSSignatureSizeInBytes {
+		return nil, errors.Errorf("commit signature not found for block %d", number)
+	}
+	return &EpochTransition{
+		header,
+		lastCommits[:shard.BLSSignatureSizeInBytes],
+		lastCommits[shard.BLSSignatureSizeInBytes:],
+	}, nil
+}
+
+// BeaconLightEpoch returns the last epoch whose committees are known to the
+// beacon light client, the epoch of the current header if it never ran.
+func (bc *BlockChain) BeaconLightEpoch() uint64 {
+	if epoch, ok := rawdb.ReadBeaconLightEpoch(bc.db); ok {
+		return epoch
+	}
+	return bc.CurrentHeader().Epoch().Uint64()
+}
+
+// InsertEpochTransitions verifies the consecutive epoch transitions of the
+// beacon chain starting at the epoch of BeaconLightEpoch, and writes the
+// committees they carry. This lets shard nodes follow the committees of the
+// beacon chain, which is what their epoch transitions and the cross-shard
+// verification need, without its blocks or even all of its headers. The
+// commit signature of each transition is verified against the committees
+// of its epoch, which the previous transition carried. It returns the
+// index of the transition which failed.
+func (bc *BlockChain) InsertEpochTransitions(transitions []*EpochTransition) (int, error) {
+	if bc.ShardID() != shard.BeaconChainShardID {
+		return 0, errors.Errorf("shard %d is not the beacon chain", bc.ShardID())
+	}
+	for i, transition := range transitions {
+		epoch, header := bc.BeaconLightEpoch(), transition.Header
+		if header == nil || header.ShardID() != shard.BeaconChainShardID ||
+			header.Epoch().Uint64() != epoch ||
+			header.Number().Uint64() != shard.Schedule.EpochLastBlock(epoch) ||
+			len(header.ShardState()) == 0 {
+			return i, errors.Errorf("not the epoch transition of epoch %d", epoch)
+		}
+		if err := bc.Engine().VerifyHeaderWithSignature(
+			bc, header, transition.CommitSig, transition.CommitBitmap, false,
+		); err != nil {
+			return i, errors.Wrapf(err, "cannot verify the epoch transition of epoch %d", epoch)
+		}
+		next, err := bc.getNextBlockEpoch(header)
+		if err != nil {
+			return i, err
+		}
+		if next.Uint64() != epoch+1 {
+			return i, errors.Errorf("epoch transition of epoch %d to epoch %v", epoch, next)
+		}
+		if _, err := bc.WriteShardStateBytes(bc.db, next, header.ShardState()); err != nil {
+			return i, err
+		}
+		if err := rawdb.WriteBeaconLightEpoch(bc.db, next.Uint64()); err != nil {
+			return i, err
+		}
+		utils.Logger().Info().
+			Uint64("epoch", next.Uint64()).
+			Uint64("block", header.Number().Uint64()).
+			Msg("[BeaconLight] verified the committees of the next epoch")
+	}
+	return 0, nil
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestInsertEpochTransitionsUnverified(t *testing.T) {
+	bc := createBlockChain()
+	if epoch := bc.BeaconLightEpoch(); epoch != 0 {
+		t.Fatalf("got light epoch %d, expect the genesis epoch", epoch)
+	}
+	if transition, err := bc.ReadEpochTransition(0); err != nil || transition != nil {
+		t.Errorf("got transition %v and error %v, expect the epoch not over", transition, err)
+	}
+	last := shard.Schedule.EpochLastBlock(0)
+	tests := []*EpochTransition{
+		// not the last block of the epoch
+		{Header: blockfactory.ForTest.NewHeader(big.NewInt(0)).With().
+			Number(new(big.Int).SetUint64(last - 1)).ShardState([]byte{0xc0}).Header()},
+		// not the next epoch
+		{Header: blockfactory.ForTest.NewHeader(big.NewInt(1)).With().
+			Number(new(big.Int).SetUint64(shard.Schedule.EpochLastBlock(1))).ShardState([]byte{0xc0}).Header()},
+		// without commit signature
+		{Header: blockfactory.ForTest.NewHeader(big.NewInt(0)).With().
+			Number(new(big.Int).SetUint64(last)).ShardState([]byte{0xc0}).Header()},
+	}
+	for i, transition := range tests {
+		if _, err := bc.InsertEpochTransitions([]*EpochTransition{transition}); err == nil {
+			t.Errorf("test %d: expect the epoch transition rejected", i)
+		}
+	}
+	if epoch := bc.BeaconLightEpoch(); epoch != 0 {
+		t.Errorf("got light epoch %d after rejected transitions", epoch)
+	}
+}
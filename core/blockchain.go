@@ -51,6 +51,8 @@ import (
 	"github.com/harmony-one/harmony/shard/committee"
 	"github.com/harmony-one/harmony/staking/apr"
 	"github.com/harmony-one/harmony/staking/effective"
+	"github.com/harmony-one/harmony/staking/governance"
+	"github.com/harmony-one/harmony/staking/network"
 	"github.com/harmony-one/harmony/staking/slash"
 	staking "github.com/harmony-one/harmony/staking/types"
 	lru "github.com/hashicorp/golang-lru"
@@ -75,7 +77,8 @@ const (
 	maxTimeFutureBlocks                = 30
 	badBlockLimit                      = 10
 	triesInMemory                      = 128
-	shardCacheLimit                    = 10
+	shardCacheLimit                    = 32
+	shardStateSnapshotInterval         = 16
 	commitsCacheLimit                  = 10
 	epochCacheLimit                    = 10
 	randomnessCacheLimit               = 10
@@ -94,9 +97,11 @@ const (
 // CacheConfig contains the configuration values for the trie caching/pruning
 // that's resident in a blockchain.
 type CacheConfig struct {
-	Disabled      bool          // Whether to disable trie write caching (archive node)
-	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
-	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	Disabled            bool          // Whether to disable trie write caching (archive node)
+	TrieCleanLimit      int           // Memory allowance (MB) to use for caching trie nodes in memory
+	TrieCleanNoPrefetch bool          // Whether to disable heuristic state prefetching for followup blocks
+	TrieNodeLimit       int           // Memory limit (MB) at which to flush the current in-memory trie to disk
+	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -155,6 +160,11 @@ type BlockChain struct {
 	validatorListByDelegatorCache *lru.Cache    // Cache of validator list by delegator
 	pendingCrossLinksCache        *lru.Cache    // Cache of last pending crosslinks
 	blockAccumulatorCache         *lru.Cache    // Cache of block accumulators
+	crossLinkArchive              atomic.Value  // ethdb.Database of the archived crosslinks
+	electionAudit                 int32         // whether the elections are recorded, must be called atomically
+	repairDepth                   uint64        // blocks checked by the repairs of the database, 0 if disabled
+	forks                         *forkTracker  // competing segments, reorgs and retained fork blocks
+	repairCh                      chan struct{} // requests of a repair of the database
 	quit                          chan struct{} // blockchain quit channel
 	running                       int32         // running must be called atomically
 	// procInterrupt must be atomically called
@@ -162,8 +172,9 @@ type BlockChain struct {
 	wg            sync.WaitGroup // chain processing wait group for shutting down
 
 	engine         consensus_engine.Engine
-	processor      Processor // block processor interface
-	validator      Validator // block and state validator interface
+	prefetcher     Prefetcher // block state prefetcher interface
+	processor      Processor  // block processor interface
+	validator      Validator  // block and state validator interface
 	vmConfig       vm.Config
 	badBlocks      *lru.Cache              // Bad block cache
 	shouldPreserve func(*types.Block) bool // Function used to determine whether should preserve the given block.
@@ -180,8 +191,9 @@ func NewBlockChain(
 ) (*BlockChain, error) {
 	if cacheConfig == nil {
 		cacheConfig = &CacheConfig{
-			TrieNodeLimit: 256 * 1024 * 1024,
-			TrieTimeLimit: 2 * time.Minute,
+			TrieCleanLimit: 256,
+			TrieNodeLimit:  256 * 1024 * 1024,
+			TrieTimeLimit:  2 * time.Minute,
 		}
 	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
@@ -206,8 +218,9 @@ func NewBlockChain(
 		cacheConfig:                   cacheConfig,
 		db:                            db,
 		triegc:                        prque.New(nil),
-		stateCache:                    state.NewDatabase(db),
+		stateCache:                    state.NewDatabaseWithCache(db, cacheConfig.TrieCleanLimit),
 		quit:                          make(chan struct{}),
+		repairCh:                      make(chan struct{}, 1),
 		shouldPreserve:                shouldPreserve,
 		bodyCache:                     bodyCache,
 		bodyRLPCache:                  bodyRLPCache,
@@ -231,6 +244,7 @@ func NewBlockChain(
 	}
 	bc.SetValidator(NewBlockValidator(chainConfig, bc, engine))
 	bc.SetProcessor(NewStateProcessor(chainConfig, bc, engine))
+	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 
 	var err error
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.getProcInterrupt)
@@ -241,6 +255,7 @@ func NewBlockChain(
 	if bc.genesisBlock == nil {
 		return nil, ErrNoGenesis
 	}
+	bc.forks = newForkTracker(db, bc.genesisBlock.ShardID())
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
 	bc.currentFastBlock.Store(nilBlock)
@@ -392,6 +407,17 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
+	// Record the rewound blocks before their bodies are deleted
+	if currentBlock := bc.CurrentBlock(); currentBlock != nil {
+		rewound := []*types.Block{}
+		for n := currentBlock.NumberU64(); n > head && len(rewound) < maxDisplacedBlocks; n-- {
+			if b := bc.GetBlockByNumber(n); b != nil {
+				rewound = append(rewound, b)
+			}
+		}
+		bc.observeRewound(rewound)
+	}
+
 	// Rewind the header chain, deleting all block bodies until then
 	delFn := func(db rawdb.DatabaseDeleter, hash common.Hash, num uint64) {
 		rawdb.DeleteBody(db, hash, num)
@@ -498,6 +524,11 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.DB, error) {
 	return state.New(root, bc.stateCache)
 }
 
+// StateCache returns the caching database underpinning the blockchain instance.
+func (bc *BlockChain) StateCache() state.Database {
+	return bc.stateCache
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -893,6 +924,7 @@ func (bc *BlockChain) Rollback(chain []common.Hash) {
 	defer bc.mu.Unlock()
 
 	valsToRemove := map[common.Address]struct{}{}
+	rewound := []*types.Block{}
 	for i := len(chain) - 1; i >= 0; i-- {
 		hash := chain[i]
 
@@ -915,6 +947,7 @@ func (bc *BlockChain) Rollback(chain []common.Hash) {
 			if newBlock != nil {
 				bc.currentBlock.Store(newBlock)
 				rawdb.WriteHeadBlockHash(bc.db, newBlock.Hash())
+				rewound = append(rewound, currentBlock)
 
 				for _, stkTxn := range currentBlock.StakingTransactions() {
 					if stkTxn.StakingType() == staking.DirectiveCreateValidator {
@@ -926,6 +959,7 @@ func (bc *BlockChain) Rollback(chain []common.Hash) {
 			}
 		}
 	}
+	bc.observeRewound(rewound)
 	bc.removeInValidatorList(valsToRemove)
 }
 
@@ -938,46 +972,32 @@ func SetReceiptsData(config *params.ChainConfig, block *types.Block, receipts ty
 		return errors.New("transaction+stakingTransactions and receipt count mismatch")
 	}
 
-	// The used gas can be calculated based on previous receipts
-	if len(receipts) > 0 && len(transactions) > 0 {
-		receipts[0].GasUsed = receipts[0].CumulativeGasUsed
-	}
-	for j := 1; j < len(transactions); j++ {
+	// in a block, txns are processed before staking txns and share the gas counter
+	for j := range receipts {
 		// The transaction hash can be retrieved from the transaction itself
-		receipts[j].TxHash = transactions[j].Hash()
-		receipts[j].GasUsed = receipts[j].CumulativeGasUsed - receipts[j-1].CumulativeGasUsed
-		// The contract address can be derived from the transaction itself
-		if transactions[j].To() == nil {
-			// Deriving the signer is expensive, only do if it's actually needed
-			from, _ := types.Sender(signer, transactions[j])
-			receipts[j].ContractAddress = crypto.CreateAddress(from, transactions[j].Nonce())
+		if j < len(transactions) {
+			receipts[j].TxHash = transactions[j].Hash()
+			// The contract address can be derived from the transaction itself
+			if transactions[j].To() == nil {
+				// Deriving the signer is expensive, only do if it's actually needed
+				from, _ := types.Sender(signer, transactions[j])
+				receipts[j].ContractAddress = crypto.CreateAddress(from, transactions[j].Nonce())
+			}
+		} else {
+			receipts[j].TxHash = stakingTransactions[j-len(transactions)].Hash()
 		}
-		// The derived log fields can simply be set from the block and transaction
-		for k := 0; k < len(receipts[j].Logs); k++ {
-			receipts[j].Logs[k].BlockNumber = block.NumberU64()
-			receipts[j].Logs[k].BlockHash = block.Hash()
-			receipts[j].Logs[k].TxHash = receipts[j].TxHash
-			receipts[j].Logs[k].TxIndex = uint(j)
-			receipts[j].Logs[k].Index = logIndex
-			logIndex++
+		// The used gas can be calculated based on previous receipts
+		if j == 0 {
+			receipts[j].GasUsed = receipts[j].CumulativeGasUsed
+		} else {
+			receipts[j].GasUsed = receipts[j].CumulativeGasUsed - receipts[j-1].CumulativeGasUsed
 		}
-	}
-
-	// The used gas can be calculated based on previous receipts
-	if len(receipts) > len(transactions) && len(stakingTransactions) > 0 {
-		receipts[len(transactions)].GasUsed = receipts[len(transactions)].CumulativeGasUsed
-	}
-	// in a block, txns are processed before staking txns
-	for j := len(transactions) + 1; j < len(transactions)+len(stakingTransactions); j++ {
-		// The transaction hash can be retrieved from the staking transaction itself
-		receipts[j].TxHash = stakingTransactions[j].Hash()
-		receipts[j].GasUsed = receipts[j].CumulativeGasUsed - receipts[j-1].CumulativeGasUsed
 		// The derived log fields can simply be set from the block and transaction
 		for k := 0; k < len(receipts[j].Logs); k++ {
 			receipts[j].Logs[k].BlockNumber = block.NumberU64()
 			receipts[j].Logs[k].BlockHash = block.Hash()
 			receipts[j].Logs[k].TxHash = receipts[j].TxHash
-			receipts[j].Logs[k].TxIndex = uint(j) + uint(len(transactions))
+			receipts[j].Logs[k].TxIndex = uint(j)
 			receipts[j].Logs[k].Index = logIndex
 			logIndex++
 		}
@@ -1111,10 +1131,14 @@ func (bc *BlockChain) WriteBlockWithState(
 
 	currentBlock := bc.CurrentBlock()
 	if currentBlock == nil || block.ParentHash() != currentBlock.Hash() {
+		if currentBlock != nil {
+			bc.observeCompeting(block)
+		}
 		return NonStatTy, errors.New("Hash of parent block doesn't match the current block hash")
 	}
 
 	// Commit state object changes to in-memory trie
+	start := time.Now()
 	root, err := state.Commit(bc.chainConfig.IsS3(block.Epoch()))
 	if err != nil {
 		return NonStatTy, err
@@ -1174,6 +1198,8 @@ func (bc *BlockChain) WriteBlockWithState(
 		}
 	}
 
+	start = observeStage(stageCommit, start)
+
 	batch := bc.db.NewBatch()
 	// Write the raw block
 	rawdb.WriteBlock(batch, block)
@@ -1197,6 +1223,8 @@ func (bc *BlockChain) WriteBlockWithState(
 	if err := batch.Write(); err != nil {
 		return NonStatTy, err
 	}
+	observeStage(stageWrite, start)
+	bc.observeCanonical(block)
 
 	bc.futureBlocks.Remove(block.Hash())
 	return CanonStatTy, nil
@@ -1292,6 +1320,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifyHeaders bool) (int,
 		if err == nil {
 			err = bc.Validator().ValidateBody(block)
 		}
+		if err == nil {
+			observeStage(stageVerify, bstart)
+		}
 		switch {
 		case err == ErrKnownBlock:
 			// Block and state both already known. However if the current block is below
@@ -1369,27 +1400,52 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifyHeaders bool) (int,
 		}
 		state, err := state.New(parent.Root(), bc.stateCache)
 		if err != nil {
+			bc.reportCorruption(Corruption{parent.NumberU64(), CorruptState, err.Error()})
 			return i, events, coalescedLogs, err
 		}
 
+		// If we have followup blocks, prefetch their state on top of the
+		// parent state while processing the block
+		var followupInterrupt uint32
+		if !bc.cacheConfig.TrieCleanNoPrefetch && i+1 < len(chain) {
+			followups := chain[i+1:]
+			if len(followups) > prefetchBlocks {
+				followups = followups[:prefetchBlocks]
+			}
+			go bc.prefetcher.Prefetch(followups, state.Copy(), bc.vmConfig, &followupInterrupt)
+		}
+
 		// Process block using the parent state as reference point.
 		receipts, cxReceipts, logs, usedGas, payout, err := bc.processor.Process(
 			block, state, bc.vmConfig,
 		)
+		atomic.StoreUint32(&followupInterrupt, 1)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			return i, events, coalescedLogs, err
 		}
 
 		// Validate the state using the default validator
+		vstart := time.Now()
 		if err := bc.Validator().ValidateState(
 			block, state, receipts, cxReceipts, usedGas,
 		); err != nil {
 			bc.reportBlock(block, receipts, err)
 			return i, events, coalescedLogs, err
 		}
+		observeStage(stageValidation, vstart)
 		proctime := time.Since(bstart)
 
+		// The elections are audited with the state they ran with
+		var audit *committee.ElectionAudit
+		if bc.isElectionAuditBlock(block.Header()) {
+			if audit, err = bc.newElectionAudit(block.Header()); err != nil {
+				utils.Logger().Warn().Err(err).
+					Uint64("number", block.NumberU64()).
+					Msg("cannot audit election")
+			}
+		}
+
 		// Write the block to the chain and get the status.
 		status, err := bc.WriteBlockWithState(
 			block, receipts, cxReceipts, payout, state,
@@ -1412,9 +1468,21 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifyHeaders bool) (int,
 			logger.Info().Msg("Inserted new block")
 			coalescedLogs = append(coalescedLogs, logs...)
 			blockInsertTimer.UpdateSince(bstart)
+			blockInsertHistogram.Observe(time.Since(bstart).Seconds())
 			events = append(events, ChainEvent{block, block.Hash(), logs})
 			lastCanon = block
 
+			if bc.isCommitteeCheckpointBlock(block.Header()) {
+				if err := bc.writeCommitteeCheckpoint(block.Epoch().Uint64()); err != nil {
+					logger.Warn().Err(err).Msg("cannot write committee checkpoint")
+				}
+			}
+			if audit != nil {
+				if err := bc.writeElectionAudit(audit); err != nil {
+					logger.Warn().Err(err).Msg("cannot write election audit")
+				}
+			}
+
 			// Only count canonical blocks for GC processing time
 			bc.gcproc += proctime
 		}
@@ -1730,7 +1798,7 @@ func (bc *BlockChain) ReadShardState(epoch *big.Int) (*shard.State, error) {
 		shardState := cached.(*shard.State)
 		return shardState, nil
 	}
-	shardState, err := rawdb.ReadShardState(bc.db, epoch)
+	shardState, err := rawdb.ReadShardStateFrom(bc.db, epoch, bc.ReadShardState)
 	if err != nil {
 		if strings.Contains(err.Error(), rawdb.MsgNoShardStateFromDB) &&
 			shard.Schedule.IsSkippedEpoch(bc.ShardID(), epoch) {
@@ -1750,12 +1818,30 @@ func (bc *BlockChain) WriteShardStateBytes(db rawdb.DatabaseWriter,
 	if err != nil {
 		return nil, err
 	}
-	err = rawdb.WriteShardStateBytes(db, epoch, shardState)
+	// the states are stored as deltas against the previous epoch, with a full
+	// state every shardStateSnapshotInterval epochs to bound the reads
+	var delta *shard.StateDelta
+	if epoch.Uint64()%shardStateSnapshotInterval != 0 {
+		prev, err := bc.ReadShardState(new(big.Int).Sub(epoch, common.Big1))
+		if err == nil {
+			delta = shard.NewStateDelta(prev, decodeShardState)
+		}
+	}
+	if delta != nil {
+		err = rawdb.WriteShardStateDelta(db, epoch, delta)
+	} else {
+		err = rawdb.WriteShardStateBytes(db, epoch, shardState)
+	}
 	if err != nil {
 		return nil, err
 	}
 	cacheKey := string(epoch.Bytes())
 	bc.shardStateCache.Add(cacheKey, decodeShardState)
+	// the rewards of the first blocks of the epoch wait for the voting power
+	// of its committees otherwise
+	if bc.chainConfig.IsStaking(epoch) && epoch.Cmp(bc.CurrentHeader().Epoch()) >= 0 {
+		votepower.Precompute(decodeShardState.DeepCopy(), epoch)
+	}
 	return decodeShardState, nil
 }
 
@@ -1922,6 +2008,10 @@ func (bc *BlockChain) DeleteCrossLinks(cls []types.CrossLink) error {
 // ReadCrossLink retrieves crosslink given shardID and blockNum.
 func (bc *BlockChain) ReadCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error) {
 	bytes, err := rawdb.ReadCrossLinkShardBlock(bc.db, shardID, blockNum)
+	if archive := bc.CrossLinkArchive(); err != nil && archive != nil &&
+		blockNum <= rawdb.ReadArchivedCrossLinkBlock(bc.db, shardID) {
+		bytes, err = rawdb.ReadCrossLinkShardBlock(archive, shardID, blockNum)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -2270,6 +2360,7 @@ func (bc *BlockChain) WriteValidatorSnapshot(
 	// Update cache
 	key := snapshot.Validator.Address.Hex() + snapshot.Epoch.String()
 	bc.validatorSnapshotCache.Add(key, snapshot)
+	network.DelegatorShares.Invalidate(snapshot.Epoch, snapshot.Validator.Address)
 	return nil
 }
 
@@ -2712,6 +2803,7 @@ func (bc *BlockChain) prepareStakingMetaData(
 			newDelegations[delegate.DelegatorAddress] = delegations
 		case staking.DirectiveUndelegate:
 		case staking.DirectiveCollectRewards:
+		case staking.DirectiveBindSlotKey:
 		default:
 		}
 	}
@@ -2758,6 +2850,42 @@ func (bc *BlockChain) UpdateBlockRewardAccumulator(
 	return bc.WriteBlockRewardAccumulator(batch, new(big.Int).Add(current, diff), number)
 }
 
+// ReadBurnedFees returns the transaction fees burned by the chain up to the
+// block, since the block the node started tracking them at
+func (bc *BlockChain) ReadBurnedFees(number uint64) (*big.Int, error) {
+	return rawdb.ReadBurnedFeesAccumulator(bc.db, number)
+}
+
+// updateBurnedFees adds the transaction fees burned by the block to the
+// burned fees accumulator, the fees of the staking transactions being always
+// burned, and those of the other transactions from the staking epoch on
+func (bc *BlockChain) updateBurnedFees(
+	batch rawdb.DatabaseWriter, block *types.Block, receipts []*types.Receipt,
+) error {
+	burned, err := bc.ReadBurnedFees(block.NumberU64() - 1)
+	if err != nil || block.NumberU64() == 0 {
+		burned = big.NewInt(0)
+	}
+	burned = new(big.Int).Set(burned)
+	// the receipts of the staking transactions follow those of the others
+	txs, stakingTxs := block.Transactions(), block.StakingTransactions()
+	if len(receipts) == len(txs)+len(stakingTxs) {
+		if bc.chainConfig.IsStaking(block.Epoch()) {
+			for i, tx := range txs {
+				burned.Add(burned, txFee(receipts[i].GasUsed, tx.GasPrice()))
+			}
+		}
+		for i, tx := range stakingTxs {
+			burned.Add(burned, txFee(receipts[len(txs)+i].GasUsed, tx.GasPrice()))
+		}
+	}
+	return rawdb.WriteBurnedFeesAccumulator(batch, burned, block.NumberU64())
+}
+
+func txFee(gasUsed uint64, gasPrice *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+}
+
 // Note this should read from the state of current block in concern (root == newBlock.root)
 func (bc *BlockChain) addDelegationIndex(
 	delegations staking.DelegationIndexes,
@@ -2801,6 +2929,34 @@ func (bc *BlockChain) ValidatorCandidates() []common.Address {
 	return list
 }
 
+// ReadGovernedExternalSlots returns the external slots of each shard
+// committee set by the governance parameter updates in the current state,
+// false when the governance did not set them
+func (bc *BlockChain) ReadGovernedExternalSlots() (int, bool) {
+	if !bc.chainConfig.IsGovernance(bc.CurrentHeader().Epoch()) {
+		return 0, false
+	}
+	db, err := bc.State()
+	if err != nil {
+		return 0, false
+	}
+	slots, ok := governance.ExternalSlots(db)
+	return int(slots), ok
+}
+
+// ReadValidatorShardPreference returns the shard preference of the
+// validator in the current state
+func (bc *BlockChain) ReadValidatorShardPreference(addr common.Address) staking.ShardPreference {
+	if !bc.chainConfig.IsShardPreference(bc.CurrentHeader().Epoch()) {
+		return staking.NoShardPreference
+	}
+	db, err := bc.State()
+	if err != nil {
+		return staking.NoShardPreference
+	}
+	return staking.ShardPreference(db.GetState(addr, staking.ShardPreferenceKey).Big().Uint64())
+}
+
 // DelegatorsInformation returns up to date information of delegators of a given validator address
 func (bc *BlockChain) DelegatorsInformation(addr common.Address) []*staking.Delegation {
 	return make([]*staking.Delegation, 0)
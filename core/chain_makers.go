@@ -263,6 +263,10 @@ func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Bloc
 func (cr *fakeChainReader) ReadShardState(epoch *big.Int) (*shard.State, error)     { return nil, nil }
 func (cr *fakeChainReader) ReadValidatorList() ([]common.Address, error)            { return nil, nil }
 func (cr *fakeChainReader) ValidatorCandidates() []common.Address                   { return nil }
+func (cr *fakeChainReader) ReadGovernedExternalSlots() (int, bool)                  { return 0, false }
+func (cr *fakeChainReader) ReadValidatorShardPreference(common.Address) staking.ShardPreference {
+	return staking.NoShardPreference
+}
 func (cr *fakeChainReader) SuperCommitteeForNextEpoch(
 	beacon consensus_engine.ChainReader, header *block.Header, isVerify bool,
 ) (*shard.State, error) {
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/signature"
+	"github.com/harmony-one/harmony/core/rawdb"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/multibls"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// CommitteeCheckpointInterval is the number of epochs between two committee
+// checkpoints of the beacon chain
+const CommitteeCheckpointInterval = 16
+
+// CommitteeCheckpoint is the certificate of the epoch transitions of the
+// beacon chain since the previous checkpoint. The commit signatures of the
+// transitions are aggregated into a single signature, which is verified at
+// once against the committees the transitions carry. The checkpoints are
+// chained by their root, which commits to the headers of the transitions.
+type CommitteeCheckpoint struct {
+	Epoch         uint64 // the transitions lead to the committees of this epoch
+	PrevRoot      common.Hash
+	Root          common.Hash
+	Headers       []*block.Header
+	CommitBitmaps [][]byte
+	AggregateSig  []byte
+}
+
+// committeeCheckpointRoot returns the root of a checkpoint following the
+// checkpoint of root prev with the transitions of the headers
+func committeeCheckpointRoot(prev common.Hash, headers []*block.Header) common.Hash {
+	data := make([][]byte, 0, len(headers)+1)
+	data = append(data, prev[:])
+	for _, header := range headers {
+		hash := header.Hash()
+		data = append(data, hash[:])
+	}
+	return crypto.Keccak256Hash(data...)
+}
+
+// ReadCommitteeCheckpoint returns the committee checkpoint of the epoch.
+func (bc *BlockChain) ReadCommitteeCheckpoint(epoch uint64) (*CommitteeCheckpoint, error) {
+	data, err := rawdb.ReadCommitteeCheckpoint(bc.db, epoch)
+	if err != nil {
+		return nil, errors.Errorf("no committee checkpoint for epoch %d", epoch)
+	}
+	checkpoint := &CommitteeCheckpoint{}
+	if err := rlp.DecodeBytes(data, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// isCommitteeCheckpointBlock returns whether the committee checkpoint of the
+// epoch of the header can be built once the header is written, which is when
+// the header is the first one of a checkpoint epoch of the staking era
+func (bc *BlockChain) isCommitteeCheckpointBlock(header *block.Header) bool {
+	epoch := header.Epoch().Uint64()
+	return bc.ShardID() == shard.BeaconChainShardID &&
+		epoch >= CommitteeCheckpointInterval && epoch%CommitteeCheckpointInterval == 0 &&
+		header.Number().Uint64() == shardingconfig.EpochFirstBlock(shard.Schedule, epoch) &&
+		bc.chainConfig.IsStaking(big.NewInt(int64(epoch-CommitteeCheckpointInterval)))
+}
+
+// writeCommitteeCheckpoint builds and writes the committee checkpoint of the
+// epoch, from the epoch transitions of the previous interval
+func (bc *BlockChain) writeCommitteeCheckpoint(epoch uint64) error {
+	checkpoint := &CommitteeCheckpoint{Epoch: epoch}
+	if prev, err := bc.ReadCommitteeCheckpoint(epoch - CommitteeCheckpointInterval); err == nil {
+		checkpoint.PrevRoot = prev.Root
+	}
+	aggSig := &bls.Sign{}
+	for e := epoch - CommitteeCheckpointInterval; e < epoch; e++ {
+		transition, err := bc.ReadEpochTransition(e)
+		if err != nil {
+			return err
+		}
+		if transition == nil {
+			return errors.Errorf("epoch %d is not over", e)
+		}
+		sig := &bls.Sign{}
+		if err := sig.Deserialize(transition.CommitSig); err != nil {
+			return errors.Wrapf(err, "cannot decode commit signature of epoch %d", e)
+		}
+		aggSig.Add(sig)
+		checkpoint.Headers = append(checkpoint.Headers, transition.Header)
+		checkpoint.CommitBitmaps = append(checkpoint.CommitBitmaps, transition.CommitBitmap)
+	}
+	checkpoint.AggregateSig = aggSig.Serialize()
+	checkpoint.Root = committeeCheckpointRoot(checkpoint.PrevRoot, checkpoint.Headers)
+	data, err := rlp.EncodeToBytes(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := rawdb.WriteCommitteeCheckpoint(bc.db, epoch, data); err != nil {
+		return err
+	}
+	utils.Logger().Info().
+		Uint64("epoch", epoch).
+		Str("root", checkpoint.Root.Hex()).
+		Msg("Wrote committee checkpoint")
+	return nil
+}
+
+type checkpointChainReader struct {
+	config *params.ChainConfig
+}
+
+func (r checkpointChainReader) Config() *params.ChainConfig {
+	return r.config
+}
+
+// VerifyCommitteeCheckpoint verifies the checkpoint against the committees
+// of the first epoch of its interval and the root of the previous checkpoint,
+// and returns the committees of the epoch of the checkpoint. The transition
+// of each epoch must carry the committees of the next one, and be signed by
+// a quorum of the beacon committee of its epoch.
+func VerifyCommitteeCheckpoint(
+	config *params.ChainConfig, committees *shard.State, prevRoot common.Hash,
+	checkpoint *CommitteeCheckpoint,
+) (*shard.State, error) {
+	if checkpoint.PrevRoot != prevRoot {
+		return nil, errors.New("committee checkpoint does not follow the previous checkpoint")
+	}
+	if len(checkpoint.Headers) == 0 || len(checkpoint.Headers) != len(checkpoint.CommitBitmaps) {
+		return nil, errors.New("malformed committee checkpoint")
+	}
+	if committeeCheckpointRoot(prevRoot, checkpoint.Headers) != checkpoint.Root {
+		return nil, errors.New("committee checkpoint root mismatch")
+	}
+	pubKeys := make([]bls.PublicKey, len(checkpoint.Headers))
+	payloads := make([][]byte, len(checkpoint.Headers))
+	for i, header := range checkpoint.Headers {
+		epoch := header.Epoch()
+		if committees.Epoch == nil || committees.Epoch.Cmp(epoch) != 0 ||
+			header.ShardID() != shard.BeaconChainShardID || len(header.ShardState()) == 0 {
+			return nil, errors.Errorf("header %d is not the epoch transition of epoch %v", i, committees.Epoch)
+		}
+		beacon, err := committees.FindCommitteeByID(shard.BeaconChainShardID)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := beacon.BLSPublicKeys()
+		if err != nil {
+			return nil, err
+		}
+		mask, err := bls_cosi.NewMask(keys, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := mask.SetMask(checkpoint.CommitBitmaps[i]); err != nil {
+			return nil, err
+		}
+		decider := quorum.NewDecider(quorum.SuperMajorityStake, shard.BeaconChainShardID)
+		decider.SetMyPublicKeyProvider(func() (*multibls.PublicKey, error) {
+			return nil, nil
+		})
+		if _, err := decider.SetVoters(beacon, epoch); err != nil {
+			return nil, err
+		}
+		if !decider.IsQuorumAchievedByMask(mask) {
+			return nil, errors.Errorf("no quorum for the epoch transition of epoch %v", epoch)
+		}
+		pubKeys[i] = *mask.AggregatePublic
+		payloads[i] = signature.ConstructCommitPayload(
+			checkpointChainReader{config}, epoch, header.Hash(),
+			header.Number().Uint64(), header.ViewID().Uint64(),
+		)
+		if committees, err = shard.DecodeWrapper(header.ShardState()); err != nil {
+			return nil, err
+		}
+	}
+	aggSig := &bls.Sign{}
+	if err := aggSig.Deserialize(checkpoint.AggregateSig); err != nil {
+		return nil, errors.Wrap(err, "cannot decode checkpoint signature")
+	}
+	if !aggSig.VerifyAggregateHashes(pubKeys, payloads) {
+		return nil, errors.New("cannot verify checkpoint signature")
+	}
+	if committees.Epoch == nil || committees.Epoch.Uint64() != checkpoint.Epoch {
+		return nil, errors.Errorf("committee checkpoint does not lead to epoch %d", checkpoint.Epoch)
+	}
+	return committees, nil
+}
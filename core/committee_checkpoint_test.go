@@ -0,0 +1,84 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/signature"
+	bls_cosi "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestVerifyCommitteeCheckpoint(t *testing.T) {
+	config := params.TestChainConfig
+	// the same four keys are the beacon committee of every epoch
+	keys := make([]*bls.SecretKey, 4)
+	slots := shard.SlotList{}
+	for i := range keys {
+		keys[i] = bls_cosi.RandPrivateKey()
+		slot := shard.Slot{EcdsaAddress: common.BigToAddress(big.NewInt(int64(i)))}
+		if err := slot.BLSPublicKey.FromLibBLSPublicKey(keys[i].GetPublicKey()); err != nil {
+			t.Fatal(err)
+		}
+		slots = append(slots, slot)
+	}
+	committees := func(epoch int64) *shard.State {
+		return &shard.State{Epoch: big.NewInt(epoch), Shards: []shard.Committee{
+			{ShardID: shard.BeaconChainShardID, Slots: slots},
+		}}
+	}
+	checkpoint := &CommitteeCheckpoint{Epoch: 3}
+	aggSig := &bls.Sign{}
+	for epoch := int64(1); epoch < 3; epoch++ {
+		next, err := shard.EncodeWrapper(*committees(epoch + 1), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := blockfactory.ForTest.NewHeader(big.NewInt(epoch)).With().
+			Number(big.NewInt(epoch * 10)).ShardState(next).Header()
+		payload := signature.ConstructCommitPayload(
+			checkpointChainReader{config}, header.Epoch(), header.Hash(),
+			header.Number().Uint64(), header.ViewID().Uint64(),
+		)
+		// three of the four keys sign
+		for _, key := range keys[:3] {
+			aggSig.Add(key.SignHash(payload))
+		}
+		checkpoint.Headers = append(checkpoint.Headers, header)
+		checkpoint.CommitBitmaps = append(checkpoint.CommitBitmaps, []byte{0x07})
+	}
+	checkpoint.AggregateSig = aggSig.Serialize()
+	checkpoint.Root = committeeCheckpointRoot(common.Hash{}, checkpoint.Headers)
+
+	result, err := VerifyCommitteeCheckpoint(config, committees(1), common.Hash{}, checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Epoch.Uint64() != 3 {
+		t.Errorf("got committees of epoch %v, expect 3", result.Epoch)
+	}
+
+	// a transition without quorum
+	bitmap := checkpoint.CommitBitmaps[1]
+	checkpoint.CommitBitmaps[1] = []byte{0x01}
+	if _, err := VerifyCommitteeCheckpoint(config, committees(1), common.Hash{}, checkpoint); err == nil {
+		t.Error("expect the transition without quorum rejected")
+	}
+	checkpoint.CommitBitmaps[1] = bitmap
+	// a tampered header
+	headers := checkpoint.Headers
+	checkpoint.Headers = []*block.Header{headers[0], blockfactory.ForTest.NewHeader(big.NewInt(2))}
+	if _, err := VerifyCommitteeCheckpoint(config, committees(1), common.Hash{}, checkpoint); err == nil {
+		t.Error("expect the tampered checkpoint rejected")
+	}
+	checkpoint.Headers = headers
+	// not following the previous checkpoint
+	if _, err := VerifyCommitteeCheckpoint(config, committees(1), common.HexToHash("0x01"), checkpoint); err == nil {
+		t.Error("expect the checkpoint with another previous root rejected")
+	}
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// ErrNoCrossLinkArchive is returned when archiving the crosslinks of a chain
+// without crosslink archive
+var ErrNoCrossLinkArchive = errors.New("no crosslink archive")
+
+// crossLinkArchive holds the crosslink archive of the chain
+type crossLinkArchive struct {
+	db ethdb.Database
+}
+
+// SetCrossLinkArchive sets the database the old crosslinks are moved to. The
+// crosslinks missing from the chain database are read from it.
+func (bc *BlockChain) SetCrossLinkArchive(db ethdb.Database) {
+	bc.crossLinkArchive.Store(crossLinkArchive{db})
+}
+
+// CrossLinkArchive returns the database of the archived crosslinks, nil if
+// the chain has none
+func (bc *BlockChain) CrossLinkArchive() ethdb.Database {
+	archive, _ := bc.crossLinkArchive.Load().(crossLinkArchive)
+	return archive.db
+}
+
+// ArchiveCrossLinks moves the crosslinks of the epochs older than the last
+// keep epochs from the chain database to the crosslink archive, and drops
+// the pending crosslinks of these epochs. The last continuous crosslink of
+// each shard and the crosslinks after it stay in the chain database. It
+// returns the number of archived crosslinks.
+func (bc *BlockChain) ArchiveCrossLinks(keep uint64) (int, error) {
+	archive := bc.CrossLinkArchive()
+	if archive == nil {
+		return 0, ErrNoCrossLinkArchive
+	}
+	epoch := bc.CurrentHeader().Epoch()
+	if epoch.Uint64() <= keep {
+		return 0, nil
+	}
+	return bc.archiveCrossLinks(archive, shard.CrossLinkShards(epoch), epoch.Uint64()-keep)
+}
+
+// archiveCrossLinks moves the crosslinks of the shards before numShards and
+// of the epochs before the until epoch to the archive
+func (bc *BlockChain) archiveCrossLinks(
+	archive ethdb.Database, numShards uint32, until uint64,
+) (int, error) {
+	if err := bc.dropPendingCrossLinks(until); err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for shardID := uint32(1); shardID < numShards; shardID++ {
+		last, err := bc.ReadShardLastCrossLink(shardID)
+		if err != nil || last == nil {
+			continue
+		}
+		from := rawdb.ReadArchivedCrossLinkBlock(bc.db, shardID) + 1
+		batch, archiveBatch := bc.db.NewBatch(), archive.NewBatch()
+		number := from
+		for ; number < last.BlockNum(); number++ {
+			data, err := rawdb.ReadCrossLinkShardBlock(bc.db, shardID, number)
+			if err == nil {
+				cl, err := types.DeserializeCrossLink(data)
+				if err != nil {
+					return archived, errors.Wrapf(err, "cannot decode crosslink %d of shard %d", number, shardID)
+				}
+				if cl.Epoch().Uint64() >= until {
+					break
+				}
+				if err := rawdb.WriteCrossLinkShardBlock(archiveBatch, shardID, number, data); err != nil {
+					return archived, err
+				}
+				if err := rawdb.DeleteCrossLinkShardBlock(batch, shardID, number); err != nil {
+					return archived, err
+				}
+				if beaconNum, ok := rawdb.ReadCrossLinkBeaconBlock(bc.db, shardID, number); ok {
+					if err := rawdb.WriteCrossLinkBeaconBlock(archiveBatch, shardID, number, beaconNum); err != nil {
+						return archived, err
+					}
+					if err := rawdb.DeleteCrossLinkBeaconBlock(batch, shardID, number); err != nil {
+						return archived, err
+					}
+				}
+				archived++
+			}
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := bc.writeArchivedCrossLinks(batch, archiveBatch, shardID, number); err != nil {
+					return archived, err
+				}
+			}
+		}
+		if number > from {
+			if err := bc.writeArchivedCrossLinks(batch, archiveBatch, shardID, number-1); err != nil {
+				return archived, err
+			}
+		}
+	}
+	if archived > 0 {
+		utils.Logger().Info().
+			Uint64("until-epoch", until).
+			Int("archived", archived).
+			Msg("Archived old crosslinks")
+	}
+	return archived, nil
+}
+
+// writeArchivedCrossLinks writes the crosslinks to the archive before they
+// are deleted from the chain database with the last archived number
+func (bc *BlockChain) writeArchivedCrossLinks(
+	batch, archiveBatch ethdb.Batch, shardID uint32, number uint64,
+) error {
+	if err := archiveBatch.Write(); err != nil {
+		return errors.Wrap(err, "cannot write crosslink archive")
+	}
+	if err := rawdb.WriteArchivedCrossLinkBlock(batch, shardID, number); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return errors.Wrap(err, "cannot delete archived crosslinks")
+	}
+	batch.Reset()
+	archiveBatch.Reset()
+	return nil
+}
+
+// dropPendingCrossLinks removes the pending crosslinks of the epochs before
+// the until epoch, which are no longer proposed
+func (bc *BlockChain) dropPendingCrossLinks(until uint64) error {
+	bc.pendingCrossLinksMutex.Lock()
+	defer bc.pendingCrossLinksMutex.Unlock()
+
+	cls, err := bc.ReadPendingCrossLinks()
+	if err != nil || len(cls) == 0 {
+		return nil
+	}
+	pending := []types.CrossLink{}
+	for _, cl := range cls {
+		if cl.Epoch().Uint64() >= until {
+			pending = append(pending, cl)
+		}
+	}
+	if len(pending) == len(cls) {
+		return nil
+	}
+	return bc.WritePendingCrossLinks(pending)
+}
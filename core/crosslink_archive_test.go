@@ -0,0 +1,65 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func TestArchiveCrossLinks(t *testing.T) {
+	bc := createBlockChain()
+	archive := ethdb.NewMemDatabase()
+	bc.SetCrossLinkArchive(archive)
+
+	// the crosslinks of the blocks 2 to 5 of shard 1, in the epochs 1 to 3
+	epochs := map[uint64]int64{2: 1, 3: 1, 4: 2, 5: 3}
+	cls := []types.CrossLink{}
+	for number := uint64(2); number <= 5; number++ {
+		cls = append(cls, types.CrossLink{
+			BlockNumberF: new(big.Int).SetUint64(number),
+			ViewIDF:      new(big.Int).SetUint64(number),
+			ShardIDF:     1,
+			EpochF:       big.NewInt(epochs[number]),
+		})
+	}
+	if err := bc.WriteCrossLinks(bc.db, cls); err != nil {
+		t.Fatal(err)
+	}
+	if err := rawdb.WriteShardLastCrossLink(bc.db, 1, cls[3].Serialize()); err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.WritePendingCrossLinks([]types.CrossLink{cls[0], cls[3]}); err != nil {
+		t.Fatal(err)
+	}
+
+	archived, err := bc.archiveCrossLinks(archive, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived != 3 {
+		t.Errorf("got %d archived crosslinks, expect 3", archived)
+	}
+	if _, err := rawdb.ReadCrossLinkShardBlock(bc.db, 1, 4); err == nil {
+		t.Error("expect the archived crosslink deleted from the chain database")
+	}
+	if _, err := rawdb.ReadCrossLinkShardBlock(bc.db, 1, 5); err != nil {
+		t.Error("expect the last crosslink kept in the chain database")
+	}
+	for number := uint64(2); number <= 5; number++ {
+		if cl, err := bc.ReadCrossLink(1, number); err != nil || cl.BlockNum() != number {
+			t.Errorf("block %d: got crosslink %v %v", number, cl, err)
+		}
+	}
+	pending, err := bc.ReadPendingCrossLinks()
+	if err != nil || len(pending) != 1 || pending[0].BlockNum() != 5 {
+		t.Errorf("got pending crosslinks %v %v, expect the crosslink of block 5", pending, err)
+	}
+
+	// the archived crosslinks are not scanned again
+	if archived, err := bc.archiveCrossLinks(archive, 2, 4); err != nil || archived != 0 {
+		t.Errorf("got %d archived crosslinks %v, expect none", archived, err)
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// CrossLinkStatus is the status of the crosslink of a shard block on the
+// beacon chain
+type CrossLinkStatus struct {
+	// CrossLink is the crosslink included in the beacon chain, nil if none
+	CrossLink *types.CrossLink
+	// Pending is whether the crosslink waits to be proposed by the leader
+	Pending bool
+	// BeaconBlock is the number of the beacon chain block which included the
+	// crosslink, 0 if it is not included or was included before the beacon
+	// block numbers of the crosslinks were recorded
+	BeaconBlock uint64
+	// Finalized is whether the beacon chain block which included the
+	// crosslink was committed
+	Finalized bool
+}
+
+// ReadCrossLinkBeaconBlock returns the number of the beacon chain block which
+// included the crosslink of the block of the shard, including the crosslinks
+// moved to the crosslink archive
+func (bc *BlockChain) ReadCrossLinkBeaconBlock(shardID uint32, blockNum uint64) (uint64, bool) {
+	if number, ok := rawdb.ReadCrossLinkBeaconBlock(bc.db, shardID, blockNum); ok {
+		return number, true
+	}
+	if archive := bc.CrossLinkArchive(); archive != nil &&
+		blockNum <= rawdb.ReadArchivedCrossLinkBlock(bc.db, shardID) {
+		return rawdb.ReadCrossLinkBeaconBlock(archive, shardID, blockNum)
+	}
+	return 0, false
+}
+
+// ReadCrossLinkStatus returns the status of the crosslink of the block of
+// the shard on the beacon chain.
+func (bc *BlockChain) ReadCrossLinkStatus(shardID uint32, blockNum uint64) (*CrossLinkStatus, error) {
+	if bc.ShardID() != shard.BeaconChainShardID {
+		return nil, errors.Errorf("shard %d is not the beacon chain", bc.ShardID())
+	}
+	status := &CrossLinkStatus{}
+	if crossLink, err := bc.ReadCrossLink(shardID, blockNum); err == nil {
+		status.CrossLink = crossLink
+	}
+	if status.CrossLink == nil {
+		pending, err := bc.ReadPendingCrossLinks()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read pending crosslinks")
+		}
+		for _, cl := range pending {
+			if cl.ShardID() == shardID && cl.BlockNum() == blockNum {
+				status.Pending = true
+				break
+			}
+		}
+		return status, nil
+	}
+	number, ok := bc.ReadCrossLinkBeaconBlock(shardID, blockNum)
+	if !ok {
+		// the crosslinks are written with their beacon block once committed
+		status.Finalized = true
+		return status, nil
+	}
+	status.BeaconBlock = number
+	status.Finalized = bc.isCommitted(number)
+	return status, nil
+}
+
+// isCommitted returns whether the commit signature of the canonical block of
+// the number is known, from the next block or from the last commits
+func (bc *BlockChain) isCommitted(number uint64) bool {
+	current := bc.CurrentHeader().Number().Uint64()
+	if number < current {
+		return true
+	}
+	if number > current {
+		return false
+	}
+	lastCommits, err := bc.ReadCommitSig(number)
+	return err == nil && len(lastCommits) > shard.BLSSignatureSizeInBytes
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestReadCrossLinkStatus(t *testing.T) {
+	bc := createBlockChain()
+	archive := ethdb.NewMemDatabase()
+	bc.SetCrossLinkArchive(archive)
+
+	// the crosslink of block 2 was included before the beacon block numbers
+	// were recorded, the ones of blocks 3 and 4 in the beacon block 1
+	epochs := map[uint64]int64{2: 1, 3: 1, 4: 3}
+	cls := []types.CrossLink{}
+	for number := uint64(2); number <= 4; number++ {
+		cls = append(cls, types.CrossLink{
+			BlockNumberF: new(big.Int).SetUint64(number),
+			ViewIDF:      new(big.Int).SetUint64(number),
+			ShardIDF:     1,
+			EpochF:       big.NewInt(epochs[number]),
+		})
+	}
+	if err := bc.WriteCrossLinks(bc.db, cls); err != nil {
+		t.Fatal(err)
+	}
+	for _, cl := range cls[1:] {
+		if err := rawdb.WriteCrossLinkBeaconBlock(bc.db, 1, cl.BlockNum(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rawdb.WriteShardLastCrossLink(bc.db, 1, cls[2].Serialize()); err != nil {
+		t.Fatal(err)
+	}
+	pending := types.CrossLink{BlockNumberF: big.NewInt(5), ShardIDF: 1, EpochF: big.NewInt(3)}
+	if err := bc.WritePendingCrossLinks([]types.CrossLink{pending}); err != nil {
+		t.Fatal(err)
+	}
+	if archived, err := bc.archiveCrossLinks(archive, 2, 2); err != nil || archived != 2 {
+		t.Fatalf("got %d archived crosslinks %v, expect 2", archived, err)
+	}
+
+	// the beacon block 1 is not committed yet
+	tests := []struct {
+		blockNum          uint64
+		included, pending bool
+		finalized         bool
+		beaconBlock       uint64
+	}{
+		{2, true, false, true, 0},
+		{3, true, false, false, 1},
+		{4, true, false, false, 1},
+		{5, false, true, false, 0},
+		{6, false, false, false, 0},
+	}
+	for _, test := range tests {
+		status, err := bc.ReadCrossLinkStatus(1, test.blockNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (status.CrossLink != nil) != test.included || status.Pending != test.pending ||
+			status.Finalized != test.finalized || status.BeaconBlock != test.beaconBlock {
+			t.Errorf("block %d: got status %+v", test.blockNum, status)
+		}
+	}
+	if number, ok := bc.ReadCrossLinkBeaconBlock(1, 3); !ok || number != 1 {
+		t.Errorf("got beacon block %d %v of the archived crosslink, expect 1", number, ok)
+	}
+	if _, ok := rawdb.ReadCrossLinkBeaconBlock(bc.db, 1, 3); ok {
+		t.Error("expect the beacon block of the archived crosslink moved to the archive")
+	}
+}
+
+func TestIsCommitted(t *testing.T) {
+	bc := createBlockChain()
+	if bc.isCommitted(0) || bc.isCommitted(1) {
+		t.Error("expect the blocks not committed without commit signature")
+	}
+	if err := bc.WriteCommitSig(0, make([]byte, shard.BLSSignatureSizeInBytes+1)); err != nil {
+		t.Fatal(err)
+	}
+	if !bc.isCommitted(0) {
+		t.Error("expect the current block committed with its commit signature")
+	}
+}
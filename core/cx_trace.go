@@ -0,0 +1,137 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// The stages of the lifecycle of a cross-shard transaction
+const (
+	// CXSourceIncluded is the inclusion of the transaction in a block of its
+	// source shard
+	CXSourceIncluded = "source-included"
+	// CXReceiptExported is the export of the cross-shard receipt of the
+	// transaction by the block of the source shard, to the destination shard
+	CXReceiptExported = "receipt-exported"
+	// CXBeaconConfirmed is the inclusion of the crosslink of the block of the
+	// source shard in the beacon chain, or the block itself on the beacon chain
+	CXBeaconConfirmed = "beacon-confirmed"
+	// CXDestinationApplied is the credit of the receipt by a block of the
+	// destination shard
+	CXDestinationApplied = "destination-applied"
+)
+
+// ErrCXTraceNotFound is returned tracing a transaction that no chain of the
+// node knows as cross-shard
+var ErrCXTraceNotFound = errors.New("cross-shard transaction not found")
+
+// CXTraceEvent is a stage of the lifecycle of a cross-shard transaction,
+// observed in a block of a chain
+type CXTraceEvent struct {
+	Stage       string
+	ShardID     uint32
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Timestamp   int64
+}
+
+// CXTrace is the lifecycle of a cross-shard transaction as observed by the
+// chains of a node, in stage order. Its correlation ID is the hash of the
+// transaction on the source shard, which the cross-shard receipt, its proof
+// and its credit on the destination shard are all keyed by. The stages of
+// the shards the node does not keep are missing.
+type CXTrace struct {
+	TxHash      common.Hash
+	FromShardID uint32
+	ToShardID   uint32
+	Events      []CXTraceEvent
+}
+
+// Delivered returns whether the destination shard credited the transaction
+func (t *CXTrace) Delivered() bool {
+	for _, event := range t.Events {
+		if event.Stage == CXDestinationApplied {
+			return true
+		}
+	}
+	return false
+}
+
+// cxTraceEvent returns the event of the stage observed in the block of the
+// chain
+func (bc *BlockChain) cxTraceEvent(stage string, number uint64, hash common.Hash) CXTraceEvent {
+	event := CXTraceEvent{
+		Stage: stage, ShardID: bc.ShardID(), BlockNumber: number, BlockHash: hash,
+	}
+	if header := bc.GetHeader(hash, number); header != nil {
+		event.Timestamp = header.Time().Int64()
+	}
+	return event
+}
+
+// TraceCX returns the lifecycle of the cross-shard transaction of the hash,
+// as recorded by the indexes of the given chains.
+func TraceCX(hash common.Hash, chains ...*BlockChain) (*CXTrace, error) {
+	trace := &CXTrace{TxHash: hash}
+	var source, destination []CXTraceEvent
+	var beacon *BlockChain
+	// the number of the block of the source shard, from the source shard or
+	// from the proof of the receipt on the destination shard
+	var sourceBlock *uint64
+	for _, chain := range chains {
+		if chain.ShardID() == shard.BeaconChainShardID {
+			beacon = chain
+		}
+		if tx, blockHash, number, _ := rawdb.ReadTransaction(chain.db, hash); tx != nil {
+			if tx.ShardID() == tx.ToShardID() {
+				return nil, errors.Errorf("transaction %s is not cross-shard", hash.Hex())
+			}
+			trace.FromShardID, trace.ToShardID = tx.ShardID(), tx.ToShardID()
+			sourceBlock = &number
+			source = append(source, chain.cxTraceEvent(CXSourceIncluded, number, blockHash))
+			receipts, err := chain.ReadCXReceipts(tx.ToShardID(), number, blockHash)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot read the cross-shard receipts of block %d", number)
+			}
+			for _, cx := range receipts {
+				if cx.TxHash == hash {
+					source = append(source, chain.cxTraceEvent(CXReceiptExported, number, blockHash))
+					break
+				}
+			}
+		}
+		if cx, blockHash, number, _ := rawdb.ReadCXReceipt(chain.db, hash); cx != nil {
+			trace.FromShardID, trace.ToShardID = cx.ShardID, cx.ToShardID
+			destination = append(destination, chain.cxTraceEvent(CXDestinationApplied, number, blockHash))
+			if block := chain.GetBlock(blockHash, number); block != nil && sourceBlock == nil {
+				for _, proof := range block.IncomingReceipts() {
+					for _, receipt := range proof.Receipts {
+						if receipt.TxHash == hash && proof.MerkleProof != nil {
+							number := proof.MerkleProof.BlockNum.Uint64()
+							sourceBlock = &number
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(source) == 0 && len(destination) == 0 {
+		return nil, errors.Wrapf(ErrCXTraceNotFound, "%s", hash.Hex())
+	}
+	trace.Events = source
+	if sourceBlock != nil && beacon != nil {
+		number, ok := *sourceBlock, trace.FromShardID == shard.BeaconChainShardID
+		if !ok {
+			number, ok = beacon.ReadCrossLinkBeaconBlock(trace.FromShardID, *sourceBlock)
+		}
+		if header := beacon.GetHeaderByNumber(number); ok && header != nil {
+			trace.Events = append(
+				trace.Events, beacon.cxTraceEvent(CXBeaconConfirmed, number, header.Hash()),
+			)
+		}
+	}
+	trace.Events = append(trace.Events, destination...)
+	return trace, nil
+}
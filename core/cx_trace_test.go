@@ -0,0 +1,104 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	chain2 "github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/pkg/errors"
+)
+
+func TestTraceCX(t *testing.T) {
+	beacon := createBlockChain()
+	gspec := Genesis{
+		Config: params.TestChainConfig, Factory: blockfactory.ForTest, GasLimit: 1e18, ShardID: 1,
+	}
+	database := ethdb.NewMemDatabase()
+	gspec.MustCommit(database)
+	source, err := NewBlockChain(database, nil, gspec.Config, chain2.Engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the transfer from shard 1 in its block 1, confirmed and credited by the
+	// beacon block 1
+	to := common.BytesToAddress([]byte{0x11})
+	tx := types.NewCrossShardTransaction(0, &to, 1, 0, big.NewInt(100), 21000, big.NewInt(1), nil)
+	cx := &types.CXReceipt{
+		TxHash: tx.Hash(), To: &to, ShardID: 1, ToShardID: 0, Amount: big.NewInt(100),
+	}
+	sourceBlock := types.NewBlock(
+		blockfactory.ForTest.NewHeader(common.Big1).With().Number(big.NewInt(1)).ShardID(1).Header(),
+		types.Transactions{tx}, types.Receipts{&types.Receipt{}}, types.CXReceipts{cx}, nil, nil,
+	)
+	rawdb.WriteBlock(source.db, sourceBlock)
+	rawdb.WriteTxLookupEntries(source.db, sourceBlock)
+
+	proof := &types.CXReceiptsProof{
+		Receipts: types.CXReceipts{cx},
+		MerkleProof: &types.CXMerkleProof{
+			BlockNum: big.NewInt(1), BlockHash: sourceBlock.Hash(), ShardID: 1,
+		},
+		Header: sourceBlock.Header(),
+	}
+	beaconBlock := types.NewBlock(
+		blockfactory.ForTest.NewHeader(common.Big1).With().Number(big.NewInt(1)).ShardID(0).Header(),
+		nil, nil, nil, []*types.CXReceiptsProof{proof}, nil,
+	)
+	rawdb.WriteBlock(beacon.db, beaconBlock)
+	rawdb.WriteCanonicalHash(beacon.db, beaconBlock.Hash(), 1)
+	rawdb.WriteCxLookupEntries(beacon.db, beaconBlock)
+	if err := rawdb.WriteCrossLinkBeaconBlock(beacon.db, 1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the node of shard 0 does not know the source shard until its receipts
+	// are exported
+	trace, err := TraceCX(tx.Hash(), beacon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{CXBeaconConfirmed, CXDestinationApplied}
+	checkTrace(t, trace, expect)
+
+	if err := rawdb.WriteCXReceipts(
+		source.db, 0, 1, sourceBlock.Hash(), types.CXReceipts{cx},
+	); err != nil {
+		t.Fatal(err)
+	}
+	trace, err = TraceCX(tx.Hash(), source, beacon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = []string{CXSourceIncluded, CXReceiptExported, CXBeaconConfirmed, CXDestinationApplied}
+	checkTrace(t, trace, expect)
+	if trace.FromShardID != 1 || trace.ToShardID != 0 || trace.Events[0].BlockHash != sourceBlock.Hash() {
+		t.Errorf("got trace %+v", trace)
+	}
+
+	if _, err := TraceCX(common.Hash{0x01}, source, beacon); errors.Cause(err) != ErrCXTraceNotFound {
+		t.Errorf("got %v, expect %v", err, ErrCXTraceNotFound)
+	}
+}
+
+func checkTrace(t *testing.T, trace *CXTrace, stages []string) {
+	t.Helper()
+	if len(trace.Events) != len(stages) {
+		t.Fatalf("got events %+v, expect stages %v", trace.Events, stages)
+	}
+	for i, stage := range stages {
+		if trace.Events[i].Stage != stage {
+			t.Errorf("got stage %s, expect %s", trace.Events[i].Stage, stage)
+		}
+	}
+	if !trace.Delivered() {
+		t.Error("expect the transfer delivered")
+	}
+}
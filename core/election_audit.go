@@ -0,0 +1,75 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/shard/committee"
+	"github.com/pkg/errors"
+)
+
+// SetElectionAudit sets whether the chain records the audit log of the
+// elections of the committees of the beacon chain blocks it inserts.
+func (bc *BlockChain) SetElectionAudit(enabled bool) {
+	value := int32(0)
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&bc.electionAudit, value)
+}
+
+// ReadElectionAudit returns the audit log of the election of the committees
+// of the epoch.
+func (bc *BlockChain) ReadElectionAudit(epoch uint64) (*committee.ElectionAudit, error) {
+	data, err := rawdb.ReadElectionAudit(bc.db, epoch)
+	if err != nil {
+		return nil, errors.Errorf("no election audit for epoch %d", epoch)
+	}
+	audit := &committee.ElectionAudit{}
+	if err := json.Unmarshal(data, audit); err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
+
+// isElectionAuditBlock returns whether the header carries the committees of
+// a staking election which is to be audited
+func (bc *BlockChain) isElectionAuditBlock(header *block.Header) bool {
+	return atomic.LoadInt32(&bc.electionAudit) == 1 &&
+		bc.ShardID() == shard.BeaconChainShardID &&
+		shard.Schedule.IsLastBlock(header.Number().Uint64()) &&
+		len(header.ShardState()) > 0 &&
+		bc.chainConfig.IsStaking(new(big.Int).Add(header.Epoch(), common.Big1))
+}
+
+// newElectionAudit runs the election of the committees the header carries
+// again from the state of the current block, which is the one the leader
+// elected them from
+func (bc *BlockChain) newElectionAudit(header *block.Header) (*committee.ElectionAudit, error) {
+	elected, err := shard.DecodeWrapper(header.ShardState())
+	if err != nil {
+		return nil, err
+	}
+	audit, err := committee.NewElectionAudit(
+		new(big.Int).Add(header.Epoch(), common.Big1), bc, elected,
+	)
+	if err != nil {
+		return nil, err
+	}
+	audit.BlockNumber = header.Number().Uint64()
+	return audit, nil
+}
+
+// writeElectionAudit writes the audit log of an election
+func (bc *BlockChain) writeElectionAudit(audit *committee.ElectionAudit) error {
+	data, err := json.Marshal(audit)
+	if err != nil {
+		return err
+	}
+	return rawdb.WriteElectionAudit(bc.db, audit.Epoch.Uint64(), data)
+}
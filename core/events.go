@@ -19,11 +19,16 @@ package core
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/harmony/core/types"
+	staking "github.com/harmony-one/harmony/staking/types"
 )
 
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// NewStakingTxsEvent is posted when a batch of staking transactions become
+// pending in the transaction pool.
+type NewStakingTxsEvent struct{ Txs []*staking.StakingTransaction }
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
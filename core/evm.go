@@ -60,6 +60,7 @@ func NewEVMContext(msg Message, header *block.Header, chain ChainContext, author
 		Transfer:    Transfer,
 		IsValidator: IsValidator,
 		GetHash:     GetHashFn(header, chain),
+		GetVRF:      GetVRFFn(header, chain),
 		Origin:      msg.From(),
 		Coinbase:    beneficiary,
 		BlockNumber: header.Number(),
@@ -96,6 +97,23 @@ func GetHashFn(ref *block.Header, chain ChainContext) func(n uint64) common.Hash
 	}
 }
 
+// GetVRFFn returns a GetVRFFunc which retrieves the VRFs of the ancestors of
+// the header by number
+func GetVRFFn(ref *block.Header, chain ChainContext) func(n uint64) []byte {
+	getHash := GetHashFn(ref, chain)
+	return func(n uint64) []byte {
+		hash := getHash(n)
+		if hash == (common.Hash{}) {
+			return nil
+		}
+		header := chain.GetHeader(hash, n)
+		if header == nil {
+			return nil
+		}
+		return header.Vrf()
+	}
+}
+
 // CanTransfer checks whether there are enough funds in the address' account to make a transfer.
 // This does not take the necessary gas in to account to make the transfer valid.
 func CanTransfer(db vm.StateDB, addr common.Address, amount *big.Int) bool {
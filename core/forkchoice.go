@@ -0,0 +1,358 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+const (
+	// DefaultForkRetention is the default number of blocks below the head
+	// the fork blocks are retained for
+	DefaultForkRetention = 1024
+	// maxReorgEvents is the number of recent reorgs recorded
+	maxReorgEvents = 128
+	// maxForkSegments is the number of recent competing segments recorded
+	maxForkSegments = 128
+	// maxDisplacedBlocks is the number of rewound canonical blocks tracked
+	// until they are replaced or restored, the deeper blocks of a rewind are
+	// not tracked
+	maxDisplacedBlocks = 4096
+)
+
+var (
+	competingBlocksCounter = metrics.DefaultRegistry.NewCounter(
+		"chain_competing_blocks_total",
+		"Number of blocks observed competing with the canonical chain", "shard",
+	)
+	reorgsCounter = metrics.DefaultRegistry.NewCounter(
+		"chain_reorgs_total", "Number of reorgs of the canonical chain", "shard",
+	)
+	reorgDepthHistogram = metrics.DefaultRegistry.NewHistogram(
+		"chain_reorg_depth", "Number of canonical blocks replaced by the reorgs",
+		[]float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 1024}, "shard",
+	)
+	forkBlocksGauge = metrics.DefaultRegistry.NewGauge(
+		"chain_fork_blocks_retained", "Number of fork blocks retained for forensic analysis", "shard",
+	)
+)
+
+// ForkBlock is a block off the canonical chain, the block of a competing
+// segment or a canonical block replaced by a reorg
+type ForkBlock struct {
+	Number uint64
+	Hash   common.Hash
+	Leader common.Address // coinbase of the block
+	// LeaderKey is the BLS key of the leader which proposed the block, empty
+	// before staking or if the leader is not in the committee
+	LeaderKey string
+}
+
+// ForkSegment is a chain segment observed competing with the canonical chain,
+// forked from its ancestor block
+type ForkSegment struct {
+	AncestorNumber uint64
+	AncestorHash   common.Hash
+	Blocks         []ForkBlock
+	FirstSeen      uint64 // unix time
+	LastSeen       uint64
+}
+
+// ReorgEvent is a reorganization of the canonical chain, whose blocks above
+// the common ancestor were replaced by the blocks of another segment
+type ReorgEvent struct {
+	ShardID        uint32
+	Depth          uint64 // number of canonical blocks replaced
+	AncestorNumber uint64
+	AncestorHash   common.Hash
+	// Replaced are the losing blocks in number order, the blocks deeper than
+	// the tracked rewinds missing
+	Replaced []ForkBlock
+	// Replacement is the first block of the winning segment
+	Replacement ForkBlock
+	Time        uint64 // unix time
+}
+
+// forkTracker records the competing segments and the reorgs of the chain,
+// and retains the fork blocks for a window of blocks below the head
+type forkTracker struct {
+	lock      sync.Mutex
+	db        ethdb.Database
+	shard     string
+	retention uint64
+	// displaced are the rewound canonical blocks by number, until a block of
+	// their number is written again
+	displaced map[uint64]ForkBlock
+	segments  []*ForkSegment
+	reorgs    []ReorgEvent
+	retained  []rawdb.ForkBlockEntry
+}
+
+func newForkTracker(db ethdb.Database, shardID uint32) *forkTracker {
+	t := &forkTracker{
+		db:        db,
+		shard:     fmt.Sprint(shardID),
+		retention: DefaultForkRetention,
+		displaced: map[uint64]ForkBlock{},
+		retained:  rawdb.ReadForkBlockEntries(db),
+	}
+	if data, err := rawdb.ReadReorgEvents(db); err == nil && len(data) > 0 {
+		if err := rlp.DecodeBytes(data, &t.reorgs); err != nil {
+			utils.Logger().Error().Err(err).Msg("[ForkChoice] invalid reorg events")
+		}
+	}
+	forkBlocksGauge.Set(float64(len(t.retained)), t.shard)
+	return t
+}
+
+// SetForkRetention sets the number of blocks below the head the fork blocks
+// are retained for, 0 not to retain them.
+func (bc *BlockChain) SetForkRetention(blocks uint64) {
+	bc.forks.lock.Lock()
+	defer bc.forks.lock.Unlock()
+	bc.forks.retention = blocks
+}
+
+// RecentReorgs returns the recent reorgs of the canonical chain, the latest
+// first.
+func (bc *BlockChain) RecentReorgs() []ReorgEvent {
+	bc.forks.lock.Lock()
+	defer bc.forks.lock.Unlock()
+	reorgs := make([]ReorgEvent, 0, len(bc.forks.reorgs))
+	for i := len(bc.forks.reorgs) - 1; i >= 0; i-- {
+		reorgs = append(reorgs, bc.forks.reorgs[i])
+	}
+	return reorgs
+}
+
+// ForkSegments returns the recent chain segments observed competing with the
+// canonical chain, the latest first.
+func (bc *BlockChain) ForkSegments() []ForkSegment {
+	bc.forks.lock.Lock()
+	defer bc.forks.lock.Unlock()
+	segments := make([]ForkSegment, 0, len(bc.forks.segments))
+	for i := len(bc.forks.segments) - 1; i >= 0; i-- {
+		segment := *bc.forks.segments[i]
+		segment.Blocks = append([]ForkBlock{}, segment.Blocks...)
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// ReadForkBlock returns the retained fork block of the hash, nil if it is not
+// retained.
+func (bc *BlockChain) ReadForkBlock(hash common.Hash) *types.Block {
+	bc.forks.lock.Lock()
+	defer bc.forks.lock.Unlock()
+	for _, entry := range bc.forks.retained {
+		if entry.Hash == hash {
+			return rawdb.ReadForkBlock(bc.db, hash, entry.Number)
+		}
+	}
+	return nil
+}
+
+// forkBlock returns the fork block of the header, with the key of its leader
+func (bc *BlockChain) forkBlock(header *block.Header) ForkBlock {
+	fork := ForkBlock{
+		Number: header.Number().Uint64(), Hash: header.Hash(), Leader: header.Coinbase(),
+	}
+	if !bc.chainConfig.IsStaking(header.Epoch()) {
+		return fork
+	}
+	shardState, err := bc.ReadShardState(header.Epoch())
+	if err != nil {
+		return fork
+	}
+	committee, err := shardState.FindCommitteeByID(header.ShardID())
+	if err != nil {
+		return fork
+	}
+	// after staking the coinbase is the address of the BLS key of the leader
+	for _, member := range committee.Slots {
+		if utils.GetAddressFromBLSPubKeyBytes(member.BLSPublicKey[:]) == fork.Leader {
+			fork.LeaderKey = member.BLSPublicKey.Hex()
+			break
+		}
+	}
+	return fork
+}
+
+// retain stores the fork block, if the head is within the retention window
+func (t *forkTracker) retain(b *types.Block, head uint64) {
+	if t.retention == 0 || b.NumberU64()+t.retention < head {
+		return
+	}
+	for _, entry := range t.retained {
+		if entry.Hash == b.Hash() {
+			return
+		}
+	}
+	if err := rawdb.WriteForkBlock(t.db, b); err != nil {
+		utils.Logger().Warn().Err(err).Uint64("number", b.NumberU64()).
+			Msg("[ForkChoice] cannot retain fork block")
+		return
+	}
+	t.retained = append(t.retained, rawdb.ForkBlockEntry{Number: b.NumberU64(), Hash: b.Hash()})
+	t.writeRetained()
+}
+
+// prune deletes the fork blocks out of the retention window of the head
+func (t *forkTracker) prune(head uint64) {
+	kept := t.retained[:0]
+	for _, entry := range t.retained {
+		if entry.Number+t.retention >= head {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := rawdb.DeleteForkBlock(t.db, entry.Hash, entry.Number); err != nil {
+			utils.Logger().Warn().Err(err).Uint64("number", entry.Number).
+				Msg("[ForkChoice] cannot delete fork block")
+		}
+	}
+	if len(kept) != len(t.retained) {
+		t.retained = kept
+		t.writeRetained()
+	}
+}
+
+func (t *forkTracker) writeRetained() {
+	if err := rawdb.WriteForkBlockEntries(t.db, t.retained); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[ForkChoice] cannot write fork block entries")
+	}
+	forkBlocksGauge.Set(float64(len(t.retained)), t.shard)
+}
+
+// observeCompeting records a block competing with the canonical chain, which
+// extends the competing segment of its parent if any
+func (bc *BlockChain) observeCompeting(b *types.Block) {
+	fork := bc.forkBlock(b.Header())
+	t := bc.forks
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := uint64(time.Now().Unix())
+	competingBlocksCounter.Inc(t.shard)
+	t.retain(b, bc.CurrentBlock().NumberU64())
+	for _, segment := range t.segments {
+		for _, known := range segment.Blocks {
+			if known.Hash == fork.Hash {
+				segment.LastSeen = now
+				return
+			}
+		}
+		if tip := segment.Blocks[len(segment.Blocks)-1]; tip.Hash == b.ParentHash() {
+			segment.Blocks = append(segment.Blocks, fork)
+			segment.LastSeen = now
+			return
+		}
+	}
+	utils.Logger().Warn().
+		Uint64("number", fork.Number).
+		Str("hash", fork.Hash.Hex()).
+		Str("leaderKey", fork.LeaderKey).
+		Msg("[ForkChoice] competing block observed")
+	t.segments = append(t.segments, &ForkSegment{
+		AncestorNumber: fork.Number - 1,
+		AncestorHash:   b.ParentHash(),
+		Blocks:         []ForkBlock{fork},
+		FirstSeen:      now,
+		LastSeen:       now,
+	})
+	if len(t.segments) > maxForkSegments {
+		t.segments = t.segments[len(t.segments)-maxForkSegments:]
+	}
+}
+
+// observeRewound records the canonical blocks rewound from the head, a reorg
+// if a different block of their number is written next
+func (bc *BlockChain) observeRewound(blocks []*types.Block) {
+	t := bc.forks
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	head := bc.CurrentBlock().NumberU64()
+	for _, b := range blocks {
+		if len(t.displaced) >= maxDisplacedBlocks {
+			return
+		}
+		t.displaced[b.NumberU64()] = bc.forkBlock(b.Header())
+		t.retain(b, head)
+	}
+}
+
+// observeCanonical records the canonical block written as the new head,
+// closing the reorg of the rewound blocks it replaces if any, and prunes the
+// fork blocks out of the retention window
+func (bc *BlockChain) observeCanonical(b *types.Block) {
+	t := bc.forks
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	number := b.NumberU64()
+	if t.retention > 0 && len(t.retained) > 0 {
+		t.prune(number)
+	}
+	if len(t.displaced) == 0 {
+		return
+	}
+	old, ok := t.displaced[number]
+	if !ok || old.Hash == b.Hash() {
+		// the rewound block was restored, or the rewind did not reach it
+		for n := range t.displaced {
+			if n <= number {
+				delete(t.displaced, n)
+			}
+		}
+		return
+	}
+
+	replaced := []ForkBlock{}
+	for n, fork := range t.displaced {
+		if n >= number {
+			replaced = append(replaced, fork)
+		}
+	}
+	sort.Slice(replaced, func(i, j int) bool { return replaced[i].Number < replaced[j].Number })
+	t.displaced = map[uint64]ForkBlock{}
+
+	event := ReorgEvent{
+		ShardID:        b.ShardID(),
+		Depth:          uint64(len(replaced)),
+		AncestorNumber: number - 1,
+		AncestorHash:   b.ParentHash(),
+		Replaced:       replaced,
+		Replacement:    bc.forkBlock(b.Header()),
+		Time:           uint64(time.Now().Unix()),
+	}
+	utils.Logger().Warn().
+		Uint64("depth", event.Depth).
+		Uint64("ancestor", event.AncestorNumber).
+		Str("replacedLeaderKey", old.LeaderKey).
+		Str("replacementLeaderKey", event.Replacement.LeaderKey).
+		Msg("[ForkChoice] canonical chain reorganized")
+	reorgsCounter.Inc(t.shard)
+	reorgDepthHistogram.Observe(float64(event.Depth), t.shard)
+
+	t.reorgs = append(t.reorgs, event)
+	if len(t.reorgs) > maxReorgEvents {
+		t.reorgs = t.reorgs[len(t.reorgs)-maxReorgEvents:]
+	}
+	data, err := rlp.EncodeToBytes(t.reorgs)
+	if err == nil {
+		err = rawdb.WriteReorgEvents(t.db, data)
+	}
+	if err != nil {
+		utils.Logger().Warn().Err(err).Msg("[ForkChoice] cannot write reorg events")
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func newForkTestBlock(parent *types.Block, coinbase byte) *types.Block {
+	header := blockfactory.ForTest.NewHeader(common.Big0).With().
+		ParentHash(parent.Hash()).
+		Number(new(big.Int).Add(parent.Number(), common.Big1)).
+		Coinbase(common.Address{coinbase}).
+		Header()
+	return types.NewBlock(header, nil, nil, nil, nil, nil)
+}
+
+func TestReorgTracking(t *testing.T) {
+	db, bc, blocks := newTestChain(t)
+	defer bc.Stop()
+
+	if err := bc.RollbackTo(1); err != nil {
+		t.Fatal(err)
+	}
+	replacement := newForkTestBlock(blocks[0], 0x01)
+	bc.observeCanonical(replacement)
+
+	reorgs := bc.RecentReorgs()
+	if len(reorgs) != 1 {
+		t.Fatalf("got %d reorgs, expect 1", len(reorgs))
+	}
+	reorg := reorgs[0]
+	if reorg.Depth != 2 || reorg.AncestorNumber != 1 || reorg.AncestorHash != blocks[0].Hash() {
+		t.Errorf("got reorg %+v", reorg)
+	}
+	if len(reorg.Replaced) != 2 || reorg.Replaced[0].Hash != blocks[1].Hash() ||
+		reorg.Replaced[1].Hash != blocks[2].Hash() {
+		t.Errorf("got replaced blocks %+v", reorg.Replaced)
+	}
+	if reorg.Replacement.Hash != replacement.Hash() || reorg.Replacement.Leader != (common.Address{0x01}) {
+		t.Errorf("got replacement %+v", reorg.Replacement)
+	}
+	// the losing blocks are retained though their bodies were deleted
+	if b := bc.ReadForkBlock(blocks[2].Hash()); b == nil || b.Hash() != blocks[2].Hash() {
+		t.Error("expect the replaced block retained")
+	}
+	// the reorgs are kept across restarts
+	if reorgs := newForkTracker(db, 0).reorgs; len(reorgs) != 1 || reorgs[0].Depth != 2 {
+		t.Errorf("got stored reorgs %+v", reorgs)
+	}
+
+	// restoring a rewound block is no reorg
+	bc.Rollback([]common.Hash{blocks[0].Hash()})
+	bc.observeCanonical(blocks[0])
+	if reorgs := bc.RecentReorgs(); len(reorgs) != 1 {
+		t.Errorf("got %d reorgs restoring a rewound block, expect 1", len(reorgs))
+	}
+}
+
+func TestCompetingSegments(t *testing.T) {
+	_, bc, blocks := newTestChain(t)
+	defer bc.Stop()
+
+	first := newForkTestBlock(blocks[1], 0x01)
+	bc.observeCompeting(first)
+	bc.observeCompeting(newForkTestBlock(first, 0x01))
+	bc.observeCompeting(first)
+	bc.observeCompeting(newForkTestBlock(blocks[0], 0x02))
+
+	segments := bc.ForkSegments()
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, expect 2", len(segments))
+	}
+	if segments[1].AncestorHash != blocks[1].Hash() || len(segments[1].Blocks) != 2 {
+		t.Errorf("got segment %+v", segments[1])
+	}
+	if segments[0].AncestorNumber != 1 || segments[0].Blocks[0].Leader != (common.Address{0x02}) {
+		t.Errorf("got segment %+v", segments[0])
+	}
+
+	// the fork blocks are pruned out of the retention window
+	if bc.ReadForkBlock(first.Hash()) == nil {
+		t.Fatal("expect the competing block retained")
+	}
+	bc.SetForkRetention(4)
+	head := blocks[2]
+	for i := 0; i < 5; i++ {
+		head = newForkTestBlock(head, 0x03)
+		bc.observeCanonical(head)
+	}
+	if bc.ReadForkBlock(first.Hash()) != nil {
+		t.Error("expect the competing block pruned")
+	}
+}
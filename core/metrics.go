@@ -0,0 +1,51 @@
+package core
+
+import (
+	"time"
+
+	"github.com/harmony-one/harmony/internal/metrics"
+)
+
+// Stages of the processing of a block
+const (
+	// stageVerify verifies the header, its commit signature included, and
+	// the body of the block
+	stageVerify = "verify"
+	// stageExecution applies the transactions, the staking transactions and
+	// the incoming receipts of the block
+	stageExecution = "execution"
+	// stageRewards computes and pays the block rewards, in the finalization
+	// of the block
+	stageRewards = "rewards"
+	// stageValidation validates the state root and the receipts of the
+	// executed block
+	stageValidation = "validation"
+	// stageCommit commits the state to the trie database
+	stageCommit = "commit"
+	// stageWrite writes the block, its receipts and its off-chain data to
+	// the chain database
+	stageWrite = "write"
+)
+
+// blockStageBuckets are the upper bounds in seconds of the buckets of the
+// block processing histograms
+var blockStageBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	blockStageHistogram = metrics.DefaultRegistry.NewHistogram(
+		"block_processing_stage_seconds",
+		"Time spent in each stage of the processing of the blocks, the proposed blocks validated by the node included",
+		blockStageBuckets, "stage",
+	)
+	blockInsertHistogram = metrics.DefaultRegistry.NewHistogram(
+		"block_insert_seconds", "Time spent inserting a block into the chain", blockStageBuckets,
+	)
+)
+
+// observeStage records the time spent in the stage since start, and returns
+// the time it ended
+func observeStage(stage string, start time.Time) time.Time {
+	now := time.Now()
+	blockStageHistogram.Observe(now.Sub(start).Seconds(), stage)
+	return now
+}
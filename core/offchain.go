@@ -33,6 +33,9 @@ func (bc *BlockChain) CommitOffChainData(
 ) (status WriteStatus, err error) {
 	// Write receipts of the block
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if err := bc.updateBurnedFees(batch, block, receipts); err != nil {
+		return NonStatTy, err
+	}
 	isBeaconChain := bc.CurrentHeader().ShardID() == shard.BeaconChainShardID
 	isStaking := bc.chainConfig.IsStaking(block.Epoch())
 	isPreStaking := bc.chainConfig.IsPreStaking(block.Epoch())
@@ -137,55 +140,14 @@ func (bc *BlockChain) CommitOffChainData(
 	if isBeaconChain &&
 		bc.chainConfig.IsCrossLink(block.Epoch()) &&
 		len(header.CrossLinks()) > 0 {
-		crossLinks := &types.CrossLinks{}
-		if err := rlp.DecodeBytes(
-			header.CrossLinks(), crossLinks,
-		); err != nil {
-			header.Logger(utils.Logger()).Err(err).
-				Msg("[insertChain/crosslinks] cannot parse cross links")
+		if err := bc.writeHeaderCrossLinks(batch, header); err != nil {
 			return NonStatTy, err
 		}
-		if !crossLinks.IsSorted() {
-			header.Logger(utils.Logger()).Err(err).
-				Msg("[insertChain/crosslinks] cross links are not sorted")
-			return NonStatTy, errors.New("proposed cross links are not sorted")
-		}
-		for _, crossLink := range *crossLinks {
-			// Process crosslink
-			if err := bc.WriteCrossLinks(
-				batch, types.CrossLinks{crossLink},
-			); err == nil {
-				utils.Logger().Info().
-					Uint64("blockNum", crossLink.BlockNum()).
-					Uint32("shardID", crossLink.ShardID()).
-					Msg("[insertChain/crosslinks] Cross Link Added to Beaconchain")
-			}
-
-			cl0, _ := bc.ReadShardLastCrossLink(crossLink.ShardID())
-			if cl0 == nil {
-				rawdb.WriteShardLastCrossLink(batch, crossLink.ShardID(), crossLink.Serialize())
-			}
-		}
-
-		// clean/update local database cache after crosslink inserted into blockchain
-		num, err := bc.DeleteFromPendingCrossLinks(*crossLinks)
-		if err != nil && nodeconfig.GetDefaultConfig().ShardID == shard.BeaconChainShardID {
-			// Only beacon chain worries about this
-			const msg = "DeleteFromPendingCrossLinks, crosslinks in header %d,  pending crosslinks: %d, problem: %+v"
-			utils.Logger().Debug().Msgf(msg, len(*crossLinks), num, err)
-		}
-		const msg = "DeleteFromPendingCrossLinks, crosslinks in header %d,  pending crosslinks: %d"
-		utils.Logger().
-			Debug().
-			Msgf(msg, len(*crossLinks), num)
-		utils.Logger().Debug().Msgf(msg, len(*crossLinks), num)
 	}
 
 	if isBeaconChain && bc.Config().IsCrossLink(bc.CurrentBlock().Epoch()) {
 		// Roll up latest crosslinks
-		for i, c := uint32(0), shard.Schedule.InstanceForEpoch(
-			epoch,
-		).NumShards(); i < c; i++ {
+		for i, c := uint32(0), shard.CrossLinkShards(epoch); i < c; i++ {
 			bc.LastContinuousCrossLink(batch, i)
 		}
 	}
@@ -277,6 +239,66 @@ func (bc *BlockChain) CommitOffChainData(
 	return CanonStatTy, nil
 }
 
+// writeHeaderCrossLinks writes the cross links proposed in a beacon chain header
+func (bc *BlockChain) writeHeaderCrossLinks(
+	batch rawdb.DatabaseWriter, header *block.Header,
+) error {
+	crossLinks := &types.CrossLinks{}
+	var writeErr error
+	if err := types.StreamCrossLinks(header.CrossLinks(), true, func(
+		_ int, crossLink *types.CrossLink,
+	) error {
+		*crossLinks = append(*crossLinks, *crossLink)
+		// Process crosslink
+		if err := bc.WriteCrossLinks(
+			batch, types.CrossLinks{*crossLink},
+		); err == nil {
+			utils.Logger().Info().
+				Uint64("blockNum", crossLink.BlockNum()).
+				Uint32("shardID", crossLink.ShardID()).
+				Msg("[insertChain/crosslinks] Cross Link Added to Beaconchain")
+		}
+		if err := rawdb.WriteCrossLinkBeaconBlock(
+			batch, crossLink.ShardID(), crossLink.BlockNum(), header.Number().Uint64(),
+		); err != nil {
+			writeErr = err
+			return err
+		}
+
+		cl0, _ := bc.ReadShardLastCrossLink(crossLink.ShardID())
+		if cl0 == nil {
+			rawdb.WriteShardLastCrossLink(batch, crossLink.ShardID(), crossLink.Serialize())
+		}
+		return nil
+	}); err != nil {
+		if writeErr != nil {
+			return writeErr
+		}
+		if err == types.ErrCrossLinksNotSorted {
+			header.Logger(utils.Logger()).Error().
+				Msg("[insertChain/crosslinks] cross links are not sorted")
+			return errors.New("proposed cross links are not sorted")
+		}
+		header.Logger(utils.Logger()).Err(err).
+			Msg("[insertChain/crosslinks] cannot parse cross links")
+		return err
+	}
+
+	// clean/update local database cache after crosslink inserted into blockchain
+	num, err := bc.DeleteFromPendingCrossLinks(*crossLinks)
+	if err != nil && nodeconfig.GetDefaultConfig().ShardID == shard.BeaconChainShardID {
+		// Only beacon chain worries about this
+		const msg = "DeleteFromPendingCrossLinks, crosslinks in header %d,  pending crosslinks: %d, problem: %+v"
+		utils.Logger().Debug().Msgf(msg, len(*crossLinks), num, err)
+	}
+	const msg = "DeleteFromPendingCrossLinks, crosslinks in header %d,  pending crosslinks: %d"
+	utils.Logger().
+		Debug().
+		Msgf(msg, len(*crossLinks), num)
+	utils.Logger().Debug().Msgf(msg, len(*crossLinks), num)
+	return nil
+}
+
 func (bc *BlockChain) writeValidatorStats(
 	tempValidatorStats map[common.Address]*staking.ValidatorStats,
 	batch rawdb.DatabaseWriter,
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// number of blocks pruned per database batch
+const pruneBatchBlocks = 1000
+
+// ErrArchivalPrune is returned when pruning the blocks of an archival node
+var ErrArchivalPrune = errors.New("archival node keeps all blocks")
+
+// OldestAvailableBlock returns the number of the oldest block whose body and
+// receipts were not pruned, 0 if no block was pruned
+func (bc *BlockChain) OldestAvailableBlock() uint64 {
+	return rawdb.ReadPrunedBlock(bc.db)
+}
+
+// IsArchival returns whether the states of all blocks are kept
+func (bc *BlockChain) IsArchival() bool {
+	return bc.cacheConfig.Disabled
+}
+
+// PruneAncientBlocks deletes the bodies and receipts of the canonical blocks
+// older than the last keep blocks. The headers are kept, so that the chain
+// can still be verified. The genesis block is never pruned. It returns the
+// number of pruned blocks.
+func (bc *BlockChain) PruneAncientBlocks(keep uint64) (int, error) {
+	if bc.cacheConfig.Disabled {
+		return 0, ErrArchivalPrune
+	}
+	head := bc.CurrentBlock().NumberU64()
+	if head <= keep {
+		return 0, nil
+	}
+	until := head - keep
+	from := rawdb.ReadPrunedBlock(bc.db)
+	if from == 0 {
+		from = 1
+	}
+	pruned := 0
+	batch := bc.db.NewBatch()
+	for number := from; number < until; number++ {
+		if hash := rawdb.ReadCanonicalHash(bc.db, number); hash != (common.Hash{}) {
+			rawdb.DeleteBody(batch, hash, number)
+			rawdb.DeleteReceipts(batch, hash, number)
+			pruned++
+		}
+		if number+1 == until || (number-from+1)%pruneBatchBlocks == 0 {
+			if err := rawdb.WritePrunedBlock(batch, number+1); err != nil {
+				return pruned, err
+			}
+			if err := batch.Write(); err != nil {
+				return pruned, errors.Wrap(err, "cannot prune blocks")
+			}
+			batch.Reset()
+		}
+	}
+	bc.bodyCache.Purge()
+	bc.bodyRLPCache.Purge()
+	bc.receiptsCache.Purge()
+	bc.blockCache.Purge()
+	utils.Logger().Info().
+		Uint64("from", from).
+		Uint64("until", until).
+		Int("pruned", pruned).
+		Msg("Pruned ancient block bodies and receipts")
+	return pruned, nil
+}
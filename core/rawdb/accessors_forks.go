@@ -0,0 +1,76 @@
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+// ForkBlockEntry is a block retained off the canonical chain for forensic
+// analysis, the losing block of a reorg or a competing block.
+type ForkBlockEntry struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// ReadForkBlock retrieves a retained fork block.
+func ReadForkBlock(db DatabaseReader, hash common.Hash, number uint64) *types.Block {
+	data, _ := db.Get(forkBlockKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(data, block); err != nil {
+		utils.Logger().Error().Err(err).Str("hash", hash.Hex()).Msg("Invalid fork block RLP")
+		return nil
+	}
+	return block
+}
+
+// WriteForkBlock stores a retained fork block.
+func WriteForkBlock(db DatabaseWriter, block *types.Block) error {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return err
+	}
+	return db.Put(forkBlockKey(block.NumberU64(), block.Hash()), data)
+}
+
+// DeleteForkBlock deletes a retained fork block.
+func DeleteForkBlock(db DatabaseDeleter, hash common.Hash, number uint64) error {
+	return db.Delete(forkBlockKey(number, hash))
+}
+
+// ReadForkBlockEntries retrieves the index of the retained fork blocks.
+func ReadForkBlockEntries(db DatabaseReader) []ForkBlockEntry {
+	data, _ := db.Get(forkBlocksKey)
+	if len(data) == 0 {
+		return nil
+	}
+	entries := []ForkBlockEntry{}
+	if err := rlp.DecodeBytes(data, &entries); err != nil {
+		utils.Logger().Error().Err(err).Msg("Invalid fork block entries RLP")
+		return nil
+	}
+	return entries
+}
+
+// WriteForkBlockEntries stores the index of the retained fork blocks.
+func WriteForkBlockEntries(db DatabaseWriter, entries []ForkBlockEntry) error {
+	data, err := rlp.EncodeToBytes(entries)
+	if err != nil {
+		return err
+	}
+	return db.Put(forkBlocksKey, data)
+}
+
+// ReadReorgEvents retrieves the recent reorgs of the canonical chain.
+func ReadReorgEvents(db DatabaseReader) ([]byte, error) {
+	return db.Get(reorgEventsKey)
+}
+
+// WriteReorgEvents stores the recent reorgs of the canonical chain.
+func WriteReorgEvents(db DatabaseWriter, data []byte) error {
+	return db.Put(reorgEventsKey, data)
+}
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"encoding/binary"
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -89,3 +90,141 @@ func WritePreimages(db DatabaseWriter, number uint64, preimages map[common.Hash]
 	preimageCounter.Inc(int64(len(preimages)))
 	preimageHitCounter.Inc(int64(len(preimages)))
 }
+
+// ReadSnapSyncPivot retrieves the hash of the pivot of an unfinished snap sync.
+func ReadSnapSyncPivot(db DatabaseReader) common.Hash {
+	data, _ := db.Get(snapSyncPivotKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteSnapSyncPivot stores the hash of the pivot of a snap sync.
+func WriteSnapSyncPivot(db DatabaseWriter, hash common.Hash) error {
+	return db.Put(snapSyncPivotKey, hash.Bytes())
+}
+
+// DeleteSnapSyncPivot removes the pivot of a finished snap sync.
+func DeleteSnapSyncPivot(db DatabaseDeleter) error {
+	return db.Delete(snapSyncPivotKey)
+}
+
+// SnapSyncTrie is the progress of the download of a state trie by a snap
+// sync. The leaves before Origin are committed in the trie of PartialRoot.
+type SnapSyncTrie struct {
+	Root        common.Hash
+	PartialRoot common.Hash
+	Origin      common.Hash
+}
+
+// ReadSnapSyncTrie retrieves the progress of the state trie being downloaded
+// by an unfinished snap sync.
+func ReadSnapSyncTrie(db DatabaseReader) *SnapSyncTrie {
+	data, _ := db.Get(snapSyncTrieKey)
+	if len(data) == 0 {
+		return nil
+	}
+	progress := &SnapSyncTrie{}
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		utils.Logger().Error().Err(err).Msg("Invalid snap sync trie progress RLP")
+		return nil
+	}
+	return progress
+}
+
+// WriteSnapSyncTrie stores the progress of the state trie being downloaded by
+// a snap sync.
+func WriteSnapSyncTrie(db DatabaseWriter, progress *SnapSyncTrie) error {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	return db.Put(snapSyncTrieKey, data)
+}
+
+// DeleteSnapSyncTrie removes the progress of a downloaded state trie.
+func DeleteSnapSyncTrie(db DatabaseDeleter) error {
+	return db.Delete(snapSyncTrieKey)
+}
+
+// ReadPrunedBlock retrieves the number of the first block whose body and
+// receipts were not pruned, 0 if no block was pruned.
+func ReadPrunedBlock(db DatabaseReader) uint64 {
+	data, _ := db.Get(prunedBlockKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WritePrunedBlock stores the number of the first block whose body and
+// receipts were not pruned.
+func WritePrunedBlock(db DatabaseWriter, number uint64) error {
+	return db.Put(prunedBlockKey, encodeBlockNumber(number))
+}
+
+// ReadBeaconLightEpoch retrieves the last epoch whose committees were
+// verified by the beacon light client, false if the client never ran.
+func ReadBeaconLightEpoch(db DatabaseReader) (uint64, bool) {
+	data, _ := db.Get(beaconLightEpochKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteBeaconLightEpoch stores the last epoch whose committees were verified
+// by the beacon light client.
+func WriteBeaconLightEpoch(db DatabaseWriter, epoch uint64) error {
+	return db.Put(beaconLightEpochKey, encodeBlockNumber(epoch))
+}
+
+// QuarantinedRange is a range of blocks found corrupted which could not be
+// repaired, which the node does not serve to its peers.
+type QuarantinedRange struct {
+	From, To uint64
+	Kind     string // kind of the first corruption of the range
+	Reason   string
+	Time     uint64 // unix time it was quarantined
+}
+
+// ReadQuarantinedRanges retrieves the quarantined block ranges.
+func ReadQuarantinedRanges(db DatabaseReader) []QuarantinedRange {
+	data, _ := db.Get(quarantinedRangesKey)
+	if len(data) == 0 {
+		return nil
+	}
+	ranges := []QuarantinedRange{}
+	if err := rlp.DecodeBytes(data, &ranges); err != nil {
+		utils.Logger().Error().Err(err).Msg("Invalid quarantined ranges RLP")
+		return nil
+	}
+	return ranges
+}
+
+// WriteQuarantinedRanges stores the quarantined block ranges.
+func WriteQuarantinedRanges(db DatabaseWriter, ranges []QuarantinedRange) error {
+	data, err := rlp.EncodeToBytes(ranges)
+	if err != nil {
+		return err
+	}
+	return db.Put(quarantinedRangesKey, data)
+}
+
+// ReadSchemaVersion retrieves the version of the storage format of the
+// database, and whether it is marked, the databases created before the
+// versions having none.
+func ReadSchemaVersion(db DatabaseReader) (uint64, bool) {
+	data, _ := db.Get(schemaVersionKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteSchemaVersion stores the version of the storage format of the
+// database.
+func WriteSchemaVersion(db DatabaseWriter, version uint64) error {
+	return db.Put(schemaVersionKey, encodeBlockNumber(version))
+}
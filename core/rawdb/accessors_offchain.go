@@ -1,6 +1,7 @@
 package rawdb
 
 import (
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,21 +13,48 @@ import (
 	"github.com/pkg/errors"
 )
 
+// shardStateDeltaMarker starts the sharding states stored as a delta against
+// the sharding state of the previous epoch, no RLP encoded state starts with it
+const shardStateDeltaMarker = 0x01
+
 // ReadShardState retrieves shard state of a specific epoch.
 func ReadShardState(
 	db DatabaseReader, epoch *big.Int,
+) (*shard.State, error) {
+	return ReadShardStateFrom(db, epoch, func(prev *big.Int) (*shard.State, error) {
+		return ReadShardState(db, prev)
+	})
+}
+
+// ReadShardStateFrom retrieves shard state of a specific epoch, reading the
+// shard state of the previous epoch with base when it is stored as a delta.
+func ReadShardStateFrom(
+	db DatabaseReader, epoch *big.Int, base func(*big.Int) (*shard.State, error),
 ) (*shard.State, error) {
 	data, err := db.Get(shardStateKey(epoch))
 	if err != nil {
 		return nil, errors.New(MsgNoShardStateFromDB)
 	}
-	ss, err2 := shard.DecodeWrapper(data)
-	if err2 != nil {
+	if len(data) == 0 || data[0] != shardStateDeltaMarker {
+		ss, err2 := shard.DecodeWrapper(data)
+		if err2 != nil {
+			return nil, errors.Wrapf(
+				err2, "cannot decode sharding state",
+			)
+		}
+		return ss, nil
+	}
+	delta := shard.StateDelta{}
+	if err := rlp.DecodeBytes(data[1:], &delta); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode sharding state delta")
+	}
+	prev, err := base(new(big.Int).Sub(epoch, common.Big1))
+	if err != nil {
 		return nil, errors.Wrapf(
-			err2, "cannot decode sharding state",
+			err, "cannot read base of sharding state delta of epoch %s", epoch,
 		)
 	}
-	return ss, nil
+	return delta.Apply(prev)
 }
 
 // WriteShardStateBytes stores sharding state into database.
@@ -42,6 +70,29 @@ func WriteShardStateBytes(db DatabaseWriter, epoch *big.Int, data []byte) error
 	return nil
 }
 
+// WriteShardStateDelta stores sharding state into database as a delta against
+// the sharding state of the previous epoch.
+func WriteShardStateDelta(db DatabaseWriter, epoch *big.Int, delta *shard.StateDelta) error {
+	data, err := rlp.EncodeToBytes(delta)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode sharding state delta")
+	}
+	if err := db.Put(shardStateKey(epoch), append([]byte{shardStateDeltaMarker}, data...)); err != nil {
+		return errors.Wrapf(
+			err, "cannot write sharding state delta",
+		)
+	}
+	utils.Logger().Info().
+		Str("epoch", epoch.String()).
+		Int("size", len(data)+1).Msg("wrote sharding state delta")
+	return nil
+}
+
+// DeleteShardState deletes the sharding state of an epoch.
+func DeleteShardState(db DatabaseDeleter, epoch *big.Int) error {
+	return db.Delete(shardStateKey(epoch))
+}
+
 // ReadCrossLinkShardBlock retrieves the blockHash given shardID and blockNum
 func ReadCrossLinkShardBlock(
 	db DatabaseReader, shardID uint32, blockNum uint64,
@@ -69,6 +120,74 @@ func WriteShardLastCrossLink(db DatabaseWriter, shardID uint32, data []byte) err
 	return db.Put(shardLastCrosslinkKey(shardID), data)
 }
 
+// ReadArchivedCrossLinkBlock retrieves the number of the last crosslink of a
+// shard moved to the crosslink archive, 0 if none was archived.
+func ReadArchivedCrossLinkBlock(db DatabaseReader, shardID uint32) uint64 {
+	data, _ := db.Get(archivedCrosslinkKey(shardID))
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteArchivedCrossLinkBlock stores the number of the last crosslink of a
+// shard moved to the crosslink archive.
+func WriteArchivedCrossLinkBlock(db DatabaseWriter, shardID uint32, blockNum uint64) error {
+	return db.Put(archivedCrosslinkKey(shardID), encodeBlockNumber(blockNum))
+}
+
+// ReadCrossLinkBeaconBlock retrieves the number of the beacon chain block
+// which included the crosslink of the given shardID and blockNum.
+func ReadCrossLinkBeaconBlock(db DatabaseReader, shardID uint32, blockNum uint64) (uint64, bool) {
+	data, _ := db.Get(crosslinkBeaconBlockKey(shardID, blockNum))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteCrossLinkBeaconBlock stores the number of the beacon chain block which
+// included the crosslink of the given shardID and blockNum.
+func WriteCrossLinkBeaconBlock(db DatabaseWriter, shardID uint32, blockNum, beaconNum uint64) error {
+	return db.Put(crosslinkBeaconBlockKey(shardID, blockNum), encodeBlockNumber(beaconNum))
+}
+
+// DeleteCrossLinkBeaconBlock deletes the beacon chain block number of the
+// crosslink of the given shardID and blockNum.
+func DeleteCrossLinkBeaconBlock(db DatabaseDeleter, shardID uint32, blockNum uint64) error {
+	return db.Delete(crosslinkBeaconBlockKey(shardID, blockNum))
+}
+
+// ReadCommitteeCheckpoint retrieves the committee checkpoint of an epoch.
+func ReadCommitteeCheckpoint(db DatabaseReader, epoch uint64) ([]byte, error) {
+	return db.Get(committeeCheckpointKey(epoch))
+}
+
+// WriteCommitteeCheckpoint stores the committee checkpoint of an epoch.
+func WriteCommitteeCheckpoint(db DatabaseWriter, epoch uint64, data []byte) error {
+	return db.Put(committeeCheckpointKey(epoch), data)
+}
+
+// DeleteCommitteeCheckpoint deletes the committee checkpoint of an epoch.
+func DeleteCommitteeCheckpoint(db DatabaseDeleter, epoch uint64) error {
+	return db.Delete(committeeCheckpointKey(epoch))
+}
+
+// ReadElectionAudit retrieves the audit log of the election of an epoch.
+func ReadElectionAudit(db DatabaseReader, epoch uint64) ([]byte, error) {
+	return db.Get(electionAuditKey(epoch))
+}
+
+// WriteElectionAudit stores the audit log of the election of an epoch.
+func WriteElectionAudit(db DatabaseWriter, epoch uint64, data []byte) error {
+	return db.Put(electionAuditKey(epoch), data)
+}
+
+// DeleteElectionAudit deletes the audit log of the election of an epoch.
+func DeleteElectionAudit(db DatabaseDeleter, epoch uint64) error {
+	return db.Delete(electionAuditKey(epoch))
+}
+
 // ReadPendingCrossLinks retrieves last pending crosslinks.
 func ReadPendingCrossLinks(db DatabaseReader) ([]byte, error) {
 	return db.Get(pendingCrosslinkKey)
@@ -111,6 +230,11 @@ func WriteCXReceipts(db DatabaseWriter, shardID uint32, number uint64, hash comm
 	return err
 }
 
+// DeleteCXReceipts deletes the cross shard receipts of a block to the destination shardID
+func DeleteCXReceipts(db DatabaseDeleter, shardID uint32, number uint64, hash common.Hash) error {
+	return db.Delete(cxReceiptKey(shardID, number, hash))
+}
+
 // ReadCXReceiptsProofSpent check whether a CXReceiptsProof is unspent
 func ReadCXReceiptsProofSpent(db DatabaseReader, shardID uint32, number uint64) (byte, error) {
 	data, err := db.Get(cxReceiptSpentKey(shardID, number))
@@ -282,6 +406,33 @@ func WriteBlockRewardAccumulator(db DatabaseWriter, newAccum *big.Int, number ui
 	return db.Put(blockRewardAccumKey(number), newAccum.Bytes())
 }
 
+// DeleteBlockRewardAccumulator ..
+func DeleteBlockRewardAccumulator(db DatabaseDeleter, number uint64) error {
+	return db.Delete(blockRewardAccumKey(number))
+}
+
+// ReadBurnedFeesAccumulator retrieves the transaction fees burned by the
+// chain up to the block
+func ReadBurnedFeesAccumulator(db DatabaseReader, number uint64) (*big.Int, error) {
+	data, err := db.Get(burnedFeesKey(number))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// WriteBurnedFeesAccumulator stores the transaction fees burned by the chain
+// up to the block
+func WriteBurnedFeesAccumulator(db DatabaseWriter, burned *big.Int, number uint64) error {
+	return db.Put(burnedFeesKey(number), burned.Bytes())
+}
+
+// DeleteBurnedFeesAccumulator removes the transaction fees burned by the
+// chain up to the block
+func DeleteBurnedFeesAccumulator(db DatabaseDeleter, number uint64) error {
+	return db.Delete(burnedFeesKey(number))
+}
+
 // ReadBlockCommitSig retrieves the signature signed on a block.
 func ReadBlockCommitSig(db DatabaseReader, blockNum uint64) ([]byte, error) {
 	var data []byte
@@ -302,6 +453,11 @@ func WriteBlockCommitSig(db DatabaseWriter, blockNum uint64, sigAndBitmap []byte
 	return db.Put(blockCommitSigKey(blockNum), sigAndBitmap)
 }
 
+// DeleteBlockCommitSig deletes the signature signed on a block.
+func DeleteBlockCommitSig(db DatabaseDeleter, blockNum uint64) error {
+	return db.Delete(blockCommitSigKey(blockNum))
+}
+
 //// Resharding ////
 
 // ReadEpochBlockNumber retrieves the epoch block number for the given epoch,
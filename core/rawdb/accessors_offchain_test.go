@@ -0,0 +1,87 @@
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+func testShardStateSlot(i byte, stake *numeric.Dec) shard.Slot {
+	slot := shard.Slot{EcdsaAddress: common.BytesToAddress([]byte{i}), EffectiveStake: stake}
+	slot.BLSPublicKey[0] = i
+	return slot
+}
+
+func TestShardStateDelta(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	stake, newStake := numeric.NewDec(100), numeric.NewDec(200)
+	states := []*shard.State{
+		{Epoch: big.NewInt(1), Shards: []shard.Committee{
+			{ShardID: 0, Slots: shard.SlotList{testShardStateSlot(1, nil), testShardStateSlot(2, &stake)}},
+			{ShardID: 1, Slots: shard.SlotList{testShardStateSlot(3, nil)}},
+		}},
+		// a new slot, a slot with a new stake, and the slots reordered
+		{Epoch: big.NewInt(2), Shards: []shard.Committee{
+			{ShardID: 0, Slots: shard.SlotList{
+				testShardStateSlot(4, &stake), testShardStateSlot(2, &newStake), testShardStateSlot(1, nil),
+			}},
+			{ShardID: 1, Slots: shard.SlotList{testShardStateSlot(3, nil)}},
+		}},
+		{Epoch: big.NewInt(3), Shards: []shard.Committee{
+			{ShardID: 0, Slots: shard.SlotList{testShardStateSlot(1, nil)}},
+			{ShardID: 1, Slots: shard.SlotList{testShardStateSlot(3, nil), testShardStateSlot(4, &stake)}},
+		}},
+	}
+	data, err := rlp.EncodeToBytes(states[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteShardStateBytes(db, states[0].Epoch, data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(states); i++ {
+		delta := shard.NewStateDelta(states[i-1], states[i])
+		if err := WriteShardStateDelta(db, states[i].Epoch, delta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, state := range states {
+		got, err := ReadShardState(db, state.Epoch)
+		if err != nil {
+			t.Fatalf("epoch %v: %v", state.Epoch, err)
+		}
+		gotData, _ := rlp.EncodeToBytes(got)
+		expectData, _ := rlp.EncodeToBytes(state)
+		if !bytes.Equal(gotData, expectData) {
+			t.Errorf("epoch %v: got %v, expect %v", state.Epoch, got, state)
+		}
+	}
+
+	if err := WriteShardStateDelta(db, big.NewInt(5), shard.NewStateDelta(states[2], states[2])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadShardState(db, big.NewInt(5)); err == nil {
+		t.Error("expect the delta without the state of the previous epoch unreadable")
+	}
+
+	// the state of the previous epoch rewritten on a fork
+	fork := states[1].DeepCopy()
+	fork.Shards[0].Slots = fork.Shards[0].Slots[1:]
+	data, err = rlp.EncodeToBytes(fork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteShardStateBytes(db, fork.Epoch, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadShardState(db, states[2].Epoch); errors.Cause(err) != shard.ErrStateDeltaBase {
+		t.Errorf("got error %v, expect %v", err, shard.ErrStateDeltaBase)
+	}
+}
@@ -0,0 +1,172 @@
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DatabaseIterator iterates over the keys and values of a backing data store.
+type DatabaseIterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Release()
+	Error() error
+}
+
+// Categories of the items of the chain database
+const (
+	CategoryHeaders     = "headers"
+	CategoryBodies      = "bodies"
+	CategoryForkBlocks  = "fork blocks"
+	CategoryReceipts    = "receipts"
+	CategoryTrieNodes   = "trie nodes and code"
+	CategoryPreimages   = "preimages"
+	CategorySnapshots   = "validator snapshots"
+	CategoryStaking     = "staking data"
+	CategoryShardStates = "shard states"
+	CategoryCommitSigs  = "commit signatures"
+	CategoryCrossLinks  = "crosslinks"
+	CategoryCXReceipts  = "cross shard receipts"
+	CategoryIndexes     = "indexes"
+	CategoryMetadata    = "metadata"
+	CategoryUnaccounted = "unaccounted"
+	CategoryTotal       = "total"
+)
+
+// Lengths of the keys of the schema which are told apart by their length
+const (
+	numberHashKeyLength = 1 + 8 + common.HashLength
+	headerHashKeyLength = 1 + 8 + 1
+	headerTDKeyLength   = numberHashKeyLength + 1
+	hashLookupKeyLength = 1 + common.HashLength
+	bloomBitsKeyLength  = 1 + 2 + 8 + common.HashLength
+	cxLookupKeyLength   = 2 + common.HashLength
+)
+
+// databaseCategories are the categories in the order of the reports
+var databaseCategories = []string{
+	CategoryHeaders, CategoryBodies, CategoryForkBlocks, CategoryReceipts, CategoryTrieNodes,
+	CategoryPreimages, CategorySnapshots, CategoryStaking, CategoryShardStates,
+	CategoryCommitSigs, CategoryCrossLinks, CategoryCXReceipts, CategoryIndexes,
+	CategoryMetadata, CategoryUnaccounted,
+}
+
+// prefixCategories map the multi-byte prefixes of the schema to their
+// category, the longer prefixes first so they match before their own prefixes
+var prefixCategories = []struct {
+	prefix   []byte
+	category string
+}{
+	{epochBlockNumberPrefix, CategoryIndexes},
+	{epochVrfBlockNumbersPrefix, CategoryIndexes},
+	{epochVdfBlockNumberPrefix, CategoryIndexes},
+	{validatorSnapshotPrefix, CategorySnapshots},
+	{configPrefix, CategoryMetadata},
+	{electionAuditPrefix, CategoryStaking},
+	{forkBlockPrefix, CategoryForkBlocks},
+	{validatorStatsPrefix, CategoryStaking},
+	{validatorListKey, CategoryStaking},
+	{cxReceiptSpentPrefix, CategoryCXReceipts},
+	{preimagePrefix, CategoryPreimages},
+	{blockCommitSigPrefix, CategoryCommitSigs},
+	{archivedCrosslinkPrefix, CategoryCrossLinks},
+	{cxReceiptPrefix, CategoryCXReceipts},
+	{pendingCrosslinkKey, CategoryCrossLinks},
+	{pendingSlashingKey, CategoryStaking},
+	{crosslinkBeaconBlockPrefix, CategoryCrossLinks},
+	{lastCommitsKey, CategoryCommitSigs},
+	{currentRewardGivenOutPrefix, CategoryStaking},
+	{burnedFeesPrefix, CategoryStaking},
+	{delegatorValidatorListPrefix, CategoryStaking},
+	{committeeCheckpointPrefix, CategoryShardStates},
+	{shardStatePrefix, CategoryShardStates},
+	{crosslinkPrefix, CategoryCrossLinks},
+	{BloomBitsIndexPrefix, CategoryIndexes},
+}
+
+// metadataKeys are the single keys of the chain metadata
+var metadataKeys = [][]byte{
+	databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey,
+	snapSyncPivotKey, snapSyncTrieKey, prunedBlockKey, beaconLightEpochKey,
+	quarantinedRangesKey, schemaVersionKey, forkBlocksKey, reorgEventsKey,
+}
+
+// DatabaseStat is the number of items of a category of the chain database,
+// and the size of their keys and values in bytes.
+type DatabaseStat struct {
+	Category string
+	Count    uint64
+	Size     uint64
+}
+
+// keyCategory returns the category of the item of the key
+func keyCategory(key []byte) string {
+	for _, metadataKey := range metadataKeys {
+		if bytes.Equal(key, metadataKey) {
+			return CategoryMetadata
+		}
+	}
+	// the trie nodes and the contract code are keyed by their hash, no
+	// prefixed key of the schema is as long
+	if len(key) == common.HashLength {
+		return CategoryTrieNodes
+	}
+	// cx + hash is the cross shard receipt lookup, which is shorter than the
+	// cxReceipt keys
+	if len(key) == cxLookupKeyLength && bytes.HasPrefix(key, cxLookupPrefix) {
+		return CategoryIndexes
+	}
+	for _, pc := range prefixCategories {
+		if bytes.HasPrefix(key, pc.prefix) {
+			return pc.category
+		}
+	}
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) &&
+		(len(key) == numberHashKeyLength || len(key) == headerHashKeyLength ||
+			len(key) == headerTDKeyLength):
+		return CategoryHeaders
+	case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == hashLookupKeyLength:
+		return CategoryHeaders
+	case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == numberHashKeyLength:
+		return CategoryBodies
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == numberHashKeyLength:
+		return CategoryReceipts
+	case bytes.HasPrefix(key, txLookupPrefix) && len(key) == hashLookupKeyLength:
+		return CategoryIndexes
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == bloomBitsKeyLength:
+		return CategoryIndexes
+	}
+	return CategoryUnaccounted
+}
+
+// InspectDatabase walks the items of the iterator, and returns the stats of
+// each category of the chain database followed by their total.
+func InspectDatabase(it DatabaseIterator) ([]DatabaseStat, error) {
+	defer it.Release()
+
+	stats := make(map[string]*DatabaseStat, len(databaseCategories))
+	for _, category := range databaseCategories {
+		stats[category] = &DatabaseStat{Category: category}
+	}
+	total := DatabaseStat{Category: CategoryTotal}
+	for it.Next() {
+		key := it.Key()
+		size := uint64(len(key) + len(it.Value()))
+		stat := stats[keyCategory(key)]
+		stat.Count++
+		stat.Size += size
+		total.Count++
+		total.Size += size
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	result := make([]DatabaseStat, 0, len(databaseCategories)+1)
+	for _, category := range databaseCategories {
+		result = append(result, *stats[category])
+	}
+	return append(result, total), nil
+}
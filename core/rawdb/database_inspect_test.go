@@ -0,0 +1,91 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// memIterator iterates over the items of a memory database
+type memIterator struct {
+	db   *ethdb.MemDatabase
+	keys [][]byte
+	key  []byte
+}
+
+func (it *memIterator) Next() bool {
+	if len(it.keys) == 0 {
+		return false
+	}
+	it.key, it.keys = it.keys[0], it.keys[1:]
+	return true
+}
+
+func (it *memIterator) Key() []byte {
+	return it.key
+}
+
+func (it *memIterator) Value() []byte {
+	value, _ := it.db.Get(it.key)
+	return value
+}
+
+func (it *memIterator) Release() {}
+
+func (it *memIterator) Error() error {
+	return nil
+}
+
+func TestInspectDatabase(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	hash := common.HexToHash("0x68656c6c6f")
+	items := map[string][][]byte{
+		CategoryHeaders: {
+			headerKey(1, hash), headerTDKey(1, hash), headerHashKey(1), headerNumberKey(hash),
+		},
+		CategoryBodies:      {blockBodyKey(1, hash)},
+		CategoryForkBlocks:  {forkBlockKey(1, hash)},
+		CategoryReceipts:    {blockReceiptsKey(1, hash)},
+		CategoryTrieNodes:   {hash.Bytes()},
+		CategoryPreimages:   {preimageKey(hash)},
+		CategorySnapshots:   {validatorSnapshotKey(common.Address{}, big.NewInt(1))},
+		CategoryStaking:     {validatorStatsKey(common.Address{}), validatorListKey, blockRewardAccumKey(1)},
+		CategoryShardStates: {shardStateKey(big.NewInt(1)), committeeCheckpointKey(1)},
+		CategoryCommitSigs:  {blockCommitSigKey(1), lastCommitsKey},
+		CategoryCrossLinks:  {crosslinkKey(0, 1), crosslinkBeaconBlockKey(0, 1), archivedCrosslinkKey(0)},
+		CategoryCXReceipts:  {cxReceiptKey(0, 1, hash), cxReceiptSpentKey(0, 1)},
+		CategoryIndexes: {
+			txLookupKey(hash), cxLookupKey(hash), bloomBitsKey(1, 1, hash), epochBlockNumberKey(big.NewInt(1)),
+		},
+		CategoryMetadata:    {headBlockKey, configKey(hash)},
+		CategoryUnaccounted: {[]byte("unknown")},
+	}
+	for _, keys := range items {
+		for _, key := range keys {
+			db.Put(key, []byte{1})
+		}
+	}
+	stats, err := InspectDatabase(&memIterator{db: db, keys: db.Keys()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != len(items)+1 {
+		t.Fatalf("got %d stats, expect %d", len(stats), len(items)+1)
+	}
+	total := uint64(0)
+	for _, stat := range stats[:len(items)] {
+		keys, size := items[stat.Category], uint64(0)
+		for _, key := range keys {
+			size += uint64(len(key)) + 1
+		}
+		if stat.Count != uint64(len(keys)) || stat.Size != size {
+			t.Errorf("%s: got %d items of %d bytes, expect %d of %d", stat.Category, stat.Count, stat.Size, len(keys), size)
+		}
+		total += stat.Size
+	}
+	if stat := stats[len(items)]; stat.Category != CategoryTotal || stat.Count != uint64(db.Len()) || stat.Size != total {
+		t.Errorf("got total %+v, expect %d items of %d bytes", stat, db.Len(), total)
+	}
+}
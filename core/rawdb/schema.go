@@ -35,6 +35,22 @@ var (
 	headBlockKey = []byte("LastBlock")
 	// headFastBlockKey tracks the latest known incomplete block's hash duirng fast sync.
 	headFastBlockKey = []byte("LastFast")
+	// snapSyncPivotKey tracks the pivot of an unfinished snap sync.
+	snapSyncPivotKey = []byte("SnapSyncPivot")
+	// snapSyncTrieKey tracks the state trie being downloaded by an unfinished snap sync.
+	snapSyncTrieKey = []byte("SnapSyncTrie")
+	// prunedBlockKey tracks the first block whose body and receipts were not pruned.
+	prunedBlockKey = []byte("PrunedBlock")
+	// beaconLightEpochKey tracks the last epoch whose committees a beacon light client verified.
+	beaconLightEpochKey = []byte("BeaconLightEpoch")
+	// quarantinedRangesKey tracks the block ranges found corrupted and not repaired.
+	quarantinedRangesKey = []byte("QuarantinedRanges")
+	// schemaVersionKey tracks the version of the storage format of the chain database.
+	schemaVersionKey = []byte("SchemaVersion")
+	// forkBlocksKey tracks the blocks retained off the canonical chain.
+	forkBlocksKey = []byte("ForkBlocks")
+	// reorgEventsKey tracks the recent reorgs of the canonical chain.
+	reorgEventsKey = []byte("ReorgEvents")
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix                 = []byte("h")  // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix               = []byte("t")  // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -50,6 +66,11 @@ var (
 	blockCommitSigPrefix         = []byte("block-sig-")
 	pendingCrosslinkKey          = []byte("pendingCL")        // prefix for shard last pending crosslink
 	pendingSlashingKey           = []byte("pendingSC")        // prefix for shard last pending slashing record
+	archivedCrosslinkPrefix      = []byte("archivedCL")       // prefix for shard last archived crosslink number
+	crosslinkBeaconBlockPrefix   = []byte("beaconCL")         // prefix for beacon block number of crosslink
+	committeeCheckpointPrefix    = []byte("ccp")              // prefix for committee checkpoint
+	electionAuditPrefix          = []byte("election-audit-")  // prefix for election audit
+	forkBlockPrefix              = []byte("fork-block-")      // forkBlockPrefix + num (uint64 big endian) + hash -> fork block
 	preimagePrefix               = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix                 = []byte("ethereum-config-") // config prefix for the db
 	crosslinkPrefix              = []byte("cl")               // prefix for crosslink
@@ -72,6 +93,7 @@ var (
 	preimageCounter             = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter          = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 	currentRewardGivenOutPrefix = []byte("blk-rwd-")
+	burnedFeesPrefix            = []byte("blk-burn-")
 )
 
 // TxLookupEntry is a positional metadata to help looking up the data content of
@@ -153,6 +175,19 @@ func shardStateKey(epoch *big.Int) []byte {
 	return append(shardStatePrefix, epoch.Bytes()...)
 }
 
+func committeeCheckpointKey(epoch uint64) []byte {
+	return append(committeeCheckpointPrefix, encodeBlockNumber(epoch)...)
+}
+
+// forkBlockKey = forkBlockPrefix + num (uint64 big endian) + hash
+func forkBlockKey(number uint64, hash common.Hash) []byte {
+	return append(append(forkBlockPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+func electionAuditKey(epoch uint64) []byte {
+	return append(electionAuditPrefix, encodeBlockNumber(epoch)...)
+}
+
 func epochBlockNumberKey(epoch *big.Int) []byte {
 	return append(epochBlockNumberPrefix, epoch.Bytes()...)
 }
@@ -172,6 +207,12 @@ func shardLastCrosslinkKey(shardID uint32) []byte {
 	return key
 }
 
+func archivedCrosslinkKey(shardID uint32) []byte {
+	sbKey := make([]byte, 4)
+	binary.BigEndian.PutUint32(sbKey, shardID)
+	return append(archivedCrosslinkPrefix, sbKey...)
+}
+
 func crosslinkKey(shardID uint32, blockNum uint64) []byte {
 	prefix := crosslinkPrefix
 	sbKey := make([]byte, 12)
@@ -181,6 +222,13 @@ func crosslinkKey(shardID uint32, blockNum uint64) []byte {
 	return key
 }
 
+func crosslinkBeaconBlockKey(shardID uint32, blockNum uint64) []byte {
+	sbKey := make([]byte, 12)
+	binary.BigEndian.PutUint32(sbKey, shardID)
+	binary.BigEndian.PutUint64(sbKey[4:], blockNum)
+	return append(crosslinkBeaconBlockPrefix, sbKey...)
+}
+
 func delegatorValidatorListKey(delegator common.Address) []byte {
 	return append(delegatorValidatorListPrefix, delegator.Bytes()...)
 }
@@ -219,6 +267,10 @@ func blockRewardAccumKey(number uint64) []byte {
 	return append(currentRewardGivenOutPrefix, encodeBlockNumber(number)...)
 }
 
+func burnedFeesKey(number uint64) []byte {
+	return append(burnedFeesPrefix, encodeBlockNumber(number)...)
+}
+
 func blockCommitSigKey(number uint64) []byte {
 	return append(blockCommitSigPrefix, encodeBlockNumber(number)...)
 }
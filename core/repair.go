@@ -0,0 +1,269 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+// Kinds of the corruptions of the chain database
+const (
+	// CorruptHeader is a canonical header missing or not decodable
+	CorruptHeader = "header"
+	// CorruptBody is a body missing, not decodable or not matching its header
+	CorruptBody = "body"
+	// CorruptReceipts is receipts missing or not matching their header
+	CorruptReceipts = "receipts"
+	// CorruptState is a trie node of the state of a block missing
+	CorruptState = "state"
+	// CorruptIndex is a transaction lookup entry missing
+	CorruptIndex = "index"
+)
+
+// Corruption is a corrupted item of a block of the chain database
+type Corruption struct {
+	Number uint64
+	Kind   string
+	Reason string
+}
+
+// RepairReport is the outcome of a repair of the chain database
+type RepairReport struct {
+	Head        uint64 // head block before the repair
+	Checked     uint64 // blocks checked below the head, the head included
+	Corruptions []Corruption
+	// IndexesRebuilt is the number of blocks whose lookup entries were
+	// rebuilt
+	IndexesRebuilt int
+	// RolledBack is whether the chain was rewound to the block of RolledBackTo,
+	// below the corrupted blocks, for them to be synced again from the peers
+	RolledBack   bool
+	RolledBackTo uint64
+	// Quarantined is the range which could not be repaired
+	Quarantined *rawdb.QuarantinedRange
+}
+
+// CheckIntegrity checks the depth last canonical blocks of the chain: their
+// headers, bodies and receipts are decoded and matched to the roots of their
+// headers, their transaction lookup entries are looked up, and the state of
+// the head block is opened. The bodies and receipts of the pruned blocks are
+// not checked.
+func (bc *BlockChain) CheckIntegrity(depth uint64) []Corruption {
+	head := bc.CurrentBlock()
+	corruptions := []Corruption{}
+	if _, err := state.New(head.Root(), bc.stateCache); err != nil {
+		corruptions = append(corruptions, Corruption{head.NumberU64(), CorruptState, err.Error()})
+	}
+	pruned := rawdb.ReadPrunedBlock(bc.db)
+	for n := head.NumberU64(); n > 0 && n+depth > head.NumberU64(); n-- {
+		corruptions = append(corruptions, bc.checkBlock(n, n >= pruned)...)
+	}
+	return corruptions
+}
+
+// checkBlock returns the corruptions of the canonical block of the number,
+// with its body and receipts if they are kept
+func (bc *BlockChain) checkBlock(number uint64, hasBody bool) []Corruption {
+	hash := rawdb.ReadCanonicalHash(bc.db, number)
+	if hash == (common.Hash{}) {
+		return []Corruption{{number, CorruptHeader, "canonical hash missing"}}
+	}
+	header := rawdb.ReadHeader(bc.db, hash, number)
+	if header == nil || header.Hash() != hash {
+		return []Corruption{{number, CorruptHeader, "header missing or not decodable"}}
+	}
+	if !hasBody {
+		return nil
+	}
+	block := rawdb.ReadBlock(bc.db, hash, number)
+	if block == nil {
+		return []Corruption{{number, CorruptBody, "body missing or not decodable"}}
+	}
+	if root := types.DeriveSha(block.Transactions(), block.StakingTransactions()); root != header.TxHash() {
+		return []Corruption{{number, CorruptBody, fmt.Sprintf(
+			"transaction root %x, header has %x", root, header.TxHash(),
+		)}}
+	}
+	corruptions := []Corruption{}
+	receipts := rawdb.ReadReceipts(bc.db, hash, number)
+	if root := types.DeriveSha(receipts); root != header.ReceiptHash() {
+		corruptions = append(corruptions, Corruption{number, CorruptReceipts, fmt.Sprintf(
+			"receipt root %x, header has %x", root, header.ReceiptHash(),
+		)})
+	}
+	for _, tx := range block.Transactions() {
+		if blockHash, _, _ := rawdb.ReadTxLookupEntry(bc.db, tx.Hash()); blockHash != hash {
+			corruptions = append(corruptions, Corruption{number, CorruptIndex, "transaction lookup missing"})
+			return corruptions
+		}
+	}
+	for _, tx := range block.StakingTransactions() {
+		if blockHash, _, _ := rawdb.ReadTxLookupEntry(bc.db, tx.Hash()); blockHash != hash {
+			corruptions = append(corruptions, Corruption{number, CorruptIndex, "staking transaction lookup missing"})
+			return corruptions
+		}
+	}
+	return corruptions
+}
+
+// Repair checks the integrity of the depth last canonical blocks, and repairs
+// the corruptions found in the scope of the corrupted blocks. The missing
+// lookup entries are rebuilt from the blocks. The chain is rewound below the
+// lowest block whose data is corrupted, to the highest block with its state,
+// for the blocks above to be synced again from the peers. If no such block is
+// found within depth blocks, or the rewind fails, the corrupted range is
+// quarantined rather than the whole database resynced.
+func (bc *BlockChain) Repair(depth uint64) (*RepairReport, error) {
+	head := bc.CurrentBlock().NumberU64()
+	report := &RepairReport{Head: head, Checked: depth}
+	if depth > head {
+		report.Checked = head + 1
+	}
+	report.Corruptions = bc.CheckIntegrity(depth)
+
+	lowest, corrupted := head, false
+	for _, c := range report.Corruptions {
+		if c.Kind == CorruptIndex {
+			block := bc.GetBlockByNumber(c.Number)
+			if block == nil {
+				continue
+			}
+			rawdb.WriteTxLookupEntries(bc.db, block)
+			rawdb.WriteCxLookupEntries(bc.db, block)
+			report.IndexesRebuilt++
+			continue
+		}
+		if !corrupted || c.Number < lowest {
+			lowest, corrupted = c.Number, true
+		}
+	}
+	if !corrupted {
+		return report, nil
+	}
+
+	reason := "no block with its state below the corrupted blocks"
+	for n := lowest; n > 0 && n+depth > lowest; n-- {
+		target := n - 1
+		block := bc.GetBlockByNumber(target)
+		if block == nil {
+			continue
+		}
+		if _, err := state.New(block.Root(), bc.stateCache); err != nil {
+			continue
+		}
+		err := bc.RollbackTo(target)
+		if err == nil {
+			report.RolledBack, report.RolledBackTo = true, target
+			return report, nil
+		}
+		reason = fmt.Sprintf("cannot rewind to block %d: %s", target, err)
+		break
+	}
+
+	kind := ""
+	for _, c := range report.Corruptions {
+		if c.Number == lowest && c.Kind != CorruptIndex {
+			kind = c.Kind
+			break
+		}
+	}
+	quarantined := rawdb.QuarantinedRange{
+		From: lowest, To: head, Kind: kind, Reason: reason, Time: uint64(time.Now().Unix()),
+	}
+	ranges := append(rawdb.ReadQuarantinedRanges(bc.db), quarantined)
+	if err := rawdb.WriteQuarantinedRanges(bc.db, ranges); err != nil {
+		return report, err
+	}
+	report.Quarantined = &quarantined
+	return report, nil
+}
+
+// IsQuarantined returns whether the block of the number is in a quarantined
+// range.
+func (bc *BlockChain) IsQuarantined(number uint64) bool {
+	for _, r := range rawdb.ReadQuarantinedRanges(bc.db) {
+		if number >= r.From && number <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableRepair repairs the chain database now, checking the depth last
+// blocks, and again whenever the processing of a block finds the state of its
+// parent missing.
+func (bc *BlockChain) EnableRepair(depth uint64) {
+	bc.runRepair(depth)
+	bc.repairDepth = depth
+	bc.wg.Add(1)
+	go bc.repairLoop()
+}
+
+// reportCorruption requests a repair of the chain database, if enabled
+func (bc *BlockChain) reportCorruption(c Corruption) {
+	if bc.repairDepth == 0 {
+		return
+	}
+	utils.Logger().Error().
+		Uint64("number", c.Number).
+		Str("kind", c.Kind).
+		Str("reason", c.Reason).
+		Msg("[Repair] chain database corruption detected")
+	select {
+	case bc.repairCh <- struct{}{}:
+	default:
+	}
+}
+
+// repairLoop repairs the chain database on the corruptions reported, until
+// the chain is stopped
+func (bc *BlockChain) repairLoop() {
+	defer bc.wg.Done()
+	for {
+		select {
+		case <-bc.repairCh:
+			bc.chainmu.Lock()
+			bc.runRepair(bc.repairDepth)
+			bc.chainmu.Unlock()
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// runRepair repairs the chain database and logs the outcome
+func (bc *BlockChain) runRepair(depth uint64) {
+	report, err := bc.Repair(depth)
+	if err != nil {
+		utils.Logger().Error().Err(err).Msg("[Repair] cannot repair the chain database")
+		return
+	}
+	if len(report.Corruptions) == 0 {
+		utils.Logger().Info().Uint64("checked", report.Checked).Msg("[Repair] chain database is intact")
+		return
+	}
+	logger := utils.Logger().With().
+		Uint64("head", report.Head).
+		Int("corruptions", len(report.Corruptions)).
+		Int("indexesRebuilt", report.IndexesRebuilt).
+		Logger()
+	switch {
+	case report.Quarantined != nil:
+		logger.Error().
+			Uint64("from", report.Quarantined.From).
+			Uint64("to", report.Quarantined.To).
+			Str("reason", report.Quarantined.Reason).
+			Msg("[Repair] corrupted blocks quarantined, a resync of the range is required")
+	case report.RolledBack:
+		logger.Warn().
+			Uint64("rolledBackTo", report.RolledBackTo).
+			Msg("[Repair] chain rewound below the corrupted blocks, they are synced again from the peers")
+	default:
+		logger.Warn().Msg("[Repair] chain database indexes rebuilt")
+	}
+}
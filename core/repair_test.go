@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/harmony-one/harmony/core/rawdb"
+)
+
+func TestRepair(t *testing.T) {
+	db, bc, blocks := newTestChain(t)
+	defer bc.Stop()
+
+	report, err := bc.Repair(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Corruptions) != 0 {
+		t.Fatalf("got corruptions %v of an intact chain", report.Corruptions)
+	}
+
+	// a missing lookup entry is rebuilt in place
+	txHash := blocks[1].Transactions()[0].Hash()
+	rawdb.DeleteTxLookupEntry(db, txHash)
+	if report, err = bc.Repair(128); err != nil {
+		t.Fatal(err)
+	}
+	if report.IndexesRebuilt != 1 || report.RolledBack || report.Quarantined != nil {
+		t.Fatalf("got report %+v, expect 1 index rebuilt", report)
+	}
+	if tx, _, _, _ := rawdb.ReadTransaction(db, txHash); tx == nil {
+		t.Error("transaction of block 2 is not indexed again")
+	}
+
+	// a missing body rewinds the chain below its block
+	rawdb.DeleteBody(db, blocks[2].Hash(), blocks[2].NumberU64())
+	if report, err = bc.Repair(128); err != nil {
+		t.Fatal(err)
+	}
+	if !report.RolledBack || report.RolledBackTo != 2 {
+		t.Fatalf("got report %+v, expect a rewind to block 2", report)
+	}
+	if n := bc.CurrentBlock().NumberU64(); n != 2 {
+		t.Fatalf("got head block %d, expect 2", n)
+	}
+	if bc.IsQuarantined(3) {
+		t.Error("block 3 is quarantined")
+	}
+}
+
+func TestRepairQuarantine(t *testing.T) {
+	db, bc, blocks := newTestChain(t)
+	defer bc.Stop()
+
+	// with a canonical hash missing the chain cannot be rewound, the range
+	// above the corrupted block is quarantined
+	rawdb.DeleteCanonicalHash(db, blocks[1].NumberU64())
+	report, err := bc.Repair(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.RolledBack || report.Quarantined == nil {
+		t.Fatalf("got report %+v, expect a quarantine", report)
+	}
+	if r := report.Quarantined; r.From != 2 || r.To != 3 || r.Kind != CorruptHeader {
+		t.Errorf("got quarantined range %+v, expect blocks 2 to 3 of a corrupted header", r)
+	}
+	if !bc.IsQuarantined(2) || !bc.IsQuarantined(3) || bc.IsQuarantined(1) {
+		t.Error("expect only blocks 2 to 3 quarantined")
+	}
+	if ranges := rawdb.ReadQuarantinedRanges(db); len(ranges) != 1 {
+		t.Errorf("got %d quarantined ranges stored, expect 1", len(ranges))
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// ApplyReshardingRedistribution moves the balances of the accounts of a shard
+// retired by the resharding at the next epoch to its destination shard, with
+// the returned cross-shard receipts. The accounts moved are the ones the chain
+// config lists for the shard and the resharding, at most
+// params.MaxReshardingAccounts of them, as the accounts of a shard cannot be
+// enumerated from its state. It only moves balances in the last block of the
+// epoch, the block carrying the shard state of the next epoch, and returns no
+// receipt for the other blocks.
+//
+// Only the balances of externally owned accounts move, not their nonces. The
+// contracts and the validators are not moved, their code, storage and
+// validator wrappers have no counterpart on the destination shard; their
+// balances stay on the retired shard.
+func ApplyReshardingRedistribution(
+	config *params.ChainConfig, db *state.DB, header *block.Header,
+) (types.CXReceipts, error) {
+	if len(header.ShardState()) == 0 {
+		return nil, nil
+	}
+	next := new(big.Int).Add(header.Epoch(), common.Big1)
+	r, ok := shard.ReshardingAt(next)
+	if !ok || !config.IsDynamicSharding(next) || !r.Retires(header.ShardID()) {
+		return nil, nil
+	}
+	accounts := config.ReshardingAccountsOf(next, header.ShardID())
+	if len(accounts) > params.MaxReshardingAccounts {
+		return nil, errors.Errorf(
+			"%d accounts to move from retired shard %d, more than the %d allowed",
+			len(accounts), header.ShardID(), params.MaxReshardingAccounts,
+		)
+	}
+
+	toShardID := r.Destination(header.ShardID())
+	receipts := types.CXReceipts{}
+	for _, addr := range accounts {
+		balance := db.GetBalance(addr)
+		if balance.Sign() <= 0 {
+			continue
+		}
+		if db.GetCodeSize(addr) > 0 || db.IsValidator(addr) {
+			utils.Logger().Warn().
+				Str("address", addr.Hex()).
+				Uint32("shardID", header.ShardID()).
+				Msg("[Resharding] contract or validator account not moved to the destination shard")
+			continue
+		}
+		to := addr
+		receipts = append(receipts, &types.CXReceipt{
+			TxHash:    crypto.Keccak256Hash(next.Bytes(), addr.Bytes()),
+			From:      addr,
+			To:        &to,
+			ShardID:   header.ShardID(),
+			ToShardID: toShardID,
+			Amount:    new(big.Int).Set(balance),
+		})
+		db.SubBalance(addr, balance)
+	}
+	return receipts, nil
+}
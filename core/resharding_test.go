@@ -0,0 +1,114 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/state"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/shard"
+)
+
+type mergeInstance struct {
+	shardingconfig.Instance
+	numShards uint32
+}
+
+func (i mergeInstance) NumShards() uint32 {
+	return i.numShards
+}
+
+// mergeSchedule merges 4 shards into 2 at epoch 3
+type mergeSchedule struct {
+	shardingconfig.Schedule
+}
+
+func (s mergeSchedule) InstanceForEpoch(epoch *big.Int) shardingconfig.Instance {
+	if epoch.Cmp(big.NewInt(3)) >= 0 {
+		return mergeInstance{numShards: 2}
+	}
+	return mergeInstance{numShards: 4}
+}
+
+func TestApplyReshardingRedistribution(t *testing.T) {
+	defer func(s shardingconfig.Schedule) { shard.Schedule = s }(shard.Schedule)
+	shard.Schedule = mergeSchedule{}
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	addrs := []common.Address{common.HexToAddress("0x02"), common.HexToAddress("0x01")}
+	db.AddBalance(addrs[0], big.NewInt(20))
+	db.AddBalance(addrs[1], big.NewInt(10))
+	db.CreateAccount(common.HexToAddress("0x03"))
+	contract, validator := common.HexToAddress("0x04"), common.HexToAddress("0x05")
+	db.AddBalance(contract, big.NewInt(30))
+	db.SetCode(contract, []byte{1})
+	db.AddBalance(validator, big.NewInt(40))
+	db.SetValidatorFlag(validator)
+	unlisted := common.HexToAddress("0x06")
+	db.AddBalance(unlisted, big.NewInt(50))
+
+	config := *params.TestChainConfig
+	config.ReshardingAccounts = []params.ReshardingAccounts{{
+		Epoch:   big.NewInt(3),
+		ShardID: 3,
+		Accounts: []common.Address{
+			addrs[1], addrs[0], common.HexToAddress("0x03"), contract, validator,
+		},
+	}}
+	apply := func(shardID uint32, epoch int64, shardState []byte) {
+		header := blockfactory.ForTest.NewHeader(big.NewInt(epoch)).With().
+			ShardID(shardID).ShardState(shardState).Header()
+		receipts, err := ApplyReshardingRedistribution(&config, db, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shardID == 3 && epoch == 2 && len(shardState) > 0 {
+			if len(receipts) != 2 {
+				t.Fatalf("got %d receipts, expect the 2 funded accounts", len(receipts))
+			}
+			if r := receipts[0]; r.From != addrs[1] || *r.To != addrs[1] || r.ToShardID != 1 || r.Amount.Int64() != 10 {
+				t.Errorf("got receipt %+v, expect 10 moved from 0x01 to shard 1", r)
+			}
+			if r := receipts[1]; r.From != addrs[0] || r.Amount.Int64() != 20 {
+				t.Errorf("got receipt %+v, expect 20 moved from 0x02", r)
+			}
+		} else if len(receipts) != 0 {
+			t.Errorf("shard %d epoch %d: got %d receipts, expect none", shardID, epoch, len(receipts))
+		}
+	}
+	apply(3, 1, []byte{1}) // no resharding at epoch 2
+	apply(1, 2, []byte{1}) // shard 1 is not retired
+	apply(3, 2, nil)       // not the last block of the epoch
+	apply(3, 2, []byte{1})
+	for _, addr := range addrs {
+		if balance := db.GetBalance(addr); balance.Sign() != 0 {
+			t.Errorf("got balance %v of %s on the retired shard, expect 0", balance, addr.Hex())
+		}
+	}
+	// the contracts, the validators and the accounts not listed stay
+	for _, addr := range []common.Address{contract, validator, unlisted} {
+		if balance := db.GetBalance(addr); balance.Sign() == 0 {
+			t.Errorf("balance of %s moved off the retired shard", addr.Hex())
+		}
+	}
+}
+
+func TestApplyReshardingRedistributionTooManyAccounts(t *testing.T) {
+	defer func(s shardingconfig.Schedule) { shard.Schedule = s }(shard.Schedule)
+	shard.Schedule = mergeSchedule{}
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	config := *params.TestChainConfig
+	config.ReshardingAccounts = []params.ReshardingAccounts{{
+		Epoch:    big.NewInt(3),
+		ShardID:  3,
+		Accounts: make([]common.Address, params.MaxReshardingAccounts+1),
+	}}
+	header := blockfactory.ForTest.NewHeader(big.NewInt(2)).With().
+		ShardID(3).ShardState([]byte{1}).Header()
+	if _, err := ApplyReshardingRedistribution(&config, db, header); err == nil {
+		t.Error("moved more accounts than allowed")
+	}
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/network"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// rollbackIndexes are the off-chain indexes touched by the rolled back blocks,
+// which are restored once their blocks are deleted
+type rollbackIndexes struct {
+	validators map[common.Address]struct{}
+	delegators map[common.Address]struct{}
+	crossLinks []types.CrossLink
+}
+
+// RollbackTo rewinds the canonical chain to the block number, so that a
+// corrupted chain tip can be recovered without a resync. The blocks above the
+// number are deleted with their receipts and their off-chain data, such as the
+// transaction lookups, the cross shard receipts, the shard states, the
+// validator snapshots and the crosslinks, and the new head is verified. The
+// state of the block must be in the database. The validator stats, which are
+// accumulated rather than kept per block, are not rewound.
+func (bc *BlockChain) RollbackTo(number uint64) error {
+	head := bc.CurrentBlock().NumberU64()
+	if number >= head {
+		return errors.Errorf("block %d is not below the head block %d", number, head)
+	}
+	target := bc.GetBlockByNumber(number)
+	if target == nil {
+		return errors.Errorf("block %d is missing", number)
+	}
+	if _, err := state.New(target.Root(), bc.stateCache); err != nil {
+		return errors.Wrapf(err, "state of block %d is missing", number)
+	}
+	validators, _ := bc.ReadValidatorList()
+
+	indexes := &rollbackIndexes{
+		validators: map[common.Address]struct{}{},
+		delegators: map[common.Address]struct{}{},
+	}
+	batch := bc.db.NewBatch()
+	for n := head; n > number; n-- {
+		header := bc.GetHeaderByNumber(n)
+		if header == nil {
+			return errors.Errorf("header of block %d is missing", n)
+		}
+		if err := bc.deleteOffChainData(batch, header, validators, indexes); err != nil {
+			return errors.Wrapf(err, "cannot delete the off-chain data of block %d", n)
+		}
+	}
+	for delegator := range indexes.delegators {
+		delegations, err := rawdb.ReadDelegationsByDelegator(bc.db, delegator)
+		if err != nil {
+			continue
+		}
+		kept := staking.DelegationIndexes{}
+		for _, delegation := range delegations {
+			if delegation.BlockNum.Uint64() <= number {
+				kept = append(kept, delegation)
+			}
+		}
+		if err := bc.writeDelegationsByDelegator(batch, delegator, kept); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return errors.Wrap(err, "cannot delete the off-chain data")
+	}
+	if err := bc.removeInValidatorList(indexes.validators); err != nil {
+		return err
+	}
+	if err := bc.rollbackCrossLinks(indexes.crossLinks); err != nil {
+		return err
+	}
+	bc.validatorSnapshotCache.Purge()
+	network.DelegatorShares.Purge()
+	bc.validatorStatsCache.Purge()
+	bc.blockAccumulatorCache.Purge()
+	bc.epochCache.Purge()
+
+	if err := bc.SetHead(number); err != nil {
+		return err
+	}
+	return bc.verifyHead(number)
+}
+
+// deleteOffChainData deletes the receipts and the off-chain data of the block
+// of the header, and collects the indexes it touched. The off-chain data of
+// the body of the block is kept if the body is missing.
+func (bc *BlockChain) deleteOffChainData(
+	batch rawdb.DatabaseDeleter, header *block.Header,
+	validators []common.Address, indexes *rollbackIndexes,
+) error {
+	hash, number := header.Hash(), header.Number().Uint64()
+	isBeaconChain := header.ShardID() == shard.BeaconChainShardID
+	nextEpoch := new(big.Int).Add(header.Epoch(), common.Big1)
+
+	rawdb.DeleteReceipts(batch, hash, number)
+	if err := rawdb.DeleteBlockCommitSig(batch, number); err != nil {
+		return err
+	}
+	if err := rawdb.DeleteBlockRewardAccumulator(batch, number); err != nil {
+		return err
+	}
+	if err := rawdb.DeleteBurnedFeesAccumulator(batch, number); err != nil {
+		return err
+	}
+	if len(header.ShardState()) > 0 {
+		if err := rawdb.DeleteShardState(batch, nextEpoch); err != nil {
+			return err
+		}
+		if err := rawdb.DeleteElectionAudit(batch, nextEpoch.Uint64()); err != nil {
+			return err
+		}
+	}
+	if bc.isCommitteeCheckpointBlock(header) {
+		if err := rawdb.DeleteCommitteeCheckpoint(batch, header.Epoch().Uint64()); err != nil {
+			return err
+		}
+	}
+	if isBeaconChain && shard.Schedule.IsLastBlock(number+1) {
+		for _, addr := range validators {
+			rawdb.DeleteValidatorSnapshot(batch, addr, nextEpoch)
+		}
+	}
+	if isBeaconChain && len(header.CrossLinks()) > 0 {
+		crossLinks := types.CrossLinks{}
+		if err := rlp.DecodeBytes(header.CrossLinks(), &crossLinks); err != nil {
+			return err
+		}
+		for _, crossLink := range crossLinks {
+			if err := rawdb.DeleteCrossLinkShardBlock(
+				batch, crossLink.ShardID(), crossLink.BlockNum(),
+			); err != nil {
+				return err
+			}
+			if err := rawdb.DeleteCrossLinkBeaconBlock(
+				batch, crossLink.ShardID(), crossLink.BlockNum(),
+			); err != nil {
+				return err
+			}
+		}
+		indexes.crossLinks = append(indexes.crossLinks, crossLinks...)
+	}
+	if bc.chainConfig.HasCrossTxFields(header.Epoch()) {
+		numShards := shard.Schedule.InstanceForEpoch(header.Epoch()).NumShards()
+		for i := uint32(0); i < numShards; i++ {
+			if i == header.ShardID() {
+				continue
+			}
+			if err := rawdb.DeleteCXReceipts(batch, i, number, hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	block := bc.GetBlock(hash, number)
+	if block == nil {
+		utils.Logger().Warn().
+			Uint64("number", number).
+			Msg("[RollbackTo] body is missing, its lookups are kept")
+		return nil
+	}
+	for _, tx := range block.Transactions() {
+		rawdb.DeleteTxLookupEntry(batch, tx.Hash())
+	}
+	for _, cxp := range block.IncomingReceipts() {
+		for _, cx := range cxp.Receipts {
+			rawdb.DeleteCxLookupEntry(batch, cx.TxHash)
+		}
+		rawdb.DeleteCXReceiptsProofSpent(
+			batch, cxp.MerkleProof.ShardID, cxp.MerkleProof.BlockNum.Uint64(),
+		)
+	}
+	for _, tx := range block.StakingTransactions() {
+		rawdb.DeleteTxLookupEntry(batch, tx.Hash())
+		switch tx.StakingType() {
+		case staking.DirectiveCreateValidator, staking.DirectiveDelegate:
+		default:
+			continue
+		}
+		addr, err := tx.SenderAddress()
+		if err != nil {
+			return err
+		}
+		indexes.delegators[addr] = struct{}{}
+		if tx.StakingType() == staking.DirectiveCreateValidator {
+			indexes.validators[addr] = struct{}{}
+			rawdb.DeleteValidatorSnapshot(batch, addr, header.Epoch())
+			rawdb.DeleteValidatorSnapshot(batch, addr, nextEpoch)
+			rawdb.DeleteValidatorStats(batch, addr)
+		}
+	}
+	return nil
+}
+
+// rollbackCrossLinks puts the crosslinks of the rolled back blocks back to
+// the pending crosslinks, and rewinds the last crosslink of their shards
+func (bc *BlockChain) rollbackCrossLinks(crossLinks []types.CrossLink) error {
+	if len(crossLinks) == 0 {
+		return nil
+	}
+	first := map[uint32]uint64{}
+	for _, crossLink := range crossLinks {
+		if n, ok := first[crossLink.ShardID()]; !ok || crossLink.BlockNum() < n {
+			first[crossLink.ShardID()] = crossLink.BlockNum()
+		}
+	}
+	for shardID, n := range first {
+		last, err := bc.ReadShardLastCrossLink(shardID)
+		if err != nil || last.BlockNum() < n {
+			continue
+		}
+		previous, err := bc.ReadCrossLink(shardID, n-1)
+		if err != nil {
+			utils.Logger().Warn().
+				Uint32("shardID", shardID).
+				Uint64("blockNum", n-1).
+				Msg("[RollbackTo] cannot rewind the last crosslink of the shard")
+			continue
+		}
+		if err := rawdb.WriteShardLastCrossLink(bc.db, shardID, previous.Serialize()); err != nil {
+			return err
+		}
+	}
+	bc.pendingCrossLinksCache.Purge()
+	_, err := bc.AddPendingCrossLinks(crossLinks)
+	return err
+}
+
+// verifyHead verifies that the head of the chain is the canonical block of
+// the number, that it has no canonical successor, and that its transactions,
+// receipts and state match the roots of its header
+func (bc *BlockChain) verifyHead(number uint64) error {
+	head := bc.CurrentBlock()
+	if head.NumberU64() != number || bc.CurrentHeader().Hash() != head.Hash() {
+		return errors.Errorf(
+			"head block is %d, head header is %d, expect %d",
+			head.NumberU64(), bc.CurrentHeader().Number().Uint64(), number,
+		)
+	}
+	if hash := rawdb.ReadCanonicalHash(bc.db, number); hash != head.Hash() {
+		return errors.Errorf("canonical hash %x of block %d is not the head %x", hash, number, head.Hash())
+	}
+	if hash := rawdb.ReadCanonicalHash(bc.db, number+1); hash != (common.Hash{}) {
+		return errors.Errorf("block %d above the head is still canonical", number+1)
+	}
+	header := head.Header()
+	if hash := types.DeriveSha(
+		head.Transactions(), head.StakingTransactions(),
+	); hash != header.TxHash() {
+		return errors.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash())
+	}
+	if number > 0 && number >= rawdb.ReadPrunedBlock(bc.db) {
+		receipts := rawdb.ReadReceipts(bc.db, head.Hash(), number)
+		if hash := types.DeriveSha(receipts); hash != header.ReceiptHash() {
+			return errors.Errorf("receipt root hash mismatch: have %x, want %x", hash, header.ReceiptHash())
+		}
+	}
+	if _, err := state.New(header.Root(), bc.stateCache); err != nil {
+		return errors.Wrapf(err, "state root %x of the head is missing", header.Root())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	chain2 "github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/internal/params"
+)
+
+// newTestChain returns a chain of 3 blocks above the genesis, which keep the
+// genesis state, each with a transaction and its receipt
+func newTestChain(t *testing.T) (ethdb.Database, *BlockChain, []*types.Block) {
+	key, _ := crypto.GenerateKey()
+	gspec := Genesis{
+		Config:  params.TestChainConfig,
+		Factory: blockfactory.ForTest,
+		Alloc: GenesisAlloc{
+			crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(8e18)},
+		},
+		GasLimit: 1e18,
+	}
+	db := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+
+	blocks, parent := []*types.Block{}, genesis
+	for i := uint64(0); i < 3; i++ {
+		tx, _ := types.SignTx(
+			types.NewTransaction(i, common.Address{}, 0, big.NewInt(100), 21000, big.NewInt(1), nil),
+			types.HomesteadSigner{}, key,
+		)
+		receipt := &types.Receipt{TxHash: tx.Hash(), GasUsed: 21000}
+		header := blockfactory.ForTest.NewHeader(common.Big0).With().
+			ParentHash(parent.Hash()).
+			Number(new(big.Int).Add(parent.Number(), common.Big1)).
+			Root(genesis.Root()).
+			Header()
+		block := types.NewBlock(header, []*types.Transaction{tx}, []*types.Receipt{receipt}, nil, nil, nil)
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteTd(db, block.Hash(), block.NumberU64(), block.Number())
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{receipt})
+		rawdb.WriteTxLookupEntries(db, block)
+		rawdb.WriteBlockRewardAccumulator(db, big.NewInt(int64(i)), block.NumberU64())
+		blocks, parent = append(blocks, block), block
+	}
+	rawdb.WriteHeadBlockHash(db, parent.Hash())
+	rawdb.WriteHeadHeaderHash(db, parent.Hash())
+	rawdb.WriteHeadFastBlockHash(db, parent.Hash())
+	bc, err := NewBlockChain(db, nil, gspec.Config, chain2.Engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bc.CurrentBlock().NumberU64(); n != 3 {
+		t.Fatalf("got head block %d, expect 3", n)
+	}
+	return db, bc, blocks
+}
+
+func TestRollbackTo(t *testing.T) {
+	db, bc, blocks := newTestChain(t)
+	defer bc.Stop()
+
+	if err := bc.RollbackTo(3); err == nil {
+		t.Error("expect an error rolling back to the head block")
+	}
+	if err := bc.RollbackTo(1); err != nil {
+		t.Fatal(err)
+	}
+	if head := bc.CurrentBlock(); head.Hash() != blocks[0].Hash() || bc.CurrentHeader().Hash() != head.Hash() {
+		t.Fatalf("got head block %d, expect 1", head.NumberU64())
+	}
+	for _, block := range blocks[1:] {
+		hash := block.Transactions()[0].Hash()
+		if tx, _, _, _ := rawdb.ReadTransaction(db, hash); tx != nil {
+			t.Errorf("transaction of block %d is still indexed", block.NumberU64())
+		}
+		if rawdb.ReadReceipts(db, block.Hash(), block.NumberU64()) != nil {
+			t.Errorf("receipts of block %d are kept", block.NumberU64())
+		}
+		if rawdb.HasHeader(db, block.Hash(), block.NumberU64()) {
+			t.Errorf("header of block %d is kept", block.NumberU64())
+		}
+		if _, err := rawdb.ReadBlockRewardAccumulator(db, block.NumberU64()); err == nil {
+			t.Errorf("block reward accumulator of block %d is kept", block.NumberU64())
+		}
+	}
+	if tx, _, _, _ := rawdb.ReadTransaction(db, blocks[0].Transactions()[0].Hash()); tx == nil {
+		t.Error("transaction of block 1 is not indexed")
+	}
+
+}
@@ -0,0 +1,462 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/harmony/block"
+	consensus_engine "github.com/harmony-one/harmony/consensus/engine"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/network"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// SnapValidatorStats is the stats of one validator in SnapStakingMeta
+type SnapValidatorStats struct {
+	Address common.Address
+	Stats   *staking.ValidatorStats
+}
+
+// SnapDelegations is the delegation index of one delegator in SnapStakingMeta
+type SnapDelegations struct {
+	Delegator common.Address
+	Indexes   staking.DelegationIndexes
+}
+
+// SnapStakingMeta is the off-chain staking data of a snap sync pivot block,
+// which is written while executing blocks and cannot be derived from the
+// state of the pivot block alone.
+type SnapStakingMeta struct {
+	Validators        []common.Address
+	Snapshots         []*staking.ValidatorSnapshot // snapshots of the pivot epoch and the next one
+	Stats             []*SnapValidatorStats
+	Delegations       []*SnapDelegations
+	RewardAccumulator *big.Int
+	Committee         []byte // shard state of the pivot epoch
+}
+
+// InsertSnapHeaders inserts a chain of headers without their bodies or state.
+// Every checkFreq-th header, the headers of epoch transitions and the last
+// header have their seal verified. The shard states carried by the headers are
+// written as soon as their header is inserted, so that the headers of the
+// following epoch can be verified.
+func (bc *BlockChain) InsertSnapHeaders(headers []*block.Header, checkFreq int) (int, error) {
+	if checkFreq < 1 {
+		checkFreq = 1
+	}
+	var pending []*block.Header
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		err := bc.writeSnapHeaders(pending)
+		pending = nil
+		return err
+	}
+	for i, header := range headers {
+		// the first header of an epoch carries the first commit of the new committee
+		seal := i == 0 || i == len(headers)-1 ||
+			len(headers[i-1].ShardState()) > 0 ||
+			header.Number().Uint64()%uint64(checkFreq) == 0
+		if seal {
+			// the seal is verified against the parent, which has to be written first
+			if err := flush(); err != nil {
+				return i, err
+			}
+			if err := bc.Engine().VerifyHeader(bc, header, true); err != nil {
+				return i, errors.Wrapf(err, "cannot verify header %d", header.Number())
+			}
+		}
+		pending = append(pending, header)
+		if len(header.ShardState()) > 0 {
+			if err := flush(); err != nil {
+				return i, err
+			}
+			epoch, err := bc.getNextBlockEpoch(header)
+			if err != nil {
+				return i, err
+			}
+			if _, err := bc.WriteShardStateBytes(bc.db, epoch, header.ShardState()); err != nil {
+				return i, err
+			}
+		}
+	}
+	return 0, flush()
+}
+
+// InsertBeaconHeaders inserts beacon chain headers without their bodies or
+// state, for shard nodes following only the beacon chain headers. The commit
+// signature of every header is verified, and the committees and cross links
+// the headers carry are written, which is all the beacon chain data a shard
+// chain reads.
+func (bc *BlockChain) InsertBeaconHeaders(headers []*block.Header) (int, error) {
+	if bc.ShardID() != shard.BeaconChainShardID {
+		return 0, errors.Errorf("shard %d is not the beacon chain", bc.ShardID())
+	}
+	if i, err := bc.InsertSnapHeaders(headers, 1); err != nil {
+		return i, err
+	}
+	batch := bc.db.NewBatch()
+	for i, header := range headers {
+		if bc.chainConfig.IsCrossLink(header.Epoch()) && len(header.CrossLinks()) > 0 {
+			if err := bc.writeHeaderCrossLinks(batch, header); err != nil {
+				return i, err
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	if len(headers) == 0 {
+		return 0, nil
+	}
+	last := headers[len(headers)-1]
+	if bc.chainConfig.IsCrossLink(last.Epoch()) {
+		batch := bc.db.NewBatch()
+		for i, c := uint32(0), shard.CrossLinkShards(last.Epoch()); i < c; i++ {
+			bc.LastContinuousCrossLink(batch, i)
+		}
+		return 0, batch.Write()
+	}
+	return 0, nil
+}
+
+// writeSnapHeaders writes headers extending the current header as the
+// canonical header chain and makes the last of them the current header.
+func (bc *BlockChain) writeSnapHeaders(headers []*block.Header) error {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	batch := bc.db.NewBatch()
+	parent := bc.CurrentHeader()
+	for _, header := range headers {
+		if header.ParentHash() != parent.Hash() ||
+			header.Number().Uint64() != parent.Number().Uint64()+1 {
+			return errors.Wrapf(
+				consensus_engine.ErrUnknownAncestor, "header %d", header.Number(),
+			)
+		}
+		rawdb.WriteHeader(batch, header)
+		rawdb.WriteCanonicalHash(batch, header.Hash(), header.Number().Uint64())
+		parent = header
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	bc.hc.SetCurrentHeader(parent)
+	return nil
+}
+
+// InsertSnapBlocks completes the headers inserted by InsertSnapHeaders with
+// the block bodies, the receipts and the off-chain data the blocks carry. The
+// bodies and receipts are checked against the roots in their headers.
+func (bc *BlockChain) InsertSnapBlocks(blocks types.Blocks, receipts []types.Receipts) (int, error) {
+	if len(blocks) != len(receipts) {
+		return 0, errors.Errorf(
+			"got %d blocks and %d receipt lists", len(blocks), len(receipts),
+		)
+	}
+	for i, b := range blocks {
+		header := b.Header()
+		if hash := types.DeriveSha(
+			b.Transactions(), b.StakingTransactions(),
+		); hash != header.TxHash() {
+			return i, errors.Errorf(
+				"block %d: transaction root hash mismatch: have %x, want %x",
+				b.NumberU64(), hash, header.TxHash(),
+			)
+		}
+		if hash := types.DeriveSha(receipts[i]); hash != header.ReceiptHash() {
+			return i, errors.Errorf(
+				"block %d: receipt root hash mismatch: have %x, want %x",
+				b.NumberU64(), hash, header.ReceiptHash(),
+			)
+		}
+	}
+	if i, err := bc.InsertReceiptChain(blocks, receipts); err != nil {
+		return i, err
+	}
+
+	batch := bc.db.NewBatch()
+	isBeaconChain := bc.ShardID() == shard.BeaconChainShardID
+	for i, b := range blocks {
+		header := b.Header()
+		if bc.chainConfig.HasCrossTxFields(b.Epoch()) {
+			bc.WriteCXReceiptsProofSpent(batch, b.IncomingReceipts())
+		}
+		if isBeaconChain &&
+			bc.chainConfig.IsCrossLink(b.Epoch()) &&
+			len(header.CrossLinks()) > 0 {
+			if err := bc.writeHeaderCrossLinks(batch, header); err != nil {
+				return i, err
+			}
+		}
+	}
+	if isBeaconChain && len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		if bc.chainConfig.IsCrossLink(last.Epoch()) {
+			for i, c := uint32(0), shard.CrossLinkShards(last.Epoch()); i < c; i++ {
+				bc.LastContinuousCrossLink(batch, i)
+			}
+		}
+	}
+	return 0, batch.Write()
+}
+
+// ReadSnapStakingMeta collects the off-chain staking data a snap synced node
+// needs to continue processing blocks after the given pivot header, whose
+// state must be available.
+func (bc *BlockChain) ReadSnapStakingMeta(header *block.Header) (*SnapStakingMeta, error) {
+	nextEpoch, err := bc.getNextBlockEpoch(header)
+	if err != nil {
+		return nil, err
+	}
+	state, err := bc.StateAt(header.Root())
+	if err != nil {
+		return nil, err
+	}
+	list, err := rawdb.ReadValidatorList(bc.db)
+	if err != nil {
+		return nil, err
+	}
+	meta := &SnapStakingMeta{
+		Validators:        []common.Address{},
+		Snapshots:         []*staking.ValidatorSnapshot{},
+		Stats:             []*SnapValidatorStats{},
+		Delegations:       []*SnapDelegations{},
+		RewardAccumulator: big.NewInt(0),
+	}
+	delegators := map[common.Address]struct{}{}
+	for _, addr := range list {
+		// the list also holds validators created after the pivot
+		if !state.IsValidator(addr) {
+			continue
+		}
+		wrapper, err := state.ValidatorWrapper(addr)
+		if err != nil {
+			return nil, err
+		}
+		meta.Validators = append(meta.Validators, addr)
+		// the block after the pivot still reads the snapshots of the pivot epoch
+		for _, epoch := range []*big.Int{header.Epoch(), nextEpoch} {
+			if snapshot, err := rawdb.ReadValidatorSnapshot(
+				bc.db, addr, epoch,
+			); err == nil {
+				meta.Snapshots = append(meta.Snapshots, snapshot)
+			}
+		}
+		if stats, err := rawdb.ReadValidatorStats(bc.db, addr); err == nil {
+			meta.Stats = append(meta.Stats, &SnapValidatorStats{addr, stats})
+		}
+		for _, delegation := range wrapper.Delegations {
+			delegator := delegation.DelegatorAddress
+			if _, ok := delegators[delegator]; ok {
+				continue
+			}
+			delegators[delegator] = struct{}{}
+			indexes, err := rawdb.ReadDelegationsByDelegator(bc.db, delegator)
+			if err != nil {
+				return nil, err
+			}
+			entry := &SnapDelegations{delegator, staking.DelegationIndexes{}}
+			for _, index := range indexes {
+				if index.BlockNum == nil || index.BlockNum.Cmp(header.Number()) <= 0 {
+					entry.Indexes = append(entry.Indexes, index)
+				}
+			}
+			meta.Delegations = append(meta.Delegations, entry)
+		}
+	}
+	if accumulator, err := bc.ReadBlockRewardAccumulator(
+		header.Number().Uint64(),
+	); err == nil {
+		meta.RewardAccumulator = accumulator
+	}
+	if committee, err := bc.ReadShardState(header.Epoch()); err == nil {
+		if meta.Committee, err = shard.EncodeWrapper(
+			*committee, bc.chainConfig.IsStaking(header.Epoch()),
+		); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// WriteSnapStakingMeta writes the off-chain staking data received for the
+// snap sync pivot header, whose state must be available. Entries which do not
+// match the state of the pivot are rejected.
+func (bc *BlockChain) WriteSnapStakingMeta(header *block.Header, meta *SnapStakingMeta) error {
+	nextEpoch, err := bc.getNextBlockEpoch(header)
+	if err != nil {
+		return err
+	}
+	state, err := bc.StateAt(header.Root())
+	if err != nil {
+		return err
+	}
+	validators := map[common.Address]struct{}{}
+	for _, addr := range meta.Validators {
+		if !state.IsValidator(addr) {
+			return errors.Errorf("%s is not a validator at the pivot", addr.Hex())
+		}
+		validators[addr] = struct{}{}
+	}
+	batch := bc.db.NewBatch()
+	if err := rawdb.WriteValidatorList(batch, meta.Validators); err != nil {
+		return err
+	}
+	for _, snapshot := range meta.Snapshots {
+		if snapshot.Validator == nil || snapshot.Epoch == nil {
+			return errors.New("incomplete validator snapshot")
+		}
+		if _, ok := validators[snapshot.Validator.Address]; !ok {
+			return errors.Errorf(
+				"snapshot of unknown validator %s", snapshot.Validator.Address.Hex(),
+			)
+		}
+		if snapshot.Epoch.Cmp(header.Epoch()) != 0 && snapshot.Epoch.Cmp(nextEpoch) != 0 {
+			return errors.Errorf("snapshot of unexpected epoch %s", snapshot.Epoch)
+		}
+		if err := rawdb.WriteValidatorSnapshot(
+			batch, snapshot.Validator, snapshot.Epoch,
+		); err != nil {
+			return err
+		}
+	}
+	for _, entry := range meta.Stats {
+		if _, ok := validators[entry.Address]; !ok {
+			return errors.Errorf("stats of unknown validator %s", entry.Address.Hex())
+		}
+		if err := rawdb.WriteValidatorStats(batch, entry.Address, entry.Stats); err != nil {
+			return err
+		}
+	}
+	for _, entry := range meta.Delegations {
+		for _, index := range entry.Indexes {
+			wrapper, err := state.ValidatorWrapper(index.ValidatorAddress)
+			if err != nil {
+				return err
+			}
+			if index.Index >= uint64(len(wrapper.Delegations)) ||
+				wrapper.Delegations[index.Index].DelegatorAddress != entry.Delegator {
+				return errors.Errorf(
+					"delegation %d of validator %s does not belong to %s",
+					index.Index, index.ValidatorAddress.Hex(), entry.Delegator.Hex(),
+				)
+			}
+		}
+		if err := rawdb.WriteDelegationsByDelegator(
+			batch, entry.Delegator, entry.Indexes,
+		); err != nil {
+			return err
+		}
+	}
+	if _, err := bc.ReadShardState(header.Epoch()); err != nil {
+		// a chain starting at a checkpoint lacks the committee which signed
+		// the pivot, the block after it verifies and rewards that commit
+		if err := writeSnapCommittee(batch, header, meta.Committee); err != nil {
+			return err
+		}
+	}
+	if meta.RewardAccumulator != nil {
+		if err := rawdb.WriteBlockRewardAccumulator(
+			batch, meta.RewardAccumulator, header.Number().Uint64(),
+		); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	bc.validatorListCache.Purge()
+	bc.validatorSnapshotCache.Purge()
+	network.DelegatorShares.Purge()
+	bc.validatorStatsCache.Purge()
+	bc.validatorListByDelegatorCache.Purge()
+	bc.blockAccumulatorCache.Purge()
+	return nil
+}
+
+// writeSnapCommittee writes the encoded committee of the epoch of the pivot header
+func writeSnapCommittee(batch rawdb.DatabaseWriter, header *block.Header, encoded []byte) error {
+	if len(encoded) == 0 {
+		return errors.Errorf("missing committee of epoch %s", header.Epoch())
+	}
+	committee, err := shard.DecodeWrapper(encoded)
+	if err != nil {
+		return err
+	}
+	if committee.Epoch != nil && committee.Epoch.Cmp(header.Epoch()) != 0 {
+		return errors.Errorf(
+			"got committee of epoch %s, expected %s", committee.Epoch, header.Epoch(),
+		)
+	}
+	return rawdb.WriteShardStateBytes(batch, header.Epoch(), encoded)
+}
+
+// WriteCheckpointHeader makes the header of a trusted checkpoint the current
+// header of an empty chain, which then continues from the checkpoint without
+// the headers before it. The checkpoint must be the last block of its epoch,
+// so that its shard state verifies the commits of the following blocks.
+func (bc *BlockChain) WriteCheckpointHeader(header *block.Header) error {
+	if bc.CurrentBlock().NumberU64() != 0 {
+		return errors.New("a checkpoint can only start an empty chain")
+	}
+	if len(header.ShardState()) == 0 {
+		return errors.Errorf(
+			"checkpoint %d is not the last block of an epoch", header.Number(),
+		)
+	}
+	nextEpoch, err := bc.getNextBlockEpoch(header)
+	if err != nil {
+		return err
+	}
+
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	batch := bc.db.NewBatch()
+	rawdb.WriteHeader(batch, header)
+	rawdb.WriteCanonicalHash(batch, header.Hash(), header.Number().Uint64())
+	if _, err := bc.WriteShardStateBytes(batch, nextEpoch, header.ShardState()); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	bc.hc.SetCurrentHeader(header)
+	utils.Logger().Info().
+		Uint64("number", header.Number().Uint64()).
+		Uint64("epoch", header.Epoch().Uint64()).
+		Str("hash", header.Hash().Hex()).
+		Msg("Wrote trusted checkpoint header")
+	return nil
+}
+
+// SnapSyncCommitHead sets the head block to the block with the given hash,
+// whose body was inserted by InsertSnapBlocks and whose state was downloaded.
+func (bc *BlockChain) SnapSyncCommitHead(hash common.Hash) error {
+	b := bc.GetBlockByHash(hash)
+	if b == nil {
+		return errors.Errorf("non existent block %x", hash)
+	}
+	if _, err := trie.NewSecure(b.Root(), bc.stateCache.TrieDB(), 0); err != nil {
+		return err
+	}
+	bc.mu.Lock()
+	rawdb.WriteHeadBlockHash(bc.db, hash)
+	rawdb.WriteHeadFastBlockHash(bc.db, hash)
+	bc.currentBlock.Store(b)
+	bc.currentFastBlock.Store(b)
+	bc.mu.Unlock()
+	utils.Logger().Info().
+		Uint64("number", b.NumberU64()).
+		Str("hash", hash.Hex()).
+		Msg("Committed snap sync head block")
+	return nil
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	consensus_engine "github.com/harmony-one/harmony/consensus/engine"
+	"github.com/pkg/errors"
+)
+
+func TestInsertBeaconHeadersUnknownAncestor(t *testing.T) {
+	bc := createBlockChain()
+	if _, err := bc.InsertBeaconHeaders(nil); err != nil {
+		t.Fatalf("cannot insert no header: %v", err)
+	}
+	header := blockfactory.ForTest.NewHeader(big.NewInt(0)).With().
+		ParentHash(common.HexToHash("0x01")).
+		Number(big.NewInt(1)).
+		Header()
+	if _, err := bc.InsertBeaconHeaders(
+		[]*block.Header{header},
+	); !errors.Is(err, consensus_engine.ErrUnknownAncestor) {
+		t.Errorf("got error %v, expect unknown ancestor", err)
+	}
+	if n := bc.CurrentHeader().Number().Uint64(); n != 0 {
+		t.Errorf("current header moved to %d", n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/harmony/common/denominations"
 	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/crypto/bls"
 	common2 "github.com/harmony-one/harmony/internal/common"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/shard"
@@ -140,6 +141,9 @@ func VerifyAndEditValidatorFromMsg(
 	if !stateDB.IsValidator(msg.ValidatorAddress) {
 		return nil, errValidatorNotExist
 	}
+	if _, _, err := msg.ShardPreferenceUpdate(); err != nil {
+		return nil, err
+	}
 	newBlsKeys := []shard.BLSPublicKey{}
 	if msg.SlotKeyToAdd != nil {
 		newBlsKeys = append(newBlsKeys, *msg.SlotKeyToAdd)
@@ -338,3 +342,108 @@ func VerifyAndCollectRewardsFromDelegation(
 	}
 	return updatedValidatorWrappers, totalRewards, nil
 }
+
+// ReadBoundSlotKey returns the key bound on the curve to the slot key of the
+// validator, false if none.
+func ReadBoundSlotKey(
+	stateDB vm.StateDB, addr common.Address, curve bls.CurveID, slotKey shard.BLSPublicKey,
+) (shard.BLSPublicKey, bool) {
+	storage := staking.BoundSlotKeyStorage(curve, slotKey)
+	return staking.DecodeBoundSlotKey([2]common.Hash{
+		stateDB.GetState(addr, storage[0]), stateDB.GetState(addr, storage[1]),
+	})
+}
+
+// ReadBoundKeyOwner returns the validator the key on the curve was bound
+// for, false if none.
+func ReadBoundKeyOwner(
+	stateDB vm.StateDB, curve bls.CurveID, boundKey shard.BLSPublicKey,
+) (common.Address, bool) {
+	owner := stateDB.GetState(
+		staking.BoundKeyIndexAddress, staking.BoundKeyOwnerStorage(curve, boundKey),
+	)
+	return common.BytesToAddress(owner.Bytes()), owner != common.Hash{}
+}
+
+// WriteBoundSlotKey binds the key of the message to the slot key of the
+// validator and indexes the validator by the bound key, releasing the key
+// previously bound to the slot key.
+func WriteBoundSlotKey(stateDB vm.StateDB, msg *staking.BindSlotKey) {
+	if old, ok := ReadBoundSlotKey(
+		stateDB, msg.ValidatorAddress, msg.Curve, msg.SlotPubKey,
+	); ok {
+		stateDB.SetState(
+			staking.BoundKeyIndexAddress,
+			staking.BoundKeyOwnerStorage(msg.Curve, old),
+			common.Hash{},
+		)
+	}
+	storage := staking.BoundSlotKeyStorage(msg.Curve, msg.SlotPubKey)
+	words := staking.EncodeBoundSlotKey(msg.BoundPubKey)
+	for i := range storage {
+		stateDB.SetState(msg.ValidatorAddress, storage[i], words[i])
+	}
+	// the nonce keeps the index account from being deleted as empty
+	if stateDB.GetNonce(staking.BoundKeyIndexAddress) == 0 {
+		stateDB.SetNonce(staking.BoundKeyIndexAddress, 1)
+	}
+	stateDB.SetState(
+		staking.BoundKeyIndexAddress,
+		staking.BoundKeyOwnerStorage(msg.Curve, msg.BoundPubKey),
+		msg.ValidatorAddress.Hash(),
+	)
+}
+
+// VerifyAndBindSlotKeyFromMsg verifies the bind slot key message using the
+// stateDB, the bound key being bound to no other slot key. Only the slot
+// keys of the validator indexed by the bound key are checked.
+//
+// Note that this function never updates the stateDB, it only reads from stateDB.
+func VerifyAndBindSlotKeyFromMsg(stateDB vm.StateDB, msg *staking.BindSlotKey) error {
+	if stateDB == nil {
+		return errStateDBIsMissing
+	}
+	if !stateDB.IsValidator(msg.ValidatorAddress) {
+		return errValidatorNotExist
+	}
+	wrapper, err := stateDB.ValidatorWrapperCopy(msg.ValidatorAddress)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, key := range wrapper.SlotPubKeys {
+		if key == msg.SlotPubKey {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.Wrapf(errSlotKeyNotFound, "slot key %x", msg.SlotPubKey)
+	}
+	if err := msg.VerifyBinding(); err != nil {
+		return err
+	}
+
+	owner, ok := ReadBoundKeyOwner(stateDB, msg.Curve, msg.BoundPubKey)
+	if !ok {
+		return nil
+	}
+	// the owner may have removed the slot key it bound the key to since
+	other := wrapper
+	if owner != msg.ValidatorAddress {
+		if other, err = stateDB.ValidatorWrapperCopy(owner); err != nil {
+			return err
+		}
+	}
+	for _, key := range other.SlotPubKeys {
+		if owner == msg.ValidatorAddress && key == msg.SlotPubKey {
+			continue
+		}
+		if bound, ok := ReadBoundSlotKey(
+			stateDB, owner, msg.Curve, key,
+		); ok && bound == msg.BoundPubKey {
+			return errors.Wrapf(errDupBlsKey, "duplicate bound key %x", bound)
+		}
+	}
+	return nil
+}
@@ -1301,6 +1301,90 @@ func makeFakeChainContextForStake() *fakeChainContext {
 }
 
 // makeStateDBForStake make the default state db for staking test
+func TestVerifyAndBindSlotKeyFromMsg(t *testing.T) {
+	sdb := makeStateDBForStake(t)
+	mcl, _ := bls.CurveByID(bls.CurveMCL)
+	bls12381, _ := bls.CurveByID(bls.CurveBLS12381)
+	randKey := func(curve bls.Curve) bls.CurveSecretKey {
+		key, err := curve.RandSecretKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	// two more slot keys of validator, one of validator2
+	slotKeys := []bls.CurveSecretKey{randKey(mcl), randKey(mcl), randKey(mcl)}
+	owners := []common.Address{validatorAddr, validatorAddr, validatorAddr2}
+	for i, key := range slotKeys {
+		w, err := sdb.ValidatorWrapperCopy(owners[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pub shard.BLSPublicKey
+		copy(pub[:], key.PublicKey())
+		w.SlotPubKeys = append(w.SlotPubKeys, pub)
+		if err := sdb.UpdateValidatorWrapper(owners[i], w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	boundKeys := []bls.CurveSecretKey{randKey(bls12381), randKey(bls12381)}
+	makeMsg := func(slot, bound int) *staking.BindSlotKey {
+		msg := &staking.BindSlotKey{ValidatorAddress: owners[slot], Curve: bls.CurveBLS12381}
+		copy(msg.SlotPubKey[:], slotKeys[slot].PublicKey())
+		copy(msg.BoundPubKey[:], boundKeys[bound].PublicKey())
+		hash := msg.Hash()
+		copy(msg.SlotKeySig[:], slotKeys[slot].Sign(hash[:]))
+		copy(msg.BoundKeySig[:], boundKeys[bound].Sign(hash[:]))
+		return msg
+	}
+	bind := func(slot, bound int, expErr error) {
+		t.Helper()
+		msg := makeMsg(slot, bound)
+		err := VerifyAndBindSlotKeyFromMsg(sdb, msg)
+		if assErr := assertError(err, expErr); assErr != nil {
+			t.Fatalf("slot %v bound %v: %v", slot, bound, assErr)
+		}
+		if err == nil {
+			WriteBoundSlotKey(sdb, msg)
+		}
+	}
+
+	bind(0, 0, nil)
+	if owner, ok := ReadBoundKeyOwner(sdb, bls.CurveBLS12381, makeMsg(0, 0).BoundPubKey); !ok || owner != validatorAddr {
+		t.Fatalf("owner %v, %v, expect %v", owner.Hex(), ok, validatorAddr.Hex())
+	}
+	// binding again the same key to the same slot key
+	bind(0, 0, nil)
+	// the key bound to another slot key of the same or another validator
+	bind(1, 0, errDupBlsKey)
+	bind(2, 0, errDupBlsKey)
+
+	// rebinding the slot key releases the key
+	bind(0, 1, nil)
+	if _, ok := ReadBoundKeyOwner(sdb, bls.CurveBLS12381, makeMsg(0, 0).BoundPubKey); ok {
+		t.Fatal("released key still indexed")
+	}
+	bind(2, 0, nil)
+	bind(1, 1, errDupBlsKey)
+
+	// the key bound to a slot key removed since is free
+	w, err := sdb.ValidatorWrapperCopy(validatorAddr2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SlotPubKeys = w.SlotPubKeys[:len(w.SlotPubKeys)-1]
+	if err := sdb.UpdateValidatorWrapper(validatorAddr2, w); err != nil {
+		t.Fatal(err)
+	}
+	bind(1, 0, nil)
+
+	// the index account survives the deletion of empty accounts
+	sdb.IntermediateRoot(true)
+	if owner, ok := ReadBoundKeyOwner(sdb, bls.CurveBLS12381, makeMsg(1, 0).BoundPubKey); !ok || owner != validatorAddr {
+		t.Fatalf("owner %v, %v, expect %v", owner.Hex(), ok, validatorAddr.Hex())
+	}
+}
+
 func makeStateDBForStake(t *testing.T) *state.DB {
 	sdb, err := newTestStateDB()
 	if err != nil {
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/staking/effective"
+	stk "github.com/harmony-one/harmony/staking/types"
+)
+
+func newRewardTestValidator(addr common.Address, status effective.Eligibility) *stk.ValidatorWrapper {
+	wrapper := &stk.ValidatorWrapper{BlockReward: big.NewInt(0)}
+	wrapper.Address = addr
+	wrapper.Status = status
+	wrapper.Rate = numeric.MustNewDecFromStr("0.1")
+	for i := byte(0); i < 3; i++ {
+		wrapper.Delegations = append(wrapper.Delegations, stk.Delegation{
+			DelegatorAddress: common.BytesToAddress([]byte{addr[19], i}),
+			Amount:           big.NewInt(int64(i) + 1),
+			Reward:           big.NewInt(0),
+		})
+	}
+	return wrapper
+}
+
+func newRewardTestDB(validators ...*stk.ValidatorWrapper) (*DB, map[common.Address]numeric.Dec) {
+	db, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	shares := map[common.Address]numeric.Dec{}
+	for _, validator := range validators {
+		copied := *validator
+		copied.BlockReward = big.NewInt(0)
+		copied.Delegations = nil
+		for _, delegation := range validator.Delegations {
+			delegation.Reward = big.NewInt(0)
+			copied.Delegations = append(copied.Delegations, delegation)
+			shares[delegation.DelegatorAddress] = numeric.NewDec(delegation.Amount.Int64()).QuoInt64(6)
+		}
+		db.stateValidators[validator.Address] = &copied
+	}
+	return db, shares
+}
+
+// TestAddRewards checks the batch of credits distributes the same rewards as
+// AddReward for each credit
+func TestAddRewards(t *testing.T) {
+	a := newRewardTestValidator(common.Address{0x0a}, effective.Active)
+	b := newRewardTestValidator(common.Address{0x0b}, effective.Active)
+	banned := newRewardTestValidator(common.Address{0x0c}, effective.Banned)
+	credits := []RewardCredit{
+		{Snapshot: a, Amount: big.NewInt(1001)},
+		{Snapshot: b, Amount: big.NewInt(777)},
+		{Snapshot: a, Amount: big.NewInt(0)},
+		{Snapshot: banned, Amount: big.NewInt(500)},
+		{Snapshot: a, Amount: big.NewInt(333)},
+	}
+
+	batched, shares := newRewardTestDB(a, b, banned)
+	single, _ := newRewardTestDB(a, b, banned)
+	for i := range credits {
+		credits[i].Shares = shares
+		if err := single.AddReward(credits[i].Snapshot, credits[i].Amount, shares); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recorded, err := batched.AddRewards(credits, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the amounts recorded for each credit add up to it
+	for i, credit := range credits {
+		paid := big.NewInt(0)
+		for _, delegator := range recorded[i] {
+			paid.Add(paid, delegator.Amount)
+		}
+		expected := credit.Amount
+		if credit.Snapshot == banned {
+			expected = big.NewInt(0)
+		}
+		if paid.Cmp(expected) != 0 {
+			t.Errorf("credit %d: recorded %v credited, expected %v", i, paid, expected)
+		}
+	}
+
+	for _, validator := range []*stk.ValidatorWrapper{a, b, banned} {
+		got, _ := batched.ValidatorWrapper(validator.Address)
+		expected, _ := single.ValidatorWrapper(validator.Address)
+		if got.BlockReward.Cmp(expected.BlockReward) != 0 {
+			t.Errorf("validator %x: block reward %v, expected %v",
+				validator.Address, got.BlockReward, expected.BlockReward)
+		}
+		for i := range got.Delegations {
+			if got.Delegations[i].Reward.Cmp(expected.Delegations[i].Reward) != 0 {
+				t.Errorf("validator %x delegation %d: reward %v, expected %v", validator.Address,
+					i, got.Delegations[i].Reward, expected.Delegations[i].Reward)
+			}
+		}
+	}
+	if got, _ := batched.ValidatorWrapper(a.Address); got.BlockReward.Cmp(big.NewInt(1334)) != 0 {
+		t.Errorf("block reward %v, expected 1334", got.BlockReward)
+	}
+	if got, _ := batched.ValidatorWrapper(banned.Address); got.BlockReward.Sign() != 0 {
+		t.Errorf("banned validator rewarded %v", got.BlockReward)
+	}
+}
+
+// TestAddRewardsMissingShares checks a delegation missing from the shares
+// ends the payout of each credit as AddReward
+func TestAddRewardsMissingShares(t *testing.T) {
+	a := newRewardTestValidator(common.Address{0x0a}, effective.Active)
+	db, shares := newRewardTestDB(a)
+	delete(shares, a.Delegations[1].DelegatorAddress)
+	recorded, err := db.AddRewards([]RewardCredit{
+		{Snapshot: a, Amount: big.NewInt(600), Shares: shares},
+		{Snapshot: a, Amount: big.NewInt(600), Shares: shares},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the amounts recorded are the ones credited, not the whole credit
+	for i := range recorded {
+		if len(recorded[i]) != 1 || recorded[i][0].Delegator != a.Delegations[0].DelegatorAddress ||
+			recorded[i][0].Amount.Cmp(big.NewInt(150)) != 0 {
+			t.Errorf("credit %d: recorded %+v, expected 150 credited to the self delegation", i, recorded[i])
+		}
+	}
+	got, _ := db.ValidatorWrapper(a.Address)
+	// commission of 60 and the share of 1/6 of the remaining 540 for each
+	if reward := got.Delegations[0].Reward; reward.Cmp(big.NewInt(300)) != 0 {
+		t.Errorf("self delegation reward %v, expected 300", reward)
+	}
+	if reward := got.Delegations[1].Reward; reward.Sign() != 0 {
+		t.Errorf("delegation without share rewarded %v", reward)
+	}
+}
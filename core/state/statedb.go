@@ -789,13 +789,66 @@ var (
 
 // AddReward distributes the reward to all the delegators based on stake percentage.
 func (db *DB) AddReward(snapshot *stk.ValidatorWrapper, reward *big.Int, shareLookup map[common.Address]numeric.Dec) error {
-	if reward.Cmp(common.Big0) == 0 {
-		utils.Logger().Info().RawJSON("validator", []byte(snapshot.String())).
-			Msg("0 given as reward")
-		return nil
+	_, err := db.AddRewards([]RewardCredit{{Snapshot: snapshot, Amount: reward, Shares: shareLookup}}, false)
+	return err
+}
+
+// RewardCredit is a reward of a validator to distribute to its delegators
+// by their shares of the stake of the validator snapshot.
+type RewardCredit struct {
+	Snapshot *stk.ValidatorWrapper
+	Amount   *big.Int
+	Shares   map[common.Address]numeric.Dec
+}
+
+// DelegatorCredit is the part of the reward of a credit credited to a
+// delegator, the commission and the rounding remainder included for the self
+// delegation.
+type DelegatorCredit struct {
+	Delegator common.Address
+	Amount    *big.Int
+}
+
+// AddRewards distributes the rewards of the credits as AddReward does for
+// each of them, in a single pass over the validators credited. The credits
+// of a validator are of the same snapshot: the validator in state and the
+// shares of its delegations are looked up once for all of them, while each
+// credit is still split on its own to round as AddReward. With record set,
+// it returns the amounts credited to the delegators of each credit, by the
+// index of the credit, none for a credit nothing was credited for.
+func (db *DB) AddRewards(credits []RewardCredit, record bool) ([][]DelegatorCredit, error) {
+	var recorded [][]DelegatorCredit
+	if record {
+		recorded = make([][]DelegatorCredit, len(credits))
+	}
+	validators := []common.Address{}
+	byValidator := map[common.Address][]int{}
+	for i, credit := range credits {
+		if credit.Amount.Cmp(common.Big0) == 0 {
+			utils.Logger().Info().RawJSON("validator", []byte(credit.Snapshot.String())).
+				Msg("0 given as reward")
+			continue
+		}
+		addr := credit.Snapshot.Address
+		if _, ok := byValidator[addr]; !ok {
+			validators = append(validators, addr)
+		}
+		byValidator[addr] = append(byValidator[addr], i)
+	}
+	for _, addr := range validators {
+		if err := db.addValidatorRewards(addr, credits, byValidator[addr], recorded); err != nil {
+			return nil, err
+		}
 	}
+	return recorded, nil
+}
 
-	curValidator, err := db.ValidatorWrapper(snapshot.Address)
+// addValidatorRewards distributes the rewards of the credits of the validator
+// at the indexes, recording the amounts credited into recorded if not nil
+func (db *DB) addValidatorRewards(
+	addr common.Address, credits []RewardCredit, indexes []int, recorded [][]DelegatorCredit,
+) error {
+	curValidator, err := db.ValidatorWrapper(addr)
 	if err != nil {
 		return errors.Wrapf(err, "failed to distribute rewards: validator does not exist")
 	}
@@ -807,38 +860,80 @@ func (db *DB) AddReward(snapshot *stk.ValidatorWrapper, reward *big.Int, shareLo
 		return nil
 	}
 
-	rewardPool := big.NewInt(0).Set(reward)
-	curValidator.BlockReward.Add(curValidator.BlockReward, reward)
-	// Payout commission
-	if r := snapshot.Validator.CommissionRates.Rate; r.GT(zero) {
-		commissionInt := r.MulInt(reward).RoundInt()
-		curValidator.Delegations[0].Reward.Add(
-			curValidator.Delegations[0].Reward,
-			commissionInt,
-		)
-		rewardPool.Sub(rewardPool, commissionInt)
+	// the shares of the delegations of the snapshot, up to the first one
+	// missing from the lookup, which ends the payout of each credit
+	first := credits[indexes[0]]
+	snapshot := first.Snapshot
+	shares := make([]numeric.Dec, 0, len(snapshot.Delegations))
+	for _, delegation := range snapshot.Delegations {
+		percentage, ok := first.Shares[delegation.DelegatorAddress]
+		if !ok {
+			utils.Logger().Error().
+				Str("delegator", delegation.DelegatorAddress.Hex()).
+				Msg("missing delegation shares for reward distribution")
+			break
+		}
+		shares = append(shares, percentage)
 	}
+	complete := len(shares) == len(snapshot.Delegations)
 
-	// Payout each delegator's reward pro-rata
-	totalRewardForDelegators := big.NewInt(0).Set(rewardPool)
-	for i := range snapshot.Delegations {
-		delegation := snapshot.Delegations[i]
-		percentage, ok := shareLookup[delegation.DelegatorAddress]
+	for _, index := range indexes {
+		reward := credits[index].Amount
+		var credited []*big.Int
+		if recorded != nil {
+			credited = make([]*big.Int, len(shares))
+			if len(credited) == 0 {
+				credited = append(credited, big.NewInt(0))
+			}
+			for i := range credited {
+				credited[i] = big.NewInt(0)
+			}
+		}
+		rewardPool := big.NewInt(0).Set(reward)
+		curValidator.BlockReward.Add(curValidator.BlockReward, reward)
+		// Payout commission
+		if r := snapshot.Validator.CommissionRates.Rate; r.GT(zero) {
+			commissionInt := r.MulInt(reward).RoundInt()
+			curValidator.Delegations[0].Reward.Add(
+				curValidator.Delegations[0].Reward,
+				commissionInt,
+			)
+			rewardPool.Sub(rewardPool, commissionInt)
+			if credited != nil {
+				credited[0].Add(credited[0], commissionInt)
+			}
+		}
 
-		if !ok {
-			return errors.Wrapf(err, "missing delegation shares for reward distribution")
+		// Payout each delegator's reward pro-rata
+		totalRewardForDelegators := big.NewInt(0).Set(rewardPool)
+		for i, percentage := range shares {
+			rewardInt := percentage.MulInt(totalRewardForDelegators).RoundInt()
+			curDelegation := curValidator.Delegations[i]
+			curDelegation.Reward.Add(curDelegation.Reward, rewardInt)
+			rewardPool.Sub(rewardPool, rewardInt)
+			if credited != nil {
+				credited[i].Add(credited[i], rewardInt)
+			}
 		}
 
-		rewardInt := percentage.MulInt(totalRewardForDelegators).RoundInt()
-		curDelegation := curValidator.Delegations[i]
-		curDelegation.Reward.Add(curDelegation.Reward, rewardInt)
-		rewardPool.Sub(rewardPool, rewardInt)
-	}
+		// The last remaining bit belongs to the validator (remember the validator's self delegation is
+		// always at index 0)
+		if complete && rewardPool.Cmp(common.Big0) > 0 {
+			curValidator.Delegations[0].Reward.Add(curValidator.Delegations[0].Reward, rewardPool)
+			if credited != nil {
+				credited[0].Add(credited[0], rewardPool)
+			}
+		}
 
-	// The last remaining bit belongs to the validator (remember the validator's self delegation is
-	// always at index 0)
-	if rewardPool.Cmp(common.Big0) > 0 {
-		curValidator.Delegations[0].Reward.Add(curValidator.Delegations[0].Reward, rewardPool)
+		if credited != nil {
+			recorded[index] = make([]DelegatorCredit, len(credited))
+			for i, amount := range credited {
+				recorded[index][i] = DelegatorCredit{
+					Delegator: curValidator.Delegations[i].DelegatorAddress,
+					Amount:    amount,
+				}
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"sync/atomic"
+
+	consensus_engine "github.com/harmony-one/harmony/consensus/engine"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/params"
+)
+
+// prefetchBlocks is the number of blocks following the block being processed
+// whose state is prefetched
+const prefetchBlocks = 4
+
+// statePrefetcher is a basic Prefetcher, which blindly executes the
+// transactions of the blocks on top of an arbitrary state, so that the trie
+// nodes they read are cached by the time the blocks are processed.
+//
+// statePrefetcher implements Prefetcher.
+type statePrefetcher struct {
+	config *params.ChainConfig     // Chain configuration options
+	bc     *BlockChain             // Canonical block chain
+	engine consensus_engine.Engine // Consensus engine used for block rewards
+}
+
+// newStatePrefetcher initialises a new statePrefetcher.
+func newStatePrefetcher(
+	config *params.ChainConfig, bc *BlockChain, engine consensus_engine.Engine,
+) *statePrefetcher {
+	return &statePrefetcher{
+		config: config,
+		bc:     bc,
+		engine: engine,
+	}
+}
+
+// Prefetch processes the transactions of the blocks one after another on
+// the statedb, ignoring their failures since the state is only an
+// approximation of the parent state of the blocks.
+func (p *statePrefetcher) Prefetch(
+	blocks []*types.Block, statedb *state.DB, cfg vm.Config, interrupt *uint32,
+) {
+	for _, block := range blocks {
+		var (
+			header  = block.Header()
+			gaspool = new(GasPool).AddGas(block.GasLimit())
+			usedGas = new(uint64)
+		)
+		beneficiary, err := p.bc.GetECDSAFromCoinbase(header)
+		if err != nil {
+			return
+		}
+		for i, tx := range block.Transactions() {
+			if atomic.LoadUint32(interrupt) == 1 {
+				return
+			}
+			statedb.Prepare(tx.Hash(), block.Hash(), i)
+			if _, _, _, err := ApplyTransaction(
+				p.config, p.bc, &beneficiary, gaspool, statedb, header, tx, usedGas, cfg,
+			); err != nil {
+				return
+			}
+		}
+		L := len(block.Transactions())
+		for i, tx := range block.StakingTransactions() {
+			if atomic.LoadUint32(interrupt) == 1 {
+				return
+			}
+			statedb.Prepare(tx.Hash(), block.Hash(), i+L)
+			if _, _, err := ApplyStakingTransaction(
+				p.config, p.bc, &beneficiary, gaspool, statedb, header, tx, usedGas, cfg,
+			); err != nil {
+				return
+			}
+		}
+		for _, cx := range block.IncomingReceipts() {
+			if atomic.LoadUint32(interrupt) == 1 {
+				return
+			}
+			if err := ApplyIncomingReceipt(p.config, statedb, header, cx); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -18,6 +18,7 @@ package core
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -31,6 +32,7 @@ import (
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/governance"
 	"github.com/harmony-one/harmony/staking/slash"
 	staking "github.com/harmony-one/harmony/staking/types"
 	"github.com/pkg/errors"
@@ -78,6 +80,7 @@ func (p *StateProcessor) Process(
 		header   = block.Header()
 		allLogs  []*types.Log
 		gp       = new(GasPool).AddGas(block.GasLimit())
+		start    = time.Now()
 	)
 
 	beneficiary, err := p.bc.GetECDSAFromCoinbase(header)
@@ -125,6 +128,15 @@ func (p *StateProcessor) Process(
 		}
 	}
 
+	governance.ApplyUpdates(p.config, statedb, header, block.Transactions(), receipts)
+
+	// the balances of a shard merged at the next epoch move to its destination shard
+	reshardCXs, err := ApplyReshardingRedistribution(p.config, statedb, header)
+	if err != nil {
+		return nil, nil, nil, 0, nil, err
+	}
+	outcxs = append(outcxs, reshardCXs...)
+
 	slashes := slash.Records{}
 	if s := header.Slashes(); len(s) > 0 {
 		if err := rlp.DecodeBytes(s, &slashes); err != nil {
@@ -134,6 +146,8 @@ func (p *StateProcessor) Process(
 		}
 	}
 
+	start = observeStage(stageExecution, start)
+
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	_, payout, err := p.engine.Finalize(
 		p.bc, header, statedb, block.Transactions(),
@@ -142,6 +156,7 @@ func (p *StateProcessor) Process(
 	if err != nil {
 		return nil, nil, nil, 0, nil, errors.New("[Process] Cannot finalize block")
 	}
+	observeStage(stageRewards, start)
 
 	return receipts, outcxs, allLogs, *usedGas, payout, nil
 }
@@ -46,6 +46,9 @@ var (
 	errNegativeAmount              = errors.New("amount can not be negative")
 	errDupIdentity                 = errors.New("validator identity exists")
 	errDupBlsKey                   = errors.New("BLS key exists")
+	errShardPreferenceNotEnabled   = errors.New("shard preference is not enabled yet")
+	errBindSlotKeyNotEnabled       = errors.New("slot key binding is not enabled yet")
+	errSlotKeyNotFound             = errors.New("slot key not found")
 )
 
 /*
@@ -374,6 +377,15 @@ func (st *StateTransition) StakingTransitionDb() (usedGas uint64, err error) {
 				BlockNumber: st.evm.BlockNumber.Uint64(),
 			})
 		}
+	case types.BindSlotKey:
+		stkMsg := &staking.BindSlotKey{}
+		if err = rlp.DecodeBytes(msg.Data(), stkMsg); err != nil {
+			return 0, err
+		}
+		if msg.From() != stkMsg.ValidatorAddress {
+			return 0, errInvalidSigner
+		}
+		err = st.verifyAndApplyBindSlotKeyTx(stkMsg)
 	default:
 		return 0, staking.ErrInvalidStakingKind
 	}
@@ -406,15 +418,35 @@ func (st *StateTransition) verifyAndApplyCreateValidatorTx(
 func (st *StateTransition) verifyAndApplyEditValidatorTx(
 	editValidator *staking.EditValidator, blockNum *big.Int,
 ) error {
+	if len(editValidator.ShardPreference) > 0 &&
+		!st.evm.ChainConfig().IsShardPreference(st.evm.EpochNumber) {
+		return errShardPreferenceNotEnabled
+	}
 	wrapper, err := VerifyAndEditValidatorFromMsg(
 		st.state, st.bc, st.evm.EpochNumber, blockNum, editValidator,
 	)
 	if err != nil {
 		return err
 	}
+	if pref, ok, _ := editValidator.ShardPreferenceUpdate(); ok {
+		st.state.SetState(
+			wrapper.Address, staking.ShardPreferenceKey, common.BigToHash(big.NewInt(int64(pref))),
+		)
+	}
 	return st.state.UpdateValidatorWrapper(wrapper.Address, wrapper)
 }
 
+func (st *StateTransition) verifyAndApplyBindSlotKeyTx(bindSlotKey *staking.BindSlotKey) error {
+	if !st.evm.ChainConfig().IsBLS12381(st.evm.EpochNumber) {
+		return errBindSlotKeyNotEnabled
+	}
+	if err := VerifyAndBindSlotKeyFromMsg(st.state, bindSlotKey); err != nil {
+		return err
+	}
+	WriteBoundSlotKey(st.state, bindSlotKey)
+	return nil
+}
+
 func (st *StateTransition) verifyAndApplyDelegateTx(delegate *staking.Delegate) error {
 	wrapper, balanceToBeDeducted, err := VerifyAndDelegateFromMsg(st.state, delegate)
 	if err != nil {
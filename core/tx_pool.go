@@ -98,6 +98,10 @@ var (
 
 	// ErrBlacklistTo is returned if a transaction's to/destination address is blacklisted
 	ErrBlacklistTo = errors.New("`to` address of transaction in blacklist")
+
+	// ErrStakingDirectiveRejected is returned if the type of a staking transaction
+	// is not accepted by the staking transaction policy of the node
+	ErrStakingDirectiveRejected = errors.New("staking transaction type not accepted by this node")
 )
 
 var (
@@ -162,6 +166,8 @@ type TxPoolConfig struct {
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 
 	Blacklist map[common.Address]struct{} // Set of accounts that cannot be a part of any transaction
+
+	StakingDirectives map[staking.Directive]struct{} // Set of staking transaction types accepted and relayed, all of them if nil
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -229,6 +235,7 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	stakingFeed  event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -545,6 +552,26 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeNewStakingTxsEvent registers a subscription of NewStakingTxsEvent
+// and starts sending event to the given channel.
+func (pool *TxPool) SubscribeNewStakingTxsEvent(ch chan<- NewStakingTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.stakingFeed.Subscribe(ch))
+}
+
+// notifyPendingStakingTxs posts the staking transactions among txs, which
+// just became pending, to the staking transaction subscribers.
+func (pool *TxPool) notifyPendingStakingTxs(txs types.PoolTransactions) {
+	stakingTxs := []*staking.StakingTransaction{}
+	for _, tx := range txs {
+		if stakingTx, ok := tx.(*staking.StakingTransaction); ok {
+			stakingTxs = append(stakingTxs, stakingTx)
+		}
+	}
+	if len(stakingTxs) > 0 {
+		go pool.stakingFeed.Send(NewStakingTxsEvent{stakingTxs})
+	}
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -711,6 +738,14 @@ func (pool *TxPool) validateTx(tx types.PoolTransaction, local bool) error {
 			return ErrBlacklistTo
 		}
 	}
+	// Make sure the node accepts the type of staking transaction
+	if stakingTx, ok := tx.(*staking.StakingTransaction); ok && pool.config.StakingDirectives != nil {
+		if _, accepted := pool.config.StakingDirectives[stakingTx.StakingType()]; !accepted {
+			return errors.WithMessagef(
+				ErrStakingDirectiveRejected, "staking transaction type is %s", stakingTx.StakingType(),
+			)
+		}
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
@@ -798,6 +833,10 @@ func (pool *TxPool) validateStakingTx(tx *staking.StakingTransaction) error {
 		if from != stkMsg.ValidatorAddress {
 			return errors.WithMessagef(ErrInvalidSender, "staking transaction sender is %s", b32)
 		}
+		if len(stkMsg.ShardPreference) > 0 &&
+			!pool.chainconfig.IsShardPreference(pool.chain.CurrentBlock().Epoch()) {
+			return errShardPreferenceNotEnabled
+		}
 		chainContext, ok := pool.chain.(ChainContext)
 		if !ok {
 			chainContext = nil // might use testing blockchain, set to nil for verifier to handle.
@@ -867,6 +906,22 @@ func (pool *TxPool) validateStakingTx(tx *staking.StakingTransaction) error {
 
 		_, _, err = VerifyAndCollectRewardsFromDelegation(pool.currentState, delegations)
 		return err
+	case staking.DirectiveBindSlotKey:
+		msg, err := staking.RLPDecodeStakeMsg(tx.Data(), staking.DirectiveBindSlotKey)
+		if err != nil {
+			return err
+		}
+		stkMsg, ok := msg.(*staking.BindSlotKey)
+		if !ok {
+			return ErrInvalidMsgForStakingDirective
+		}
+		if from != stkMsg.ValidatorAddress {
+			return errors.WithMessagef(ErrInvalidSender, "staking transaction sender is %s", b32)
+		}
+		if !pool.chainconfig.IsBLS12381(pool.chain.CurrentBlock().Epoch()) {
+			return errBindSlotKeyNotEnabled
+		}
+		return VerifyAndBindSlotKeyFromMsg(pool.currentState, stkMsg)
 	default:
 		return staking.ErrInvalidStakingKind
 	}
@@ -961,6 +1016,7 @@ func (pool *TxPool) add(tx types.PoolTransaction, local bool) (bool, error) {
 
 		// We've directly injected a replacement transaction, notify subsystems
 		// go pool.txFeed.Send(NewTxsEvent{types.PoolTransactions{tx}})
+		pool.notifyPendingStakingTxs(types.PoolTransactions{tx})
 
 		return old != nil, nil
 	}
@@ -1313,6 +1369,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	//if len(promoted) > 0 {
 	//	go pool.txFeed.Send(NewTxsEvent{promoted})
 	//}
+	pool.notifyPendingStakingTxs(promoted)
 	// If the pending limit is overflown, start equalizing allowances
 	pending := uint64(0)
 	for _, list := range pool.pending {
@@ -42,6 +42,7 @@ import (
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
 	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -416,6 +417,47 @@ func TestCreateValidatorTransaction(t *testing.T) {
 	}
 }
 
+func TestStakingTransactionEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupTxPool()
+	pool.chain = createBlockChain()
+	defer pool.Stop()
+
+	events := make(chan NewStakingTxsEvent, 1)
+	sub := pool.SubscribeNewStakingTxsEvent(events)
+	defer sub.Unsubscribe()
+
+	fromKey, _ := crypto.GenerateKey()
+	stx, err := stakingCreateValidatorTransaction(fromKey)
+	if err != nil {
+		t.Errorf("cannot create new staking transaction, %v\n", err)
+	}
+	stxAddr, _ := stx.SenderAddress()
+	pool.currentState.AddBalance(stxAddr, tenKOnes)
+	pool.currentState.AddBalance(stxAddr, cost)
+
+	goodFromKey, _ := crypto.GenerateKey()
+	tx := transaction(0, 0, 25000, goodFromKey)
+	txAddr, _ := deriveSender(tx)
+	pool.currentState.AddBalance(txAddr, big.NewInt(50100))
+
+	for _, err := range pool.AddRemotes(types.PoolTransactions{stx, tx}) {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	select {
+	case ev := <-events:
+		if len(ev.Txs) != 1 || ev.Txs[0].Hash() != stx.Hash() {
+			t.Errorf("got %d staking transactions, expect only %x", len(ev.Txs), stx.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event for pending staking transaction")
+	}
+}
+
 func TestMixedTransactions(t *testing.T) {
 	t.Parallel()
 
@@ -495,6 +537,36 @@ func TestBlacklistedTransactions(t *testing.T) {
 	DefaultTxPoolConfig.Blacklist = map[common.Address]struct{}{}
 }
 
+func TestStakingDirectivesPolicy(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupTxPool()
+	pool.chain = createBlockChain()
+	defer pool.Stop()
+
+	fromKey, _ := crypto.GenerateKey()
+	stx, err := stakingCreateValidatorTransaction(fromKey)
+	if err != nil {
+		t.Fatalf("cannot create new staking transaction, %v\n", err)
+	}
+	stxAddr, _ := stx.SenderAddress()
+	pool.currentState.AddBalance(stxAddr, tenKOnes)
+	pool.currentState.AddBalance(stxAddr, cost)
+
+	pool.config.StakingDirectives = map[staking.Directive]struct{}{
+		staking.DirectiveDelegate:       {},
+		staking.DirectiveCollectRewards: {},
+	}
+	if err := pool.AddRemotes(types.PoolTransactions{stx})[0]; errors.Cause(err) != ErrStakingDirectiveRejected {
+		t.Error("expected", ErrStakingDirectiveRejected, "got", err)
+	}
+
+	pool.config.StakingDirectives[staking.DirectiveCreateValidator] = struct{}{}
+	if err := pool.AddRemotes(types.PoolTransactions{stx})[0]; err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -60,3 +60,13 @@ type Processor interface {
 		[]*types.Log, uint64, reward.Reader, error,
 	)
 }
+
+// Prefetcher is an interface for pre-caching the state of blocks to be
+// processed.
+//
+// Prefetch executes the transactions of the blocks on a throwaway statedb,
+// loading the trie nodes they touch into the caches, and recovering their
+// senders. It stops when the interrupt is set.
+type Prefetcher interface {
+	Prefetch(blocks []*types.Block, statedb *state.DB, cfg vm.Config, interrupt *uint32)
+}
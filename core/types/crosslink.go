@@ -1,14 +1,17 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"io"
 	"math/big"
 	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/block"
+	"github.com/pkg/errors"
 )
 
 // CrossLink is only used on beacon chain to store the hash links from other shards
@@ -130,17 +133,126 @@ type CrossLinks []CrossLink
 // Sort crosslinks by shardID and then tie break by blockNum then by viewID
 func (cls CrossLinks) Sort() {
 	sort.Slice(cls, func(i, j int) bool {
-		return cls[i].ShardID() < cls[j].ShardID() ||
-			(cls[i].ShardID() == cls[j].ShardID() && cls[i].Number().Cmp(cls[j].Number()) < 0) ||
-			(cls[i].ShardID() == cls[j].ShardID() && cls[i].Number() == cls[j].Number() && cls[i].ViewID().Cmp(cls[j].ViewID()) < 0)
+		return crossLinkLess(&cls[i], &cls[j])
 	})
 }
 
 // IsSorted checks whether the cross links are sorted
 func (cls CrossLinks) IsSorted() bool {
 	return sort.SliceIsSorted(cls, func(i, j int) bool {
-		return cls[i].ShardID() < cls[j].ShardID() ||
-			(cls[i].ShardID() == cls[j].ShardID() && cls[i].Number().Cmp(cls[j].Number()) < 0) ||
-			(cls[i].ShardID() == cls[j].ShardID() && cls[i].Number() == cls[j].Number() && cls[i].ViewID().Cmp(cls[j].ViewID()) < 0)
+		return crossLinkLess(&cls[i], &cls[j])
 	})
 }
+
+// crossLinkLess is the order of the sorted cross links
+func crossLinkLess(a, b *CrossLink) bool {
+	return a.ShardID() < b.ShardID() ||
+		(a.ShardID() == b.ShardID() && a.Number().Cmp(b.Number()) < 0) ||
+		(a.ShardID() == b.ShardID() && a.Number() == b.Number() && a.ViewID().Cmp(b.ViewID()) < 0)
+}
+
+var (
+	// ErrCrossLinksNotSorted is returned streaming cross links out of order
+	ErrCrossLinksNotSorted = errors.New("cross links are not sorted")
+)
+
+// CrossLinkDecoder decodes the cross links of an RLP encoded list one at a
+// time, without decoding the whole list.
+type CrossLinkDecoder struct {
+	stream  *rlp.Stream
+	started bool
+	done    bool
+}
+
+// NewCrossLinkDecoder returns the decoder of the RLP encoded list of cross
+// links, such as the cross links of a beacon chain header.
+func NewCrossLinkDecoder(data []byte) *CrossLinkDecoder {
+	return &CrossLinkDecoder{
+		stream: rlp.NewStream(bytes.NewReader(data), uint64(len(data))),
+	}
+}
+
+// Next decodes the next cross link of the list, and returns io.EOF past the
+// last one.
+func (d *CrossLinkDecoder) Next() (*CrossLink, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	if !d.started {
+		if _, err := d.stream.List(); err != nil {
+			return nil, err
+		}
+		d.started = true
+	}
+	cl := &CrossLink{}
+	switch err := d.stream.Decode(cl); err {
+	case nil:
+		return cl, nil
+	case rlp.EOL:
+		if err := d.stream.ListEnd(); err != nil {
+			return nil, err
+		}
+		if _, _, err := d.stream.Kind(); err != io.EOF {
+			return nil, rlp.ErrMoreThanOneValue
+		}
+		d.done = true
+		return nil, io.EOF
+	default:
+		return nil, err
+	}
+}
+
+// crossLinkStreamBuffer is the number of cross links decoded ahead of their
+// processing by StreamCrossLinks
+const crossLinkStreamBuffer = 16
+
+// StreamCrossLinks calls fn with each cross link of the RLP encoded list, in
+// order with its index, while the next ones are decoded ahead, so the decoding
+// overlaps their processing and the list is never decoded as a whole. It
+// returns the first error of the decoding or of fn, the cross links after it
+// not being processed, and ErrCrossLinksNotSorted if sorted is set and a cross
+// link is out of order.
+func StreamCrossLinks(data []byte, sorted bool, fn func(i int, cl *CrossLink) error) error {
+	type decoded struct {
+		cl  *CrossLink
+		err error
+	}
+	links := make(chan decoded, crossLinkStreamBuffer)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(links)
+		decoder := NewCrossLinkDecoder(data)
+		for {
+			cl, err := decoder.Next()
+			if err == io.EOF {
+				return
+			}
+			select {
+			case links <- decoded{cl, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var prev *CrossLink
+	i := 0
+	for link := range links {
+		if link.err != nil {
+			return link.err
+		}
+		if sorted && prev != nil && crossLinkLess(link.cl, prev) {
+			return ErrCrossLinksNotSorted
+		}
+		if err := fn(i, link.cl); err != nil {
+			return err
+		}
+		prev = link.cl
+		i++
+	}
+	return nil
+}
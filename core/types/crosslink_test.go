@@ -0,0 +1,115 @@
+package types
+
+import (
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+func testCrossLinks() CrossLinks {
+	cls := CrossLinks{}
+	for shardID := uint32(1); shardID <= 3; shardID++ {
+		for num := int64(10); num < 14; num++ {
+			cls = append(cls, CrossLink{
+				BlockNumberF: big.NewInt(num),
+				ViewIDF:      big.NewInt(num),
+				BitmapF:      []byte{0xff, byte(num)},
+				ShardIDF:     shardID,
+				EpochF:       big.NewInt(2),
+			})
+		}
+	}
+	return cls
+}
+
+func TestCrossLinkDecoder(t *testing.T) {
+	cls := testCrossLinks()
+	data, err := rlp.EncodeToBytes(cls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := NewCrossLinkDecoder(data)
+	for i := range cls {
+		cl, err := decoder.Next()
+		if err != nil {
+			t.Fatalf("cross link %d: %v", i, err)
+		}
+		if cl.ShardID() != cls[i].ShardID() || cl.BlockNum() != cls[i].BlockNum() ||
+			string(cl.Bitmap()) != string(cls[i].Bitmap()) {
+			t.Errorf("cross link %d decoded as %v", i, cl)
+		}
+	}
+	for range []int{0, 1} {
+		if _, err := decoder.Next(); err != io.EOF {
+			t.Errorf("got error %v past the last cross link", err)
+		}
+	}
+
+	// the trailing data is found past the last cross link
+	decoder = NewCrossLinkDecoder(append(data, 0x80))
+	for range cls {
+		if _, err := decoder.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := decoder.Next(); err != rlp.ErrMoreThanOneValue {
+		t.Errorf("got error %v decoding trailing data", err)
+	}
+	empty, _ := rlp.EncodeToBytes(CrossLinks{})
+	if _, err := NewCrossLinkDecoder(empty).Next(); err != io.EOF {
+		t.Errorf("got error %v decoding no cross links", err)
+	}
+}
+
+func TestStreamCrossLinks(t *testing.T) {
+	cls := testCrossLinks()
+	data, _ := rlp.EncodeToBytes(cls)
+	n := 0
+	if err := StreamCrossLinks(data, true, func(i int, cl *CrossLink) error {
+		if i != n || cl.BlockNum() != cls[i].BlockNum() || cl.ShardID() != cls[i].ShardID() {
+			t.Errorf("cross link %d streamed as %d: %v", n, i, cl)
+		}
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n != len(cls) {
+		t.Errorf("streamed %d of %d cross links", n, len(cls))
+	}
+
+	// the cross links before the first one out of order are processed
+	cls[5], cls[6] = cls[6], cls[5]
+	data, _ = rlp.EncodeToBytes(cls)
+	n = 0
+	err := StreamCrossLinks(data, true, func(int, *CrossLink) error { n++; return nil })
+	if err != ErrCrossLinksNotSorted || n != 6 {
+		t.Errorf("got error %v after %d cross links out of order", err, n)
+	}
+	if err := StreamCrossLinks(data, false, func(int, *CrossLink) error { return nil }); err != nil {
+		t.Errorf("got error %v streaming unsorted cross links", err)
+	}
+
+	// an error of fn stops the stream
+	stop := errors.New("stop")
+	n = 0
+	err = StreamCrossLinks(data, false, func(i int, _ *CrossLink) error {
+		n++
+		if i == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 3 {
+		t.Errorf("got error %v after %d cross links", err, n)
+	}
+
+	if err := StreamCrossLinks(data[:len(data)-3], false, func(int, *CrossLink) error {
+		return nil
+	}); err == nil {
+		t.Error("truncated cross links streamed")
+	}
+}
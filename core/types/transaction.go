@@ -54,12 +54,14 @@ const (
 	Delegate
 	Undelegate
 	CollectRewards
+	BindSlotKey
 )
 
 // StakingTypeMap is the map from staking type to transactionType
 var StakingTypeMap = map[staking.Directive]TransactionType{staking.DirectiveCreateValidator: StakeCreateVal,
 	staking.DirectiveEditValidator: StakeEditVal, staking.DirectiveDelegate: Delegate,
-	staking.DirectiveUndelegate: Undelegate, staking.DirectiveCollectRewards: CollectRewards}
+	staking.DirectiveUndelegate: Undelegate, staking.DirectiveCollectRewards: CollectRewards,
+	staking.DirectiveBindSlotKey: BindSlotKey}
 
 // Transaction struct.
 type Transaction struct {
@@ -88,6 +90,8 @@ func (txType TransactionType) String() string {
 		return "Undelegate"
 	} else if txType == CollectRewards {
 		return "CollectRewards"
+	} else if txType == BindSlotKey {
+		return "BindSlotKey"
 	}
 	return "Unknown"
 }
@@ -358,3 +358,37 @@ func (c *bn256Pairing) Run(input []byte) ([]byte, error) {
 	}
 	return false32Byte, nil
 }
+
+// RandomnessAddress is the address of the randomness precompile, activated
+// by the randomness fork, which returns the VRF randomness of a recent block
+var RandomnessAddress = common.BytesToAddress([]byte{0xfe})
+
+// RandomnessSize is the size of the output of the randomness precompile, the
+// 32 bytes of the VRF randomness followed by the 96 bytes of its BLS proof
+const RandomnessSize = 128
+
+// randomness implemented as a native contract reading the VRF of the block
+// of the number of its input, which must be one of the 256 blocks before
+// the current one, like for the BLOCKHASH op code. Its output is zero for the
+// other blocks and the blocks without a VRF.
+type randomness struct {
+	getVRF GetVRFFunc
+	number *big.Int
+}
+
+func (c *randomness) RequiredGas(input []byte) uint64 {
+	return params.RandomnessGas
+}
+
+func (c *randomness) Run(input []byte) ([]byte, error) {
+	output := make([]byte, RandomnessSize)
+	num := new(big.Int).SetBytes(getData(input, 0, 32))
+	n := new(big.Int).Sub(c.number, common.Big257)
+	if c.getVRF == nil || num.Cmp(n) <= 0 || num.Cmp(c.number) >= 0 {
+		return output, nil
+	}
+	if vrf := c.getVRF(num.Uint64()); len(vrf) == RandomnessSize {
+		copy(output, vrf)
+	}
+	return output, nil
+}
@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"testing"
@@ -480,3 +481,35 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+func TestPrecompiledRandomness(t *testing.T) {
+	vrf := make([]byte, RandomnessSize)
+	vrf[0], vrf[RandomnessSize-1] = 1, 2
+	vrfs := map[uint64][]byte{44: vrf, 299: vrf, 300: vrf}
+	p := &randomness{
+		getVRF: func(n uint64) []byte { return vrfs[n] },
+		number: big.NewInt(300),
+	}
+	zero := make([]byte, RandomnessSize)
+	tests := []struct {
+		number   int64
+		expected []byte
+	}{
+		{299, vrf},
+		{44, vrf},
+		{298, zero}, // no VRF
+		{43, zero},  // more than 256 blocks before
+		{300, zero}, // current block
+		{301, zero},
+	}
+	for _, test := range tests {
+		input := common.LeftPadBytes(big.NewInt(test.number).Bytes(), 32)
+		res, err := p.Run(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(res, test.expected) {
+			t.Errorf("block %d: got %x, expect %x", test.number, res, test.expected)
+		}
+	}
+}
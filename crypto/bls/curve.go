@@ -0,0 +1,90 @@
+package bls
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// CurveID identifies the BLS signature scheme of a key
+type CurveID byte
+
+const (
+	// CurveMCL is the BLS12-381 scheme of the mcl library, with its own
+	// serialization and hash to curve, of the keys of the committees
+	CurveMCL CurveID = iota
+	// CurveBLS12381 is the BLS12-381 scheme of the IETF BLS signature draft,
+	// ciphersuite BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_, the scheme of
+	// the eth2 keys
+	CurveBLS12381
+)
+
+const (
+	// PublicKeySize is the size of the serialized public keys of the curves,
+	// points of G1
+	PublicKeySize = 48
+	// SignatureSize is the size of the serialized signatures of the curves,
+	// points of G2
+	SignatureSize = 96
+)
+
+var (
+	// ErrUnknownCurve is returned for a curve of an unknown identifier
+	ErrUnknownCurve = errors.New("unknown BLS curve")
+	// ErrInvalidSignature is returned verifying a signature not of the message
+	// by the public key
+	ErrInvalidSignature = errors.New("invalid BLS signature")
+
+	curves = map[CurveID]Curve{
+		CurveMCL:      mclCurve{},
+		CurveBLS12381: bls12381Curve{},
+	}
+)
+
+func (id CurveID) String() string {
+	if curve, ok := curves[id]; ok {
+		return curve.String()
+	}
+	return fmt.Sprintf("Curve %d", byte(id))
+}
+
+// Curve is a BLS signature scheme with the public keys in G1 and the
+// signatures in G2, working on serialized keys and signatures so the keys of
+// the curves can be handled alike.
+type Curve interface {
+	// ID returns the identifier of the curve
+	ID() CurveID
+	// String returns the name of the curve
+	String() string
+	// RandSecretKey returns a random secret key
+	RandSecretKey() (CurveSecretKey, error)
+	// SecretKeyFromBytes returns the secret key of its serialization
+	SecretKeyFromBytes(data []byte) (CurveSecretKey, error)
+	// Verify checks the signature of the message by the public key, and
+	// returns ErrInvalidSignature if it is not the signature of the message
+	Verify(pubKey, sig, msg []byte) error
+	// AggregatePublicKeys returns the sum of the public keys
+	AggregatePublicKeys(pubKeys [][]byte) ([]byte, error)
+	// AggregateSignatures returns the sum of the signatures
+	AggregateSignatures(sigs [][]byte) ([]byte, error)
+}
+
+// CurveSecretKey is the secret key of a curve
+type CurveSecretKey interface {
+	// Curve returns the curve of the key
+	Curve() Curve
+	// Bytes returns the serialization of the key
+	Bytes() []byte
+	// PublicKey returns the serialized public key of the key
+	PublicKey() []byte
+	// Sign returns the serialized signature of the message
+	Sign(msg []byte) []byte
+}
+
+// CurveByID returns the curve of the identifier
+func CurveByID(id CurveID) (Curve, error) {
+	if curve, ok := curves[id]; ok {
+		return curve, nil
+	}
+	return nil, errors.Wrapf(ErrUnknownCurve, "curve id %d", id)
+}
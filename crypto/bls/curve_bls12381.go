@@ -0,0 +1,135 @@
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/pkg/errors"
+)
+
+const (
+	// bls12381SecretKeySize is the size of the big endian secret keys
+	bls12381SecretKeySize = 32
+	// bls12381SignatureDST is the domain separation tag of the hash of the
+	// messages to G2 of the proof of possession ciphersuite
+	bls12381SignatureDST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+)
+
+var errInvalidBLS12381SecretKey = errors.New("invalid BLS12-381 secret key")
+
+// bls12381Curve is the curve of the IETF BLS signature draft, with the zcash
+// serialization of the compressed points.
+type bls12381Curve struct{}
+
+type bls12381SecretKey struct {
+	key *bls12381.Fr
+}
+
+func (bls12381Curve) ID() CurveID {
+	return CurveBLS12381
+}
+
+func (bls12381Curve) String() string {
+	return "bls12-381"
+}
+
+func (bls12381Curve) RandSecretKey() (CurveSecretKey, error) {
+	for {
+		key, err := bls12381.NewFr().Rand(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot generate BLS12-381 secret key")
+		}
+		if !key.IsZero() {
+			return bls12381SecretKey{key}, nil
+		}
+	}
+}
+
+func (bls12381Curve) SecretKeyFromBytes(data []byte) (CurveSecretKey, error) {
+	if len(data) != bls12381SecretKeySize {
+		return nil, errors.Wrapf(errInvalidBLS12381SecretKey, "size %d", len(data))
+	}
+	key := new(big.Int).SetBytes(data)
+	if key.Sign() == 0 || key.Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, errInvalidBLS12381SecretKey
+	}
+	return bls12381SecretKey{bls12381.NewFr().FromBytes(data)}, nil
+}
+
+func (bls12381Curve) Verify(pubKey, sig, msg []byte) error {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	pub, err := g1.FromCompressed(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "cannot deserialize BLS12-381 public key")
+	}
+	// the identity is the public key of no secret key
+	if g1.IsZero(pub) {
+		return errors.New("BLS12-381 public key is the identity")
+	}
+	s, err := g2.FromCompressed(sig)
+	if err != nil {
+		return errors.Wrap(err, "cannot deserialize BLS12-381 signature")
+	}
+	h, err := g2.HashToCurve(msg, []byte(bls12381SignatureDST))
+	if err != nil {
+		return errors.Wrap(err, "cannot hash message to G2")
+	}
+	// e(pub, H(msg)) == e(G1, sig)
+	engine := bls12381.NewEngine()
+	engine.AddPairInv(g1.One(), s)
+	engine.AddPair(pub, h)
+	if !engine.Check() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (bls12381Curve) AggregatePublicKeys(pubKeys [][]byte) ([]byte, error) {
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for i := range pubKeys {
+		pub, err := g1.FromCompressed(pubKeys[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot deserialize BLS12-381 public key %d", i)
+		}
+		g1.Add(agg, agg, pub)
+	}
+	return g1.ToCompressed(agg), nil
+}
+
+func (bls12381Curve) AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for i := range sigs {
+		sig, err := g2.FromCompressed(sigs[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot deserialize BLS12-381 signature %d", i)
+		}
+		g2.Add(agg, agg, sig)
+	}
+	return g2.ToCompressed(agg), nil
+}
+
+func (k bls12381SecretKey) Curve() Curve {
+	return bls12381Curve{}
+}
+
+func (k bls12381SecretKey) Bytes() []byte {
+	return k.key.ToBytes()
+}
+
+func (k bls12381SecretKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(g1.MulScalar(g1.New(), g1.One(), k.key))
+}
+
+func (k bls12381SecretKey) Sign(msg []byte) []byte {
+	g2 := bls12381.NewG2()
+	h, err := g2.HashToCurve(msg, []byte(bls12381SignatureDST))
+	if err != nil {
+		// the hash fails only for a domain separation tag over 255 bytes
+		panic(err)
+	}
+	return g2.ToCompressed(g2.MulScalar(g2.New(), h, k.key))
+}
@@ -0,0 +1,89 @@
+package bls
+
+import (
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/pkg/errors"
+)
+
+// mclCurve is the curve of the keys of the mcl library, the message of its
+// signatures being a hash mapped to G2 as the signatures of the slot keys.
+type mclCurve struct{}
+
+type mclSecretKey struct {
+	key *bls.SecretKey
+}
+
+func (mclCurve) ID() CurveID {
+	return CurveMCL
+}
+
+func (mclCurve) String() string {
+	return "mcl-bls12-381"
+}
+
+func (mclCurve) RandSecretKey() (CurveSecretKey, error) {
+	return mclSecretKey{RandPrivateKey()}, nil
+}
+
+func (mclCurve) SecretKeyFromBytes(data []byte) (CurveSecretKey, error) {
+	key := &bls.SecretKey{}
+	if err := key.Deserialize(data); err != nil {
+		return nil, errors.Wrap(err, "cannot deserialize mcl secret key")
+	}
+	return mclSecretKey{key}, nil
+}
+
+func (mclCurve) Verify(pubKey, sig, msg []byte) error {
+	pub := &bls.PublicKey{}
+	if err := pub.Deserialize(pubKey); err != nil {
+		return errors.Wrap(err, "cannot deserialize mcl public key")
+	}
+	s := &bls.Sign{}
+	if err := s.Deserialize(sig); err != nil {
+		return errors.Wrap(err, "cannot deserialize mcl signature")
+	}
+	if !s.VerifyHash(pub, msg) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (mclCurve) AggregatePublicKeys(pubKeys [][]byte) ([]byte, error) {
+	agg := &bls.PublicKey{}
+	for i := range pubKeys {
+		pub := &bls.PublicKey{}
+		if err := pub.Deserialize(pubKeys[i]); err != nil {
+			return nil, errors.Wrapf(err, "cannot deserialize mcl public key %d", i)
+		}
+		agg.Add(pub)
+	}
+	return agg.Serialize(), nil
+}
+
+func (mclCurve) AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	agg := &bls.Sign{}
+	for i := range sigs {
+		sig := &bls.Sign{}
+		if err := sig.Deserialize(sigs[i]); err != nil {
+			return nil, errors.Wrapf(err, "cannot deserialize mcl signature %d", i)
+		}
+		agg.Add(sig)
+	}
+	return agg.Serialize(), nil
+}
+
+func (k mclSecretKey) Curve() Curve {
+	return mclCurve{}
+}
+
+func (k mclSecretKey) Bytes() []byte {
+	return k.key.Serialize()
+}
+
+func (k mclSecretKey) PublicKey() []byte {
+	return k.key.GetPublicKey().Serialize()
+}
+
+func (k mclSecretKey) Sign(msg []byte) []byte {
+	return k.key.SignHash(msg).Serialize()
+}
@@ -0,0 +1,96 @@
+package bls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestCurves(t *testing.T) {
+	msg := crypto.Keccak256([]byte("harmony-one"))
+	other := crypto.Keccak256([]byte("other"))
+	for _, id := range []CurveID{CurveMCL, CurveBLS12381} {
+		curve, err := CurveByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := curve.RandSecretKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, sig := key.PublicKey(), key.Sign(msg)
+		if len(pub) != PublicKeySize || len(sig) != SignatureSize {
+			t.Errorf("%s: got public key of %d bytes, signature of %d bytes", curve, len(pub), len(sig))
+		}
+		if err := curve.Verify(pub, sig, msg); err != nil {
+			t.Errorf("%s: %v", curve, err)
+		}
+		if err := curve.Verify(pub, sig, other); err != ErrInvalidSignature {
+			t.Errorf("%s: got error %v verifying the signature of another message", curve, err)
+		}
+
+		restored, err := curve.SecretKeyFromBytes(key.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(restored.PublicKey(), pub) {
+			t.Errorf("%s: secret key changed by its serialization", curve)
+		}
+
+		// the aggregate signature of a message is verified by the aggregate key
+		key2, _ := curve.RandSecretKey()
+		aggPub, err := curve.AggregatePublicKeys([][]byte{pub, key2.PublicKey()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		aggSig, err := curve.AggregateSignatures([][]byte{sig, key2.Sign(msg)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := curve.Verify(aggPub, aggSig, msg); err != nil {
+			t.Errorf("%s: aggregate signature: %v", curve, err)
+		}
+		if err := curve.Verify(aggPub, sig, msg); err != ErrInvalidSignature {
+			t.Errorf("%s: got error %v verifying a signature by the aggregate key", curve, err)
+		}
+	}
+	if _, err := CurveByID(CurveID(9)); errors.Cause(err) != ErrUnknownCurve {
+		t.Errorf("got error %v of an unknown curve", err)
+	}
+}
+
+func TestBLS12381Vector(t *testing.T) {
+	// a sign test vector of the eth2 BLS tests
+	sk, _ := hex.DecodeString("263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3")
+	pub, _ := hex.DecodeString("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	sig, _ := hex.DecodeString("91347bccf740d859038fcdcaf233eeceb2a436bcaaee9b2aa3bfb70efe29dfb2677562ccbea1c8e061fb9971b0753c240622fab78489ce96768259fc01360346da5b9f579e5da0d941e4c6ba18a0e64906082375394f337fa1af2b7127b0d121")
+	msg := bytes.Repeat([]byte{0xab}, 32)
+
+	key, err := bls12381Curve{}.SecretKeyFromBytes(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key.PublicKey(), pub) {
+		t.Errorf("got public key %x", key.PublicKey())
+	}
+	if !bytes.Equal(key.Sign(msg), sig) {
+		t.Errorf("got signature %x", key.Sign(msg))
+	}
+	if err := (bls12381Curve{}).Verify(pub, sig, msg); err != nil {
+		t.Error(err)
+	}
+
+	for _, bad := range [][]byte{nil, make([]byte, 32), bytes.Repeat([]byte{0xff}, 32)} {
+		if _, err := (bls12381Curve{}).SecretKeyFromBytes(bad); err == nil {
+			t.Errorf("secret key %x accepted", bad)
+		}
+	}
+	identity := make([]byte, PublicKeySize)
+	identity[0] = 0xc0
+	if err := (bls12381Curve{}).Verify(identity, sig, msg); err == nil {
+		t.Error("identity public key accepted")
+	}
+}
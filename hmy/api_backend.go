@@ -16,12 +16,14 @@ import (
 	"github.com/harmony-one/harmony/api/proto"
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
 	internal_bls "github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/chain"
 	internal_common "github.com/harmony-one/harmony/internal/common"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	commonRPC "github.com/harmony-one/harmony/internal/hmyapi/common"
@@ -50,7 +52,11 @@ type APIBackend struct {
 		BlockHeight  int64
 		TotalStaking *big.Int
 	}
-	apiCache singleflight.Group
+	apiCache      singleflight.Group
+	responseCache *commonRPC.ResponseCache
+	// upstreamState reads the state pruned locally from an upstream node,
+	// nil unless the node is a light serving node
+	upstreamState state.Database
 }
 
 // SingleFlightRequest ...
@@ -67,6 +73,28 @@ func (b *APIBackend) SingleFlightForgetKey(key string) {
 	b.apiCache.Forget(key)
 }
 
+// CachedResponse returns the cached response for key if there is one,
+// otherwise it computes the response with fn and caches it when the
+// block blockNum it is derived from is final.
+func (b *APIBackend) CachedResponse(
+	key string, blockNum uint64, fn func() (interface{}, error),
+) (interface{}, error) {
+	canonicalHash := func(num uint64) common.Hash {
+		return rawdb.ReadCanonicalHash(b.hmy.chainDb, num)
+	}
+	if res, ok := b.responseCache.Get(key, canonicalHash); ok {
+		return res, nil
+	}
+	res, err := fn()
+	if err != nil || res == nil {
+		return res, err
+	}
+	b.responseCache.Add(
+		key, blockNum, canonicalHash(blockNum), b.CurrentBlock().NumberU64(), res,
+	)
+	return res, nil
+}
+
 // ChainDb ...
 func (b *APIBackend) ChainDb() ethdb.Database {
 	return b.hmy.chainDb
@@ -107,6 +135,10 @@ func (b *APIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.Blo
 		return nil, nil, err
 	}
 	stateDb, err := b.hmy.blockchain.StateAt(header.Root())
+	if err != nil && b.upstreamState != nil {
+		// the state is pruned, read it from the upstream node
+		stateDb, err = state.New(header.Root(), b.upstreamState)
+	}
 	return stateDb, header, err
 }
 
@@ -209,6 +241,11 @@ func (b *APIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subs
 	return b.hmy.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+// SubscribeNewStakingTxsEvent subscribes new staking tx event.
+func (b *APIBackend) SubscribeNewStakingTxsEvent(ch chan<- core.NewStakingTxsEvent) event.Subscription {
+	return b.hmy.TxPool().SubscribeNewStakingTxsEvent(ch)
+}
+
 // SubscribeChainEvent subcribes chain event.
 // TODO: this is not implemented or verified yet for harmony.
 func (b *APIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
@@ -447,7 +484,7 @@ func (b *APIBackend) GetValidatorInformation(
 	}
 
 	computed := availability.ComputeCurrentSigning(
-		snapshot.Validator, wrapper,
+		snapshot.Validator, wrapper, bc.Config().AvailabilityThreshold(now),
 	)
 	beaconChainBlocks := uint64(
 		b.hmy.BeaconChain().CurrentBlock().Header().Number().Int64(),
@@ -563,6 +600,11 @@ func (b *APIBackend) GetMedianRawStakeSnapshot() (
 	return res.(*committee.CompletedEPoSRound), nil
 }
 
+// GetElectionAudit returns the audit log of the election of the epoch.
+func (b *APIBackend) GetElectionAudit(epoch uint64) (*committee.ElectionAudit, error) {
+	return b.hmy.BlockChain().ReadElectionAudit(epoch)
+}
+
 // GetLatestChainHeaders ..
 func (b *APIBackend) GetLatestChainHeaders() *block.HeaderPair {
 	return &block.HeaderPair{
@@ -586,11 +628,14 @@ func (b *APIBackend) GetTotalStakingSnapshot() *big.Int {
 		return b.TotalStakingCache.TotalStaking
 	}
 	stakes := big.NewInt(0)
+	threshold := b.hmy.BlockChain().Config().AvailabilityThreshold(
+		b.hmy.BlockChain().CurrentBlock().Epoch(),
+	)
 	for i := range candidates {
 		snapshot, _ := b.hmy.BlockChain().ReadValidatorSnapshot(candidates[i])
 		validator, _ := b.hmy.BlockChain().ReadValidatorInformation(candidates[i])
 		if !committee.IsEligibleForEPoSAuction(
-			snapshot, validator,
+			snapshot, validator, threshold,
 		) {
 			continue
 		}
@@ -602,6 +647,29 @@ func (b *APIBackend) GetTotalStakingSnapshot() *big.Int {
 	return b.TotalStakingCache.TotalStaking
 }
 
+// GetSupply returns the total, circulating and staked supply of ONE at the
+// current block of the beacon chain
+func (b *APIBackend) GetSupply() (*network.Supply, error) {
+	beaconchain := b.hmy.BeaconChain()
+	blockNr := beaconchain.CurrentBlock().NumberU64()
+	key := fmt.Sprintf("supply-%d", blockNr)
+
+	// delete cache for previous block
+	prevKey := fmt.Sprintf("supply-%d", blockNr-1)
+	b.apiCache.Forget(prevKey)
+
+	res, err := b.SingleFlightRequest(
+		key,
+		func() (interface{}, error) {
+			return network.NewSupply(beaconchain, network.GetSupplySchedule())
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*network.Supply), nil
+}
+
 // GetDelegationsByValidator returns all delegation information of a validator
 func (b *APIBackend) GetDelegationsByValidator(validator common.Address) []*staking.Delegation {
 	wrapper, err := b.hmy.BlockChain().ReadValidatorInformation(validator)
@@ -670,6 +738,11 @@ func (b *APIBackend) GetShardState() (*shard.State, error) {
 	return b.hmy.BlockChain().ReadShardState(b.hmy.BlockChain().CurrentHeader().Epoch())
 }
 
+// GetShardStateOfEpoch returns the shard state of the given epoch
+func (b *APIBackend) GetShardStateOfEpoch(epoch *big.Int) (*shard.State, error) {
+	return b.hmy.BlockChain().ReadShardState(epoch)
+}
+
 // GetCurrentStakingErrorSink ..
 func (b *APIBackend) GetCurrentStakingErrorSink() types.TransactionErrorReports {
 	return b.hmy.nodeAPI.ReportStakingErrorSink()
@@ -685,6 +758,12 @@ func (b *APIBackend) GetPendingCXReceipts() []*types.CXReceiptsProof {
 	return b.hmy.nodeAPI.PendingCXReceipts()
 }
 
+// TraceCrossShardTransaction returns the lifecycle of the cross-shard
+// transaction of the hash, as observed by the chains of the node
+func (b *APIBackend) TraceCrossShardTransaction(hash common.Hash) (*core.CXTrace, error) {
+	return b.hmy.nodeAPI.TraceCrossShardTransaction(hash)
+}
+
 // GetCurrentUtilityMetrics ..
 func (b *APIBackend) GetCurrentUtilityMetrics() (*network.UtilityMetric, error) {
 	return network.NewUtilityMetricSnapshot(b.hmy.BlockChain())
@@ -803,6 +882,38 @@ func (b *APIBackend) GetSuperCommittees() (*quorum.Transition, error) {
 	return res.(*quorum.Transition), err
 }
 
+// GetRecentReorgs returns the recent reorgs of the canonical chain, the
+// latest first.
+func (b *APIBackend) GetRecentReorgs() []core.ReorgEvent {
+	return b.hmy.BlockChain().RecentReorgs()
+}
+
+// GetForkSegments returns the recent chain segments observed competing with
+// the canonical chain, the latest first.
+func (b *APIBackend) GetForkSegments() []core.ForkSegment {
+	return b.hmy.BlockChain().ForkSegments()
+}
+
+// GetForkBlock returns the retained fork block of the hash.
+func (b *APIBackend) GetForkBlock(hash common.Hash) *types.Block {
+	return b.hmy.BlockChain().ReadForkBlock(hash)
+}
+
+// GetPayoutAudit returns the audit log of the block rewards of the node.
+func (b *APIBackend) GetPayoutAudit() (*chain.PayoutAudit, error) {
+	audit := chain.GetPayoutAudit()
+	if audit == nil {
+		return nil, chain.ErrPayoutAuditDisabled
+	}
+	return audit, nil
+}
+
+// EstimateBlockRewards returns the header of the next block of the beacon
+// chain and the block rewards it would pay, without changing the state.
+func (b *APIBackend) EstimateBlockRewards() (*block.Header, reward.Reader, error) {
+	return b.hmy.nodeAPI.EstimateBlockRewards()
+}
+
 // GetCurrentBadBlocks ..
 func (b *APIBackend) GetCurrentBadBlocks() []core.BadBlock {
 	return b.hmy.BlockChain().BadBlocks()
@@ -811,7 +922,7 @@ func (b *APIBackend) GetCurrentBadBlocks() []core.BadBlock {
 // GetLastCrossLinks ..
 func (b *APIBackend) GetLastCrossLinks() ([]*types.CrossLink, error) {
 	crossLinks := []*types.CrossLink{}
-	for i := uint32(1); i < shard.Schedule.InstanceForEpoch(b.CurrentBlock().Epoch()).NumShards(); i++ {
+	for i := uint32(1); i < shard.CrossLinkShards(b.CurrentBlock().Epoch()); i++ {
 		link, err := b.hmy.BlockChain().ReadShardLastCrossLink(i)
 		if err != nil {
 			return nil, err
@@ -822,6 +933,17 @@ func (b *APIBackend) GetLastCrossLinks() ([]*types.CrossLink, error) {
 	return crossLinks, nil
 }
 
+// GetCrossLink returns the crosslink of the shard block, archived or not
+func (b *APIBackend) GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error) {
+	return b.hmy.BlockChain().ReadCrossLink(shardID, blockNum)
+}
+
+// GetCrossLinkStatus returns the status of the crosslink of the shard block
+// on the beacon chain
+func (b *APIBackend) GetCrossLinkStatus(shardID uint32, blockNum uint64) (*core.CrossLinkStatus, error) {
+	return b.hmy.BlockChain().ReadCrossLinkStatus(shardID, blockNum)
+}
+
 // GetNodeMetadata ..
 func (b *APIBackend) GetNodeMetadata() commonRPC.NodeMetadata {
 	cfg := nodeconfig.GetDefaultConfig()
@@ -842,6 +964,8 @@ func (b *APIBackend) GetNodeMetadata() commonRPC.NodeMetadata {
 	}
 	c := commonRPC.C{}
 	c.TotalKnownPeers, c.Connected, c.NotConnected = b.hmy.nodeAPI.PeerConnectivity()
+	c.Reachability = b.hmy.nodeAPI.PeerReachability()
+	c.DroppedByKind, c.DroppedByPeer = b.hmy.nodeAPI.PeerRateLimitDrops()
 
 	return commonRPC.NodeMetadata{
 		blsKeys,
@@ -860,6 +984,11 @@ func (b *APIBackend) GetNodeMetadata() commonRPC.NodeMetadata {
 	}
 }
 
+// GetHealthStatus ..
+func (b *APIBackend) GetHealthStatus() commonRPC.HealthStatus {
+	return b.hmy.nodeAPI.GetHealthStatus()
+}
+
 // GetBlockSigners ..
 func (b *APIBackend) GetBlockSigners(ctx context.Context, blockNr rpc.BlockNumber) (shard.SlotList, *internal_bls.Mask, error) {
 	block, err := b.BlockByNumber(ctx, blockNr)
@@ -889,3 +1018,38 @@ func (b *APIBackend) GetBlockSigners(ctx context.Context, blockNr rpc.BlockNumbe
 	}
 	return committee.Slots, mask, nil
 }
+
+// GetCommitteeCheckpoint returns the committee checkpoint of the epoch.
+func (b *APIBackend) GetCommitteeCheckpoint(epoch uint64) (*core.CommitteeCheckpoint, error) {
+	return b.hmy.BlockChain().ReadCommitteeCheckpoint(epoch)
+}
+
+// GetHeaderCommitSig returns the aggregated commit signature and the signers
+// bitmap of the quorum certificate which finalized the block at blockNr.
+func (b *APIBackend) GetHeaderCommitSig(
+	ctx context.Context, blockNr rpc.BlockNumber,
+) ([]byte, []byte, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header == nil {
+		return nil, nil, errors.Errorf("header not found for block %d", blockNr)
+	}
+	num := header.Number().Uint64()
+	// The certificate of a block is carried by the next block, only the head
+	// block's certificate is kept apart
+	if next := b.hmy.BlockChain().GetHeaderByNumber(num + 1); next != nil {
+		sig := next.LastCommitSignature()
+		return sig[:], next.LastCommitBitmap(), nil
+	}
+	lastCommits, err := b.hmy.BlockChain().ReadCommitSig(num)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "commit signature not found for block %d", num)
+	}
+	if len(lastCommits) < shard.BLSSignatureSizeInBytes {
+		return nil, nil, errors.Errorf("malformed commit signature for block %d", num)
+	}
+	return lastCommits[:shard.BLSSignatureSizeInBytes],
+		lastCommits[shard.BLSSignatureSizeInBytes:], nil
+}
@@ -8,11 +8,19 @@ import (
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	commonRPC "github.com/harmony-one/harmony/internal/hmyapi/common"
 	staking "github.com/harmony-one/harmony/staking/types"
 )
 
+// responseCacheFinalityDepth is the number of blocks below the chain head
+// after which RPC responses derived from a block are cached
+const responseCacheFinalityDepth = 16
+
 // Harmony implements the Harmony full node service.
 type Harmony struct {
 	// Channel for shutting down the service
@@ -51,8 +59,13 @@ type NodeAPI interface {
 	ReportStakingErrorSink() types.TransactionErrorReports
 	ReportPlainErrorSink() types.TransactionErrorReports
 	PendingCXReceipts() []*types.CXReceiptsProof
+	TraceCrossShardTransaction(hash common.Hash) (*core.CXTrace, error)
+	EstimateBlockRewards() (*block.Header, reward.Reader, error)
 	GetNodeBootTime() int64
 	PeerConnectivity() (int, int, int)
+	PeerReachability() string
+	PeerRateLimitDrops() (map[string]uint64, map[string]uint64)
+	GetHealthStatus() commonRPC.HealthStatus
 }
 
 // New creates a new Harmony object (including the
@@ -76,6 +89,9 @@ func New(
 		shardID:       shardID,
 	}
 	hmy.APIBackend = &APIBackend{hmy: hmy,
+		responseCache: commonRPC.NewResponseCache(
+			nodeconfig.GetRPCCacheSize(), responseCacheFinalityDepth,
+		),
 		TotalStakingCache: struct {
 			sync.Mutex
 			BlockHeight  int64
@@ -85,6 +101,13 @@ func New(
 			TotalStaking: big.NewInt(0),
 		},
 	}
+	if url := nodeconfig.GetRPCUpstream(); url != "" {
+		upstreamState, err := newUpstreamState(chainDb, url)
+		if err != nil {
+			return nil, err
+		}
+		hmy.APIBackend.upstreamState = upstreamState
+	}
 	return hmy, nil
 }
 
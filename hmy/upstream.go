@@ -0,0 +1,76 @@
+package hmy
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// upstreamTimeout bounds every request of the upstream node
+	upstreamTimeout = 10 * time.Second
+	// upstreamCacheSize is the memory (MB) caching the trie nodes fetched
+	// from the upstream node
+	upstreamCacheSize = 64
+)
+
+var errUpstreamMismatch = errors.New("upstream node returned data not matching its hash")
+
+// upstreamDB is the chain database of a light serving node. The state trie
+// nodes and contract codes are keyed by their hash, so those pruned locally
+// are fetched from the upstream node and verified by hashing them.
+type upstreamDB struct {
+	ethdb.Database
+	client *rpc.Client
+}
+
+// newUpstreamState returns the state database reading the state pruned from
+// chainDb from the node serving HTTP RPC at url
+func newUpstreamState(chainDb ethdb.Database, url string) (state.Database, error) {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		return nil, err
+	}
+	return state.NewDatabaseWithCache(&upstreamDB{chainDb, client}, upstreamCacheSize), nil
+}
+
+// Get returns the value of the key in the local database, or the state node
+// with the key as hash from the upstream node
+func (db *upstreamDB) Get(key []byte) ([]byte, error) {
+	value, err := db.Database.Get(key)
+	if err == nil || len(key) != common.HashLength {
+		return value, err
+	}
+	hash := common.BytesToHash(key)
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+	defer cancel()
+	var node hexutil.Bytes
+	if err := db.client.CallContext(ctx, &node, "hmyv2_getStateNode", hash); err != nil {
+		utils.Logger().Warn().Err(err).Str("hash", hash.Hex()).Msg("[upstream] cannot fetch state node")
+		return nil, errors.Wrapf(err, "cannot fetch state node %x from upstream", hash)
+	}
+	if crypto.Keccak256Hash(node) != hash {
+		return nil, errUpstreamMismatch
+	}
+	return node, nil
+}
+
+// Has returns whether the key is in the local database or is the hash of a
+// state node of the upstream node
+func (db *upstreamDB) Has(key []byte) (bool, error) {
+	if has, err := db.Database.Has(key); has || err != nil {
+		return has, err
+	}
+	if _, err := db.Get(key); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
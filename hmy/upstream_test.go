@@ -0,0 +1,66 @@
+package hmy
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core/state"
+)
+
+// StateNodeServer serves the state nodes of a database like an upstream node
+type StateNodeServer struct {
+	db     ethdb.Database
+	tamper bool
+}
+
+func (u *StateNodeServer) GetStateNode(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	node, err := u.db.Get(hash[:])
+	if err == nil && u.tamper {
+		node = append(node, 0)
+	}
+	return node, err
+}
+
+func newTestUpstreamState(t *testing.T, upstream *StateNodeServer) state.Database {
+	server := rpc.NewServer()
+	if err := server.RegisterName("hmyv2", upstream); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	return state.NewDatabase(&upstreamDB{ethdb.NewMemDatabase(), client})
+}
+
+func TestUpstreamState(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	addr := common.HexToAddress("0x1234")
+	statedb.AddBalance(addr, big.NewInt(42))
+	statedb.SetCode(addr, []byte{0x60, 0x00})
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statedb.Database().TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := state.New(root, newTestUpstreamState(t, &StateNodeServer{db: db}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := pruned.GetBalance(addr); balance.Int64() != 42 {
+		t.Errorf("got balance %v, expect 42", balance)
+	}
+	if code := pruned.GetCode(addr); len(code) != 2 {
+		t.Errorf("got code %x", code)
+	}
+
+	if _, err := state.New(root, newTestUpstreamState(t, &StateNodeServer{db: db, tamper: true})); err == nil {
+		t.Error("read the state from tampered state nodes")
+	}
+}
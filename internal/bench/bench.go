@@ -0,0 +1,195 @@
+// Package bench runs the standardized workloads of the harmony bench command
+// on the chain of a fixture, and measures their throughput, for the
+// performance of the releases to be compared on the hardware of the
+// operators.
+package bench
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// Workloads of the benchmark
+const (
+	// Import inserts blocks into the chain, verifying and executing them
+	Import = "import"
+	// Rewards computes the block rewards of the beacon chain blocks, over
+	// the cross-links they carry
+	Rewards = "rewards"
+	// Replay executes the blocks again on the state of their parents
+	Replay = "replay"
+)
+
+// Workloads are the workloads of the benchmark, in the order they are run, the
+// import last since it rewrites the chain
+var Workloads = []string{Replay, Rewards, Import}
+
+// Result is the measure of a workload
+type Result struct {
+	Workload   string        `json:"workload"`
+	Blocks     int           `json:"blocks"`
+	Txs        int           `json:"txs"`
+	Gas        uint64        `json:"gas"`
+	CrossLinks int           `json:"crossLinks"`
+	Elapsed    time.Duration `json:"elapsed"`
+}
+
+// BlocksPerSecond returns the throughput of the workload in blocks
+func (r *Result) BlocksPerSecond() float64 {
+	return perSecond(float64(r.Blocks), r.Elapsed)
+}
+
+// TxsPerSecond returns the throughput of the workload in transactions
+func (r *Result) TxsPerSecond() float64 {
+	return perSecond(float64(r.Txs), r.Elapsed)
+}
+
+// MGasPerSecond returns the throughput of the workload in millions of gas
+func (r *Result) MGasPerSecond() float64 {
+	return perSecond(float64(r.Gas)/1e6, r.Elapsed)
+}
+
+// CrossLinksPerSecond returns the throughput of the workload in cross-links
+func (r *Result) CrossLinksPerSecond() float64 {
+	return perSecond(float64(r.CrossLinks), r.Elapsed)
+}
+
+func perSecond(count float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return count / elapsed.Seconds()
+}
+
+// add counts the block into the result
+func (r *Result) add(block *types.Block) {
+	r.Blocks++
+	r.Txs += len(block.Transactions()) + len(block.StakingTransactions())
+	r.Gas += block.GasUsed()
+}
+
+// Blocks returns the canonical blocks of the chain from the number to its
+// head block, the range of the workloads.
+func Blocks(bc *core.BlockChain, from uint64) (types.Blocks, error) {
+	head := bc.CurrentBlock().NumberU64()
+	if from == 0 || from > head {
+		return nil, errors.Errorf("no blocks from %d, the head block is %d", from, head)
+	}
+	blocks := make(types.Blocks, 0, head-from+1)
+	for n := from; n <= head; n++ {
+		block := bc.GetBlockByNumber(n)
+		if block == nil {
+			return nil, errors.Errorf("block %d is missing", n)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// parentState returns the state of the parent of the block
+func parentState(bc *core.BlockChain, block *types.Block) (*state.DB, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, errors.Errorf("parent of block %d is missing", block.NumberU64())
+	}
+	statedb, err := bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, errors.Wrapf(err, "state of block %d is missing, the fixture must be archival", parent.Number())
+	}
+	return statedb, nil
+}
+
+// RunReplay executes the blocks again, each on the state the previous one
+// left, starting from the state of the parent of the first one, and checks
+// the state roots of the blocks. Nothing is written to the chain.
+func RunReplay(bc *core.BlockChain, blocks types.Blocks) (*Result, error) {
+	result := &Result{Workload: Replay}
+	if len(blocks) == 0 {
+		return result, nil
+	}
+	statedb, err := parentState(bc, blocks[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		start := time.Now()
+		if _, _, _, _, _, err := bc.Processor().Process(block, statedb, vm.Config{}); err != nil {
+			return nil, errors.Wrapf(err, "cannot execute block %d", block.NumberU64())
+		}
+		root, err := statedb.Commit(bc.Config().IsS3(block.Epoch()))
+		if err != nil {
+			return nil, err
+		}
+		result.Elapsed += time.Since(start)
+		if root != block.Root() {
+			return nil, errors.Errorf(
+				"block %d executed to state root %x, its header has %x", block.NumberU64(), root, block.Root(),
+			)
+		}
+		if statedb, err = state.New(root, bc.StateCache()); err != nil {
+			return nil, err
+		}
+		result.add(block)
+	}
+	return result, nil
+}
+
+// RunRewards computes the rewards of the beacon chain blocks carrying
+// cross-links, each on the state of its parent. Nothing is written to the
+// chain.
+func RunRewards(bc *core.BlockChain, blocks types.Blocks) (*Result, error) {
+	if bc.ShardID() != shard.BeaconChainShardID {
+		return nil, errors.New("the rewards are computed over the cross-links of the beacon chain")
+	}
+	result := &Result{Workload: Rewards}
+	for _, block := range blocks {
+		if len(block.Header().CrossLinks()) == 0 {
+			continue
+		}
+		crossLinks := types.CrossLinks{}
+		if err := rlp.DecodeBytes(block.Header().CrossLinks(), &crossLinks); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode the cross-links of block %d", block.NumberU64())
+		}
+		statedb, err := parentState(bc, block)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		if _, err := chain.AccumulateRewardsAndCountSigs(bc, statedb, block.Header(), bc); err != nil {
+			return nil, errors.Wrapf(err, "cannot compute the rewards of block %d", block.NumberU64())
+		}
+		result.Elapsed += time.Since(start)
+		result.add(block)
+		result.CrossLinks += len(crossLinks)
+	}
+	return result, nil
+}
+
+// RunImport rewinds the chain below the blocks, and inserts them again,
+// verifying their headers and seals and executing them as the node does.
+func RunImport(bc *core.BlockChain, blocks types.Blocks) (*Result, error) {
+	result := &Result{Workload: Import}
+	if len(blocks) == 0 {
+		return result, nil
+	}
+	if err := bc.RollbackTo(blocks[0].NumberU64() - 1); err != nil {
+		return nil, errors.Wrap(err, "cannot rewind the chain below the blocks")
+	}
+	start := time.Now()
+	if n, err := bc.InsertChain(blocks, true); err != nil {
+		return nil, errors.Wrapf(err, "cannot insert block %d", blocks[n].NumberU64())
+	}
+	result.Elapsed = time.Since(start)
+	for _, block := range blocks {
+		result.add(block)
+	}
+	return result, nil
+}
@@ -0,0 +1,25 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultThroughput(t *testing.T) {
+	r := &Result{Blocks: 10, Txs: 300, Gas: 25e6, CrossLinks: 4, Elapsed: 2 * time.Second}
+	if got := r.BlocksPerSecond(); got != 5 {
+		t.Errorf("got %v blocks/s, expect 5", got)
+	}
+	if got := r.TxsPerSecond(); got != 150 {
+		t.Errorf("got %v txs/s, expect 150", got)
+	}
+	if got := r.MGasPerSecond(); got != 12.5 {
+		t.Errorf("got %v Mgas/s, expect 12.5", got)
+	}
+	if got := r.CrossLinksPerSecond(); got != 2 {
+		t.Errorf("got %v cross-links/s, expect 2", got)
+	}
+	if got := (&Result{Blocks: 10}).BlocksPerSecond(); got != 0 {
+		t.Errorf("got %v blocks/s of no elapsed time, expect 0", got)
+	}
+}
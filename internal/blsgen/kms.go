@@ -0,0 +1,254 @@
+package blsgen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	ffi_bls "github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Key management services the BLS keys and their passphrases are fetched
+// from, so that they never sit unencrypted on disk. A secret source is one of
+//
+//	vault:<secret path>#<field>          a field of a HashiCorp Vault secret,
+//	                                     read at VAULT_ADDR with VAULT_TOKEN
+//	awskms:<ciphertext file>             a file encrypted with AWS KMS,
+//	                                     decrypted with the default AWS credentials
+//	gcpkms:<ciphertext file>#<key name>  a file encrypted with the GCP KMS key,
+//	                                     decrypted with GOOGLE_OAUTH_ACCESS_TOKEN
+//	                                     or the token of the instance service account
+//
+// The credentials in VAULT_ADDR, VAULT_TOKEN and GOOGLE_OAUTH_ACCESS_TOKEN may
+// be secret references, such as ${file:/run/secrets/vault-token}, expanded as
+// utils.ExpandSecrets does.
+const (
+	sourceVault  = "vault"
+	sourceAWSKMS = "awskms"
+	sourceGCPKMS = "gcpkms"
+)
+
+// endpoints of the key management services, variables for the tests
+var (
+	gcpKMSEndpoint   = "https://cloudkms.googleapis.com/v1/"
+	gcpTokenEndpoint = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	kmsClient        = &http.Client{Timeout: 10 * time.Second}
+)
+
+// IsKMSSource returns whether the secret source is a key management service
+func IsKMSSource(src string) bool {
+	switch strings.SplitN(src, ":", 2)[0] {
+	case sourceVault, sourceAWSKMS, sourceGCPKMS:
+		return strings.Contains(src, ":")
+	}
+	return false
+}
+
+// FetchSecret fetches the secret of the key management service source
+func FetchSecret(src string) ([]byte, error) {
+	methodArg := strings.SplitN(src, ":", 2)
+	if len(methodArg) < 2 {
+		return nil, errors.Errorf("invalid secret source %#v", src)
+	}
+	location, qualifier := methodArg[1], ""
+	if i := strings.LastIndex(location, "#"); i >= 0 {
+		location, qualifier = location[:i], location[i+1:]
+	}
+	switch methodArg[0] {
+	case sourceVault:
+		if qualifier == "" {
+			return nil, errors.Errorf("no field in vault secret source %#v", src)
+		}
+		return fetchVaultSecret(location, qualifier)
+	case sourceAWSKMS:
+		return decryptAWSKMS(location)
+	case sourceGCPKMS:
+		if qualifier == "" {
+			return nil, errors.Errorf("no key name in gcpkms secret source %#v", src)
+		}
+		return decryptGCPKMS(location, qualifier)
+	}
+	return nil, errors.Errorf("invalid secret source %#v", src)
+}
+
+// GetPassphraseFromSource reads a passphrase from a key management service
+// source, or from the sources of utils.GetPassphraseFromSource
+func GetPassphraseFromSource(src string) (string, error) {
+	if !IsKMSSource(src) {
+		return utils.GetPassphraseFromSource(src)
+	}
+	secret, err := FetchSecret(src)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// LoadBLSKeyFromSource loads the BLS private key fetched from the key
+// management service source, either raw or hex encoded
+func LoadBLSKeyFromSource(src string) (*ffi_bls.SecretKey, error) {
+	secret, err := FetchSecret(src)
+	if err != nil {
+		return nil, err
+	}
+	keyHex := strings.TrimSpace(string(secret))
+	if len(secret) == 32 {
+		keyHex = hex.EncodeToString(secret)
+	}
+	priKey := &ffi_bls.SecretKey{}
+	if err := priKey.DeserializeHexStr(keyHex); err != nil {
+		return nil, errors.Wrapf(err, "invalid bls private key from %s", strings.SplitN(src, ":", 2)[0])
+	}
+	return priKey, nil
+}
+
+// readCiphertext reads a ciphertext file, either raw or hex encoded
+func readCiphertext(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail read at: %s", fileName)
+	}
+	if unhexed, err := hex.DecodeString(strings.TrimSpace(string(data))); err == nil {
+		return unhexed, nil
+	}
+	return data, nil
+}
+
+// kmsRequest sends the request to a key management service and decodes its
+// JSON response
+func kmsRequest(req *http.Request, result interface{}) error {
+	resp, err := kmsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s %s: %s", req.Method, req.URL.Host, resp.Status)
+	}
+	return json.Unmarshal(body, result)
+}
+
+// fetchVaultSecret reads the field of the secret at path, of a version 1 or
+// version 2 key/value secrets engine
+func fetchVaultSecret(path, field string) ([]byte, error) {
+	addr, err := utils.ExpandSecretsEnv("VAULT_ADDR")
+	if err != nil {
+		return nil, err
+	}
+	token, err := utils.ExpandSecretsEnv("VAULT_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if addr == "" || token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	req, err := http.NewRequest(
+		http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := kmsRequest(req, &resp); err != nil {
+		return nil, errors.Wrapf(err, "cannot read vault secret %s", path)
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return nil, errors.Errorf("no field %s in vault secret %s", field, path)
+	}
+	return []byte(value), nil
+}
+
+// decryptAWSKMS decrypts the ciphertext file with the key of the AWS KMS
+// ciphertext, using the default credentials and region of the AWS SDK
+func decryptAWSKMS(fileName string) ([]byte, error) {
+	ciphertext, err := readCiphertext(fileName)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create aws session")
+	}
+	clear, err := kms.New(sess).Decrypt(&kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decrypt %s with aws kms", fileName)
+	}
+	return clear.Plaintext, nil
+}
+
+// gcpAccessToken returns the OAuth access token of the GCP KMS requests
+func gcpAccessToken() (string, error) {
+	if token, err := utils.ExpandSecretsEnv("GOOGLE_OAUTH_ACCESS_TOKEN"); err != nil || token != "" {
+		return token, err
+	}
+	req, err := http.NewRequest(http.MethodGet, gcpTokenEndpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := kmsRequest(req, &resp); err != nil {
+		return "", errors.Wrap(err, "cannot get the gcp service account token")
+	}
+	return resp.AccessToken, nil
+}
+
+// decryptGCPKMS decrypts the ciphertext file with the GCP KMS key, named as
+// projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>
+func decryptGCPKMS(fileName, keyName string) ([]byte, error) {
+	ciphertext, err := readCiphertext(fileName)
+	if err != nil {
+		return nil, err
+	}
+	token, err := gcpAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{
+		"ciphertext": base64.StdEncoding.EncodeToString(ciphertext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(
+		http.MethodPost, fmt.Sprintf("%s%s:decrypt", gcpKMSEndpoint, keyName), bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := kmsRequest(req, &resp); err != nil {
+		return nil, errors.Wrapf(err, "cannot decrypt %s with gcp kms", fileName)
+	}
+	return base64.StdEncoding.DecodeString(resp.Plaintext)
+}
@@ -0,0 +1,138 @@
+package blsgen
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ffi_bls "github.com/harmony-one/bls/ffi/go/bls"
+)
+
+func TestVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/bls":
+			w.Write([]byte(`{"data": {"pass": "kv1"}}`))
+		case "/v1/secret/data/bls":
+			w.Write([]byte(`{"data": {"data": {"pass": "kv2"}, "metadata": {}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	os.Setenv("VAULT_ADDR", server.URL)
+	os.Setenv("VAULT_TOKEN", "token")
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	for src, want := range map[string]string{
+		"vault:secret/bls#pass":      "kv1",
+		"vault:secret/data/bls#pass": "kv2",
+	} {
+		got, err := GetPassphraseFromSource(src)
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", src, got, want)
+		}
+	}
+	for _, src := range []string{"vault:secret/bls#none", "vault:secret/none#pass", "vault:secret/bls"} {
+		if _, err := GetPassphraseFromSource(src); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+	os.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := GetPassphraseFromSource("vault:secret/bls#pass"); err == nil {
+		t.Error("expected an error with a wrong token")
+	}
+}
+
+func TestGCPKMSKey(t *testing.T) {
+	priKey := &ffi_bls.SecretKey{}
+	priKey.SetByCSPRNG()
+	keyName := "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/" + keyName + ":decrypt":
+			var req struct {
+				Ciphertext string `json:"ciphertext"`
+			}
+			if r.Header.Get("Authorization") != "Bearer token" ||
+				json.NewDecoder(r.Body).Decode(&req) != nil {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			// the test ciphertext is the reversed plaintext
+			data, _ := base64.StdEncoding.DecodeString(req.Ciphertext)
+			for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+				data[i], data[j] = data[j], data[i]
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"plaintext": base64.StdEncoding.EncodeToString(data),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer func(kms, token string) { gcpKMSEndpoint, gcpTokenEndpoint = kms, token }(gcpKMSEndpoint, gcpTokenEndpoint)
+	gcpKMSEndpoint, gcpTokenEndpoint = server.URL+"/", server.URL+"/token"
+
+	dir, err := ioutil.TempDir("", "kms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ciphertext := priKey.Serialize()
+	for i, j := 0, len(ciphertext)-1; i < j; i, j = i+1, j-1 {
+		ciphertext[i], ciphertext[j] = ciphertext[j], ciphertext[i]
+	}
+	fileName := filepath.Join(dir, "key.bls")
+	if err := ioutil.WriteFile(fileName, []byte(hex.EncodeToString(ciphertext)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBLSKeyFromSource("gcpkms:" + fileName + "#" + keyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsEqual(priKey) {
+		t.Errorf("got key %s, want %s", got.SerializeToHexStr(), priKey.SerializeToHexStr())
+	}
+	if _, err := LoadBLSKeyFromSource("gcpkms:" + fileName + "#projects/none"); err == nil {
+		t.Error("expected an error with an unknown key")
+	}
+}
+
+func TestIsKMSSource(t *testing.T) {
+	for src, want := range map[string]bool{
+		"vault:secret/bls#pass": true,
+		"awskms:key.bls":        true,
+		"gcpkms:key.bls#k":      true,
+		"file:pass.txt":         false,
+		"pass:vault":            false,
+		"vault":                 false,
+		"stdin":                 false,
+	} {
+		if got := IsKMSSource(src); got != want {
+			t.Errorf("IsKMSSource(%s) = %v, want %v", src, got, want)
+		}
+	}
+}
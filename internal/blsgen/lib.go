@@ -63,6 +63,12 @@ func WritePriKeyWithPassPhrase(
 	return fileName, nil
 }
 
+// EncryptBLSKey returns the BLS key encrypted with the passphrase, which is
+// the content of its key file.
+func EncryptBLSKey(privateKey *ffi_bls.SecretKey, passphrase string) (string, error) {
+	return encrypt([]byte(privateKey.SerializeToHexStr()), passphrase)
+}
+
 // WriteToFile will print any string of text to a file safely by
 // checking for errors and syncing at the end.
 func WriteToFile(filename string, data string) error {
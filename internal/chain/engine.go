@@ -280,9 +280,10 @@ func (e *engineImpl) Finalize(
 		// ComputeAndMutateEPOSStatus depends on the signing counts that's
 		// consistent with the counts when the new shardState was proposed.
 		// Refer to committee.IsEligibleForEPoSAuction()
+		threshold := chain.Config().AvailabilityThreshold(header.Epoch())
 		for _, addr := range curShardState.StakedValidators().Addrs {
 			if err := availability.ComputeAndMutateEPOSStatus(
-				chain, state, addr,
+				chain, state, addr, threshold,
 			); err != nil {
 				return nil, nil, err
 			}
@@ -0,0 +1,429 @@
+package chain
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/shard"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+const (
+	// stagedPayoutsLimit is the number of processed blocks whose payouts are
+	// kept until their block is committed
+	stagedPayoutsLimit = 128
+	// MaxPayoutAuditEpochs is the number of epochs an earnings query spans
+	// at most
+	MaxPayoutAuditEpochs = 1000
+)
+
+var (
+	// ErrPayoutAuditDisabled is returned querying the payout audit log of a
+	// node which does not record it
+	ErrPayoutAuditDisabled = errors.New("payout audit log is not enabled")
+
+	payoutAudit *PayoutAudit
+
+	// key prefixes of the payout audit log
+	blockPayoutsPrefix      = []byte("p") // + block number -> block payouts
+	validatorEarningsPrefix = []byte("v") // + validator address + epoch -> earnings
+	delegatorEarningsPrefix = []byte("d") // + delegator address + epoch -> earnings per validator
+	epochEarningsPrefix     = []byte("e") // + epoch -> earnings of all the validators
+)
+
+// PayoutRecord is a block reward paid to a validator for the signatures of
+// one of its keys, split between its delegators
+type PayoutRecord struct {
+	ShardID    uint32 // shard of the signed block
+	Validator  common.Address
+	EarningKey shard.BLSPublicKey
+	Amount     *big.Int
+	Delegators []reward.DelegatorPayout
+}
+
+// BlockPayouts are the payouts of a beacon chain block
+type BlockPayouts struct {
+	BlockNum  uint64
+	BlockHash common.Hash
+	Epoch     uint64
+	Payouts   []PayoutRecord
+}
+
+// Earnings are the rewards of an account, or of all the validators, during
+// an epoch
+type Earnings struct {
+	Epoch   uint64
+	Amount  *big.Int
+	Payouts uint64 // number of payouts
+}
+
+// DelegationEarnings are the rewards of a delegator from a validator during
+// an epoch
+type DelegationEarnings struct {
+	Validator common.Address
+	Amount    *big.Int
+	Payouts   uint64
+}
+
+// DelegatorEarnings are the rewards of a delegator during an epoch
+type DelegatorEarnings struct {
+	Epoch       uint64
+	Amount      *big.Int
+	Delegations []DelegationEarnings
+}
+
+// PayoutAudit is the audit log of the block rewards of the chain, recording
+// the payouts of AccumulateRewardsAndCountSigs as the blocks are committed,
+// indexed per block, per validator, per delegator and per epoch. The payouts
+// of a block replaced by a reorg are replaced by those of the block taking
+// its place.
+type PayoutAudit struct {
+	lock   sync.Mutex
+	db     ethdb.Database
+	staged *lru.Cache // block hash -> *BlockPayouts
+}
+
+// NewPayoutAudit returns the audit log recording into the database
+func NewPayoutAudit(db ethdb.Database) *PayoutAudit {
+	staged, _ := lru.New(stagedPayoutsLimit)
+	return &PayoutAudit{db: db, staged: staged}
+}
+
+// SetPayoutAudit sets the audit log the payouts of the processed blocks are
+// staged into, nil to disable it
+func SetPayoutAudit(audit *PayoutAudit) {
+	payoutAudit = audit
+}
+
+// GetPayoutAudit returns the audit log of the payouts, nil if disabled
+func GetPayoutAudit() *PayoutAudit {
+	return payoutAudit
+}
+
+// newRound returns the payouts of the block being processed, nil if the
+// audit log is disabled
+func (audit *PayoutAudit) newRound(header *block.Header) *BlockPayouts {
+	if audit == nil {
+		return nil
+	}
+	return &BlockPayouts{
+		BlockNum: header.Number().Uint64(), Epoch: header.Epoch().Uint64(),
+	}
+}
+
+// add records the payout with its split between the delegators of the
+// validator
+func (round *BlockPayouts) add(payout reward.Payout) {
+	if round == nil {
+		return
+	}
+	round.Payouts = append(round.Payouts, PayoutRecord{
+		ShardID:    payout.ShardID,
+		Validator:  payout.Addr,
+		EarningKey: payout.EarningKey,
+		Amount:     new(big.Int).Set(payout.NewlyEarned),
+		Delegators: payout.Delegators,
+	})
+}
+
+// stage keeps the payouts of the processed block until it is committed
+func (audit *PayoutAudit) stage(hash common.Hash, round *BlockPayouts) {
+	if audit == nil || round == nil || len(round.Payouts) == 0 {
+		return
+	}
+	round.BlockHash = hash
+	audit.staged.Add(hash, round)
+}
+
+// Commit records the staged payouts of the committed block. A block
+// committed at the height of another one, replacing it by a reorg, takes
+// the place of its payouts in the log.
+func (audit *PayoutAudit) Commit(b *types.Block) error {
+	staged, ok := audit.staged.Get(b.Hash())
+	if !ok {
+		return nil
+	}
+	audit.staged.Remove(b.Hash())
+	round := staged.(*BlockPayouts)
+
+	audit.lock.Lock()
+	defer audit.lock.Unlock()
+	replaced := &BlockPayouts{}
+	found, err := getRLP(audit.db, blockPayoutsKey(round.BlockNum), replaced)
+	if err != nil {
+		return err
+	}
+	if found && replaced.BlockHash == round.BlockHash {
+		return nil
+	}
+	delta := newPayoutsDelta()
+	if found {
+		delta.add(replaced, -1)
+	}
+	delta.add(round, 1)
+
+	batch := audit.db.NewBatch()
+	if err := putRLP(batch, blockPayoutsKey(round.BlockNum), round); err != nil {
+		return err
+	}
+	for epoch, d := range delta.epochs {
+		if err := audit.addEarnings(batch, epochEarningsKey(epoch), epoch, d); err != nil {
+			return err
+		}
+	}
+	for key, d := range delta.validators {
+		if err := audit.addEarnings(
+			batch, validatorEarningsKey(key.addr, key.epoch), key.epoch, d,
+		); err != nil {
+			return err
+		}
+	}
+	for key, delegations := range delta.delegators {
+		if err := audit.addDelegations(batch, key.addr, key.epoch, delegations); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// epochKey is an account during an epoch
+type epochKey struct {
+	addr  common.Address
+	epoch uint64
+}
+
+// earningsDelta is the change of recorded earnings
+type earningsDelta struct {
+	amount  *big.Int
+	payouts int64
+}
+
+// payoutsDelta is the change of the recorded earnings by the payouts of the
+// blocks committed and replaced
+type payoutsDelta struct {
+	epochs     map[uint64]*earningsDelta
+	validators map[epochKey]*earningsDelta
+	delegators map[epochKey]map[common.Address]*earningsDelta // by validator
+}
+
+func newPayoutsDelta() *payoutsDelta {
+	return &payoutsDelta{
+		epochs:     map[uint64]*earningsDelta{},
+		validators: map[epochKey]*earningsDelta{},
+		delegators: map[epochKey]map[common.Address]*earningsDelta{},
+	}
+}
+
+// add adds the payouts of the round to the change, taken back for a
+// negative sign
+func (delta *payoutsDelta) add(round *BlockPayouts, sign int64) {
+	change := func(d *earningsDelta, amount *big.Int) *earningsDelta {
+		if d == nil {
+			d = &earningsDelta{amount: big.NewInt(0)}
+		}
+		if sign < 0 {
+			d.amount.Sub(d.amount, amount)
+		} else {
+			d.amount.Add(d.amount, amount)
+		}
+		d.payouts += sign
+		return d
+	}
+	for _, payout := range round.Payouts {
+		delta.epochs[round.Epoch] = change(delta.epochs[round.Epoch], payout.Amount)
+		validator := epochKey{payout.Validator, round.Epoch}
+		delta.validators[validator] = change(delta.validators[validator], payout.Amount)
+		for _, share := range payout.Delegators {
+			delegator := epochKey{share.Delegator, round.Epoch}
+			if delta.delegators[delegator] == nil {
+				delta.delegators[delegator] = map[common.Address]*earningsDelta{}
+			}
+			delegations := delta.delegators[delegator]
+			delegations[payout.Validator] = change(delegations[payout.Validator], share.Amount)
+		}
+	}
+}
+
+// addEarnings adds the change to the earnings of the key, deleted once no
+// payout is left
+func (audit *PayoutAudit) addEarnings(
+	batch ethdb.Batch, key []byte, epoch uint64, delta *earningsDelta,
+) error {
+	stored := &Earnings{Epoch: epoch, Amount: big.NewInt(0)}
+	if _, err := getRLP(audit.db, key, stored); err != nil {
+		return err
+	}
+	stored.Amount.Add(stored.Amount, delta.amount)
+	stored.Payouts = uint64(int64(stored.Payouts) + delta.payouts)
+	if stored.Payouts == 0 {
+		return batch.Delete(key)
+	}
+	return putRLP(batch, key, stored)
+}
+
+func (audit *PayoutAudit) addDelegations(
+	batch ethdb.Batch, delegator common.Address, epoch uint64,
+	delegations map[common.Address]*earningsDelta,
+) error {
+	key := delegatorEarningsKey(delegator, epoch)
+	stored := []DelegationEarnings{}
+	if _, err := getRLP(audit.db, key, &stored); err != nil {
+		return err
+	}
+	for i := range stored {
+		if delta, ok := delegations[stored[i].Validator]; ok {
+			stored[i].Amount.Add(stored[i].Amount, delta.amount)
+			stored[i].Payouts = uint64(int64(stored[i].Payouts) + delta.payouts)
+			delete(delegations, stored[i].Validator)
+		}
+	}
+	for validator, delta := range delegations {
+		if delta.payouts <= 0 {
+			continue
+		}
+		stored = append(stored, DelegationEarnings{
+			Validator: validator,
+			Amount:    new(big.Int).Set(delta.amount),
+			Payouts:   uint64(delta.payouts),
+		})
+	}
+	kept := stored[:0]
+	for _, delegation := range stored {
+		if delegation.Payouts > 0 {
+			kept = append(kept, delegation)
+		}
+	}
+	if len(kept) == 0 {
+		return batch.Delete(key)
+	}
+	return putRLP(batch, key, kept)
+}
+
+// BlockPayouts returns the payouts of the block number, nil if none were
+// recorded
+func (audit *PayoutAudit) BlockPayouts(number uint64) (*BlockPayouts, error) {
+	payouts := &BlockPayouts{}
+	found, err := getRLP(audit.db, blockPayoutsKey(number), payouts)
+	if err != nil || !found {
+		return nil, err
+	}
+	return payouts, nil
+}
+
+// EpochEarnings returns the rewards paid to all the validators during the
+// epochs of the range, the epochs without payouts skipped
+func (audit *PayoutAudit) EpochEarnings(from, to uint64) ([]Earnings, error) {
+	return audit.earnings(from, to, epochEarningsKey)
+}
+
+// ValidatorEarnings returns the rewards of the validator during the epochs
+// of the range, the epochs without payouts skipped
+func (audit *PayoutAudit) ValidatorEarnings(addr common.Address, from, to uint64) ([]Earnings, error) {
+	return audit.earnings(from, to, func(epoch uint64) []byte {
+		return validatorEarningsKey(addr, epoch)
+	})
+}
+
+func (audit *PayoutAudit) earnings(from, to uint64, key func(uint64) []byte) ([]Earnings, error) {
+	if err := checkEpochRange(from, to); err != nil {
+		return nil, err
+	}
+	result := []Earnings{}
+	for epoch := from; epoch <= to; epoch++ {
+		earnings := Earnings{}
+		found, err := getRLP(audit.db, key(epoch), &earnings)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			result = append(result, earnings)
+		}
+	}
+	return result, nil
+}
+
+// DelegatorEarnings returns the rewards of the delegator during the epochs of
+// the range, per validator, the epochs without payouts skipped
+func (audit *PayoutAudit) DelegatorEarnings(addr common.Address, from, to uint64) ([]DelegatorEarnings, error) {
+	if err := checkEpochRange(from, to); err != nil {
+		return nil, err
+	}
+	result := []DelegatorEarnings{}
+	for epoch := from; epoch <= to; epoch++ {
+		delegations := []DelegationEarnings{}
+		found, err := getRLP(audit.db, delegatorEarningsKey(addr, epoch), &delegations)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		earnings := DelegatorEarnings{Epoch: epoch, Amount: big.NewInt(0), Delegations: delegations}
+		for _, delegation := range delegations {
+			earnings.Amount.Add(earnings.Amount, delegation.Amount)
+		}
+		result = append(result, earnings)
+	}
+	return result, nil
+}
+
+func checkEpochRange(from, to uint64) error {
+	if from > to {
+		return errors.Errorf("epoch %d is after epoch %d", from, to)
+	}
+	if to-from >= MaxPayoutAuditEpochs {
+		return errors.Errorf("cannot query more than %d epochs", MaxPayoutAuditEpochs)
+	}
+	return nil
+}
+
+func getRLP(db ethdb.Database, key []byte, value interface{}) (bool, error) {
+	if has, err := db.Has(key); err != nil || !has {
+		return false, err
+	}
+	data, err := db.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return true, rlp.DecodeBytes(data, value)
+}
+
+func putRLP(batch ethdb.Putter, key []byte, value interface{}) error {
+	data, err := rlp.EncodeToBytes(value)
+	if err != nil {
+		return err
+	}
+	return batch.Put(key, data)
+}
+
+func encodeUint64(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+func blockPayoutsKey(number uint64) []byte {
+	return append(append([]byte{}, blockPayoutsPrefix...), encodeUint64(number)...)
+}
+
+func epochEarningsKey(epoch uint64) []byte {
+	return append(append([]byte{}, epochEarningsPrefix...), encodeUint64(epoch)...)
+}
+
+func validatorEarningsKey(addr common.Address, epoch uint64) []byte {
+	key := append(append([]byte{}, validatorEarningsPrefix...), addr.Bytes()...)
+	return append(key, encodeUint64(epoch)...)
+}
+
+func delegatorEarningsKey(addr common.Address, epoch uint64) []byte {
+	key := append(append([]byte{}, delegatorEarningsPrefix...), addr.Bytes()...)
+	return append(key, encodeUint64(epoch)...)
+}
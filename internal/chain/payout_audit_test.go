@@ -0,0 +1,128 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+var (
+	validatorAddr = common.Address{0x01}
+	delegatorAddr = common.Address{0x02}
+)
+
+func TestPayoutAuditCommit(t *testing.T) {
+	audit := NewPayoutAudit(ethdb.NewMemDatabase())
+	for number := int64(1); number <= 2; number++ {
+		header := blockfactory.NewTestHeader().With().
+			Number(big.NewInt(number)).Epoch(big.NewInt(5)).Header()
+		block := types.NewBlock(header, nil, nil, nil, nil, nil)
+		audit.stage(block.Hash(), &BlockPayouts{
+			BlockNum: block.NumberU64(),
+			Epoch:    5,
+			Payouts: []PayoutRecord{{
+				Validator: validatorAddr,
+				Amount:    big.NewInt(1000),
+				Delegators: []reward.DelegatorPayout{
+					{Delegator: validatorAddr, Amount: big.NewInt(775)},
+					{Delegator: delegatorAddr, Amount: big.NewInt(225)},
+				},
+			}},
+		})
+		// the block is recorded once
+		for i := 0; i < 2; i++ {
+			if err := audit.Commit(block); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	payouts, err := audit.BlockPayouts(2)
+	if err != nil || payouts == nil || len(payouts.Payouts) != 1 {
+		t.Fatalf("got block payouts %+v, error %v", payouts, err)
+	}
+	earnings, err := audit.ValidatorEarnings(validatorAddr, 4, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(earnings) != 1 || earnings[0].Epoch != 5 ||
+		earnings[0].Amount.Int64() != 2000 || earnings[0].Payouts != 2 {
+		t.Errorf("got validator earnings %+v", earnings)
+	}
+	epochs, err := audit.EpochEarnings(5, 5)
+	if err != nil || len(epochs) != 1 || epochs[0].Amount.Int64() != 2000 {
+		t.Errorf("got epoch earnings %+v, error %v", epochs, err)
+	}
+	delegations, err := audit.DelegatorEarnings(delegatorAddr, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations) != 1 || delegations[0].Amount.Int64() != 450 ||
+		delegations[0].Delegations[0].Validator != validatorAddr {
+		t.Errorf("got delegator earnings %+v", delegations)
+	}
+	if _, err := audit.ValidatorEarnings(validatorAddr, 0, MaxPayoutAuditEpochs); err == nil {
+		t.Error("expect a too long epoch range to fail")
+	}
+}
+
+func TestPayoutAuditCommitReorg(t *testing.T) {
+	audit := NewPayoutAudit(ethdb.NewMemDatabase())
+	otherAddr := common.Address{0x03}
+	commit := func(time int64, validator common.Address, amount int64) *types.Block {
+		header := blockfactory.NewTestHeader().With().
+			Number(big.NewInt(2)).Epoch(big.NewInt(5)).Time(big.NewInt(time)).Header()
+		block := types.NewBlock(header, nil, nil, nil, nil, nil)
+		audit.stage(block.Hash(), &BlockPayouts{
+			BlockNum: 2,
+			Epoch:    5,
+			Payouts: []PayoutRecord{{
+				Validator: validator,
+				Amount:    big.NewInt(amount),
+				Delegators: []reward.DelegatorPayout{
+					{Delegator: validator, Amount: big.NewInt(amount - 100)},
+					{Delegator: delegatorAddr, Amount: big.NewInt(100)},
+				},
+			}},
+		})
+		if err := audit.Commit(block); err != nil {
+			t.Fatal(err)
+		}
+		return block
+	}
+	commit(1, validatorAddr, 1000)
+	canonical := commit(2, otherAddr, 500)
+
+	// the block of the reorg replaces the payouts of the block at its height
+	payouts, err := audit.BlockPayouts(2)
+	if err != nil || payouts == nil || payouts.BlockHash != canonical.Hash() {
+		t.Fatalf("got block payouts %+v, error %v, expected those of the canonical block", payouts, err)
+	}
+	if earnings, err := audit.ValidatorEarnings(validatorAddr, 5, 5); err != nil || len(earnings) != 0 {
+		t.Errorf("got earnings %+v of the validator of the replaced block, error %v", earnings, err)
+	}
+	if earnings, err := audit.ValidatorEarnings(otherAddr, 5, 5); err != nil ||
+		len(earnings) != 1 || earnings[0].Amount.Int64() != 500 || earnings[0].Payouts != 1 {
+		t.Errorf("got earnings %+v of the validator of the canonical block, error %v", earnings, err)
+	}
+	if epochs, err := audit.EpochEarnings(5, 5); err != nil ||
+		len(epochs) != 1 || epochs[0].Amount.Int64() != 500 || epochs[0].Payouts != 1 {
+		t.Errorf("got epoch earnings %+v, error %v", epochs, err)
+	}
+	delegations, err := audit.DelegatorEarnings(delegatorAddr, 5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations) != 1 || len(delegations[0].Delegations) != 1 ||
+		delegations[0].Delegations[0].Validator != otherAddr || delegations[0].Amount.Int64() != 100 {
+		t.Errorf("got delegator earnings %+v", delegations)
+	}
+	if delegations, err := audit.DelegatorEarnings(validatorAddr, 5, 5); err != nil || len(delegations) != 0 {
+		t.Errorf("got earnings %+v of the self delegation of the replaced block, error %v", delegations, err)
+	}
+}
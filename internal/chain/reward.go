@@ -1,7 +1,6 @@
 package chain
 
 import (
-	"fmt"
 	"math/big"
 	"sort"
 
@@ -21,7 +20,6 @@ import (
 	"github.com/harmony-one/harmony/staking/availability"
 	"github.com/harmony-one/harmony/staking/network"
 	"github.com/pkg/errors"
-	"golang.org/x/sync/singleflight"
 )
 
 func ballotResultBeaconchain(
@@ -44,77 +42,107 @@ func ballotResultBeaconchain(
 	return availability.BallotResult(parentHeader, header, parentShardState, shard.BeaconChainShardID)
 }
 
-var (
-	votingPowerCache   singleflight.Group
-	delegateShareCache singleflight.Group
-)
-
+// lookupVotingPower returns the roster of the committee at epoch, which
+// votepower.Compute caches by the hash of the committee
 func lookupVotingPower(
 	epoch *big.Int, subComm *shard.Committee,
 ) (*votepower.Roster, error) {
-	key := fmt.Sprintf("%s-%d", epoch.String(), subComm.ShardID)
-	results, err, _ := votingPowerCache.Do(
-		key, func() (interface{}, error) {
-			votingPower, err := votepower.Compute(subComm, epoch)
-			if err != nil {
-				return nil, err
-			}
-
-			// For new calc, remove old data from 3 epochs ago
-			deleteEpoch := big.NewInt(0).Sub(epoch, big.NewInt(3))
-			deleteKey := fmt.Sprintf("%s-%d", deleteEpoch.String(), subComm.ShardID)
-			votingPowerCache.Forget(deleteKey)
+	return votepower.Compute(subComm, epoch)
+}
 
-			return votingPower, nil
-		},
-	)
-	if err != nil {
-		return nil, err
+// preStakingBlockReward returns the reward of the signers of a block of a
+// shard at epoch before staking, from the reward schedule of the chain config
+// if it covers the epoch.
+func preStakingBlockReward(bc engine.ChainReader, epoch *big.Int) *big.Int {
+	if scheduled := bc.Config().RewardSchedule.BlockReward(epoch); scheduled != nil {
+		return scheduled
 	}
+	return network.BlockReward
+}
 
-	return results.(*votepower.Roster), nil
+// rewardCredits are the rewards due to the validators for a block, credited
+// to their delegators in a single pass once all computed
+type rewardCredits []state.RewardCredit
+
+// add returns the credits with the reward due to the validator of the
+// snapshot, to distribute by the shares of its delegations
+func (c rewardCredits) add(
+	snapshot *types2.ValidatorSnapshot, due *big.Int, shares map[common.Address]numeric.Dec,
+) rewardCredits {
+	return append(c, state.RewardCredit{Snapshot: snapshot.Validator, Amount: due, Shares: shares})
 }
 
-// Lookup or compute the shares of stake for all delegators in a validator
-func lookupDelegatorShares(
-	snapshot *types2.ValidatorSnapshot,
-) (map[common.Address]numeric.Dec, error) {
-	epoch := snapshot.Epoch
-	validatorSnapshot := snapshot.Validator
-	key := fmt.Sprintf("%s-%s", epoch.String(), validatorSnapshot.Address.Hex())
-
-	shares, err, _ := delegateShareCache.Do(
-		key, func() (interface{}, error) {
-			result := map[common.Address]numeric.Dec{}
-
-			totalDelegationDec := numeric.NewDecFromBigInt(validatorSnapshot.TotalDelegation())
-			if totalDelegationDec.IsZero() {
-				utils.Logger().Info().
-					RawJSON("validator-snapshot", []byte(validatorSnapshot.String())).
-					Msg("zero total delegation during AddReward delegation payout")
-				return result, nil
-			}
+// beaconDue is the reward due to a signer of a block of the beacon chain
+type beaconDue struct {
+	payout   reward.Payout
+	snapshot *types2.ValidatorSnapshot
+	shares   map[common.Address]numeric.Dec
+	power    numeric.Dec
+}
 
-			for i := range validatorSnapshot.Delegations {
-				delegation := validatorSnapshot.Delegations[i]
-				// NOTE percentage = <this_delegator_amount>/<total_delegation>
-				percentage := numeric.NewDecFromBigInt(delegation.Amount).Quo(totalDelegationDec)
-				result[delegation.DelegatorAddress] = percentage
-			}
+// assignRemainder gives the remainder of the block reward left by the
+// rounding of the dues to the signer of the most voting power, the first of
+// them on a tie, and returns it. The dues rounded up beyond the block reward
+// leave no remainder.
+func assignRemainder(blockReward numeric.Dec, dues []beaconDue) *big.Int {
+	if len(dues) == 0 {
+		return big.NewInt(0)
+	}
+	paid, top := big.NewInt(0), 0
+	for i := range dues {
+		paid.Add(paid, dues[i].payout.NewlyEarned)
+		if dues[i].power.GT(dues[top].power) {
+			top = i
+		}
+	}
+	remainder := new(big.Int).Sub(blockReward.TruncateInt(), paid)
+	if remainder.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	dues[top].payout.NewlyEarned = new(big.Int).Add(dues[top].payout.NewlyEarned, remainder)
+	return remainder
+}
 
-			// For new calc, remove old data from 3 epochs ago
-			deleteEpoch := big.NewInt(0).Sub(epoch, big.NewInt(3))
-			deleteKey := fmt.Sprintf("%s-%s", deleteEpoch.String(), validatorSnapshot.Address.Hex())
-			votingPowerCache.Forget(deleteKey)
+// delegatorPayouts returns the amounts credited to the delegators for the
+// credit at index, none if they were not recorded
+func delegatorPayouts(credited [][]state.DelegatorCredit, index int) []reward.DelegatorPayout {
+	if credited == nil || credited[index] == nil {
+		return nil
+	}
+	payouts := make([]reward.DelegatorPayout, len(credited[index]))
+	for i, credit := range credited[index] {
+		payouts[i] = reward.DelegatorPayout{Delegator: credit.Delegator, Amount: credit.Amount}
+	}
+	return payouts
+}
 
-			return result, nil
-		},
-	)
-	if err != nil {
-		return nil, err
+// stakedBlockReward returns the reward of the signers of a block of a shard
+// at epoch. The reward schedule of the chain config comes first for the
+// epochs it covers. From the dynamic sharding fork on, the issuance of the
+// network set by the chain config is split across the shards of the epoch
+// whatever their number.
+func stakedBlockReward(bc engine.ChainReader, epoch *big.Int) numeric.Dec {
+	config := bc.Config()
+	if scheduled := config.RewardSchedule.BlockReward(epoch); scheduled != nil {
+		return numeric.NewDecFromBigInt(scheduled)
+	}
+	if config.IsDynamicSharding(epoch) {
+		return network.StakedRewardForShards(
+			config.StakedNetworkReward,
+			shard.Schedule.InstanceForEpoch(epoch).NumShards(),
+		)
 	}
+	return network.BaseStakedReward
+}
 
-	return shares.(map[common.Address]numeric.Dec), nil
+// NetworkBlockReward returns the reward issued for each block height across
+// all the shards of the epoch, the current reward rate of the network.
+func NetworkBlockReward(bc engine.ChainReader, epoch *big.Int) numeric.Dec {
+	numShards := int64(shard.Schedule.InstanceForEpoch(epoch).NumShards())
+	if !bc.Config().IsStaking(epoch) {
+		return numeric.NewDecFromBigInt(preStakingBlockReward(bc, epoch)).MulInt64(numShards)
+	}
+	return stakedBlockReward(bc, epoch).MulInt64(numShards)
 }
 
 // AccumulateRewardsAndCountSigs credits the coinbase of the given block with the mining
@@ -123,6 +151,41 @@ func lookupDelegatorShares(
 func AccumulateRewardsAndCountSigs(
 	bc engine.ChainReader, state *state.DB,
 	header *block.Header, beaconChain engine.ChainReader,
+) (reward.Reader, error) {
+	return accumulateRewardsAndCountSigs(
+		bc, state, header, beaconChain, payoutAudit, payoutAudit != nil,
+	)
+}
+
+// SimulateRewards returns the payouts of the block of the header carrying the
+// crosslinks, run against a copy of the state without changing it nor
+// recording them in the payout audit log.
+func SimulateRewards(
+	bc engine.ChainReader, state *state.DB, header *block.Header,
+	crossLinks types.CrossLinks, beaconChain engine.ChainReader,
+) (reward.Reader, error) {
+	simulated := types.CopyHeader(header)
+	if len(crossLinks) > 0 {
+		sorted := append(types.CrossLinks{}, crossLinks...)
+		sorted.Sort()
+		data, err := rlp.EncodeToBytes(sorted)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot encode crosslinks")
+		}
+		simulated.SetCrossLinks(data)
+	} else if len(header.CrossLinks()) > 0 {
+		simulated.SetCrossLinks([]byte{})
+	}
+	return accumulateRewardsAndCountSigs(bc, state.Copy(), simulated, beaconChain, nil, true)
+}
+
+// accumulateRewardsAndCountSigs is AccumulateRewardsAndCountSigs recording
+// the staking payouts in the audit log, with the amounts credited to the
+// delegators of each payout if withDelegators is set
+func accumulateRewardsAndCountSigs(
+	bc engine.ChainReader, state *state.DB,
+	header *block.Header, beaconChain engine.ChainReader, audit *PayoutAudit,
+	withDelegators bool,
 ) (reward.Reader, error) {
 	blockNum := header.Number().Uint64()
 	currentHeader := beaconChain.CurrentHeader()
@@ -143,7 +206,7 @@ func AccumulateRewardsAndCountSigs(
 	if headerE := header.Epoch(); bc.Config().IsStaking(headerE) &&
 		bc.CurrentHeader().ShardID() == shard.BeaconChainShardID {
 		utils.AnalysisStart("accumulateRewardBeaconchainSelfPayout", nowEpoch, blockNow)
-		defaultReward := network.BaseStakedReward
+		defaultReward := stakedBlockReward(bc, headerE)
 
 		// Following is commented because the new econ-model has a flat-rate block reward
 		// of 28 ONE per block assuming 4 shards and 8s block time:
@@ -171,13 +234,15 @@ func AccumulateRewardsAndCountSigs(
 
 		newRewards, beaconP, shardP :=
 			big.NewInt(0), []reward.Payout{}, []reward.Payout{}
+		// the payouts recorded by the audit log, if enabled
+		round := audit.newRound(header)
 
 		// Take care of my own beacon chain committee, _ is missing, for slashing
 		members, payable, missing, err := ballotResultBeaconchain(beaconChain, header)
 		if err != nil {
 			return network.EmptyPayout, err
 		}
-		subComm := shard.Committee{shard.BeaconChainShardID, members}
+		subComm := shard.Committee{ShardID: shard.BeaconChainShardID, Slots: members}
 
 		if err := availability.IncrementValidatorSigningCounts(
 			beaconChain,
@@ -203,9 +268,9 @@ func AccumulateRewardsAndCountSigs(
 				allSignersShare = allSignersShare.Add(voterShare)
 			}
 		}
+		dues := make([]beaconDue, 0, len(payable))
 		for beaconMember := range payable {
-			// TODO Give out whatever leftover to the last voter/handle
-			// what to do about share of those that didn't sign
+			// TODO what to do about share of those that didn't sign
 			blsKey := payable[beaconMember].BLSPublicKey
 			voter := votingPower.Voters[blsKey]
 			if !voter.IsHarmonyNode {
@@ -216,33 +281,47 @@ func AccumulateRewardsAndCountSigs(
 				due := defaultReward.Mul(
 					voter.OverallPercent.Quo(allSignersShare),
 				).RoundInt()
-				newRewards.Add(newRewards, due)
 
-				shares, err := lookupDelegatorShares(snapshot)
+				shares, err := network.DelegatorShares.Shares(snapshot)
 				if err != nil {
 					return network.EmptyPayout, err
 				}
-				if err := state.AddReward(snapshot.Validator, due, shares); err != nil {
-					return network.EmptyPayout, err
-				}
-				beaconP = append(beaconP, reward.Payout{
-					ShardID:     shard.BeaconChainShardID,
-					Addr:        voter.EarningAccount,
-					NewlyEarned: due,
-					EarningKey:  voter.Identity,
+				dues = append(dues, beaconDue{
+					payout: reward.Payout{
+						ShardID:     shard.BeaconChainShardID,
+						Addr:        voter.EarningAccount,
+						NewlyEarned: due,
+						EarningKey:  voter.Identity,
+					},
+					snapshot: snapshot,
+					shares:   shares,
+					power:    voter.OverallPercent,
 				})
 			}
 		}
+		remainder := big.NewInt(0)
+		if bc.Config().IsRewardRemainder(headerE) {
+			remainder = assignRemainder(defaultReward, dues)
+		}
+		beaconCredits := make(rewardCredits, 0, len(dues))
+		for _, due := range dues {
+			newRewards.Add(newRewards, due.payout.NewlyEarned)
+			beaconCredits = beaconCredits.add(due.snapshot, due.payout.NewlyEarned, due.shares)
+		}
+		credited, err := state.AddRewards(beaconCredits, withDelegators)
+		if err != nil {
+			return network.EmptyPayout, err
+		}
+		for i, due := range dues {
+			due.payout.Delegators = delegatorPayouts(credited, i)
+			round.add(due.payout)
+			beaconP = append(beaconP, due.payout)
+		}
 		utils.AnalysisEnd("accumulateRewardBeaconchainSelfPayout", nowEpoch, blockNow)
 
 		utils.AnalysisStart("accumulateRewardShardchainPayout", nowEpoch, blockNow)
 		// Handle rewards for shardchain
 		if cxLinks := header.CrossLinks(); len(cxLinks) > 0 {
-			crossLinks := types.CrossLinks{}
-			if err := rlp.DecodeBytes(cxLinks, &crossLinks); err != nil {
-				return network.EmptyPayout, err
-			}
-
 			type slotPayable struct {
 				shard.Slot
 				payout  *big.Int
@@ -259,36 +338,39 @@ func AccumulateRewardsAndCountSigs(
 
 			allPayables, allMissing := []slotPayable{}, []slotMissing{}
 
-			for i := range crossLinks {
-
-				cxLink := crossLinks[i]
+			// the cross links are paid out as they are decoded
+			numCrossLinks := 0
+			if err := types.StreamCrossLinks(cxLinks, false, func(
+				i int, cxLink *types.CrossLink,
+			) error {
+				numCrossLinks = i + 1
 				epoch, shardID := cxLink.Epoch(), cxLink.ShardID()
 				if !bc.Config().IsStaking(epoch) {
-					continue
+					return nil
 				}
 				shardState, err := bc.ReadShardState(epoch)
 
 				if err != nil {
-					return network.EmptyPayout, err
+					return err
 				}
 
 				subComm, err := shardState.FindCommitteeByID(shardID)
 				if err != nil {
-					return network.EmptyPayout, err
+					return err
 				}
 
 				payableSigners, missing, err := availability.BlockSigners(
 					cxLink.Bitmap(), subComm,
 				)
 				if err != nil {
-					return network.EmptyPayout, err
+					return err
 				}
 
 				staked := subComm.StakedValidators()
 				if err := availability.IncrementValidatorSigningCounts(
 					beaconChain, staked, state, payableSigners, missing,
 				); err != nil {
-					return network.EmptyPayout, err
+					return err
 				}
 
 				votingPower, err := lookupVotingPower(
@@ -296,9 +378,10 @@ func AccumulateRewardsAndCountSigs(
 				)
 
 				if err != nil {
-					return network.EmptyPayout, err
+					return err
 				}
 
+				shardReward := stakedBlockReward(bc, epoch)
 				allSignersShare := numeric.ZeroDec()
 				for j := range payableSigners {
 					voter := votingPower.Voters[payableSigners[j].BLSPublicKey]
@@ -311,7 +394,7 @@ func AccumulateRewardsAndCountSigs(
 				for j := range payableSigners {
 					voter := votingPower.Voters[payableSigners[j].BLSPublicKey]
 					if !voter.IsHarmonyNode && !voter.OverallPercent.IsZero() {
-						due := defaultReward.Mul(
+						due := shardReward.Mul(
 							voter.OverallPercent.Quo(allSignersShare),
 						)
 						allPayables = append(allPayables, slotPayable{
@@ -331,9 +414,12 @@ func AccumulateRewardsAndCountSigs(
 						index:  j,
 					})
 				}
+				return nil
+			}); err != nil {
+				return network.EmptyPayout, err
 			}
 
-			resultsHandle := make([][]slotPayable, len(crossLinks))
+			resultsHandle := make([][]slotPayable, numCrossLinks)
 			for i := range resultsHandle {
 				resultsHandle[i] = []slotPayable{}
 			}
@@ -353,6 +439,8 @@ func AccumulateRewardsAndCountSigs(
 			}
 
 			// Finally do the pay
+			shardCredits := make(rewardCredits, 0, len(allPayables))
+			shardPayouts := make([]reward.Payout, 0, len(allPayables))
 			for bucket := range resultsHandle {
 				for payThem := range resultsHandle[bucket] {
 					payable := resultsHandle[bucket][payThem]
@@ -365,14 +453,12 @@ func AccumulateRewardsAndCountSigs(
 					due := resultsHandle[bucket][payThem].payout
 					newRewards.Add(newRewards, due)
 
-					shares, err := lookupDelegatorShares(snapshot)
+					shares, err := network.DelegatorShares.Shares(snapshot)
 					if err != nil {
 						return network.EmptyPayout, err
 					}
-					if err := state.AddReward(snapshot.Validator, due, shares); err != nil {
-						return network.EmptyPayout, err
-					}
-					shardP = append(shardP, reward.Payout{
+					shardCredits = shardCredits.add(snapshot, due, shares)
+					shardPayouts = append(shardPayouts, reward.Payout{
 						ShardID:     payable.shardID,
 						Addr:        payable.EcdsaAddress,
 						NewlyEarned: due,
@@ -380,12 +466,26 @@ func AccumulateRewardsAndCountSigs(
 					})
 				}
 			}
+			credited, err := state.AddRewards(shardCredits, withDelegators)
+			if err != nil {
+				return network.EmptyPayout, err
+			}
+			for i, payout := range shardPayouts {
+				payout.Delegators = delegatorPayouts(credited, i)
+				round.add(payout)
+				shardP = append(shardP, payout)
+			}
 			utils.AnalysisEnd("accumulateRewardShardchainPayout", nowEpoch, blockNow)
+			audit.stage(header.Hash(), round)
 			return network.NewStakingEraRewardForRound(
-				newRewards, missing, beaconP, shardP,
+				newRewards, remainder, missing, beaconP, shardP,
 			), nil
 		}
-		return network.EmptyPayout, nil
+		utils.AnalysisEnd("accumulateRewardShardchainPayout", nowEpoch, blockNow)
+		audit.stage(header.Hash(), round)
+		return network.NewStakingEraRewardForRound(
+			newRewards, remainder, missing, beaconP, shardP,
+		), nil
 	}
 
 	// Before staking
@@ -415,14 +515,14 @@ func AccumulateRewardsAndCountSigs(
 		return network.EmptyPayout, err
 	}
 
-	totalAmount := big.NewInt(0)
+	totalAmount, blockReward := big.NewInt(0), preStakingBlockReward(bc, header.Epoch())
 
 	{
 		last := big.NewInt(0)
 		count := big.NewInt(int64(len(signers)))
 		for i, account := range signers {
 			cur := big.NewInt(0)
-			cur.Mul(network.BlockReward, big.NewInt(int64(i+1))).Div(cur, count)
+			cur.Mul(blockReward, big.NewInt(int64(i+1))).Div(cur, count)
 			diff := big.NewInt(0).Sub(cur, last)
 			state.AddBalance(account.EcdsaAddress, diff)
 			totalAmount.Add(totalAmount, diff)
@@ -430,9 +530,9 @@ func AccumulateRewardsAndCountSigs(
 		}
 	}
 
-	if totalAmount.Cmp(network.BlockReward) != 0 {
+	if totalAmount.Cmp(blockReward) != 0 {
 		utils.Logger().Error().
-			Int64("block-reward", network.BlockReward.Int64()).
+			Int64("block-reward", blockReward.Int64()).
 			Int64("total-amount-paid-out", totalAmount.Int64()).
 			Msg("Total paid out was not equal to block-reward")
 		return nil, errors.Wrapf(
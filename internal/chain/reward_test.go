@@ -0,0 +1,220 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/engine"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/crypto/bls"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	types2 "github.com/harmony-one/harmony/staking/types"
+	staketest "github.com/harmony-one/harmony/staking/types/test"
+	"github.com/pkg/errors"
+)
+
+func newTestDues(powers ...string) []beaconDue {
+	blockReward := numeric.NewDec(100)
+	dues := []beaconDue{}
+	for _, power := range powers {
+		share := numeric.MustNewDecFromStr(power)
+		dues = append(dues, beaconDue{
+			payout: reward.Payout{NewlyEarned: blockReward.Mul(share).RoundInt()},
+			power:  share,
+		})
+	}
+	return dues
+}
+
+func TestAssignRemainder(t *testing.T) {
+	// the thirds rounded down leave 1 to the first of the signers of the most
+	// voting power
+	dues := newTestDues("0.333333333333333333", "0.333333333333333333", "0.333333333333333334")
+	if remainder := assignRemainder(numeric.NewDec(100), dues); remainder.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("got remainder %v, expected 1", remainder)
+	}
+	for i, expected := range []int64{33, 33, 34} {
+		if earned := dues[i].payout.NewlyEarned; earned.Cmp(big.NewInt(expected)) != 0 {
+			t.Errorf("due %d: got %v, expected %v", i, earned, expected)
+		}
+	}
+
+	// the dues rounded up beyond the block reward leave nothing
+	dues = newTestDues("0.006", "0.006", "0.988")
+	if remainder := assignRemainder(numeric.NewDec(100), dues); remainder.Sign() != 0 {
+		t.Errorf("got remainder %v, expected none", remainder)
+	}
+	if remainder := assignRemainder(numeric.NewDec(100), nil); remainder.Sign() != 0 {
+		t.Errorf("got remainder %v without signers", remainder)
+	}
+}
+
+// simulateTestChain is a beacon chain of a parent block, the committees of
+// its shard state and the snapshots of their validators
+type simulateTestChain struct {
+	engine.ChainReader
+	parent     *block.Header
+	shardState *shard.State
+	snapshots  map[common.Address]*types2.ValidatorSnapshot
+}
+
+func (chain *simulateTestChain) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+func (chain *simulateTestChain) CurrentHeader() *block.Header {
+	return chain.parent
+}
+
+func (chain *simulateTestChain) GetHeaderByHash(hash common.Hash) *block.Header {
+	if hash == chain.parent.Hash() {
+		return chain.parent
+	}
+	return nil
+}
+
+func (chain *simulateTestChain) ReadShardState(epoch *big.Int) (*shard.State, error) {
+	return chain.shardState, nil
+}
+
+func (chain *simulateTestChain) ReadValidatorSnapshot(
+	addr common.Address,
+) (*types2.ValidatorSnapshot, error) {
+	snapshot, ok := chain.snapshots[addr]
+	if !ok {
+		return nil, errors.Errorf("no snapshot of %s", addr.Hex())
+	}
+	return snapshot, nil
+}
+
+// newSimulateTestChain returns a chain of the beacon chain and a shard of a
+// harmony node and 2 validators each, the state holding the validators
+func newSimulateTestChain(t *testing.T, epoch *big.Int) (*simulateTestChain, *state.DB) {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	chain := &simulateTestChain{
+		shardState: &shard.State{Epoch: epoch},
+		snapshots:  map[common.Address]*types2.ValidatorSnapshot{},
+	}
+	for shardID := uint32(0); shardID < 2; shardID++ {
+		committee := shard.Committee{ShardID: shardID}
+		for i := 0; i < 3; i++ {
+			slot := shard.Slot{
+				EcdsaAddress: common.BigToAddress(big.NewInt(int64(shardID*10 + uint32(i) + 1))),
+			}
+			copy(slot.BLSPublicKey[:], bls.RandPrivateKey().GetPublicKey().Serialize())
+			if i > 0 {
+				stake := numeric.NewDec(int64(i) * 100)
+				slot.EffectiveStake = &stake
+				wrapper := staketest.GetDefaultValidatorWrapperWithAddr(
+					slot.EcdsaAddress, []shard.BLSPublicKey{slot.BLSPublicKey},
+				)
+				db.SetValidatorFlag(slot.EcdsaAddress)
+				if err := db.UpdateValidatorWrapper(slot.EcdsaAddress, &wrapper); err != nil {
+					t.Fatal(err)
+				}
+				snapshot := staketest.CopyValidatorWrapper(wrapper)
+				chain.snapshots[slot.EcdsaAddress] = &types2.ValidatorSnapshot{
+					Validator: &snapshot, Epoch: epoch,
+				}
+			}
+			committee.Slots = append(committee.Slots, slot)
+		}
+		chain.shardState.Shards = append(chain.shardState.Shards, committee)
+	}
+	chain.parent = blockfactory.ForTest.NewHeader(epoch).With().
+		Number(big.NewInt(1)).Header()
+	return chain, db
+}
+
+func TestSimulateRewards(t *testing.T) {
+	defer func(s shardingconfig.Schedule) { shard.Schedule = s }(shard.Schedule)
+	shard.Schedule = shardingconfig.LocalnetSchedule
+	epoch := big.NewInt(2)
+	chain, db := newSimulateTestChain(t, epoch)
+	header := blockfactory.ForTest.NewHeader(epoch).With().
+		Number(big.NewInt(2)).ParentHash(chain.parent.Hash()).
+		LastCommitBitmap([]byte{0x07}).Header()
+	blockReward := stakedBlockReward(chain, epoch).TruncateInt()
+
+	// a block without crosslinks pays the whole block reward to the beacon
+	// chain signers, the remainder included
+	payout, err := SimulateRewards(chain, db, header, nil, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := payout.ReadRoundResult()
+	if result.Total.Cmp(blockReward) != 0 {
+		t.Errorf("got total %v without crosslinks, expected %v", result.Total, blockReward)
+	}
+	if len(result.BeaconchainAward) != 2 || len(result.ShardChainAward) != 0 {
+		t.Errorf("got %d beacon and %d shard payouts, expected 2 and none",
+			len(result.BeaconchainAward), len(result.ShardChainAward))
+	}
+
+	// a crosslink of the shard adds the payouts of its signers, rounded down
+	crossLink := types.CrossLink{
+		BlockNumberF: big.NewInt(5),
+		ViewIDF:      big.NewInt(5),
+		BitmapF:      []byte{0x07},
+		ShardIDF:     1,
+		EpochF:       epoch,
+	}
+	payout, err = SimulateRewards(chain, db, header, types.CrossLinks{crossLink}, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = payout.ReadRoundResult()
+	if len(result.BeaconchainAward) != 2 || len(result.ShardChainAward) != 2 {
+		t.Fatalf("got %d beacon and %d shard payouts, expected 2 each",
+			len(result.BeaconchainAward), len(result.ShardChainAward))
+	}
+	shardTotal := new(big.Int).Sub(result.Total, blockReward)
+	if shardTotal.Cmp(blockReward) > 0 ||
+		shardTotal.Cmp(new(big.Int).Sub(blockReward, big.NewInt(2))) < 0 {
+		t.Errorf("got total %v of the crosslink, expected %v rounded down", shardTotal, blockReward)
+	}
+
+	// the simulation splits each payout between the delegators
+	for _, payout := range append(result.BeaconchainAward, result.ShardChainAward...) {
+		credited := big.NewInt(0)
+		for _, delegator := range payout.Delegators {
+			credited.Add(credited, delegator.Amount)
+		}
+		if credited.Cmp(payout.NewlyEarned) != 0 {
+			t.Errorf("payout of %s: %v credited to the delegators, expected %v",
+				payout.Addr.Hex(), credited, payout.NewlyEarned)
+		}
+	}
+	// which the block processing does not without the payout audit log
+	defer SetPayoutAudit(GetPayoutAudit())
+	SetPayoutAudit(nil)
+	payout, err = AccumulateRewardsAndCountSigs(chain, db.Copy(), header, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, paid := range payout.ReadRoundResult().BeaconchainAward {
+		if paid.Delegators != nil {
+			t.Errorf("payout of %s split between the delegators", paid.Addr.Hex())
+		}
+	}
+
+	// the state simulated against is left unchanged
+	for addr := range chain.snapshots {
+		wrapper, err := db.ValidatorWrapper(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wrapper.BlockReward.Sign() != 0 || wrapper.Counters.NumBlocksToSign.Sign() != 0 {
+			t.Errorf("validator %s rewarded by the simulation", addr.Hex())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package nodeconfig
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint is a trusted block which a node with an empty database syncs
+// from instead of genesis. It must be the last block of its epoch.
+type Checkpoint struct {
+	Epoch *big.Int
+	Hash  common.Hash // hash of the block
+	Root  common.Hash // state root of the block
+}
+
+var checkpoint *Checkpoint // trusted checkpoint to sync from, nil to sync from genesis
+
+// ParseCheckpoint parses a checkpoint given as epoch:blockhash:stateroot,
+// with the hashes in hex.
+func ParseCheckpoint(s string) (*Checkpoint, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, errors.Errorf("checkpoint %q is not epoch:blockhash:stateroot", s)
+	}
+	epoch, ok := new(big.Int).SetString(parts[0], 10)
+	if !ok || epoch.Sign() < 0 {
+		return nil, errors.Errorf("invalid checkpoint epoch %q", parts[0])
+	}
+	hash, err := parseCheckpointHash(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid checkpoint block hash")
+	}
+	root, err := parseCheckpointHash(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid checkpoint state root")
+	}
+	return &Checkpoint{Epoch: epoch, Hash: hash, Root: root}, nil
+}
+
+func parseCheckpointHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, errors.Errorf("got %d bytes, expected %d", len(b), common.HashLength)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// SetCheckpoint sets the trusted checkpoint to sync from, nil to sync from genesis
+func SetCheckpoint(c *Checkpoint) {
+	checkpoint = c
+}
+
+// GetCheckpoint gets the trusted checkpoint to sync from, nil to sync from genesis
+func GetCheckpoint() *Checkpoint {
+	return checkpoint
+}
@@ -0,0 +1,34 @@
+package nodeconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseCheckpoint(t *testing.T) {
+	hash := "0x" + strings.Repeat("ab", 32)
+	root := "0x" + strings.Repeat("cd", 32)
+	c, err := ParseCheckpoint("42:" + hash + ":" + root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Epoch.Uint64() != 42 || c.Hash != common.HexToHash(hash) || c.Root != common.HexToHash(root) {
+		t.Errorf("got checkpoint %+v", c)
+	}
+
+	for _, s := range []string{
+		"",
+		"42:" + hash,
+		"-1:" + hash + ":" + root,
+		"x:" + hash + ":" + root,
+		"42:0xabcd:" + root,
+		"42:" + hash + ":" + root[2:],
+		"42:" + hash + ":" + root + ":1",
+	} {
+		if _, err := ParseCheckpoint(s); err == nil {
+			t.Errorf("parsed invalid checkpoint %q", s)
+		}
+	}
+}
@@ -6,9 +6,12 @@ package nodeconfig
 import (
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
 	"github.com/harmony-one/harmony/internal/params"
@@ -61,7 +64,168 @@ const (
 )
 
 var version string
-var publicRPC bool // enable public RPC access
+var publicRPC bool    // enable public RPC access
+var adminRPC bool     // enable the admin RPC namespace
+var ethRPCStrict bool // serve the eth namespace with strict Ethereum semantics
+var ipcPath string    // path of the IPC RPC socket, empty to disable it
+var snapSync bool     // bootstrap empty chains with snap sync
+var snapServer bool   // serve the requests of snap syncing peers
+var streamSync bool   // sync over libp2p streams with discovered peers
+var blockPeriod = 8 * time.Second
+var rpcCacheSize = 1024 // number of finalized RPC responses to cache
+var rpcUpstream string  // URL of the node serving the state pruned locally
+var wsConfig = WSConfig{
+	MaxConnections:   1024,
+	MaxSubscriptions: 128,
+	IdleTimeout:      2 * time.Minute,
+	PingInterval:     30 * time.Second,
+}
+
+// WSConfig holds the connection limits of the websocket RPC endpoint
+type WSConfig struct {
+	MaxConnections   int           // maximum concurrent connections, 0 for no limit
+	MaxSubscriptions int           // maximum subscriptions per connection, 0 for no limit
+	IdleTimeout      time.Duration // close connections silent for this long, 0 to disable
+	PingInterval     time.Duration // interval of keep-alive pings, 0 to disable
+}
+
+var rpcTLSConfig RPCTLSConfig
+
+// RPCTLSConfig holds the certificate the HTTP and websocket RPC endpoints
+// are served with, TLS is disabled when no certificate is set
+type RPCTLSConfig struct {
+	CertFile string // PEM encoded certificate chain
+	KeyFile  string // PEM encoded private key
+	Reload   bool   // reload the certificate when the files change
+}
+
+// Enabled returns whether the RPC endpoints are served over TLS
+func (c RPCTLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+var natConfig NATConfig
+var peerReputationPath string // file of the peer reputations, empty to keep them in memory
+var allowlistConfig AllowlistConfig
+var peerDiversityConfig = PeerDiversityConfig{MaxOutboundPerGroup: 8, Anchors: 4}
+var connManagerConfig = ConnManagerConfig{
+	LowWater:      160,
+	HighWater:     192,
+	GracePeriod:   time.Minute,
+	SilencePeriod: 10 * time.Second,
+}
+var beaconHeaderSync bool // follow only the beacon chain headers on shard nodes
+var beaconLight bool      // follow only the beacon chain committees on shard nodes
+var syncBandwidth SyncBandwidth
+var syncServerLimits SyncServerLimits
+var syncCompress bool // gzip the sync responses of the grpc sync protocol
+var configReloader func() ([]string, error)
+var telemetryConfig TelemetryConfig
+var profilerConfig ProfilerConfig
+var dbRepairDepth uint64              // blocks checked by the repairs of the chain databases, 0 to disable them
+var forkRetention uint64 = 1024       // blocks below the head the fork blocks are retained for, 0 not to retain them
+var payoutAudit bool                  // record the block rewards into the payout audit log
+var localnetAccounts []common.Address // accounts funded in the genesis blocks of a generated localnet
+var explorerDBConfig = ExplorerDBConfig{Kind: "badger", Backfill: true}
+
+// TelemetryConfig is the endpoint the node reports its anonymized health to,
+// only when the operator opts in
+type TelemetryConfig struct {
+	Endpoint string        // URL the reports are posted to, empty to disable the reports
+	Interval time.Duration // delay between two reports
+}
+
+// ProfilerConfig is where the node ships the profiles it periodically captures,
+// to diagnose its performance after the fact
+type ProfilerConfig struct {
+	Interval    time.Duration // delay between two captures
+	CPUDuration time.Duration // time the CPU profile is captured for
+	Profiles    []string      // profiles captured: cpu, heap, goroutine
+	Endpoint    string        // URL the profiles are posted to, empty to post none
+	Dir         string        // directory the profiles are written to, empty to write none
+	Keep        int           // profiles of each type kept in the directory
+}
+
+// ExplorerDBConfig is the database the explorer nodes index the blocks of
+// their shard into
+type ExplorerDBConfig struct {
+	Kind     string // badger or postgres
+	Source   string // connection string of the postgres database, the badger database is under DBDir
+	Backfill bool   // index the blocks of the chain not indexed yet, from the last indexed one
+}
+
+// SyncBandwidth caps the bandwidth of the sync protocols in bytes per second,
+// 0 for no cap. The transfers over the caps are delayed.
+type SyncBandwidth struct {
+	Upload   int // bytes per second of the responses served to syncing peers
+	Download int // bytes per second of the responses fetched from peers while syncing
+}
+
+// SyncServerLimits caps the sync requests served by the node, 0 for no cap.
+// The requests over the caps are refused.
+type SyncServerLimits struct {
+	ClientRate        int // requests per second of a client
+	ClientConcurrency int // requests of a client served concurrently
+	MaxConcurrency    int // requests of all clients served concurrently
+}
+
+// AllowlistConfig restricts the p2p connections of permissioned deployments
+// and sentry architectures to the allowlisted peers, the node accepts all
+// peers when none is listed
+type AllowlistConfig struct {
+	Peers        []string // peer IDs of the allowed peers
+	Subnets      []string // CIDR subnets of the allowed peers
+	SwarmKeyFile string   // pre-shared key of the private network, empty for the public network
+}
+
+// ConnManagerConfig bounds the p2p connections, the connections of the
+// unprotected peers over the high watermark are pruned down to the low
+// watermark. The watermarks at 0 disable the pruning.
+type ConnManagerConfig struct {
+	LowWater       int
+	HighWater      int
+	GracePeriod    time.Duration       // age of the new connections before they can be pruned
+	SilencePeriod  time.Duration       // minimum delay between two prunings
+	ProtectedPeers map[uint32][]string // peer IDs never pruned, by shard
+}
+
+// PeerDiversityConfig spreads the outbound p2p connections across network
+// groups, the autonomous systems of the ASN map or else the /16 IPv4 and /32
+// IPv6 subnets, and keeps the long-lived outbound peers as anchors
+type PeerDiversityConfig struct {
+	MaxOutboundPerGroup int    // outbound connections of a network group, 0 for no limit
+	ASNMapFile          string // address ranges and their AS numbers, empty to group by subnet
+	Anchors             int    // anchor peers reconnected on restart and never pruned
+	AnchorsPath         string // file of the anchor peers, empty to keep them in memory
+}
+
+// ParseProtectedPeers parses the protected peers given as
+// shardID=peerID,shardID=peerID
+func ParseProtectedPeers(s string) (map[uint32][]string, error) {
+	peers := map[uint32][]string{}
+	if s == "" {
+		return peers, nil
+	}
+	for _, peer := range strings.Split(s, ",") {
+		parts := strings.Split(peer, "=")
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, errors.Errorf("protected peer %q is not shardID=peerID", peer)
+		}
+		shardID, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("invalid shard %q of protected peer %s", parts[0], parts[1])
+		}
+		peers[uint32(shardID)] = append(peers[uint32(shardID)], parts[1])
+	}
+	return peers, nil
+}
+
+// NATConfig configures the traversal of the NAT the p2p host may be behind,
+// the host is assumed publicly reachable when it is disabled
+type NATConfig struct {
+	Enabled bool     // detect the reachability with AutoNAT and map the port with UPnP or NAT-PMP
+	Relays  []string // multiaddrs of the circuit relays advertised while not publicly reachable
+}
 
 // ConfigType is the structure of all node related configuration variables
 type ConfigType struct {
@@ -86,6 +250,8 @@ type ConfigType struct {
 	isArchival       bool
 	WebHooks         struct {
 		Hooks *webhooks.Hooks
+		// Dispatcher posts the events of the hooks, nil without hooks
+		Dispatcher *webhooks.Dispatcher
 	}
 }
 
@@ -232,6 +398,316 @@ func GetPublicRPC() bool {
 	return publicRPC
 }
 
+// SetAdminRPC set the boolean value of admin RPC namespace access
+func SetAdminRPC(v bool) {
+	adminRPC = v
+}
+
+// GetAdminRPC get the boolean value of admin RPC namespace access
+func GetAdminRPC() bool {
+	return adminRPC
+}
+
+// SetIPCPath set the path of the IPC RPC socket
+func SetIPCPath(path string) {
+	ipcPath = path
+}
+
+// GetIPCPath get the path of the IPC RPC socket, empty if IPC is disabled
+func GetIPCPath() string {
+	return ipcPath
+}
+
+// SetSnapSync set the boolean value of bootstrapping empty chains with snap sync
+func SetSnapSync(v bool) {
+	snapSync = v
+}
+
+// GetSnapSync get the boolean value of bootstrapping empty chains with snap sync
+func GetSnapSync() bool {
+	return snapSync
+}
+
+// SetBeaconHeaderSync set the boolean value of following only the beacon
+// chain headers on shard nodes
+func SetBeaconHeaderSync(v bool) {
+	beaconHeaderSync = v
+}
+
+// GetBeaconHeaderSync get the boolean value of following only the beacon
+// chain headers on shard nodes
+func GetBeaconHeaderSync() bool {
+	return beaconHeaderSync
+}
+
+// SetBeaconLight set the boolean value of following only the committees of
+// the beacon chain on shard nodes
+func SetBeaconLight(v bool) {
+	beaconLight = v
+}
+
+// GetBeaconLight get the boolean value of following only the committees of
+// the beacon chain on shard nodes
+func GetBeaconLight() bool {
+	return beaconLight
+}
+
+// SetSyncBandwidth sets the bandwidth caps of the sync protocols
+func SetSyncBandwidth(bandwidth SyncBandwidth) {
+	syncBandwidth = bandwidth
+}
+
+// GetSyncBandwidth returns the bandwidth caps of the sync protocols
+func GetSyncBandwidth() SyncBandwidth {
+	return syncBandwidth
+}
+
+// SetSyncServerLimits sets the caps of the sync requests served by the node
+func SetSyncServerLimits(limits SyncServerLimits) {
+	syncServerLimits = limits
+}
+
+// GetSyncServerLimits returns the caps of the sync requests served by the node
+func GetSyncServerLimits() SyncServerLimits {
+	return syncServerLimits
+}
+
+// SetSyncCompress sets whether the responses of the grpc sync protocol are
+// compressed
+func SetSyncCompress(v bool) {
+	syncCompress = v
+}
+
+// GetSyncCompress returns whether the responses of the grpc sync protocol
+// are compressed
+func GetSyncCompress() bool {
+	return syncCompress
+}
+
+// SetSnapServer set the boolean value of serving snap sync requests
+func SetSnapServer(v bool) {
+	snapServer = v
+}
+
+// GetSnapServer get the boolean value of serving snap sync requests
+func GetSnapServer() bool {
+	return snapServer
+}
+
+// SetStreamSync set the boolean value of syncing over libp2p streams
+func SetStreamSync(v bool) {
+	streamSync = v
+}
+
+// GetStreamSync get the boolean value of syncing over libp2p streams
+func GetStreamSync() bool {
+	return streamSync
+}
+
+// SetEthRPCStrict set the boolean value of the strict eth RPC namespace
+func SetEthRPCStrict(v bool) {
+	ethRPCStrict = v
+}
+
+// GetEthRPCStrict get the boolean value of the strict eth RPC namespace
+func GetEthRPCStrict() bool {
+	return ethRPCStrict
+}
+
+// SetBlockPeriod sets the block period the leader uses to propose blocks
+func SetBlockPeriod(d time.Duration) {
+	blockPeriod = d
+}
+
+// GetBlockPeriod returns the block period of the network
+func GetBlockPeriod() time.Duration {
+	return blockPeriod
+}
+
+// SetRPCCacheSize sets the number of finalized RPC responses to cache, 0 disables the cache
+func SetRPCCacheSize(size int) {
+	rpcCacheSize = size
+}
+
+// GetRPCCacheSize returns the number of finalized RPC responses to cache
+func GetRPCCacheSize() int {
+	return rpcCacheSize
+}
+
+// SetRPCUpstream sets the URL of the HTTP RPC endpoint of the node the state
+// pruned locally is read from, empty to disable it
+func SetRPCUpstream(url string) {
+	rpcUpstream = url
+}
+
+// GetRPCUpstream returns the URL of the node the state pruned locally is read from
+func GetRPCUpstream() string {
+	return rpcUpstream
+}
+
+// SetWSConfig sets the connection limits of the websocket RPC endpoint
+func SetWSConfig(config WSConfig) {
+	wsConfig = config
+}
+
+// GetWSConfig returns the connection limits of the websocket RPC endpoint
+func GetWSConfig() WSConfig {
+	return wsConfig
+}
+
+// SetRPCTLSConfig sets the certificate of the RPC endpoints
+func SetRPCTLSConfig(config RPCTLSConfig) {
+	rpcTLSConfig = config
+}
+
+// GetRPCTLSConfig returns the certificate of the RPC endpoints
+func GetRPCTLSConfig() RPCTLSConfig {
+	return rpcTLSConfig
+}
+
+// SetNATConfig sets the NAT traversal of the p2p host
+func SetNATConfig(config NATConfig) {
+	natConfig = config
+}
+
+// GetNATConfig returns the NAT traversal of the p2p host
+func GetNATConfig() NATConfig {
+	return natConfig
+}
+
+// SetConnManagerConfig sets the bounds of the p2p connections
+func SetConnManagerConfig(config ConnManagerConfig) {
+	connManagerConfig = config
+}
+
+// GetConnManagerConfig returns the bounds of the p2p connections
+func GetConnManagerConfig() ConnManagerConfig {
+	return connManagerConfig
+}
+
+// SetPeerDiversityConfig sets the diversity of the outbound p2p connections
+func SetPeerDiversityConfig(config PeerDiversityConfig) {
+	peerDiversityConfig = config
+}
+
+// GetPeerDiversityConfig returns the diversity of the outbound p2p connections
+func GetPeerDiversityConfig() PeerDiversityConfig {
+	return peerDiversityConfig
+}
+
+// SetTelemetryConfig sets the endpoint the node reports its health to
+func SetTelemetryConfig(config TelemetryConfig) {
+	telemetryConfig = config
+}
+
+// GetTelemetryConfig returns the endpoint the node reports its health to
+func GetTelemetryConfig() TelemetryConfig {
+	return telemetryConfig
+}
+
+// SetProfilerConfig sets where the node ships its profiles
+func SetProfilerConfig(config ProfilerConfig) {
+	profilerConfig = config
+}
+
+// GetProfilerConfig returns where the node ships its profiles
+func GetProfilerConfig() ProfilerConfig {
+	return profilerConfig
+}
+
+// SetDBRepairDepth sets the number of blocks checked by the repairs of the
+// chain databases, 0 to disable the repairs
+func SetDBRepairDepth(depth uint64) {
+	dbRepairDepth = depth
+}
+
+// GetDBRepairDepth returns the number of blocks checked by the repairs of the
+// chain databases, 0 if the repairs are disabled
+func GetDBRepairDepth() uint64 {
+	return dbRepairDepth
+}
+
+// SetForkRetention sets the number of blocks below the head the blocks off
+// the canonical chain are retained for, 0 not to retain them
+func SetForkRetention(blocks uint64) {
+	forkRetention = blocks
+}
+
+// GetForkRetention returns the number of blocks below the head the blocks off
+// the canonical chain are retained for
+func GetForkRetention() uint64 {
+	return forkRetention
+}
+
+// SetPayoutAudit sets whether the node records the block rewards of the
+// beacon chain into the payout audit log
+func SetPayoutAudit(enabled bool) {
+	payoutAudit = enabled
+}
+
+// GetPayoutAudit returns whether the node records the block rewards of the
+// beacon chain into the payout audit log
+func GetPayoutAudit() bool {
+	return payoutAudit
+}
+
+// SetExplorerDBConfig sets the database the explorer nodes index into
+func SetExplorerDBConfig(config ExplorerDBConfig) {
+	explorerDBConfig = config
+}
+
+// GetExplorerDBConfig returns the database the explorer nodes index into
+func GetExplorerDBConfig() ExplorerDBConfig {
+	return explorerDBConfig
+}
+
+// SetLocalnetAccounts sets the accounts funded in the genesis blocks of a
+// generated localnet
+func SetLocalnetAccounts(accounts []common.Address) {
+	localnetAccounts = accounts
+}
+
+// GetLocalnetAccounts returns the accounts funded in the genesis blocks of a
+// generated localnet
+func GetLocalnetAccounts() []common.Address {
+	return localnetAccounts
+}
+
+// SetPeerReputationPath sets the file saving the reputation of the peers
+func SetPeerReputationPath(path string) {
+	peerReputationPath = path
+}
+
+// GetPeerReputationPath returns the file saving the reputation of the peers
+func GetPeerReputationPath() string {
+	return peerReputationPath
+}
+
+// SetAllowlistConfig sets the peers the p2p host is restricted to
+func SetAllowlistConfig(config AllowlistConfig) {
+	allowlistConfig = config
+}
+
+// GetAllowlistConfig returns the peers the p2p host is restricted to
+func GetAllowlistConfig() AllowlistConfig {
+	return allowlistConfig
+}
+
+// SetConfigReloader sets the function reloading the settings changeable at
+// runtime, which returns the names of the changed settings
+func SetConfigReloader(reload func() ([]string, error)) {
+	configReloader = reload
+}
+
+// ReloadConfig reloads the settings changeable at runtime, and returns the
+// names of the changed settings
+func ReloadConfig() ([]string, error) {
+	if configReloader == nil {
+		return nil, errors.New("the node does not reload its config")
+	}
+	return configReloader()
+}
+
 // ShardingSchedule returns the sharding schedule for this node config.
 func (conf *ConfigType) ShardingSchedule() shardingconfig.Schedule {
 	return conf.shardingSchedule
@@ -296,18 +772,54 @@ func (conf *ConfigType) ValidateConsensusKeysForSameShard(pubkeys []*bls.PublicK
 
 // ChainConfig returns the chain configuration for the network type.
 func (t NetworkType) ChainConfig() params.ChainConfig {
+	return *t.chainConfig()
+}
+
+// OverrideForkEpoch activates the fork of the chain configuration of the
+// network type at the epoch, for the forks to be tested on private networks.
+// The chain configuration of mainnet cannot be overridden.
+func (t NetworkType) OverrideForkEpoch(fork string, epoch *big.Int) error {
+	if t == Mainnet {
+		return errors.New("the forks of mainnet cannot be overridden")
+	}
+	return t.chainConfig().SetForkEpoch(fork, epoch)
+}
+
+// LoadChainConfig replaces the chain configuration of the network type with
+// the one of the JSON or TOML file, which sets the fields it has over the
+// chain configuration of the network type, for custom networks to be run
+// without patching the chain configurations. The chain configuration of
+// mainnet cannot be replaced, nor can another network take its chain id.
+func (t NetworkType) LoadChainConfig(path string) error {
+	if t == Mainnet {
+		return errors.New("the chain config of mainnet cannot be replaced")
+	}
+	config, err := params.LoadChainConfig(path, t.chainConfig())
+	if err != nil {
+		return err
+	}
+	if config.ChainID.Cmp(params.MainnetChainID) == 0 {
+		return errors.Errorf("chain config %s has the chain id of mainnet", path)
+	}
+	*t.chainConfig() = *config
+	return nil
+}
+
+// chainConfig returns the chain configuration shared by the nodes of the
+// network type
+func (t NetworkType) chainConfig() *params.ChainConfig {
 	switch t {
 	case Mainnet:
-		return *params.MainnetChainConfig
+		return params.MainnetChainConfig
 	case Pangaea:
-		return *params.PangaeaChainConfig
+		return params.PangaeaChainConfig
 	case Partner:
-		return *params.PartnerChainConfig
+		return params.PartnerChainConfig
 	case Stressnet:
-		return *params.StressnetChainConfig
+		return params.StressnetChainConfig
 	case Localnet:
-		return *params.LocalnetChainConfig
+		return params.LocalnetChainConfig
 	default:
-		return *params.TestnetChainConfig
+		return params.TestnetChainConfig
 	}
 }
@@ -1,11 +1,17 @@
 package nodeconfig
 
 import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/internal/blsgen"
 	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
 	"github.com/pkg/errors"
 )
 
@@ -88,3 +94,151 @@ func TestValidateConsensusKeysForSameShard(t *testing.T) {
 		t.Error("expected", e, "got", nil)
 	}
 }
+
+func TestParseProtectedPeers(t *testing.T) {
+	peers, err := ParseProtectedPeers("0=QmA,1=QmB,0=QmC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 2 || len(peers[0]) != 2 || peers[0][1] != "QmC" || peers[1][0] != "QmB" {
+		t.Errorf("got %v", peers)
+	}
+	if peers, err := ParseProtectedPeers(""); err != nil || len(peers) != 0 {
+		t.Errorf("got %v %v for no peers", peers, err)
+	}
+	for _, s := range []string{"QmA", "0=", "x=QmA", "-1=QmA", "0=QmA=QmB"} {
+		if _, err := ParseProtectedPeers(s); err == nil {
+			t.Errorf("%q parsed", s)
+		}
+	}
+}
+
+func TestOverrideForkEpoch(t *testing.T) {
+	localnet := NetworkType(Localnet)
+	config := localnet.ChainConfig()
+	defer func() { *params.LocalnetChainConfig = config }()
+
+	if err := localnet.OverrideForkEpoch("staking", big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	if epoch := localnet.ChainConfig().StakingEpoch; epoch.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("staking epoch %v, expected 42", epoch)
+	}
+	if err := localnet.OverrideForkEpoch("unknown", big.NewInt(42)); err == nil {
+		t.Error("unknown fork overridden")
+	}
+	if err := NetworkType(Mainnet).OverrideForkEpoch("staking", big.NewInt(42)); err == nil {
+		t.Error("mainnet fork overridden")
+	}
+}
+
+func TestLoadChainConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	reward, _ := new(big.Int).SetString("28000000000000000000", 10)
+	files := []string{
+		write("custom.json", `{
+			"chain-id": 7,
+			"staking-epoch": 42,
+			"staked-network-reward": 28000000000000000000,
+			"availability-thresholds": [{"epoch": 50, "threshold": "0.8"}],
+			"reward-schedule": [
+				{"epoch": 10, "reward": 28000000000000000000},
+				{"epoch": 20}
+			],
+			"resharding-accounts": [
+				{"epoch": 60, "shard-id": 3, "accounts": ["0x0000000000000000000000000000000000000001"]}
+			]
+		}`),
+		write("custom.toml", `
+			chain-id = 7
+			staking-epoch = 42
+			staked-network-reward = "28000000000000000000"
+			[[availability-thresholds]]
+			epoch = 50
+			threshold = "0.8"
+			[[reward-schedule]]
+			epoch = 10
+			reward = "28000000000000000000"
+			[[reward-schedule]]
+			epoch = 20
+			[[resharding-accounts]]
+			epoch = 60
+			shard-id = 3
+			accounts = ["0x0000000000000000000000000000000000000001"]
+		`),
+	}
+	localnet := NetworkType(Localnet)
+	config := localnet.ChainConfig()
+	defer func() { *params.LocalnetChainConfig = config }()
+	for _, file := range files {
+		*params.LocalnetChainConfig = config
+		if err := localnet.LoadChainConfig(file); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		loaded := localnet.ChainConfig()
+		if loaded.ChainID.Cmp(big.NewInt(7)) != 0 ||
+			loaded.StakingEpoch.Cmp(big.NewInt(42)) != 0 ||
+			loaded.StakedNetworkReward.Cmp(reward) != 0 {
+			t.Errorf("%s: got %v staking epoch %v reward %v",
+				file, loaded.ChainID, loaded.StakingEpoch, loaded.StakedNetworkReward)
+		}
+		if threshold := loaded.AvailabilityThreshold(big.NewInt(50)); threshold.String() != "0.800000000000000000" {
+			t.Errorf("%s: availability threshold %v", file, threshold)
+		}
+		for epoch, expected := range map[int64]*big.Int{9: nil, 10: reward, 19: reward, 20: nil} {
+			got := loaded.RewardSchedule.BlockReward(big.NewInt(epoch))
+			if (got == nil) != (expected == nil) || (got != nil && got.Cmp(expected) != 0) {
+				t.Errorf("%s: block reward %v at epoch %d, expected %v", file, got, epoch, expected)
+			}
+		}
+		if accounts := loaded.ReshardingAccountsOf(big.NewInt(60), 3); len(accounts) != 1 ||
+			accounts[0] != common.HexToAddress("0x01") {
+			t.Errorf("%s: resharding accounts %v", file, accounts)
+		}
+		// the fields missing from the file are the ones of the network type
+		if loaded.CrossLinkEpoch.Cmp(config.CrossLinkEpoch) != 0 {
+			t.Errorf("%s: cross link epoch %v, expected %v", file, loaded.CrossLinkEpoch, config.CrossLinkEpoch)
+		}
+	}
+	if config.StakingEpoch.Cmp(big.NewInt(42)) == 0 {
+		t.Error("epoch of the base chain config changed")
+	}
+
+	*params.LocalnetChainConfig = config
+	for _, file := range []string{
+		write("unknown.json", `{"staking-epok": 42}`),
+		write("mainnet.json", `{"chain-id": 1}`),
+		write("bad.toml", `staking-epoch = "x"`),
+		write("config.yaml", `chain-id: 7`),
+		write("negative-reward.json", `{"reward-schedule": [{"epoch": 10, "reward": -1}]}`),
+		write("unordered-reward.toml", `
+			[[reward-schedule]]
+			epoch = 20
+			[[reward-schedule]]
+			epoch = 10
+			reward = "1"
+		`),
+		write("reward-no-epoch.json", `{"reward-schedule": [{"reward": 1}]}`),
+		write("resharding.json", `{"resharding-accounts": [
+			{"epoch": 60, "shard-id": 3}, {"epoch": 60, "shard-id": 3}
+		]}`),
+	} {
+		if err := localnet.LoadChainConfig(file); err == nil {
+			t.Errorf("%s loaded", file)
+		}
+	}
+	if err := NetworkType(Mainnet).LoadChainConfig(files[0]); err == nil {
+		t.Error("mainnet chain config replaced")
+	}
+}
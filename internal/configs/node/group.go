@@ -25,6 +25,7 @@ const (
 	GroupIDBeaconClient      GroupID = "%s/0.0.1/client/beacon"
 	GroupIDShardPrefix       GroupID = "%s/0.0.1/node/shard/%s"
 	GroupIDShardClientPrefix GroupID = "%s/0.0.1/client/shard/%s"
+	GroupIDCrossLink         GroupID = "%s/0.0.1/node/crosslink"
 	GroupIDGlobal            GroupID = "%s/0.0.1/node/global"
 	GroupIDGlobalClient      GroupID = "%s/0.0.1/node/global"
 	GroupIDUnknown           GroupID = "%s/B1acKh0lE"
@@ -71,6 +72,12 @@ func NewClientGroupIDByShardID(shardID ShardID) GroupID {
 	return GroupID(fmt.Sprintf(GroupIDShardClientPrefix.String(), getNetworkPrefix(shardID), strconv.Itoa(int(shardID))))
 }
 
+// NewCrossLinkGroupID returns the groupID where the shard leaders send
+// their cross-links to the beacon chain
+func NewCrossLinkGroupID() GroupID {
+	return GroupID(fmt.Sprintf(GroupIDCrossLink.String(), getNetworkPrefix(0)))
+}
+
 // ActionType lists action on group
 type ActionType uint
 
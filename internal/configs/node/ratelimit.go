@@ -0,0 +1,69 @@
+package nodeconfig
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RateLimits limits the inbound gossip messages per second, a limit of 0
+// disables it
+type RateLimits struct {
+	Kinds map[string]float64 // messages of each kind from all peers together
+	Peer  float64            // messages from each peer, except consensus messages
+}
+
+var rateLimits = RateLimits{Kinds: map[string]float64{}}
+
+// ParseRateLimits parses the limits of message kinds given as
+// kind=rate,kind=rate with the rates in messages per second
+func ParseRateLimits(s string) (map[string]float64, error) {
+	limits := map[string]float64{}
+	if s == "" {
+		return limits, nil
+	}
+	for _, limit := range strings.Split(s, ",") {
+		parts := strings.Split(limit, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("rate limit %q is not kind=rate", limit)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || rate < 0 {
+			return nil, errors.Errorf("invalid rate limit of %s %q", parts[0], parts[1])
+		}
+		limits[parts[0]] = rate
+	}
+	return limits, nil
+}
+
+// SetRateLimits sets the limits of the inbound gossip messages
+func SetRateLimits(limits RateLimits) {
+	rateLimits = limits
+}
+
+// GetRateLimits returns the limits of the inbound gossip messages
+func GetRateLimits() RateLimits {
+	return rateLimits
+}
+
+// ValidationPool bounds the validation of the inbound gossip messages, the
+// messages of each topic wait in a queue for the validation workers of the
+// topic
+type ValidationPool struct {
+	Workers   int // validation workers of each topic
+	QueueSize int // messages of each topic waiting for a worker
+}
+
+var validationPool = ValidationPool{Workers: runtime.NumCPU(), QueueSize: 1024}
+
+// SetValidationPool sets the bounds of the gossip message validation
+func SetValidationPool(pool ValidationPool) {
+	validationPool = pool
+}
+
+// GetValidationPool returns the bounds of the gossip message validation
+func GetValidationPool() ValidationPool {
+	return validationPool
+}
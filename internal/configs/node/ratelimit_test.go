@@ -0,0 +1,21 @@
+package nodeconfig
+
+import "testing"
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("transaction=1000,staking=2.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 2 || limits["transaction"] != 1000 || limits["staking"] != 2.5 {
+		t.Errorf("got %v", limits)
+	}
+	if limits, err := ParseRateLimits(""); err != nil || len(limits) != 0 {
+		t.Errorf("got %v %v for no limits", limits, err)
+	}
+	for _, s := range []string{"transaction", "=10", "transaction=x", "transaction=-1", "a=1=2"} {
+		if _, err := ParseRateLimits(s); err == nil {
+			t.Errorf("%q parsed", s)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package nodeconfig
+
+import (
+	"strings"
+
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+// stakingDirectives is the set of staking transaction types the node accepts
+// into its pool and relays, all of them if nil
+var stakingDirectives map[staking.Directive]struct{}
+
+// ParseStakingDirectives parses the staking transaction types given as
+// CollectRewards,Delegate, nil for all types if s is empty
+func ParseStakingDirectives(s string) (map[staking.Directive]struct{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	directives := map[staking.Directive]struct{}{}
+	for _, name := range strings.Split(s, ",") {
+		directive, err := staking.ParseDirective(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		directives[directive] = struct{}{}
+	}
+	return directives, nil
+}
+
+// SetStakingDirectives sets the staking transaction types the node accepts
+// and relays, all of them if nil
+func SetStakingDirectives(directives map[staking.Directive]struct{}) {
+	stakingDirectives = directives
+}
+
+// GetStakingDirectives returns the staking transaction types the node accepts
+// and relays, nil if it accepts all of them
+func GetStakingDirectives() map[staking.Directive]struct{} {
+	return stakingDirectives
+}
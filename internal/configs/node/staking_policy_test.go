@@ -0,0 +1,27 @@
+package nodeconfig
+
+import (
+	"testing"
+
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+func TestParseStakingDirectives(t *testing.T) {
+	directives, err := ParseStakingDirectives("CollectRewards, Delegate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rewards := directives[staking.DirectiveCollectRewards]
+	_, delegate := directives[staking.DirectiveDelegate]
+	if len(directives) != 2 || !rewards || !delegate {
+		t.Errorf("got %v", directives)
+	}
+	if directives, err := ParseStakingDirectives(""); err != nil || directives != nil {
+		t.Errorf("got %v %v for all types", directives, err)
+	}
+	for _, s := range []string{"Stake", "Delegate,", "delegate"} {
+		if _, err := ParseStakingDirectives(s); err == nil {
+			t.Errorf("%q parsed", s)
+		}
+	}
+}
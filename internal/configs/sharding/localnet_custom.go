@@ -0,0 +1,59 @@
+package shardingconfig
+
+import (
+	"math/big"
+
+	"github.com/harmony-one/harmony/internal/genesis"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/pkg/errors"
+)
+
+// customLocalnetSchedule is the schedule of a localnet of generated
+// validators, with the epochs of the localnet
+type customLocalnetSchedule struct {
+	localnetSchedule
+	preStaking Instance
+	staking    Instance
+}
+
+// NewCustomLocalnetSchedule returns the schedule of a localnet of numShards
+// shards, whose validators are the accounts, assigned to the shards in turn,
+// the account i to the shard i % numShards. Once staking is enabled, each
+// shard also has externalSlots slots elected among the staking validators.
+func NewCustomLocalnetSchedule(
+	numShards uint32, externalSlots int, accounts []genesis.DeployAccount,
+) (Schedule, error) {
+	if numShards == 0 || len(accounts) == 0 || len(accounts)%int(numShards) != 0 {
+		return nil, errors.Errorf(
+			"cannot assign %d validators evenly to %d shards", len(accounts), numShards,
+		)
+	}
+	if externalSlots < 0 {
+		return nil, errors.Errorf("negative external slots %d", externalSlots)
+	}
+	perShard := len(accounts) / int(numShards)
+	reshardingEpoch := []*big.Int{big.NewInt(0), params.LocalnetChainConfig.StakingEpoch}
+	preStaking, err := NewInstance(
+		numShards, perShard, perShard, numeric.OneDec(),
+		accounts, nil, reshardingEpoch, LocalnetSchedule.BlocksPerEpoch(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	staking, err := NewInstance(
+		numShards, perShard+externalSlots, perShard, numeric.MustNewDecFromStr("0.68"),
+		accounts, nil, reshardingEpoch, LocalnetSchedule.BlocksPerEpoch(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return customLocalnetSchedule{preStaking: preStaking, staking: staking}, nil
+}
+
+func (s customLocalnetSchedule) InstanceForEpoch(epoch *big.Int) Instance {
+	if epoch.Cmp(params.LocalnetChainConfig.StakingEpoch) >= 0 {
+		return s.staking
+	}
+	return s.preStaking
+}
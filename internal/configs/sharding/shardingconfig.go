@@ -82,6 +82,15 @@ type Instance interface {
 	BlocksPerEpoch() uint64
 }
 
+// EpochFirstBlock returns the first block number of the given epoch
+// according to the schedule.
+func EpochFirstBlock(s Schedule, epochNum uint64) uint64 {
+	if epochNum == 0 {
+		return 0
+	}
+	return s.EpochLastBlock(epochNum-1) + 1
+}
+
 // genShardingStructure return sharding structure, given shard number and its patterns.
 func genShardingStructure(shardNum, shardID int, httpPattern, wsPattern string) []map[string]interface{} {
 	res := []map[string]interface{}{}
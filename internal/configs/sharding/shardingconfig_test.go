@@ -118,3 +118,26 @@ func TestGetShardingStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestEpochFirstBlock(t *testing.T) {
+	tests := []struct {
+		epoch uint64
+		first uint64
+		last  uint64
+	}{
+		{0, 0, 344063},
+		{1, 344064, 360447},
+		{2, 360448, 376831},
+	}
+	for i, test := range tests {
+		if got := EpochFirstBlock(MainnetSchedule, test.epoch); got != test.first {
+			t.Errorf("EpochFirstBlock error: index %v, got %v, expect %v\n", i, got, test.first)
+		}
+		if got := MainnetSchedule.EpochLastBlock(test.epoch); got != test.last {
+			t.Errorf("EpochLastBlock error: index %v, got %v, expect %v\n", i, got, test.last)
+		}
+		if !MainnetSchedule.IsLastBlock(test.last) {
+			t.Errorf("IsLastBlock error: index %v, block %v\n", i, test.last)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package viperconfig
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigVersion is the version of the schema of the node config file. The
+// config files without VersionKey are of version 0.
+const ConfigVersion = 1
+
+// VersionKey is the key of the schema version in the node config file
+const VersionKey = "config_version"
+
+// configKeys holds the keys read from the config file, with whether their
+// zero value is applied
+var configKeys sync.Map
+
+func registerKey(name string, zeroApplied bool) {
+	configKeys.Store(name, zeroApplied)
+}
+
+// configKey returns whether the key is read from the config file, and
+// whether its zero value is applied
+func configKey(name string) (known, zeroApplied bool) {
+	value, ok := configKeys.Load(name)
+	if !ok {
+		return false, false
+	}
+	return true, value.(bool)
+}
+
+// migrations upgrade a node config from the version of their index to the
+// next one, and return the warnings of the changes
+var migrations = []func(conf map[string]interface{}) []string{
+	migrateV0,
+}
+
+// migrateV0 replaces the deprecated dns key with dns_zone
+func migrateV0(conf map[string]interface{}) []string {
+	value, ok := conf["dns"]
+	if !ok {
+		return nil
+	}
+	delete(conf, "dns")
+	if enabled, _ := value.(bool); !enabled {
+		return []string{
+			"dns is deprecated and removed, its false value was ignored: " +
+				"pass -dns=false to the node to use the libp2p peer discovery",
+		}
+	}
+	if _, ok := conf["dns_zone"]; ok {
+		return []string{"dns is deprecated and removed, dns_zone is set"}
+	}
+	conf["dns_zone"] = "t.hmny.io"
+	return []string{"dns is deprecated and replaced with dns_zone t.hmny.io"}
+}
+
+// Version returns the schema version of the node config.
+func Version(conf map[string]interface{}) (int, error) {
+	value, ok := conf[VersionKey]
+	if !ok {
+		return 0, nil
+	}
+	// the JSON numbers are decoded as float64, the viper settings as int
+	switch v := value.(type) {
+	case float64:
+		if v >= 0 && v == float64(int(v)) {
+			return int(v), nil
+		}
+	case int:
+		if v >= 0 {
+			return v, nil
+		}
+	}
+	return 0, errors.Errorf("invalid %s %v", VersionKey, value)
+}
+
+// MigrateConfig upgrades the node config to ConfigVersion, and returns the
+// warnings of the changes.
+func MigrateConfig(conf map[string]interface{}) ([]string, error) {
+	version, err := Version(conf)
+	if err != nil {
+		return nil, err
+	}
+	if version > ConfigVersion {
+		return nil, errors.Errorf(
+			"config version %d is newer than the version %d of this node", version, ConfigVersion,
+		)
+	}
+	warnings := []string{}
+	for ; version < ConfigVersion; version++ {
+		warnings = append(warnings, migrations[version](conf)...)
+	}
+	conf[VersionKey] = ConfigVersion
+	return warnings, nil
+}
+
+// CheckConfig returns the warnings about the keys of the node config which
+// the node ignores: the unknown keys, the keys whose zero value does not
+// override the flag, and the values the flag cannot be set to.
+func CheckConfig(conf map[string]interface{}, flags *flag.FlagSet) []string {
+	names := make([]string, 0, len(conf))
+	for name := range conf {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	warnings := []string{}
+	for _, name := range names {
+		if name == VersionKey {
+			continue
+		}
+		known, zeroApplied := configKey(name)
+		if !known {
+			warnings = append(warnings, fmt.Sprintf("%s is not a node config key, it is ignored", name))
+			continue
+		}
+		value := conf[name]
+		if !zeroApplied && (value == nil || reflect.ValueOf(value).IsZero()) {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s is set to %v, which is ignored: pass the flag to the node instead", name, value,
+			))
+			continue
+		}
+		if f := flags.Lookup(name); f != nil {
+			if err := checkFlagValue(f, value); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s has an invalid value %v: %s", name, value, err))
+			}
+		}
+	}
+	return warnings
+}
+
+// checkFlagValue returns whether the flag can be set to the value, without
+// setting it
+func checkFlagValue(f *flag.Flag, value interface{}) error {
+	kind := reflect.TypeOf(f.Value)
+	if kind.Kind() != reflect.Ptr {
+		return nil
+	}
+	check, ok := reflect.New(kind.Elem()).Interface().(flag.Value)
+	if !ok {
+		return nil
+	}
+	text := fmt.Sprint(value)
+	if number, ok := value.(float64); ok {
+		text = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return check.Set(text)
+}
+
+// ReadConfigFile reads the node config file.
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	conf := map[string]interface{}{}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode %s", path)
+	}
+	return conf, nil
+}
+
+// WriteConfigFile writes the node config file.
+func WriteConfigFile(path string, conf map[string]interface{}) error {
+	data, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
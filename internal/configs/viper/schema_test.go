@@ -0,0 +1,81 @@
+package viperconfig
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		conf     map[string]interface{}
+		expect   map[string]interface{}
+		warnings int
+	}{
+		{
+			map[string]interface{}{"dns": true, "port": "9000"},
+			map[string]interface{}{"dns_zone": "t.hmny.io", "port": "9000", VersionKey: ConfigVersion},
+			1,
+		},
+		{
+			map[string]interface{}{"dns": true, "dns_zone": "z"},
+			map[string]interface{}{"dns_zone": "z", VersionKey: ConfigVersion},
+			1,
+		},
+		{
+			map[string]interface{}{"dns": false},
+			map[string]interface{}{VersionKey: ConfigVersion},
+			1,
+		},
+		{
+			map[string]interface{}{"dns": true, VersionKey: float64(1)},
+			map[string]interface{}{"dns": true, VersionKey: ConfigVersion},
+			0,
+		},
+	}
+	for i, test := range tests {
+		warnings, err := MigrateConfig(test.conf)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if len(warnings) != test.warnings || !reflect.DeepEqual(test.conf, test.expect) {
+			t.Errorf("test %d: got %v %v, expect %v", i, test.conf, warnings, test.expect)
+		}
+	}
+	if _, err := MigrateConfig(map[string]interface{}{VersionKey: float64(ConfigVersion + 1)}); err == nil {
+		t.Error("expect an error for a newer config version")
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	size := flags.Int("test_size", 1, "")
+	enabled := flags.Bool("test_enabled", false, "")
+	reloaded := flags.Bool("test_reloaded", true, "")
+	env, file := viper.New(), viper.New()
+	ResetConfInt(size, env, file, "", "test_size")
+	ResetConfBool(enabled, env, file, "", "test_enabled")
+	ReloadConfBool(reloaded, env, file, "", "test_reloaded")
+
+	warnings := CheckConfig(map[string]interface{}{
+		VersionKey:      float64(ConfigVersion),
+		"test_size":     float64(1e6),
+		"test_reloaded": false,
+	}, flags)
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %v, expect none", warnings)
+	}
+	warnings = CheckConfig(map[string]interface{}{
+		"test_size":    "big",
+		"test_enabled": false,
+		"test_unknown": 1,
+	}, flags)
+	if len(warnings) != 3 {
+		t.Errorf("got warnings %v, expect 3", warnings)
+	}
+	if *size != 1 {
+		t.Errorf("got flag value %d, expect the flag unchanged", *size)
+	}
+}
@@ -19,6 +19,16 @@ func CreateEnvViper() *viper.Viper {
 // CreateConfFileViper creates viper to read from config file
 // Now the config file is JSON type, name is "config.json"
 func CreateConfFileViper(filePath, confName, confType string) *viper.Viper {
+	configFileViper, err := ReadConfFileViper(filePath, confName, confType)
+	if err != nil {
+		panic(err)
+	}
+	return configFileViper
+}
+
+// ReadConfFileViper reads the config file like CreateConfFileViper, but
+// returns the error of a malformed config file
+func ReadConfFileViper(filePath, confName, confType string) (*viper.Viper, error) {
 	configFileViper := viper.New()
 	configFileViper.SetConfigName(confName) // name of config file (without extension)
 	configFileViper.SetConfigType(confType) // REQUIRED if the config file does not have the extension in the name
@@ -27,10 +37,10 @@ func CreateConfFileViper(filePath, confName, confType string) *viper.Viper {
 
 	if err := configFileViper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			panic(fmt.Errorf("fatal error config file: %s", err))
+			return nil, fmt.Errorf("fatal error config file: %s", err)
 		}
 	}
-	return configFileViper
+	return configFileViper, nil
 }
 
 func getEnvName(sectionName string, flagName string) string {
@@ -56,6 +66,7 @@ func getConfName(sectionName string, flagName string) string {
 
 // ResetConfUInt resets UInt value to value from config files and system environment variable
 func ResetConfUInt(value *uint, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), false)
 	var confRet = configFileViper.GetInt(getConfName(sectionName, flagName))
 	if confRet != 0 {
 		*value = uint(confRet)
@@ -71,6 +82,7 @@ func ResetConfUInt(value *uint, envViper *viper.Viper, configFileViper *viper.Vi
 
 // ResetConfInt resets INT value to value from config files and system environment variable
 func ResetConfInt(value *int, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), false)
 	var confRet = configFileViper.GetInt(getConfName(sectionName, flagName))
 	if confRet != 0 {
 		*value = confRet
@@ -84,8 +96,25 @@ func ResetConfInt(value *int, envViper *viper.Viper, configFileViper *viper.Vipe
 	}
 }
 
+// ResetConfFloat64 resets Float64 value to value from config files and system environment variable
+func ResetConfFloat64(value *float64, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), false)
+	var confRet = configFileViper.GetFloat64(getConfName(sectionName, flagName))
+	if confRet != 0 {
+		*value = confRet
+		return
+	}
+
+	var envRet = envViper.GetFloat64(getEnvName(sectionName, flagName))
+	if envRet != 0 {
+		*value = envRet
+		return
+	}
+}
+
 // ResetConfBool resets Bool value to value from config files and system environment variable
 func ResetConfBool(value *bool, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), false)
 	var confRet = configFileViper.GetBool(getConfName(sectionName, flagName))
 	if confRet {
 		*value = confRet
@@ -101,6 +130,7 @@ func ResetConfBool(value *bool, envViper *viper.Viper, configFileViper *viper.Vi
 
 // ResetConfString resets String value to value from config files and system environment variable
 func ResetConfString(value *string, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), false)
 	var confRet = configFileViper.GetString(getConfName(sectionName, flagName))
 	if confRet != "" {
 		*value = confRet
@@ -113,3 +143,18 @@ func ResetConfString(value *string, envViper *viper.Viper, configFileViper *vipe
 		return
 	}
 }
+
+// ReloadConfBool resets Bool value to value from config files and system
+// environment variable, whenever it is set there. Unlike ResetConfBool, the
+// value can be turned off.
+func ReloadConfBool(value *bool, envViper *viper.Viper, configFileViper *viper.Viper, sectionName string, flagName string) {
+	registerKey(getConfName(sectionName, flagName), true)
+	if name := getConfName(sectionName, flagName); configFileViper.IsSet(name) {
+		*value = configFileViper.GetBool(name)
+		return
+	}
+	if name := getEnvName(sectionName, flagName); envViper.IsSet(name) {
+		*value = envViper.GetBool(name)
+		return
+	}
+}
@@ -0,0 +1,157 @@
+// Package diskmon monitors the free space of the partition of the node
+// databases, and triggers mitigations when it falls below thresholds instead
+// of letting the node crash on a full disk.
+package diskmon
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Action is a mitigation of a low free disk space
+type Action string
+
+// Mitigations of a low free disk space
+const (
+	// Compact compacts the databases
+	Compact Action = "compact"
+	// Prune deletes the bodies and receipts of the ancient blocks
+	Prune Action = "prune"
+	// PauseIndexes pauses the non-critical indexes until the space is freed
+	PauseIndexes Action = "pause-indexes"
+	// Webhook pages the operator through the low disk space webhook
+	Webhook Action = "webhook"
+)
+
+var actions = map[Action]struct{}{Compact: {}, Prune: {}, PauseIndexes: {}, Webhook: {}}
+
+// Threshold is a free space below which the actions are triggered
+type Threshold struct {
+	Free    uint64 // bytes
+	Actions []Action
+}
+
+// ParseThresholds parses comma separated thresholds, each in the form of
+// <free MiB>:<action>[+<action>...], e.g. 10240:compact+webhook,2048:prune
+func ParseThresholds(spec string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid disk threshold %#v", item)
+		}
+		mib, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil || mib == 0 {
+			return nil, errors.Errorf("invalid free space of disk threshold %#v", item)
+		}
+		t := Threshold{Free: mib << 20}
+		for _, action := range strings.Split(parts[1], "+") {
+			if _, ok := actions[Action(action)]; !ok {
+				return nil, errors.Errorf("unknown action %#v of disk threshold %#v", action, item)
+			}
+			t.Actions = append(t.Actions, Action(action))
+		}
+		thresholds = append(thresholds, t)
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Free > thresholds[j].Free })
+	return thresholds, nil
+}
+
+// MitigateFunc runs the action of the threshold the free space fell below
+type MitigateFunc func(action Action, t Threshold, free uint64) error
+
+// RecoverFunc undoes the action of the threshold the free space rose above
+type RecoverFunc func(action Action, t Threshold, free uint64)
+
+// Monitor checks the free space of a partition against thresholds. The
+// actions of a threshold run once when the free space falls below it, and
+// are undone when the free space rises a tenth above it.
+type Monitor struct {
+	path       string
+	thresholds []Threshold
+	below      []bool
+	mitigate   MitigateFunc
+	recover    RecoverFunc
+	freeSpace  func(path string) (uint64, error)
+}
+
+// New returns the monitor of the partition of the path
+func New(
+	path string, thresholds []Threshold, mitigate MitigateFunc, recover RecoverFunc,
+) *Monitor {
+	return &Monitor{
+		path:       path,
+		thresholds: thresholds,
+		below:      make([]bool, len(thresholds)),
+		mitigate:   mitigate,
+		recover:    recover,
+		freeSpace:  FreeSpace,
+	}
+}
+
+// FreeSpace returns the space available to the node on the partition of
+// the path, in bytes
+func FreeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, errors.Wrapf(err, "cannot stat the partition of %s", path)
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
+
+// Run checks the free space at every interval, forever
+func (m *Monitor) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		if err := m.Check(); err != nil {
+			utils.Logger().Warn().Err(err).Msg("[DiskMonitor] cannot check the free disk space")
+		}
+	}
+}
+
+// Check checks the free space once, and runs the actions of the thresholds
+// it crossed
+func (m *Monitor) Check() error {
+	free, err := m.freeSpace(m.path)
+	if err != nil {
+		return err
+	}
+	for i, t := range m.thresholds {
+		switch {
+		case !m.below[i] && free < t.Free:
+			m.below[i] = true
+			utils.Logger().Warn().
+				Uint64("free-mib", free>>20).
+				Uint64("threshold-mib", t.Free>>20).
+				Msg("[DiskMonitor] free disk space is low, mitigating")
+			for _, action := range t.Actions {
+				if err := m.mitigate(action, t, free); err != nil {
+					utils.Logger().Error().Err(err).
+						Str("action", string(action)).
+						Msg("[DiskMonitor] mitigation failed")
+				}
+			}
+		case m.below[i] && free >= t.Free+t.Free/10:
+			m.below[i] = false
+			utils.Logger().Info().
+				Uint64("free-mib", free>>20).
+				Uint64("threshold-mib", t.Free>>20).
+				Msg("[DiskMonitor] free disk space recovered")
+			for _, action := range t.Actions {
+				m.recover(action, t, free)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package diskmon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds("2048:prune+pause-indexes, 10240:compact+webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Threshold{
+		{Free: 10240 << 20, Actions: []Action{Compact, Webhook}},
+		{Free: 2048 << 20, Actions: []Action{Prune, PauseIndexes}},
+	}
+	if !reflect.DeepEqual(thresholds, expected) {
+		t.Errorf("got %v, expected %v", thresholds, expected)
+	}
+	for _, spec := range []string{"2048", "0:compact", "x:compact", "2048:delete", "2048:"} {
+		if _, err := ParseThresholds(spec); err == nil {
+			t.Errorf("%#v: expected an error", spec)
+		}
+	}
+}
+
+func TestMonitorCheck(t *testing.T) {
+	var mitigated, recovered []Action
+	m := New(".", []Threshold{
+		{Free: 1000, Actions: []Action{Compact}},
+		{Free: 100, Actions: []Action{Prune, PauseIndexes}},
+	}, func(action Action, _ Threshold, _ uint64) error {
+		mitigated = append(mitigated, action)
+		return nil
+	}, func(action Action, _ Threshold, _ uint64) {
+		recovered = append(recovered, action)
+	})
+	for _, step := range []struct {
+		free      uint64
+		mitigated []Action
+		recovered []Action
+	}{
+		{2000, nil, nil},
+		{900, []Action{Compact}, nil},
+		{800, nil, nil},
+		{50, []Action{Prune, PauseIndexes}, nil},
+		{105, nil, nil}, // not a tenth above the threshold
+		{500, nil, []Action{Prune, PauseIndexes}},
+		{1200, nil, []Action{Compact}},
+	} {
+		mitigated, recovered = nil, nil
+		free := step.free
+		m.freeSpace = func(string) (uint64, error) { return free, nil }
+		if err := m.Check(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(mitigated, step.mitigated) || !reflect.DeepEqual(recovered, step.recovered) {
+			t.Errorf("free %d: mitigated %v recovered %v, expected %v and %v",
+				free, mitigated, recovered, step.mitigated, step.recovered)
+		}
+	}
+	if _, err := FreeSpace("."); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package apieth serves the eth namespace with strict Ethereum semantics.
+// Quantities are hex encoded, addresses use the 0x form and the Harmony
+// specific fields (shards, view ids, epochs, staking transactions) are left
+// out, they remain available in the hmy namespaces. Ethereum encoded
+// transactions are not decodable by the node, eth_sendRawTransaction is
+// therefore not served.
+package apieth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
+	"github.com/pkg/errors"
+)
+
+// PublicEthAPI provides the Ethereum compatible subset of the node API
+type PublicEthAPI struct {
+	b       apiv1.Backend
+	chain   *apiv1.PublicBlockChainAPI
+	txPool  *apiv1.PublicTransactionPoolAPI
+	harmony *apiv1.PublicHarmonyAPI
+}
+
+// NewPublicEthAPI creates a new API for the eth namespace.
+func NewPublicEthAPI(b apiv1.Backend) *PublicEthAPI {
+	return &PublicEthAPI{
+		b:       b,
+		chain:   apiv1.NewPublicBlockChainAPI(b),
+		txPool:  apiv1.NewPublicTransactionPoolAPI(b, new(apiv1.AddrLocker)),
+		harmony: apiv1.NewPublicHarmonyAPI(b),
+	}
+}
+
+// ChainId returns the chain id used to sign transactions.
+func (s *PublicEthAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(s.b.ChainConfig().ChainID)
+}
+
+// BlockNumber returns the number of the latest block.
+func (s *PublicEthAPI) BlockNumber() hexutil.Uint64 {
+	return s.chain.BlockNumber()
+}
+
+// GasPrice returns a suggestion for the gas price.
+func (s *PublicEthAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	return s.harmony.GasPrice(ctx)
+}
+
+// GetBalance returns the balance of address at the given block.
+func (s *PublicEthAPI) GetBalance(
+	ctx context.Context, address common.Address, blockNr rpc.BlockNumber,
+) (*hexutil.Big, error) {
+	return s.chain.GetBalance(ctx, address.Hex(), blockNr)
+}
+
+// GetTransactionCount returns the nonce of address at the given block.
+func (s *PublicEthAPI) GetTransactionCount(
+	ctx context.Context, address common.Address, blockNr rpc.BlockNumber,
+) (*hexutil.Uint64, error) {
+	return s.txPool.GetTransactionCount(ctx, address.Hex(), blockNr)
+}
+
+// GetCode returns the code stored at address at the given block.
+func (s *PublicEthAPI) GetCode(
+	ctx context.Context, address common.Address, blockNr rpc.BlockNumber,
+) (hexutil.Bytes, error) {
+	return s.chain.GetCode(ctx, address.Hex(), blockNr)
+}
+
+// GetStorageAt returns the storage of address at key at the given block.
+func (s *PublicEthAPI) GetStorageAt(
+	ctx context.Context, address common.Address, key string, blockNr rpc.BlockNumber,
+) (hexutil.Bytes, error) {
+	return s.chain.GetStorageAt(ctx, address.Hex(), key, blockNr)
+}
+
+// Call executes the given message on the state of the given block without
+// creating a transaction.
+func (s *PublicEthAPI) Call(
+	ctx context.Context, args apiv1.CallArgs, blockNr rpc.BlockNumber,
+) (hexutil.Bytes, error) {
+	return s.chain.Call(ctx, args, blockNr)
+}
+
+// EstimateGas returns the gas needed to execute the given message.
+func (s *PublicEthAPI) EstimateGas(
+	ctx context.Context, args apiv1.CallArgs,
+) (hexutil.Uint64, error) {
+	return s.chain.EstimateGas(ctx, args)
+}
+
+// GetBlockByNumber returns the requested block in the Ethereum format.
+// When fullTx is true all transactions are returned in full detail,
+// otherwise only the transaction hashes.
+func (s *PublicEthAPI) GetBlockByNumber(
+	ctx context.Context, blockNr rpc.BlockNumber, fullTx bool,
+) (map[string]interface{}, error) {
+	block, err := s.b.BlockByNumber(ctx, blockNr)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return s.marshalBlock(block, fullTx)
+}
+
+// GetBlockByHash returns the requested block in the Ethereum format.
+func (s *PublicEthAPI) GetBlockByHash(
+	ctx context.Context, blockHash common.Hash, fullTx bool,
+) (map[string]interface{}, error) {
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return s.marshalBlock(block, fullTx)
+}
+
+func (s *PublicEthAPI) marshalBlock(
+	block *types.Block, fullTx bool,
+) (map[string]interface{}, error) {
+	key := fmt.Sprintf("eth_getBlock-%s-%t", block.Hash().Hex(), fullTx)
+	res, err := s.b.CachedResponse(key, block.NumberU64(), func() (interface{}, error) {
+		return RPCMarshalBlock(block, fullTx)
+	})
+	if res == nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), err
+}
+
+// GetTransactionByHash returns the plain transaction for the given hash.
+// Staking transactions are not visible in the eth namespace.
+func (s *PublicEthAPI) GetTransactionByHash(
+	ctx context.Context, hash common.Hash,
+) (*RPCTransaction, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, nil
+	}
+	return newRPCTransaction(tx, blockHash, blockNumber, index)
+}
+
+// GetTransactionReceipt returns the receipt of the plain transaction with the given hash.
+func (s *PublicEthAPI) GetTransactionReceipt(
+	ctx context.Context, hash common.Hash,
+) (map[string]interface{}, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, nil
+	}
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= int(index) {
+		return nil, nil
+	}
+	return RPCMarshalReceipt(tx, blockHash, blockNumber, index, receipts[index])
+}
+
+// GetBlockReceipts returns the receipts of the plain transactions of the given block.
+func (s *PublicEthAPI) GetBlockReceipts(
+	ctx context.Context, blockNr rpc.BlockNumber,
+) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumber(ctx, blockNr)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(receipts) < len(txs) {
+		return nil, errors.Errorf(
+			"block %d has %d receipts for %d transactions",
+			block.NumberU64(), len(receipts), len(txs),
+		)
+	}
+	result := make([]map[string]interface{}, len(txs))
+	for i, tx := range txs {
+		fields, err := RPCMarshalReceipt(
+			tx, block.Hash(), block.NumberU64(), uint64(i), receipts[i],
+		)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = fields
+	}
+	return result, nil
+}
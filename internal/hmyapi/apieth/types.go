@@ -0,0 +1,139 @@
+package apieth
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+// RPCTransaction is a plain transaction in the Ethereum RPC format, without
+// the Harmony specific shard and timestamp fields
+type RPCTransaction struct {
+	BlockHash        *common.Hash    `json:"blockHash"`
+	BlockNumber      *hexutil.Big    `json:"blockNumber"`
+	From             common.Address  `json:"from"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Hash             common.Hash     `json:"hash"`
+	Input            hexutil.Bytes   `json:"input"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	To               *common.Address `json:"to"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	Value            *hexutil.Big    `json:"value"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+}
+
+// newRPCTransaction returns the Ethereum RPC representation of tx, with the
+// location metadata set if blockHash is not empty
+func newRPCTransaction(
+	tx *types.Transaction, blockHash common.Hash, blockNumber, index uint64,
+) (*RPCTransaction, error) {
+	from, err := tx.SenderAddress()
+	if err != nil {
+		return nil, err
+	}
+	v, r, s := tx.RawSignatureValues()
+	result := &RPCTransaction{
+		From:     from,
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Hash:     tx.Hash(),
+		Input:    hexutil.Bytes(tx.Data()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		To:       tx.To(),
+		Value:    (*hexutil.Big)(tx.Value()),
+		V:        (*hexutil.Big)(v),
+		R:        (*hexutil.Big)(r),
+		S:        (*hexutil.Big)(s),
+	}
+	if blockHash != (common.Hash{}) {
+		result.BlockHash = &blockHash
+		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
+		result.TransactionIndex = (*hexutil.Uint64)(&index)
+	}
+	return result, nil
+}
+
+// RPCMarshalBlock converts the block to the Ethereum RPC format. Staking
+// transactions and the Harmony specific header fields are left out, the
+// proof of work fields are zero.
+func RPCMarshalBlock(b *types.Block, fullTx bool) (map[string]interface{}, error) {
+	head := b.Header()
+	fields := map[string]interface{}{
+		"number":           (*hexutil.Big)(head.Number()),
+		"hash":             b.Hash(),
+		"parentHash":       head.ParentHash(),
+		"nonce":            ethtypes.BlockNonce{},
+		"mixHash":          head.MixDigest(),
+		"sha3Uncles":       ethtypes.EmptyUncleHash,
+		"logsBloom":        head.Bloom(),
+		"stateRoot":        head.Root(),
+		"miner":            head.Coinbase(),
+		"difficulty":       (*hexutil.Big)(big.NewInt(0)),
+		"totalDifficulty":  (*hexutil.Big)(big.NewInt(0)),
+		"extraData":        hexutil.Bytes(head.Extra()),
+		"size":             hexutil.Uint64(b.Size()),
+		"gasLimit":         hexutil.Uint64(head.GasLimit()),
+		"gasUsed":          hexutil.Uint64(head.GasUsed()),
+		"timestamp":        hexutil.Uint64(head.Time().Uint64()),
+		"transactionsRoot": head.TxHash(),
+		"receiptsRoot":     head.ReceiptHash(),
+		"uncles":           []common.Hash{},
+	}
+	txs := b.Transactions()
+	transactions := make([]interface{}, len(txs))
+	for i, tx := range txs {
+		if !fullTx {
+			transactions[i] = tx.Hash()
+			continue
+		}
+		rpcTx, err := newRPCTransaction(tx, b.Hash(), b.NumberU64(), uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		transactions[i] = rpcTx
+	}
+	fields["transactions"] = transactions
+	return fields, nil
+}
+
+// RPCMarshalReceipt converts the receipt of tx to the Ethereum RPC format
+func RPCMarshalReceipt(
+	tx *types.Transaction, blockHash common.Hash, blockNumber, index uint64,
+	receipt *types.Receipt,
+) (map[string]interface{}, error) {
+	from, err := tx.SenderAddress()
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		"blockHash":         blockHash,
+		"blockNumber":       hexutil.Uint64(blockNumber),
+		"transactionHash":   tx.Hash(),
+		"transactionIndex":  hexutil.Uint64(index),
+		"from":              from,
+		"to":                tx.To(),
+		"gasUsed":           hexutil.Uint64(receipt.GasUsed),
+		"cumulativeGasUsed": hexutil.Uint64(receipt.CumulativeGasUsed),
+		"contractAddress":   nil,
+		"logs":              receipt.Logs,
+		"logsBloom":         receipt.Bloom,
+	}
+	if len(receipt.PostState) > 0 {
+		fields["root"] = hexutil.Bytes(receipt.PostState)
+	} else {
+		fields["status"] = hexutil.Uint(receipt.Status)
+	}
+	if receipt.Logs == nil {
+		fields["logs"] = []*types.Log{}
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		fields["contractAddress"] = receipt.ContractAddress
+	}
+	return fields, nil
+}
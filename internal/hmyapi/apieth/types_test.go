@@ -0,0 +1,127 @@
+package apieth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func newTestTxs(t *testing.T) []*types.Transaction {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(2))
+	transfer, err := types.SignTx(types.NewTransaction(
+		0, common.HexToAddress("0x1234"), 0, big.NewInt(1), 21000, big.NewInt(1), nil,
+	), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create, err := types.SignTx(types.NewContractCreation(
+		1, 0, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60, 0x00},
+	), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*types.Transaction{transfer, create}
+}
+
+func TestRPCMarshalBlock(t *testing.T) {
+	txs := newTestTxs(t)
+	header := blockfactory.NewTestHeader().With().Number(big.NewInt(314)).Header()
+	block := types.NewBlock(header, txs, types.Receipts{{}, {}}, nil, nil, nil)
+
+	fields, err := RPCMarshalBlock(block, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"viewID", "epoch", "stakingTransactions", "signers", "shardID"} {
+		if _, ok := decoded[field]; ok {
+			t.Errorf("block has harmony field %s", field)
+		}
+	}
+	expect := map[string]interface{}{
+		"number":          "0x13a",
+		"nonce":           "0x0000000000000000",
+		"difficulty":      "0x0",
+		"totalDifficulty": "0x0",
+		"sha3Uncles":      "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347",
+	}
+	for field, value := range expect {
+		if decoded[field] != value {
+			t.Errorf("got %s %v, expect %v", field, decoded[field], value)
+		}
+	}
+	transactions := decoded["transactions"].([]interface{})
+	if len(transactions) != 2 {
+		t.Fatalf("got %d transactions, expect 2", len(transactions))
+	}
+	transfer := transactions[0].(map[string]interface{})
+	if transfer["to"] != "0x0000000000000000000000000000000000001234" {
+		t.Errorf("got to %v, expect 0x address", transfer["to"])
+	}
+	if transfer["blockNumber"] != "0x13a" || transfer["transactionIndex"] != "0x0" {
+		t.Errorf("got location %v/%v, expect 0x13a/0x0", transfer["blockNumber"], transfer["transactionIndex"])
+	}
+	for _, field := range []string{"shardID", "toShardID", "timestamp"} {
+		if _, ok := transfer[field]; ok {
+			t.Errorf("transaction has harmony field %s", field)
+		}
+	}
+	if create := transactions[1].(map[string]interface{}); create["to"] != nil {
+		t.Errorf("got to %v for contract creation, expect null", create["to"])
+	}
+}
+
+func TestRPCMarshalReceipt(t *testing.T) {
+	tx := newTestTxs(t)[1]
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		GasUsed:           53000,
+		CumulativeGasUsed: 74000,
+		ContractAddress:   common.HexToAddress("0xabcd"),
+	}
+	fields, err := RPCMarshalReceipt(tx, common.HexToHash("0x01"), 314, 1, receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"blockNumber":       "0x13a",
+		"transactionIndex":  "0x1",
+		"gasUsed":           "0xcf08",
+		"cumulativeGasUsed": "0x12110",
+		"status":            "0x1",
+		"contractAddress":   "0x000000000000000000000000000000000000abcd",
+		"to":                nil,
+	}
+	for field, value := range expect {
+		if decoded[field] != value {
+			t.Errorf("got %s %v, expect %v", field, decoded[field], value)
+		}
+	}
+	if logs, ok := decoded["logs"].([]interface{}); !ok || len(logs) != 0 {
+		t.Errorf("got logs %v, expect empty list", decoded["logs"])
+	}
+}
@@ -32,6 +32,7 @@ type Backend interface {
 	ProtocolVersion() int
 	ChainDb() ethdb.Database
 	SingleFlightRequest(key string, fn func() (interface{}, error)) (interface{}, error)
+	CachedResponse(key string, blockNum uint64, fn func() (interface{}, error)) (interface{}, error)
 	SingleFlightForgetKey(key string)
 	EventMux() *event.TypeMux
 	RPCGasCap() *big.Int // global gas cap for hmy_call over rpc: DoS protection
@@ -58,6 +59,7 @@ type Backend interface {
 	GetAccountNonce(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (uint64, error)
 	// TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeNewStakingTxsEvent(chan<- core.NewStakingTxsEvent) event.Subscription
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
 	// Get balance
@@ -88,9 +90,12 @@ type Backend interface {
 	GetCurrentUtilityMetrics() (*network.UtilityMetric, error)
 	GetSuperCommittees() (*quorum.Transition, error)
 	GetTotalStakingSnapshot() *big.Int
+	GetSupply() (*network.Supply, error)
 	GetCurrentBadBlocks() []core.BadBlock
 	GetLastCrossLinks() ([]*types.CrossLink, error)
+	GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error)
 	GetLatestChainHeaders() *block.HeaderPair
 	GetNodeMetadata() commonRPC.NodeMetadata
+	GetHealthStatus() commonRPC.HealthStatus
 	GetBlockSigners(ctx context.Context, blockNr rpc.BlockNumber) (shard.SlotList, *bls.Mask, error)
 }
@@ -14,7 +14,6 @@ import (
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/common/denominations"
 	"github.com/harmony-one/harmony/consensus/quorum"
-	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
@@ -34,7 +33,6 @@ const (
 	defaultFromAddress  = "0x0000000000000000000000000000000000000000"
 	defaultBlocksPeriod = 15000
 	validatorsPageSize  = 100
-	initSupply          = int64(12600000000)
 )
 
 // PublicBlockChainAPI provides an API to access the Harmony blockchain.
@@ -82,6 +80,20 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.
 	if err := s.isBlockGreaterThanLatest(blockNr); err != nil {
 		return nil, err
 	}
+	if blockNr < 0 {
+		return s.getBlockByNumber(ctx, blockNr, fullTx)
+	}
+	key := fmt.Sprintf("hmy_getBlockByNumber-%d-%t", blockNr, fullTx)
+	res, err := s.b.CachedResponse(key, uint64(blockNr), func() (interface{}, error) {
+		return s.getBlockByNumber(ctx, blockNr, fullTx)
+	})
+	if res == nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), err
+}
+
+func (s *PublicBlockChainAPI) getBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
 	block, err := s.b.BlockByNumber(ctx, blockNr)
 	if block != nil {
 		blockArgs := BlockArgs{WithSigners: false, InclTx: true, FullTx: fullTx, InclStaking: true}
@@ -102,8 +114,15 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.
 func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, blockHash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	block, err := s.b.GetBlock(ctx, blockHash)
 	if block != nil {
-		blockArgs := BlockArgs{WithSigners: false, InclTx: true, FullTx: fullTx, InclStaking: true}
-		return RPCMarshalBlock(block, blockArgs)
+		key := fmt.Sprintf("hmy_getBlockByHash-%s-%t", blockHash.Hex(), fullTx)
+		res, err := s.b.CachedResponse(key, block.NumberU64(), func() (interface{}, error) {
+			blockArgs := BlockArgs{WithSigners: false, InclTx: true, FullTx: fullTx, InclStaking: true}
+			return RPCMarshalBlock(block, blockArgs)
+		})
+		if res == nil {
+			return nil, err
+		}
+		return res.(map[string]interface{}), err
 	}
 	return nil, err
 }
@@ -998,15 +1017,48 @@ func (s *PublicBlockChainAPI) GetCurrentBadBlocks() []core.BadBlock {
 	return s.b.GetCurrentBadBlocks()
 }
 
-// GetTotalSupply ..
+// GetTotalSupply returns the total supply of ONE, the genesis supply with the
+// block rewards issued less the transaction fees burned
 func (s *PublicBlockChainAPI) GetTotalSupply() (numeric.Dec, error) {
-	return numeric.NewDec(initSupply), nil
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return numeric.ZeroDec(), err
+	}
+	return toONE(supply.Total), nil
 }
 
-// GetCirculatingSupply ..
+// GetCirculatingSupply returns the circulating supply of ONE, the total
+// supply less the tokens not released or still locked by the supply schedule
 func (s *PublicBlockChainAPI) GetCirculatingSupply() (numeric.Dec, error) {
-	timestamp := time.Now()
-	return numeric.NewDec(initSupply).Mul(reward.PercentageForTimeStamp(timestamp.Unix())), nil
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return numeric.ZeroDec(), err
+	}
+	return toONE(supply.Circulating), nil
+}
+
+// GetSupply returns the total, circulating and staked supply of ONE, and the
+// block rewards issued, the transaction fees burned and the tokens locked
+// they are computed from
+func (s *PublicBlockChainAPI) GetSupply() (*RPCSupply, error) {
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return nil, err
+	}
+	return &RPCSupply{
+		Epoch:       supply.Epoch.Uint64(),
+		Total:       toONE(supply.Total),
+		Circulating: toONE(supply.Circulating),
+		Staked:      toONE(supply.Staked),
+		Issued:      toONE(supply.Issued),
+		Burned:      toONE(supply.Burned),
+		Locked:      toONE(supply.Locked),
+	}, nil
+}
+
+// toONE converts the amount in atto to ONE
+func toONE(amount numeric.Dec) numeric.Dec {
+	return amount.QuoInt64(denominations.One)
 }
 
 // GetStakingNetworkInfo ..
@@ -1020,14 +1072,19 @@ func (s *PublicBlockChainAPI) GetStakingNetworkInfo(
 	round, _ := s.GetMedianRawStakeSnapshot()
 	epoch := s.LatestHeader(ctx).Epoch
 	epochLastBlock, _ := s.EpochLastBlock(epoch)
-	totalSupply, _ := s.GetTotalSupply()
-	circulatingSupply, _ := s.GetCirculatingSupply()
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return nil, err
+	}
 	return &StakingNetworkInfo{
-		TotalSupply:       totalSupply,
-		CirculatingSupply: circulatingSupply,
+		TotalSupply:       toONE(supply.Total),
+		CirculatingSupply: toONE(supply.Circulating),
 		EpochLastBlock:    epochLastBlock,
 		TotalStaking:      totalStaking,
 		MedianRawStake:    round.MedianStake,
+		AvailabilityThreshold: s.b.ChainConfig().AvailabilityThreshold(
+			new(big.Int).SetUint64(uint64(epoch)),
+		),
 	}, nil
 }
 
@@ -1038,3 +1095,12 @@ func (s *PublicBlockChainAPI) GetLastCrossLinks() ([]*types.CrossLink, error) {
 	}
 	return s.b.GetLastCrossLinks()
 }
+
+// GetCrossLink returns the crosslink of the block of the shard, including the
+// crosslinks moved to the crosslink archive
+func (s *PublicBlockChainAPI) GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	return s.b.GetCrossLink(shardID, blockNum)
+}
@@ -29,3 +29,21 @@ func (*DebugAPI) SetLogVerbosity(ctx context.Context, level int) (map[string]int
 	utils.SetLogVerbosity(verbosity)
 	return map[string]interface{}{"verbosity": verbosity.String()}, nil
 }
+
+// SetLogModules sets the log verbosity of the modules on runtime, given as
+// module=verbosity,module=verbosity, the other modules keeping the global
+// verbosity
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"debug_setLogModules","params":["consensus=4,rpc=2"],"id":1}' http://localhost:9123
+func (*DebugAPI) SetLogModules(ctx context.Context, modules string) (map[string]interface{}, error) {
+	verbosity, err := utils.ParseLogModules(modules)
+	if err != nil {
+		return nil, err
+	}
+	utils.SetLogModuleVerbosity(verbosity)
+	result := make(map[string]interface{}, len(verbosity))
+	for module, level := range verbosity {
+		result[module] = level.String()
+	}
+	return result, nil
+}
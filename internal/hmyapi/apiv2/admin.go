@@ -0,0 +1,126 @@
+package apiv2
+
+import (
+	"github.com/harmony-one/harmony/api/proto"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/p2p"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+// PrivateAdminAPI offers node administration RPC methods
+type PrivateAdminAPI struct {
+	host    p2p.Host
+	shardID uint32
+}
+
+// NewPrivateAdminAPI creates a new admin API instance.
+func NewPrivateAdminAPI(host p2p.Host, shardID uint32) *PrivateAdminAPI {
+	return &PrivateAdminAPI{host, shardID}
+}
+
+// NodeInfo describes the p2p identity of the node
+type NodeInfo struct {
+	ID              libp2p_peer.ID `json:"id"`
+	Addrs           []string       `json:"addrs"`
+	Protocols       []string       `json:"protocols"`
+	ShardID         uint32         `json:"shardID"`
+	Version         string         `json:"version"`
+	ProtocolVersion int            `json:"protocolVersion"`
+	PeerCount       int            `json:"peerCount"`
+}
+
+// NodeInfo returns the p2p identity, listening addresses and protocols of the node
+func (s *PrivateAdminAPI) NodeInfo() *NodeInfo {
+	h := s.host.GetP2PHost()
+	info := &NodeInfo{
+		ID:              h.ID(),
+		Addrs:           []string{},
+		Protocols:       h.Mux().Protocols(),
+		ShardID:         s.shardID,
+		Version:         nodeconfig.GetVersion(),
+		ProtocolVersion: proto.ProtocolVersion,
+		PeerCount:       len(h.Network().Peers()),
+	}
+	for _, addr := range h.Addrs() {
+		info.Addrs = append(info.Addrs, addr.String())
+	}
+	return info
+}
+
+// Peers returns the connected peers with their shards, connection
+// direction, supported protocols and latency
+func (s *PrivateAdminAPI) Peers() []p2p.PeerInfo {
+	return s.host.ListPeers()
+}
+
+// PeerTopology returns the connected peers grouped by shard, with their
+// role, version and gossip mesh membership
+func (s *PrivateAdminAPI) PeerTopology() p2p.PeerTopology {
+	return s.host.Topology()
+}
+
+// AddPeer connects to the peer at the given multiaddress, which must
+// include the peer ID, e.g. /ip4/1.2.3.4/tcp/9000/p2p/QmPeer
+func (s *PrivateAdminAPI) AddPeer(addr string) (libp2p_peer.ID, error) {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid multiaddress %s", addr)
+	}
+	return s.host.ConnectPeer(maddr)
+}
+
+// RemovePeer disconnects from the peer with the given ID
+func (s *PrivateAdminAPI) RemovePeer(id string) (bool, error) {
+	peerID, err := libp2p_peer.Decode(id)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid peer ID %s", id)
+	}
+	if err := s.host.DisconnectPeer(peerID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddTrustedPeer connects to the peer at the given multiaddress and keeps
+// the connection alive, reconnecting whenever it drops
+func (s *PrivateAdminAPI) AddTrustedPeer(addr string) (libp2p_peer.ID, error) {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid multiaddress %s", addr)
+	}
+	return s.host.AddTrustedPeer(maddr)
+}
+
+// RemoveTrustedPeer stops keeping the connection to the peer with the given ID alive.
+// It returns whether the peer was trusted.
+func (s *PrivateAdminAPI) RemoveTrustedPeer(id string) (bool, error) {
+	peerID, err := libp2p_peer.Decode(id)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid peer ID %s", id)
+	}
+	return s.host.RemoveTrustedPeer(peerID), nil
+}
+
+// PeerReputations returns the offenses, score and ban of the misbehaving peers
+func (s *PrivateAdminAPI) PeerReputations() []p2p.PeerReputation {
+	return s.host.PeerReputations()
+}
+
+// ClearPeerReputation forgets the offenses of the peer with the given ID,
+// lifting its ban. It returns whether the peer had misbehaved.
+func (s *PrivateAdminAPI) ClearPeerReputation(id string) (bool, error) {
+	peerID, err := libp2p_peer.Decode(id)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid peer ID %s", id)
+	}
+	return s.host.ClearPeerReputation(peerID), nil
+}
+
+// ReloadConfig reloads the settings changeable at runtime from the config
+// file and the environment, like SIGHUP. It returns the names of the changed
+// settings.
+func (s *PrivateAdminAPI) ReloadConfig() ([]string, error) {
+	return nodeconfig.ReloadConfig()
+}
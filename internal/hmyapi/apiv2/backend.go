@@ -10,11 +10,13 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
 	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/chain"
 	commonRPC "github.com/harmony-one/harmony/internal/hmyapi/common"
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/shard"
@@ -31,6 +33,7 @@ type Backend interface {
 	NetVersion() uint64
 	ProtocolVersion() int
 	SingleFlightRequest(key string, fn func() (interface{}, error)) (interface{}, error)
+	CachedResponse(key string, blockNum uint64, fn func() (interface{}, error)) (interface{}, error)
 	SingleFlightForgetKey(key string)
 	ChainDb() ethdb.Database
 	EventMux() *event.TypeMux
@@ -56,6 +59,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	GetAccountNonce(ctx context.Context, addr common.Address, blockNr rpc.BlockNumber) (uint64, error)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeNewStakingTxsEvent(chan<- core.NewStakingTxsEvent) event.Subscription
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
 	GetBalance(
@@ -77,16 +81,30 @@ type Backend interface {
 	GetDelegationsByDelegatorByBlock(delegator common.Address, block *types.Block) ([]common.Address, []*staking.Delegation)
 	GetValidatorSelfDelegation(addr common.Address) *big.Int
 	GetShardState() (*shard.State, error)
+	GetShardStateOfEpoch(epoch *big.Int) (*shard.State, error)
 	GetCurrentStakingErrorSink() types.TransactionErrorReports
 	GetCurrentTransactionErrorSink() types.TransactionErrorReports
 	GetMedianRawStakeSnapshot() (*committee.CompletedEPoSRound, error)
+	GetElectionAudit(epoch uint64) (*committee.ElectionAudit, error)
 	GetPendingCXReceipts() []*types.CXReceiptsProof
+	TraceCrossShardTransaction(hash common.Hash) (*core.CXTrace, error)
 	GetCurrentUtilityMetrics() (*network.UtilityMetric, error)
 	GetSuperCommittees() (*quorum.Transition, error)
 	GetTotalStakingSnapshot() *big.Int
+	GetSupply() (*network.Supply, error)
 	GetCurrentBadBlocks() []core.BadBlock
+	GetRecentReorgs() []core.ReorgEvent
+	GetForkSegments() []core.ForkSegment
+	GetForkBlock(hash common.Hash) *types.Block
+	GetPayoutAudit() (*chain.PayoutAudit, error)
+	EstimateBlockRewards() (*block.Header, reward.Reader, error)
 	GetLastCrossLinks() ([]*types.CrossLink, error)
+	GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error)
+	GetCrossLinkStatus(shardID uint32, blockNum uint64) (*core.CrossLinkStatus, error)
 	GetLatestChainHeaders() *block.HeaderPair
 	GetNodeMetadata() commonRPC.NodeMetadata
+	GetHealthStatus() commonRPC.HealthStatus
 	GetBlockSigners(ctx context.Context, blockNr rpc.BlockNumber) (shard.SlotList, *bls.Mask, error)
+	GetHeaderCommitSig(ctx context.Context, blockNr rpc.BlockNumber) ([]byte, []byte, error)
+	GetCommitteeCheckpoint(epoch uint64) (*core.CommitteeCheckpoint, error)
 }
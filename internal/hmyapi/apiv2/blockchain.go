@@ -13,7 +13,6 @@ import (
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/common/denominations"
 	"github.com/harmony-one/harmony/consensus/quorum"
-	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
@@ -33,7 +32,6 @@ const (
 	defaultFromAddress  = "0x0000000000000000000000000000000000000000"
 	defaultBlocksPeriod = 15000
 	validatorsPageSize  = 100
-	initSupply          = int64(12600000000)
 )
 
 // PublicBlockChainAPI provides an API to access the Harmony blockchain.
@@ -76,6 +74,20 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr uint
 	if err := s.isBlockGreaterThanLatest(blockNr); err != nil {
 		return nil, err
 	}
+	key := fmt.Sprintf(
+		"hmyv2_getBlockByNumber-%d-%t-%t-%t",
+		blockNr, blockArgs.WithSigners, blockArgs.FullTx, blockArgs.InclStaking,
+	)
+	res, err := s.b.CachedResponse(key, blockNr, func() (interface{}, error) {
+		return s.getBlockByNumber(ctx, blockNr, blockArgs)
+	})
+	if res == nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), err
+}
+
+func (s *PublicBlockChainAPI) getBlockByNumber(ctx context.Context, blockNr uint64, blockArgs BlockArgs) (map[string]interface{}, error) {
 	block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(blockNr))
 	blockArgs.InclTx = true
 	if block != nil {
@@ -104,17 +116,32 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, blockHash comm
 	block, err := s.b.GetBlock(ctx, blockHash)
 	blockArgs.InclTx = true
 	if block != nil {
-		if blockArgs.WithSigners {
-			blockArgs.Signers, err = s.GetBlockSigners(ctx, block.NumberU64())
-			if err != nil {
-				return nil, err
-			}
+		key := fmt.Sprintf(
+			"hmyv2_getBlockByHash-%s-%t-%t-%t",
+			blockHash.Hex(), blockArgs.WithSigners, blockArgs.FullTx, blockArgs.InclStaking,
+		)
+		res, err := s.b.CachedResponse(key, block.NumberU64(), func() (interface{}, error) {
+			return s.marshalBlock(ctx, block, blockArgs)
+		})
+		if res == nil {
+			return nil, err
 		}
-		return RPCMarshalBlock(block, blockArgs)
+		return res.(map[string]interface{}), err
 	}
 	return nil, err
 }
 
+func (s *PublicBlockChainAPI) marshalBlock(ctx context.Context, block *types.Block, blockArgs BlockArgs) (map[string]interface{}, error) {
+	if blockArgs.WithSigners {
+		signers, err := s.GetBlockSigners(ctx, block.NumberU64())
+		if err != nil {
+			return nil, err
+		}
+		blockArgs.Signers = signers
+	}
+	return RPCMarshalBlock(block, blockArgs)
+}
+
 // GetBlocks method returns blocks in range blockStart, blockEnd just like GetBlockByNumber but all at once.
 func (s *PublicBlockChainAPI) GetBlocks(ctx context.Context, blockStart, blockEnd uint64, blockArgs BlockArgs) ([]map[string]interface{}, error) {
 	result := make([]map[string]interface{}, 0)
@@ -552,6 +579,17 @@ func (s *PublicBlockChainAPI) GetMedianRawStakeSnapshot() (
 	return s.b.GetMedianRawStakeSnapshot()
 }
 
+// GetElectionAudit returns the audit log of the election of the committees
+// of the epoch, recorded by the beacon chain nodes running with
+// -election_audit: the candidates, the slots at auction in auction order
+// with the tie breaks, and the committee positions of the elected slots.
+func (s *PublicBlockChainAPI) GetElectionAudit(epoch uint64) (*committee.ElectionAudit, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	return s.b.GetElectionAudit(epoch)
+}
+
 // GetAllValidatorAddresses returns all validator addresses.
 func (s *PublicBlockChainAPI) GetAllValidatorAddresses() ([]string, error) {
 	if err := s.isBeaconShard(); err != nil {
@@ -947,15 +985,48 @@ func (s *PublicBlockChainAPI) GetCurrentBadBlocks() []core.BadBlock {
 	return s.b.GetCurrentBadBlocks()
 }
 
-// GetTotalSupply ..
+// GetTotalSupply returns the total supply of ONE, the genesis supply with the
+// block rewards issued less the transaction fees burned
 func (s *PublicBlockChainAPI) GetTotalSupply() (numeric.Dec, error) {
-	return numeric.NewDec(initSupply), nil
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return numeric.ZeroDec(), err
+	}
+	return toONE(supply.Total), nil
 }
 
-// GetCirculatingSupply ..
+// GetCirculatingSupply returns the circulating supply of ONE, the total
+// supply less the tokens not released or still locked by the supply schedule
 func (s *PublicBlockChainAPI) GetCirculatingSupply() (numeric.Dec, error) {
-	timestamp := time.Now()
-	return numeric.NewDec(initSupply).Mul(reward.PercentageForTimeStamp(timestamp.Unix())), nil
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return numeric.ZeroDec(), err
+	}
+	return toONE(supply.Circulating), nil
+}
+
+// GetSupply returns the total, circulating and staked supply of ONE, and the
+// block rewards issued, the transaction fees burned and the tokens locked
+// they are computed from
+func (s *PublicBlockChainAPI) GetSupply() (*RPCSupply, error) {
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return nil, err
+	}
+	return &RPCSupply{
+		Epoch:       supply.Epoch.Uint64(),
+		Total:       toONE(supply.Total),
+		Circulating: toONE(supply.Circulating),
+		Staked:      toONE(supply.Staked),
+		Issued:      toONE(supply.Issued),
+		Burned:      toONE(supply.Burned),
+		Locked:      toONE(supply.Locked),
+	}, nil
+}
+
+// toONE converts the amount in atto to ONE
+func toONE(amount numeric.Dec) numeric.Dec {
+	return amount.QuoInt64(denominations.One)
 }
 
 // GetStakingNetworkInfo ..
@@ -969,14 +1040,19 @@ func (s *PublicBlockChainAPI) GetStakingNetworkInfo(
 	round, _ := s.GetMedianRawStakeSnapshot()
 	epoch := s.GetEpoch(ctx)
 	epochLastBlock, _ := s.EpochLastBlock(epoch)
-	totalSupply, _ := s.GetTotalSupply()
-	circulatingSupply, _ := s.GetCirculatingSupply()
+	supply, err := s.b.GetSupply()
+	if err != nil {
+		return nil, err
+	}
 	return &StakingNetworkInfo{
-		TotalSupply:       totalSupply,
-		CirculatingSupply: circulatingSupply,
+		TotalSupply:       toONE(supply.Total),
+		CirculatingSupply: toONE(supply.Circulating),
 		EpochLastBlock:    epochLastBlock,
 		TotalStaking:      totalStaking,
 		MedianRawStake:    round.MedianStake,
+		AvailabilityThreshold: s.b.ChainConfig().AvailabilityThreshold(
+			new(big.Int).SetUint64(uint64(epoch)),
+		),
 	}, nil
 }
 
@@ -987,3 +1063,12 @@ func (s *PublicBlockChainAPI) GetLastCrossLinks() ([]*types.CrossLink, error) {
 	}
 	return s.b.GetLastCrossLinks()
 }
+
+// GetCrossLink returns the crosslink of the block of the shard, including the
+// crosslinks moved to the crosslink archive
+func (s *PublicBlockChainAPI) GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	return s.b.GetCrossLink(shardID, blockNum)
+}
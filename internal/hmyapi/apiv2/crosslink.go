@@ -0,0 +1,68 @@
+package apiv2
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/pkg/errors"
+)
+
+// RPCCrossLinkStatus is the status of the crosslink of a shard block on the
+// beacon chain. The beacon block is omitted for the crosslinks included
+// before their beacon block numbers were recorded.
+type RPCCrossLinkStatus struct {
+	ShardID           uint32           `json:"shardID"`
+	BlockNumber       uint64           `json:"blockNumber"`
+	Pending           bool             `json:"pending"`
+	Included          bool             `json:"included"`
+	Finalized         bool             `json:"finalized"`
+	BeaconBlockNumber *uint64          `json:"beaconBlockNumber,omitempty"`
+	BeaconBlockHash   *common.Hash     `json:"beaconBlockHash,omitempty"`
+	CrossLink         *types.CrossLink `json:"crossLink,omitempty"`
+}
+
+func newRPCCrossLinkStatus(
+	shardID uint32, blockNum uint64, status *core.CrossLinkStatus,
+) *RPCCrossLinkStatus {
+	result := &RPCCrossLinkStatus{
+		ShardID:     shardID,
+		BlockNumber: blockNum,
+		Pending:     status.Pending,
+		Included:    status.CrossLink != nil,
+		Finalized:   status.Finalized,
+		CrossLink:   status.CrossLink,
+	}
+	if status.BeaconBlock > 0 {
+		number := status.BeaconBlock
+		result.BeaconBlockNumber = &number
+	}
+	return result
+}
+
+// GetCrossLinkStatus returns whether the crosslink of the block of the shard
+// is pending, included in a beacon chain block and finalized, with the
+// beacon chain block which included it.
+func (s *PublicBlockChainAPI) GetCrossLinkStatus(
+	ctx context.Context, shardID uint32, blockNum uint64,
+) (*RPCCrossLinkStatus, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	status, err := s.b.GetCrossLinkStatus(shardID, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	result := newRPCCrossLinkStatus(shardID, blockNum, status)
+	if result.BeaconBlockNumber != nil {
+		header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(*result.BeaconBlockNumber))
+		if err != nil || header == nil {
+			return nil, errors.Errorf("cannot read beacon block %d", *result.BeaconBlockNumber)
+		}
+		hash := header.Hash()
+		result.BeaconBlockHash = &hash
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func TestNewRPCCrossLinkStatus(t *testing.T) {
+	cl := &types.CrossLink{BlockNumberF: big.NewInt(7), ShardIDF: 1, EpochF: big.NewInt(1)}
+
+	pending := newRPCCrossLinkStatus(1, 7, &core.CrossLinkStatus{Pending: true})
+	if !pending.Pending || pending.Included || pending.Finalized || pending.BeaconBlockNumber != nil {
+		t.Errorf("got %+v, expect a pending crosslink", pending)
+	}
+
+	included := newRPCCrossLinkStatus(1, 7, &core.CrossLinkStatus{
+		CrossLink: cl, BeaconBlock: 12, Finalized: true,
+	})
+	if !included.Included || !included.Finalized || included.Pending {
+		t.Errorf("got %+v, expect a finalized crosslink", included)
+	}
+	if included.BeaconBlockNumber == nil || *included.BeaconBlockNumber != 12 {
+		t.Errorf("got beacon block %v, expect 12", included.BeaconBlockNumber)
+	}
+	if included.CrossLink != cl {
+		t.Error("expect the crosslink")
+	}
+
+	legacy := newRPCCrossLinkStatus(1, 7, &core.CrossLinkStatus{CrossLink: cl, Finalized: true})
+	if !legacy.Included || legacy.BeaconBlockNumber != nil {
+		t.Errorf("got %+v, expect no beacon block", legacy)
+	}
+}
@@ -0,0 +1,57 @@
+package apiv2
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core"
+)
+
+// RPCCXTraceEvent is a stage of the lifecycle of a cross-shard transaction,
+// observed in a block of a shard: source-included, receipt-exported,
+// beacon-confirmed or destination-applied
+type RPCCXTraceEvent struct {
+	Stage       string      `json:"stage"`
+	ShardID     uint32      `json:"shardID"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Timestamp   int64       `json:"timestamp"`
+}
+
+// RPCCXTrace is the lifecycle of a cross-shard transaction, correlated across
+// the shards by the hash of the transaction on its source shard
+type RPCCXTrace struct {
+	CorrelationID common.Hash       `json:"correlationID"`
+	FromShardID   uint32            `json:"fromShardID"`
+	ToShardID     uint32            `json:"toShardID"`
+	Delivered     bool              `json:"delivered"`
+	Events        []RPCCXTraceEvent `json:"events"`
+}
+
+func newRPCCXTrace(trace *core.CXTrace) *RPCCXTrace {
+	result := &RPCCXTrace{
+		CorrelationID: trace.TxHash,
+		FromShardID:   trace.FromShardID,
+		ToShardID:     trace.ToShardID,
+		Delivered:     trace.Delivered(),
+		Events:        []RPCCXTraceEvent{},
+	}
+	for _, event := range trace.Events {
+		result.Events = append(result.Events, RPCCXTraceEvent(event))
+	}
+	return result
+}
+
+// TraceCrossShardTransaction returns the lifecycle of the cross-shard
+// transaction of the hash, from its inclusion on the source shard to its
+// credit on the destination shard, as far as the shards kept by the node
+// observed it.
+func (s *PublicTransactionPoolAPI) TraceCrossShardTransaction(
+	ctx context.Context, hash common.Hash,
+) (*RPCCXTrace, error) {
+	trace, err := s.b.TraceCrossShardTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCCXTrace(trace), nil
+}
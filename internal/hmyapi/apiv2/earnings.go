@@ -0,0 +1,244 @@
+package apiv2
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/internal/chain"
+	internal_common "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/shard"
+)
+
+// RPCEarnings are the rewards of an account, or of all the validators,
+// during an epoch
+type RPCEarnings struct {
+	Epoch   uint64   `json:"epoch"`
+	Amount  *big.Int `json:"amount"`
+	Payouts uint64   `json:"payouts"`
+}
+
+// RPCDelegationEarnings are the rewards of a delegator from a validator
+// during an epoch
+type RPCDelegationEarnings struct {
+	Validator string   `json:"validator"`
+	Amount    *big.Int `json:"amount"`
+	Payouts   uint64   `json:"payouts"`
+}
+
+// RPCDelegatorEarnings are the rewards of a delegator during an epoch, per
+// validator
+type RPCDelegatorEarnings struct {
+	Epoch       uint64                  `json:"epoch"`
+	Amount      *big.Int                `json:"amount"`
+	Delegations []RPCDelegationEarnings `json:"delegations"`
+}
+
+// RPCDelegatorPayout is the share of a payout credited to a delegator
+type RPCDelegatorPayout struct {
+	Delegator string   `json:"delegator"`
+	Amount    *big.Int `json:"amount"`
+}
+
+// RPCPayout is a block reward paid to a validator for the signatures of one
+// of its keys of a shard
+type RPCPayout struct {
+	ShardID    uint32               `json:"shardID"`
+	Validator  string               `json:"validator"`
+	EarningKey string               `json:"earningKey"`
+	Amount     *big.Int             `json:"amount"`
+	Delegators []RPCDelegatorPayout `json:"delegators"`
+}
+
+// RPCBlockPayouts are the block rewards paid by a beacon chain block
+type RPCBlockPayouts struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Epoch       uint64      `json:"epoch"`
+	Payouts     []RPCPayout `json:"payouts"`
+}
+
+// RPCRewardEstimate are the block rewards the next beacon chain block would
+// pay, split between the delegators of the validators
+type RPCRewardEstimate struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	Epoch       uint64      `json:"epoch"`
+	Total       *big.Int    `json:"total"`
+	Remainder   *big.Int    `json:"remainder"`
+	Payouts     []RPCPayout `json:"payouts"`
+}
+
+func newRPCEarnings(earnings []chain.Earnings) []RPCEarnings {
+	result := make([]RPCEarnings, len(earnings))
+	for i, e := range earnings {
+		result[i] = RPCEarnings{Epoch: e.Epoch, Amount: e.Amount, Payouts: e.Payouts}
+	}
+	return result
+}
+
+func newRPCPayout(
+	shardID uint32, validator common.Address, earningKey shard.BLSPublicKey,
+	amount *big.Int, shares []reward.DelegatorPayout,
+) (RPCPayout, error) {
+	bech32, err := internal_common.AddressToBech32(validator)
+	if err != nil {
+		return RPCPayout{}, err
+	}
+	delegators := make([]RPCDelegatorPayout, len(shares))
+	for i, share := range shares {
+		if delegators[i].Delegator, err = internal_common.AddressToBech32(share.Delegator); err != nil {
+			return RPCPayout{}, err
+		}
+		delegators[i].Amount = share.Amount
+	}
+	return RPCPayout{
+		ShardID:    shardID,
+		Validator:  bech32,
+		EarningKey: earningKey.Hex(),
+		Amount:     amount,
+		Delegators: delegators,
+	}, nil
+}
+
+func newRPCBlockPayouts(payouts *chain.BlockPayouts) (*RPCBlockPayouts, error) {
+	result := &RPCBlockPayouts{
+		BlockNumber: payouts.BlockNum,
+		BlockHash:   payouts.BlockHash,
+		Epoch:       payouts.Epoch,
+		Payouts:     make([]RPCPayout, len(payouts.Payouts)),
+	}
+	for i, payout := range payouts.Payouts {
+		rpcPayout, err := newRPCPayout(
+			payout.ShardID, payout.Validator, payout.EarningKey, payout.Amount, payout.Delegators,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Payouts[i] = rpcPayout
+	}
+	return result, nil
+}
+
+func newRPCRewardEstimate(header *block.Header, breakdown *reward.Breakdown) (*RPCRewardEstimate, error) {
+	result := &RPCRewardEstimate{
+		BlockNumber: header.Number().Uint64(),
+		Epoch:       header.Epoch().Uint64(),
+		Total:       breakdown.Total,
+		Remainder:   breakdown.Remainder,
+		Payouts:     []RPCPayout{},
+	}
+	for _, shardPayouts := range breakdown.Shards {
+		for _, signer := range shardPayouts.Signers {
+			rpcPayout, err := newRPCPayout(
+				shardPayouts.ShardID, signer.Validator, signer.EarningKey, signer.Amount, signer.Delegators,
+			)
+			if err != nil {
+				return nil, err
+			}
+			result.Payouts = append(result.Payouts, rpcPayout)
+		}
+	}
+	return result, nil
+}
+
+// GetBlockPayouts returns the block rewards paid by the beacon chain block of
+// the number, split between the delegators of the validators, from the
+// payout audit log of the node.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"hmyv2_getBlockPayouts","params":[1024],"id":1}' http://localhost:9500
+func (s *PublicBlockChainAPI) GetBlockPayouts(ctx context.Context, blockNum uint64) (*RPCBlockPayouts, error) {
+	audit, err := s.b.GetPayoutAudit()
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := audit.BlockPayouts(blockNum)
+	if err != nil || payouts == nil {
+		return nil, err
+	}
+	return newRPCBlockPayouts(payouts)
+}
+
+// GetEpochEarnings returns the block rewards paid to all the validators
+// during the epochs from fromEpoch to toEpoch, from the payout audit log of
+// the node.
+func (s *PublicBlockChainAPI) GetEpochEarnings(ctx context.Context, fromEpoch, toEpoch uint64) ([]RPCEarnings, error) {
+	audit, err := s.b.GetPayoutAudit()
+	if err != nil {
+		return nil, err
+	}
+	earnings, err := audit.EpochEarnings(fromEpoch, toEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCEarnings(earnings), nil
+}
+
+// GetValidatorEarnings returns the block rewards paid to the validator during
+// the epochs from fromEpoch to toEpoch, before their split between its
+// delegators, from the payout audit log of the node.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"hmyv2_getValidatorEarnings","params":["one1...",180,190],"id":1}' http://localhost:9500
+func (s *PublicBlockChainAPI) GetValidatorEarnings(
+	ctx context.Context, address string, fromEpoch, toEpoch uint64,
+) ([]RPCEarnings, error) {
+	audit, err := s.b.GetPayoutAudit()
+	if err != nil {
+		return nil, err
+	}
+	earnings, err := audit.ValidatorEarnings(
+		internal_common.ParseAddr(address), fromEpoch, toEpoch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCEarnings(earnings), nil
+}
+
+// GetDelegatorEarnings returns the block rewards credited to the delegator
+// during the epochs from fromEpoch to toEpoch, per validator, from the payout
+// audit log of the node.
+func (s *PublicBlockChainAPI) GetDelegatorEarnings(
+	ctx context.Context, address string, fromEpoch, toEpoch uint64,
+) ([]RPCDelegatorEarnings, error) {
+	audit, err := s.b.GetPayoutAudit()
+	if err != nil {
+		return nil, err
+	}
+	earnings, err := audit.DelegatorEarnings(
+		internal_common.ParseAddr(address), fromEpoch, toEpoch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]RPCDelegatorEarnings, len(earnings))
+	for i, e := range earnings {
+		delegations := make([]RPCDelegationEarnings, len(e.Delegations))
+		for j, delegation := range e.Delegations {
+			validator, err := internal_common.AddressToBech32(delegation.Validator)
+			if err != nil {
+				return nil, err
+			}
+			delegations[j] = RPCDelegationEarnings{
+				Validator: validator, Amount: delegation.Amount, Payouts: delegation.Payouts,
+			}
+		}
+		result[i] = RPCDelegatorEarnings{Epoch: e.Epoch, Amount: e.Amount, Delegations: delegations}
+	}
+	return result, nil
+}
+
+// EstimateBlockRewards returns the block rewards the next block of the beacon
+// chain would pay with the commit signatures of the current block and the
+// pending crosslinks, split between the delegators of the validators, for
+// the validators to preview their earnings before the block is finalized.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"hmyv2_estimateBlockRewards","params":[],"id":1}' http://localhost:9500
+func (s *PublicBlockChainAPI) EstimateBlockRewards(ctx context.Context) (*RPCRewardEstimate, error) {
+	header, payout, err := s.b.EstimateBlockRewards()
+	if err != nil {
+		return nil, err
+	}
+	return newRPCRewardEstimate(header, payout.ReadBreakdown())
+}
@@ -0,0 +1,53 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/reward"
+	internal_common "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestNewRPCRewardEstimate(t *testing.T) {
+	validator, delegator := common.Address{1}, common.Address{2}
+	header := blockfactory.NewTestHeader().With().
+		Number(big.NewInt(101)).Epoch(big.NewInt(7)).Header()
+	breakdown := reward.NewBreakdown(&reward.CompletedRound{
+		Total:     big.NewInt(300),
+		Remainder: big.NewInt(1),
+		BeaconchainAward: []reward.Payout{{
+			Addr: validator, NewlyEarned: big.NewInt(100), EarningKey: shard.BLSPublicKey{1},
+			Delegators: []reward.DelegatorPayout{
+				{Delegator: validator, Amount: big.NewInt(60)},
+				{Delegator: delegator, Amount: big.NewInt(40)},
+			},
+		}},
+		ShardChainAward: []reward.Payout{{
+			ShardID: 1, Addr: delegator, NewlyEarned: big.NewInt(200), EarningKey: shard.BLSPublicKey{2},
+		}},
+	})
+	result, err := newRPCRewardEstimate(header, breakdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.BlockNumber != 101 || result.Epoch != 7 ||
+		result.Total.Int64() != 300 || result.Remainder.Int64() != 1 {
+		t.Errorf("got estimate %+v", result)
+	}
+	if len(result.Payouts) != 2 {
+		t.Fatalf("got %d payouts, expect 2", len(result.Payouts))
+	}
+	beacon, shard1 := result.Payouts[0], result.Payouts[1]
+	if beacon.ShardID != 0 || beacon.Validator != internal_common.MustAddressToBech32(validator) ||
+		len(beacon.Delegators) != 2 ||
+		beacon.Delegators[1].Delegator != internal_common.MustAddressToBech32(delegator) ||
+		beacon.Delegators[1].Amount.Int64() != 40 {
+		t.Errorf("got beacon payout %+v", beacon)
+	}
+	if shard1.ShardID != 1 || shard1.Amount.Int64() != 200 || len(shard1.Delegators) != 0 {
+		t.Errorf("got shard payout %+v", shard1)
+	}
+}
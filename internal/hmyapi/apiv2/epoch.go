@@ -0,0 +1,193 @@
+package apiv2
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultEpochScheduleCount = 5
+	maxEpochScheduleCount     = 100
+)
+
+// EpochBlockRange is the range of beacon chain blocks belonging to an epoch
+type EpochBlockRange struct {
+	Epoch      uint64 `json:"epoch"`
+	FirstBlock uint64 `json:"firstBlock"`
+	LastBlock  uint64 `json:"lastBlock"`
+}
+
+// EpochProgress reports how far the chain has advanced in the current epoch
+type EpochProgress struct {
+	EpochBlockRange
+	CurrentBlock     uint64  `json:"currentBlock"`
+	BlocksElapsed    uint64  `json:"blocksElapsed"`
+	BlocksRemaining  uint64  `json:"blocksRemaining"`
+	Progress         float64 `json:"progress"`
+	ProjectedEndTime uint64  `json:"projectedEndTime"`
+}
+
+// EpochBoundary is a projected start of an upcoming epoch
+type EpochBoundary struct {
+	Epoch              uint64 `json:"epoch"`
+	FirstBlock         uint64 `json:"firstBlock"`
+	ProjectedTimestamp uint64 `json:"projectedTimestamp"`
+}
+
+// ForkActivation is the activation point of a chain config fork
+type ForkActivation struct {
+	Name               string `json:"name"`
+	Epoch              uint64 `json:"epoch"`
+	FirstBlock         uint64 `json:"firstBlock"`
+	Activated          bool   `json:"activated"`
+	ProjectedTimestamp uint64 `json:"projectedTimestamp,omitempty"`
+}
+
+// EpochSchedule holds the projected upcoming epoch boundaries and fork activations
+type EpochSchedule struct {
+	CurrentBlock     uint64           `json:"currentBlock"`
+	CurrentEpoch     uint64           `json:"currentEpoch"`
+	CurrentTimestamp uint64           `json:"currentTimestamp"`
+	BlockPeriod      uint64           `json:"blockPeriod"`
+	Boundaries       []EpochBoundary  `json:"boundaries"`
+	Forks            []ForkActivation `json:"forks"`
+}
+
+func newEpochBlockRange(sched shardingconfig.Schedule, epoch uint64) EpochBlockRange {
+	return EpochBlockRange{
+		Epoch:      epoch,
+		FirstBlock: shardingconfig.EpochFirstBlock(sched, epoch),
+		LastBlock:  sched.EpochLastBlock(epoch),
+	}
+}
+
+// projectBlockTime estimates the time at which block will be produced,
+// assuming one block every period from the current block onwards.
+func projectBlockTime(
+	curBlock, curTime uint64, period time.Duration, block uint64,
+) uint64 {
+	if block <= curBlock {
+		return curTime
+	}
+	return curTime + uint64((time.Duration(block-curBlock) * period).Seconds())
+}
+
+func newEpochProgress(
+	sched shardingconfig.Schedule, curBlock, curTime uint64, period time.Duration,
+) *EpochProgress {
+	epoch := sched.CalcEpochNumber(curBlock).Uint64()
+	r := newEpochBlockRange(sched, epoch)
+	total := r.LastBlock - r.FirstBlock + 1
+	elapsed := curBlock - r.FirstBlock + 1
+	return &EpochProgress{
+		EpochBlockRange:  r,
+		CurrentBlock:     curBlock,
+		BlocksElapsed:    elapsed,
+		BlocksRemaining:  total - elapsed,
+		Progress:         float64(elapsed) / float64(total),
+		ProjectedEndTime: projectBlockTime(curBlock, curTime, period, r.LastBlock),
+	}
+}
+
+func newEpochSchedule(
+	sched shardingconfig.Schedule, config *params.ChainConfig,
+	curBlock, curTime uint64, period time.Duration, count int,
+) *EpochSchedule {
+	epoch := sched.CalcEpochNumber(curBlock).Uint64()
+	result := &EpochSchedule{
+		CurrentBlock:     curBlock,
+		CurrentEpoch:     epoch,
+		CurrentTimestamp: curTime,
+		BlockPeriod:      uint64(period.Seconds()),
+		Boundaries:       []EpochBoundary{},
+		Forks:            []ForkActivation{},
+	}
+	for i := 1; i <= count; i++ {
+		next := epoch + uint64(i)
+		first := shardingconfig.EpochFirstBlock(sched, next)
+		result.Boundaries = append(result.Boundaries, EpochBoundary{
+			Epoch:              next,
+			FirstBlock:         first,
+			ProjectedTimestamp: projectBlockTime(curBlock, curTime, period, first),
+		})
+	}
+	for _, fork := range config.Forks() {
+		// forks scheduled for EpochTBD are not planned yet
+		if fork.Epoch == nil || fork.Epoch.Cmp(params.EpochTBD) >= 0 {
+			continue
+		}
+		forkEpoch := fork.Epoch.Uint64()
+		activation := ForkActivation{
+			Name:       fork.Name,
+			Epoch:      forkEpoch,
+			FirstBlock: shardingconfig.EpochFirstBlock(sched, forkEpoch),
+			Activated:  forkEpoch <= epoch,
+		}
+		if !activation.Activated {
+			activation.ProjectedTimestamp = projectBlockTime(
+				curBlock, curTime, period, activation.FirstBlock,
+			)
+		}
+		result.Forks = append(result.Forks, activation)
+	}
+	return result
+}
+
+// GetEpochBlockRange returns the first and last beacon chain block of the given epoch.
+func (s *PublicBlockChainAPI) GetEpochBlockRange(epoch uint64) (*EpochBlockRange, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	r := newEpochBlockRange(shard.Schedule, epoch)
+	return &r, nil
+}
+
+// GetEpochProgress returns the block range of the current epoch, how many
+// blocks of it have been produced and when it is projected to end.
+func (s *PublicBlockChainAPI) GetEpochProgress(ctx context.Context) (*EpochProgress, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return newEpochProgress(
+		shard.Schedule, header.Number().Uint64(), header.Time().Uint64(),
+		nodeconfig.GetBlockPeriod(),
+	), nil
+}
+
+// GetEpochSchedule returns the projected timestamps of the next count epoch
+// boundaries and of all the chain config fork activations.
+// If count is 0, the next defaultEpochScheduleCount boundaries are returned.
+func (s *PublicBlockChainAPI) GetEpochSchedule(
+	ctx context.Context, count int,
+) (*EpochSchedule, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	if count < 0 || count > maxEpochScheduleCount {
+		return nil, errors.Errorf(
+			"count %d must be between 0 and %d", count, maxEpochScheduleCount,
+		)
+	}
+	if count == 0 {
+		count = defaultEpochScheduleCount
+	}
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return newEpochSchedule(
+		shard.Schedule, s.b.ChainConfig(), header.Number().Uint64(),
+		header.Time().Uint64(), nodeconfig.GetBlockPeriod(), count,
+	), nil
+}
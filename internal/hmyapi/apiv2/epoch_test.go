@@ -0,0 +1,95 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/params"
+)
+
+func TestNewEpochBlockRange(t *testing.T) {
+	tests := []struct {
+		epoch       uint64
+		first, last uint64
+	}{
+		{0, 0, 9},
+		{1, 10, 14},
+		{2, 15, 19},
+	}
+	for i, test := range tests {
+		r := newEpochBlockRange(shardingconfig.LocalnetSchedule, test.epoch)
+		if r.FirstBlock != test.first || r.LastBlock != test.last {
+			t.Errorf(
+				"index %d: got [%d, %d], expect [%d, %d]",
+				i, r.FirstBlock, r.LastBlock, test.first, test.last,
+			)
+		}
+	}
+}
+
+func TestNewEpochProgress(t *testing.T) {
+	progress := newEpochProgress(
+		shardingconfig.LocalnetSchedule, 12, 1000, 2*time.Second,
+	)
+	if progress.Epoch != 1 {
+		t.Errorf("got epoch %d, expect 1", progress.Epoch)
+	}
+	if progress.BlocksElapsed != 3 || progress.BlocksRemaining != 2 {
+		t.Errorf(
+			"got elapsed %d remaining %d, expect 3 and 2",
+			progress.BlocksElapsed, progress.BlocksRemaining,
+		)
+	}
+	if progress.ProjectedEndTime != 1004 {
+		t.Errorf("got projected end %d, expect 1004", progress.ProjectedEndTime)
+	}
+	// last block of the epoch is fully elapsed
+	progress = newEpochProgress(
+		shardingconfig.LocalnetSchedule, 14, 1000, 2*time.Second,
+	)
+	if progress.Progress != 1 || progress.BlocksRemaining != 0 {
+		t.Errorf("got progress %f remaining %d, expect 1 and 0",
+			progress.Progress, progress.BlocksRemaining,
+		)
+	}
+}
+
+func TestNewEpochSchedule(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:         params.TestChainID,
+		CrossTxEpoch:    big.NewInt(0),
+		StakingEpoch:    big.NewInt(3),
+		PreStakingEpoch: big.NewInt(2),
+		S3Epoch:         params.EpochTBD,
+	}
+	sched := newEpochSchedule(
+		shardingconfig.LocalnetSchedule, config, 12, 1000, 2*time.Second, 2,
+	)
+	if len(sched.Boundaries) != 2 {
+		t.Fatalf("got %d boundaries, expect 2", len(sched.Boundaries))
+	}
+	if b := sched.Boundaries[0]; b.Epoch != 2 || b.FirstBlock != 15 ||
+		b.ProjectedTimestamp != 1006 {
+		t.Errorf("unexpected first boundary %+v", b)
+	}
+	if b := sched.Boundaries[1]; b.Epoch != 3 || b.FirstBlock != 20 ||
+		b.ProjectedTimestamp != 1016 {
+		t.Errorf("unexpected second boundary %+v", b)
+	}
+	forks := map[string]ForkActivation{}
+	for _, fork := range sched.Forks {
+		forks[fork.Name] = fork
+	}
+	if len(forks) != 3 {
+		t.Fatalf("got %d forks, expect 3 (nil and TBD epochs skipped)", len(forks))
+	}
+	if f := forks["cross-tx"]; !f.Activated || f.ProjectedTimestamp != 0 {
+		t.Errorf("unexpected cross-tx activation %+v", f)
+	}
+	if f := forks["staking"]; f.Activated || f.FirstBlock != 20 ||
+		f.ProjectedTimestamp != 1016 {
+		t.Errorf("unexpected staking activation %+v", f)
+	}
+}
@@ -15,4 +15,8 @@ var (
 	ErrNotBeaconShard = errors.New("cannot call this rpc on non beaconchain node")
 	// ErrRequestedBlockTooHigh when given block is greater than latest block number
 	ErrRequestedBlockTooHigh = errors.New("requested block number greater than current block number")
+	// ErrBlockNotFound when the requested block is not in the chain of the node
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrTransactionNotFound when the requested transaction is not finalized in the chain of the node
+	ErrTransactionNotFound = errors.New("transaction not found")
 )
@@ -0,0 +1,124 @@
+package apiv2
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/core"
+	internal_common "github.com/harmony-one/harmony/internal/common"
+)
+
+// RPCForkBlock is a block off the canonical chain, with the coinbase and the
+// BLS key of the leader which proposed it
+type RPCForkBlock struct {
+	Number    uint64      `json:"number"`
+	Hash      common.Hash `json:"hash"`
+	Leader    string      `json:"leader"`
+	LeaderKey string      `json:"leaderKey"`
+}
+
+// RPCForkSegment is a chain segment observed competing with the canonical
+// chain, forked from its ancestor block
+type RPCForkSegment struct {
+	AncestorNumber uint64         `json:"ancestorNumber"`
+	AncestorHash   common.Hash    `json:"ancestorHash"`
+	Blocks         []RPCForkBlock `json:"blocks"`
+	FirstSeen      uint64         `json:"firstSeen"`
+	LastSeen       uint64         `json:"lastSeen"`
+}
+
+// RPCReorgEvent is a reorganization of the canonical chain, whose blocks
+// above the common ancestor were replaced by the blocks of another segment
+type RPCReorgEvent struct {
+	ShardID        uint32         `json:"shardID"`
+	Depth          uint64         `json:"depth"`
+	AncestorNumber uint64         `json:"ancestorNumber"`
+	AncestorHash   common.Hash    `json:"ancestorHash"`
+	Replaced       []RPCForkBlock `json:"replaced"`
+	Replacement    RPCForkBlock   `json:"replacement"`
+	Time           uint64         `json:"time"`
+}
+
+func newRPCForkBlock(fork core.ForkBlock) (RPCForkBlock, error) {
+	leader, err := internal_common.AddressToBech32(fork.Leader)
+	if err != nil {
+		return RPCForkBlock{}, err
+	}
+	return RPCForkBlock{
+		Number: fork.Number, Hash: fork.Hash, Leader: leader, LeaderKey: fork.LeaderKey,
+	}, nil
+}
+
+func newRPCForkBlocks(forks []core.ForkBlock) ([]RPCForkBlock, error) {
+	result := make([]RPCForkBlock, len(forks))
+	for i, fork := range forks {
+		var err error
+		if result[i], err = newRPCForkBlock(fork); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetRecentReorgs returns the recent reorgs of the canonical chain of the
+// node, the latest first, with the keys of the leaders of the replaced and
+// the replacing blocks.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"hmyv2_getRecentReorgs","params":[],"id":1}' http://localhost:9500
+func (s *PublicBlockChainAPI) GetRecentReorgs() ([]RPCReorgEvent, error) {
+	reorgs := s.b.GetRecentReorgs()
+	result := make([]RPCReorgEvent, len(reorgs))
+	for i, reorg := range reorgs {
+		replaced, err := newRPCForkBlocks(reorg.Replaced)
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := newRPCForkBlock(reorg.Replacement)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = RPCReorgEvent{
+			ShardID:        reorg.ShardID,
+			Depth:          reorg.Depth,
+			AncestorNumber: reorg.AncestorNumber,
+			AncestorHash:   reorg.AncestorHash,
+			Replaced:       replaced,
+			Replacement:    replacement,
+			Time:           reorg.Time,
+		}
+	}
+	return result, nil
+}
+
+// GetForkSegments returns the recent chain segments observed competing with
+// the canonical chain of the node, the latest first.
+func (s *PublicBlockChainAPI) GetForkSegments() ([]RPCForkSegment, error) {
+	segments := s.b.GetForkSegments()
+	result := make([]RPCForkSegment, len(segments))
+	for i, segment := range segments {
+		blocks, err := newRPCForkBlocks(segment.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = RPCForkSegment{
+			AncestorNumber: segment.AncestorNumber,
+			AncestorHash:   segment.AncestorHash,
+			Blocks:         blocks,
+			FirstSeen:      segment.FirstSeen,
+			LastSeen:       segment.LastSeen,
+		}
+	}
+	return result, nil
+}
+
+// GetRawForkBlock returns the RLP encoding of the fork block of the hash,
+// retained off the canonical chain for forensic analysis.
+func (s *PublicRawAPI) GetRawForkBlock(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	block := s.b.GetForkBlock(hash)
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+	return rlp.EncodeToBytes(block)
+}
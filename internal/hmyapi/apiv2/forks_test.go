@@ -0,0 +1,30 @@
+package apiv2
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/core"
+	internal_common "github.com/harmony-one/harmony/internal/common"
+)
+
+func TestNewRPCForkBlocks(t *testing.T) {
+	forks := []core.ForkBlock{
+		{Number: 5, Hash: common.Hash{5}, Leader: common.Address{1}, LeaderKey: "0xab"},
+		{Number: 6, Hash: common.Hash{6}, Leader: common.Address{2}},
+	}
+	result, err := newRPCForkBlocks(forks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d blocks, expect 2", len(result))
+	}
+	for i, fork := range forks {
+		leader := internal_common.MustAddressToBech32(fork.Leader)
+		if result[i].Number != fork.Number || result[i].Hash != fork.Hash ||
+			result[i].Leader != leader || result[i].LeaderKey != fork.LeaderKey {
+			t.Errorf("index %d: got %+v, expect %+v", i, result[i], fork)
+		}
+	}
+}
@@ -62,3 +62,9 @@ type NodeMetadata struct {
 func (s *PublicHarmonyAPI) GetNodeMetadata() commonRPC.NodeMetadata {
 	return s.b.GetNodeMetadata()
 }
+
+// GetHealthStatus reports the sync lag of the chains the answering node
+// tracks, its consensus participation and whether it is ready to serve
+func (s *PublicHarmonyAPI) GetHealthStatus() commonRPC.HealthStatus {
+	return s.b.GetHealthStatus()
+}
@@ -0,0 +1,321 @@
+package apiv2
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/consensus/signature"
+	"github.com/harmony-one/harmony/core"
+	internal_common "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// RPCCommitteeMember is one slot of the committee which signs the blocks of an epoch
+type RPCCommitteeMember struct {
+	Address        string       `json:"address"`
+	BLSPublicKey   string       `json:"blsPublicKey"`
+	EffectiveStake *numeric.Dec `json:"effectiveStake"`
+}
+
+// RPCCommittee is the committee of a shard for an epoch, in slot order,
+// which is the order of the bits in the commit bitmap
+type RPCCommittee struct {
+	ShardID uint32               `json:"shardID"`
+	Epoch   uint64               `json:"epoch"`
+	Hash    common.Hash          `json:"hash"`
+	Members []RPCCommitteeMember `json:"members"`
+}
+
+// RPCHeaderProof is a block header together with the quorum certificate which
+// finalized it and the committee needed to verify the certificate
+type RPCHeaderProof struct {
+	BlockNumber   uint64        `json:"blockNumber"`
+	BlockHash     common.Hash   `json:"blockHash"`
+	ShardID       uint32        `json:"shardID"`
+	Epoch         uint64        `json:"epoch"`
+	ViewID        uint64        `json:"viewID"`
+	Header        hexutil.Bytes `json:"header"`
+	CommitPayload hexutil.Bytes `json:"commitPayload"`
+	CommitSig     hexutil.Bytes `json:"commitSig"`
+	CommitBitmap  hexutil.Bytes `json:"commitBitmap"`
+	Committee     *RPCCommittee `json:"committee"`
+}
+
+// RPCEpochTransitionProof is the proof of the last beacon chain block of an
+// epoch, whose header carries the shard state of the next epoch
+type RPCEpochTransitionProof struct {
+	Epoch      uint64          `json:"epoch"`
+	NextEpoch  uint64          `json:"nextEpoch"`
+	ShardState hexutil.Bytes   `json:"shardState"`
+	Proof      *RPCHeaderProof `json:"proof"`
+}
+
+// RPCShardStateProof is the shard state of an epoch, decoded into the
+// committees of its shards, with the proof of the beacon chain block whose
+// header carries it. The committees hold the slot stakes of the election.
+type RPCShardStateProof struct {
+	Epoch      uint64          `json:"epoch"`
+	Committees []*RPCCommittee `json:"committees"`
+	ShardState hexutil.Bytes   `json:"shardState"`
+	Proof      *RPCHeaderProof `json:"proof"`
+}
+
+// RPCCommitteeCheckpoint is the certificate of the beacon chain epoch
+// transitions since the previous checkpoint, with their RLP encoded headers,
+// their commit bitmaps and the aggregate of their commit signatures
+type RPCCommitteeCheckpoint struct {
+	Epoch         uint64          `json:"epoch"`
+	PrevRoot      common.Hash     `json:"prevRoot"`
+	Root          common.Hash     `json:"root"`
+	Headers       []hexutil.Bytes `json:"headers"`
+	CommitBitmaps []hexutil.Bytes `json:"commitBitmaps"`
+	AggregateSig  hexutil.Bytes   `json:"aggregateSig"`
+}
+
+// chainConfigReader adapts Backend to the chain reader signature helpers need
+type chainConfigReader struct {
+	b Backend
+}
+
+func (r chainConfigReader) Config() *params.ChainConfig {
+	return r.b.ChainConfig()
+}
+
+func newRPCCommitteeMember(slot shard.Slot) (RPCCommitteeMember, error) {
+	oneAddress, err := internal_common.AddressToBech32(slot.EcdsaAddress)
+	if err != nil {
+		return RPCCommitteeMember{}, err
+	}
+	return RPCCommitteeMember{
+		Address:        oneAddress,
+		BLSPublicKey:   slot.BLSPublicKey.Hex(),
+		EffectiveStake: slot.EffectiveStake,
+	}, nil
+}
+
+func newRPCCommittee(committee *shard.Committee, epoch *big.Int) (*RPCCommittee, error) {
+	result := &RPCCommittee{
+		ShardID: committee.ShardID,
+		Epoch:   epoch.Uint64(),
+		Hash:    committee.Hash(),
+		Members: make([]RPCCommitteeMember, len(committee.Slots)),
+	}
+	for i, slot := range committee.Slots {
+		member, err := newRPCCommitteeMember(slot)
+		if err != nil {
+			return nil, err
+		}
+		result.Members[i] = member
+	}
+	return result, nil
+}
+
+func (s *PublicBlockChainAPI) getHeaderProof(
+	ctx context.Context, blockNr rpc.BlockNumber,
+) (*RPCHeaderProof, error) {
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.Errorf("header not found for block %d", blockNr)
+	}
+	sig, bitmap, err := s.b.GetHeaderCommitSig(ctx, rpc.BlockNumber(header.Number().Int64()))
+	if err != nil {
+		return nil, err
+	}
+	committee, err := s.b.GetValidators(header.Epoch())
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, errors.Errorf("committee not found for epoch %v", header.Epoch())
+	}
+	rpcCommittee, err := newRPCCommittee(committee, header.Epoch())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCHeaderProof{
+		BlockNumber: header.Number().Uint64(),
+		BlockHash:   header.Hash(),
+		ShardID:     header.ShardID(),
+		Epoch:       header.Epoch().Uint64(),
+		ViewID:      header.ViewID().Uint64(),
+		Header:      encoded,
+		CommitPayload: signature.ConstructCommitPayload(
+			chainConfigReader{s.b}, header.Epoch(), header.Hash(),
+			header.Number().Uint64(), header.ViewID().Uint64(),
+		),
+		CommitSig:    sig,
+		CommitBitmap: bitmap,
+		Committee:    rpcCommittee,
+	}, nil
+}
+
+// GetHeaderProof returns the RLP encoded header of the given block, the
+// aggregated commit signature and bitmap which finalized it, the signed
+// payload and the committee whose keys the bitmap refers to.
+func (s *PublicBlockChainAPI) GetHeaderProof(
+	ctx context.Context, blockNr uint64,
+) (*RPCHeaderProof, error) {
+	if err := s.isBlockGreaterThanLatest(blockNr); err != nil {
+		return nil, err
+	}
+	return s.getHeaderProof(ctx, rpc.BlockNumber(blockNr))
+}
+
+// GetLatestHeaderProof returns the header proof of the latest block.
+func (s *PublicBlockChainAPI) GetLatestHeaderProof(
+	ctx context.Context,
+) (*RPCHeaderProof, error) {
+	return s.getHeaderProof(ctx, rpc.LatestBlockNumber)
+}
+
+// epochTransitionProof returns the header proof of the last beacon chain
+// block of the epoch and the shard state of the next epoch in its header
+func (s *PublicBlockChainAPI) epochTransitionProof(
+	ctx context.Context, epoch uint64,
+) (*RPCHeaderProof, []byte, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, nil, err
+	}
+	lastBlock := shard.Schedule.EpochLastBlock(epoch)
+	if err := s.isBlockGreaterThanLatest(lastBlock); err != nil {
+		return nil, nil, err
+	}
+	proof, err := s.getHeaderProof(ctx, rpc.BlockNumber(lastBlock))
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(lastBlock))
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, header.ShardState(), nil
+}
+
+// GetEpochTransitionProof returns the header proof of the last beacon chain
+// block of the given epoch along with the shard state of the next epoch it
+// commits to, which lets light clients follow committee rotations.
+func (s *PublicBlockChainAPI) GetEpochTransitionProof(
+	ctx context.Context, epoch uint64,
+) (*RPCEpochTransitionProof, error) {
+	proof, shardState, err := s.epochTransitionProof(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCEpochTransitionProof{
+		Epoch:      epoch,
+		NextEpoch:  epoch + 1,
+		ShardState: shardState,
+		Proof:      proof,
+	}, nil
+}
+
+// GetShardStateProof returns the committees of all shards for the given
+// epoch with their slot stakes, the encoded shard state they are decoded
+// from, and the proof of the beacon chain block whose header carries it.
+// The committees can be checked against the header of the proof, whose
+// quorum certificate is signed by the beacon committee of the previous epoch.
+func (s *PublicBlockChainAPI) GetShardStateProof(
+	ctx context.Context, epoch uint64,
+) (*RPCShardStateProof, error) {
+	if epoch == 0 {
+		return nil, errors.New("shard state of the genesis epoch has no proof")
+	}
+	proof, shardState, err := s.epochTransitionProof(ctx, epoch-1)
+	if err != nil {
+		return nil, err
+	}
+	if len(shardState) == 0 {
+		return nil, errors.Errorf("no shard state for epoch %d", epoch)
+	}
+	return newRPCShardStateProof(epoch, shardState, proof)
+}
+
+func newRPCShardStateProof(
+	epoch uint64, shardState []byte, proof *RPCHeaderProof,
+) (*RPCShardStateProof, error) {
+	state, err := shard.DecodeWrapper(shardState)
+	if err != nil {
+		return nil, err
+	}
+	result := &RPCShardStateProof{
+		Epoch:      epoch,
+		Committees: make([]*RPCCommittee, len(state.Shards)),
+		ShardState: shardState,
+		Proof:      proof,
+	}
+	for i := range state.Shards {
+		committee, err := newRPCCommittee(&state.Shards[i], new(big.Int).SetUint64(epoch))
+		if err != nil {
+			return nil, err
+		}
+		result.Committees[i] = committee
+	}
+	return result, nil
+}
+
+// GetCommitteeCheckpoint returns the committee checkpoint of the given
+// epoch, a multiple of the checkpoint interval. It certifies the epoch
+// transitions since the previous checkpoint with a single aggregated
+// signature, so that superlight clients can move from the committees of the
+// previous checkpoint to the ones of this epoch with one verification.
+func (s *PublicBlockChainAPI) GetCommitteeCheckpoint(
+	ctx context.Context, epoch uint64,
+) (*RPCCommitteeCheckpoint, error) {
+	if err := s.isBeaconShard(); err != nil {
+		return nil, err
+	}
+	if epoch == 0 || epoch%core.CommitteeCheckpointInterval != 0 {
+		return nil, errors.Errorf(
+			"epoch %d is not a multiple of the checkpoint interval %d", epoch, core.CommitteeCheckpointInterval,
+		)
+	}
+	checkpoint, err := s.b.GetCommitteeCheckpoint(epoch)
+	if err != nil {
+		return nil, err
+	}
+	result := &RPCCommitteeCheckpoint{
+		Epoch:         checkpoint.Epoch,
+		PrevRoot:      checkpoint.PrevRoot,
+		Root:          checkpoint.Root,
+		Headers:       make([]hexutil.Bytes, len(checkpoint.Headers)),
+		CommitBitmaps: make([]hexutil.Bytes, len(checkpoint.CommitBitmaps)),
+		AggregateSig:  checkpoint.AggregateSig,
+	}
+	for i, header := range checkpoint.Headers {
+		if result.Headers[i], err = rlp.EncodeToBytes(header); err != nil {
+			return nil, err
+		}
+	}
+	for i, bitmap := range checkpoint.CommitBitmaps {
+		result.CommitBitmaps[i] = bitmap
+	}
+	return result, nil
+}
+
+// GetStateNode returns the state trie node or the contract code with the
+// given hash, for light serving nodes reading the state they pruned from
+// this node. Only values hashing to the requested hash are returned.
+func (s *PublicBlockChainAPI) GetStateNode(
+	ctx context.Context, hash common.Hash,
+) (hexutil.Bytes, error) {
+	node, err := s.b.ChainDb().Get(hash[:])
+	if err != nil || crypto.Keccak256Hash(node) != hash {
+		return nil, errors.Errorf("state node %x not found", hash)
+	}
+	return node, nil
+}
@@ -0,0 +1,76 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestNewRPCCommittee(t *testing.T) {
+	stake := numeric.NewDec(100)
+	committee := &shard.Committee{
+		ShardID: 1,
+		Slots: shard.SlotList{
+			{EcdsaAddress: common.BigToAddress(big.NewInt(1)), BLSPublicKey: shard.BLSPublicKey{1}},
+			{EcdsaAddress: common.BigToAddress(big.NewInt(2)), BLSPublicKey: shard.BLSPublicKey{2}, EffectiveStake: &stake},
+		},
+	}
+	result, err := newRPCCommittee(committee, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ShardID != 1 || result.Epoch != 3 || result.Hash != committee.Hash() {
+		t.Errorf("unexpected committee header %+v", result)
+	}
+	if len(result.Members) != 2 {
+		t.Fatalf("got %d members, expect 2", len(result.Members))
+	}
+	// members must keep slot order so bitmap indices line up
+	for i, member := range result.Members {
+		if member.BLSPublicKey != committee.Slots[i].BLSPublicKey.Hex() {
+			t.Errorf("index %d: got key %s, expect %s",
+				i, member.BLSPublicKey, committee.Slots[i].BLSPublicKey.Hex(),
+			)
+		}
+	}
+	if result.Members[0].EffectiveStake != nil ||
+		!result.Members[1].EffectiveStake.Equal(stake) {
+		t.Errorf("unexpected effective stakes")
+	}
+}
+
+func TestNewRPCShardStateProof(t *testing.T) {
+	stake := numeric.NewDec(100)
+	state := &shard.State{
+		Epoch: big.NewInt(5),
+		Shards: []shard.Committee{
+			{ShardID: 0, Slots: shard.SlotList{{BLSPublicKey: shard.BLSPublicKey{1}}}},
+			{ShardID: 1, Slots: shard.SlotList{{BLSPublicKey: shard.BLSPublicKey{2}, EffectiveStake: &stake}}},
+		},
+	}
+	encoded, err := shard.EncodeWrapper(*state, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := newRPCShardStateProof(5, encoded, &RPCHeaderProof{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Committees) != 2 {
+		t.Fatalf("got %d committees, expect 2", len(result.Committees))
+	}
+	for i, committee := range result.Committees {
+		if committee.Epoch != 5 || committee.Hash != state.Shards[i].Hash() {
+			t.Errorf("committee %d: unexpected committee %+v", i, committee)
+		}
+	}
+	if !result.Committees[1].Members[0].EffectiveStake.Equal(stake) {
+		t.Errorf("unexpected effective stake")
+	}
+	if _, err := newRPCShardStateProof(5, []byte{1, 2}, nil); err == nil {
+		t.Error("expect an invalid shard state to fail")
+	}
+}
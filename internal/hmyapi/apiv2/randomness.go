@@ -0,0 +1,120 @@
+package apiv2
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/vm"
+	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// RPCRandomness is the VRF randomness of a block, generated by its leader
+// from the hash of the previous block, with the BLS proof verifying it
+// against the key of the leader, and the header proof of the block, whose
+// header carries the randomness and the proof
+type RPCRandomness struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Epoch       uint64      `json:"epoch"`
+	// Seed is the hash of the previous block, the input of the VRF
+	Seed       common.Hash   `json:"seed"`
+	Randomness common.Hash   `json:"randomness"`
+	VRFProof   hexutil.Bytes `json:"vrfProof"`
+	// Leader is the BLS public key of the leader which generated the VRF
+	Leader      string          `json:"leader"`
+	HeaderProof *RPCHeaderProof `json:"headerProof"`
+}
+
+// GetBlockRandomness returns the VRF randomness of the given block, which
+// the contracts read from the randomness precompile, with the proofs of the
+// randomness and of the block. Only the beacon chain blocks with a new leader
+// have a VRF.
+func (s *PublicBlockChainAPI) GetBlockRandomness(
+	ctx context.Context, blockNr uint64,
+) (*RPCRandomness, error) {
+	if err := s.isBlockGreaterThanLatest(blockNr); err != nil {
+		return nil, err
+	}
+	header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(blockNr))
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.Errorf("header not found for block %d", blockNr)
+	}
+	if len(header.Vrf()) != vm.RandomnessSize {
+		return nil, errors.Errorf("block %d has no VRF", blockNr)
+	}
+	parent, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(blockNr-1))
+	if err != nil {
+		return nil, err
+	}
+	committee, err := s.b.GetValidators(header.Epoch())
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, errors.Errorf("committee not found for epoch %v", header.Epoch())
+	}
+	leader, err := leaderSlot(committee, header, s.b.ChainConfig().IsStaking(header.Epoch()))
+	if err != nil {
+		return nil, err
+	}
+	leaderKey := new(bls.PublicKey)
+	if err := leader.BLSPublicKey.ToLibBLSPublicKey(leaderKey); err != nil {
+		return nil, err
+	}
+	if err := verifyVRF(leaderKey, parent.Hash(), header.Vrf()); err != nil {
+		return nil, errors.Wrapf(err, "block %d", blockNr)
+	}
+	proof, err := s.getHeaderProof(ctx, rpc.BlockNumber(blockNr))
+	if err != nil {
+		return nil, err
+	}
+	return &RPCRandomness{
+		BlockNumber: blockNr,
+		BlockHash:   header.Hash(),
+		Epoch:       header.Epoch().Uint64(),
+		Seed:        parent.Hash(),
+		Randomness:  common.BytesToHash(header.Vrf()[:32]),
+		VRFProof:    header.Vrf()[32:],
+		Leader:      leader.BLSPublicKey.Hex(),
+		HeaderProof: proof,
+	}, nil
+}
+
+// leaderSlot returns the committee slot of the leader of the block, that of
+// its coinbase, which is the address of the key of the leader from the
+// staking epoch on, and the address of its validator before
+func leaderSlot(committee *shard.Committee, header *block.Header, isStaking bool) (*shard.Slot, error) {
+	for i, member := range committee.Slots {
+		address := member.EcdsaAddress
+		if isStaking {
+			address = utils.GetAddressFromBLSPubKeyBytes(member.BLSPublicKey[:])
+		}
+		if address == header.Coinbase() {
+			return &committee.Slots[i], nil
+		}
+	}
+	return nil, errors.Errorf("no committee member of coinbase %s", header.Coinbase().Hex())
+}
+
+// verifyVRF verifies the VRF randomness and its proof, of the seed, against
+// the key of the leader
+func verifyVRF(leader *bls.PublicKey, seed common.Hash, vrf []byte) error {
+	hash, err := vrf_bls.NewVRFVerifier(leader).ProofToHash(seed[:], vrf[32:])
+	if err != nil {
+		return err
+	}
+	if common.BytesToHash(hash[:]) != common.BytesToHash(vrf[:32]) {
+		return vrf_bls.ErrInvalidVRF
+	}
+	return nil
+}
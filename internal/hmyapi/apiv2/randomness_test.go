@@ -0,0 +1,29 @@
+package apiv2
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/crypto/bls"
+	vrf_bls "github.com/harmony-one/harmony/crypto/vrf/bls"
+)
+
+func TestVerifyVRF(t *testing.T) {
+	key := bls.RandPrivateKey()
+	seed := common.HexToHash("0x1234")
+	vrf, proof := vrf_bls.NewVRFSigner(key).Evaluate(seed[:])
+	output := append(vrf[:], proof...)
+	if err := verifyVRF(key.GetPublicKey(), seed, output); err != nil {
+		t.Fatalf("valid VRF refused: %v", err)
+	}
+	if err := verifyVRF(key.GetPublicKey(), common.HexToHash("0x5678"), output); err == nil {
+		t.Error("VRF of another seed accepted")
+	}
+	if err := verifyVRF(bls.RandPrivateKey().GetPublicKey(), seed, output); err == nil {
+		t.Error("VRF of another leader accepted")
+	}
+	output[0] ^= 1
+	if err := verifyVRF(key.GetPublicKey(), seed, output); err != vrf_bls.ErrInvalidVRF {
+		t.Errorf("got error %v verifying a tampered VRF", err)
+	}
+}
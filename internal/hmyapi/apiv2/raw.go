@@ -0,0 +1,137 @@
+package apiv2
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+// PublicRawAPI provides the RLP encodings of the headers, blocks, receipts and
+// transactions of the chain, as stored in the database of the node, so that
+// external tooling can verify them without access to the database.
+type PublicRawAPI struct {
+	b Backend
+}
+
+// NewPublicRawAPI creates a new API for the RLP encodings of the chain data.
+func NewPublicRawAPI(b Backend) *PublicRawAPI {
+	return &PublicRawAPI{b}
+}
+
+// GetRawHeader returns the RLP encoding of the header of the block number.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"debug_getRawHeader","params":[1],"id":1}' http://localhost:9500
+func (s *PublicRawAPI) GetRawHeader(ctx context.Context, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, ErrBlockNotFound
+	}
+	return rlp.EncodeToBytes(header)
+}
+
+// GetRawHeaderByHash returns the RLP encoding of the header of the block hash.
+func (s *PublicRawAPI) GetRawHeaderByHash(ctx context.Context, blockHash common.Hash) (hexutil.Bytes, error) {
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+	return rlp.EncodeToBytes(block.Header())
+}
+
+// GetRawBlock returns the RLP encoding of the block of the block number.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"debug_getRawBlock","params":[1],"id":1}' http://localhost:9500
+func (s *PublicRawAPI) GetRawBlock(ctx context.Context, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
+	block, err := s.b.BlockByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRawBlock(block)
+}
+
+// GetRawBlockByHash returns the RLP encoding of the block of the block hash.
+func (s *PublicRawAPI) GetRawBlockByHash(ctx context.Context, blockHash common.Hash) (hexutil.Bytes, error) {
+	block, err := s.b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return encodeRawBlock(block)
+}
+
+// GetRawReceipts returns the RLP encodings of the receipts of the block
+// number, in transaction order.
+func (s *PublicRawAPI) GetRawReceipts(ctx context.Context, blockNr rpc.BlockNumber) ([]hexutil.Bytes, error) {
+	header, err := s.b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, ErrBlockNotFound
+	}
+	return s.GetRawReceiptsByHash(ctx, header.Hash())
+}
+
+// GetRawReceiptsByHash returns the RLP encodings of the receipts of the block
+// hash, in transaction order.
+func (s *PublicRawAPI) GetRawReceiptsByHash(ctx context.Context, blockHash common.Hash) ([]hexutil.Bytes, error) {
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipts == nil {
+		return nil, ErrBlockNotFound
+	}
+	return encodeRawReceipts(receipts)
+}
+
+// GetRawTransaction returns the RLP encoding of the finalized transaction of
+// the hash.
+func (s *PublicRawAPI) GetRawTransaction(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	tx, _, _, _ := rawdb.ReadTransaction(s.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return rlp.EncodeToBytes(tx)
+}
+
+// GetRawStakingTransaction returns the RLP encoding of the finalized staking
+// transaction of the hash.
+// Example usage:
+//  curl -H "Content-Type: application/json" -d '{"method":"debug_getRawStakingTransaction","params":["0x..."],"id":1}' http://localhost:9500
+func (s *PublicRawAPI) GetRawStakingTransaction(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	stx, _, _, _ := rawdb.ReadStakingTransaction(s.b.ChainDb(), hash)
+	if stx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return rlp.EncodeToBytes(stx)
+}
+
+func encodeRawBlock(block *types.Block) (hexutil.Bytes, error) {
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+	return rlp.EncodeToBytes(block)
+}
+
+func encodeRawReceipts(receipts types.Receipts) ([]hexutil.Bytes, error) {
+	result := make([]hexutil.Bytes, len(receipts))
+	for i, receipt := range receipts {
+		encoded, err := rlp.EncodeToBytes(receipt)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = encoded
+	}
+	return result, nil
+}
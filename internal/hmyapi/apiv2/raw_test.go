@@ -0,0 +1,57 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+)
+
+func TestEncodeRawBlock(t *testing.T) {
+	to := common.BytesToAddress([]byte{0x11})
+	tx := types.NewTransaction(0, to, 0, big.NewInt(100), 21000, big.NewInt(1), nil)
+	header := blockfactory.NewTestHeader().With().Number(big.NewInt(3)).Header()
+	block := types.NewBlock(header, types.Transactions{tx}, types.Receipts{&types.Receipt{}}, nil, nil, nil)
+
+	encoded, err := encodeRawBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &types.Block{}
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != block.Hash() || decoded.Transactions()[0].Hash() != tx.Hash() {
+		t.Errorf("decoded block %s, expect %s", decoded.Hash().Hex(), block.Hash().Hex())
+	}
+	if _, err := encodeRawBlock(nil); err != ErrBlockNotFound {
+		t.Errorf("got %v, expect %v", err, ErrBlockNotFound)
+	}
+}
+
+func TestEncodeRawReceipts(t *testing.T) {
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000},
+		{Status: types.ReceiptStatusFailed, CumulativeGasUsed: 42000},
+	}
+	encoded, err := encodeRawReceipts(receipts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) != len(receipts) {
+		t.Fatalf("got %d receipts, expect %d", len(encoded), len(receipts))
+	}
+	for i, raw := range encoded {
+		decoded := &types.Receipt{}
+		if err := rlp.DecodeBytes(raw, decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Status != receipts[i].Status ||
+			decoded.CumulativeGasUsed != receipts[i].CumulativeGasUsed {
+			t.Errorf("receipt %d: got %+v, expect %+v", i, decoded, receipts[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package apiv2
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// RPCStakeChange is the change of the effective stake of a slot kept from
+// an epoch to another
+type RPCStakeChange struct {
+	Address      string       `json:"address"`
+	BLSPublicKey string       `json:"blsPublicKey"`
+	FromStake    *numeric.Dec `json:"fromStake"`
+	ToStake      *numeric.Dec `json:"toStake"`
+	Delta        numeric.Dec  `json:"delta"`
+}
+
+// RPCCommitteeDiff is the change of the committee of a shard from an epoch
+// to another. The slots moved from another shard are added slots.
+type RPCCommitteeDiff struct {
+	ShardID      uint32               `json:"shardID"`
+	Added        []RPCCommitteeMember `json:"added"`
+	Removed      []RPCCommitteeMember `json:"removed"`
+	StakeChanges []RPCStakeChange     `json:"stakeChanges"`
+}
+
+// RPCShardStateDiff is the change of the committees of all shards from an
+// epoch to another
+type RPCShardStateDiff struct {
+	FromEpoch uint64             `json:"fromEpoch"`
+	ToEpoch   uint64             `json:"toEpoch"`
+	Shards    []RPCCommitteeDiff `json:"shards"`
+}
+
+// stakeOrZero returns the effective stake, zero for the harmony slots
+func stakeOrZero(stake *numeric.Dec) numeric.Dec {
+	if stake == nil {
+		return numeric.ZeroDec()
+	}
+	return *stake
+}
+
+func newCommitteeDiff(shardID uint32, from, to shard.SlotList) (RPCCommitteeDiff, error) {
+	diff := RPCCommitteeDiff{
+		ShardID:      shardID,
+		Added:        []RPCCommitteeMember{},
+		Removed:      []RPCCommitteeMember{},
+		StakeChanges: []RPCStakeChange{},
+	}
+	previous := make(map[shard.BLSPublicKey]shard.Slot, len(from))
+	for _, slot := range from {
+		previous[slot.BLSPublicKey] = slot
+	}
+	kept := make(map[shard.BLSPublicKey]struct{}, len(to))
+	for _, slot := range to {
+		prev, ok := previous[slot.BLSPublicKey]
+		if !ok {
+			member, err := newRPCCommitteeMember(slot)
+			if err != nil {
+				return diff, err
+			}
+			diff.Added = append(diff.Added, member)
+			continue
+		}
+		kept[slot.BLSPublicKey] = struct{}{}
+		fromStake, toStake := stakeOrZero(prev.EffectiveStake), stakeOrZero(slot.EffectiveStake)
+		if fromStake.Equal(toStake) {
+			continue
+		}
+		member, err := newRPCCommitteeMember(slot)
+		if err != nil {
+			return diff, err
+		}
+		diff.StakeChanges = append(diff.StakeChanges, RPCStakeChange{
+			Address:      member.Address,
+			BLSPublicKey: member.BLSPublicKey,
+			FromStake:    prev.EffectiveStake,
+			ToStake:      slot.EffectiveStake,
+			Delta:        toStake.Sub(fromStake),
+		})
+	}
+	for _, slot := range from {
+		if _, ok := kept[slot.BLSPublicKey]; ok {
+			continue
+		}
+		member, err := newRPCCommitteeMember(slot)
+		if err != nil {
+			return diff, err
+		}
+		diff.Removed = append(diff.Removed, member)
+	}
+	return diff, nil
+}
+
+// newShardStateDiff returns the changes of the committees of the shards of
+// the two shard states, in shard order
+func newShardStateDiff(fromEpoch, toEpoch uint64, from, to *shard.State) (*RPCShardStateDiff, error) {
+	slots := map[uint32][2]shard.SlotList{}
+	for _, committee := range from.Shards {
+		s := slots[committee.ShardID]
+		s[0] = committee.Slots
+		slots[committee.ShardID] = s
+	}
+	for _, committee := range to.Shards {
+		s := slots[committee.ShardID]
+		s[1] = committee.Slots
+		slots[committee.ShardID] = s
+	}
+	shardIDs := make([]uint32, 0, len(slots))
+	for shardID := range slots {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	result := &RPCShardStateDiff{
+		FromEpoch: fromEpoch,
+		ToEpoch:   toEpoch,
+		Shards:    make([]RPCCommitteeDiff, len(shardIDs)),
+	}
+	for i, shardID := range shardIDs {
+		diff, err := newCommitteeDiff(shardID, slots[shardID][0], slots[shardID][1])
+		if err != nil {
+			return nil, err
+		}
+		result.Shards[i] = diff
+	}
+	return result, nil
+}
+
+// GetShardStateDiff returns the committee changes of each shard from the
+// shard state of fromEpoch to the one of toEpoch: the keys added and
+// removed, and the changes of the effective stakes of the kept keys.
+func (s *PublicBlockChainAPI) GetShardStateDiff(
+	fromEpoch, toEpoch uint64,
+) (*RPCShardStateDiff, error) {
+	// the shard state of the next epoch is known before it starts
+	if latest := s.b.CurrentBlock().Epoch().Uint64() + 1; fromEpoch > latest || toEpoch > latest {
+		return nil, errors.Errorf("epoch is greater than the latest epoch %d", latest)
+	}
+	from, err := s.b.GetShardStateOfEpoch(new(big.Int).SetUint64(fromEpoch))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read shard state of epoch %d", fromEpoch)
+	}
+	to, err := s.b.GetShardStateOfEpoch(new(big.Int).SetUint64(toEpoch))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read shard state of epoch %d", toEpoch)
+	}
+	return newShardStateDiff(fromEpoch, toEpoch, from, to)
+}
@@ -0,0 +1,57 @@
+package apiv2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+)
+
+func TestNewShardStateDiff(t *testing.T) {
+	stake, newStake := numeric.NewDec(100), numeric.NewDec(150)
+	slot := func(i byte, stake *numeric.Dec) shard.Slot {
+		return shard.Slot{
+			EcdsaAddress:   common.BigToAddress(big.NewInt(int64(i))),
+			BLSPublicKey:   shard.BLSPublicKey{i},
+			EffectiveStake: stake,
+		}
+	}
+	from := &shard.State{Shards: []shard.Committee{
+		{ShardID: 0, Slots: shard.SlotList{slot(1, nil), slot(2, &stake), slot(3, &stake)}},
+		{ShardID: 1, Slots: shard.SlotList{slot(4, &stake)}},
+	}}
+	// key 3 moves to shard 1, key 2 gets a new stake, key 4 leaves and
+	// shard 2 is new
+	to := &shard.State{Shards: []shard.Committee{
+		{ShardID: 0, Slots: shard.SlotList{slot(1, nil), slot(2, &newStake)}},
+		{ShardID: 1, Slots: shard.SlotList{slot(3, &stake)}},
+		{ShardID: 2, Slots: shard.SlotList{slot(5, &stake)}},
+	}}
+	result, err := newShardStateDiff(1, 2, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Shards) != 3 {
+		t.Fatalf("got %d shards, expect 3", len(result.Shards))
+	}
+	key := func(i byte) string {
+		return shard.BLSPublicKey{i}.Hex()
+	}
+	shard0, shard1, shard2 := result.Shards[0], result.Shards[1], result.Shards[2]
+	if len(shard0.Added) != 0 || len(shard0.Removed) != 1 || shard0.Removed[0].BLSPublicKey != key(3) {
+		t.Errorf("shard 0: got %+v, expect key 3 removed", shard0)
+	}
+	if len(shard0.StakeChanges) != 1 || shard0.StakeChanges[0].BLSPublicKey != key(2) ||
+		!shard0.StakeChanges[0].Delta.Equal(numeric.NewDec(50)) {
+		t.Errorf("shard 0: got stake changes %+v, expect key 2 up 50", shard0.StakeChanges)
+	}
+	if len(shard1.Added) != 1 || shard1.Added[0].BLSPublicKey != key(3) ||
+		len(shard1.Removed) != 1 || shard1.Removed[0].BLSPublicKey != key(4) {
+		t.Errorf("shard 1: got %+v, expect key 3 added and key 4 removed", shard1)
+	}
+	if shard2.ShardID != 2 || len(shard2.Added) != 1 || len(shard2.Removed) != 0 {
+		t.Errorf("shard 2: got %+v, expect key 5 added", shard2)
+	}
+}
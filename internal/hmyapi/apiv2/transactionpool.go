@@ -2,6 +2,7 @@ package apiv2
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -301,6 +302,21 @@ func (s *PublicTransactionPoolAPI) fillStakingTransactionFields(stx *staking.Sta
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	blockHash, blockNumber, _ := rawdb.ReadTxLookupEntry(s.b.ChainDb(), hash)
+	if blockHash == (common.Hash{}) {
+		return s.getTransactionReceipt(ctx, hash)
+	}
+	key := fmt.Sprintf("hmyv2_getTransactionReceipt-%s", hash.Hex())
+	res, err := s.b.CachedResponse(key, blockNumber, func() (interface{}, error) {
+		return s.getTransactionReceipt(ctx, hash)
+	})
+	if res == nil {
+		return nil, err
+	}
+	return res.(map[string]interface{}), err
+}
+
+func (s *PublicTransactionPoolAPI) getTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	var tx *types.Transaction
 	var stx *staking.StakingTransaction
 	var blockHash common.Hash
@@ -319,7 +335,20 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if len(receipts) <= int(index) {
 		return nil, nil
 	}
-	receipt := receipts[index]
+	return s.marshalReceipt(tx, stx, blockHash, blockNumber, index, receipts[index])
+}
+
+func (s *PublicTransactionPoolAPI) marshalReceipt(
+	tx *types.Transaction, stx *staking.StakingTransaction,
+	blockHash common.Hash, blockNumber, index uint64, receipt *types.Receipt,
+) (map[string]interface{}, error) {
+	var err error
+	var hash common.Hash
+	if tx != nil {
+		hash = tx.Hash()
+	} else {
+		hash = stx.Hash()
+	}
 	fields := map[string]interface{}{
 		"blockHash":         blockHash,
 		"blockNumber":       blockNumber,
@@ -356,6 +385,62 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	return fields, nil
 }
 
+// GetBlockReceipts returns the receipts of all the transactions and staking
+// transactions of the given block, in the order they were executed.
+func (s *PublicTransactionPoolAPI) GetBlockReceipts(
+	ctx context.Context, blockNr uint64,
+) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(blockNr))
+	if block == nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("hmyv2_getBlockReceipts-%d", blockNr)
+	res, err := s.b.CachedResponse(key, block.NumberU64(), func() (interface{}, error) {
+		return s.getBlockReceipts(ctx, block)
+	})
+	if res == nil {
+		return nil, err
+	}
+	return res.([]map[string]interface{}), err
+}
+
+func (s *PublicTransactionPoolAPI) getBlockReceipts(
+	ctx context.Context, block *types.Block,
+) ([]map[string]interface{}, error) {
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs, stxs := block.Transactions(), block.StakingTransactions()
+	if len(receipts) != len(txs)+len(stxs) {
+		return nil, errors.Errorf(
+			"block %d has %d receipts for %d transactions",
+			block.NumberU64(), len(receipts), len(txs)+len(stxs),
+		)
+	}
+	// receipts of staking transactions follow the ones of plain transactions
+	result := make([]map[string]interface{}, 0, len(receipts))
+	for i, tx := range txs {
+		fields, err := s.marshalReceipt(
+			tx, nil, block.Hash(), block.NumberU64(), uint64(i), receipts[i],
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fields)
+	}
+	for i, stx := range stxs {
+		fields, err := s.marshalReceipt(
+			nil, stx, block.Hash(), block.NumberU64(), uint64(i), receipts[len(txs)+i],
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fields)
+	}
+	return result, nil
+}
+
 // GetPoolStats returns stats for the tx-pool
 func (s *PublicTransactionPoolAPI) GetPoolStats() (pendingCount, queuedCount int) {
 	return s.b.GetPoolStats()
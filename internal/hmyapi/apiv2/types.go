@@ -309,6 +309,25 @@ func newRPCStakingTransaction(
 		fields = map[string]interface{}{
 			"delegatorAddress": delegatorAddress,
 		}
+	case staking.DirectiveBindSlotKey:
+		rawMsg, err := staking.RLPDecodeStakeMsg(tx.Data(), staking.DirectiveBindSlotKey)
+		if err != nil {
+			return nil
+		}
+		msg, ok := rawMsg.(*staking.BindSlotKey)
+		if !ok {
+			return nil
+		}
+		validatorAddress, err := internal_common.AddressToBech32(msg.ValidatorAddress)
+		if err != nil {
+			return nil
+		}
+		fields = map[string]interface{}{
+			"validatorAddress": validatorAddress,
+			"slotPubKey":       msg.SlotPubKey,
+			"curve":            msg.Curve.String(),
+			"boundPubKey":      msg.BoundPubKey,
+		}
 	case staking.DirectiveDelegate:
 		rawMsg, err := staking.RLPDecodeStakeMsg(tx.Data(), staking.DirectiveDelegate)
 		if err != nil {
@@ -534,6 +553,18 @@ type CallArgs struct {
 	Data     *hexutil.Bytes  `json:"data"`
 }
 
+// RPCSupply is the supply of ONE at the current block of the beacon chain, in
+// ONE
+type RPCSupply struct {
+	Epoch       uint64      `json:"epoch"`
+	Total       numeric.Dec `json:"total-supply"`
+	Circulating numeric.Dec `json:"circulating-supply"`
+	Staked      numeric.Dec `json:"staked-supply"`
+	Issued      numeric.Dec `json:"issued"`
+	Burned      numeric.Dec `json:"burned"`
+	Locked      numeric.Dec `json:"locked"`
+}
+
 // StakingNetworkInfo returns global staking info.
 type StakingNetworkInfo struct {
 	TotalSupply       numeric.Dec `json:"total-supply"`
@@ -541,4 +572,7 @@ type StakingNetworkInfo struct {
 	EpochLastBlock    uint64      `json:"epoch-last-block"`
 	TotalStaking      *big.Int    `json:"total-staking"`
 	MedianRawStake    numeric.Dec `json:"median-raw-stake"`
+	// AvailabilityThreshold is the signing threshold of the validators at
+	// the epoch, at or below which they are set inactive
+	AvailabilityThreshold numeric.Dec `json:"availability-threshold"`
 }
@@ -10,11 +10,13 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/consensus/quorum"
+	"github.com/harmony-one/harmony/consensus/reward"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/state"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/core/vm"
 	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/chain"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv2"
 	commonRPC "github.com/harmony-one/harmony/internal/hmyapi/common"
@@ -31,6 +33,7 @@ type Backend interface {
 	ProtocolVersion() int
 	ChainDb() ethdb.Database
 	SingleFlightRequest(key string, fn func() (interface{}, error)) (interface{}, error)
+	CachedResponse(key string, blockNum uint64, fn func() (interface{}, error)) (interface{}, error)
 	SingleFlightForgetKey(key string)
 	EventMux() *event.TypeMux
 	RPCGasCap() *big.Int // global gas cap for hmy_call over rpc: DoS protection
@@ -51,6 +54,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	GetAccountNonce(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (uint64, error)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeNewStakingTxsEvent(chan<- core.NewStakingTxsEvent) event.Subscription
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
 	GetBalance(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (*big.Int, error)
@@ -71,18 +75,32 @@ type Backend interface {
 	GetDelegationsByDelegatorByBlock(delegator common.Address, block *types.Block) ([]common.Address, []*staking.Delegation)
 	GetValidatorSelfDelegation(addr common.Address) *big.Int
 	GetShardState() (*shard.State, error)
+	GetShardStateOfEpoch(epoch *big.Int) (*shard.State, error)
 	GetCurrentStakingErrorSink() types.TransactionErrorReports
 	GetCurrentTransactionErrorSink() types.TransactionErrorReports
 	GetMedianRawStakeSnapshot() (*committee.CompletedEPoSRound, error)
+	GetElectionAudit(epoch uint64) (*committee.ElectionAudit, error)
 	GetPendingCXReceipts() []*types.CXReceiptsProof
+	TraceCrossShardTransaction(hash common.Hash) (*core.CXTrace, error)
 	GetCurrentUtilityMetrics() (*network.UtilityMetric, error)
 	GetSuperCommittees() (*quorum.Transition, error)
 	GetTotalStakingSnapshot() *big.Int
+	GetSupply() (*network.Supply, error)
 	GetCurrentBadBlocks() []core.BadBlock
+	GetRecentReorgs() []core.ReorgEvent
+	GetForkSegments() []core.ForkSegment
+	GetForkBlock(hash common.Hash) *types.Block
+	GetPayoutAudit() (*chain.PayoutAudit, error)
+	EstimateBlockRewards() (*block.Header, reward.Reader, error)
 	GetLastCrossLinks() ([]*types.CrossLink, error)
+	GetCrossLink(shardID uint32, blockNum uint64) (*types.CrossLink, error)
+	GetCrossLinkStatus(shardID uint32, blockNum uint64) (*core.CrossLinkStatus, error)
 	GetLatestChainHeaders() *block.HeaderPair
 	GetNodeMetadata() commonRPC.NodeMetadata
+	GetHealthStatus() commonRPC.HealthStatus
 	GetBlockSigners(ctx context.Context, blockNr rpc.BlockNumber) (shard.SlotList, *bls.Mask, error)
+	GetHeaderCommitSig(ctx context.Context, blockNr rpc.BlockNumber) ([]byte, []byte, error)
+	GetCommitteeCheckpoint(epoch uint64) (*core.CommitteeCheckpoint, error)
 }
 
 // GetAPIs returns all the APIs.
@@ -112,7 +130,7 @@ func GetAPIs(b Backend) []rpc.API {
 			Namespace: "hmy",
 			Version:   "1.0",
 			Service:   apiv1.NewDebugAPI(b),
-			Public:    true, // FIXME: change to false once IPC implemented
+			Public:    false,
 		},
 		{
 			Namespace: "hmyv2",
@@ -136,7 +154,13 @@ func GetAPIs(b Backend) []rpc.API {
 			Namespace: "hmyv2",
 			Version:   "1.0",
 			Service:   apiv2.NewDebugAPI(b),
-			Public:    true, // FIXME: change to false once IPC implemented
+			Public:    false,
+		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   apiv2.NewPublicRawAPI(b),
+			Public:    true,
 		},
 	}
 }
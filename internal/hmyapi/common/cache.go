@@ -0,0 +1,71 @@
+package common
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// ResponseCache caches RPC responses computed from finalized chain data,
+// keyed by method and params. Every entry remembers the hash of the block it
+// was computed from, so entries orphaned by a reorg are dropped on lookup.
+// A nil ResponseCache is valid and caches nothing.
+type ResponseCache struct {
+	entries *lru.Cache
+	depth   uint64
+}
+
+type cachedResponse struct {
+	blockNum  uint64
+	blockHash common.Hash
+	value     interface{}
+}
+
+// NewResponseCache creates a cache holding up to size responses of blocks at
+// least depth blocks below the chain head. It returns nil if size is not positive.
+func NewResponseCache(size int, depth uint64) *ResponseCache {
+	if size <= 0 {
+		return nil
+	}
+	entries, _ := lru.New(size)
+	return &ResponseCache{entries: entries, depth: depth}
+}
+
+// Get returns the cached response for key, provided the block it was computed
+// from is still canonical according to canonicalHash.
+func (c *ResponseCache) Get(
+	key string, canonicalHash func(uint64) common.Hash,
+) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	value, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(*cachedResponse)
+	if canonicalHash(entry.blockNum) != entry.blockHash {
+		c.entries.Remove(key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Add caches the response for key if block blockNum is deep enough below head
+// to be considered final. It returns whether the response was cached.
+func (c *ResponseCache) Add(
+	key string, blockNum uint64, blockHash common.Hash, head uint64, value interface{},
+) bool {
+	if c == nil || blockNum+c.depth > head {
+		return false
+	}
+	c.entries.Add(key, &cachedResponse{blockNum, blockHash, value})
+	return true
+}
+
+// Len returns the number of cached responses.
+func (c *ResponseCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.entries.Len()
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestResponseCache(t *testing.T) {
+	canonical := map[uint64]common.Hash{
+		1:  common.HexToHash("0x01"),
+		10: common.HexToHash("0x0a"),
+	}
+	lookup := func(num uint64) common.Hash { return canonical[num] }
+
+	cache := NewResponseCache(8, 5)
+	if cache.Add("recent", 10, canonical[10], 12, "recent") {
+		t.Error("block within the finality depth must not be cached")
+	}
+	if !cache.Add("old", 1, canonical[1], 12, "old") {
+		t.Fatal("final block was not cached")
+	}
+	if v, ok := cache.Get("old", lookup); !ok || v != "old" {
+		t.Errorf("got %v %v, expect old true", v, ok)
+	}
+	// a reorg replaces the canonical block, orphaning the entry
+	canonical[1] = common.HexToHash("0xff")
+	if _, ok := cache.Get("old", lookup); ok {
+		t.Error("entry of an orphaned block was returned")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("got %d entries, expect orphaned entry to be removed", cache.Len())
+	}
+}
+
+func TestNilResponseCache(t *testing.T) {
+	var cache *ResponseCache
+	if NewResponseCache(0, 5) != nil {
+		t.Error("zero size cache must be disabled")
+	}
+	if cache.Add("key", 1, common.Hash{}, 100, "value") {
+		t.Error("disabled cache cached a response")
+	}
+	if _, ok := cache.Get("key", func(uint64) common.Hash { return common.Hash{} }); ok {
+		t.Error("disabled cache returned a response")
+	}
+}
@@ -4,9 +4,12 @@ import "github.com/harmony-one/harmony/internal/params"
 
 // C ..
 type C struct {
-	TotalKnownPeers int `json:"total-known-peers"`
-	Connected       int `json:"connected"`
-	NotConnected    int `json:"not-connected"`
+	TotalKnownPeers int               `json:"total-known-peers"`
+	Connected       int               `json:"connected"`
+	NotConnected    int               `json:"not-connected"`
+	Reachability    string            `json:"reachability"`
+	DroppedByKind   map[string]uint64 `json:"rate-limited-by-kind"`
+	DroppedByPeer   map[string]uint64 `json:"rate-limited-by-peer"`
 }
 
 // NodeMetadata captures select metadata of the RPC answering node
@@ -25,3 +28,24 @@ type NodeMetadata struct {
 	NodeBootTime   int64              `json:"node-unix-start-time"`
 	C              C                  `json:"p2p-connectivity"`
 }
+
+// ChainHealth reports how far a chain tracked by the node lags behind its peers
+type ChainHealth struct {
+	ShardID      uint32 `json:"shard-id"`
+	CurrentBlock uint64 `json:"current-block"`
+	PeerBlock    uint64 `json:"peer-block"`
+	Lag          uint64 `json:"lag"`
+	InSync       bool   `json:"in-sync"`
+}
+
+// HealthStatus captures whether the RPC answering node is alive and ready to serve
+type HealthStatus struct {
+	Healthy       bool          `json:"healthy"`
+	Ready         bool          `json:"ready"`
+	NodeState     string        `json:"node-state"`
+	ConsensusMode string        `json:"consensus-mode"`
+	IsLeader      bool          `json:"is-leader"`
+	InCommittee   bool          `json:"in-committee"`
+	Chains        []ChainHealth `json:"chains"`
+	Problems      []string      `json:"problems"`
+}
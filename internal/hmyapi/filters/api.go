@@ -14,6 +14,8 @@ import (
 
 	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
+	staking "github.com/harmony-one/harmony/staking/types"
 )
 
 var (
@@ -151,6 +153,42 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// NewPendingStakingTransactions creates a subscription that is triggered each
+// time a staking transaction enters the pending state of the transaction pool.
+// The notifications carry the decoded staking directive and its fields.
+func (api *PublicFilterAPI) NewPendingStakingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		stakingTxs := make(chan []*staking.StakingTransaction, 128)
+		pendingStakingTxSub := api.events.SubscribePendingStakingTxs(stakingTxs)
+
+		for {
+			select {
+			case txs := <-stakingTxs:
+				for _, tx := range txs {
+					if rpcTx := apiv1.NewRPCPendingStakingTransaction(tx); rpcTx != nil {
+						notifier.Notify(rpcSub.ID, rpcTx)
+					}
+				}
+			case <-rpcSub.Err():
+				pendingStakingTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				pendingStakingTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 //
@@ -0,0 +1,368 @@
+package hmyapi
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+const (
+	// openRPCVersion is the version of the OpenRPC specification the schema follows
+	openRPCVersion = "1.2.6"
+	// DiscoverMethod is the method name the OpenRPC specification reserves
+	// for service discovery, served as an alias of rpc_discover
+	DiscoverMethod = "rpc.discover"
+	// maxDiscoverRequestSize bounds the HTTP requests inspected for the discover alias
+	maxDiscoverRequestSize = 512 * 1024
+)
+
+var (
+	contextType         = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	subscriptionType    = reflect.TypeOf((*rpc.Subscription)(nil))
+	bigIntType          = reflect.TypeOf(big.Int{})
+	blockNumberType     = reflect.TypeOf(rpc.BlockNumber(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// OpenRPCDocument is the OpenRPC schema of the methods served by an endpoint
+type OpenRPCDocument struct {
+	OpenRPC string          `json:"openrpc"`
+	Info    OpenRPCInfo     `json:"info"`
+	Methods []OpenRPCMethod `json:"methods"`
+}
+
+// OpenRPCInfo describes the API
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes one JSON-RPC method
+type OpenRPCMethod struct {
+	Name   string                `json:"name"`
+	Params []OpenRPCContentDescr `json:"params"`
+	Result *OpenRPCContentDescr  `json:"result,omitempty"`
+}
+
+// OpenRPCContentDescr describes a parameter or the result of a method
+type OpenRPCContentDescr struct {
+	Name     string                 `json:"name"`
+	Required bool                   `json:"required,omitempty"`
+	Schema   map[string]interface{} `json:"schema"`
+}
+
+// PublicDiscoverAPI serves the OpenRPC schema of an endpoint
+type PublicDiscoverAPI struct {
+	doc *OpenRPCDocument
+}
+
+// NewPublicDiscoverAPI creates the discover API describing the given APIs.
+func NewPublicDiscoverAPI(apis []rpc.API) *PublicDiscoverAPI {
+	return &PublicDiscoverAPI{doc: NewOpenRPCDocument(apis)}
+}
+
+// Discover returns the OpenRPC schema of the methods served by the endpoint.
+func (s *PublicDiscoverAPI) Discover() *OpenRPCDocument {
+	return s.doc
+}
+
+// DiscoverAPI returns the rpc namespace API for the given APIs.
+func DiscoverAPI(apis []rpc.API) rpc.API {
+	return rpc.API{
+		Namespace: "rpc",
+		Version:   "1.0",
+		Service:   NewPublicDiscoverAPI(apis),
+		Public:    true,
+	}
+}
+
+// NewOpenRPCDocument generates the schema of the methods of apis following
+// the rules the rpc package registers services by.
+func NewOpenRPCDocument(apis []rpc.API) *OpenRPCDocument {
+	methods := map[string]OpenRPCMethod{
+		"rpc_modules": {
+			Name:   "rpc_modules",
+			Params: []OpenRPCContentDescr{},
+			Result: &OpenRPCContentDescr{
+				Name:   "result",
+				Schema: typeSchema(reflect.TypeOf(map[string]string{}), nil),
+			},
+		},
+		"rpc_discover": {
+			Name:   "rpc_discover",
+			Params: []OpenRPCContentDescr{},
+			Result: &OpenRPCContentDescr{Name: "result", Schema: map[string]interface{}{"type": "object"}},
+		},
+	}
+	subscriptions := map[string][]string{}
+	for _, api := range apis {
+		typ := reflect.TypeOf(api.Service)
+		for i := 0; i < typ.NumMethod(); i++ {
+			method := typ.Method(i)
+			if method.PkgPath != "" {
+				continue
+			}
+			name := formatMethodName(method.Name)
+			if isSubscription(method.Type) {
+				subscriptions[api.Namespace] = append(subscriptions[api.Namespace], name)
+				continue
+			}
+			if m, ok := newOpenRPCMethod(api.Namespace+"_"+name, method.Type); ok {
+				methods[m.Name] = m
+			}
+		}
+	}
+	for namespace, names := range subscriptions {
+		sort.Strings(names)
+		enum := make([]interface{}, len(names))
+		for i, name := range names {
+			enum[i] = name
+		}
+		methods[namespace+"_subscribe"] = OpenRPCMethod{
+			Name: namespace + "_subscribe",
+			Params: []OpenRPCContentDescr{{
+				Name:     "subscription",
+				Required: true,
+				Schema:   map[string]interface{}{"type": "string", "enum": enum},
+			}},
+			Result: &OpenRPCContentDescr{Name: "subscriptionID", Schema: map[string]interface{}{"type": "string"}},
+		}
+		methods[namespace+"_unsubscribe"] = OpenRPCMethod{
+			Name: namespace + "_unsubscribe",
+			Params: []OpenRPCContentDescr{{
+				Name:     "subscriptionID",
+				Required: true,
+				Schema:   map[string]interface{}{"type": "string"},
+			}},
+			Result: &OpenRPCContentDescr{Name: "result", Schema: map[string]interface{}{"type": "boolean"}},
+		}
+	}
+
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info:    OpenRPCInfo{Title: "Harmony JSON-RPC API", Version: nodeconfig.GetVersion()},
+		Methods: make([]OpenRPCMethod, 0, len(methods)),
+	}
+	for _, m := range methods {
+		doc.Methods = append(doc.Methods, m)
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+	return doc
+}
+
+// formatMethodName lower cases the first letter like the rpc package does
+func formatMethodName(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// isSubscription reports whether the method is served as a subscription
+func isSubscription(typ reflect.Type) bool {
+	return typ.NumOut() == 2 && typ.Out(0) == subscriptionType && typ.Out(1) == errorType
+}
+
+// newOpenRPCMethod describes the method of type typ, whose first input is the
+// receiver. It returns false for methods the rpc package does not serve.
+func newOpenRPCMethod(name string, typ reflect.Type) (OpenRPCMethod, bool) {
+	m := OpenRPCMethod{Name: name, Params: []OpenRPCContentDescr{}}
+	first := 1
+	if typ.NumIn() > 1 && typ.In(1) == contextType {
+		first = 2
+	}
+	for i := first; i < typ.NumIn(); i++ {
+		in := typ.In(i)
+		m.Params = append(m.Params, OpenRPCContentDescr{
+			Name: "param" + strconv.Itoa(i-first),
+			// the rpc package passes nil for missing trailing pointer arguments
+			Required: in.Kind() != reflect.Ptr,
+			Schema:   typeSchema(in, nil),
+		})
+	}
+	var results []reflect.Type
+	for i := 0; i < typ.NumOut(); i++ {
+		if out := typ.Out(i); out != errorType {
+			results = append(results, out)
+		} else if i != typ.NumOut()-1 {
+			return m, false
+		}
+	}
+	switch len(results) {
+	case 0:
+	case 1:
+		m.Result = &OpenRPCContentDescr{Name: "result", Schema: typeSchema(results[0], nil)}
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// typeSchema returns the JSON schema of the JSON encoding of typ. The types
+// in seen are being described already, recursive references become objects.
+func typeSchema(typ reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == bigIntType:
+		return map[string]interface{}{"type": "integer"}
+	case typ == blockNumberType:
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string", "enum": []interface{}{"latest", "pending", "earliest"}},
+			},
+		}
+	case typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType):
+		return map[string]interface{}{"type": "string"}
+	case typ.Implements(jsonMarshalerType) || reflect.PtrTo(typ).Implements(jsonMarshalerType):
+		// custom encodings are not described
+		return map[string]interface{}{}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// byte slices and arrays are encoded as strings
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(typ.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type": "object", "additionalProperties": typeSchema(typ.Elem(), seen),
+		}
+	case reflect.Struct:
+		if seen[typ] {
+			return map[string]interface{}{"type": "object"}
+		}
+		if seen == nil {
+			seen = map[reflect.Type]bool{}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+		properties := map[string]interface{}{}
+		structProperties(typ, seen, properties)
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	// interfaces may hold any value
+	return map[string]interface{}{}
+}
+
+// structProperties adds the JSON encoded fields of typ to properties,
+// flattening embedded structs like encoding/json does
+func structProperties(typ reflect.Type, seen map[reflect.Type]bool, properties map[string]interface{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				structProperties(embedded, seen, properties)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, seen)
+	}
+}
+
+// RewriteDiscoverAlias renames rpc.discover calls in the JSON-RPC request or
+// batch to rpc_discover, the name the method is registered under.
+func RewriteDiscoverAlias(data []byte) []byte {
+	if !bytes.Contains(data, []byte(DiscoverMethod)) {
+		return data
+	}
+	var (
+		batch bool
+		msgs  []map[string]json.RawMessage
+	)
+	if err := json.Unmarshal(data, &msgs); err == nil {
+		batch = true
+	} else {
+		var msg map[string]json.RawMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return data
+		}
+		msgs = []map[string]json.RawMessage{msg}
+	}
+	alias, _ := json.Marshal(DiscoverMethod)
+	for _, msg := range msgs {
+		if bytes.Equal(msg["method"], alias) {
+			msg["method"] = json.RawMessage(`"rpc_discover"`)
+		}
+	}
+	var (
+		rewritten []byte
+		err       error
+	)
+	if batch {
+		rewritten, err = json.Marshal(msgs)
+	} else {
+		rewritten, err = json.Marshal(msgs[0])
+	}
+	if err != nil {
+		return data
+	}
+	return rewritten
+}
+
+// DiscoverAliasHandler serves the rpc.discover alias in front of the HTTP
+// JSON-RPC handler next.
+func DiscoverAliasHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.ContentLength > maxDiscoverRequestSize {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDiscoverRequestSize+1))
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body) <= maxDiscoverRequestSize {
+			body = RewriteDiscoverAlias(body)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
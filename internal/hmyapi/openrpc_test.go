@@ -0,0 +1,139 @@
+package hmyapi
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// OpenRPCTestResult is returned by OpenRPCTestService
+type OpenRPCTestResult struct {
+	Hash    common.Hash        `json:"hash"`
+	Value   *big.Int           `json:"value"`
+	Skipped string             `json:"-"`
+	Next    *OpenRPCTestResult `json:"next"`
+	Items   []uint64           `json:"items"`
+	Extra   map[string]bool    `json:"extra"`
+}
+
+// OpenRPCTestService is described in the tests, rpc requires it to be exported
+type OpenRPCTestService struct{}
+
+func (OpenRPCTestService) Echo(s string) string { return s }
+
+func (OpenRPCTestService) GetResult(
+	ctx context.Context, blockNr rpc.BlockNumber, full *bool,
+) (*OpenRPCTestResult, error) {
+	return nil, nil
+}
+
+func (OpenRPCTestService) Reset() error { return nil }
+
+func (OpenRPCTestService) Ticks(ctx context.Context) (*rpc.Subscription, error) {
+	return nil, nil
+}
+
+func TestNewOpenRPCDocument(t *testing.T) {
+	doc := NewOpenRPCDocument([]rpc.API{{Namespace: "test", Service: OpenRPCTestService{}}})
+	methods := map[string]OpenRPCMethod{}
+	names := []string{}
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+		names = append(names, m.Name)
+	}
+	expected := []string{
+		"rpc_discover", "rpc_modules", "test_echo", "test_getResult",
+		"test_reset", "test_subscribe", "test_unsubscribe",
+	}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("got methods %v, expect %v", names, expected)
+	}
+
+	getResult := methods["test_getResult"]
+	if len(getResult.Params) != 2 || !getResult.Params[0].Required || getResult.Params[1].Required {
+		t.Errorf("got params %+v, expect required block number and optional flag", getResult.Params)
+	}
+	if _, ok := getResult.Params[0].Schema["oneOf"]; !ok {
+		t.Errorf("got block number schema %v, expect oneOf", getResult.Params[0].Schema)
+	}
+	properties := getResult.Result.Schema["properties"].(map[string]interface{})
+	expectedProperties := map[string]string{
+		"hash": "string", "value": "integer", "next": "object", "items": "array", "extra": "object",
+	}
+	if len(properties) != len(expectedProperties) {
+		t.Errorf("got properties %v, expect %v", properties, expectedProperties)
+	}
+	for name, typ := range expectedProperties {
+		schema, ok := properties[name].(map[string]interface{})
+		if !ok || schema["type"] != typ {
+			t.Errorf("got %s schema %v, expect type %s", name, properties[name], typ)
+		}
+	}
+	if methods["test_reset"].Result != nil {
+		t.Errorf("got result %+v for method returning only an error", methods["test_reset"].Result)
+	}
+	enum := methods["test_subscribe"].Params[0].Schema["enum"].([]interface{})
+	if len(enum) != 1 || enum[0] != "ticks" {
+		t.Errorf("got subscriptions %v, expect [ticks]", enum)
+	}
+}
+
+func TestRewriteDiscoverAlias(t *testing.T) {
+	tests := []struct {
+		in, method string
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"rpc.discover"}`, "rpc_discover"},
+		{`{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["rpc.discover"]}`, "test_echo"},
+		{`[{"jsonrpc":"2.0","id":1,"method":"rpc.discover"}]`, "rpc_discover"},
+	}
+	for i, test := range tests {
+		out := RewriteDiscoverAlias([]byte(test.in))
+		var msg wsMessage
+		if strings.HasPrefix(test.in, "[") {
+			var msgs []wsMessage
+			if err := json.Unmarshal(out, &msgs); err != nil || len(msgs) != 1 {
+				t.Fatalf("test %d: got %s, expect a batch of one", i, out)
+			}
+			msg = msgs[0]
+		} else if err := json.Unmarshal(out, &msg); err != nil {
+			t.Fatalf("test %d: got %s: %v", i, out, err)
+		}
+		if msg.Method != test.method {
+			t.Errorf("test %d: got method %s, expect %s", i, msg.Method, test.method)
+		}
+	}
+}
+
+func TestDiscoverAliasHandler(t *testing.T) {
+	srv := rpc.NewServer()
+	apis := []rpc.API{{Namespace: "test", Service: OpenRPCTestService{}}}
+	srv.RegisterName("test", OpenRPCTestService{})
+	discover := DiscoverAPI(apis)
+	srv.RegisterName(discover.Namespace, discover.Service)
+	httpSrv := httptest.NewServer(DiscoverAliasHandler(srv))
+	defer httpSrv.Close()
+
+	resp, err := http.Post(httpSrv.URL, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc.discover"}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Result OpenRPCDocument `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Result.OpenRPC != openRPCVersion || len(result.Result.Methods) == 0 {
+		t.Errorf("got %+v, expect the OpenRPC document", result.Result)
+	}
+}
@@ -0,0 +1,99 @@
+package hmyapi
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// certCheckInterval is how often the certificate files are checked for changes
+const certCheckInterval = 10 * time.Second
+
+// CertReloader serves the RPC TLS certificate and, when reloading is enabled,
+// picks up a rotated certificate on the first handshake after its files change.
+type CertReloader struct {
+	config nodeconfig.RPCTLSConfig
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader loads the certificate of config.
+func NewCertReloader(config nodeconfig.RPCTLSConfig) (*CertReloader, error) {
+	r := &CertReloader{config: config}
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// filesModTime returns the latest modification time of the certificate files
+func (r *CertReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.config.CertFile, r.config.KeyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "cannot stat TLS certificate")
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return errors.Wrap(err, "cannot load TLS certificate")
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// maybeReload reloads the certificate if its files changed since it was loaded.
+// A certificate which fails to load is logged and the previous one kept.
+func (r *CertReloader) maybeReload(now time.Time) {
+	if !r.config.Reload || now.Sub(r.lastCheck) < certCheckInterval {
+		return
+	}
+	r.lastCheck = now
+	modTime, err := r.filesModTime()
+	if err == nil && modTime.After(r.modTime) {
+		err = r.load(modTime)
+		if err == nil {
+			utils.Logger().Info().Str("cert", r.config.CertFile).Msg("[RPC] reloaded TLS certificate")
+		}
+	}
+	if err != nil {
+		utils.Logger().Warn().Err(err).Msg("[RPC] keeping previous TLS certificate")
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maybeReload(time.Now())
+	return r.cert, nil
+}
+
+// TLSConfig returns the server TLS configuration using the certificate.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
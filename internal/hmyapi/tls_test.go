@@ -0,0 +1,110 @@
+package hmyapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+// writeTestCert writes a self-signed certificate with the given serial number
+func writeTestCert(t *testing.T, config nodeconfig.RPCTLSConfig, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(config.CertFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(config.KeyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func certSerial(t *testing.T, r *CertReloader) int64 {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	config := nodeconfig.RPCTLSConfig{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+		Reload:   true,
+	}
+	writeTestCert(t, config, 1)
+	r, err := NewCertReloader(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial := certSerial(t, r); serial != 1 {
+		t.Fatalf("got certificate %d, expect 1", serial)
+	}
+
+	// a broken certificate is not picked up
+	future := time.Now().Add(time.Minute)
+	if err := ioutil.WriteFile(config.KeyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(config.KeyFile, future, future)
+	r.lastCheck = time.Time{}
+	if serial := certSerial(t, r); serial != 1 {
+		t.Errorf("got certificate %d after broken rotation, expect 1", serial)
+	}
+
+	writeTestCert(t, config, 2)
+	future = future.Add(time.Minute)
+	os.Chtimes(config.CertFile, future, future)
+	if serial := certSerial(t, r); serial != 1 {
+		t.Errorf("got certificate %d before the check interval passed, expect 1", serial)
+	}
+	r.lastCheck = time.Time{}
+	if serial := certSerial(t, r); serial != 2 {
+		t.Errorf("got certificate %d after rotation, expect 2", serial)
+	}
+}
+
+func TestCertReloaderMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewCertReloader(nodeconfig.RPCTLSConfig{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	})
+	if err == nil {
+		t.Error("expected an error for missing certificate files")
+	}
+}
@@ -0,0 +1,335 @@
+package hmyapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+const (
+	wsReadLimit    = 5 * 1024 * 1024
+	wsWriteTimeout = 10 * time.Second
+	// errcodeTooManySubscriptions is the JSON-RPC error code of rejected subscriptions
+	errcodeTooManySubscriptions = -32005
+)
+
+// WSHandler serves JSON-RPC over websocket connections, enforcing the
+// connection limits of nodeconfig.WSConfig
+type WSHandler struct {
+	srv      *rpc.Server
+	config   nodeconfig.WSConfig
+	upgrader websocket.Upgrader
+
+	mu     sync.Mutex
+	conns  map[*wsConn]struct{}
+	closed bool
+}
+
+// NewWSHandler returns a websocket handler for srv accepting connections from
+// the given origins, "*" allowing any origin.
+func NewWSHandler(
+	srv *rpc.Server, origins []string, config nodeconfig.WSConfig,
+) *WSHandler {
+	h := &WSHandler{
+		srv:    srv,
+		config: config,
+		conns:  map[*wsConn]struct{}{},
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     wsOriginChecker(origins),
+	}
+	return h
+}
+
+func wsOriginChecker(origins []string) func(*http.Request) bool {
+	allowed := map[string]struct{}{}
+	for _, origin := range origins {
+		if origin == "*" {
+			return func(*http.Request) bool { return true }
+		}
+		allowed[strings.ToLower(origin)] = struct{}{}
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// not a browser
+			return true
+		}
+		_, ok := allowed[strings.ToLower(origin)]
+		return ok
+	}
+}
+
+// ConnectionCount returns the number of open websocket connections.
+func (h *WSHandler) ConnectionCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// ServeHTTP upgrades the request to a websocket connection and serves
+// JSON-RPC on it until either side closes it.
+func (h *WSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	full := h.config.MaxConnections > 0 && len(h.conns) >= h.config.MaxConnections
+	if h.closed || full {
+		h.mu.Unlock()
+		http.Error(w, "too many websocket connections", http.StatusServiceUnavailable)
+		return
+	}
+	// reserve the slot before the upgrade so concurrent handshakes cannot exceed the limit
+	c := &wsConn{
+		subs:        map[string]struct{}{},
+		pendingSubs: map[string]struct{}{},
+		maxSubs:     h.config.MaxSubscriptions,
+		idleTimeout: h.config.IdleTimeout,
+		done:        make(chan struct{}),
+	}
+	h.conns[c] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, c)
+		h.mu.Unlock()
+	}()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		utils.Logger().Debug().Err(err).Msg("[WS] upgrade failed")
+		return
+	}
+	c.conn = conn
+	conn.SetReadLimit(wsReadLimit)
+	c.extendDeadline()
+	conn.SetPongHandler(func(string) error {
+		c.extendDeadline()
+		return nil
+	})
+	if h.config.PingInterval > 0 {
+		go c.pingLoop(h.config.PingInterval)
+	}
+	h.srv.ServeCodec(
+		rpc.NewCodec(conn.UnderlyingConn(), c.encode, c.decode),
+		rpc.OptionMethodInvocation|rpc.OptionSubscriptions,
+	)
+	close(c.done)
+}
+
+// Close notifies every connected client that the server is going away and
+// closes the connections. New connections are refused afterwards.
+func (h *WSHandler) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*wsConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// wsConn tracks the subscriptions and liveness of one websocket connection
+type wsConn struct {
+	conn        *websocket.Conn
+	writeMu     sync.Mutex
+	maxSubs     int
+	idleTimeout time.Duration
+	done        chan struct{}
+
+	subMu       sync.Mutex
+	subs        map[string]struct{} // active subscription ids
+	pendingSubs map[string]struct{} // ids of subscribe requests awaiting a response
+}
+
+// wsMessage is the part of a JSON-RPC request or response the connection inspects
+type wsMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+func (c *wsConn) extendDeadline() {
+	if c.idleTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+	}
+}
+
+func (c *wsConn) pingLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			err := c.conn.WriteControl(
+				websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout),
+			)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) close(code int, reason string) {
+	c.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(wsWriteTimeout),
+	)
+	c.conn.Close()
+}
+
+func (c *wsConn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *wsConn) encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.trackResponse(data)
+	return c.write(data)
+}
+
+// decode reads the next message, answering subscribe requests over the
+// subscription limit itself instead of passing them to the server.
+func (c *wsConn) decode(v interface{}) error {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				c.close(websocket.CloseNormalClosure, "idle timeout")
+			}
+			return err
+		}
+		c.extendDeadline()
+		data, rejected := c.filterRequests(RewriteDiscoverAlias(data))
+		for _, id := range rejected {
+			if err := c.write(tooManySubscriptionsResponse(id, c.maxSubs)); err != nil {
+				return err
+			}
+		}
+		if data != nil {
+			return json.Unmarshal(data, v)
+		}
+	}
+}
+
+// filterRequests removes the subscribe requests exceeding the subscription
+// limit from data and returns their ids. It returns nil data if no request is left.
+func (c *wsConn) filterRequests(data []byte) ([]byte, []json.RawMessage) {
+	var (
+		batch bool
+		msgs  []wsMessage
+		raw   []json.RawMessage
+	)
+	if err := json.Unmarshal(data, &raw); err == nil {
+		batch = true
+		msgs = make([]wsMessage, len(raw))
+		for i := range raw {
+			json.Unmarshal(raw[i], &msgs[i])
+		}
+	} else {
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			// let the server report the malformed request
+			return data, nil
+		}
+		raw, msgs = []json.RawMessage{data}, []wsMessage{msg}
+	}
+
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	var rejected []json.RawMessage
+	kept := raw[:0]
+	for i, msg := range msgs {
+		switch {
+		case strings.HasSuffix(msg.Method, "_subscribe"):
+			if c.maxSubs > 0 && len(c.subs)+len(c.pendingSubs) >= c.maxSubs {
+				rejected = append(rejected, msg.ID)
+				continue
+			}
+			c.pendingSubs[string(msg.ID)] = struct{}{}
+		case strings.HasSuffix(msg.Method, "_unsubscribe"):
+			var params []string
+			if json.Unmarshal(msg.Params, &params) == nil && len(params) > 0 {
+				delete(c.subs, params[0])
+			}
+		}
+		kept = append(kept, raw[i])
+	}
+	if len(rejected) == 0 {
+		return data, nil
+	}
+	if len(kept) == 0 {
+		return nil, rejected
+	}
+	if !batch {
+		return kept[0], rejected
+	}
+	filtered, _ := json.Marshal(kept)
+	return filtered, rejected
+}
+
+// trackResponse records the subscription id returned for a pending subscribe request.
+func (c *wsConn) trackResponse(data []byte) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	if len(c.pendingSubs) == 0 {
+		return
+	}
+	var msgs []wsMessage
+	if json.Unmarshal(data, &msgs) != nil {
+		var msg wsMessage
+		if json.Unmarshal(data, &msg) != nil {
+			return
+		}
+		msgs = []wsMessage{msg}
+	}
+	for _, msg := range msgs {
+		if _, ok := c.pendingSubs[string(msg.ID)]; !ok || len(msg.ID) == 0 {
+			continue
+		}
+		delete(c.pendingSubs, string(msg.ID))
+		var subID string
+		if msg.Error == nil && json.Unmarshal(msg.Result, &subID) == nil {
+			c.subs[subID] = struct{}{}
+		}
+	}
+}
+
+func tooManySubscriptionsResponse(id json.RawMessage, limit int) []byte {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	resp, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    errcodeTooManySubscriptions,
+			"message": fmt.Sprintf("too many subscriptions, limit is %d", limit),
+		},
+	})
+	return resp
+}
@@ -0,0 +1,129 @@
+package hmyapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+// WSTestService is registered on the test servers, rpc requires it to be exported
+type WSTestService struct{}
+
+func (WSTestService) Echo(s string) string { return s }
+
+func (WSTestService) Ticks(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	return notifier.CreateSubscription(), nil
+}
+
+func newTestWSServer(t *testing.T, config nodeconfig.WSConfig) (*WSHandler, string) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("test", WSTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewWSHandler(srv, []string{"*"}, config)
+	httpSrv := httptest.NewServer(handler)
+	t.Cleanup(httpSrv.Close)
+	return handler, "ws" + strings.TrimPrefix(httpSrv.URL, "http")
+}
+
+func wsCall(t *testing.T, conn *websocket.Conn, req string) wsMessage {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestWSMaxConnections(t *testing.T) {
+	_, url := newTestWSServer(t, nodeconfig.WSConfig{MaxConnections: 1})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if msg := wsCall(t, conn, `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hi"]}`); string(msg.Result) != `"hi"` {
+		t.Errorf("got result %s, expect \"hi\"", msg.Result)
+	}
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("connection over the limit was accepted")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got response %v, expect status %d", resp, http.StatusServiceUnavailable)
+	}
+}
+
+func TestWSMaxSubscriptions(t *testing.T) {
+	_, url := newTestWSServer(t, nodeconfig.WSConfig{MaxSubscriptions: 1})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	const subscribe = `{"jsonrpc":"2.0","id":%d,"method":"test_subscribe","params":["ticks"]}`
+	first := wsCall(t, conn, strings.Replace(subscribe, "%d", "1", 1))
+	if first.Error != nil {
+		t.Fatalf("first subscription failed: %s", first.Error)
+	}
+	second := wsCall(t, conn, strings.Replace(subscribe, "%d", "2", 1))
+	if second.Error == nil || string(second.ID) != "2" {
+		t.Fatalf("got %+v, expect subscription over the limit to be rejected", second)
+	}
+	var subID string
+	json.Unmarshal(first.Result, &subID)
+	unsub := wsCall(t, conn,
+		`{"jsonrpc":"2.0","id":3,"method":"test_unsubscribe","params":["`+subID+`"]}`,
+	)
+	if unsub.Error != nil {
+		t.Fatalf("unsubscribe failed: %s", unsub.Error)
+	}
+	if third := wsCall(t, conn, strings.Replace(subscribe, "%d", "4", 1)); third.Error != nil {
+		t.Errorf("subscription after unsubscribe was rejected: %s", third.Error)
+	}
+}
+
+func TestWSIdleTimeout(t *testing.T) {
+	_, url := newTestWSServer(t, nodeconfig.WSConfig{IdleTimeout: 100 * time.Millisecond})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("got %v, expect the idle connection to be closed", err)
+	}
+}
+
+func TestWSClose(t *testing.T) {
+	handler, url := newTestWSServer(t, nodeconfig.WSConfig{})
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	wsCall(t, conn, `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hi"]}`)
+	handler.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("got %v, expect a going away close notification", err)
+	}
+}
@@ -0,0 +1,139 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/pkg/errors"
+)
+
+// bundleVersion is the version of the format of the bundles
+const bundleVersion = 1
+
+// bundle is an encrypted export of key files of a keyring, to move the keys
+// of a validator to another host
+type bundle struct {
+	Version int                 `json:"version"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+}
+
+// bundleFile is a key file of a bundle, with its folder and name in the
+// keyring
+type bundleFile struct {
+	Type KeyType `json:"type"`
+	Name string  `json:"name"`
+	Data []byte  `json:"data"`
+}
+
+// Export returns the bundle of the keys of the IDs, all the keys if there is
+// none, encrypted with the passphrase. The key files are exported as they
+// are, encrypted with their own passphrase, along with the passphrase files
+// of the BLS keys.
+func (k *Keyring) Export(ids []string, passphrase string) ([]byte, error) {
+	keys := []Key{}
+	if len(ids) == 0 {
+		all, err := k.Keys()
+		if err != nil {
+			return nil, err
+		}
+		keys = all
+	}
+	for _, id := range ids {
+		key, err := k.Find(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.Wrap(ErrKeyNotFound, "no key to export")
+	}
+
+	files := []bundleFile{}
+	for _, key := range keys {
+		paths := []string{key.Path}
+		if key.HasPass {
+			paths = append(paths, strings.TrimSuffix(key.Path, blsKeyExt)+blsPassExt)
+		}
+		for _, path := range paths {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, bundleFile{Type: key.Type, Name: filepath.Base(path), Data: data})
+		}
+	}
+	plain, err := json.Marshal(files)
+	if err != nil {
+		return nil, err
+	}
+	crypto, err := keystore.EncryptDataV3(plain, []byte(passphrase), k.scryptN, k.scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&bundle{Version: bundleVersion, Crypto: crypto}, "", "  ")
+}
+
+// Import decrypts the bundle with the passphrase, writes its key files into
+// the keyring, and returns the imported keys. The key files already in the
+// keyring are kept, and a bundle with a key file conflicting with one of the
+// keyring is not imported.
+func (k *Keyring) Import(data []byte, passphrase string) ([]Key, error) {
+	b := bundle{}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, errors.Wrap(err, "cannot decode the bundle")
+	}
+	if b.Version != bundleVersion {
+		return nil, errors.Errorf("unsupported bundle version %d", b.Version)
+	}
+	plain, err := keystore.DecryptDataV3(b.Crypto, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decrypt the bundle")
+	}
+	files := []bundleFile{}
+	if err := json.Unmarshal(plain, &files); err != nil {
+		return nil, errors.Wrap(err, "cannot decode the bundle")
+	}
+
+	// check all the files before writing any of them
+	for _, file := range files {
+		if file.Type != BLS && file.Type != ECDSA {
+			return nil, errors.Wrap(ErrInvalidType, string(file.Type))
+		}
+		if file.Name != filepath.Base(file.Name) || strings.HasPrefix(file.Name, ".") {
+			return nil, errors.Errorf("invalid key file name %q", file.Name)
+		}
+		existing, err := ioutil.ReadFile(filepath.Join(k.Dir(file.Type), file.Name))
+		if err == nil && !bytes.Equal(existing, file.Data) {
+			return nil, errors.Wrap(ErrKeyConflicts, file.Name)
+		}
+	}
+	imported := map[string]bool{}
+	for _, file := range files {
+		if err := os.MkdirAll(k.Dir(file.Type), 0700); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(k.Dir(file.Type), file.Name)
+		if err := ioutil.WriteFile(path, file.Data, 0600); err != nil {
+			return nil, err
+		}
+		imported[path] = true
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+	result := []Key{}
+	for _, key := range keys {
+		if imported[key.Path] {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+// Package keyring manages the BLS and the ECDSA keys of a validator in one
+// directory layout. The BLS keys are in its bls folder, in the layout of the
+// -blsfolder of the node: <public key>.key files with their optional
+// <public key>.pass passphrase files. The ECDSA keys are in its ecdsa folder,
+// as the encrypted JSON key files of the accounts keystore.
+package keyring
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/blsgen"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/pkg/errors"
+)
+
+// KeyType is the type of a key of the keyring, which is also the name of its
+// folder
+type KeyType string
+
+// Types of the keys
+const (
+	BLS   KeyType = "bls"
+	ECDSA KeyType = "ecdsa"
+)
+
+// Extensions of the BLS key files
+const (
+	blsKeyExt  = ".key"
+	blsPassExt = ".pass"
+)
+
+// Errors of the keyring
+var (
+	ErrKeyNotFound  = errors.New("key not found")
+	ErrNotECDSAKey  = errors.New("not an ECDSA key")
+	ErrInvalidType  = errors.New("invalid key type")
+	ErrKeyConflicts = errors.New("a different key file of the same name exists")
+)
+
+// Key is a key of the keyring
+type Key struct {
+	Type KeyType
+	// ID is the serialized public key of a BLS key, and the bech32 address
+	// of an ECDSA key
+	ID   string
+	Path string
+	// HasPass is whether the passphrase file of a BLS key is next to it
+	HasPass bool
+}
+
+// Keyring is the key directory of a validator
+type Keyring struct {
+	dir     string
+	scryptN int
+	scryptP int
+}
+
+// New returns the keyring of the directory, whose ECDSA keys are encrypted
+// with the standard scrypt parameters.
+func New(dir string) *Keyring {
+	return &Keyring{
+		dir:     dir,
+		scryptN: keystore.StandardScryptN,
+		scryptP: keystore.StandardScryptP,
+	}
+}
+
+// NewLight returns the keyring of the directory, whose ECDSA keys are
+// encrypted with the light scrypt parameters, for the keys of test networks.
+func NewLight(dir string) *Keyring {
+	return &Keyring{
+		dir:     dir,
+		scryptN: keystore.LightScryptN,
+		scryptP: keystore.LightScryptP,
+	}
+}
+
+// Dir returns the folder of the keys of the type.
+func (k *Keyring) Dir(t KeyType) string {
+	return filepath.Join(k.dir, string(t))
+}
+
+// NewBLSKey generates a BLS key encrypted with the passphrase, and writes the
+// passphrase file next to it if savePass is set.
+func (k *Keyring) NewBLSKey(passphrase string, savePass bool) (*Key, error) {
+	if err := os.MkdirAll(k.Dir(BLS), 0700); err != nil {
+		return nil, err
+	}
+	privateKey := bls.RandPrivateKey()
+	encrypted, err := blsgen.EncryptBLSKey(privateKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	id := privateKey.GetPublicKey().SerializeToHexStr()
+	path := filepath.Join(k.Dir(BLS), id+blsKeyExt)
+	if err := ioutil.WriteFile(path, []byte(encrypted), 0600); err != nil {
+		return nil, err
+	}
+	if savePass {
+		passPath := filepath.Join(k.Dir(BLS), id+blsPassExt)
+		if err := ioutil.WriteFile(passPath, []byte(passphrase), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return &Key{Type: BLS, ID: id, Path: path, HasPass: savePass}, nil
+}
+
+// NewECDSAKey generates an ECDSA key encrypted with the passphrase.
+func (k *Keyring) NewECDSAKey(passphrase string) (*Key, error) {
+	account, err := keystore.StoreKey(k.Dir(ECDSA), passphrase, k.scryptN, k.scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		Type: ECDSA,
+		ID:   common2.MustAddressToBech32(account.Address),
+		Path: account.URL.Path,
+	}, nil
+}
+
+// Keys returns the keys of the keyring, the BLS keys first, each sorted by
+// their ID.
+func (k *Keyring) Keys() ([]Key, error) {
+	blsKeys, err := k.blsKeys()
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKeys, err := k.ecdsaKeys()
+	if err != nil {
+		return nil, err
+	}
+	return append(blsKeys, ecdsaKeys...), nil
+}
+
+// Find returns the key of the ID, which may also be the hex address of an
+// ECDSA key.
+func (k *Keyring) Find(id string) (*Key, error) {
+	if strings.HasPrefix(id, "0x") && common.IsHexAddress(id) {
+		id = common2.MustAddressToBech32(common.HexToAddress(id))
+	}
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].ID == id {
+			return &keys[i], nil
+		}
+	}
+	return nil, errors.Wrap(ErrKeyNotFound, id)
+}
+
+// ECDSAKey decrypts the ECDSA key of the ID with the passphrase.
+func (k *Keyring) ECDSAKey(id, passphrase string) (*ecdsa.PrivateKey, error) {
+	key, err := k.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.Type != ECDSA {
+		return nil, errors.Wrap(ErrNotECDSAKey, id)
+	}
+	data, err := ioutil.ReadFile(key.Path)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decrypt %s", key.Path)
+	}
+	return decrypted.PrivateKey, nil
+}
+
+func (k *Keyring) blsKeys() ([]Key, error) {
+	files, err := readDir(k.Dir(BLS))
+	if err != nil {
+		return nil, err
+	}
+	passes := map[string]bool{}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), blsPassExt) {
+			passes[strings.TrimSuffix(file.Name(), blsPassExt)] = true
+		}
+	}
+	keys := []Key{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), blsKeyExt) {
+			continue
+		}
+		id := strings.TrimSuffix(file.Name(), blsKeyExt)
+		keys = append(keys, Key{
+			Type:    BLS,
+			ID:      id,
+			Path:    filepath.Join(k.Dir(BLS), file.Name()),
+			HasPass: passes[id],
+		})
+	}
+	return keys, nil
+}
+
+func (k *Keyring) ecdsaKeys() ([]Key, error) {
+	files, err := readDir(k.Dir(ECDSA))
+	if err != nil {
+		return nil, err
+	}
+	keys := []Key{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(k.Dir(ECDSA), file.Name())
+		address, err := keyFileAddress(path)
+		if err != nil {
+			// not a key file of the keystore
+			continue
+		}
+		keys = append(keys, Key{
+			Type: ECDSA,
+			ID:   common2.MustAddressToBech32(address),
+			Path: path,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// keyFileAddress returns the address of an encrypted ECDSA key file
+func keyFileAddress(path string) (common.Address, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	key := struct {
+		Address string `json:"address"`
+	}{}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(key.Address) {
+		return common.Address{}, errors.Errorf("invalid address %q", key.Address)
+	}
+	return common.HexToAddress(key.Address), nil
+}
+
+// readDir returns the files of the directory sorted by name, none if the
+// directory does not exist
+func readDir(dir string) ([]os.FileInfo, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return files, err
+}
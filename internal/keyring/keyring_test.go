@@ -0,0 +1,103 @@
+package keyring
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harmony-one/harmony/internal/blsgen"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/pkg/errors"
+)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	dir, err := ioutil.TempDir("", "keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := NewLight(dir)
+	return k
+}
+
+func TestKeyring(t *testing.T) {
+	k := newTestKeyring(t)
+	defer os.RemoveAll(k.dir)
+
+	blsKey, err := k.NewBLSKey("bls pass", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := k.NewECDSAKey("ecdsa pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []Key{*blsKey, *ecdsaKey}) {
+		t.Fatalf("got keys %+v, expect %+v %+v", keys, blsKey, ecdsaKey)
+	}
+	// the BLS keys are loaded by the node from the bls folder
+	if _, err := blsgen.LoadBLSKeyWithPassPhrase(blsKey.Path, "bls pass"); err != nil {
+		t.Error(err)
+	}
+
+	privateKey, err := k.ECDSAKey(ecdsaKey.ID, "ecdsa pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := common2.MustAddressToBech32(crypto.PubkeyToAddress(privateKey.PublicKey)); id != ecdsaKey.ID {
+		t.Errorf("got key of %s, expect %s", id, ecdsaKey.ID)
+	}
+	if _, err := k.ECDSAKey(ecdsaKey.ID, "wrong"); err == nil {
+		t.Error("expect an error decrypting with a wrong passphrase")
+	}
+	if _, err := k.ECDSAKey(blsKey.ID, "bls pass"); errors.Cause(err) != ErrNotECDSAKey {
+		t.Errorf("got error %v, expect %v", err, ErrNotECDSAKey)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	src, dst := newTestKeyring(t), newTestKeyring(t)
+	defer os.RemoveAll(src.dir)
+	defer os.RemoveAll(dst.dir)
+
+	blsKey, _ := src.NewBLSKey("bls pass", true)
+	ecdsaKey, _ := src.NewECDSAKey("ecdsa pass")
+	if _, err := src.NewBLSKey("other pass", false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := src.Export([]string{blsKey.ID, ecdsaKey.ID}, "bundle pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.Import(data, "wrong"); err == nil {
+		t.Error("expect an error importing with a wrong passphrase")
+	}
+	imported, err := dst.Import(data, "bundle pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 || imported[0].ID != blsKey.ID || !imported[0].HasPass || imported[1].ID != ecdsaKey.ID {
+		t.Fatalf("got imported keys %+v", imported)
+	}
+	if _, err := dst.ECDSAKey(ecdsaKey.ID, "ecdsa pass"); err != nil {
+		t.Error(err)
+	}
+
+	// importing again keeps the identical files, a conflicting file stops it
+	if _, err := dst.Import(data, "bundle pass"); err != nil {
+		t.Error(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dst.Dir(BLS), blsKey.ID+blsPassExt), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.Import(data, "bundle pass"); errors.Cause(err) != ErrKeyConflicts {
+		t.Errorf("got error %v, expect %v", err, ErrKeyConflicts)
+	}
+}
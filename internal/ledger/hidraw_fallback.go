@@ -0,0 +1,14 @@
+// +build !linux
+
+package ledger
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// openDevice is only implemented over the hidraw devices of Linux
+func openDevice() (io.ReadWriteCloser, error) {
+	return nil, errors.Wrap(ErrDeviceNotFound, "Ledger devices are only supported on Linux")
+}
@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ledgerVendorID is the USB vendor ID of Ledger, as in the HID_ID of the
+// hidraw devices
+const ledgerVendorID = "00002C97"
+
+// hidrawDevice is a hidraw device node, whose HID reports are written with
+// their report ID ahead of them
+type hidrawDevice struct {
+	file *os.File
+}
+
+func (d *hidrawDevice) Write(packet []byte) (int, error) {
+	// the Ledger devices do not number their reports, the ID is 0
+	if _, err := d.file.Write(append([]byte{0x00}, packet...)); err != nil {
+		return 0, err
+	}
+	return len(packet), nil
+}
+
+func (d *hidrawDevice) Read(packet []byte) (int, error) {
+	return d.file.Read(packet)
+}
+
+func (d *hidrawDevice) Close() error {
+	return d.file.Close()
+}
+
+// openDevice opens the hidraw node of the APDU interface of the first Ledger
+// device plugged in
+func openDevice() (io.ReadWriteCloser, error) {
+	nodes, err := filepath.Glob("/sys/class/hidraw/hidraw*")
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if !isLedgerInterface(filepath.Join(node, "device", "uevent")) {
+			continue
+		}
+		file, err := os.OpenFile(filepath.Join("/dev", filepath.Base(node)), os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &hidrawDevice{file: file}, nil
+	}
+	return nil, ErrDeviceNotFound
+}
+
+// isLedgerInterface returns whether the uevent file is of the first USB
+// interface of a Ledger device, which is the one of the APDU commands
+func isLedgerInterface(uevent string) bool {
+	file, err := os.Open(uevent)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	isLedger, isFirstInterface := false, false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "HID_ID="):
+			fields := strings.Split(strings.TrimPrefix(line, "HID_ID="), ":")
+			isLedger = len(fields) == 3 && strings.EqualFold(fields[1], ledgerVendorID)
+		case strings.HasPrefix(line, "HID_PHYS="):
+			isFirstInterface = strings.HasSuffix(line, "/input0")
+		}
+	}
+	return isLedger && isFirstInterface
+}
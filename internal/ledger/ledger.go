@@ -0,0 +1,221 @@
+// Package ledger signs the staking transactions with the ECDSA key of a
+// Ledger Nano S running the Harmony app. It talks to the device with the APDU
+// commands of the app, framed in the HID packets of the Ledger transport.
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// Framing of the HID packets of the Ledger transport
+const (
+	hidPacketSize = 64
+	hidChannel    = 0x0101
+	hidTagAPDU    = 0x05
+)
+
+// APDU commands of the Harmony app
+const (
+	claHarmony       = 0xe0
+	insGetVersion    = 0x01
+	insGetPublicKey  = 0x02
+	insSignStaking   = 0x04
+	p1First          = 0x00
+	p1More           = 0x80
+	p2DisplayAddress = 0x00
+	p2SignHash       = 0x01
+	p2Finish         = 0x02
+	// apduChunkSize is the largest payload of a command
+	apduChunkSize = 255
+	signatureSize = 65
+)
+
+// Status words of the responses
+const (
+	swOK           = 0x9000
+	swUserRejected = 0x6985
+)
+
+// Errors of the device
+var (
+	ErrDeviceNotFound = errors.New("no Ledger device found")
+	ErrUserRejected   = errors.New("the transaction was rejected on the device")
+	ErrWrongSigner    = errors.New("the signature is not of the address of the device")
+)
+
+// NanoS is a Ledger Nano S running the Harmony app
+type NanoS struct {
+	device io.ReadWriteCloser
+}
+
+// Open opens the first Ledger device plugged in.
+func Open() (*NanoS, error) {
+	device, err := openDevice()
+	if err != nil {
+		return nil, err
+	}
+	return &NanoS{device: device}, nil
+}
+
+// NewNanoS returns the app on the device, which reads and writes the HID
+// packets of the Ledger transport.
+func NewNanoS(device io.ReadWriteCloser) *NanoS {
+	return &NanoS{device: device}
+}
+
+// Close closes the device.
+func (n *NanoS) Close() error {
+	return n.device.Close()
+}
+
+// Version returns the version of the Harmony app.
+func (n *NanoS) Version() (string, error) {
+	reply, err := n.exchange([]byte{claHarmony, insGetVersion, 0x00, 0x00, 0x00})
+	if err != nil {
+		return "", err
+	}
+	if len(reply) < 3 {
+		return "", errors.Errorf("invalid version reply %x", reply)
+	}
+	return fmt.Sprintf("%d.%d.%d", reply[0], reply[1], reply[2]), nil
+}
+
+// Address returns the address of the ECDSA key of the device.
+func (n *NanoS) Address() (common.Address, error) {
+	reply, err := n.exchange([]byte{claHarmony, insGetPublicKey, 0x00, p2DisplayAddress, 0x00})
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, err := common2.Bech32ToAddress(string(reply))
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "invalid address reply %q", reply)
+	}
+	return address, nil
+}
+
+// SignStaking has the staking transaction signed on the device for the chain
+// ID, once the user approved it, and returns the signed transaction. The
+// signature is checked to be of the address of the device.
+func (n *NanoS) SignStaking(
+	tx *staking.StakingTransaction, chainID *big.Int,
+) (*staking.StakingTransaction, error) {
+	address, err := n.Address()
+	if err != nil {
+		return nil, err
+	}
+	signer := staking.NewEIP155Signer(chainID)
+	payload, err := signer.Payload(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply []byte
+	for p1 := byte(p1First); len(payload) > 0; p1 = p1More {
+		size := len(payload)
+		if size > apduChunkSize {
+			size = apduChunkSize
+		}
+		p2 := byte(p2SignHash)
+		if size == len(payload) {
+			p2 = p2Finish
+		}
+		apdu := append([]byte{claHarmony, insSignStaking, p1, p2, byte(size)}, payload[:size]...)
+		if reply, err = n.exchange(apdu); err != nil {
+			return nil, err
+		}
+		payload = payload[size:]
+	}
+	if len(reply) != signatureSize {
+		return nil, errors.Errorf("invalid signature of %d bytes", len(reply))
+	}
+	sig := append([]byte{}, reply...)
+	// the app may return the recovery ID in the legacy 27/28 form
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	signed, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, err
+	}
+	if sender, err := staking.Sender(signer, signed); err != nil || sender != address {
+		return nil, ErrWrongSigner
+	}
+	return signed, nil
+}
+
+// exchange sends the APDU command to the device and returns the data of its
+// response
+func (n *NanoS) exchange(apdu []byte) ([]byte, error) {
+	if err := writeAPDU(n.device, apdu); err != nil {
+		return nil, errors.Wrap(err, "cannot write to the device")
+	}
+	reply, err := readAPDU(n.device)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read from the device")
+	}
+	if len(reply) < 2 {
+		return nil, errors.New("the device replied no status")
+	}
+	data, status := reply[:len(reply)-2], binary.BigEndian.Uint16(reply[len(reply)-2:])
+	switch status {
+	case swOK:
+		return data, nil
+	case swUserRejected:
+		return nil, ErrUserRejected
+	}
+	return nil, errors.Errorf("the device replied status %#04x, is the Harmony app open?", status)
+}
+
+// writeAPDU writes the APDU as the HID packets of the Ledger transport, the
+// length of the APDU ahead of it in the first packet
+func writeAPDU(w io.Writer, apdu []byte) error {
+	data := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	copy(data[2:], apdu)
+	for seq := uint16(0); len(data) > 0; seq++ {
+		packet := make([]byte, hidPacketSize)
+		binary.BigEndian.PutUint16(packet, hidChannel)
+		packet[2] = hidTagAPDU
+		binary.BigEndian.PutUint16(packet[3:], seq)
+		n := copy(packet[5:], data)
+		data = data[n:]
+		if _, err := w.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAPDU reads an APDU from the HID packets of the Ledger transport
+func readAPDU(r io.Reader) ([]byte, error) {
+	var (
+		reply  []byte
+		length = -1
+	)
+	for seq := uint16(0); length < 0 || len(reply) < length; seq++ {
+		packet := make([]byte, hidPacketSize)
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint16(packet) != hidChannel || packet[2] != hidTagAPDU {
+			return nil, errors.Errorf("invalid packet header %x", packet[:3])
+		}
+		if got := binary.BigEndian.Uint16(packet[3:]); got != seq {
+			return nil, errors.Errorf("packet %d out of sequence, expect %d", got, seq)
+		}
+		data := packet[5:]
+		if seq == 0 {
+			length, data = int(binary.BigEndian.Uint16(data)), data[2:]
+		}
+		reply = append(reply, data...)
+	}
+	return reply[:length], nil
+}
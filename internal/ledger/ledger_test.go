@@ -0,0 +1,129 @@
+package ledger
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+// fakeDevice is a Harmony app answering the APDU commands written to it with
+// the key, or rejecting the transactions
+type fakeDevice struct {
+	key      *ecdsa.PrivateKey
+	reject   bool
+	written  bytes.Buffer
+	replies  bytes.Buffer
+	payload  []byte
+	commands int
+}
+
+func (d *fakeDevice) Write(packet []byte) (int, error) {
+	return d.written.Write(packet)
+}
+
+func (d *fakeDevice) Read(packet []byte) (int, error) {
+	if d.replies.Len() == 0 {
+		apdu, err := readAPDU(&d.written)
+		if err != nil {
+			return 0, err
+		}
+		d.commands++
+		if err := writeAPDU(&d.replies, d.handle(apdu)); err != nil {
+			return 0, err
+		}
+	}
+	return d.replies.Read(packet)
+}
+
+func (d *fakeDevice) Close() error {
+	return nil
+}
+
+func (d *fakeDevice) handle(apdu []byte) []byte {
+	ok := []byte{0x90, 0x00}
+	switch apdu[1] {
+	case insGetPublicKey:
+		return append([]byte(common2.MustAddressToBech32(crypto.PubkeyToAddress(d.key.PublicKey))), ok...)
+	case insSignStaking:
+		if apdu[2] == p1First {
+			d.payload = nil
+		}
+		d.payload = append(d.payload, apdu[5:5+int(apdu[4])]...)
+		if apdu[3] != p2Finish {
+			return ok
+		}
+		if d.reject {
+			return []byte{0x69, 0x85}
+		}
+		sig, _ := crypto.Sign(crypto.Keccak256(d.payload), d.key)
+		sig[64] += 27
+		return append(sig, ok...)
+	}
+	return []byte{0x6d, 0x00}
+}
+
+func TestFraming(t *testing.T) {
+	for _, size := range []int{0, 1, 57, 58, 59, 200, 1000} {
+		apdu := bytes.Repeat([]byte{0xab}, size)
+		buf := bytes.Buffer{}
+		if err := writeAPDU(&buf, apdu); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len()%hidPacketSize != 0 {
+			t.Errorf("size %d: got %d bytes, expect whole packets", size, buf.Len())
+		}
+		got, err := readAPDU(&buf)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, apdu) {
+			t.Errorf("size %d: got %x, expect %x", size, got, apdu)
+		}
+	}
+}
+
+func TestSignStaking(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	description := staking.Description{
+		Name:    string(bytes.Repeat([]byte{'a'}, 140)),
+		Details: string(bytes.Repeat([]byte{'b'}, 280)),
+	}
+	tx, err := staking.NewStakingTransaction(0, 1e6, big.NewInt(1), func() (staking.Directive, interface{}) {
+		return staking.DirectiveEditValidator, staking.EditValidator{
+			ValidatorAddress: address,
+			Description:      description,
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := &fakeDevice{key: key}
+	nanos := NewNanoS(device)
+	got, err := nanos.Address()
+	if err != nil || got != address {
+		t.Fatalf("got address %x %v, expect %x", got, err, address)
+	}
+	signed, err := nanos.SignStaking(tx, big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.commands < 4 {
+		t.Errorf("got %d commands, expect the payload signed in chunks", device.commands)
+	}
+	sender, err := staking.Sender(staking.NewEIP155Signer(big.NewInt(2)), signed)
+	if err != nil || sender != address {
+		t.Errorf("got sender %x %v, expect %x", sender, err, address)
+	}
+
+	device.reject = true
+	if _, err := nanos.SignStaking(tx, big.NewInt(2)); err != ErrUserRejected {
+		t.Errorf("got %v, expect %v", err, ErrUserRejected)
+	}
+}
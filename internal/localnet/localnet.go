@@ -0,0 +1,132 @@
+// Package localnet generates the keys and the spec of a localnet, a network
+// of validators of generated keys run on the local host by the harmony
+// localnet command. The nodes of the localnet load the spec to build their
+// sharding schedule and to fund the accounts of the spec in their genesis
+// blocks.
+package localnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+	"github.com/harmony-one/harmony/internal/genesis"
+	"github.com/harmony-one/harmony/internal/keyring"
+	"github.com/pkg/errors"
+)
+
+// SpecFile is the name of the spec file in the directory of a localnet
+const SpecFile = "localnet.json"
+
+// Spec is the spec of a localnet, its keys being in the keyring of its
+// directory, encrypted with an empty passphrase
+type Spec struct {
+	Shards uint32 `json:"shards"`
+	// ExternalSlots is the number of slots of each shard elected among the
+	// staking validators once staking is enabled
+	ExternalSlots int `json:"externalSlots"`
+	// Validators are the genesis validators, the validator i in the shard
+	// i % Shards, each with its BLS key and its funded account
+	Validators []genesis.DeployAccount `json:"validators"`
+	// DevAccounts are the funded accounts of the developers
+	DevAccounts []string `json:"devAccounts"`
+}
+
+// Generate generates the keys of a localnet of the validators assigned evenly
+// to the shards and of the developer accounts into the keyring of the
+// directory, and writes its spec there.
+func Generate(dir string, shards uint32, validators, externalSlots, devAccounts int) (*Spec, error) {
+	if shards == 0 || validators <= 0 || validators%int(shards) != 0 {
+		return nil, errors.Errorf("cannot assign %d validators evenly to %d shards", validators, shards)
+	}
+	spec := &Spec{Shards: shards, ExternalSlots: externalSlots}
+	kr := Keyring(dir)
+	for i := 0; i < validators; i++ {
+		blsKey, err := kr.NewBLSKey("", true)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot generate the BLS key of a validator")
+		}
+		account, err := kr.NewECDSAKey("")
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot generate the account of a validator")
+		}
+		spec.Validators = append(spec.Validators, genesis.DeployAccount{
+			Index:        fmt.Sprintf(" %d ", i),
+			Address:      account.ID,
+			BLSPublicKey: blsKey.ID,
+		})
+	}
+	for i := 0; i < devAccounts; i++ {
+		account, err := kr.NewECDSAKey("")
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot generate a developer account")
+		}
+		spec.DevAccounts = append(spec.DevAccounts, account.ID)
+	}
+	if _, err := spec.Schedule(); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, SpecFile), data, 0644); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Load loads the spec of the file.
+func Load(file string) (*Spec, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, errors.Wrapf(err, "invalid localnet spec %s", file)
+	}
+	return spec, nil
+}
+
+// Keyring returns the keyring of the localnet of the directory.
+func Keyring(dir string) *keyring.Keyring {
+	return keyring.NewLight(filepath.Join(dir, "keys"))
+}
+
+// Exists returns whether the directory has the spec of a localnet.
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, SpecFile))
+	return err == nil
+}
+
+// Schedule returns the sharding schedule of the localnet.
+func (s *Spec) Schedule() (shardingconfig.Schedule, error) {
+	return shardingconfig.NewCustomLocalnetSchedule(s.Shards, s.ExternalSlots, s.Validators)
+}
+
+// FundedAccounts returns the accounts funded in the genesis blocks, of the
+// validators and of the developers.
+func (s *Spec) FundedAccounts() ([]common.Address, error) {
+	accounts := make([]common.Address, 0, len(s.Validators)+len(s.DevAccounts))
+	for _, validator := range s.Validators {
+		address, err := common2.Bech32ToAddress(validator.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid validator address %s", validator.Address)
+		}
+		accounts = append(accounts, address)
+	}
+	for _, account := range s.DevAccounts {
+		address, err := common2.Bech32ToAddress(account)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid developer account %s", account)
+		}
+		accounts = append(accounts, address)
+	}
+	return accounts, nil
+}
@@ -0,0 +1,77 @@
+package localnet
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harmony-one/harmony/internal/keyring"
+)
+
+func TestGenerateLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := Generate(dir, 2, 3, 1, 1); err == nil {
+		t.Error("expect an error assigning 3 validators to 2 shards")
+	}
+	spec, err := Generate(dir, 2, 4, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(dir) {
+		t.Fatal("spec file not written")
+	}
+	loaded, err := Load(filepath.Join(dir, SpecFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, spec) {
+		t.Errorf("got spec %+v, expect %+v", loaded, spec)
+	}
+
+	keys, err := Keyring(dir).Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blsKeys := 0
+	for _, key := range keys {
+		if key.Type == keyring.BLS {
+			blsKeys++
+			if !key.HasPass {
+				t.Errorf("BLS key %s has no passphrase file", key.ID)
+			}
+		}
+	}
+	if blsKeys != 4 || len(keys) != 10 {
+		t.Errorf("got %d keys of which %d BLS keys, expect 10 of which 4", len(keys), blsKeys)
+	}
+	accounts, err := spec.FundedAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 6 {
+		t.Errorf("got %d funded accounts, expect 6", len(accounts))
+	}
+
+	schedule, err := spec.Schedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := schedule.InstanceForEpoch(big.NewInt(0))
+	if genesis.NumShards() != 2 || genesis.NumNodesPerShard() != 2 || genesis.NumHarmonyOperatedNodesPerShard() != 2 {
+		t.Errorf("got genesis instance of %d shards of %d nodes, %d operated, expect 2 of 2, 2 operated",
+			genesis.NumShards(), genesis.NumNodesPerShard(), genesis.NumHarmonyOperatedNodesPerShard())
+	}
+	staking := schedule.InstanceForEpoch(big.NewInt(100))
+	if staking.NumNodesPerShard() != 3 || staking.NumHarmonyOperatedNodesPerShard() != 2 {
+		t.Errorf("got staking instance of %d nodes, %d operated, expect 3, 2 operated",
+			staking.NumNodesPerShard(), staking.NumHarmonyOperatedNodesPerShard())
+	}
+}
@@ -0,0 +1,389 @@
+// Package metrics exports metrics in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// summarySamples is the number of recent observations a summary computes its
+// quantiles from
+const summarySamples = 1024
+
+// summaryQuantiles are the quantiles exported by the summaries
+var summaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+// metric is a metric family written to the exposition
+type metric interface {
+	metricName() string
+	write(w io.Writer)
+}
+
+// Registry is a set of metrics served to Prometheus
+type Registry struct {
+	lock       sync.Mutex
+	metrics    []metric
+	collectors []func()
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry is the registry served by the metrics endpoint of the node
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) register(m metric) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Vec {
+	v := newVec(name, help, "counter", labels)
+	r.register(v)
+	return v
+}
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Vec {
+	v := newVec(name, help, "gauge", labels)
+	r.register(v)
+	return v
+}
+
+// NewSummary registers a summary of the recent observations with the given
+// label names
+func (r *Registry) NewSummary(name, help string, labels ...string) *Summary {
+	s := &Summary{name: name, help: help, labels: labels, series: map[string]*summarySeries{}}
+	r.register(s)
+	return s
+}
+
+// NewHistogram registers a histogram of the observations in the buckets of
+// the given upper bounds, in increasing order, with the given label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		name: name, help: help, buckets: buckets, labels: labels,
+		series: map[string]*histogramSeries{},
+	}
+	r.register(h)
+	return h
+}
+
+// OnCollect adds a function called before each exposition, to update the
+// metrics which are read from their source rather than recorded
+func (r *Registry) OnCollect(collect func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.collectors = append(r.collectors, collect)
+}
+
+// Write writes the metrics in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.Lock()
+	collectors := append([]func(){}, r.collectors...)
+	metrics := append([]metric{}, r.metrics...)
+	r.lock.Unlock()
+	for _, collect := range collectors {
+		collect()
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].metricName() < metrics[j].metricName()
+	})
+	buf := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(buf)
+	}
+	return buf.Flush()
+}
+
+// ServeHTTP serves the metrics to Prometheus
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.Write(w)
+}
+
+// labelEscaper escapes the label values of the exposition
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// series is the values of the labels of a time series
+type series []string
+
+func (s series) key() string {
+	return strings.Join(s, "\xff")
+}
+
+// format returns the labels of the series as {name="value",...}, with the
+// extra label appended
+func (s series) format(names []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+labelEscaper.Replace(s[i])+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+labelEscaper.Replace(extra[1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return fmt.Sprint(v)
+}
+
+// Vec is a counter or a gauge, with a value for each combination of its labels
+type Vec struct {
+	name, help, kind string
+	labels           []string
+	lock             sync.Mutex
+	series           map[string]series
+	values           map[string]float64
+}
+
+func newVec(name, help, kind string, labels []string) *Vec {
+	return &Vec{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: map[string]series{},
+		values: map[string]float64{},
+	}
+}
+
+func (v *Vec) metricName() string {
+	return v.name
+}
+
+// seriesOf returns the key of the series of the label values, which must be
+// given for all labels
+func (v *Vec) seriesOf(labelValues []string) string {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values",
+			v.name, len(v.labels), len(labelValues)))
+	}
+	s := series(labelValues)
+	key := s.key()
+	if _, ok := v.series[key]; !ok {
+		v.series[key] = append(series{}, s...)
+	}
+	return key
+}
+
+// Add adds delta to the value of the series of the label values
+func (v *Vec) Add(delta float64, labelValues ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.values[v.seriesOf(labelValues)] += delta
+}
+
+// Inc adds one to the value of the series of the label values
+func (v *Vec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Set sets the value of the series of the label values
+func (v *Vec) Set(value float64, labelValues ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.values[v.seriesOf(labelValues)] = value
+}
+
+// Reset removes all series, so that a gauge only exports the series set
+// since then
+func (v *Vec) Reset() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.series = map[string]series{}
+	v.values = map[string]float64{}
+}
+
+// Value returns the value of the series of the label values
+func (v *Vec) Value(labelValues ...string) float64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.values[series(labelValues).key()]
+}
+
+func (v *Vec) write(w io.Writer) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind)
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n",
+			v.name, v.series[key].format(v.labels), formatValue(v.values[key]))
+	}
+}
+
+// summarySeries is the recent observations of a series of a summary
+type summarySeries struct {
+	labels  series
+	samples []float64
+	next    int
+	count   uint64
+	sum     float64
+}
+
+// Summary exports the quantiles of the recent observations of each
+// combination of its labels, and the count and sum of all observations
+type Summary struct {
+	name, help string
+	labels     []string
+	lock       sync.Mutex
+	series     map[string]*summarySeries
+}
+
+func (s *Summary) metricName() string {
+	return s.name
+}
+
+// Observe records an observation in the series of the label values
+func (s *Summary) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values",
+			s.name, len(s.labels), len(labelValues)))
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := series(labelValues).key()
+	ss, ok := s.series[key]
+	if !ok {
+		ss = &summarySeries{labels: append(series{}, labelValues...)}
+		s.series[key] = ss
+	}
+	if len(ss.samples) < summarySamples {
+		ss.samples = append(ss.samples, value)
+	} else {
+		ss.samples[ss.next] = value
+		ss.next = (ss.next + 1) % summarySamples
+	}
+	ss.count++
+	ss.sum += value
+}
+
+// quantile returns the q quantile of the sorted values
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	return sorted[int(q*float64(len(sorted)-1)+0.5)]
+}
+
+func (s *Summary) write(w io.Writer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s summary\n", s.name, s.help, s.name)
+	keys := make([]string, 0, len(s.series))
+	for key := range s.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ss := s.series[key]
+		sorted := append([]float64{}, ss.samples...)
+		sort.Float64s(sorted)
+		for _, q := range summaryQuantiles {
+			fmt.Fprintf(w, "%s%s %s\n", s.name,
+				ss.labels.format(s.labels, "quantile", fmt.Sprint(q)),
+				formatValue(quantile(sorted, q)))
+		}
+		labels := ss.labels.format(s.labels)
+		fmt.Fprintf(w, "%s_sum%s %s\n", s.name, labels, formatValue(ss.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", s.name, labels, ss.count)
+	}
+}
+
+// histogramSeries is the observations of a series of a histogram
+type histogramSeries struct {
+	labels series
+	counts []uint64 // observations of each bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Histogram exports the count of the observations of each combination of its
+// labels in cumulative buckets, and their count and sum
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	labels     []string
+	lock       sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+func (h *Histogram) metricName() string {
+	return h.name
+}
+
+// Observe records an observation in the series of the label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values",
+			h.name, len(h.labels), len(labelValues)))
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	key := series(labelValues).key()
+	hs, ok := h.series[key]
+	if !ok {
+		hs = &histogramSeries{
+			labels: append(series{}, labelValues...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = hs
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		hs.counts[i]++
+	}
+	hs.count++
+	hs.sum += value
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hs := h.series[key]
+		cumulative := uint64(0)
+		for i, bound := range h.buckets {
+			cumulative += hs.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+				hs.labels.format(h.labels, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name,
+			hs.labels.format(h.labels, "le", "+Inf"), hs.count)
+		labels := hs.labels.format(h.labels)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatValue(hs.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, hs.count)
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	peers := r.NewGauge("test_peers", "Peers of the topic", "topic")
+	bytesTotal := r.NewCounter("test_bytes_total", "Bytes of the topic", "topic", "direction")
+	latency := r.NewSummary("test_latency_seconds", "Latency of the messages", "kind")
+	r.OnCollect(func() {
+		peers.Reset()
+		peers.Set(3, `a"b`)
+	})
+	bytesTotal.Add(10, "t", "in")
+	bytesTotal.Add(5, "t", "in")
+	bytesTotal.Inc("t", "out")
+	for i := 1; i <= 100; i++ {
+		latency.Observe(float64(i), "block")
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_bytes_total Bytes of the topic
+# TYPE test_bytes_total counter
+test_bytes_total{topic="t",direction="in"} 15
+test_bytes_total{topic="t",direction="out"} 1
+# HELP test_latency_seconds Latency of the messages
+# TYPE test_latency_seconds summary
+test_latency_seconds{kind="block",quantile="0.5"} 51
+test_latency_seconds{kind="block",quantile="0.9"} 90
+test_latency_seconds{kind="block",quantile="0.99"} 99
+test_latency_seconds_sum{kind="block"} 5050
+test_latency_seconds_count{kind="block"} 100
+# HELP test_peers Peers of the topic
+# TYPE test_peers gauge
+test_peers{topic="a\"b"} 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got exposition\n%s\nexpect\n%s", got, want)
+	}
+}
+
+func TestSummaryKeepsRecentSamples(t *testing.T) {
+	r := NewRegistry()
+	s := r.NewSummary("test_summary", "Test summary")
+	for i := 0; i < 2*summarySamples; i++ {
+		s.Observe(float64(i))
+	}
+	var buf bytes.Buffer
+	r.Write(&buf)
+	if !strings.Contains(buf.String(), `test_summary{quantile="0.5"} 1536`) {
+		t.Errorf("median is not of the recent samples:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "test_summary_count 2048") {
+		t.Errorf("count is not of all samples:\n%s", buf.String())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_seconds", "Test histogram", []float64{0.1, 1}, "stage")
+	for _, v := range []float64{0.05, 0.1, 0.5, 2} {
+		h.Observe(v, "commit")
+	}
+	var buf bytes.Buffer
+	r.Write(&buf)
+	want := `# HELP test_seconds Test histogram
+# TYPE test_seconds histogram
+test_seconds_bucket{stage="commit",le="0.1"} 2
+test_seconds_bucket{stage="commit",le="1"} 3
+test_seconds_bucket{stage="commit",le="+Inf"} 4
+test_seconds_sum{stage="commit"} 2.65
+test_seconds_count{stage="commit"} 4
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got exposition\n%s\nexpect\n%s", got, want)
+	}
+}
+
+func TestVecLabelCount(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("set a value without all label values")
+		}
+	}()
+	NewRegistry().NewGauge("test_gauge", "Test gauge", "a", "b").Set(1, "a")
+}
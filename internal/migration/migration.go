@@ -0,0 +1,173 @@
+// Package migration migrates the storage format of the chain databases, which
+// also keep the off-chain data of the node, between the versions marked in
+// them, so a change of the format does not require a resync. Each migration
+// is applied atomically with the version marker it moves the database to,
+// and can be dry-run, or rolled back to downgrade the node.
+package migration
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// Migration moves the chain database from the version before its own to its
+// version. Its changes are buffered in memory until it returns, and are then
+// written with the version marker in one batch.
+type Migration struct {
+	Version     uint64
+	Description string
+	// Up migrates the database to the version
+	Up func(db ethdb.Database) error
+	// Down migrates the database back to the version before, it is nil if
+	// the migration cannot be rolled back
+	Down func(db ethdb.Database) error
+}
+
+// Migrations are the migrations of the chain databases, in the order of
+// their versions, which follow each other from 1. The chain databases created
+// by the node are marked with the version of the last one, and those created
+// before the versions are marked with the version 0. Appending a migration
+// changes the storage format of the node.
+var Migrations = []Migration{}
+
+// Errors of the migrations
+var (
+	ErrNewerSchema  = errors.New("chain database has a newer schema, roll it back with the node which migrated it")
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+)
+
+// Step is a migration applied or rolled back by Migrate
+type Step struct {
+	Version     uint64
+	Description string
+	Down        bool
+	// Puts and Deletes are the numbers of keys written and deleted
+	Puts, Deletes int
+}
+
+// Latest returns the version of the last of the migrations
+func Latest(migrations []Migration) uint64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Version returns the schema version of the chain database
+func Version(db ethdb.Database) uint64 {
+	version, _ := rawdb.ReadSchemaVersion(db)
+	return version
+}
+
+// Migrate applies the migrations up or rolls them back down to the version,
+// in order, and returns the steps taken. Unless dryRun is set, each step is
+// written atomically with the version it moves the database to, so a failed
+// step leaves the database at the version before it. A dry run writes
+// nothing, and reports the changes the steps would make.
+func Migrate(db ethdb.Database, migrations []Migration, to uint64, dryRun bool) ([]Step, error) {
+	if err := check(migrations); err != nil {
+		return nil, err
+	}
+	if to > Latest(migrations) {
+		return nil, errors.Errorf("unknown schema version %d, the latest is %d", to, Latest(migrations))
+	}
+	from := Version(db)
+	if from > Latest(migrations) {
+		return nil, errors.Wrapf(ErrNewerSchema, "schema version %d, the latest known is %d", from, Latest(migrations))
+	}
+	// a dry run applies the steps to an overlay of the database kept
+	// across them, so each step sees the changes of the ones before
+	var dry *overlay
+	if dryRun {
+		dry = newOverlay(db)
+	}
+	steps := []Step{}
+	for version := from; version != to; {
+		var (
+			step Step
+			err  error
+		)
+		if version < to {
+			m := migrations[version]
+			step = Step{Version: m.Version, Description: m.Description}
+			err = apply(db, dry, m.Up, m.Version, &step)
+		} else {
+			m := migrations[version-1]
+			step = Step{Version: m.Version, Description: m.Description, Down: true}
+			if m.Down == nil {
+				err = ErrIrreversible
+			} else {
+				err = apply(db, dry, m.Down, m.Version-1, &step)
+			}
+		}
+		if err != nil {
+			return steps, errors.Wrapf(err, "migration %d (%s)", step.Version, step.Description)
+		}
+		steps = append(steps, step)
+		if step.Down {
+			version--
+		} else {
+			version++
+		}
+		if !dryRun {
+			utils.Logger().Info().
+				Uint64("version", version).
+				Bool("down", step.Down).
+				Int("puts", step.Puts).
+				Int("deletes", step.Deletes).
+				Str("migration", step.Description).
+				Msg("[migration] chain database migrated")
+		}
+	}
+	return steps, nil
+}
+
+// apply runs the migration function on an overlay of the database, and
+// writes its changes with the version marker in one batch, into the dry run
+// overlay if any
+func apply(
+	db ethdb.Database, dry *overlay, migrate func(ethdb.Database) error, version uint64, step *Step,
+) error {
+	if dry != nil {
+		db = dry
+	}
+	changes := newOverlay(db)
+	if err := migrate(changes); err != nil {
+		return err
+	}
+	step.Puts, step.Deletes = changes.counts()
+	batch := db.NewBatch()
+	if err := changes.writeTo(batch); err != nil {
+		return err
+	}
+	if err := rawdb.WriteSchemaVersion(batch, version); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// check checks that the versions of the migrations follow each other from 1
+func check(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version != uint64(i+1) || m.Up == nil {
+			return errors.Errorf("invalid migration %d (%s) at position %d", m.Version, m.Description, i)
+		}
+	}
+	return nil
+}
+
+// Init marks the new chain database with the latest version of the
+// migrations, since it is created in the latest storage format.
+func Init(db ethdb.Database, migrations []Migration) error {
+	return rawdb.WriteSchemaVersion(db, Latest(migrations))
+}
+
+// Upgrade applies the pending migrations to the chain database, the ones
+// created before the versions having them all pending. It is run at the
+// startup of the node.
+func Upgrade(db ethdb.Database, migrations []Migration) error {
+	_, err := Migrate(db, migrations, Latest(migrations), false)
+	return err
+}
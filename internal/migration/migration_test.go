@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/pkg/errors"
+)
+
+// testMigrations rename the key a to b, then b to c, the last one cannot be
+// rolled back
+func testMigrations() []Migration {
+	rename := func(from, to string) func(ethdb.Database) error {
+		return func(db ethdb.Database) error {
+			value, err := db.Get([]byte(from))
+			if err != nil {
+				return err
+			}
+			if err := db.Put([]byte(to), value); err != nil {
+				return err
+			}
+			return db.Delete([]byte(from))
+		}
+	}
+	return []Migration{
+		{Version: 1, Description: "a to b", Up: rename("a", "b"), Down: rename("b", "a")},
+		{Version: 2, Description: "b to c", Up: rename("b", "c")},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("a"), []byte("value"))
+	migrations := testMigrations()
+
+	steps, err := Migrate(db, migrations, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || steps[1].Puts != 1 || steps[1].Deletes != 1 {
+		t.Errorf("dry run steps %+v", steps)
+	}
+	if _, ok := rawdb.ReadSchemaVersion(db); ok || db.Len() != 1 {
+		t.Fatal("dry run wrote the database")
+	}
+
+	if err := Upgrade(db, migrations); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := db.Get([]byte("c")); string(value) != "value" || Version(db) != 2 {
+		t.Fatalf("migrated to version %d with c %q", Version(db), value)
+	}
+	if _, err := Migrate(db, migrations, 0, false); errors.Cause(err) != ErrIrreversible {
+		t.Errorf("rolled back an irreversible migration: %v", err)
+	}
+	if _, err := Migrate(db, migrations[:1], 1, false); errors.Cause(err) != ErrNewerSchema {
+		t.Errorf("migrated a newer schema: %v", err)
+	}
+}
+
+func TestMigrateRollback(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("a"), []byte("value"))
+	migrations := testMigrations()[:1]
+	if err := Upgrade(db, migrations); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(db, migrations, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := db.Get([]byte("a")); string(value) != "value" || Version(db) != 0 {
+		t.Errorf("rolled back to version %d with a %q", Version(db), value)
+	}
+	if ok, _ := db.Has([]byte("b")); ok {
+		t.Error("b kept after the rollback")
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	// the migration fails without the key a, and writes nothing
+	db.Put([]byte("b"), []byte("value"))
+	if err := Upgrade(db, testMigrations()); err == nil {
+		t.Fatal("migrated without the key")
+	}
+	if _, ok := rawdb.ReadSchemaVersion(db); ok || db.Len() != 1 {
+		t.Error("failed migration wrote the database")
+	}
+}
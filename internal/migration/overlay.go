@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/pkg/errors"
+)
+
+var errNotFound = errors.New("not found")
+
+// overlay is a database buffering the changes to its base database in
+// memory, reading them back over the base
+type overlay struct {
+	base ethdb.Database
+	lock sync.RWMutex
+	// changes are the values written, nil for the keys deleted
+	changes map[string][]byte
+}
+
+func newOverlay(base ethdb.Database) *overlay {
+	return &overlay{base: base, changes: map[string][]byte{}}
+}
+
+func (o *overlay) Put(key []byte, value []byte) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.changes[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (o *overlay) Delete(key []byte) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.changes[string(key)] = nil
+	return nil
+}
+
+func (o *overlay) Get(key []byte) ([]byte, error) {
+	o.lock.RLock()
+	value, changed := o.changes[string(key)]
+	o.lock.RUnlock()
+	if !changed {
+		return o.base.Get(key)
+	}
+	if value == nil {
+		return nil, errNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+func (o *overlay) Has(key []byte) (bool, error) {
+	o.lock.RLock()
+	value, changed := o.changes[string(key)]
+	o.lock.RUnlock()
+	if !changed {
+		return o.base.Has(key)
+	}
+	return value != nil, nil
+}
+
+// Close does not close the base database
+func (o *overlay) Close() {}
+
+func (o *overlay) NewBatch() ethdb.Batch {
+	return &overlayBatch{o: o}
+}
+
+// counts returns the numbers of keys written and deleted
+func (o *overlay) counts() (puts, deletes int) {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	for _, value := range o.changes {
+		if value == nil {
+			deletes++
+		} else {
+			puts++
+		}
+	}
+	return puts, deletes
+}
+
+// writeTo writes the changes into the batch
+func (o *overlay) writeTo(batch ethdb.Batch) error {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	for key, value := range o.changes {
+		var err error
+		if value == nil {
+			err = batch.Delete([]byte(key))
+		} else {
+			err = batch.Put([]byte(key), value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overlayBatch is a batch of changes to an overlay
+type overlayBatch struct {
+	o      *overlay
+	writes []overlayWrite
+	size   int
+}
+
+type overlayWrite struct {
+	key, value []byte
+	delete     bool
+}
+
+func (b *overlayBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, overlayWrite{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *overlayBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, overlayWrite{key: append([]byte{}, key...), delete: true})
+	b.size++
+	return nil
+}
+
+func (b *overlayBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *overlayBatch) Write() error {
+	for _, w := range b.writes {
+		if w.delete {
+			b.o.Delete(w.key)
+		} else {
+			b.o.Put(w.key, w.value)
+		}
+	}
+	return nil
+}
+
+func (b *overlayBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
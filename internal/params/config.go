@@ -5,6 +5,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
 )
 
 // Well-known chain IDs.
@@ -25,83 +26,125 @@ var EpochTBD = big.NewInt(10000000)
 var (
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
-		ChainID:          MainnetChainID,
-		CrossTxEpoch:     big.NewInt(28),
-		CrossLinkEpoch:   big.NewInt(186),
-		StakingEpoch:     big.NewInt(186),
-		PreStakingEpoch:  big.NewInt(185),
-		QuickUnlockEpoch: big.NewInt(191),
-		EIP155Epoch:      big.NewInt(28),
-		S3Epoch:          big.NewInt(28),
-		ReceiptLogEpoch:  big.NewInt(101),
+		ChainID:              MainnetChainID,
+		CrossTxEpoch:         big.NewInt(28),
+		CrossLinkEpoch:       big.NewInt(186),
+		StakingEpoch:         big.NewInt(186),
+		PreStakingEpoch:      big.NewInt(185),
+		QuickUnlockEpoch:     big.NewInt(191),
+		EIP155Epoch:          big.NewInt(28),
+		S3Epoch:              big.NewInt(28),
+		ReceiptLogEpoch:      big.NewInt(101),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// TestnetChainConfig contains the chain parameters to run a node on the harmony test network.
 	TestnetChainConfig = &ChainConfig{
-		ChainID:          TestnetChainID,
-		CrossTxEpoch:     big.NewInt(0),
-		CrossLinkEpoch:   big.NewInt(2),
-		StakingEpoch:     big.NewInt(2),
-		PreStakingEpoch:  big.NewInt(1),
-		QuickUnlockEpoch: big.NewInt(0),
-		EIP155Epoch:      big.NewInt(0),
-		S3Epoch:          big.NewInt(0),
-		ReceiptLogEpoch:  big.NewInt(0),
+		ChainID:              TestnetChainID,
+		CrossTxEpoch:         big.NewInt(0),
+		CrossLinkEpoch:       big.NewInt(2),
+		StakingEpoch:         big.NewInt(2),
+		PreStakingEpoch:      big.NewInt(1),
+		QuickUnlockEpoch:     big.NewInt(0),
+		EIP155Epoch:          big.NewInt(0),
+		S3Epoch:              big.NewInt(0),
+		ReceiptLogEpoch:      big.NewInt(0),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// PangaeaChainConfig contains the chain parameters for the Pangaea network.
 	// All features except for CrossLink are enabled at launch.
 	PangaeaChainConfig = &ChainConfig{
-		ChainID:          PangaeaChainID,
-		CrossTxEpoch:     big.NewInt(0),
-		CrossLinkEpoch:   big.NewInt(2),
-		StakingEpoch:     big.NewInt(2),
-		PreStakingEpoch:  big.NewInt(1),
-		QuickUnlockEpoch: big.NewInt(0),
-		EIP155Epoch:      big.NewInt(0),
-		S3Epoch:          big.NewInt(0),
-		ReceiptLogEpoch:  big.NewInt(0),
+		ChainID:              PangaeaChainID,
+		CrossTxEpoch:         big.NewInt(0),
+		CrossLinkEpoch:       big.NewInt(2),
+		StakingEpoch:         big.NewInt(2),
+		PreStakingEpoch:      big.NewInt(1),
+		QuickUnlockEpoch:     big.NewInt(0),
+		EIP155Epoch:          big.NewInt(0),
+		S3Epoch:              big.NewInt(0),
+		ReceiptLogEpoch:      big.NewInt(0),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// PartnerChainConfig contains the chain parameters for the Partner network.
 	// All features except for CrossLink are enabled at launch.
 	PartnerChainConfig = &ChainConfig{
-		ChainID:          PartnerChainID,
-		CrossTxEpoch:     big.NewInt(0),
-		CrossLinkEpoch:   big.NewInt(2),
-		StakingEpoch:     big.NewInt(2),
-		PreStakingEpoch:  big.NewInt(1),
-		QuickUnlockEpoch: big.NewInt(0),
-		EIP155Epoch:      big.NewInt(0),
-		S3Epoch:          big.NewInt(0),
-		ReceiptLogEpoch:  big.NewInt(0),
+		ChainID:              PartnerChainID,
+		CrossTxEpoch:         big.NewInt(0),
+		CrossLinkEpoch:       big.NewInt(2),
+		StakingEpoch:         big.NewInt(2),
+		PreStakingEpoch:      big.NewInt(1),
+		QuickUnlockEpoch:     big.NewInt(0),
+		EIP155Epoch:          big.NewInt(0),
+		S3Epoch:              big.NewInt(0),
+		ReceiptLogEpoch:      big.NewInt(0),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// StressnetChainConfig contains the chain parameters for the Stress test network.
 	// All features except for CrossLink are enabled at launch.
 	StressnetChainConfig = &ChainConfig{
-		ChainID:          StressnetChainID,
-		CrossTxEpoch:     big.NewInt(0),
-		CrossLinkEpoch:   big.NewInt(2),
-		StakingEpoch:     big.NewInt(2),
-		PreStakingEpoch:  big.NewInt(1),
-		QuickUnlockEpoch: big.NewInt(0),
-		EIP155Epoch:      big.NewInt(0),
-		S3Epoch:          big.NewInt(0),
-		ReceiptLogEpoch:  big.NewInt(0),
+		ChainID:              StressnetChainID,
+		CrossTxEpoch:         big.NewInt(0),
+		CrossLinkEpoch:       big.NewInt(2),
+		StakingEpoch:         big.NewInt(2),
+		PreStakingEpoch:      big.NewInt(1),
+		QuickUnlockEpoch:     big.NewInt(0),
+		EIP155Epoch:          big.NewInt(0),
+		S3Epoch:              big.NewInt(0),
+		ReceiptLogEpoch:      big.NewInt(0),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// LocalnetChainConfig contains the chain parameters to run for local development.
 	LocalnetChainConfig = &ChainConfig{
-		ChainID:          TestnetChainID,
-		CrossTxEpoch:     big.NewInt(0),
-		CrossLinkEpoch:   big.NewInt(2),
-		StakingEpoch:     big.NewInt(2),
-		PreStakingEpoch:  big.NewInt(0),
-		QuickUnlockEpoch: big.NewInt(0),
-		EIP155Epoch:      big.NewInt(0),
-		S3Epoch:          big.NewInt(0),
-		ReceiptLogEpoch:  big.NewInt(0),
+		ChainID:              TestnetChainID,
+		CrossTxEpoch:         big.NewInt(0),
+		CrossLinkEpoch:       big.NewInt(2),
+		StakingEpoch:         big.NewInt(2),
+		PreStakingEpoch:      big.NewInt(0),
+		QuickUnlockEpoch:     big.NewInt(0),
+		EIP155Epoch:          big.NewInt(0),
+		S3Epoch:              big.NewInt(0),
+		ReceiptLogEpoch:      big.NewInt(0),
+		DynamicShardingEpoch: EpochTBD,
+		GovernanceEpoch:      EpochTBD,
+		ShardPreferenceEpoch: EpochTBD,
+		RandomnessEpoch:      EpochTBD,
+		HeaderV4Epoch:        EpochTBD,
+		BLS12381Epoch:        EpochTBD,
+		RewardRemainderEpoch: EpochTBD,
 	}
 
 	// AllProtocolChanges ...
@@ -117,6 +160,20 @@ var (
 		big.NewInt(0),             // EIP155Epoch
 		big.NewInt(0),             // S3Epoch
 		big.NewInt(0),             // ReceiptLogEpoch
+		big.NewInt(0),             // DynamicShardingEpoch
+		big.NewInt(0),             // GovernanceEpoch
+		nil,                       // GovernanceKeys
+		0,                         // GovernanceThreshold
+		big.NewInt(0),             // ShardPreferenceEpoch
+		big.NewInt(0),             // RandomnessEpoch
+		big.NewInt(0),             // HeaderV4Epoch
+		big.NewInt(0),             // BLS12381Epoch
+		big.NewInt(0),             // RewardRemainderEpoch
+		nil,                       // InternalRotation
+		nil,                       // StakedNetworkReward
+		nil,                       // AvailabilityThresholds
+		nil,                       // RewardSchedule
+		nil,                       // ReshardingAccounts
 	}
 
 	// TestChainConfig ...
@@ -132,6 +189,20 @@ var (
 		big.NewInt(0), // EIP155Epoch
 		big.NewInt(0), // S3Epoch
 		big.NewInt(0), // ReceiptLogEpoch
+		big.NewInt(0), // DynamicShardingEpoch
+		big.NewInt(0), // GovernanceEpoch
+		nil,           // GovernanceKeys
+		0,             // GovernanceThreshold
+		big.NewInt(0), // ShardPreferenceEpoch
+		big.NewInt(0), // RandomnessEpoch
+		big.NewInt(0), // HeaderV4Epoch
+		big.NewInt(0), // BLS12381Epoch
+		big.NewInt(0), // RewardRemainderEpoch
+		nil,           // InternalRotation
+		nil,           // StakedNetworkReward
+		nil,           // AvailabilityThresholds
+		nil,           // RewardSchedule
+		nil,           // ReshardingAccounts
 	}
 
 	// TestRules ...
@@ -184,6 +255,178 @@ type ChainConfig struct {
 
 	// ReceiptLogEpoch is the first epoch support receiptlog
 	ReceiptLogEpoch *big.Int `json:"receipt-log-epoch,omitempty"`
+
+	// DynamicShardingEpoch is the first epoch the number of shards can change
+	// at, with the balances of the merged shards moved to their destination
+	// shard and the block reward split across the shards of the epoch
+	DynamicShardingEpoch *big.Int `json:"dynamic-sharding-epoch,omitempty"`
+
+	// GovernanceEpoch is the first epoch the beacon chain applies the
+	// protocol parameter updates signed by the governance keys
+	GovernanceEpoch *big.Int `json:"governance-epoch,omitempty"`
+
+	// GovernanceKeys are the addresses of the keys signing the parameter updates
+	GovernanceKeys []common.Address `json:"governance-keys,omitempty"`
+
+	// GovernanceThreshold is the number of governance keys a parameter
+	// update needs the signatures of
+	GovernanceThreshold uint32 `json:"governance-threshold,omitempty"`
+
+	// ShardPreferenceEpoch is the first epoch the validators can declare a
+	// preference to stay on their shard across the elections
+	ShardPreferenceEpoch *big.Int `json:"shard-preference-epoch,omitempty"`
+
+	// RandomnessEpoch is the first epoch the contracts can read the VRF
+	// randomness of the recent blocks from the randomness precompile
+	RandomnessEpoch *big.Int `json:"randomness-epoch,omitempty"`
+
+	// HeaderV4Epoch is the first epoch of the v4 block headers, which carry
+	// the fields introduced by the later forks after the v3 fields
+	HeaderV4Epoch *big.Int `json:"header-v4-epoch,omitempty"`
+
+	// BLS12381Epoch is the first epoch the validators can bind the keys of
+	// the IETF BLS12-381 scheme to their slot keys, for the transition of the
+	// committees to the scheme
+	BLS12381Epoch *big.Int `json:"bls12381-epoch,omitempty"`
+
+	// RewardRemainderEpoch is the first epoch the remainder of the block
+	// reward of the beacon chain signers, left by the rounding of their
+	// shares, goes to the signer of the most voting power instead of being
+	// burnt
+	RewardRemainderEpoch *big.Int `json:"reward-remainder-epoch,omitempty"`
+
+	// InternalRotation is the schedule of the rotation of the harmony
+	// operated slots of the staking committees, in epoch order
+	InternalRotation []InternalRotationStep `json:"internal-rotation,omitempty"`
+
+	// StakedNetworkReward is the block reward of the whole network for each
+	// block height from the dynamic sharding fork on, split evenly across the
+	// shards of the epoch. It defaults to the staked block reward of each of
+	// the 4 shards of the staking launch.
+	StakedNetworkReward *big.Int `json:"staked-network-reward,omitempty"`
+
+	// AvailabilityThresholds is the schedule of the signing threshold of the
+	// validators, the share of the blocks to sign of an epoch at or below
+	// which a validator is set inactive, in epoch order. It defaults to
+	// DefaultAvailabilityThreshold.
+	AvailabilityThresholds []AvailabilityThresholdStep `json:"availability-thresholds,omitempty"`
+
+	// RewardSchedule is the schedule of the block reward of each shard, in
+	// epoch order. The epochs it covers take their block reward from it
+	// instead of the rewards of the network before and after staking.
+	RewardSchedule RewardSchedule `json:"reward-schedule,omitempty"`
+
+	// ReshardingAccounts lists the accounts of the shards retired by the
+	// reshardings whose balances move to the destination shard. Only the
+	// balances of the listed accounts move, as the accounts of a shard cannot
+	// be enumerated from its state.
+	ReshardingAccounts []ReshardingAccounts `json:"resharding-accounts,omitempty"`
+}
+
+// InternalRotationStep sets the percentage of the harmony operated slots of
+// each shard given to other harmony keys at each election from Epoch on.
+type InternalRotationStep struct {
+	Epoch   *big.Int `json:"epoch"`
+	Percent uint32   `json:"percent"`
+}
+
+// AvailabilityThresholdStep sets the signing threshold of the validators
+// from Epoch on.
+type AvailabilityThresholdStep struct {
+	Epoch     *big.Int    `json:"epoch"`
+	Threshold numeric.Dec `json:"threshold"`
+}
+
+// RewardScheduleStep sets the block reward of each shard from Epoch on, up
+// to the epoch of the next step.
+type RewardScheduleStep struct {
+	Epoch  *big.Int `json:"epoch"`
+	Reward *big.Int `json:"reward"`
+}
+
+// RewardSchedule is a schedule of block rewards by epoch range, in epoch
+// order.
+type RewardSchedule []RewardScheduleStep
+
+// BlockReward returns the block reward of each shard at the epoch, nil if
+// the epoch is before the first step or its step sets no reward, ending the
+// range of the previous step. The schedule is checked by ChainConfig.Validate.
+func (s RewardSchedule) BlockReward(epoch *big.Int) *big.Int {
+	var reward *big.Int
+	for _, step := range s {
+		if !isForked(step.Epoch, epoch) {
+			break
+		}
+		reward = step.Reward
+	}
+	if reward == nil {
+		return nil
+	}
+	return new(big.Int).Set(reward)
+}
+
+// MaxReshardingAccounts is the most accounts a retired shard can move to its
+// destination shard, all in the last block of the epoch before the resharding.
+const MaxReshardingAccounts = 10000
+
+// ReshardingAccounts lists the accounts of the shard retired by the
+// resharding at Epoch whose balances move to its destination shard.
+type ReshardingAccounts struct {
+	Epoch    *big.Int         `json:"epoch"`
+	ShardID  uint32           `json:"shard-id"`
+	Accounts []common.Address `json:"accounts"`
+}
+
+// ReshardingAccountsOf returns the accounts of the shard retired by the
+// resharding at the epoch whose balances move to its destination shard
+func (c *ChainConfig) ReshardingAccountsOf(epoch *big.Int, shardID uint32) []common.Address {
+	for _, r := range c.ReshardingAccounts {
+		if r.ShardID == shardID && r.Epoch != nil && r.Epoch.Cmp(epoch) == 0 {
+			return r.Accounts
+		}
+	}
+	return nil
+}
+
+// DefaultAvailabilityThreshold is the signing threshold of the validators
+// before the first step of the schedule, 2/3 of the blocks to sign.
+var DefaultAvailabilityThreshold = numeric.NewDec(2).Quo(numeric.NewDec(3))
+
+// Validate returns an error if the schedules of the chain config are
+// inconsistent.
+func (c *ChainConfig) Validate() error {
+	for i, step := range c.RewardSchedule {
+		if step.Epoch == nil {
+			return fmt.Errorf("reward schedule step %d has no epoch", i)
+		}
+		if i > 0 && step.Epoch.Cmp(c.RewardSchedule[i-1].Epoch) <= 0 {
+			return fmt.Errorf(
+				"reward schedule step %d at epoch %v is not after epoch %v",
+				i, step.Epoch, c.RewardSchedule[i-1].Epoch,
+			)
+		}
+		if step.Reward != nil && step.Reward.Sign() < 0 {
+			return fmt.Errorf("negative reward %v of the reward schedule at epoch %v", step.Reward, step.Epoch)
+		}
+	}
+	seen := map[string]bool{}
+	for _, r := range c.ReshardingAccounts {
+		if r.Epoch == nil {
+			return fmt.Errorf("resharding accounts of shard %d have no epoch", r.ShardID)
+		}
+		key := fmt.Sprintf("%v-%d", r.Epoch, r.ShardID)
+		if seen[key] {
+			return fmt.Errorf("resharding accounts of shard %d at epoch %v listed twice", r.ShardID, r.Epoch)
+		}
+		seen[key] = true
+		if len(r.Accounts) > MaxReshardingAccounts {
+			return fmt.Errorf(
+				"%d resharding accounts of shard %d at epoch %v, more than the %d allowed",
+				len(r.Accounts), r.ShardID, r.Epoch, MaxReshardingAccounts,
+			)
+		}
+	}
+	return nil
 }
 
 // String implements the fmt.Stringer interface.
@@ -253,6 +496,78 @@ func (c *ChainConfig) IsReceiptLog(epoch *big.Int) bool {
 	return isForked(c.ReceiptLogEpoch, epoch)
 }
 
+// IsDynamicSharding returns whether epoch is either equal to the DynamicSharding fork epoch or greater.
+func (c *ChainConfig) IsDynamicSharding(epoch *big.Int) bool {
+	return isForked(c.DynamicShardingEpoch, epoch)
+}
+
+// IsGovernance returns whether epoch is either equal to the Governance fork epoch or greater.
+func (c *ChainConfig) IsGovernance(epoch *big.Int) bool {
+	return isForked(c.GovernanceEpoch, epoch)
+}
+
+// IsShardPreference returns whether epoch is either equal to the ShardPreference fork epoch or greater.
+func (c *ChainConfig) IsShardPreference(epoch *big.Int) bool {
+	return isForked(c.ShardPreferenceEpoch, epoch)
+}
+
+// IsRandomness returns whether epoch is either equal to the Randomness fork epoch or greater.
+func (c *ChainConfig) IsRandomness(epoch *big.Int) bool {
+	return isForked(c.RandomnessEpoch, epoch)
+}
+
+// IsHeaderV4 returns whether epoch is either equal to the HeaderV4 fork epoch or greater.
+func (c *ChainConfig) IsHeaderV4(epoch *big.Int) bool {
+	return isForked(c.HeaderV4Epoch, epoch)
+}
+
+// IsBLS12381 returns whether epoch is either equal to the BLS12-381 fork epoch or greater.
+func (c *ChainConfig) IsBLS12381(epoch *big.Int) bool {
+	return isForked(c.BLS12381Epoch, epoch)
+}
+
+// IsRewardRemainder returns whether epoch is either equal to the reward remainder fork epoch or greater.
+func (c *ChainConfig) IsRewardRemainder(epoch *big.Int) bool {
+	return isForked(c.RewardRemainderEpoch, epoch)
+}
+
+// InternalRotationPercent returns the percentage of the harmony operated
+// slots of each shard rotated at the election of the epoch, 0 if none.
+func (c *ChainConfig) InternalRotationPercent(epoch *big.Int) uint32 {
+	percent := uint32(0)
+	for _, step := range c.InternalRotation {
+		if !isForked(step.Epoch, epoch) {
+			break
+		}
+		percent = step.Percent
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// AvailabilityThreshold returns the signing threshold of the validators at
+// the epoch, between 0 and 1.
+func (c *ChainConfig) AvailabilityThreshold(epoch *big.Int) numeric.Dec {
+	threshold := DefaultAvailabilityThreshold
+	for _, step := range c.AvailabilityThresholds {
+		if !isForked(step.Epoch, epoch) {
+			break
+		}
+		if !step.Threshold.IsNil() {
+			threshold = step.Threshold
+		}
+	}
+	switch {
+	case threshold.IsNegative():
+		return numeric.ZeroDec()
+	case threshold.GT(numeric.OneDec()):
+		return numeric.OneDec()
+	}
+	return threshold.Copy()
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -268,6 +583,61 @@ func (c *ChainConfig) GasTable(epoch *big.Int) GasTable {
 	}
 }
 
+// Fork is a protocol upgrade of the chain config, activated at Epoch.
+type Fork struct {
+	Name  string   `json:"name"`
+	Epoch *big.Int `json:"epoch"`
+}
+
+// Forks returns all the epoch-activated protocol upgrades of the chain
+// config, in the order they are declared in ChainConfig.
+func (c *ChainConfig) Forks() []Fork {
+	fields := c.forkFields()
+	forks := make([]Fork, 0, len(fields))
+	for _, field := range fields {
+		forks = append(forks, Fork{field.name, *field.epoch})
+	}
+	return forks
+}
+
+// SetForkEpoch activates the fork of the name, as named by Forks, at the
+// epoch.
+func (c *ChainConfig) SetForkEpoch(name string, epoch *big.Int) error {
+	for _, field := range c.forkFields() {
+		if field.name == name {
+			*field.epoch = new(big.Int).Set(epoch)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown fork %q", name)
+}
+
+// forkField is the epoch field of a fork of the chain config
+type forkField struct {
+	name  string
+	epoch **big.Int
+}
+
+func (c *ChainConfig) forkFields() []forkField {
+	return []forkField{
+		{"cross-tx", &c.CrossTxEpoch},
+		{"cross-link", &c.CrossLinkEpoch},
+		{"staking", &c.StakingEpoch},
+		{"prestaking", &c.PreStakingEpoch},
+		{"quick-unlock", &c.QuickUnlockEpoch},
+		{"eip155", &c.EIP155Epoch},
+		{"s3", &c.S3Epoch},
+		{"receipt-log", &c.ReceiptLogEpoch},
+		{"dynamic-sharding", &c.DynamicShardingEpoch},
+		{"governance", &c.GovernanceEpoch},
+		{"shard-preference", &c.ShardPreferenceEpoch},
+		{"randomness", &c.RandomnessEpoch},
+		{"header-v4", &c.HeaderV4Epoch},
+		{"bls12381", &c.BLS12381Epoch},
+		{"reward-remainder", &c.RewardRemainderEpoch},
+	}
+}
+
 // isForked returns whether a fork scheduled at epoch s is active at the given head epoch.
 func isForked(s, epoch *big.Int) bool {
 	if s == nil || epoch == nil {
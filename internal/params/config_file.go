@@ -0,0 +1,106 @@
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+)
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// LoadChainConfig returns the chain config of the JSON or TOML file, by its
+// extension, the fields missing from the file set as in the base config. The
+// keys of the file are the json names of the fields of ChainConfig. The big
+// integers of a TOML file beyond the 64 bits of its integers, as the block
+// rewards, are written as strings of their decimal digits.
+func LoadChainConfig(path string, base *ChainConfig) (*ChainConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read chain config")
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".toml":
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse chain config %s", path)
+		}
+		values := tomlToJSON(tree.ToMap(), reflect.TypeOf(ChainConfig{}))
+		if data, err = json.Marshal(values); err != nil {
+			return nil, errors.Wrapf(err, "invalid chain config %s", path)
+		}
+	default:
+		return nil, errors.Errorf("chain config %s is neither .json nor .toml", path)
+	}
+
+	// decoded into a deep copy of the base config, not to change the epochs
+	// it shares with the other configs
+	baseData, err := json.Marshal(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot copy base chain config")
+	}
+	config := &ChainConfig{}
+	if err := json.Unmarshal(baseData, config); err != nil {
+		return nil, errors.Wrap(err, "cannot copy base chain config")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, errors.Wrapf(err, "invalid chain config %s", path)
+	}
+	if config.ChainID == nil {
+		return nil, errors.Errorf("chain config %s has no chain-id", path)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid chain config %s", path)
+	}
+	return config, nil
+}
+
+// tomlToJSON returns the values of a TOML tree decoding as JSON into the
+// type, the strings of the big integers turned into JSON numbers
+func tomlToJSON(value interface{}, typ reflect.Type) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch v := value.(type) {
+	case string:
+		if typ == bigIntType {
+			return json.Number(v)
+		}
+	case map[string]interface{}:
+		if typ.Kind() == reflect.Struct {
+			for key, item := range v {
+				if field, ok := jsonField(typ, key); ok {
+					v[key] = tomlToJSON(item, field.Type)
+				}
+			}
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice {
+			for i := range v {
+				v[i] = tomlToJSON(v[i], typ.Elem())
+			}
+		}
+	}
+	return value
+}
+
+// jsonField returns the field of the struct type of the json name
+func jsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if strings.EqualFold(tag, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/vm"
+	"github.com/harmony-one/harmony/internal/migration"
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/pkg/errors"
@@ -30,13 +31,15 @@ type Collection interface {
 // CollectionImpl is the main implementation of the shard chain collection.
 // See the Collection interface for details.
 type CollectionImpl struct {
-	dbFactory    DBFactory
-	dbInit       DBInitializer
-	engine       engine.Engine
-	mtx          sync.Mutex
-	pool         map[uint32]*core.BlockChain
-	disableCache bool
-	chainConfig  *params.ChainConfig
+	dbFactory     DBFactory
+	dbInit        DBInitializer
+	engine        engine.Engine
+	mtx           sync.Mutex
+	pool          map[uint32]*core.BlockChain
+	disableCache  bool
+	repairDepth   uint64
+	forkRetention uint64
+	chainConfig   *params.ChainConfig
 }
 
 // NewCollection creates and returns a new shard chain collection.
@@ -50,11 +53,12 @@ func NewCollection(
 	chainConfig *params.ChainConfig,
 ) *CollectionImpl {
 	return &CollectionImpl{
-		dbFactory:   dbFactory,
-		dbInit:      dbInit,
-		engine:      engine,
-		pool:        make(map[uint32]*core.BlockChain),
-		chainConfig: chainConfig,
+		dbFactory:     dbFactory,
+		dbInit:        dbInit,
+		engine:        engine,
+		pool:          make(map[uint32]*core.BlockChain),
+		chainConfig:   chainConfig,
+		forkRetention: core.DefaultForkRetention,
 	}
 }
 
@@ -86,6 +90,12 @@ func (sc *CollectionImpl) ShardChain(shardID uint32) (*core.BlockChain, error) {
 		if err := sc.dbInit.InitChainDB(db, shardID); err != nil {
 			return nil, errors.Wrapf(err, "cannot initialize a new chain database")
 		}
+		if err := migration.Init(db, migration.Migrations); err != nil {
+			return nil, errors.Wrapf(err, "cannot mark the schema of the new chain database")
+		}
+	}
+	if err := migration.Upgrade(db, migration.Migrations); err != nil {
+		return nil, errors.Wrapf(err, "cannot migrate the chain database")
 	}
 	var cacheConfig *core.CacheConfig
 	if sc.disableCache {
@@ -98,6 +108,10 @@ func (sc *CollectionImpl) ShardChain(shardID uint32) (*core.BlockChain, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot create blockchain")
 	}
+	if sc.repairDepth > 0 {
+		bc.EnableRepair(sc.repairDepth)
+	}
+	bc.SetForkRetention(sc.forkRetention)
 	db = nil // don't close
 	sc.pool[shardID] = bc
 	return bc, nil
@@ -110,6 +124,20 @@ func (sc *CollectionImpl) DisableCache() {
 	sc.disableCache = true
 }
 
+// EnableRepair repairs the corruptions of the newly opened chains found in
+// their depth last blocks, at their opening and whenever they find a state
+// missing. It does not affect already open chains.
+func (sc *CollectionImpl) EnableRepair(depth uint64) {
+	sc.repairDepth = depth
+}
+
+// SetForkRetention sets the number of blocks below the head the newly opened
+// chains retain their fork blocks for, 0 not to retain them. It does not
+// affect already open chains.
+func (sc *CollectionImpl) SetForkRetention(blocks uint64) {
+	sc.forkRetention = blocks
+}
+
 // CloseShardChain closes the given shard chain.
 func (sc *CollectionImpl) CloseShardChain(shardID uint32) error {
 	sc.mtx.Lock()
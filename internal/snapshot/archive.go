@@ -0,0 +1,332 @@
+package snapshot
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	common2 "github.com/harmony-one/harmony/internal/common"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// ManifestFile is the name of the manifest in the directory of an archive
+const ManifestFile = "manifest.json"
+
+// Errors of the archives
+var (
+	ErrArchiveExists    = errors.New("archive directory is not empty")
+	ErrNoManifest       = errors.New("no archive manifest")
+	ErrSignature        = errors.New("invalid archive manifest signature")
+	ErrUntrustedSigner  = errors.New("archive manifest signed by an untrusted signer")
+	ErrChunkCorrupted   = errors.New("archive chunk corrupted")
+	ErrSectionsMismatch = errors.New("restored databases differ from the manifest")
+)
+
+// Manifest describes an archive, a snapshot of the chain databases of a node
+// split into chunk files, with the checksums of the chunks. It is signed by
+// the publisher of the archive, so the archive can be fetched from anywhere
+// and its integrity verified before it is restored.
+type Manifest struct {
+	// Version is the version of the node which created the archive
+	Version string `json:"version"`
+	// Created is the unix time the archive was created at
+	Created  int64     `json:"created"`
+	Sections []Section `json:"sections"`
+	Chunks   []Chunk   `json:"chunks"`
+	// Signer is the address of the key signing the manifest
+	Signer    string `json:"signer"`
+	Signature string `json:"signature"`
+}
+
+// Chunk is a chunk file of an archive
+type Chunk struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// sigHash returns the hash of the manifest signed by its signer, the hash of
+// its encoding without its signature
+func (m *Manifest) sigHash() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(data), nil
+}
+
+// sign signs the manifest with the key
+func (m *Manifest) sign(key *ecdsa.PrivateKey) error {
+	signer, err := common2.AddressToBech32(crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		return err
+	}
+	m.Signer = signer
+	h, err := m.sigHash()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(h, key)
+	if err != nil {
+		return err
+	}
+	m.Signature = hexutil.Encode(sig)
+	return nil
+}
+
+// Verify verifies that the manifest is signed by its signer, which must be
+// one of the trusted signers unless there are none.
+func (m *Manifest) Verify(trusted []common.Address) error {
+	signer, err := common2.Bech32ToAddress(m.Signer)
+	if err != nil {
+		return ErrSignature
+	}
+	sig, err := hexutil.Decode(m.Signature)
+	if err != nil {
+		return ErrSignature
+	}
+	h, err := m.sigHash()
+	if err != nil {
+		return err
+	}
+	pub, err := crypto.SigToPub(h, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != signer {
+		return ErrSignature
+	}
+	if len(trusted) == 0 {
+		return nil
+	}
+	for _, address := range trusted {
+		if address == signer {
+			return nil
+		}
+	}
+	return ErrUntrustedSigner
+}
+
+// LoadManifest loads the manifest of the archive in the directory, without
+// verifying it.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "invalid archive manifest")
+	}
+	return m, nil
+}
+
+// chunkWriter splits what is written to it into the chunk files of an
+// archive, and computes their checksums
+type chunkWriter struct {
+	dir       string
+	chunkSize int64
+	chunks    []Chunk
+	f         *os.File
+	checksum  hash.Hash
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if cw.f == nil {
+			if err := cw.next(); err != nil {
+				return written, err
+			}
+		}
+		chunk := &cw.chunks[len(cw.chunks)-1]
+		n := int64(len(p))
+		if left := cw.chunkSize - chunk.Size; n > left {
+			n = left
+		}
+		if _, err := cw.f.Write(p[:n]); err != nil {
+			return written, err
+		}
+		cw.checksum.Write(p[:n])
+		chunk.Size += n
+		written += int(n)
+		p = p[n:]
+		if chunk.Size == cw.chunkSize {
+			if err := cw.close(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// next creates the next chunk file
+func (cw *chunkWriter) next() error {
+	name := fmt.Sprintf("chunk-%05d", len(cw.chunks))
+	f, err := os.Create(filepath.Join(cw.dir, name))
+	if err != nil {
+		return err
+	}
+	cw.f = f
+	cw.checksum = sha256.New()
+	cw.chunks = append(cw.chunks, Chunk{Name: name})
+	return nil
+}
+
+// close closes the current chunk file, if any
+func (cw *chunkWriter) close() error {
+	if cw.f == nil {
+		return nil
+	}
+	f := cw.f
+	cw.f = nil
+	cw.chunks[len(cw.chunks)-1].SHA256 = hex.EncodeToString(cw.checksum.Sum(nil))
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CreateArchive writes the archive of all the chain databases in dbDir into
+// the directory, as chunk files of at most chunkSize bytes and the manifest
+// signed with the key, written last. The node using dbDir must have been shut
+// down, so the databases are at a consistent head block. The directory is
+// created if needed, and must be empty.
+func CreateArchive(
+	dbDir, dir string, chunkSize int64, version string, key *ecdsa.PrivateKey,
+) (*Manifest, error) {
+	if chunkSize <= 0 {
+		return nil, errors.Errorf("invalid chunk size %d", chunkSize)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if files, err := ioutil.ReadDir(dir); err != nil {
+		return nil, err
+	} else if len(files) > 0 {
+		return nil, ErrArchiveExists
+	}
+	cw := &chunkWriter{dir: dir, chunkSize: chunkSize}
+	sections, err := export(dbDir, cw)
+	if cerr := cw.close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		for _, chunk := range cw.chunks {
+			os.Remove(filepath.Join(dir, chunk.Name))
+		}
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:  version,
+		Created:  time.Now().Unix(),
+		Sections: sections,
+		Chunks:   cw.chunks,
+	}
+	if err := m.sign(key); err != nil {
+		return nil, errors.Wrap(err, "cannot sign the archive manifest")
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, ManifestFile)); err != nil {
+		return nil, err
+	}
+	utils.Logger().Info().
+		Int("chunks", len(m.Chunks)).
+		Str("signer", m.Signer).
+		Msg("[snapshot] archive created")
+	return m, nil
+}
+
+// VerifyChunks verifies the sizes and the checksums of the chunk files of the
+// archive in the directory against its manifest.
+func VerifyChunks(dir string, m *Manifest) error {
+	for _, chunk := range m.Chunks {
+		f, err := os.Open(filepath.Join(dir, filepath.Base(chunk.Name)))
+		if err != nil {
+			return errors.Wrapf(err, "cannot open chunk %s", chunk.Name)
+		}
+		checksum := sha256.New()
+		size, err := io.Copy(checksum, f)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "cannot read chunk %s", chunk.Name)
+		}
+		if size != chunk.Size || hex.EncodeToString(checksum.Sum(nil)) != chunk.SHA256 {
+			return errors.Wrapf(ErrChunkCorrupted, "chunk %s", chunk.Name)
+		}
+	}
+	return nil
+}
+
+// RestoreArchive verifies the manifest of the archive in the directory, which
+// must be signed by one of the trusted signers unless there are none, and the
+// checksums of its chunks, and only then writes its chain databases into
+// dbDir, which must not have chain databases of the same shards.
+func RestoreArchive(dir, dbDir string, trusted []common.Address) (*Manifest, error) {
+	m, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Verify(trusted); err != nil {
+		return nil, err
+	}
+	if err := VerifyChunks(dir, m); err != nil {
+		return nil, err
+	}
+	readers := make([]io.Reader, 0, len(m.Chunks))
+	for _, chunk := range m.Chunks {
+		f, err := os.Open(filepath.Join(dir, filepath.Base(chunk.Name)))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	sections, err := importFrom(io.MultiReader(readers...), dbDir)
+	if err != nil {
+		return nil, err
+	}
+	if !sameSections(sections, m.Sections) {
+		// the databases did not exist before the import
+		for _, section := range sections {
+			os.RemoveAll(ChainDBDir(dbDir, section.ShardID))
+		}
+		return nil, ErrSectionsMismatch
+	}
+	return m, nil
+}
+
+func sameSections(a, b []Section) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
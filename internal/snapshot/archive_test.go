@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestCreateRestoreArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, archive := path.Join(dir, "src"), path.Join(dir, "archive")
+	makeTestDB(t, src, 0)
+	makeTestDB(t, src, 1)
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	created, err := CreateArchive(src, archive, 1024, "test", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Chunks) < 2 || len(created.Sections) != 2 {
+		t.Fatalf("created %d chunks of %d sections", len(created.Chunks), len(created.Sections))
+	}
+	if _, err := CreateArchive(src, archive, 1024, "test", key); err != ErrArchiveExists {
+		t.Errorf("created over an archive: %v", err)
+	}
+
+	restored, err := RestoreArchive(archive, path.Join(dir, "dst"), []common.Address{signer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameSections(restored.Sections, created.Sections) {
+		t.Errorf("restored %v, created %v", restored.Sections, created.Sections)
+	}
+	if shards, _ := ChainDBs(path.Join(dir, "dst")); len(shards) != 2 {
+		t.Errorf("restored the databases of shards %v", shards)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := RestoreArchive(
+		archive, path.Join(dir, "untrusted"), []common.Address{crypto.PubkeyToAddress(other.PublicKey)},
+	); err != ErrUntrustedSigner {
+		t.Errorf("restored the archive of an untrusted signer: %v", err)
+	}
+
+	// a tampered manifest is not signed anymore
+	tampered := *created
+	tampered.Version = "tampered"
+	data, _ := json.Marshal(&tampered)
+	if err := ioutil.WriteFile(path.Join(archive, ManifestFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreArchive(archive, path.Join(dir, "tampered"), nil); err != ErrSignature {
+		t.Errorf("restored a tampered manifest: %v", err)
+	}
+	data, _ = json.Marshal(created)
+	if err := ioutil.WriteFile(path.Join(archive, ManifestFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a corrupted chunk is detected before anything is restored
+	chunk := path.Join(archive, created.Chunks[1].Name)
+	content, _ := ioutil.ReadFile(chunk)
+	content[0] ^= 0xff
+	if err := ioutil.WriteFile(chunk, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := path.Join(dir, "corrupted")
+	if _, err := RestoreArchive(archive, dst, nil); errors.Cause(err) != ErrChunkCorrupted {
+		t.Errorf("restored a corrupted chunk: %v", err)
+	}
+	if shards, _ := ChainDBs(dst); len(shards) != 0 {
+		t.Errorf("left databases of shards %v", shards)
+	}
+}
@@ -0,0 +1,366 @@
+// Package snapshot exports the chain databases of a stopped node into a
+// compressed and checksummed snapshot file, and imports them into the
+// database directory of a new node. The off-chain indexes of a node, such as
+// the validator snapshots, the crosslinks and the cross shard receipts, are
+// kept in its chain databases, so they are part of the snapshot.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/core/rawdb"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// magic starts every snapshot file, it is followed by the gzip compressed
+// records of the snapshot
+var magic = []byte("HMYSNAP1")
+
+// Kinds of the records of a snapshot
+const (
+	// kindSection starts the entries of the database of a shard, its value
+	// is the RLP encoded Section
+	kindSection uint8 = iota
+	// kindEntry is a key and value of the database of the section
+	kindEntry
+	// kindEnd ends the snapshot, its value is the SHA-256 checksum of the
+	// encoding of all the records before it
+	kindEnd
+)
+
+// Errors of the snapshots
+var (
+	ErrNoDatabase  = errors.New("no chain database to export")
+	ErrBadMagic    = errors.New("not a snapshot file")
+	ErrChecksum    = errors.New("snapshot checksum mismatch")
+	ErrTruncated   = errors.New("snapshot is truncated")
+	ErrHeadMissing = errors.New("head block or its state is missing")
+)
+
+// record is the unit of the encoding of a snapshot
+type record struct {
+	Kind  uint8
+	Key   []byte
+	Value []byte
+}
+
+// Section is the database of a shard in a snapshot, at its head block
+type Section struct {
+	ShardID uint32
+	Number  uint64
+	Hash    common.Hash
+	Root    common.Hash
+	Entries uint64 `rlp:"-"`
+}
+
+func (s Section) String() string {
+	return fmt.Sprintf("shard %d at block %d %s (%d entries)",
+		s.ShardID, s.Number, s.Hash.Hex(), s.Entries)
+}
+
+// ChainDBDir returns the directory of the chain database of the shard, as
+// opened by shardchain.LDBFactory
+func ChainDBDir(dbDir string, shardID uint32) string {
+	return path.Join(dbDir, fmt.Sprintf("harmony_db_%d", shardID))
+}
+
+// ChainDBs returns the shards whose chain database is in dbDir
+func ChainDBs(dbDir string) ([]uint32, error) {
+	dirs, err := filepath.Glob(path.Join(dbDir, "harmony_db_*"))
+	if err != nil {
+		return nil, err
+	}
+	var shards []uint32
+	for _, dir := range dirs {
+		var shardID uint32
+		if _, err := fmt.Sscanf(path.Base(dir), "harmony_db_%d", &shardID); err != nil {
+			continue
+		}
+		if ChainDBDir(dbDir, shardID) == dir {
+			shards = append(shards, shardID)
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+	return shards, nil
+}
+
+// headSection returns the section of the database at its head block, whose
+// state must be in the database
+func headSection(db ethdb.Database, shardID uint32) (*Section, error) {
+	hash := rawdb.ReadHeadBlockHash(db)
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		return nil, ErrHeadMissing
+	}
+	header := rawdb.ReadHeader(db, hash, *number)
+	if header == nil {
+		return nil, ErrHeadMissing
+	}
+	if ok, _ := db.Has(header.Root().Bytes()); !ok {
+		return nil, ErrHeadMissing
+	}
+	return &Section{
+		ShardID: shardID,
+		Number:  *number,
+		Hash:    hash,
+		Root:    header.Root(),
+	}, nil
+}
+
+// recordWriter writes the records of a snapshot and computes their checksum
+type recordWriter struct {
+	w        io.Writer
+	checksum hash.Hash
+}
+
+func (rw *recordWriter) write(r *record) error {
+	data, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		return err
+	}
+	rw.checksum.Write(data)
+	_, err = rw.w.Write(data)
+	return err
+}
+
+// Export writes the snapshot of all the chain databases in dbDir to the
+// file. The node using dbDir must be stopped.
+func Export(dbDir, file string) ([]Section, error) {
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create snapshot file")
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+	sections, err := export(dbDir, f)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return sections, os.Rename(tmp, file)
+}
+
+// export writes the snapshot of all the chain databases in dbDir to w
+func export(dbDir string, w io.Writer) ([]Section, error) {
+	shards, err := ChainDBs(dbDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, ErrNoDatabase
+	}
+	if _, err := w.Write(magic); err != nil {
+		return nil, err
+	}
+	zw := gzip.NewWriter(w)
+	rw := &recordWriter{w: zw, checksum: sha256.New()}
+	var sections []Section
+	for _, shardID := range shards {
+		section, err := exportDB(rw, dbDir, shardID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot export the chain database of shard %d", shardID)
+		}
+		sections = append(sections, *section)
+	}
+	if err := rw.write(&record{Kind: kindEnd, Value: rw.checksum.Sum(nil)}); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// exportDB writes the section of the chain database of the shard
+func exportDB(rw *recordWriter, dbDir string, shardID uint32) (*Section, error) {
+	db, err := ethdb.NewLDBDatabase(ChainDBDir(dbDir, shardID), 0, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open the database, is the node stopped?")
+	}
+	defer db.Close()
+	section, err := headSection(db, shardID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := rlp.EncodeToBytes(section)
+	if err != nil {
+		return nil, err
+	}
+	if err := rw.write(&record{Kind: kindSection, Value: value}); err != nil {
+		return nil, err
+	}
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		if err := rw.write(&record{Kind: kindEntry, Key: it.Key(), Value: it.Value()}); err != nil {
+			return nil, err
+		}
+		section.Entries++
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	utils.Logger().Info().Str("section", section.String()).Msg("[snapshot] exported")
+	return section, nil
+}
+
+// Import writes the chain databases of the snapshot file into dbDir, which
+// must not have chain databases of the same shards. The databases are only
+// moved in place once the checksum of the whole snapshot is verified.
+func Import(file, dbDir string) ([]Section, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open snapshot file")
+	}
+	defer f.Close()
+	return importFrom(f, dbDir)
+}
+
+// importFrom writes the chain databases of the snapshot read from in into
+// dbDir, as Import does
+func importFrom(in io.Reader, dbDir string) ([]Section, error) {
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(in, head); err != nil || !bytes.Equal(head, magic) {
+		return nil, ErrBadMagic
+	}
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decompress snapshot")
+	}
+	if err := os.MkdirAll(dbDir, 0700); err != nil {
+		return nil, err
+	}
+
+	var (
+		stream   = rlp.NewStream(zr, 0)
+		checksum = sha256.New()
+		sections []Section
+		dirs     []string
+		db       *ethdb.LDBDatabase
+		batch    ethdb.Batch
+	)
+	// the imported databases are removed unless all of them are moved in place
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+	closeDB := func() error {
+		if db == nil {
+			return nil
+		}
+		err := batch.Write()
+		if err == nil {
+			err = checkHead(db, &sections[len(sections)-1])
+		}
+		db.Close()
+		db = nil
+		return err
+	}
+
+	for {
+		raw, err := stream.Raw()
+		if err != nil {
+			return nil, ErrTruncated
+		}
+		var r record
+		if err := rlp.DecodeBytes(raw, &r); err != nil {
+			return nil, errors.Wrap(err, "invalid snapshot record")
+		}
+		if r.Kind == kindEnd {
+			if !bytes.Equal(r.Value, checksum.Sum(nil)) {
+				return nil, ErrChecksum
+			}
+			break
+		}
+		checksum.Write(raw)
+		switch r.Kind {
+		case kindSection:
+			if err := closeDB(); err != nil {
+				return nil, err
+			}
+			var section Section
+			if err := rlp.DecodeBytes(r.Value, &section); err != nil {
+				return nil, errors.Wrap(err, "invalid snapshot section")
+			}
+			dir := ChainDBDir(dbDir, section.ShardID)
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				return nil, errors.Errorf("chain database %s already exists", dir)
+			}
+			tmp := dir + ".import"
+			if err := os.RemoveAll(tmp); err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, tmp)
+			if db, err = ethdb.NewLDBDatabase(tmp, 0, 0); err != nil {
+				return nil, err
+			}
+			batch = db.NewBatch()
+			sections = append(sections, section)
+		case kindEntry:
+			if db == nil {
+				return nil, errors.New("snapshot entry before its section")
+			}
+			if err := batch.Put(r.Key, r.Value); err != nil {
+				return nil, err
+			}
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return nil, err
+				}
+				batch.Reset()
+			}
+			sections[len(sections)-1].Entries++
+		default:
+			return nil, errors.Errorf("unknown snapshot record kind %d", r.Kind)
+		}
+	}
+	if err := closeDB(); err != nil {
+		return nil, err
+	}
+	for i, tmp := range dirs {
+		if err := os.Rename(tmp, ChainDBDir(dbDir, sections[i].ShardID)); err != nil {
+			return nil, err
+		}
+	}
+	dirs = nil
+	for _, section := range sections {
+		utils.Logger().Info().Str("section", section.String()).Msg("[snapshot] imported")
+	}
+	return sections, nil
+}
+
+// checkHead checks that the imported database is at the block of its section
+func checkHead(db ethdb.Database, section *Section) error {
+	head, err := headSection(db, section.ShardID)
+	if err != nil {
+		return err
+	}
+	if head.Hash != section.Hash || head.Root != section.Root {
+		return errors.Errorf("imported shard %d is at block %s, expected %s",
+			section.ShardID, head.Hash.Hex(), section.Hash.Hex())
+	}
+	return nil
+}
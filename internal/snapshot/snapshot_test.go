@@ -0,0 +1,114 @@
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/rawdb"
+)
+
+// makeTestDB writes a chain database of the shard with a head block and
+// entries in dbDir
+func makeTestDB(t *testing.T, dbDir string, shardID uint32) {
+	db, err := ethdb.NewLDBDatabase(ChainDBDir(dbDir, shardID), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	root := common.HexToHash("0xabcd")
+	header := blockfactory.NewTestHeader().With().
+		ShardID(shardID).
+		Number(big.NewInt(42)).
+		Root(root).
+		Header()
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), 42)
+	rawdb.WriteHeadBlockHash(db, header.Hash())
+	db.Put(root[:], []byte("state root node"))
+	for i := 0; i < 1000; i++ {
+		db.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d-%d", shardID, i)))
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, dst, file := path.Join(dir, "src"), path.Join(dir, "dst"), path.Join(dir, "snapshot")
+	makeTestDB(t, src, 0)
+	makeTestDB(t, src, 2)
+
+	exported, err := Export(src, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 2 || exported[0].ShardID != 0 || exported[1].ShardID != 2 ||
+		exported[0].Number != 42 {
+		t.Fatalf("exported %v", exported)
+	}
+	imported, err := Import(file, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 || imported[1] != exported[1] {
+		t.Fatalf("imported %v, exported %v", imported, exported)
+	}
+	db, err := ethdb.NewLDBDatabase(ChainDBDir(dst, 2), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if value, _ := db.Get([]byte("key-999")); string(value) != "value-2-999" {
+		t.Errorf("got imported value %q", value)
+	}
+	if rawdb.ReadHeadBlockHash(db) != exported[1].Hash {
+		t.Error("imported head block is not the exported one")
+	}
+
+	// the databases of a node are not overwritten
+	if _, err := Import(file, dst); err == nil {
+		t.Error("imported over existing databases")
+	}
+}
+
+func TestImportCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, file := path.Join(dir, "src"), path.Join(dir, "snapshot")
+	makeTestDB(t, src, 1)
+	if _, err := Export(src, file); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]byte{
+		"truncated":      data[:len(data)/2],
+		"not a snapshot": append([]byte("NOTASNAP"), data[len(magic):]...),
+	}
+	for name, corrupted := range tests {
+		if err := ioutil.WriteFile(file, corrupted, 0600); err != nil {
+			t.Fatal(err)
+		}
+		dst := path.Join(dir, name)
+		if _, err := Import(file, dst); err == nil {
+			t.Errorf("%s: imported", name)
+		}
+		if shards, _ := ChainDBs(dst); len(shards) != 0 {
+			t.Errorf("%s: left databases of shards %v", name, shards)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// logLevels are the zerolog levels of the global logs and of the modules
+// logging at their own verbosity
+type logLevels struct {
+	global  zerolog.Level
+	modules map[string]zerolog.Level
+}
+
+var (
+	// current logLevels, swapped on a change of verbosity
+	currentLogLevels atomic.Value
+	// verbosity of the modules, by module directory
+	logModules = map[string]log.Lvl{}
+	// root directory of the source files, the modules are relative to
+	sourceRoot = func() string {
+		_, file, _, _ := runtime.Caller(0)
+		return path.Dir(path.Dir(path.Dir(file)))
+	}()
+)
+
+// logSubsystems are the subsystems usable as log modules, with the module
+// directories they stand for
+var logSubsystems = map[string][]string{
+	"sync":    {"api/service/syncing"},
+	"rewards": {"internal/chain", "staking/network", "staking/availability", "staking/apr"},
+	"rpc":     {"internal/hmyapi", "hmy"},
+	"p2p":     {"p2p", "api/service/networkinfo"},
+}
+
+// ParseLogModules parses the verbosity of the modules given as
+// module=verbosity,module=verbosity, a module being a directory of the
+// repository such as consensus or api/service/syncing, or one of the sync,
+// rewards, rpc and p2p subsystems. The later modules override the earlier.
+func ParseLogModules(s string) (map[string]log.Lvl, error) {
+	modules := map[string]log.Lvl{}
+	if s == "" {
+		return modules, nil
+	}
+	for _, module := range strings.Split(s, ",") {
+		parts := strings.Split(module, "=")
+		if len(parts) != 2 || strings.Trim(parts[0], "/") == "" {
+			return nil, errors.Errorf("log module %q is not module=verbosity", module)
+		}
+		verbosity, err := strconv.Atoi(parts[1])
+		if err != nil || verbosity < 0 || verbosity > int(log.LvlTrace) {
+			return nil, errors.Errorf("invalid verbosity of log module %s %q", parts[0], parts[1])
+		}
+		name := strings.Trim(parts[0], "/")
+		dirs, ok := logSubsystems[name]
+		if !ok {
+			dirs = []string{name}
+		}
+		for _, dir := range dirs {
+			modules[dir] = log.Lvl(verbosity)
+		}
+	}
+	return modules, nil
+}
+
+// SetLogModuleVerbosity sets the verbosity of the logs of the modules, the
+// logs of the submodules included. The logs of the other modules keep the
+// verbosity of SetLogVerbosity.
+func SetLogModuleVerbosity(modules map[string]log.Lvl) {
+	logModules = modules
+	if glogger != nil {
+		glogger.Vmodule(logVmodule())
+	}
+	updateZeroLogLevel(int(logVerbosity))
+}
+
+// logVmodule returns the verbosity of the modules as a glog vmodule pattern
+func logVmodule() string {
+	patterns := make([]string, 0, len(logModules))
+	for module, verbosity := range logModules {
+		patterns = append(patterns, fmt.Sprintf("%s/*=%d", module, verbosity))
+	}
+	sort.Strings(patterns)
+	return strings.Join(patterns, ",")
+}
+
+// storeLogLevels swaps the levels applied by the module hook, and returns
+// the level of the most verbose module
+func storeLogLevels(global zerolog.Level) zerolog.Level {
+	levels := logLevels{global, make(map[string]zerolog.Level, len(logModules))}
+	lowest := global
+	for module, verbosity := range logModules {
+		level := zeroLogLevel(int(verbosity))
+		levels.modules[module] = level
+		if level < lowest {
+			lowest = level
+		}
+	}
+	currentLogLevels.Store(levels)
+	return lowest
+}
+
+// moduleHook discards the events below the level of the module logging
+// them, the logger lets through the events of the most verbose module
+type moduleHook struct{}
+
+func (moduleHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	levels, ok := currentLogLevels.Load().(logLevels)
+	if !ok || len(levels.modules) == 0 {
+		return
+	}
+	if level < levels.of(callerFile()) {
+		e.Discard()
+	}
+}
+
+// of returns the level of the module of a source file, the level of the
+// innermost module wins
+func (levels logLevels) of(file string) zerolog.Level {
+	dir := path.Dir(strings.TrimPrefix(file, sourceRoot+"/"))
+	level, matched := levels.global, ""
+	for module, moduleLevel := range levels.modules {
+		if (dir == module || strings.HasPrefix(dir, module+"/")) && len(module) > len(matched) {
+			level, matched = moduleLevel, module
+		}
+	}
+	return level
+}
+
+// callerFile returns the source file of the code logging an event, outside
+// of zerolog
+func callerFile() string {
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/rs/zerolog") {
+			return frame.File
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/rs/zerolog"
+)
+
+func TestParseLogModules(t *testing.T) {
+	modules, err := ParseLogModules("consensus=4,/api/service/syncing/=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules["consensus"] != log.LvlDebug || modules["api/service/syncing"] != log.LvlError {
+		t.Errorf("got %v", modules)
+	}
+	modules, err = ParseLogModules("rpc=4,hmy=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules["internal/hmyapi"] != log.LvlDebug || modules["hmy"] != log.LvlWarn {
+		t.Errorf("got %v", modules)
+	}
+	for _, s := range []string{"consensus", "consensus=", "=4", "consensus=6", "consensus=-1", "consensus=4,"} {
+		if _, err := ParseLogModules(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestModuleHook(t *testing.T) {
+	defer func(modules map[string]log.Lvl) {
+		logModules = modules
+		storeLogLevels(zeroLogLevel(int(logVerbosity)))
+	}(logModules)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Hook(moduleHook{})
+	tests := []struct {
+		modules map[string]log.Lvl
+		logged  bool
+	}{
+		{map[string]log.Lvl{}, true},
+		{map[string]log.Lvl{"internal": log.LvlDebug}, true},
+		{map[string]log.Lvl{"internal": log.LvlWarn}, false},
+		{map[string]log.Lvl{"internal": log.LvlWarn, "internal/utils": log.LvlDebug}, true},
+		{map[string]log.Lvl{"internal": log.LvlDebug, "internal/utils": log.LvlError}, false},
+		{map[string]log.Lvl{"consensus": log.LvlError}, true},
+		{map[string]log.Lvl{"internal/util": log.LvlError}, true},
+	}
+	for i, test := range tests {
+		buf.Reset()
+		logModules = test.modules
+		storeLogLevels(zerolog.DebugLevel)
+		logger.Info().Msg("module")
+		if logged := buf.Len() > 0; logged != test.logged {
+			t.Errorf("index %d: logged %v, expect %v", i, logged, test.logged)
+		}
+	}
+
+	// the logs outside of the modules keep the global level
+	buf.Reset()
+	logModules = map[string]log.Lvl{"consensus": log.LvlDebug}
+	storeLogLevels(zerolog.ErrorLevel)
+	logger.Info().Msgf("global %d", 1)
+	if buf.Len() > 0 {
+		t.Error("expect the log below the global level discarded")
+	}
+}
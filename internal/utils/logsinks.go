@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/natefinch/lumberjack"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Formats of the log sinks
+const (
+	LogSinkJSON   = "json"
+	LogSinkLogfmt = "logfmt"
+	LogSinkSyslog = "syslog"
+)
+
+// syslogTag is the tag of the logs sent to syslog
+const syslogTag = "harmony"
+
+// LogSink is an output the logs are written to in addition to the log file of
+// the node. The target of the json and logfmt sinks is stderr, stdout or the
+// path of a rotated file, and the target of the syslog sink is empty for the
+// local syslog, or the network and address of a remote one, e.g.
+// udp:10.0.0.1:514.
+type LogSink struct {
+	Format string
+	Target string
+}
+
+var (
+	// logOutput is the main output of the logs, the log file of the node
+	logOutput io.Writer
+	// logSinkWriters are the writers of the log sinks
+	logSinkWriters []io.Writer
+)
+
+// ParseLogSinks parses the log sinks given as format:target,format:target,
+// e.g. json:/var/log/harmony.json,logfmt:stderr,syslog
+func ParseLogSinks(s string) ([]LogSink, error) {
+	sinks := []LogSink{}
+	if s == "" {
+		return sinks, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.SplitN(item, ":", 2)
+		sink := LogSink{Format: parts[0]}
+		if len(parts) == 2 {
+			sink.Target = parts[1]
+		}
+		switch sink.Format {
+		case LogSinkJSON, LogSinkLogfmt:
+			if sink.Target == "" {
+				return nil, errors.Errorf("log sink %q has no target", item)
+			}
+		case LogSinkSyslog:
+			if sink.Target != "" && len(strings.SplitN(sink.Target, ":", 2)) != 2 {
+				return nil, errors.Errorf("syslog sink %q is not syslog:network:address", item)
+			}
+		default:
+			return nil, errors.Errorf("unknown format of log sink %q", item)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// SetLogSinks writes the logs to the sinks in addition to the log file of the
+// node, the rotated files of the sinks being of up to maxSize megabytes.
+func SetLogSinks(sinks []LogSink, maxSize int) error {
+	writers := make([]io.Writer, 0, len(sinks))
+	for _, sink := range sinks {
+		w, err := openLogSink(sink, maxSize)
+		if err != nil {
+			return errors.Wrapf(err, "cannot open log sink %s:%s", sink.Format, sink.Target)
+		}
+		writers = append(writers, w)
+	}
+	logSinkWriters = writers
+	updateZeroLogOutput()
+	return nil
+}
+
+// openLogSink returns the writer of the sink
+func openLogSink(sink LogSink, maxSize int) (io.Writer, error) {
+	if sink.Format == LogSinkSyslog {
+		network, address := "", ""
+		if sink.Target != "" {
+			parts := strings.SplitN(sink.Target, ":", 2)
+			network, address = parts[0], parts[1]
+		}
+		w, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, syslogTag)
+		if err != nil {
+			return nil, err
+		}
+		return zerolog.SyslogLevelWriter(w), nil
+	}
+	var w io.Writer
+	switch sink.Target {
+	case "stderr":
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		w = &lumberjack.Logger{Filename: sink.Target, MaxSize: maxSize, Compress: true}
+	}
+	if sink.Format == LogSinkLogfmt {
+		return logfmtWriter{w}, nil
+	}
+	return w, nil
+}
+
+// updateZeroLogOutput writes the logs to the main output and the sinks
+func updateZeroLogOutput() {
+	writers := append([]io.Writer{}, logSinkWriters...)
+	if logOutput != nil {
+		writers = append([]io.Writer{logOutput}, writers...)
+	}
+	childLogger := Logger().Output(zerolog.MultiLevelWriter(writers...))
+	zeroLogger = &childLogger
+}
+
+// logfmtWriter writes the JSON events of zerolog as logfmt lines
+type logfmtWriter struct {
+	w io.Writer
+}
+
+// logfmtFirstKeys are the keys written first, in this order
+var logfmtFirstKeys = []string{
+	zerolog.TimestampFieldName, zerolog.LevelFieldName,
+	zerolog.CallerFieldName, zerolog.MessageFieldName,
+}
+
+func (l logfmtWriter) Write(p []byte) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	event := map[string]interface{}{}
+	if err := decoder.Decode(&event); err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(event))
+	for _, key := range logfmtFirstKeys {
+		if _, ok := event[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	rest := make([]string, 0, len(event))
+	for key := range event {
+		if !isLogfmtFirstKey(key) {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	buf := bytes.Buffer{}
+	for i, key := range append(keys, rest...) {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(event[key]))
+	}
+	buf.WriteByte('\n')
+	if _, err := l.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func isLogfmtFirstKey(key string) bool {
+	for _, first := range logfmtFirstKeys {
+		if key == first {
+			return true
+		}
+	}
+	return false
+}
+
+// logfmtValue formats a value of an event, quoted if it is not a bare word
+func logfmtValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number, bool:
+		s = fmt.Sprint(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		s = string(encoded)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
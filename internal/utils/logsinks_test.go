@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestParseLogSinks(t *testing.T) {
+	sinks, err := ParseLogSinks("json:/var/log/harmony.json,logfmt:stderr,syslog,syslog:udp:10.0.0.1:514")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []LogSink{
+		{LogSinkJSON, "/var/log/harmony.json"},
+		{LogSinkLogfmt, "stderr"},
+		{LogSinkSyslog, ""},
+		{LogSinkSyslog, "udp:10.0.0.1:514"},
+	}
+	if len(sinks) != len(expect) {
+		t.Fatalf("got %v, expect %v", sinks, expect)
+	}
+	for i := range sinks {
+		if sinks[i] != expect[i] {
+			t.Errorf("index %d: got %v, expect %v", i, sinks[i], expect[i])
+		}
+	}
+	for _, s := range []string{"json", "logfmt:", "xml:stderr", "syslog:udp", "json:stderr,"} {
+		if _, err := ParseLogSinks(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestLogfmtWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(logfmtWriter{buf})
+	logger.Info().
+		Str("peer", "QmPeer").
+		Uint64("block", 12).
+		Str("reason", "bad sig").
+		Interface("shards", []int{0, 1}).
+		Msg("block rejected")
+	expect := `level=info message="block rejected" block=12 peer=QmPeer reason="bad sig" shards=[0,1]` + "\n"
+	if got := buf.String(); got != expect {
+		t.Errorf("got %q, expect %q", got, expect)
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// secretRef matches the ${env:NAME} and ${file:path} secret references
+var secretRef = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// ExpandSecrets replaces the secret references of the value with the secrets
+// they refer to, so the configs of the node can be distributed without their
+// secrets: ${env:NAME} with the environment variable NAME, and ${file:path}
+// with the content of the file, without its trailing newlines, such as a
+// mounted secret.
+func ExpandSecrets(value string) (string, error) {
+	var err error
+	expanded := secretRef.ReplaceAllStringFunc(value, func(ref string) string {
+		match := secretRef.FindStringSubmatch(ref)
+		secret, e := readSecret(match[1], match[2])
+		if e != nil && err == nil {
+			err = e
+		}
+		return secret
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// ExpandSecretsEnv returns the environment variable, with its secret
+// references expanded.
+func ExpandSecretsEnv(name string) (string, error) {
+	value, err := ExpandSecrets(os.Getenv(name))
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot expand %s", name)
+	}
+	return value, nil
+}
+
+func readSecret(kind, ref string) (string, error) {
+	switch kind {
+	case "env":
+		secret, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", errors.Errorf("environment variable %#v of a secret undefined", ref)
+		}
+		return secret, nil
+	default:
+		data, err := ioutil.ReadFile(ref)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot read secret file %#v", ref)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(file, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("HARMONY_TEST_SECRET", "env-token")
+	defer os.Unsetenv("HARMONY_TEST_SECRET")
+
+	tests := map[string]string{
+		"":                           "",
+		"no secret":                  "no secret",
+		"${env:HARMONY_TEST_SECRET}": "env-token",
+		"https://hooks.example.com/${file:" + file + "}?user=${env:HARMONY_TEST_SECRET}": "https://hooks.example.com/file-token?user=env-token",
+	}
+	for value, expected := range tests {
+		if expanded, err := ExpandSecrets(value); err != nil {
+			t.Errorf("%q: %v", value, err)
+		} else if expanded != expected {
+			t.Errorf("%q expanded to %q, expected %q", value, expanded, expected)
+		}
+	}
+	for _, value := range []string{
+		"${env:HARMONY_TEST_UNDEFINED}", "${file:" + filepath.Join(dir, "missing") + "}",
+	} {
+		if _, err := ExpandSecrets(value); err == nil {
+			t.Errorf("%q expanded", value)
+		}
+	}
+}
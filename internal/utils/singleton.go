@@ -79,6 +79,7 @@ func GetLogInstance() log.Logger {
 		multiHandler := log.MultiHandler(logHandlers...)
 		glogger = log.NewGlogHandler(multiHandler)
 		glogger.Verbosity(logVerbosity)
+		glogger.Vmodule(logVmodule())
 		logInstance = log.New("port", port, "ip", ip)
 		logInstance.SetHandler(glogger)
 		log.Root().SetHandler(glogger)
@@ -105,12 +106,13 @@ func setZeroLoggerFileOutput(filepath string, maxSize int) error {
 	// Initialize ZeroLogger if it hasn't been already
 	// TODO: zerolog filename prefix can be removed once all loggers
 	// has been replaced
-	childLogger := Logger().Output(&lumberjack.Logger{
+	Logger()
+	logOutput = &lumberjack.Logger{
 		Filename: fmt.Sprintf("%s/zerolog-%s", dir, filename),
 		MaxSize:  maxSize,
 		Compress: true,
-	})
-	zeroLogger = &childLogger
+	}
+	updateZeroLogOutput()
 
 	return nil
 }
@@ -166,8 +168,10 @@ func Logger() *zerolog.Logger {
 		writer := diode.NewWriter(os.Stderr, 1000, 10*time.Millisecond, func(missed int) {
 			fmt.Printf("Logger Dropped %d messages", missed)
 		})
-		logger := zerolog.New(zerolog.ConsoleWriter{Out: writer}).
+		logOutput = zerolog.ConsoleWriter{Out: writer}
+		logger := zerolog.New(logOutput).
 			Level(zeroLoggerLevel).
+			Hook(moduleHook{}).
 			With().
 			Caller().
 			Timestamp().
@@ -195,19 +199,23 @@ func SampledLogger() *zerolog.Logger {
 	return sampledLogger
 }
 
-func updateZeroLogLevel(level int) {
+func zeroLogLevel(level int) zerolog.Level {
 	switch level {
 	case 0:
-		zeroLoggerLevel = zerolog.Disabled
+		return zerolog.Disabled
 	case 1:
-		zeroLoggerLevel = zerolog.ErrorLevel
+		return zerolog.ErrorLevel
 	case 2:
-		zeroLoggerLevel = zerolog.WarnLevel
+		return zerolog.WarnLevel
 	case 3:
-		zeroLoggerLevel = zerolog.InfoLevel
+		return zerolog.InfoLevel
 	default:
-		zeroLoggerLevel = zerolog.DebugLevel
+		return zerolog.DebugLevel
 	}
+}
+
+func updateZeroLogLevel(level int) {
+	zeroLoggerLevel = storeLogLevels(zeroLogLevel(level))
 	childLogger := Logger().Level(zeroLoggerLevel)
 	zeroLogger = &childLogger
 }
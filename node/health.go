@@ -0,0 +1,126 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/harmony-one/harmony/api/service/syncing"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/rawdb"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	commonRPC "github.com/harmony-one/harmony/internal/hmyapi/common"
+	"github.com/harmony-one/harmony/shard"
+)
+
+// healthMaxBlockLag is the number of blocks a chain may lag behind the sync
+// peers before the node reports it is not ready to serve
+const healthMaxBlockLag = 5
+
+// newChainHealth compares the current block of a chain to the highest block
+// of the sync peers, an unknown peer height of 0 does not count as lag
+func newChainHealth(shardID uint32, current, peer uint64) commonRPC.ChainHealth {
+	health := commonRPC.ChainHealth{
+		ShardID:      shardID,
+		CurrentBlock: current,
+		PeerBlock:    peer,
+		InSync:       true,
+	}
+	if health.PeerBlock > health.CurrentBlock {
+		health.Lag = health.PeerBlock - health.CurrentBlock
+		health.InSync = health.Lag <= healthMaxBlockLag
+	}
+	return health
+}
+
+// checkChainDB reads the head block back from the database of bc
+func checkChainDB(bc *core.BlockChain) error {
+	db := bc.ChainDb()
+	hash := rawdb.ReadHeadBlockHash(db)
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		return fmt.Errorf("shard %d: head block %x not found in database", bc.ShardID(), hash)
+	}
+	if rawdb.ReadHeader(db, hash, *number) == nil {
+		return fmt.Errorf("shard %d: head header %x not readable from database", bc.ShardID(), hash)
+	}
+	return nil
+}
+
+// GetHealthStatus reports the sync lag of the chains the node tracks, its
+// consensus participation and the state of its databases. The node is healthy
+// when its databases are readable and ready when, in addition, every chain is
+// within healthMaxBlockLag blocks of the sync peers.
+func (node *Node) GetHealthStatus() commonRPC.HealthStatus {
+	node.stateMutex.Lock()
+	state := node.State
+	node.stateMutex.Unlock()
+	status := commonRPC.HealthStatus{
+		Healthy:       true,
+		Ready:         true,
+		NodeState:     state.String(),
+		ConsensusMode: node.Consensus.Mode().String(),
+		IsLeader:      node.Consensus.IsLeader(),
+		Chains:        []commonRPC.ChainHealth{},
+		Problems:      []string{},
+	}
+	if node.Consensus.PubKey != nil {
+		for _, key := range node.Consensus.PubKey.PublicKey {
+			if node.Consensus.IsValidatorInCommittee(key) {
+				status.InCommittee = true
+				break
+			}
+		}
+	}
+
+	chains := []*core.BlockChain{node.Blockchain()}
+	syncs := []*syncing.StateSync{node.stateSync}
+	if node.Blockchain().ShardID() != shard.BeaconChainShardID {
+		chains = append(chains, node.Beaconchain())
+		syncs = append(syncs, node.beaconSync)
+	}
+	for i, bc := range chains {
+		if err := checkChainDB(bc); err != nil {
+			status.Healthy = false
+			status.Problems = append(status.Problems, err.Error())
+		}
+		peer := uint64(0)
+		if syncs[i] != nil {
+			peer = syncs[i].LastPeerHeight()
+		}
+		height := bc.CurrentBlock().NumberU64()
+		if i > 0 && nodeconfig.GetBeaconHeaderSync() {
+			// the beacon chain only has its headers
+			height = bc.CurrentHeader().Number().Uint64()
+		}
+		health := newChainHealth(bc.ShardID(), height, peer)
+		if !health.InSync {
+			status.Ready = false
+			status.Problems = append(status.Problems, fmt.Sprintf(
+				"shard %d: %d blocks behind peers", health.ShardID, health.Lag,
+			))
+		}
+		status.Chains = append(status.Chains, health)
+	}
+	status.Ready = status.Ready && status.Healthy
+	return status
+}
+
+func (node *Node) serveHealth(w http.ResponseWriter, ok func(commonRPC.HealthStatus) bool) {
+	status := node.GetHealthStatus()
+	w.Header().Set("Content-Type", "application/json")
+	if !ok(status) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// healthzHandler answers liveness probes
+func (node *Node) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	node.serveHealth(w, func(s commonRPC.HealthStatus) bool { return s.Healthy })
+}
+
+// readyzHandler answers readiness probes
+func (node *Node) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	node.serveHealth(w, func(s commonRPC.HealthStatus) bool { return s.Ready })
+}
@@ -0,0 +1,24 @@
+package node
+
+import "testing"
+
+func TestNewChainHealth(t *testing.T) {
+	tests := []struct {
+		current, peer uint64
+		lag           uint64
+		inSync        bool
+	}{
+		{100, 0, 0, true}, // peers not asked yet
+		{100, 90, 0, true},
+		{100, 100 + healthMaxBlockLag, healthMaxBlockLag, true},
+		{100, 101 + healthMaxBlockLag, healthMaxBlockLag + 1, false},
+	}
+	for i, test := range tests {
+		health := newChainHealth(1, test.current, test.peer)
+		if health.Lag != test.lag || health.InSync != test.inSync {
+			t.Errorf("index %d: got lag %d in sync %v, expect %d %v",
+				i, health.Lag, health.InSync, test.lag, test.inSync,
+			)
+		}
+	}
+}
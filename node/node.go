@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 	protobuf "github.com/golang/protobuf/proto"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/harmony/api/client"
@@ -22,6 +23,7 @@ import (
 	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	"github.com/harmony-one/harmony/consensus"
+	"github.com/harmony-one/harmony/consensus/signguard"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/rawdb"
 	"github.com/harmony-one/harmony/core/types"
@@ -29,6 +31,7 @@ import (
 	"github.com/harmony-one/harmony/internal/chain"
 	common2 "github.com/harmony-one/harmony/internal/common"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/metrics"
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/internal/shardchain"
 	"github.com/harmony-one/harmony/internal/utils"
@@ -140,6 +143,15 @@ type Node struct {
 	SyncingPeerProvider    SyncingPeerProvider
 	// The p2p host used to send/receive p2p messages
 	host p2p.Host
+	// The node whose process hosts the shard of this node, nil unless the
+	// node was created by HostShard
+	primary *Node
+	// The nodes of the other shards hosted in the process of this node
+	hostedShards []*Node
+	// The lease on the signing with the keys shared with a standby node
+	signingLease *signguard.Lease
+	// The explorer index paused on a low free disk space
+	indexPause indexPause
 	// Service manager.
 	serviceManager               *service.Manager
 	ContractDeployerKey          *ecdsa.PrivateKey
@@ -164,6 +176,10 @@ type Node struct {
 	// BroadcastInvalidTx flag is considered when adding pending tx to tx-pool
 	BroadcastInvalidTx bool
 
+	// economicsEpoch is the epoch the staking economics gauges are computed at
+	economicsEpoch *big.Int
+	economicsLock  sync.Mutex
+
 	// metrics of p2p messages
 	NumTotalMessages   uint32
 	NumValidMessages   uint32
@@ -279,7 +295,9 @@ func (node *Node) AddPendingStakingTransaction(
 				break
 			}
 		}
-		if err == nil || node.BroadcastInvalidTx {
+		// the staking transactions rejected by the policy of the node are
+		// never relayed
+		if err == nil || node.BroadcastInvalidTx && errors.Cause(err) != core.ErrStakingDirectiveRejected {
 			utils.Logger().Info().Str("Hash", newStakingTx.Hash().Hex()).Msg("Broadcasting Staking Tx")
 			node.tryBroadcastStaking(newStakingTx)
 		}
@@ -460,11 +478,109 @@ func (node *Node) validateShardBoundMessage(
 	return &m, senderKey, false, nil
 }
 
+// messageKind returns the kind of a message starting with its category,
+// which the inbound messages are rate limited by
+func messageKind(payload []byte) string {
+	if proto.MessageCategory(payload[0]) == proto.Consensus {
+		return p2p.ConsensusMessageKind
+	}
+	if len(payload) < proto.MessageCategoryBytes+proto.MessageTypeBytes {
+		return "other"
+	}
+	switch proto_node.MessageType(payload[proto.MessageCategoryBytes+proto.MessageTypeBytes-1]) {
+	case proto_node.Transaction:
+		return "transaction"
+	case proto_node.Staking:
+		return "staking"
+	case proto_node.Block:
+		if len(payload) <= proto.MessageCategoryBytes+proto.MessageTypeBytes {
+			return "other"
+		}
+		switch proto_node.BlockMessageType(payload[proto.MessageCategoryBytes+proto.MessageTypeBytes]) {
+		case proto_node.Sync:
+			return "block"
+		case proto_node.CrossLink:
+			return "crosslink"
+		case proto_node.Receipt:
+			return "receipt"
+		case proto_node.SlashCandidate:
+			return "slash"
+		}
+	}
+	return "other"
+}
+
+// validateNodeMessage checks that the payload of a node message, starting
+// with its category, is well formed so that malformed messages are rejected
+// before their propagation. It returns true for the deprecated message types
+// to be ignored.
+func validateNodeMessage(payload []byte) (bool, error) {
+	if len(payload) < proto.MessageCategoryBytes+proto.MessageTypeBytes {
+		return false, errors.WithStack(errInvalidPayloadSize)
+	}
+	msgPayload := payload[proto.MessageCategoryBytes+proto.MessageTypeBytes:]
+	switch proto_node.MessageType(payload[proto.MessageCategoryBytes+proto.MessageTypeBytes-1]) {
+	case proto_node.Transaction:
+		return false, validateTransactionMessage(msgPayload, &types.Transactions{})
+	case proto_node.Staking:
+		txs := staking.StakingTransactions{}
+		if err := validateTransactionMessage(msgPayload, &txs); err != nil {
+			return false, err
+		}
+		// the staking transactions of the types the node does not accept
+		// are not relayed
+		if directives := nodeconfig.GetStakingDirectives(); directives != nil {
+			for _, tx := range txs {
+				if _, ok := directives[tx.StakingType()]; !ok {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case proto_node.Block:
+		if len(msgPayload) < 1 {
+			return false, errors.WithStack(errWrongBlockMsgSize)
+		}
+		switch proto_node.BlockMessageType(msgPayload[0]) {
+		case proto_node.Sync, proto_node.CrossLink, proto_node.Receipt, proto_node.SlashCandidate:
+			// the handlers decode the list, only check it spans the message
+			kind, _, rest, err := rlp.Split(msgPayload[1:])
+			if err != nil {
+				return false, errors.WithStack(err)
+			}
+			if kind != rlp.List || len(rest) != 0 {
+				return false, errors.WithStack(errMalformedNodeMessage)
+			}
+			return false, nil
+		}
+	case proto_node.Client, proto_node.PING, proto_node.ShardState:
+		return true, nil
+	}
+	return false, errors.WithStack(errUnknownNodeMessageType)
+}
+
+// validateTransactionMessage decodes the transactions of a transaction
+// message payload into txs
+func validateTransactionMessage(msgPayload []byte, txs interface{}) error {
+	if len(msgPayload) >= types.MaxEncodedPoolTransactionSize {
+		return errors.WithStack(core.ErrOversizedData)
+	}
+	if len(msgPayload) < 1 {
+		return errors.WithStack(errInvalidPayloadSize)
+	}
+	if proto_node.TransactionMessageType(msgPayload[0]) != proto_node.Send {
+		return errors.WithStack(errUnknownNodeMessageType)
+	}
+	return errors.WithStack(rlp.DecodeBytes(msgPayload[1:], txs))
+}
+
 var (
 	errMsgHadNoHMYPayLoadAssumption      = errors.New("did not have sufficient size for hmy msg")
 	errConsensusMessageOnUnexpectedTopic = errors.New("received consensus on wrong topic")
 	errConvertToValidMessage             = errors.New("convert p2p message to valid message")
 	errUnkonwnP2PMessageType             = errors.New("unknown p2p message type")
+	errUnknownNodeMessageType            = errors.New("unknown node message type")
+	errMalformedNodeMessage              = errors.New("malformed node message")
 )
 
 // Start kicks off the node message handling
@@ -486,6 +602,18 @@ func (node *Node) Start() error {
 			groups[t.tp] = t.isCon
 		}
 	}
+	// the topics shared with the hosting node are handled by the hosting node,
+	// a topic is subscribed once by the shared p2p host
+	if node.primary != nil {
+		delete(groups, nodeconfig.NewClientGroupIDByShardID(shard.BeaconChainShardID))
+		delete(groups, node.primary.NodeConfig.GetClientGroupID())
+	}
+	// the beacon chain nodes validate the cross-links before relaying them
+	crossLinkTopic := ""
+	if node.NodeConfig.ShardID == shard.BeaconChainShardID {
+		crossLinkTopic = string(nodeconfig.NewCrossLinkGroupID())
+		groups[nodeconfig.NewCrossLinkGroupID()] = false
+	}
 
 	type u struct {
 		p2p.NamedTopic
@@ -550,6 +678,13 @@ func (node *Node) Start() error {
 
 		topicNamed := allTopics[i].Name
 		isConsensusBound := allTopics[i].consensusBound
+		isCrossLinkBound := topicNamed == crossLinkTopic
+
+		// the cross-links are verified against their committee before being relayed
+		validatorTimeout := 50 * time.Millisecond
+		if isCrossLinkBound {
+			validatorTimeout = crossLinkValidationTimeout
+		}
 
 		utils.Logger().Info().
 			Str("topic", topicNamed).
@@ -559,19 +694,32 @@ func (node *Node) Start() error {
 		if err := pubsub.RegisterTopicValidator(
 			topicNamed,
 			// this is the validation function called to quickly validate every p2p message
-			func(ctx context.Context, peer libp2p_peer.ID, msg *libp2p_pubsub.Message) bool {
+			// rejected messages are not propagated and penalize the peer score of their sender,
+			// ignored messages are neither propagated nor penalized
+			func(ctx context.Context, peer libp2p_peer.ID, msg *libp2p_pubsub.Message) libp2p_pubsub.ValidationResult {
 				entryTime := time.Now()
 				defer utils.SampledLogger().Debug().Str("cost", time.Now().Sub(entryTime).String()).Msg("[cost:topic_validator]")
 
 				hmyMsg := msg.GetData()
 
 				// first to validate the size of the p2p message
-				if len(hmyMsg) < p2pMsgPrefixSize {
-					return true
+				if len(hmyMsg) < p2pMsgPrefixSize+proto.MessageCategoryBytes {
+					errChan <- withError{
+						errors.WithStack(errMsgHadNoHMYPayLoadAssumption),
+						msg.GetFrom(),
+					}
+					node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+					return libp2p_pubsub.ValidationReject
 				}
 
+				p2p.CountReceivedMessage(topicNamed, len(hmyMsg))
 				openBox := hmyMsg[p2pMsgPrefixSize:]
 
+				// drop the messages over the rate limits before validating them
+				if !node.host.AllowMessage(messageKind(openBox), peer) {
+					return libp2p_pubsub.ValidationIgnore
+				}
+
 				// validate message category
 				switch proto.MessageCategory(openBox[proto.MessageCategoryBytes-1]) {
 				case proto.Consensus:
@@ -582,24 +730,45 @@ func (node *Node) Start() error {
 							errors.WithStack(errConsensusMessageOnUnexpectedTopic),
 							msg.GetFrom(),
 						}
-						return false
+						node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+						return libp2p_pubsub.ValidationReject
 					}
 
-					// validate consensus message
-					validMsg, senderPubKey, ignore, err := node.validateShardBoundMessage(
-						ctx, openBox[proto.MessageCategoryBytes:],
+					// validate consensus message on the validation workers of the topic
+					var (
+						validMsg     *msg_pb.Message
+						senderPubKey *bls.PublicKey
+						ignore       bool
+						err          error
 					)
+					if result := node.host.ValidateMessage(ctx, topicNamed, func(ctx context.Context) libp2p_pubsub.ValidationResult {
+						validMsg, senderPubKey, ignore, err = node.validateShardBoundMessage(
+							ctx, openBox[proto.MessageCategoryBytes:],
+						)
+						return libp2p_pubsub.ValidationAccept
+					}); result != libp2p_pubsub.ValidationAccept {
+						return result
+					}
 
 					if err != nil {
 						errChan <- withError{err,
 							msg.GetFrom(),
 						}
-						return false
+						// the committee of the sender may not be known yet at an epoch change
+						if errors.Is(err, shard.ErrValidNotInCommittee) {
+							return libp2p_pubsub.ValidationIgnore
+						}
+						if errors.Is(err, errNoSenderPubKey) || errors.Is(err, errNotRightKeySize) {
+							node.host.ReportPeer(peer, p2p.OffenseInvalidSender)
+						} else {
+							node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+						}
+						return libp2p_pubsub.ValidationReject
 					}
 
 					// ignore the further processing of the p2p messages as it is not intended for this node
 					if ignore {
-						return true
+						return libp2p_pubsub.ValidationAccept
 					}
 
 					msg.ValidatorData = validated{
@@ -608,10 +777,34 @@ func (node *Node) Start() error {
 						handleCArg:     validMsg,
 						senderPubKey:   senderPubKey,
 					}
-					return true
 
 				case proto.Node:
-					// TODO push the message parsing here, so can ban
+					ignore, err := validateNodeMessage(openBox)
+					if err != nil {
+						errChan <- withError{err, msg.GetFrom()}
+						node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+						return libp2p_pubsub.ValidationReject
+					}
+					if ignore {
+						return libp2p_pubsub.ValidationIgnore
+					}
+					if isCrossLinkBound {
+						var err error
+						result := node.host.ValidateMessage(ctx, topicNamed, func(ctx context.Context) libp2p_pubsub.ValidationResult {
+							var result libp2p_pubsub.ValidationResult
+							result, err = node.validateCrossLinkMessage(openBox)
+							return result
+						})
+						if err != nil {
+							errChan <- withError{err, msg.GetFrom()}
+						}
+						if result == libp2p_pubsub.ValidationReject {
+							node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+						}
+						if result != libp2p_pubsub.ValidationAccept {
+							return result
+						}
+					}
 					msg.ValidatorData = validated{
 						consensusBound: false,
 						handleE:        node.HandleNodeMessage,
@@ -622,7 +815,8 @@ func (node *Node) Start() error {
 						errors.WithStack(errUnkonwnP2PMessageType),
 						proto.MessageCategory(openBox[proto.MessageCategoryBytes-1]),
 					}
-					return false
+					node.host.ReportPeer(peer, p2p.OffenseInvalidMessage)
+					return libp2p_pubsub.ValidationReject
 				}
 
 				select {
@@ -633,13 +827,13 @@ func (node *Node) Start() error {
 					}
 					errChan <- withError{errors.WithStack(ctx.Err()), msg}
 				default:
-					return true
+					return libp2p_pubsub.ValidationAccept
 				}
 
-				return false
+				return libp2p_pubsub.ValidationIgnore
 			},
 			// WithValidatorTimeout is an option that sets a timeout for an (asynchronous) topic validator. By default there is no timeout in asynchronous validators.
-			libp2p_pubsub.WithValidatorTimeout(50*time.Millisecond),
+			libp2p_pubsub.WithValidatorTimeout(validatorTimeout),
 			// WithValidatorConcurrency set the concurernt validator, default is 1024
 			libp2p_pubsub.WithValidatorConcurrency(p2p.SetAsideForConsensus),
 		); err != nil {
@@ -746,6 +940,19 @@ func New(
 	chainDBFactory shardchain.DBFactory,
 	blacklist map[common.Address]struct{},
 	isArchival bool,
+) *Node {
+	return newNode(host, consensusObj, chainDBFactory, nil, blacklist, isArchival)
+}
+
+// newNode creates a new node, whose chains are opened by the given
+// collection if any, or by a new collection of chainDBFactory
+func newNode(
+	host p2p.Host,
+	consensusObj *consensus.Consensus,
+	chainDBFactory shardchain.DBFactory,
+	shardChains shardchain.Collection,
+	blacklist map[common.Address]struct{},
+	isArchival bool,
 ) *Node {
 	node := Node{}
 	node.unixTimeAtNodeStart = time.Now().Unix()
@@ -767,13 +974,21 @@ func New(
 	chainConfig := networkType.ChainConfig()
 	node.chainConfig = chainConfig
 
-	collection := shardchain.NewCollection(
-		chainDBFactory, &genesisInitializer{&node}, chain.Engine, &chainConfig,
-	)
-	if isArchival {
-		collection.DisableCache()
+	if shardChains != nil {
+		node.shardChains = shardChains
+	} else {
+		collection := shardchain.NewCollection(
+			chainDBFactory, &genesisInitializer{&node}, chain.Engine, &chainConfig,
+		)
+		if isArchival {
+			collection.DisableCache()
+		}
+		if depth := nodeconfig.GetDBRepairDepth(); depth > 0 {
+			collection.EnableRepair(depth)
+		}
+		collection.SetForkRetention(nodeconfig.GetForkRetention())
+		node.shardChains = collection
 	}
-	node.shardChains = collection
 
 	if host != nil && consensusObj != nil {
 		// Consensus and associated channel to communicate blocks
@@ -801,6 +1016,7 @@ func New(
 		node.BeaconBlockChannel = make(chan *types.Block)
 		txPoolConfig := core.DefaultTxPoolConfig
 		txPoolConfig.Blacklist = blacklist
+		txPoolConfig.StakingDirectives = nodeconfig.GetStakingDirectives()
 		node.TxPool = core.NewTxPool(txPoolConfig, node.Blockchain().Config(), blockchain, node.TransactionErrorSink)
 		node.CxPool = core.NewCxPool(core.CxPoolSize)
 		node.Worker = worker.New(node.Blockchain().Config(), blockchain, chain.Engine)
@@ -865,6 +1081,9 @@ func New(
 			}
 		}()
 	}
+	if node.NodeConfig.ShardID == shard.BeaconChainShardID {
+		metrics.DefaultRegistry.OnCollect(node.collectEconomicsMetrics)
+	}
 	go func() {
 		ticker := time.NewTicker(time.Minute)
 		defer ticker.Stop()
@@ -1007,9 +1226,17 @@ func (node *Node) ServiceManager() *service.Manager {
 }
 
 // ShutDown gracefully shut down the node server and dump the in-memory blockchain state into DB.
-func (node *Node) ShutDown() {
-	node.Blockchain().Stop()
-	node.Beaconchain().Stop()
+func (node *Node) ShutDown(leaveTimeout time.Duration) {
+	node.StopRPC()
+	if node.NodeConfig.Role() == nodeconfig.Validator {
+		node.Consensus.Leave(leaveTimeout)
+		node.serviceManager.StopService(service.Consensus)
+	}
+	node.releaseSigningLease()
+	// the chains of the hosted nodes are in the shared collection
+	if err := node.shardChains.Close(); err != nil {
+		utils.Logger().Error().Err(err).Msg("cannot close the chain databases")
+	}
 	const msg = "Successfully shut down!\n"
 	utils.Logger().Print(msg)
 	fmt.Print(msg)
@@ -0,0 +1,49 @@
+package node
+
+import (
+	"github.com/harmony-one/harmony/api/service/beaconlight"
+	"github.com/harmony-one/harmony/core"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// newBeaconLightService returns the service serving the epoch transitions
+// of the beacon chain on beacon nodes, and following them on the shard nodes
+// running the beacon light client, nil for the other nodes
+func (node *Node) newBeaconLightService() *beaconlight.Service {
+	if node.NodeConfig.ShardID == shard.BeaconChainShardID {
+		return beaconlight.New(node.host, nil, node.serveEpochTransitions, nil)
+	}
+	if !nodeconfig.GetBeaconLight() {
+		return nil
+	}
+	beacon := node.Beaconchain()
+	return beaconlight.New(
+		node.host, beacon.BeaconLightEpoch, nil,
+		func(transitions []*core.EpochTransition) error {
+			_, err := beacon.InsertEpochTransitions(transitions)
+			return err
+		},
+	)
+}
+
+// serveEpochTransitions returns the epoch transitions of the request from
+// the beacon chain, up to the last finished epoch
+func (node *Node) serveEpochTransitions(
+	request beaconlight.Request,
+) ([]*core.EpochTransition, error) {
+	chain := node.Blockchain()
+	transitions := []*core.EpochTransition{}
+	for epoch := request.Epoch; epoch < request.Epoch+request.Count; epoch++ {
+		transition, err := chain.ReadEpochTransition(epoch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read epoch transition of epoch %d", epoch)
+		}
+		if transition == nil {
+			break
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, nil
+}
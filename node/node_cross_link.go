@@ -8,12 +8,16 @@ import (
 	common2 "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/api/proto"
+	"github.com/harmony-one/harmony/api/service/crosslink"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/metrics"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/multibls"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/verify"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/singleflight"
 )
@@ -21,12 +25,22 @@ import (
 const (
 	maxPendingCrossLinkSize = 1000
 	crossLinkBatchSize      = 3
+	// time to verify the signatures of a cross-link message before relaying it
+	crossLinkValidationTimeout = time.Second
 )
 
 var (
-	errAlreadyExist = errors.New("crosslink already exist")
-	deciderCache    singleflight.Group
-	committeeCache  singleflight.Group
+	errAlreadyExist              = errors.New("crosslink already exist")
+	errNotCrossLinkMessage       = errors.New("not a cross-link message on the cross-link topic")
+	errCrossLinkMessageBatchSize = errors.New("too many cross-links in the message")
+	deciderCache                 singleflight.Group
+	committeeCache               singleflight.Group
+)
+
+// crossLinkMessagesCounter counts the messages of the cross-link topic by
+// their validation result
+var crossLinkMessagesCounter = metrics.DefaultRegistry.NewCounter(
+	"node_crosslink_messages_total", "Messages of the cross-link topic by validation result", "result",
 )
 
 // VerifyBlockCrossLinks verifies the cross links of the block
@@ -37,28 +51,27 @@ func (node *Node) VerifyBlockCrossLinks(block *types.Block) error {
 		return nil
 	}
 
-	crossLinks := &types.CrossLinks{}
-	err := rlp.DecodeBytes(cxLinksData, crossLinks)
-	if err != nil {
-		return errors.Wrapf(
-			err, "[CrossLinkVerification] failed to decode cross links",
-		)
-	}
-
-	if !crossLinks.IsSorted() {
-		return errors.New("[CrossLinkVerification] cross links are not sorted")
-	}
-
-	for _, crossLink := range *crossLinks {
+	// the cross links are verified as they are decoded
+	var verifyErr error
+	err := types.StreamCrossLinks(cxLinksData, true, func(_ int, crossLink *types.CrossLink) error {
 		cl, err := node.Blockchain().ReadCrossLink(crossLink.ShardID(), crossLink.BlockNum())
 		if err == nil && cl != nil {
 			// Add slash for exist same blocknum but different crosslink
-			return errAlreadyExist
-		}
-		if err := node.VerifyCrossLink(crossLink); err != nil {
-			return errors.Wrapf(err, "cannot VerifyBlockCrossLinks")
-
+			verifyErr = errAlreadyExist
+		} else if err := node.VerifyCrossLink(*crossLink); err != nil {
+			verifyErr = errors.Wrapf(err, "cannot VerifyBlockCrossLinks")
 		}
+		return verifyErr
+	})
+	switch {
+	case verifyErr != nil:
+		return verifyErr
+	case err == types.ErrCrossLinksNotSorted:
+		return errors.New("[CrossLinkVerification] cross links are not sorted")
+	case err != nil:
+		return errors.Wrapf(
+			err, "[CrossLinkVerification] failed to decode cross links",
+		)
 	}
 	return nil
 }
@@ -66,18 +79,6 @@ func (node *Node) VerifyBlockCrossLinks(block *types.Block) error {
 // ProcessCrossLinkMessage verify and process Node/CrossLink message into crosslink when it's valid
 func (node *Node) ProcessCrossLinkMessage(msgPayload []byte) {
 	if node.NodeConfig.ShardID == shard.BeaconChainShardID {
-		pendingCLs, err := node.Blockchain().ReadPendingCrossLinks()
-		if err == nil && len(pendingCLs) >= maxPendingCrossLinkSize {
-			utils.Logger().Debug().
-				Msgf("[ProcessingCrossLink] Pending Crosslink reach maximum size: %d", len(pendingCLs))
-			return
-		}
-
-		existingCLs := map[common2.Hash]struct{}{}
-		for _, pending := range pendingCLs {
-			existingCLs[pending.Hash()] = struct{}{}
-		}
-
 		crosslinks := []types.CrossLink{}
 		if err := rlp.DecodeBytes(msgPayload, &crosslinks); err != nil {
 			utils.Logger().Error().
@@ -85,47 +86,120 @@ func (node *Node) ProcessCrossLinkMessage(msgPayload []byte) {
 				Msg("[ProcessingCrossLink] Crosslink Message Broadcast Unable to Decode")
 			return
 		}
+		// A sanity check to prevent spamming
+		node.addPendingCrossLinks(crosslinks, crossLinkBatchSize*2+1)
+	}
+}
 
-		candidates := []types.CrossLink{}
+// addPendingCrossLinks verifies the first max crosslinks, and adds the valid
+// ones to the pending crosslinks. The crosslinks already pending or committed
+// are skipped. It returns the number of pending crosslinks.
+func (node *Node) addPendingCrossLinks(crosslinks []types.CrossLink, max int) int {
+	pendingCLs, err := node.Blockchain().ReadPendingCrossLinks()
+	if err == nil && len(pendingCLs) >= maxPendingCrossLinkSize {
 		utils.Logger().Debug().
-			Msgf("[ProcessingCrossLink] Received crosslinks: %d", len(crosslinks))
+			Msgf("[ProcessingCrossLink] Pending Crosslink reach maximum size: %d", len(pendingCLs))
+		return len(pendingCLs)
+	}
 
-		for i, cl := range crosslinks {
-			if i > crossLinkBatchSize*2 { // A sanity check to prevent spamming
-				break
-			}
+	existingCLs := map[common2.Hash]struct{}{}
+	for _, pending := range pendingCLs {
+		existingCLs[pending.Hash()] = struct{}{}
+	}
 
-			if _, ok := existingCLs[cl.Hash()]; ok {
-				utils.Logger().Debug().Err(err).
-					Msgf("[ProcessingCrossLink] Cross Link already exists in pending queue, pass. Beacon Epoch: %d, Block num: %d, Epoch: %d, shardID %d",
-						node.Blockchain().CurrentHeader().Epoch(), cl.Number(), cl.Epoch(), cl.ShardID())
-				continue
-			}
+	candidates := []types.CrossLink{}
+	utils.Logger().Debug().
+		Msgf("[ProcessingCrossLink] Received crosslinks: %d", len(crosslinks))
 
-			exist, err := node.Blockchain().ReadCrossLink(cl.ShardID(), cl.Number().Uint64())
-			if err == nil && exist != nil {
-				utils.Logger().Debug().Err(err).
-					Msgf("[ProcessingCrossLink] Cross Link already exists, pass. Beacon Epoch: %d, Block num: %d, Epoch: %d, shardID %d", node.Blockchain().CurrentHeader().Epoch(), cl.Number(), cl.Epoch(), cl.ShardID())
-				continue
-			}
+	for i, cl := range crosslinks {
+		if i >= max {
+			break
+		}
 
-			if err = node.VerifyCrossLink(cl); err != nil {
-				utils.Logger().Info().
-					Str("cross-link-issue", err.Error()).
-					Msgf("[ProcessingCrossLink] Failed to verify new cross link for blockNum %d epochNum %d shard %d skipped: %v", cl.BlockNum(), cl.Epoch().Uint64(), cl.ShardID(), cl)
-				continue
-			}
+		if _, ok := existingCLs[cl.Hash()]; ok {
+			utils.Logger().Debug().Err(err).
+				Msgf("[ProcessingCrossLink] Cross Link already exists in pending queue, pass. Beacon Epoch: %d, Block num: %d, Epoch: %d, shardID %d",
+					node.Blockchain().CurrentHeader().Epoch(), cl.Number(), cl.Epoch(), cl.ShardID())
+			continue
+		}
+
+		exist, err := node.Blockchain().ReadCrossLink(cl.ShardID(), cl.Number().Uint64())
+		if err == nil && exist != nil {
+			utils.Logger().Debug().Err(err).
+				Msgf("[ProcessingCrossLink] Cross Link already exists, pass. Beacon Epoch: %d, Block num: %d, Epoch: %d, shardID %d", node.Blockchain().CurrentHeader().Epoch(), cl.Number(), cl.Epoch(), cl.ShardID())
+			continue
+		}
 
-			candidates = append(candidates, cl)
-			utils.Logger().Debug().
-				Msgf("[ProcessingCrossLink] Committing for shardID %d, blockNum %d",
-					cl.ShardID(), cl.Number().Uint64(),
-				)
+		if err = node.VerifyCrossLink(cl); err != nil {
+			utils.Logger().Info().
+				Str("cross-link-issue", err.Error()).
+				Msgf("[ProcessingCrossLink] Failed to verify new cross link for blockNum %d epochNum %d shard %d skipped: %v", cl.BlockNum(), cl.Epoch().Uint64(), cl.ShardID(), cl)
+			continue
 		}
-		Len, _ := node.Blockchain().AddPendingCrossLinks(candidates)
+
+		candidates = append(candidates, cl)
 		utils.Logger().Debug().
-			Msgf("[ProcessingCrossLink] Add pending crosslinks,  total pending: %d", Len)
+			Msgf("[ProcessingCrossLink] Committing for shardID %d, blockNum %d",
+				cl.ShardID(), cl.Number().Uint64(),
+			)
 	}
+	Len, _ := node.Blockchain().AddPendingCrossLinks(candidates)
+	utils.Logger().Debug().
+		Msgf("[ProcessingCrossLink] Add pending crosslinks,  total pending: %d", Len)
+	return Len
+}
+
+// validateCrossLinkMessage verifies the cross-links of a message of the
+// cross-link topic, starting with its category, before the message is
+// relayed. The cross-links already committed are not verified again. The
+// messages with the cross-links of an epoch whose committee is not known yet
+// are ignored rather than rejected, as the node may be behind the sender.
+func (node *Node) validateCrossLinkMessage(
+	payload []byte,
+) (result libp2p_pubsub.ValidationResult, err error) {
+	defer func() {
+		switch result {
+		case libp2p_pubsub.ValidationAccept:
+			crossLinkMessagesCounter.Inc("accepted")
+		case libp2p_pubsub.ValidationReject:
+			crossLinkMessagesCounter.Inc("rejected")
+		default:
+			crossLinkMessagesCounter.Inc("ignored")
+		}
+	}()
+
+	if messageKind(payload) != "crosslink" {
+		return libp2p_pubsub.ValidationReject, errors.WithStack(errNotCrossLinkMessage)
+	}
+	crossLinks := []types.CrossLink{}
+	if err := rlp.DecodeBytes(
+		payload[proto.MessageCategoryBytes+proto.MessageTypeBytes+1:], &crossLinks,
+	); err != nil {
+		return libp2p_pubsub.ValidationReject, errors.WithStack(err)
+	}
+	if len(crossLinks) > crossLinkBatchSize*2 {
+		return libp2p_pubsub.ValidationReject, errors.WithStack(errCrossLinkMessageBatchSize)
+	}
+
+	verified := 0
+	for _, cl := range crossLinks {
+		exist, err := node.Blockchain().ReadCrossLink(cl.ShardID(), cl.BlockNum())
+		if err == nil && exist != nil {
+			continue
+		}
+		if _, err := node.lookupCommittee(cl.Epoch(), cl.ShardID()); err != nil {
+			return libp2p_pubsub.ValidationIgnore, err
+		}
+		if err := node.VerifyCrossLink(cl); err != nil {
+			return libp2p_pubsub.ValidationReject, err
+		}
+		verified++
+	}
+	// no need to relay the cross-links already committed
+	if verified == 0 {
+		return libp2p_pubsub.ValidationIgnore, nil
+	}
+	return libp2p_pubsub.ValidationAccept, nil
 }
 
 // VerifyCrossLink verifies the header is valid
@@ -237,3 +311,74 @@ func (node *Node) lookupCommittee(
 
 	return result.(*shard.Committee), nil
 }
+
+// newCrossLinkRequestService returns the service requesting the missing
+// crosslinks on the beacon chain, and serving the crosslinks of the shard
+// chain to the beacon chain otherwise
+func (node *Node) newCrossLinkRequestService() *crosslink.Service {
+	deliver := func(crossLinks []types.CrossLink) {
+		node.addPendingCrossLinks(crossLinks, crosslink.MaxCrossLinks)
+	}
+	if node.NodeConfig.ShardID == shard.BeaconChainShardID {
+		return crosslink.New(node.host, node.crossLinkGaps, nil, deliver)
+	}
+	return crosslink.New(node.host, nil, node.serveCrossLinks, deliver)
+}
+
+// crossLinkGaps returns the first block of each shard after the last
+// continuous crosslink, unless its crosslink is already pending
+func (node *Node) crossLinkGaps() []crosslink.Gap {
+	chain := node.Blockchain()
+	pendingCLs, err := chain.ReadPendingCrossLinks()
+	if err != nil {
+		pendingCLs = nil
+	}
+	gaps := []crosslink.Gap{}
+	for shardID := uint32(1); shardID < shard.CrossLinkShards(chain.CurrentHeader().Epoch()); shardID++ {
+		last, err := chain.ReadShardLastCrossLink(shardID)
+		if err != nil || last == nil {
+			continue
+		}
+		gap := crosslink.Gap{ShardID: shardID, From: last.BlockNum() + 1}
+		pending := false
+		for _, cl := range pendingCLs {
+			if cl.ShardID() != shardID {
+				continue
+			}
+			if cl.BlockNum() == gap.From {
+				pending = true
+			} else if cl.BlockNum() > gap.From {
+				gap.Hole = true
+			}
+		}
+		if !pending {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
+
+// serveCrossLinks returns the crosslinks of the blocks of the request from
+// the shard chain, up to the parent of the current block
+func (node *Node) serveCrossLinks(request crosslink.Request) ([]types.CrossLink, error) {
+	chain := node.Blockchain()
+	if request.ShardID != chain.ShardID() {
+		return nil, errors.Errorf("shard %d not served", request.ShardID)
+	}
+	crossLinks := []types.CrossLink{}
+	for number := request.From; number < request.From+request.Count; number++ {
+		header := chain.GetHeaderByNumber(number + 1)
+		if header == nil {
+			break
+		}
+		if number <= 1 || !chain.Config().IsCrossLink(header.Epoch()) {
+			continue
+		}
+		parentHeader := chain.GetHeaderByHash(header.ParentHash())
+		if parentHeader == nil {
+			break
+		}
+		crossLinks = append(crossLinks, *types.NewCrossLink(header, parentHeader))
+	}
+	return crossLinks, nil
+}
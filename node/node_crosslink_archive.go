@@ -0,0 +1,49 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// crossLinkArchiveInterval is the interval of the archiving of the old
+// crosslinks of the beacon chain
+const crossLinkArchiveInterval = 10 * time.Minute
+
+// StartCrossLinkArchiver opens the crosslink archive of the beacon chain, and
+// moves the crosslinks older than the last keepEpochs epochs to it every
+// crossLinkArchiveInterval. With keepEpochs 0 nothing is archived, but the
+// existing archive is still opened so that its crosslinks stay readable.
+func (node *Node) StartCrossLinkArchiver(keepEpochs uint64) error {
+	dir := path.Join(
+		node.NodeConfig.DBDir, fmt.Sprintf("harmony_db_%d_crosslinks", shard.BeaconChainShardID),
+	)
+	if keepEpochs == 0 {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	db, err := ethdb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		return errors.Wrap(err, "cannot open crosslink archive")
+	}
+	chain := node.Beaconchain()
+	chain.SetCrossLinkArchive(db)
+	if keepEpochs == 0 {
+		return nil
+	}
+	go func() {
+		for range time.Tick(crossLinkArchiveInterval) {
+			if _, err := chain.ArchiveCrossLinks(keepEpochs); err != nil {
+				utils.Logger().Warn().Err(err).Msg("[CrossLinkArchive] cannot archive crosslinks")
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,131 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/api/service/explorer"
+	"github.com/harmony-one/harmony/core"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/diskmon"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/webhooks"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// indexPause tracks the pause of the explorer index, whose skipped blocks are
+// indexed when it resumes
+type indexPause struct {
+	sync.Mutex
+	paused bool
+	from   uint64 // first block not indexed
+}
+
+// skip returns whether the block is not indexed as the index is paused
+func (p *indexPause) skip(blockNum uint64) bool {
+	p.Lock()
+	defer p.Unlock()
+	if p.paused && (p.from == 0 || blockNum < p.from) {
+		p.from = blockNum
+	}
+	return p.paused
+}
+
+// StartDiskMonitor monitors the free space of the partition of the databases
+// every interval, and mitigates the thresholds the free space falls below.
+// The prune mitigation keeps the bodies and receipts of the last keepBlocks
+// blocks.
+func (node *Node) StartDiskMonitor(
+	thresholds []diskmon.Threshold, interval time.Duration, keepBlocks uint64,
+) {
+	mitigate := func(action diskmon.Action, t diskmon.Threshold, free uint64) error {
+		return node.mitigateLowDisk(action, t, free, keepBlocks)
+	}
+	monitor := diskmon.New(node.NodeConfig.DBDir, thresholds, mitigate, node.recoverDiskSpace)
+	go monitor.Run(interval)
+}
+
+// chains returns the chains open in the process of the node
+func (node *Node) chains() []*core.BlockChain {
+	chains := []*core.BlockChain{}
+	open := map[uint32]bool{}
+	for _, n := range append([]*Node{node}, node.hostedShards...) {
+		for _, chain := range []*core.BlockChain{n.Blockchain(), n.Beaconchain()} {
+			if !open[chain.ShardID()] {
+				open[chain.ShardID()] = true
+				chains = append(chains, chain)
+			}
+		}
+	}
+	return chains
+}
+
+func (node *Node) mitigateLowDisk(
+	action diskmon.Action, t diskmon.Threshold, free, keepBlocks uint64,
+) error {
+	switch action {
+	case diskmon.Compact:
+		for _, chain := range node.chains() {
+			if db, ok := chain.ChainDb().(*ethdb.LDBDatabase); ok {
+				if err := db.LDB().CompactRange(util.Range{}); err != nil {
+					return errors.Wrapf(err, "cannot compact the database of shard %d", chain.ShardID())
+				}
+			}
+		}
+		if node.NodeConfig.Role() == nodeconfig.ExplorerNode {
+			storage := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port)
+			if err := storage.Backend().Compact(); err != nil {
+				return errors.Wrap(err, "cannot compact the explorer database")
+			}
+		}
+	case diskmon.Prune:
+		for _, chain := range node.chains() {
+			if _, err := chain.PruneAncientBlocks(keepBlocks); err != nil {
+				return errors.Wrapf(err, "cannot prune the blocks of shard %d", chain.ShardID())
+			}
+		}
+	case diskmon.PauseIndexes:
+		node.indexPause.Lock()
+		node.indexPause.paused = true
+		node.indexPause.Unlock()
+		utils.Logger().Warn().Msg("[DiskMonitor] explorer index paused")
+	case diskmon.Webhook:
+		if hooks := node.NodeConfig.WebHooks.Hooks; hooks != nil && hooks.Disk != nil {
+			node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+				hooks.Disk.OnLowDiskSpace, webhooks.EventLowDiskSpace, map[string]interface{}{
+					"db-dir":          node.NodeConfig.DBDir,
+					"free-bytes":      free,
+					"threshold-bytes": t.Free,
+				},
+			)
+		}
+	}
+	return nil
+}
+
+// recoverDiskSpace resumes the explorer index paused by the threshold, and
+// indexes the blocks skipped while it was paused
+func (node *Node) recoverDiskSpace(action diskmon.Action, t diskmon.Threshold, free uint64) {
+	if action != diskmon.PauseIndexes {
+		return
+	}
+	node.indexPause.Lock()
+	from := node.indexPause.from
+	node.indexPause.paused, node.indexPause.from = false, 0
+	node.indexPause.Unlock()
+	utils.Logger().Info().Uint64("from", from).Msg("[DiskMonitor] explorer index resumed")
+	if from == 0 {
+		return
+	}
+	go func() {
+		storage := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port)
+		for num := from; num <= node.Blockchain().CurrentBlock().NumberU64(); num++ {
+			if err := storage.Dump(node.Blockchain().GetBlockByNumber(num)); err != nil {
+				utils.Logger().Error().Err(err).Msg("[DiskMonitor] cannot index the blocks of the pause")
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,71 @@
+package node
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/harmony-one/harmony/common/denominations"
+	"github.com/harmony-one/harmony/internal/chain"
+	"github.com/harmony-one/harmony/internal/metrics"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/staking/network"
+)
+
+// gauges of the economics of the network, exported by the beacon chain nodes
+// and computed once per epoch
+var (
+	economicsEpochGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_economics_epoch", "Epoch of the beacon chain the staking economics gauges are computed at",
+	)
+	totalStakedGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_total_staked_one", "Total delegation of the elected validators, in ONE",
+	)
+	medianEffectiveStakeGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_median_effective_stake_one", "Median effective stake of the slots of the elected validators, in ONE",
+	)
+	circulatingSupplyGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_circulating_supply_one", "Circulating supply of the network, in ONE",
+	)
+	blockRewardGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_block_reward_one", "Reward issued for each block height across the shards, in ONE",
+	)
+	electedValidatorsGauge = metrics.DefaultRegistry.NewGauge(
+		"staking_elected_validators", "Number of the elected validators",
+	)
+)
+
+// collectEconomicsMetrics updates the gauges of the economics of the network
+// when the beacon chain enters a new epoch, so the heavy computation is done
+// once per epoch however often the metrics are scraped
+func (node *Node) collectEconomicsMetrics() {
+	node.economicsLock.Lock()
+	defer node.economicsLock.Unlock()
+	beaconchain := node.Beaconchain()
+	epoch := beaconchain.CurrentHeader().Epoch()
+	if node.economicsEpoch != nil && node.economicsEpoch.Cmp(epoch) == 0 {
+		return
+	}
+	snapshot, err := network.NewEconomicsSnapshot(
+		beaconchain, chain.NetworkBlockReward(beaconchain, epoch),
+	)
+	if err != nil {
+		utils.Logger().Warn().Err(err).
+			Uint64("epoch", epoch.Uint64()).
+			Msg("[collectEconomicsMetrics] cannot compute the staking economics")
+		return
+	}
+	economicsEpochGauge.Set(float64(snapshot.Epoch.Uint64()))
+	totalStakedGauge.Set(inONE(snapshot.TotalStaked))
+	medianEffectiveStakeGauge.Set(inONE(snapshot.MedianEffectiveStake))
+	circulatingSupplyGauge.Set(inONE(snapshot.CirculatingSupply))
+	blockRewardGauge.Set(inONE(snapshot.BlockReward))
+	electedValidatorsGauge.Set(float64(snapshot.ElectedValidators))
+	node.economicsEpoch = new(big.Int).Set(epoch)
+}
+
+// inONE returns the amount in atto as a number of ONE
+func inONE(amount numeric.Dec) float64 {
+	one, _ := strconv.ParseFloat(amount.QuoInt64(denominations.One).String(), 64)
+	return one
+}
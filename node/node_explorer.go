@@ -2,7 +2,6 @@ package node
 
 import (
 	"context"
-	"sort"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,6 +11,7 @@ import (
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/signature"
 	"github.com/harmony-one/harmony/core/types"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/pkg/errors"
 )
@@ -128,19 +128,22 @@ func (node *Node) AddNewBlockForExplorer(block *types.Block) {
 		}
 		// Clean up the blocks to avoid OOM.
 		node.Consensus.FBFTLog.DeleteBlockByNumber(block.NumberU64())
-		// Do dump all blocks from state syncing for explorer one time
-		// TODO: some blocks can be dumped before state syncing finished.
-		// And they would be dumped again here. Please fix it.
-		once.Do(func() {
-			utils.Logger().Info().Int64("starting height", int64(block.NumberU64())-1).
-				Msg("[Explorer] Populating explorer data from state synced blocks")
-			go func() {
-				for blockHeight := int64(block.NumberU64()) - 1; blockHeight >= 0; blockHeight-- {
-					explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, true).Dump(
-						node.Blockchain().GetBlockByNumber(uint64(blockHeight)), uint64(blockHeight))
-				}
-			}()
-		})
+		// The explorer storage only indexes the shard of the hosting node
+		if node.primary != nil {
+			return
+		}
+		// Backfill the blocks not indexed yet, from state syncing or before a
+		// restart, one time. The blocks dumped meanwhile are rewritten as is.
+		if nodeconfig.GetExplorerDBConfig().Backfill {
+			once.Do(func() {
+				go func() {
+					storage := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port)
+					if err := storage.Backfill(node.Blockchain()); err != nil {
+						utils.Logger().Error().Err(err).Msg("[Explorer] Backfill failed")
+					}
+				}()
+			})
+		}
 	} else {
 		utils.Logger().Error().Err(err).Msg("[Explorer] Error when adding new block for explorer node")
 	}
@@ -151,9 +154,13 @@ func (node *Node) commitBlockForExplorer(block *types.Block) {
 	if block.ShardID() != node.NodeConfig.ShardID {
 		return
 	}
-	// Dump new block into level db.
-	utils.Logger().Info().Uint64("blockNum", block.NumberU64()).Msg("[Explorer] Committing block into explorer DB")
-	explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, true).Dump(block, block.NumberU64())
+	if node.primary == nil && !node.indexPause.skip(block.NumberU64()) {
+		// Dump new block into level db.
+		utils.Logger().Info().Uint64("blockNum", block.NumberU64()).Msg("[Explorer] Committing block into explorer DB")
+		if err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port).Dump(block); err != nil {
+			utils.Logger().Error().Err(err).Msg("[Explorer] Error when dumping block into explorer DB")
+		}
+	}
 
 	curNum := block.NumberU64()
 	if curNum-100 > 0 {
@@ -164,65 +171,28 @@ func (node *Node) commitBlockForExplorer(block *types.Block) {
 
 // GetTransactionsHistory returns list of transactions hashes of address.
 func (node *Node) GetTransactionsHistory(address, txType, order string) ([]common.Hash, error) {
-	addressData := &explorer.Address{}
-	key := explorer.GetAddressKey(address)
-	bytes, err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, false).GetDB().Get([]byte(key), nil)
-	if err != nil {
-		utils.Logger().Debug().Err(err).
-			Msgf("[Explorer] Error retrieving transaction history for address %s", address)
-		return make([]common.Hash, 0), nil
-	}
-	if err = rlp.DecodeBytes(bytes, &addressData); err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot convert address data from DB")
-		return nil, err
-	}
-	if order == "DESC" {
-		sort.Slice(addressData.TXs[:], func(i, j int) bool {
-			return addressData.TXs[i].Timestamp > addressData.TXs[j].Timestamp
-		})
-	} else {
-		sort.Slice(addressData.TXs[:], func(i, j int) bool {
-			return addressData.TXs[i].Timestamp < addressData.TXs[j].Timestamp
-		})
-	}
-	hashes := make([]common.Hash, 0)
-	for _, tx := range addressData.TXs {
-		if txType == "" || txType == "ALL" || txType == tx.Type {
-			hash := common.HexToHash(tx.Hash)
-			hashes = append(hashes, hash)
-		}
-	}
-	return hashes, nil
+	return node.addressTransactionsHistory(address, txType, order, false)
 }
 
 // GetStakingTransactionsHistory returns list of staking transactions hashes of address.
 func (node *Node) GetStakingTransactionsHistory(address, txType, order string) ([]common.Hash, error) {
-	addressData := &explorer.Address{}
-	key := explorer.GetAddressKey(address)
-	bytes, err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, false).GetDB().Get([]byte(key), nil)
+	return node.addressTransactionsHistory(address, txType, order, true)
+}
+
+func (node *Node) addressTransactionsHistory(
+	address, txType, order string, staking bool,
+) ([]common.Hash, error) {
+	records, err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port).Backend().
+		AddressTransactions(address, staking, order == "DESC", 0, 0)
 	if err != nil {
-		utils.Logger().Debug().Err(err).
-			Msgf("[Explorer] Staking transaction history for address %s not found", address)
-		return make([]common.Hash, 0), nil
-	}
-	if err = rlp.DecodeBytes(bytes, &addressData); err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot convert address data from DB")
+		utils.Logger().Error().Err(err).
+			Msgf("[Explorer] Error retrieving transaction history for address %s", address)
 		return nil, err
 	}
-	if order == "DESC" {
-		sort.Slice(addressData.StakingTXs[:], func(i, j int) bool {
-			return addressData.StakingTXs[i].Timestamp > addressData.StakingTXs[j].Timestamp
-		})
-	} else {
-		sort.Slice(addressData.StakingTXs[:], func(i, j int) bool {
-			return addressData.StakingTXs[i].Timestamp < addressData.StakingTXs[j].Timestamp
-		})
-	}
 	hashes := make([]common.Hash, 0)
-	for _, tx := range addressData.StakingTXs {
+	for _, tx := range records {
 		if txType == "" || txType == "ALL" || txType == tx.Type {
-			hash := common.HexToHash(tx.Hash)
-			hashes = append(hashes, hash)
+			hashes = append(hashes, common.HexToHash(tx.Hash))
 		}
 	}
 	return hashes, nil
@@ -230,46 +200,12 @@ func (node *Node) GetStakingTransactionsHistory(address, txType, order string) (
 
 // GetTransactionsCount returns the number of regular transactions hashes of address for input type.
 func (node *Node) GetTransactionsCount(address, txType string) (uint64, error) {
-	addressData := &explorer.Address{}
-	key := explorer.GetAddressKey(address)
-	bytes, err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, false).GetDB().Get([]byte(key), nil)
-	if err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot get storage db instance")
-		return 0, nil
-	}
-	if err = rlp.DecodeBytes(bytes, &addressData); err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot convert address data from DB")
-		return 0, err
-	}
-
-	count := uint64(0)
-	for _, tx := range addressData.TXs {
-		if txType == "" || txType == "ALL" || txType == tx.Type {
-			count++
-		}
-	}
-	return count, nil
+	hashes, err := node.addressTransactionsHistory(address, txType, "", false)
+	return uint64(len(hashes)), err
 }
 
 // GetStakingTransactionsCount returns the number of staking transactions hashes of address for input type.
 func (node *Node) GetStakingTransactionsCount(address, txType string) (uint64, error) {
-	addressData := &explorer.Address{}
-	key := explorer.GetAddressKey(address)
-	bytes, err := explorer.GetStorageInstance(node.SelfPeer.IP, node.SelfPeer.Port, false).GetDB().Get([]byte(key), nil)
-	if err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot get storage db instance")
-		return 0, nil
-	}
-	if err = rlp.DecodeBytes(bytes, &addressData); err != nil {
-		utils.Logger().Error().Err(err).Msg("[Explorer] Cannot convert address data from DB")
-		return 0, err
-	}
-
-	count := uint64(0)
-	for _, tx := range addressData.StakingTXs {
-		if txType == "" || txType == "ALL" || txType == tx.Type {
-			count++
-		}
-	}
-	return count, nil
+	hashes, err := node.addressTransactionsHistory(address, txType, "", true)
+	return uint64(len(hashes)), err
 }
@@ -30,6 +30,8 @@ const (
 	ContractDeployerInitFund = 10000000000
 	// InitFreeFund is the initial fund for permissioned accounts for testnet/devnet/
 	InitFreeFund = 100
+	// LocalnetAccountInitFund is the initial fund of the accounts of a generated localnet.
+	LocalnetAccountInitFund = 10000000
 )
 
 var (
@@ -56,7 +58,7 @@ func (gi *genesisInitializer) InitChainDB(db ethdb.Database, shardID uint32) err
 		if err != nil {
 			return errors.New("cannot find local shard in genesis")
 		}
-		shardState = &shard.State{nil, []shard.Committee{*subComm}}
+		shardState = &shard.State{Shards: []shard.Committee{*subComm}}
 	}
 	gi.node.SetupGenesisBlock(db, shardID, shardState)
 	return nil
@@ -110,6 +112,12 @@ func (node *Node) SetupGenesisBlock(db ethdb.Database, shardID uint32, myShardSt
 		genesisAlloc[contractDeployerAddress] = core.GenesisAccount{Balance: contractDeployerFunds}
 		node.ContractDeployerKey = contractDeployerKey
 	}
+	if netType == nodeconfig.Localnet {
+		localnetFunds := new(big.Int).Mul(big.NewInt(LocalnetAccountInitFund), big.NewInt(denominations.One))
+		for _, address := range nodeconfig.GetLocalnetAccounts() {
+			genesisAlloc[address] = core.GenesisAccount{Balance: localnetFunds}
+		}
+	}
 
 	gspec := core.Genesis{
 		Config:         &chainConfig,
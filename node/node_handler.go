@@ -95,6 +95,11 @@ func (node *Node) HandleNodeMessage(
 			if err := rlp.DecodeBytes(msgPayload[1:], &blocks); err != nil {
 				return err
 			}
+			for _, block := range blocks {
+				p2p.ObservePropagationLatency(
+					"block", time.Since(time.Unix(block.Time().Int64(), 0)),
+				)
+			}
 			// for non-beaconchain node, subscribe to beacon block broadcast
 			if node.Blockchain().ShardID() != shard.BeaconChainShardID &&
 				node.NodeConfig.Role() != nodeconfig.ExplorerNode {
@@ -230,7 +235,7 @@ func (node *Node) BroadcastCrossLink() {
 
 	utils.Logger().Info().Msgf(
 		"Construct and Broadcasting new crosslink to beacon chain groupID %s",
-		nodeconfig.NewGroupIDByShardID(shard.BeaconChainShardID),
+		nodeconfig.NewCrossLinkGroupID(),
 	)
 	headers := []*block.Header{}
 	lastLink, err := node.Beaconchain().ReadShardLastCrossLink(curBlock.ShardID())
@@ -274,6 +279,9 @@ func (node *Node) BroadcastCrossLink() {
 		}
 	}
 
+	if len(headers) == 0 {
+		return
+	}
 	utils.Logger().Info().Msgf("[BroadcastCrossLink] Broadcasting Block Headers, latestBlockNum %d, currentBlockNum %d, Number of Headers %d", latestBlockNum, curBlock.NumberU64(), len(headers))
 	for _, header := range headers {
 		utils.Logger().Debug().Msgf(
@@ -282,7 +290,7 @@ func (node *Node) BroadcastCrossLink() {
 		)
 	}
 	node.host.SendMessageToGroups(
-		[]nodeconfig.GroupID{nodeconfig.NewGroupIDByShardID(shard.BeaconChainShardID)},
+		[]nodeconfig.GroupID{nodeconfig.NewCrossLinkGroupID()},
 		p2p.ConstructMessage(
 			proto_node.ConstructCrossLinkMessage(node.Consensus.ChainReader, headers)),
 	)
@@ -460,6 +468,7 @@ func (node *Node) PostConsensusProcessing(
 				}
 				computed := availability.ComputeCurrentSigning(
 					snapshot.Validator, wrapper,
+					node.Beaconchain().Config().AvailabilityThreshold(newBlock.Epoch()),
 				)
 				beaconChainBlocks := uint64(
 					node.Beaconchain().CurrentBlock().Header().Number().Int64(),
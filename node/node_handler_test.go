@@ -1,9 +1,13 @@
 package node
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/api/proto"
+	proto_node "github.com/harmony-one/harmony/api/proto/node"
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	"github.com/harmony-one/harmony/core/types"
@@ -14,6 +18,7 @@ import (
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/shard"
 	staking "github.com/harmony-one/harmony/staking/types"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
 )
 
 func TestAddNewBlock(t *testing.T) {
@@ -89,3 +94,104 @@ func TestVerifyNewBlock(t *testing.T) {
 		t.Error("New block is not verified successfully:", err)
 	}
 }
+
+func TestValidateNodeMessage(t *testing.T) {
+	txs := types.Transactions{types.NewTransaction(
+		0, common.Address{}, 0, big.NewInt(0), 21000, big.NewInt(1), nil,
+	)}
+	txMsg := proto_node.ConstructTransactionListMessageAccount(txs)
+	syncMsg := proto_node.ConstructBlocksSyncMessage(nil)
+	tests := []struct {
+		payload []byte
+		ignore  bool
+		valid   bool
+	}{
+		{txMsg, false, true},
+		{txMsg[:len(txMsg)-1], false, false},
+		{syncMsg, false, true},
+		{append(syncMsg, 0), false, false},
+		{[]byte{byte(proto.Node), byte(proto_node.Block), 0xff, 0xc0}, false, false},
+		{[]byte{byte(proto.Node), byte(proto_node.PING)}, true, true},
+		{[]byte{byte(proto.Node), 0xff}, false, false},
+		{[]byte{byte(proto.Node)}, false, false},
+	}
+	for i, test := range tests {
+		ignore, err := validateNodeMessage(test.payload)
+		if ignore != test.ignore || (err == nil) != test.valid {
+			t.Errorf("index %d: got %v %v, expect ignore %v valid %v",
+				i, ignore, err, test.ignore, test.valid,
+			)
+		}
+	}
+}
+
+func TestMessageKind(t *testing.T) {
+	txs := types.Transactions{}
+	tests := []struct {
+		payload []byte
+		kind    string
+	}{
+		{[]byte{byte(proto.Consensus)}, p2p.ConsensusMessageKind},
+		{proto_node.ConstructTransactionListMessageAccount(txs), "transaction"},
+		{proto_node.ConstructBlocksSyncMessage(nil), "block"},
+		{[]byte{byte(proto.Node), byte(proto_node.Block)}, "other"},
+		{[]byte{byte(proto.Node)}, "other"},
+	}
+	for i, test := range tests {
+		if kind := messageKind(test.payload); kind != test.kind {
+			t.Errorf("index %d: got %s, expect %s", i, kind, test.kind)
+		}
+	}
+}
+
+func TestValidateCrossLinkMessage(t *testing.T) {
+	blsKey := bls.RandPrivateKey()
+	pubKey := blsKey.GetPublicKey()
+	leader := p2p.Peer{IP: "127.0.0.1", Port: "8882", ConsensusPubKey: pubKey}
+	priKey, _, _ := utils.GenKeyP2P("127.0.0.1", "9902")
+	host, err := p2p.NewHost(&leader, priKey)
+	if err != nil {
+		t.Fatalf("newhost failure: %v", err)
+	}
+	decider := quorum.NewDecider(
+		quorum.SuperMajorityVote, shard.BeaconChainShardID,
+	)
+	consensus, err := consensus.New(
+		host, shard.BeaconChainShardID, leader, multibls.GetPrivateKey(blsKey), decider,
+	)
+	if err != nil {
+		t.Fatalf("Cannot craeate consensus: %v", err)
+	}
+	node := New(host, consensus, testDBFactory, nil, false)
+
+	crossLinkMsg := func(crossLinks ...types.CrossLink) []byte {
+		data, _ := rlp.EncodeToBytes(crossLinks)
+		return append([]byte{byte(proto.Node), byte(proto_node.Block), byte(proto_node.CrossLink)}, data...)
+	}
+	crossLink := func(epoch int64) types.CrossLink {
+		return types.CrossLink{
+			BlockNumberF: big.NewInt(5),
+			ViewIDF:      big.NewInt(5),
+			ShardIDF:     1,
+			EpochF:       big.NewInt(epoch),
+		}
+	}
+	tests := []struct {
+		payload []byte
+		result  libp2p_pubsub.ValidationResult
+	}{
+		{proto_node.ConstructBlocksSyncMessage(nil), libp2p_pubsub.ValidationReject},
+		{[]byte{byte(proto.Node), byte(proto_node.Block), byte(proto_node.CrossLink), 0xc2}, libp2p_pubsub.ValidationReject},
+		{crossLinkMsg(), libp2p_pubsub.ValidationIgnore},
+		{crossLinkMsg(make([]types.CrossLink, crossLinkBatchSize*2+1)...), libp2p_pubsub.ValidationReject},
+		// the committee of a future epoch is not known yet
+		{crossLinkMsg(crossLink(1000)), libp2p_pubsub.ValidationIgnore},
+		// the cross-link is not signed by the committee
+		{crossLinkMsg(crossLink(0)), libp2p_pubsub.ValidationReject},
+	}
+	for i, test := range tests {
+		if result, err := node.validateCrossLinkMessage(test.payload); result != test.result {
+			t.Errorf("index %d: got %v %v, expect %v", i, result, err, test.result)
+		}
+	}
+}
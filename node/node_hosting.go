@@ -0,0 +1,21 @@
+package node
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/consensus"
+)
+
+// HostShard creates the node of another shard hosted in the process of the
+// node. The hosted node shares the p2p host and the chain collection of the
+// node: its chain database is opened next to the databases of the node and
+// the beacon chain is opened once. The hosted node syncs its shard over the
+// libp2p streams and its RPC is served under /shard/<id> by the RPC server of
+// the node.
+func (node *Node) HostShard(
+	consensusObj *consensus.Consensus, blacklist map[common.Address]struct{},
+) *Node {
+	hosted := newNode(node.host, consensusObj, nil, node.shardChains, blacklist, false)
+	hosted.primary = node
+	node.hostedShards = append(node.hostedShards, hosted)
+	return hosted
+}
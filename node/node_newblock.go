@@ -175,36 +175,16 @@ func (node *Node) proposeNewBlock() (*types.Block, error) {
 	// Prepare cross links and slashing messages
 	var crossLinksToPropose types.CrossLinks
 	if isBeaconchainInCrossLinkEra {
-		allPending, err := node.Blockchain().ReadPendingCrossLinks()
-		invalidToDelete := []types.CrossLink{}
+		crossLinks, invalidToDelete, err := node.proposableCrossLinks()
 		if err == nil {
-			for _, pending := range allPending {
-				exist, err := node.Blockchain().ReadCrossLink(pending.ShardID(), pending.BlockNum())
-				if err == nil || exist != nil {
-					invalidToDelete = append(invalidToDelete, pending)
-					utils.Logger().Debug().
-						AnErr("[proposeNewBlock] pending crosslink is already committed onchain", err)
-					continue
-				}
-
-				// Crosslink is already verified before it's accepted to pending,
-				// no need to verify again in proposal.
-				if !node.Blockchain().Config().IsCrossLink(pending.Epoch()) {
-					utils.Logger().Debug().
-						AnErr("[proposeNewBlock] pending crosslink that's before crosslink epoch", err)
-					continue
-				}
-
-				crossLinksToPropose = append(crossLinksToPropose, pending)
-			}
+			crossLinksToPropose = crossLinks
 			utils.Logger().Info().
-				Msgf("[proposeNewBlock] Proposed %d crosslinks from %d pending crosslinks",
-					len(crossLinksToPropose), len(allPending),
+				Msgf("[proposeNewBlock] Proposed %d crosslinks, %d pending crosslinks already committed",
+					len(crossLinksToPropose), len(invalidToDelete),
 				)
 		} else {
-			utils.Logger().Error().Err(err).Msgf(
-				"[proposeNewBlock] Unable to Read PendingCrossLinks, number of crosslinks: %d",
-				len(allPending),
+			utils.Logger().Error().Err(err).Msg(
+				"[proposeNewBlock] Unable to Read PendingCrossLinks",
 			)
 		}
 		node.Blockchain().DeleteFromPendingCrossLinks(invalidToDelete)
@@ -318,3 +298,33 @@ Loop:
 	utils.Logger().Debug().Msgf("[proposeReceiptsProof] number of validReceipts %d", len(validReceiptsList))
 	return validReceiptsList
 }
+
+// proposableCrossLinks returns the pending crosslinks to propose in the next
+// block, and the pending ones already committed on chain
+func (node *Node) proposableCrossLinks() (types.CrossLinks, []types.CrossLink, error) {
+	allPending, err := node.Blockchain().ReadPendingCrossLinks()
+	if err != nil {
+		return nil, nil, err
+	}
+	crossLinksToPropose, invalidToDelete := types.CrossLinks{}, []types.CrossLink{}
+	for _, pending := range allPending {
+		exist, err := node.Blockchain().ReadCrossLink(pending.ShardID(), pending.BlockNum())
+		if err == nil || exist != nil {
+			invalidToDelete = append(invalidToDelete, pending)
+			utils.Logger().Debug().
+				AnErr("[proposeNewBlock] pending crosslink is already committed onchain", err)
+			continue
+		}
+
+		// Crosslink is already verified before it's accepted to pending,
+		// no need to verify again in proposal.
+		if !node.Blockchain().Config().IsCrossLink(pending.Epoch()) {
+			utils.Logger().Debug().
+				AnErr("[proposeNewBlock] pending crosslink that's before crosslink epoch", err)
+			continue
+		}
+
+		crossLinksToPropose = append(crossLinksToPropose, pending)
+	}
+	return crossLinksToPropose, invalidToDelete, nil
+}
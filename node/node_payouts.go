@@ -0,0 +1,104 @@
+package node
+
+import (
+	"fmt"
+	"math/big"
+	"path"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/harmony-one/harmony/block"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/chain"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// errRewardsNotOnBeacon is returned estimating the block rewards on a node
+// not of the beacon chain, which pays them
+var errRewardsNotOnBeacon = errors.New("block rewards are estimated on the beacon chain")
+
+// StartPayoutAudit opens the payout audit log, if enabled, and records into
+// it the block rewards of the beacon chain blocks as they are committed.
+func (node *Node) StartPayoutAudit() error {
+	if !nodeconfig.GetPayoutAudit() {
+		return nil
+	}
+	dir := path.Join(
+		node.NodeConfig.DBDir, fmt.Sprintf("harmony_payouts_%d", shard.BeaconChainShardID),
+	)
+	db, err := ethdb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		return errors.Wrap(err, "cannot open the payout audit log")
+	}
+	audit := chain.NewPayoutAudit(db)
+	chain.SetPayoutAudit(audit)
+	utils.Logger().Info().Str("dir", dir).Msg("[PayoutAudit] recording the block rewards")
+
+	chainEvents := make(chan core.ChainEvent, chainEventBuffer)
+	sub := node.Beaconchain().SubscribeChainEvent(chainEvents)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-chainEvents:
+				if err := audit.Commit(ev.Block); err != nil {
+					utils.Logger().Warn().Err(err).
+						Uint64("blockNum", ev.Block.NumberU64()).
+						Msg("[PayoutAudit] cannot record the payouts")
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// EstimateBlockRewards returns the header of the next block of the beacon
+// chain and the block rewards it would pay, with the commit signatures of the
+// current block and the pending crosslinks, without changing the state.
+func (node *Node) EstimateBlockRewards() (*block.Header, reward.Reader, error) {
+	if node.NodeConfig.ShardID != shard.BeaconChainShardID {
+		return nil, nil, errRewardsNotOnBeacon
+	}
+	bc := node.Blockchain()
+	parent := bc.CurrentBlock()
+	header := blockfactory.NewFactory(bc.Config()).NewHeader(node.Worker.GetNewEpoch()).With().
+		ParentHash(parent.Hash()).
+		Number(new(big.Int).Add(parent.Number(), common.Big1)).
+		Time(big.NewInt(time.Now().Unix())).
+		ShardID(bc.ShardID()).
+		Header()
+	sig, mask, err := node.Consensus.BlockCommitSig(parent.NumberU64())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot get the commit signatures of the current block")
+	}
+	if len(sig) > 0 && len(mask) > 0 {
+		lastCommitSig := header.LastCommitSignature()
+		copy(lastCommitSig[:], sig)
+		header.SetLastCommitSignature(lastCommitSig)
+		header.SetLastCommitBitmap(mask)
+	}
+	var crossLinks types.CrossLinks
+	if bc.Config().IsCrossLink(header.Epoch()) {
+		if crossLinks, _, err = node.proposableCrossLinks(); err != nil {
+			return nil, nil, errors.Wrap(err, "cannot read the pending crosslinks")
+		}
+	}
+	state, err := bc.State()
+	if err != nil {
+		return nil, nil, err
+	}
+	payout, err := chain.SimulateRewards(bc, state, header, crossLinks, node.Beaconchain())
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, payout, nil
+}
@@ -0,0 +1,81 @@
+package node
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	downloader_pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	"github.com/harmony-one/harmony/api/service/syncing/snap"
+	"github.com/pkg/errors"
+)
+
+// errSnapServerDisabled is returned to snap sync requests when the node does not serve them
+var errSnapServerDisabled = errors.New("[SYNC] snap sync requests are not served")
+
+// calculateSnapResponse serves the requests of snap syncing peers
+func (node *Node) calculateSnapResponse(
+	request *downloader_pb.DownloaderRequest,
+) (*downloader_pb.DownloaderResponse, error) {
+	response := &downloader_pb.DownloaderResponse{}
+	bc := node.Blockchain()
+	triedb := bc.StateCache().TrieDB()
+	switch request.Type {
+	case downloader_pb.DownloaderRequest_STATERANGE:
+		r, err := snap.ServeRange(
+			triedb,
+			common.BytesToHash(request.Root),
+			common.BytesToHash(request.Origin),
+			snap.SoftResponseLimit,
+		)
+		if err != nil {
+			return response, errors.Wrap(err, "[SYNC] cannot serve state range")
+		}
+		encoded, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return response, err
+		}
+		response.Payload = [][]byte{encoded}
+
+	case downloader_pb.DownloaderRequest_BYTECODES:
+		hashes := make([]common.Hash, len(request.Hashes))
+		for i := range request.Hashes {
+			hashes[i] = common.BytesToHash(request.Hashes[i])
+		}
+		response.Payload = snap.ServeByteCodes(triedb, hashes)
+
+	case downloader_pb.DownloaderRequest_RECEIPTS:
+		size := 0
+		for _, hash := range request.Hashes {
+			if size >= snap.SoftResponseLimit {
+				break
+			}
+			receipts := bc.GetReceiptsByHash(common.BytesToHash(hash))
+			if receipts == nil {
+				break
+			}
+			encoded, err := rlp.EncodeToBytes(receipts)
+			if err != nil {
+				return response, err
+			}
+			response.Payload = append(response.Payload, encoded)
+			size += len(encoded)
+		}
+
+	case downloader_pb.DownloaderRequest_STAKINGMETA:
+		header := bc.GetHeaderByHash(common.BytesToHash(request.BlockHash))
+		if header == nil {
+			return response, errors.Errorf(
+				"[SYNC] unknown snap sync pivot %x", request.BlockHash,
+			)
+		}
+		meta, err := bc.ReadSnapStakingMeta(header)
+		if err != nil {
+			return response, errors.Wrap(err, "[SYNC] cannot read staking meta")
+		}
+		encoded, err := rlp.EncodeToBytes(meta)
+		if err != nil {
+			return response, err
+		}
+		response.Payload = [][]byte{encoded}
+	}
+	return response, nil
+}
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"time"
+
+	"github.com/harmony-one/harmony/consensus/signguard"
+	"github.com/harmony-one/harmony/internal/utils"
+)
+
+// StartSigningLease keeps the node signing with its keys while it holds the
+// signing lease shared with its standby, and takes the signing over when the
+// lease of the other node expires. The lease is renewed three times per
+// lease duration. A standby node gives the other node a lease duration to
+// acquire the lease before competing for it.
+func (node *Node) StartSigningLease(lease *signguard.Lease, standby bool) {
+	node.signingLease = lease
+	go node.runSigningLease(lease, standby)
+}
+
+func (node *Node) runSigningLease(lease *signguard.Lease, standby bool) {
+	guard := node.Consensus.SigningGuard
+	if standby {
+		time.Sleep(lease.Duration())
+	}
+	// a lease is only held once renewed, in case another node acquired the
+	// expired lease concurrently
+	acquired, held := false, false
+	ticker := time.NewTicker(lease.Duration() / 3)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		expiry, err := lease.Acquire(time.Now())
+		switch {
+		case err != nil:
+			if held {
+				guard.Deactivate()
+				utils.Logger().Warn().Err(err).Msg("[SigningLease] lost the signing lease, signing stopped")
+			} else if err != signguard.ErrLeaseHeld {
+				utils.Logger().Warn().Err(err).Msg("[SigningLease] cannot acquire the signing lease")
+			}
+			acquired, held = false, false
+		case !acquired:
+			acquired = true
+		case !held:
+			// the votes signed by the previous holder are in the shared
+			// slashing protection file
+			if err := guard.Reload(); err != nil {
+				utils.Logger().Error().Err(err).Msg("[SigningLease] cannot take the signing over")
+				acquired = false
+				continue
+			}
+			guard.ActivateUntil(expiry)
+			held = true
+			utils.Logger().Info().
+				Time("expiry", expiry).
+				Msg("[SigningLease] acquired the signing lease, signing started")
+		default:
+			guard.ActivateUntil(expiry)
+		}
+	}
+}
+
+// releaseSigningLease stops the signing and releases the signing lease, so
+// that the standby takes the signing over without waiting for its expiry
+func (node *Node) releaseSigningLease() {
+	if node.signingLease == nil {
+		return
+	}
+	node.Consensus.SigningGuard.Deactivate()
+	if err := node.signingLease.Release(); err != nil {
+		utils.Logger().Warn().Err(err).Msg("[SigningLease] cannot release the signing lease")
+	}
+}
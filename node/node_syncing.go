@@ -11,13 +11,18 @@ import (
 	"github.com/harmony-one/harmony/api/service/syncing"
 	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	downloader_pb "github.com/harmony-one/harmony/api/service/syncing/downloader/proto"
+	"github.com/harmony-one/harmony/block"
 	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/node/worker"
 	"github.com/harmony-one/harmony/p2p"
+	"github.com/harmony-one/harmony/shard"
 	lru "github.com/hashicorp/golang-lru"
+	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/pkg/errors"
 )
 
@@ -50,11 +55,36 @@ func (node *Node) DoSyncWithoutConsensus() {
 // IsSameHeight tells whether node is at same bc height as a peer
 func (node *Node) IsSameHeight() (uint64, bool) {
 	if node.stateSync == nil {
-		node.stateSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+		node.stateSync = node.createStateSync(node.Blockchain().ShardID())
 	}
 	return node.stateSync.IsSameBlockchainHeight(node.Blockchain())
 }
 
+// syncProtocolID returns the libp2p protocol serving the syncing requests of the shard
+func (node *Node) syncProtocolID(shardID uint32) protocol.ID {
+	return downloader.ProtocolID(string(node.NodeConfig.GetNetworkType()), shardID)
+}
+
+// createStateSync returns the state sync of the given shard, which syncs
+// over libp2p streams if stream syncing is enabled
+func (node *Node) createStateSync(shardID uint32) *syncing.StateSync {
+	stateSync := syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+	if nodeconfig.GetStreamSync() && node.host != nil {
+		stateSync.EnableStreamSync(node.host.GetP2PHost(), node.syncProtocolID(shardID))
+	}
+	if node.host != nil {
+		// the requests are routed to the peers advertising the blocks in their handshake
+		stateSync.SetPeerHeads(func(id libp2p_peer.ID) (p2p.HeadState, bool) {
+			handshake, ok := node.host.PeerHandshake(id)
+			if !ok {
+				return p2p.HeadState{}, false
+			}
+			return handshake.Head(shardID)
+		})
+	}
+	return stateSync
+}
+
 // SyncingPeerProvider is an interface for getting the peers in the given shard.
 type SyncingPeerProvider interface {
 	SyncingPeers(shardID uint32) (peers []p2p.Peer, err error)
@@ -120,6 +150,43 @@ func (p *DNSSyncingPeerProvider) SyncingPeers(shardID uint32) (peers []p2p.Peer,
 	return peers, nil
 }
 
+// StreamSyncingPeerProvider returns the connected libp2p peers serving the
+// syncing protocol of the shard. The peers are found by the libp2p peer
+// discovery of the network, so no DNS sync hosts are needed.
+type StreamSyncingPeerProvider struct {
+	host    libp2p_host.Host
+	network string
+}
+
+// NewStreamSyncingPeerProvider returns a provider of the libp2p peers of the
+// node serving the syncing protocol.
+func NewStreamSyncingPeerProvider(node *Node) *StreamSyncingPeerProvider {
+	return &StreamSyncingPeerProvider{
+		host:    node.host.GetP2PHost(),
+		network: string(node.NodeConfig.GetNetworkType()),
+	}
+}
+
+// SyncingPeers returns the connected peers serving the syncing protocol of the shard.
+func (p *StreamSyncingPeerProvider) SyncingPeers(shardID uint32) (peers []p2p.Peer, err error) {
+	protocolID := string(downloader.ProtocolID(p.network, shardID))
+	for _, peerID := range p.host.Network().Peers() {
+		supported, err := p.host.Peerstore().SupportsProtocols(peerID, protocolID)
+		if err != nil || len(supported) == 0 {
+			continue
+		}
+		peers = append(peers, p2p.Peer{
+			PeerID: peerID,
+			Addrs:  p.host.Peerstore().Addrs(peerID),
+		})
+	}
+	if len(peers) == 0 {
+		return nil, errors.Errorf(
+			"[SYNC] no connected peers serve the syncing protocol %s", protocolID)
+	}
+	return peers, nil
+}
+
 // LocalSyncingPeerProvider uses localnet deployment convention to synthesize
 // syncing peers.
 type LocalSyncingPeerProvider struct {
@@ -159,9 +226,31 @@ func (p *LocalSyncingPeerProvider) SyncingPeers(shardID uint32) (peers []p2p.Pee
 
 // DoBeaconSyncing update received beaconchain blocks and downloads missing beacon chain blocks
 func (node *Node) DoBeaconSyncing() {
+	if nodeconfig.GetBeaconLight() {
+		// the committees are followed by the beacon light client service
+		for range node.BeaconBlockChannel {
+		}
+		return
+	}
 	go func(node *Node) {
 		// TODO ek – infinite loop; add shutdown/cleanup logic
 		for beaconBlock := range node.BeaconBlockChannel {
+			if nodeconfig.GetBeaconHeaderSync() {
+				// blocks not extending the headers are left to the header sync loop
+				if beaconBlock.NumberU64() != node.Beaconchain().CurrentHeader().Number().Uint64()+1 {
+					continue
+				}
+				if _, err := node.Beaconchain().InsertBeaconHeaders(
+					[]*block.Header{beaconBlock.Header()},
+				); err != nil {
+					utils.Logger().Warn().Err(err).
+						Uint64("block", beaconBlock.NumberU64()).
+						Msg("cannot insert beacon block header")
+				} else if node.Consensus.IsLeader() {
+					node.BroadcastCrossLink()
+				}
+				continue
+			}
 			if node.beaconSync != nil {
 				err := node.beaconSync.UpdateBlockAndStatus(
 					beaconBlock, node.Beaconchain(), node.BeaconWorker, true,
@@ -180,7 +269,7 @@ func (node *Node) DoBeaconSyncing() {
 	for {
 		if node.beaconSync == nil {
 			utils.Logger().Info().Msg("initializing beacon sync")
-			node.beaconSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+			node.beaconSync = node.createStateSync(shard.BeaconChainShardID)
 		}
 		if node.beaconSync.GetActivePeerNumber() == 0 {
 			utils.Logger().Info().Msg("no peers; bootstrapping beacon sync config")
@@ -197,7 +286,11 @@ func (node *Node) DoBeaconSyncing() {
 				continue
 			}
 		}
-		node.beaconSync.SyncLoop(node.Beaconchain(), node.BeaconWorker, true, nil)
+		if nodeconfig.GetBeaconHeaderSync() {
+			node.beaconSync.HeaderSyncLoop(node.Beaconchain())
+		} else {
+			node.beaconSync.SyncLoop(node.Beaconchain(), node.BeaconWorker, true, nil)
+		}
 		time.Sleep(time.Duration(SyncFrequency) * time.Second)
 	}
 }
@@ -219,7 +312,7 @@ func (node *Node) DoSyncing(bc *core.BlockChain, worker *worker.Worker, willJoin
 // doSync keep the node in sync with other peers, willJoinConsensus means the node will try to join consensus after catch up
 func (node *Node) doSync(bc *core.BlockChain, worker *worker.Worker, willJoinConsensus bool) {
 	if node.stateSync == nil {
-		node.stateSync = syncing.CreateStateSync(node.SelfPeer.IP, node.SelfPeer.Port, node.GetSyncID())
+		node.stateSync = node.createStateSync(bc.ShardID())
 		utils.Logger().Debug().Msg("[SYNC] initialized state sync")
 	}
 	if node.stateSync.GetActivePeerNumber() < syncing.NumPeersLowBound {
@@ -298,8 +391,22 @@ func (node *Node) InitSyncingServer() {
 // StartSyncingServer starts syncing server.
 func (node *Node) StartSyncingServer() {
 	utils.Logger().Info().Msg("[SYNC] support_syncing: StartSyncingServer")
+	if node.primary != nil {
+		// the syncing port is served by the hosting node, the hosted shard is
+		// only served over libp2p streams
+		node.downloaderServer.StartStream(
+			node.host.GetP2PHost(), node.syncProtocolID(node.Blockchain().ShardID()),
+		)
+		return
+	}
 	if node.downloaderServer.GrpcServer == nil {
 		node.downloaderServer.Start(node.SelfPeer.IP, syncing.GetSyncingPort(node.SelfPeer.Port))
+		if node.host != nil {
+			// serve the peers syncing over libp2p streams as well
+			node.downloaderServer.StartStream(
+				node.host.GetP2PHost(), node.syncProtocolID(node.Blockchain().ShardID()),
+			)
+		}
 	}
 }
 
@@ -403,6 +510,15 @@ func (node *Node) CalculateResponse(request *downloader_pb.DownloaderRequest, in
 	case downloader_pb.DownloaderRequest_BLOCKHEIGHT:
 		response.BlockHeight = node.Blockchain().CurrentBlock().NumberU64()
 
+	case downloader_pb.DownloaderRequest_STATERANGE,
+		downloader_pb.DownloaderRequest_BYTECODES,
+		downloader_pb.DownloaderRequest_RECEIPTS,
+		downloader_pb.DownloaderRequest_STAKINGMETA:
+		if !nodeconfig.GetSnapServer() {
+			return response, errSnapServerDisabled
+		}
+		return node.calculateSnapResponse(request)
+
 	// this is the out of sync node acts as grpc server side
 	case downloader_pb.DownloaderRequest_NEWBLOCK:
 		if node.State != NodeNotInSync {
@@ -490,7 +606,7 @@ func (node *Node) getEncodedBlockHeaderByHash(hash common.Hash) ([]byte, error)
 		return b.([]byte), nil
 	}
 	h := node.Blockchain().GetHeaderByHash(hash)
-	if h == nil {
+	if h == nil || node.Blockchain().IsQuarantined(h.Number().Uint64()) {
 		return nil, errHeaderNotExist
 	}
 	b, err := rlp.EncodeToBytes(h)
@@ -506,7 +622,7 @@ func (node *Node) getEncodedBlockByHash(hash common.Hash) ([]byte, error) {
 		return b.([]byte), nil
 	}
 	blk := node.Blockchain().GetBlockByHash(hash)
-	if blk == nil {
+	if blk == nil || node.Blockchain().IsQuarantined(blk.NumberU64()) {
 		return nil, errBlockNotExist
 	}
 	b, err := rlp.EncodeToBytes(blk)
@@ -1,10 +1,12 @@
 package node
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
 
+	"github.com/harmony-one/harmony/api/service/syncing/downloader"
 	"github.com/harmony-one/harmony/consensus"
 	"github.com/harmony-one/harmony/consensus/quorum"
 	bls2 "github.com/harmony-one/harmony/crypto/bls"
@@ -13,6 +15,7 @@ import (
 	"github.com/harmony-one/harmony/multibls"
 	"github.com/harmony-one/harmony/p2p"
 	"github.com/harmony-one/harmony/shard"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -221,3 +224,23 @@ func TestAddBeaconPeer(t *testing.T) {
 		}
 	}
 }
+
+func TestStreamSyncingPeerProvider(t *testing.T) {
+	net, err := mocknet.FullMeshConnected(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := net.Hosts()
+	p := &StreamSyncingPeerProvider{host: hosts[0], network: "testnet"}
+	if _, err := p.SyncingPeers(1); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no connected peers")
+	}
+
+	// the protocols of the peers are known from the identify protocol
+	protocolID := string(downloader.ProtocolID("testnet", 1))
+	hosts[0].Peerstore().AddProtocols(hosts[1].ID(), protocolID)
+	hosts[0].Peerstore().AddProtocols(hosts[2].ID(), string(downloader.ProtocolID("testnet", 0)))
+	if peers, err := p.SyncingPeers(1); assert.NoError(t, err) && assert.Len(t, peers, 1) {
+		assert.Equal(t, hosts[1].ID(), peers[0].PeerID)
+	}
+}
@@ -0,0 +1,283 @@
+package node
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/availability"
+	"github.com/harmony-one/harmony/staking/effective"
+	"github.com/harmony-one/harmony/webhooks"
+)
+
+// chainEventBuffer is the number of chain events the webhooks can fall behind
+const chainEventBuffer = 64
+
+// validatorWatch tracks the validators of the keys of the node across the
+// blocks, to post the changes of their state
+type validatorWatch struct {
+	hooks    *webhooks.ValidatorHooks
+	statuses map[common.Address]effective.Eligibility
+	missed   map[shard.BLSPublicKey]uint64
+}
+
+// StartEventWebhooks posts the elections, jailings and missed blocks of the
+// validators of the keys of the node, and the stalls of its chain, to the
+// hooks of the webhook yaml
+func (node *Node) StartEventWebhooks() {
+	hooks := node.NodeConfig.WebHooks.Hooks
+	if hooks == nil {
+		return
+	}
+	if hooks.Validator != nil && node.Consensus != nil && node.Consensus.PubKey != nil {
+		go node.watchValidators(&validatorWatch{
+			hooks:    hooks.Validator,
+			statuses: map[common.Address]effective.Eligibility{},
+			missed:   map[shard.BLSPublicKey]uint64{},
+		})
+	}
+	if hooks.Sync != nil && hooks.Sync.OnSyncStalled != "" {
+		go node.watchSyncStall(hooks.Sync)
+	}
+}
+
+// watchValidators follows the blocks of the chain of the node for the blocks
+// missed by its keys, and the blocks of the beacon chain for their elections
+// and jailings
+func (node *Node) watchValidators(w *validatorWatch) {
+	chainEvents := make(chan core.ChainEvent, chainEventBuffer)
+	sub := node.Blockchain().SubscribeChainEvent(chainEvents)
+	defer sub.Unsubscribe()
+	// a nil channel for the beacon node, whose chain is the beacon chain
+	var beaconEvents chan core.ChainEvent
+	var beaconErr <-chan error
+	if node.Blockchain().ShardID() != shard.BeaconChainShardID {
+		beaconEvents = make(chan core.ChainEvent, chainEventBuffer)
+		beaconSub := node.Beaconchain().SubscribeChainEvent(beaconEvents)
+		defer beaconSub.Unsubscribe()
+		beaconErr = beaconSub.Err()
+	}
+
+	for {
+		select {
+		case ev := <-chainEvents:
+			node.checkMissedBlocks(w, ev.Block)
+			if beaconEvents == nil {
+				node.checkBeaconBlock(w, ev.Block)
+			}
+		case ev := <-beaconEvents:
+			node.checkBeaconBlock(w, ev.Block)
+		case <-sub.Err():
+			return
+		case <-beaconErr:
+			return
+		}
+	}
+}
+
+// checkMissedBlocks counts the blocks missed in a row by the keys of the node
+// in the committee of the parent of the block, whose signatures the block
+// carries
+func (node *Node) checkMissedBlocks(w *validatorWatch, block *types.Block) {
+	if w.hooks.OnMissedBlocks == "" || block.NumberU64() <= 1 {
+		return
+	}
+	bc := node.Blockchain()
+	parentHeader := bc.GetHeaderByHash(block.ParentHash())
+	if parentHeader == nil {
+		return
+	}
+	parentShardState, err := bc.ReadShardState(parentHeader.Epoch())
+	if err != nil {
+		return
+	}
+	parentCommittee, err := parentShardState.FindCommitteeByID(bc.ShardID())
+	if err != nil {
+		return
+	}
+	_, missing, err := availability.BlockSigners(
+		block.Header().LastCommitBitmap(), parentCommittee,
+	)
+	if err != nil {
+		utils.Logger().Debug().Err(err).
+			Uint64("block", block.NumberU64()).
+			Msg("[Webhooks] cannot read the signers of the block")
+		return
+	}
+	missed := map[shard.BLSPublicKey]bool{}
+	for _, slot := range missing {
+		missed[slot.BLSPublicKey] = true
+	}
+	inCommittee := map[shard.BLSPublicKey]bool{}
+	for _, slot := range parentCommittee.Slots {
+		inCommittee[slot.BLSPublicKey] = true
+	}
+
+	for _, key := range node.Consensus.PubKey.PublicKey {
+		pubKey := *shard.FromLibBLSPublicKeyUnsafe(key)
+		switch {
+		case !inCommittee[pubKey]:
+			delete(w.missed, pubKey)
+		case !missed[pubKey]:
+			w.missed[pubKey] = 0
+		default:
+			w.missed[pubKey]++
+			if w.missed[pubKey] == w.hooks.MissedBlocks {
+				node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+					w.hooks.OnMissedBlocks, webhooks.EventMissedBlocks,
+					map[string]interface{}{
+						"bls-key":       pubKey.Hex(),
+						"missed-blocks": w.missed[pubKey],
+						"block":         parentHeader.Number().Uint64(),
+					},
+				)
+			}
+		}
+	}
+}
+
+// checkBeaconBlock posts the elections of the keys of the node at the last
+// block of an epoch, and the jailings of their validators at the blocks that
+// can change their status
+func (node *Node) checkBeaconBlock(w *validatorWatch, block *types.Block) {
+	header := block.Header()
+	if block.NumberU64() == 0 {
+		return
+	}
+	if len(header.ShardState()) > 0 {
+		node.checkElection(w, block)
+	}
+	if w.hooks.OnJailed == "" || (len(header.ShardState()) == 0 && len(header.Slashes()) == 0) {
+		return
+	}
+	for _, addr := range node.GetAddresses(block.Epoch()) {
+		wrapper, err := node.Beaconchain().ReadValidatorInformationAt(addr, block.Root())
+		if err != nil {
+			continue
+		}
+		status, seen := w.statuses[addr]
+		w.statuses[addr] = wrapper.Status
+		if seen && status != effective.Banned && wrapper.Status == effective.Banned {
+			node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+				w.hooks.OnJailed, webhooks.EventJailed, map[string]interface{}{
+					"validator": addr,
+					"status":    wrapper.Status.String(),
+					"block":     block.NumberU64(),
+				},
+			)
+		}
+	}
+}
+
+// checkElection compares the committees of the keys of the node in the
+// current epoch and in the next epoch, elected at the last block of the epoch
+func (node *Node) checkElection(w *validatorWatch, block *types.Block) {
+	if w.hooks.OnElected == "" && w.hooks.OnUnelected == "" {
+		return
+	}
+	next, err := block.Header().GetShardState()
+	if err != nil {
+		return
+	}
+	current, err := node.Beaconchain().ReadShardState(block.Epoch())
+	if err != nil {
+		return
+	}
+	for _, key := range node.Consensus.PubKey.PublicKey {
+		pubKey := *shard.FromLibBLSPublicKeyUnsafe(key)
+		wasIn, isIn := committeeOf(current, pubKey), committeeOf(&next, pubKey)
+		data := map[string]interface{}{
+			"bls-key": pubKey.Hex(),
+			"epoch":   next.Epoch,
+		}
+		switch {
+		case wasIn == nil && isIn != nil:
+			data["shard-id"] = isIn.ShardID
+			node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+				w.hooks.OnElected, webhooks.EventElected, data,
+			)
+		case wasIn != nil && isIn == nil:
+			data["shard-id"] = wasIn.ShardID
+			node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+				w.hooks.OnUnelected, webhooks.EventUnelected, data,
+			)
+		}
+	}
+}
+
+// committeeOf returns the committee of the key in the shard state, nil if the
+// key is not elected
+func committeeOf(state *shard.State, key shard.BLSPublicKey) *shard.Committee {
+	for i := range state.Shards {
+		for _, slot := range state.Shards[i].Slots {
+			if slot.BLSPublicKey == key {
+				return &state.Shards[i]
+			}
+		}
+	}
+	return nil
+}
+
+// watchSyncStall posts the head of the chain of the node once it did not
+// change for the stall timeout, and again after the chain grew and stalled
+// again
+func (node *Node) watchSyncStall(hooks *webhooks.SyncHooks) {
+	ticker := time.NewTicker(hooks.StallTimeout / 4)
+	defer ticker.Stop()
+	head, since, posted := node.Blockchain().CurrentBlock().NumberU64(), time.Now(), false
+	for range ticker.C {
+		current := node.Blockchain().CurrentBlock().NumberU64()
+		if current != head {
+			head, since, posted = current, time.Now(), false
+			continue
+		}
+		if posted || time.Since(since) < hooks.StallTimeout {
+			continue
+		}
+		peer := uint64(0)
+		if node.stateSync != nil {
+			peer = node.stateSync.LastPeerHeight()
+		}
+		node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+			hooks.OnSyncStalled, webhooks.EventSyncStalled, map[string]interface{}{
+				"block":      head,
+				"peer-block": peer,
+				"since":      since.UTC(),
+			},
+		)
+		posted = true
+	}
+}
+
+// NotifyViewChange posts the view changes from or to a key of the node
+func (node *Node) NotifyViewChange(viewID uint64, from, to *bls.PublicKey) {
+	hooks := node.NodeConfig.WebHooks.Hooks
+	if hooks == nil || hooks.Consensus == nil || node.Consensus.PubKey == nil {
+		return
+	}
+	ours := func(key *bls.PublicKey) bool {
+		return key != nil && node.Consensus.PubKey.Contains(key)
+	}
+	if !ours(from) && !ours(to) {
+		return
+	}
+	hex := func(key *bls.PublicKey) string {
+		if key == nil {
+			return ""
+		}
+		return key.SerializeToHexStr()
+	}
+	node.NodeConfig.WebHooks.Dispatcher.Dispatch(
+		hooks.Consensus.OnViewChange, webhooks.EventViewChange, map[string]interface{}{
+			"view-id":      viewID,
+			"from-leader":  hex(from),
+			"to-leader":    hex(to),
+			"from-own-key": ours(from),
+			"to-own-key":   ours(to),
+		},
+	)
+}
@@ -1,6 +1,7 @@
 package node
 
 import (
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/p2p"
 )
 
@@ -8,3 +9,19 @@ import (
 func (node *Node) GetHost() p2p.Host {
 	return node.host
 }
+
+// HeadState returns the head of the chain of the node, with the blocks and
+// states it serves, announced to the peers in the handshake
+func (node *Node) HeadState() p2p.HeadState {
+	chain := node.Blockchain()
+	head := chain.CurrentHeader()
+	return p2p.HeadState{
+		ShardID:     chain.ShardID(),
+		Genesis:     chain.Genesis().Hash().Hex(),
+		Number:      head.Number().Uint64(),
+		Hash:        head.Hash().Hex(),
+		OldestBlock: chain.OldestAvailableBlock(),
+		Archival:    chain.IsArchival(),
+		SnapServer:  nodeconfig.GetSnapServer(),
+	}
+}
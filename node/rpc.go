@@ -1,17 +1,23 @@
 package node
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/core/types"
 	"github.com/harmony-one/harmony/hmy"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/hmyapi"
+	"github.com/harmony-one/harmony/internal/hmyapi/apieth"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv1"
 	"github.com/harmony-one/harmony/internal/hmyapi/apiv2"
 	"github.com/harmony-one/harmony/internal/hmyapi/filters"
@@ -27,13 +33,22 @@ var (
 	// HTTP RPC
 	httpListener     net.Listener
 	httpHandler      *rpc.Server
+	httpServer       *http.Server
 	httpEndpoint     = ""
 	wsEndpoint       = ""
-	httpModules      = []string{"hmy", "hmyv2", "net", "netv2", "explorer"}
+	wsListener       net.Listener
+	wsHandler        *hmyapi.WSHandler
+	wsServer         *http.Server
+	ipcEndpoint      = ""
+	ipcListener      net.Listener
+	ipcHandler       *rpc.Server
+	shardHandlers    []*rpc.Server
+	rpcTLS           *tls.Config
+	httpModules      = []string{"hmy", "hmyv2", "net", "netv2", "explorer", "debug"}
 	httpVirtualHosts = []string{"*"}
 	httpTimeouts     = rpc.DefaultHTTPTimeouts
 	httpOrigins      = []string{"*"}
-	wsModules        = []string{"hmy", "hmyv2", "net", "netv2", "web3"}
+	wsModules        = []string{"hmy", "hmyv2", "net", "netv2", "web3", "debug"}
 	wsOrigins        = []string{"*"}
 	harmony          *hmy.Harmony
 )
@@ -48,6 +63,18 @@ func (node *Node) PeerConnectivity() (int, int, int) {
 	return node.host.C()
 }
 
+// PeerReachability returns whether the p2p host is publicly reachable
+func (node *Node) PeerReachability() string {
+	return node.host.Reachability().String()
+}
+
+// PeerRateLimitDrops returns the number of inbound p2p messages of each kind
+// dropped over the limit of their kind and over the limit of their peer
+func (node *Node) PeerRateLimitDrops() (map[string]uint64, map[string]uint64) {
+	drops := node.host.RateLimitDrops()
+	return drops.ByKind, drops.ByPeer
+}
+
 // PendingCXReceipts returns node.pendingCXReceiptsProof
 func (node *Node) PendingCXReceipts() []*types.CXReceiptsProof {
 	cxReceipts := make([]*types.CXReceiptsProof, len(node.pendingCXReceipts))
@@ -59,6 +86,16 @@ func (node *Node) PendingCXReceipts() []*types.CXReceiptsProof {
 	return cxReceipts
 }
 
+// TraceCrossShardTransaction returns the lifecycle of the cross-shard
+// transaction of the hash, as recorded by the chains open in the process
+func (node *Node) TraceCrossShardTransaction(hash common.Hash) (*core.CXTrace, error) {
+	host := node
+	if node.primary != nil {
+		host = node.primary
+	}
+	return core.TraceCX(hash, host.chains()...)
+}
+
 // ReportStakingErrorSink is the report of failed staking transactions this node has (held inmemory only)
 func (node *Node) ReportStakingErrorSink() types.TransactionErrorReports {
 	return node.TransactionErrorSink.StakingReport()
@@ -87,51 +124,193 @@ func (node *Node) StartRPC(nodePort string) error {
 		apis = append(apis, service.APIs()...)
 	}
 
+	rpcTLS = nil
+	if config := nodeconfig.GetRPCTLSConfig(); config.Enabled() {
+		reloader, err := hmyapi.NewCertReloader(config)
+		if err != nil {
+			return err
+		}
+		rpcTLS = reloader.TLSConfig()
+	}
+
+	if err := node.startIPC(nodeconfig.GetIPCPath(), apis); err != nil {
+		return err
+	}
+	netAPIs := networkAPIs(apis)
+
 	port, _ := strconv.Atoi(nodePort)
 
+	modules := httpModules
+	if nodeconfig.GetAdminRPC() {
+		modules = append(modules, "admin")
+	}
+	if nodeconfig.GetEthRPCStrict() {
+		modules = append(modules, "eth")
+	}
+
 	ip := ""
 	if !nodeconfig.GetPublicRPC() {
 		ip = "127.0.0.1"
 	}
 	httpEndpoint = fmt.Sprintf("%v:%v", ip, port+rpcHTTPPortOffset)
 
-	if err := node.startHTTP(httpEndpoint, apis, httpModules, httpOrigins, httpVirtualHosts, httpTimeouts); err != nil {
+	// the shards hosted by the node are served over HTTP under /shard/<id>
+	shardAPIs := map[uint32][]rpc.API{}
+	for _, hosted := range node.hostedShards {
+		backend, err := hmy.New(
+			hosted, hosted.TxPool, hosted.CxPool, new(event.TypeMux), hosted.Consensus.ShardID,
+		)
+		if err != nil {
+			node.stopIPC()
+			return err
+		}
+		shardAPIs[hosted.Consensus.ShardID] = networkAPIs(hosted.backendAPIs(backend))
+	}
+
+	if err := node.startHTTP(httpEndpoint, netAPIs, shardAPIs, modules, httpOrigins, httpVirtualHosts, httpTimeouts); err != nil {
+		node.stopIPC()
 		return err
 	}
 	wsEndpoint = fmt.Sprintf("%v:%v", ip, port+rpcWSPortOffset)
-	if err := node.startWS(wsEndpoint, apis, wsModules, wsOrigins, true); err != nil {
+	if err := node.startWS(wsEndpoint, netAPIs, wsModules, wsOrigins, true); err != nil {
 		node.stopHTTP()
+		node.stopIPC()
 		return err
 	}
 
 	return nil
 }
 
-// startHTTP initializes and starts the HTTP RPC endpoint.
-func (node *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
+// networkAPIs returns the APIs served over HTTP and websocket. The admin
+// namespace is only served when enabled explicitly, the other private APIs
+// are restricted to the IPC socket once it is enabled.
+func networkAPIs(apis []rpc.API) []rpc.API {
+	ipc := nodeconfig.GetIPCPath() != ""
+	served := make([]rpc.API, 0, len(apis))
+	for _, api := range apis {
+		switch {
+		case api.Namespace == "admin":
+			if !nodeconfig.GetAdminRPC() {
+				continue
+			}
+		case !api.Public && ipc:
+			continue
+		}
+		served = append(served, api)
+	}
+	return served
+}
+
+// newRPCServer registers the APIs of the allowed modules on a new RPC server.
+// If no module is given, all public APIs are registered.
+func newRPCServer(apis []rpc.API, modules []string, exposeAll bool) (*rpc.Server, error) {
+	whitelist := make(map[string]bool)
+	for _, module := range modules {
+		whitelist[module] = true
+	}
+	handler := rpc.NewServer()
+	registered := []rpc.API{}
+	for _, api := range apis {
+		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+				return nil, err
+			}
+			registered = append(registered, api)
+		}
+	}
+	discover := hmyapi.DiscoverAPI(registered)
+	if err := handler.RegisterName(discover.Namespace, discover.Service); err != nil {
+		return nil, err
+	}
+	return handler, nil
+}
+
+// rpcListen listens on the TCP endpoint, terminating TLS if it is configured.
+// It returns the listener and the URL scheme suffix, "s" when TLS is used.
+func rpcListen(endpoint string) (net.Listener, string, error) {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+	if rpcTLS == nil {
+		return listener, "", nil
+	}
+	return tls.NewListener(listener, rpcTLS), "s", nil
+}
+
+// shardPath returns the HTTP path serving the RPC of the shard
+func shardPath(shardID uint32) string {
+	return fmt.Sprintf("/shard/%d", shardID)
+}
+
+// startHTTP initializes and starts the HTTP RPC endpoint. The APIs of the
+// shards hosted by the node are served under the path of their shard.
+func (node *Node) startHTTP(endpoint string, apis []rpc.API, shardAPIs map[uint32][]rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
 
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	handler, err := newRPCServer(apis, modules, false)
 	if err != nil {
 		return err
 	}
+	shardServers := make(map[uint32]*rpc.Server, len(shardAPIs))
+	for shardID, hostedAPIs := range shardAPIs {
+		if shardServers[shardID], err = newRPCServer(hostedAPIs, modules, false); err != nil {
+			return err
+		}
+	}
+	listener, secure, err := rpcListen(endpoint)
+	if err != nil {
+		return err
+	}
+	// health probes bypass the CORS and virtual host checks of the RPC handler
+	server := rpc.NewHTTPServer(cors, vhosts, timeouts, handler)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", node.healthzHandler)
+	mux.HandleFunc("/readyz", node.readyzHandler)
+	if len(shardServers) > 0 {
+		mux.Handle(shardPath(node.Consensus.ShardID), hmyapi.DiscoverAliasHandler(server.Handler))
+	}
+	for shardID, shardServer := range shardServers {
+		shardHTTP := rpc.NewHTTPServer(cors, vhosts, timeouts, shardServer)
+		mux.Handle(shardPath(shardID), hmyapi.DiscoverAliasHandler(shardHTTP.Handler))
+		shardHandlers = append(shardHandlers, shardServer)
+	}
+	mux.Handle("/", hmyapi.DiscoverAliasHandler(server.Handler))
+	server.Handler = mux
+	go server.Serve(listener)
 
 	utils.Logger().Info().
-		Str("url", fmt.Sprintf("http://%s", endpoint)).
+		Str("url", fmt.Sprintf("http%s://%s", secure, endpoint)).
 		Str("cors", strings.Join(cors, ",")).
 		Str("vhosts", strings.Join(vhosts, ",")).
 		Msg("HTTP endpoint opened")
 	// All listeners booted successfully
 	httpListener = listener
 	httpHandler = handler
+	httpServer = server
 	return nil
 }
 
-// stopHTTP terminates the HTTP RPC endpoint.
+// drainServer stops the server from accepting new connections and waits for
+// the requests in flight to complete, at most for the write timeout of the
+// responses.
+func drainServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeouts.WriteTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		utils.Logger().Warn().Err(err).Msg("RPC requests in flight not completed")
+	}
+}
+
+// stopHTTP drains and terminates the HTTP RPC endpoint.
 func (node *Node) stopHTTP() {
+	if httpServer != nil {
+		drainServer(httpServer)
+		httpServer = nil
+	}
 	if httpListener != nil {
 		httpListener.Close()
 		httpListener = nil
@@ -141,6 +320,10 @@ func (node *Node) stopHTTP() {
 		httpHandler.Stop()
 		httpHandler = nil
 	}
+	for _, shardHandler := range shardHandlers {
+		shardHandler.Stop()
+	}
+	shardHandlers = nil
 }
 
 // startWS initializes and starts the websocket RPC endpoint.
@@ -151,21 +334,118 @@ func (node *Node) startWS(
 	if endpoint == "" {
 		return nil
 	}
-	listener, _, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	handler, err := newRPCServer(apis, modules, exposeAll)
 	if err != nil {
 		return err
 	}
+	listener, secure, err := rpcListen(endpoint)
+	if err != nil {
+		return err
+	}
+	config := nodeconfig.GetWSConfig()
+	wsHandler = hmyapi.NewWSHandler(handler, wsOrigins, config)
+	server := &http.Server{Handler: wsHandler}
+	go server.Serve(listener)
+
 	utils.Logger().Info().
-		Str("url", fmt.Sprintf("ws://%s", listener.Addr())).
+		Str("url", fmt.Sprintf("ws%s://%s", secure, listener.Addr())).
+		Int("max-connections", config.MaxConnections).
+		Int("max-subscriptions", config.MaxSubscriptions).
+		Dur("idle-timeout", config.IdleTimeout).
 		Msg("WebSocket endpoint opened")
+	wsListener = listener
+	wsServer = server
 	return nil
 }
 
+// stopWS notifies the connected websocket clients and terminates the websocket RPC endpoint.
+func (node *Node) stopWS() {
+	if wsHandler != nil {
+		wsHandler.Close()
+		wsHandler = nil
+	}
+	if wsServer != nil {
+		drainServer(wsServer)
+		wsServer = nil
+	}
+	if wsListener != nil {
+		wsListener.Close()
+		wsListener = nil
+		utils.Logger().Info().Str("url", fmt.Sprintf("ws://%s", wsEndpoint)).Msg("WebSocket endpoint closed")
+	}
+}
+
+// startIPC initializes and starts the IPC RPC endpoint, which serves all APIs
+// including the private ones.
+func (node *Node) startIPC(endpoint string, apis []rpc.API) error {
+	// Short circuit if the IPC endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	apis = append(apis, hmyapi.DiscoverAPI(apis))
+	listener, handler, err := rpc.StartIPCEndpoint(endpoint, apis)
+	if err != nil {
+		return err
+	}
+	utils.Logger().Info().Str("url", endpoint).Msg("IPC endpoint opened")
+	ipcEndpoint = endpoint
+	ipcListener = listener
+	ipcHandler = handler
+	return nil
+}
+
+// stopIPC terminates the IPC RPC endpoint.
+func (node *Node) stopIPC() {
+	if ipcListener != nil {
+		ipcListener.Close()
+		ipcListener = nil
+		utils.Logger().Info().Str("url", ipcEndpoint).Msg("IPC endpoint closed")
+	}
+	if ipcHandler != nil {
+		ipcHandler.Stop()
+		ipcHandler = nil
+	}
+}
+
+// StopRPC terminates the HTTP, websocket and IPC RPC endpoints, once the
+// requests in flight are completed.
+func (node *Node) StopRPC() {
+	node.stopWS()
+	node.stopHTTP()
+	node.stopIPC()
+}
+
+// RestartRPC restarts the RPC endpoints to apply the changed RPC settings,
+// once the requests in flight are completed
+func (node *Node) RestartRPC(nodePort string) error {
+	node.StopRPC()
+	return node.StartRPC(nodePort)
+}
+
 // APIs return the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (node *Node) APIs() []rpc.API {
+	return node.backendAPIs(harmony)
+}
+
+// backendAPIs returns the APIs of the node served by the given backend
+func (node *Node) backendAPIs(harmony *hmy.Harmony) []rpc.API {
 	// Gather all the possible APIs to surface
 	apis := hmyapi.GetAPIs(harmony.APIBackend)
+	apis = append(apis, rpc.API{
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   apiv2.NewPrivateAdminAPI(node.host, node.Consensus.ShardID),
+		Public:    false,
+	})
+	if nodeconfig.GetEthRPCStrict() {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   apieth.NewPublicEthAPI(harmony.APIBackend),
+			Public:    true,
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/harmony-one/harmony/consensus"
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+// IPCTestService is registered on the test IPC endpoint, rpc requires it to be exported
+type IPCTestService struct{}
+
+func (IPCTestService) Echo(s string) string { return s }
+
+// ShardTestService is registered on the test HTTP endpoint for each shard
+type ShardTestService struct{ shardID uint32 }
+
+func (s ShardTestService) ShardID() uint32 { return s.shardID }
+
+func TestNetworkAPIs(t *testing.T) {
+	defer nodeconfig.SetIPCPath("")
+	defer nodeconfig.SetAdminRPC(false)
+	apis := []rpc.API{
+		{Namespace: "hmy", Public: true},
+		{Namespace: "hmy", Public: false},
+		{Namespace: "admin", Public: false},
+	}
+	tests := []struct {
+		ipcPath  string
+		adminRPC bool
+		expected []rpc.API
+	}{
+		{"", false, apis[:2]},
+		{"", true, apis},
+		{"harmony.ipc", false, apis[:1]},
+		{"harmony.ipc", true, []rpc.API{apis[0], apis[2]}},
+	}
+	for i, test := range tests {
+		nodeconfig.SetIPCPath(test.ipcPath)
+		nodeconfig.SetAdminRPC(test.adminRPC)
+		served := networkAPIs(apis)
+		if len(served) != len(test.expected) {
+			t.Errorf("test %d: got %d APIs, expect %d", i, len(served), len(test.expected))
+			continue
+		}
+		for j := range served {
+			if served[j] != test.expected[j] {
+				t.Errorf("test %d: got API %+v, expect %+v", i, served[j], test.expected[j])
+			}
+		}
+	}
+}
+
+func TestIPCEndpoint(t *testing.T) {
+	node := &Node{}
+	endpoint := filepath.Join(t.TempDir(), "harmony.ipc")
+	apis := []rpc.API{{Namespace: "test", Service: IPCTestService{}, Public: false}}
+	if err := node.startIPC(endpoint, apis); err != nil {
+		t.Fatal(err)
+	}
+	defer node.stopIPC()
+
+	client, err := rpc.DialIPC(context.Background(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	var result string
+	if err := client.Call(&result, "test_echo", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "hi" {
+		t.Errorf("got %q, expect \"hi\"", result)
+	}
+}
+
+func TestHTTPShardRouting(t *testing.T) {
+	node := &Node{Consensus: &consensus.Consensus{ShardID: 1}}
+	shardAPI := func(shardID uint32) []rpc.API {
+		return []rpc.API{{Namespace: "test", Service: ShardTestService{shardID}, Public: true}}
+	}
+	shardAPIs := map[uint32][]rpc.API{0: shardAPI(0), 2: shardAPI(2)}
+	if err := node.startHTTP(
+		"127.0.0.1:0", shardAPI(1), shardAPIs, []string{"test"}, nil, []string{"*"}, rpc.DefaultHTTPTimeouts,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer node.stopHTTP()
+
+	tests := []struct {
+		path     string
+		expected uint32
+	}{
+		{"/", 1},
+		{"/shard/0", 0},
+		{"/shard/1", 1},
+		{"/shard/2", 2},
+	}
+	for _, test := range tests {
+		client, err := rpc.DialHTTP("http://" + httpListener.Addr().String() + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var shardID uint32
+		if err := client.Call(&shardID, "test_shardID"); err != nil {
+			t.Errorf("%s: %v", test.path, err)
+		} else if shardID != test.expected {
+			t.Errorf("%s: got shard %d, expect %d", test.path, shardID, test.expected)
+		}
+		client.Close()
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/harmony-one/harmony/api/service/consensus"
 	"github.com/harmony-one/harmony/api/service/explorer"
 	"github.com/harmony-one/harmony/api/service/networkinfo"
+	"github.com/harmony-one/harmony/api/service/profiler"
+	"github.com/harmony-one/harmony/api/service/telemetry"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
 	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/staking/network"
 )
 
 func (node *Node) setupForValidator() {
@@ -21,6 +24,7 @@ func (node *Node) setupForValidator() {
 		service.NetworkInfo,
 		networkinfo.MustNew(
 			node.host, node.NodeConfig.GetShardGroupID(), chanPeer, nil, node.networkInfoDHTPath(),
+			node.currentEpoch,
 		),
 	)
 	// Register consensus service.
@@ -33,6 +37,14 @@ func (node *Node) setupForValidator() {
 		service.BlockProposal,
 		blockproposal.New(node.Consensus.ReadySignal, node.WaitForConsensusReadyV2),
 	)
+	// Register crosslink request service.
+	node.serviceManager.RegisterService(
+		service.CrossLinkRequest, node.newCrossLinkRequestService(),
+	)
+	// Register beacon light client service.
+	if s := node.newBeaconLightService(); s != nil {
+		node.serviceManager.RegisterService(service.BeaconLight, s)
+	}
 
 	if node.NodeConfig.GetNetworkType() != nodeconfig.Mainnet {
 		// Register client support service.
@@ -52,14 +64,21 @@ func (node *Node) setupForExplorerNode() {
 	node.serviceManager.RegisterService(
 		service.NetworkInfo,
 		networkinfo.MustNew(
-			node.host, node.NodeConfig.GetShardGroupID(), chanPeer, nil, node.networkInfoDHTPath()),
+			node.host, node.NodeConfig.GetShardGroupID(), chanPeer, nil, node.networkInfoDHTPath(),
+			node.currentEpoch,
+		),
 	)
 	// Register explorer service.
 	node.serviceManager.RegisterService(
-		service.SupportExplorer, explorer.New(&node.SelfPeer),
+		service.SupportExplorer, explorer.New(&node.SelfPeer, node.supply),
 	)
 }
 
+// supply computes the supply of ONE at the current block of the beacon chain
+func (node *Node) supply() (*network.Supply, error) {
+	return network.NewSupply(node.Beaconchain(), network.GetSupplySchedule())
+}
+
 // ServiceManagerSetup setups service store.
 func (node *Node) ServiceManagerSetup() {
 	node.serviceManager = &service.Manager{}
@@ -70,6 +89,16 @@ func (node *Node) ServiceManagerSetup() {
 	case nodeconfig.ExplorerNode:
 		node.setupForExplorerNode()
 	}
+	if config := nodeconfig.GetTelemetryConfig(); config.Endpoint != "" {
+		node.serviceManager.RegisterService(
+			service.Telemetry, telemetry.New(config, node.telemetryReport),
+		)
+	}
+	if config := nodeconfig.GetProfilerConfig(); config.Endpoint != "" || config.Dir != "" {
+		node.serviceManager.RegisterService(
+			service.Profiler, profiler.New(config, node.NodeConfig.ShardID),
+		)
+	}
 	node.serviceManager.SetupServiceMessageChan(node.serviceMessageChan)
 }
 
@@ -98,3 +127,8 @@ func (node *Node) networkInfoDHTPath() string {
 		node.chainConfig.ChainID,
 	)
 }
+
+// currentEpoch returns the epoch of the current block of the shard
+func (node *Node) currentEpoch() uint64 {
+	return node.Blockchain().CurrentHeader().Epoch().Uint64()
+}
@@ -0,0 +1,44 @@
+package node
+
+import (
+	"github.com/harmony-one/harmony/api/service/telemetry"
+)
+
+// telemetryBlocks is the number of recent blocks the consensus participation
+// of the node is reported over
+const telemetryBlocks = 100
+
+// telemetryReport returns the anonymized health of the node sent to the
+// telemetry endpoint, without any address, key or peer ID of the node
+func (node *Node) telemetryReport() telemetry.Report {
+	health := node.GetHealthStatus()
+	report := telemetry.Report{
+		Agent:       node.SelfPeer.UserAgent,
+		Network:     string(node.NodeConfig.GetNetworkType()),
+		ShardID:     node.NodeConfig.ShardID,
+		Chains:      make([]telemetry.ChainReport, 0, len(health.Chains)),
+		Peers:       node.host.GetPeerCount(),
+		InCommittee: health.InCommittee,
+	}
+	for _, chain := range health.Chains {
+		report.Chains = append(report.Chains, telemetry.ChainReport{
+			ShardID: chain.ShardID, Block: chain.CurrentBlock, Lag: chain.Lag,
+		})
+	}
+	if node.Consensus.PubKey == nil || !health.InCommittee {
+		return report
+	}
+	bc := node.Blockchain()
+	current := bc.CurrentBlock().NumberU64()
+	for number := current; number > 0 && report.Blocks < telemetryBlocks; number-- {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			break
+		}
+		report.Blocks++
+		if node.numSignaturesIncludedInBlock(block) > 0 {
+			report.SignedBlocks++
+		}
+	}
+	return report
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/internal/utils"
 	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/governance"
 	"github.com/harmony-one/harmony/staking/slash"
 	staking "github.com/harmony-one/harmony/staking/types"
 	"github.com/pkg/errors"
@@ -491,9 +492,15 @@ func (w *Worker) FinalizeNewBlock(
 	}
 	state := w.current.state.Copy()
 	copyHeader := types.CopyHeader(w.current.header)
+	governance.ApplyUpdates(w.config, state, copyHeader, w.current.txs, w.current.receipts)
+	reshardCXs, err := core.ApplyReshardingRedistribution(w.config, state, copyHeader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot redistribute the balances of the retired shard")
+	}
+	outcxs := append(w.current.outcxs[:len(w.current.outcxs):len(w.current.outcxs)], reshardCXs...)
 	block, _, err := w.engine.Finalize(
 		w.chain, copyHeader, state, w.current.txs, w.current.receipts,
-		w.current.outcxs, w.current.incxs, w.current.stakingTxs,
+		outcxs, w.current.incxs, w.current.stakingTxs,
 		w.current.slashes,
 	)
 	if err != nil {
@@ -0,0 +1,92 @@
+package p2p
+
+import (
+	"strconv"
+	"sync"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	connmgr "github.com/libp2p/go-libp2p-connmgr"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// shardPeerTag returns the protection tag of the peers of a shard
+func shardPeerTag(shardID uint32) string {
+	return "shard/" + strconv.FormatUint(uint64(shardID), 10)
+}
+
+// connManager is the libp2p connection manager of the host, which prunes the
+// connections of the unprotected peers over the high watermark. It hands
+// the connection manager wrapped connections to count the pruned ones.
+type connManager struct {
+	*connmgr.BasicConnMgr
+	lock  sync.Mutex
+	conns map[libp2p_network.Conn]*prunableConn
+}
+
+// newConnManager returns the connection manager bounding the connections to
+// the watermarks of the config, with the protected peers of the config
+func newConnManager(config nodeconfig.ConnManagerConfig) (*connManager, error) {
+	connmgr.SilencePeriod = config.SilencePeriod
+	cm := &connManager{
+		BasicConnMgr: connmgr.NewConnManager(config.LowWater, config.HighWater, config.GracePeriod),
+		conns:        map[libp2p_network.Conn]*prunableConn{},
+	}
+	for shardID, peers := range config.ProtectedPeers {
+		for _, s := range peers {
+			id, err := libp2p_peer.Decode(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid protected peer ID %#v of shard %d", s, shardID)
+			}
+			cm.Protect(id, shardPeerTag(shardID))
+		}
+	}
+	return cm, nil
+}
+
+// Notifee returns the notifee tracking the connections for the connection
+// manager
+func (cm *connManager) Notifee() libp2p_network.Notifiee {
+	return &connManagerNotifee{cm.BasicConnMgr.Notifee(), cm}
+}
+
+// prunableConn is a connection tracked by the connection manager, which only
+// closes it to prune it
+type prunableConn struct {
+	libp2p_network.Conn
+	pruned sync.Once
+}
+
+// Close counts the connection as pruned and closes it
+func (c *prunableConn) Close() error {
+	c.pruned.Do(func() {
+		prunedConnectionsCounter.Inc(c.Stat().Direction.String())
+	})
+	return c.Conn.Close()
+}
+
+// connManagerNotifee forwards the connection events to the connection
+// manager with the wrapped connections
+type connManagerNotifee struct {
+	libp2p_network.Notifiee
+	cm *connManager
+}
+
+func (n *connManagerNotifee) Connected(net libp2p_network.Network, conn libp2p_network.Conn) {
+	wrapped := &prunableConn{Conn: conn}
+	n.cm.lock.Lock()
+	n.cm.conns[conn] = wrapped
+	n.cm.lock.Unlock()
+	n.Notifiee.Connected(net, wrapped)
+}
+
+func (n *connManagerNotifee) Disconnected(net libp2p_network.Network, conn libp2p_network.Conn) {
+	n.cm.lock.Lock()
+	wrapped, ok := n.cm.conns[conn]
+	delete(n.cm.conns, conn)
+	n.cm.lock.Unlock()
+	if ok {
+		n.Notifiee.Disconnected(net, wrapped)
+	}
+}
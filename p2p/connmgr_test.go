@@ -0,0 +1,84 @@
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_crypto "github.com/libp2p/go-libp2p-core/crypto"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// testConn is a connection to a peer which records whether it was closed
+type testConn struct {
+	libp2p_network.Conn
+	peer   libp2p_peer.ID
+	addr   ma.Multiaddr
+	dir    libp2p_network.Direction
+	closed bool
+}
+
+func (c *testConn) RemotePeer() libp2p_peer.ID    { return c.peer }
+func (c *testConn) RemoteMultiaddr() ma.Multiaddr { return c.addr }
+func (c *testConn) Stat() libp2p_network.Stat     { return libp2p_network.Stat{Direction: c.dir} }
+func (c *testConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func newTestPeerID(t *testing.T) libp2p_peer.ID {
+	_, key, err := libp2p_crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := libp2p_peer.IDFromPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestConnManager(t *testing.T) {
+	protected := &testConn{peer: newTestPeerID(t)}
+	conns := []*testConn{{peer: newTestPeerID(t)}, {peer: newTestPeerID(t)}}
+	cm, err := newConnManager(nodeconfig.ConnManagerConfig{
+		LowWater:       1,
+		HighWater:      1,
+		ProtectedPeers: map[uint32][]string{1: {protected.peer.Pretty()}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.Close()
+	notifee := cm.Notifee()
+	notifee.Connected(nil, protected)
+	for _, conn := range conns {
+		notifee.Connected(nil, conn)
+	}
+	time.Sleep(time.Millisecond)
+	// the unprotected connections are pruned down to the low watermark
+	cm.TrimOpenConns(context.Background())
+	if protected.closed || conns[0].closed == conns[1].closed {
+		t.Errorf("got closed %v %v %v, expect one unprotected peer pruned",
+			protected.closed, conns[0].closed, conns[1].closed,
+		)
+	}
+	for _, conn := range conns {
+		if conn.closed {
+			notifee.Disconnected(nil, conn)
+		}
+	}
+	if count := cm.GetInfo().ConnCount; count != 2 {
+		t.Errorf("got %d connections, expect the pruned one untracked", count)
+	}
+
+	if _, err := newConnManager(nodeconfig.ConnManagerConfig{
+		ProtectedPeers: map[uint32][]string{0: {"invalid"}},
+	}); err == nil {
+		t.Error("expect an invalid protected peer ID refused")
+	}
+}
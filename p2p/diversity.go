@@ -0,0 +1,362 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/pkg/errors"
+)
+
+// Constants of the anchor peers. The outbound peers connected for
+// anchorMinAge are the anchors, reconnected on restart and never pruned.
+const (
+	anchorMinAge       = time.Hour
+	anchorSaveInterval = 5 * time.Minute
+	anchorTag          = "anchor"
+)
+
+// asnRange is a range of addresses announced by an autonomous system
+type asnRange struct {
+	subnet *net.IPNet
+	asn    uint32
+}
+
+// loadASNMap loads the autonomous systems of the address ranges from the file
+// at path, with a range and its AS number per line, e.g. 1.2.0.0/16 1234
+func loadASNMap(path string) ([]asnRange, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open ASN map")
+	}
+	defer f.Close()
+	var ranges []asnRange
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d of ASN map %s is not a range and an AS number", line, path)
+		}
+		_, subnet, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid range on line %d of ASN map %s", line, path)
+		}
+		asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(fields[1]), "AS"), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid AS number on line %d of ASN map %s", line, path)
+		}
+		ranges = append(ranges, asnRange{subnet, uint32(asn)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "cannot read ASN map")
+	}
+	// the most specific range of an address wins
+	sort.SliceStable(ranges, func(i, j int) bool {
+		left, _ := ranges[i].subnet.Mask.Size()
+		right, _ := ranges[j].subnet.Mask.Size()
+		return left > right
+	})
+	return ranges, nil
+}
+
+// outboundConn is an outbound connection counted in its network group
+type outboundConn struct {
+	group  string
+	opened time.Time
+}
+
+// anchorPeer is an anchor peer saved in the anchors file
+type anchorPeer struct {
+	ID    libp2p_peer.ID `json:"id"`
+	Addrs []string       `json:"addrs"`
+}
+
+// peerDiversity spreads the outbound connections across network groups, the
+// autonomous systems of the ASN map or else the /16 IPv4 and /32 IPv6
+// subnets, so that a single actor cannot hold all the outbound connections
+// of the node. The trusted and anchor peers are exempted from the limit.
+type peerDiversity struct {
+	lock        sync.Mutex
+	maxPerGroup int // outbound connections of a group, 0 for no limit
+	asns        []asnRange
+	groups      map[string]int
+	outbound    map[libp2p_network.Conn]outboundConn
+	reserved    map[libp2p_peer.ID]struct{} // trusted peers
+	anchors     map[libp2p_peer.ID]anchorPeer
+	maxAnchors  int
+	path        string // file of the anchors, empty to keep them in memory
+	now         func() time.Time
+}
+
+// newPeerDiversity loads the ASN map and the anchors saved by the last run
+func newPeerDiversity(config nodeconfig.PeerDiversityConfig) (*peerDiversity, error) {
+	asns, err := loadASNMap(config.ASNMapFile)
+	if err != nil {
+		return nil, err
+	}
+	d := &peerDiversity{
+		maxPerGroup: config.MaxOutboundPerGroup,
+		asns:        asns,
+		groups:      map[string]int{},
+		outbound:    map[libp2p_network.Conn]outboundConn{},
+		reserved:    map[libp2p_peer.ID]struct{}{},
+		anchors:     map[libp2p_peer.ID]anchorPeer{},
+		maxAnchors:  config.Anchors,
+		path:        config.AnchorsPath,
+		now:         time.Now,
+	}
+	if d.path == "" {
+		return d, nil
+	}
+	data, err := ioutil.ReadFile(d.path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read anchor peers")
+	}
+	var anchors []anchorPeer
+	if err := json.Unmarshal(data, &anchors); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode anchor peers in %s", d.path)
+	}
+	for _, anchor := range anchors {
+		if len(d.anchors) < d.maxAnchors {
+			d.anchors[anchor.ID] = anchor
+		}
+	}
+	return d, nil
+}
+
+// group returns the network group of the address, empty for the addresses
+// without IP such as relayed addresses
+func (d *peerDiversity) group(addr ma.Multiaddr) string {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return ""
+	}
+	for _, r := range d.asns {
+		if r.subnet.Contains(ip) {
+			return "AS" + strconv.FormatUint(uint64(r.asn), 10)
+		}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(16, 32)).String() + "/16"
+	}
+	return ip.Mask(net.CIDRMask(32, 128)).String() + "/32"
+}
+
+// allowsDial returns whether dialing the peer at the address keeps the
+// outbound connections within the limit of its network group
+func (d *peerDiversity) allowsDial(id libp2p_peer.ID, addr ma.Multiaddr) bool {
+	if d == nil || d.maxPerGroup <= 0 {
+		return true
+	}
+	group := d.group(addr)
+	if group == "" {
+		return true
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if _, ok := d.reserved[id]; ok {
+		return true
+	}
+	if _, ok := d.anchors[id]; ok {
+		return true
+	}
+	if d.groups[group] < d.maxPerGroup {
+		return true
+	}
+	diverseDialsRefusedCounter.Inc()
+	return false
+}
+
+// reserve exempts the trusted peer from the limit of its network group
+func (d *peerDiversity) reserve(id libp2p_peer.ID) {
+	d.lock.Lock()
+	d.reserved[id] = struct{}{}
+	d.lock.Unlock()
+}
+
+// release puts the peer back under the limit of its network group
+func (d *peerDiversity) release(id libp2p_peer.ID) {
+	d.lock.Lock()
+	delete(d.reserved, id)
+	d.lock.Unlock()
+}
+
+// notifiee counts the outbound connections of each network group
+func (d *peerDiversity) notifiee() libp2p_network.Notifiee {
+	return &libp2p_network.NotifyBundle{
+		ConnectedF: func(_ libp2p_network.Network, conn libp2p_network.Conn) {
+			if conn.Stat().Direction != libp2p_network.DirOutbound {
+				return
+			}
+			group := d.group(conn.RemoteMultiaddr())
+			d.lock.Lock()
+			d.outbound[conn] = outboundConn{group, d.now()}
+			d.groups[group]++
+			d.lock.Unlock()
+		},
+		DisconnectedF: func(_ libp2p_network.Network, conn libp2p_network.Conn) {
+			d.lock.Lock()
+			if c, ok := d.outbound[conn]; ok {
+				delete(d.outbound, conn)
+				if d.groups[c.group]--; d.groups[c.group] <= 0 {
+					delete(d.groups, c.group)
+				}
+			}
+			d.lock.Unlock()
+		},
+	}
+}
+
+// selectAnchors picks the anchors among the outbound peers connected for
+// anchorMinAge, the longest connected first with a single peer per network
+// group. The anchors still connected are kept. It returns the anchors added
+// and the anchors dropped.
+func (d *peerDiversity) selectAnchors() (added, dropped []libp2p_peer.ID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.now()
+	type candidate struct {
+		anchor anchorPeer
+		group  string
+		opened time.Time
+	}
+	connected := map[libp2p_peer.ID]candidate{}
+	for conn, c := range d.outbound {
+		id := conn.RemotePeer()
+		if prev, ok := connected[id]; ok && !c.opened.Before(prev.opened) {
+			continue
+		}
+		connected[id] = candidate{
+			anchorPeer{id, []string{conn.RemoteMultiaddr().String()}}, c.group, c.opened,
+		}
+	}
+
+	anchors := map[libp2p_peer.ID]anchorPeer{}
+	groups := map[string]struct{}{}
+	for id, anchor := range d.anchors {
+		if c, ok := connected[id]; ok {
+			anchors[id] = anchor
+			groups[c.group] = struct{}{}
+		} else {
+			dropped = append(dropped, id)
+		}
+	}
+	candidates := make([]candidate, 0, len(connected))
+	for id, c := range connected {
+		if _, ok := anchors[id]; !ok && now.Sub(c.opened) >= anchorMinAge {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].opened.Before(candidates[j].opened)
+	})
+	for _, c := range candidates {
+		if len(anchors) >= d.maxAnchors {
+			break
+		}
+		if _, ok := groups[c.group]; ok && c.group != "" {
+			continue
+		}
+		anchors[c.anchor.ID] = c.anchor
+		groups[c.group] = struct{}{}
+		added = append(added, c.anchor.ID)
+	}
+	d.anchors = anchors
+	return added, dropped
+}
+
+// listAnchors returns the anchors sorted by peer ID
+func (d *peerDiversity) listAnchors() []anchorPeer {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	anchors := make([]anchorPeer, 0, len(d.anchors))
+	for _, anchor := range d.anchors {
+		anchors = append(anchors, anchor)
+	}
+	sort.Slice(anchors, func(i, j int) bool {
+		return anchors[i].ID < anchors[j].ID
+	})
+	return anchors
+}
+
+// saveAnchors writes the anchors to the anchors file
+func (d *peerDiversity) saveAnchors() error {
+	if d.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(d.listAnchors(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0700); err != nil {
+		return errors.Wrapf(err, "cannot create anchor peer directory")
+	}
+	tmp := d.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "cannot write anchor peers")
+	}
+	return os.Rename(tmp, d.path)
+}
+
+// connectAnchors connects to the anchors saved by the last run, protecting
+// them from the connection manager
+func (host *HostV2) connectAnchors() {
+	for _, anchor := range host.diversity.listAnchors() {
+		info := libp2p_peer.AddrInfo{ID: anchor.ID}
+		for _, s := range anchor.Addrs {
+			if addr, err := ma.NewMultiaddr(s); err == nil {
+				info.Addrs = append(info.Addrs, addr)
+			}
+		}
+		host.h.ConnManager().Protect(info.ID, anchorTag)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+			defer cancel()
+			if err := host.h.Connect(ctx, info); err != nil {
+				host.logger.Info().Err(err).Str("peer", info.ID.Pretty()).
+					Msg("cannot connect to anchor peer")
+			}
+		}()
+	}
+}
+
+// updateAnchors periodically selects the anchors among the outbound peers
+// and saves them
+func (host *HostV2) updateAnchors() {
+	for range time.Tick(anchorSaveInterval) {
+		added, dropped := host.diversity.selectAnchors()
+		for _, id := range dropped {
+			host.h.ConnManager().Unprotect(id, anchorTag)
+		}
+		for _, id := range added {
+			host.h.ConnManager().Protect(id, anchorTag)
+			host.logger.Info().Str("peer", id.Pretty()).Msg("added anchor peer")
+		}
+		if err := host.diversity.saveAnchors(); err != nil {
+			host.logger.Warn().Err(err).Msg("cannot save anchor peers")
+		}
+	}
+}
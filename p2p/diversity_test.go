@@ -0,0 +1,133 @@
+package p2p
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestNetworkGroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diversity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	asnMap := filepath.Join(dir, "asn")
+	content := "# ranges\n1.2.0.0/16 AS100\n1.2.3.0/24 200\n"
+	if err := ioutil.WriteFile(asnMap, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := newPeerDiversity(nodeconfig.PeerDiversityConfig{ASNMapFile: asnMap})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr  string
+		group string
+	}{
+		{"/ip4/1.2.3.4/tcp/9000", "AS200"},
+		{"/ip4/1.2.4.4/tcp/9000", "AS100"},
+		{"/ip4/5.6.7.8/tcp/9000", "5.6.0.0/16"},
+		{"/ip6/2001:db8:1:2::1/tcp/9000", "2001:db8::/32"},
+		{"/dns4/example.com/tcp/9000", ""},
+	}
+	for i, test := range tests {
+		if group := d.group(ma.StringCast(test.addr)); group != test.group {
+			t.Errorf("index %d: got %s, expect %s", i, group, test.group)
+		}
+	}
+
+	if err := ioutil.WriteFile(asnMap, []byte("1.2.0.0/16\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadASNMap(asnMap); err == nil {
+		t.Error("expect a range without AS number refused")
+	}
+}
+
+func TestPeerDiversity(t *testing.T) {
+	d, err := newPeerDiversity(nodeconfig.PeerDiversityConfig{MaxOutboundPerGroup: 1, Anchors: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	addr := ma.StringCast("/ip4/5.6.7.8/tcp/9000")
+	conn := &testConn{peer: newTestPeerID(t), addr: addr, dir: libp2p_network.DirOutbound}
+	inbound := &testConn{peer: newTestPeerID(t), addr: addr, dir: libp2p_network.DirInbound}
+	notifiee := d.notifiee()
+	notifiee.Connected(nil, conn)
+	notifiee.Connected(nil, inbound)
+
+	other, trusted := newTestPeerID(t), newTestPeerID(t)
+	if d.allowsDial(other, ma.StringCast("/ip4/5.6.1.1/tcp/9000")) {
+		t.Error("expect the dial over the outbound connections of the group refused")
+	}
+	if !d.allowsDial(other, ma.StringCast("/ip4/5.7.1.1/tcp/9000")) {
+		t.Error("expect the dial of another group allowed")
+	}
+	d.reserve(trusted)
+	if !d.allowsDial(trusted, ma.StringCast("/ip4/5.6.1.1/tcp/9000")) {
+		t.Error("expect the dial of a trusted peer allowed")
+	}
+
+	// the outbound peer becomes an anchor once connected long enough
+	if added, _ := d.selectAnchors(); len(added) != 0 {
+		t.Errorf("got anchors %v, expect none", added)
+	}
+	now = now.Add(anchorMinAge)
+	if added, _ := d.selectAnchors(); len(added) != 1 || added[0] != conn.peer {
+		t.Errorf("got anchors %v, expect the outbound peer", added)
+	}
+	notifiee.Disconnected(nil, conn)
+	if !d.allowsDial(other, ma.StringCast("/ip4/5.6.1.1/tcp/9000")) {
+		t.Error("expect the dial allowed after the disconnection")
+	}
+	if _, dropped := d.selectAnchors(); len(dropped) != 1 || dropped[0] != conn.peer {
+		t.Errorf("got dropped anchors %v, expect the disconnected peer", dropped)
+	}
+}
+
+func TestSaveAnchors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "anchors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := nodeconfig.PeerDiversityConfig{Anchors: 2, AnchorsPath: filepath.Join(dir, "anchors.json")}
+	d, err := newPeerDiversity(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	d.now = func() time.Time { return now.Add(-anchorMinAge) }
+	notifiee := d.notifiee()
+	notifiee.Connected(nil, &testConn{
+		peer: newTestPeerID(t), addr: ma.StringCast("/ip4/1.1.1.1/tcp/9000"), dir: libp2p_network.DirOutbound,
+	})
+	notifiee.Connected(nil, &testConn{
+		peer: newTestPeerID(t), addr: ma.StringCast("/ip4/2.2.2.2/tcp/9000"), dir: libp2p_network.DirOutbound,
+	})
+	d.now = func() time.Time { return now }
+	if added, _ := d.selectAnchors(); len(added) != 2 {
+		t.Fatalf("got anchors %v, expect 2", added)
+	}
+	if err := d.saveAnchors(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := newPeerDiversity(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anchors, saved := loaded.listAnchors(), d.listAnchors()
+	if len(anchors) != 2 || anchors[0].ID != saved[0].ID || anchors[1].Addrs[0] != saved[1].Addrs[0] {
+		t.Errorf("got anchors %v, expect %v", anchors, saved)
+	}
+}
@@ -0,0 +1,144 @@
+package p2p
+
+import (
+	"net"
+	"os"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p "github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/control"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/pkg/errors"
+)
+
+// allowlist restricts the connections to the listed peers and subnets, the
+// peer IDs are verified by the secure channel of the connection
+type allowlist struct {
+	peers   map[libp2p_peer.ID]struct{}
+	subnets []*net.IPNet
+}
+
+// newAllowlist parses the allowlist of config, nil when it is disabled
+func newAllowlist(config nodeconfig.AllowlistConfig) (*allowlist, error) {
+	if len(config.Peers) == 0 && len(config.Subnets) == 0 {
+		return nil, nil
+	}
+	a := &allowlist{peers: map[libp2p_peer.ID]struct{}{}}
+	for _, s := range config.Peers {
+		id, err := libp2p_peer.Decode(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid allowlisted peer ID %#v", s)
+		}
+		a.peers[id] = struct{}{}
+	}
+	for _, s := range config.Subnets {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid allowlisted subnet %#v", s)
+		}
+		a.subnets = append(a.subnets, subnet)
+	}
+	return a, nil
+}
+
+// allowsPeer returns whether the peer is allowlisted by its ID
+func (a *allowlist) allowsPeer(id libp2p_peer.ID) bool {
+	_, ok := a.peers[id]
+	return ok
+}
+
+// allowsAddr returns whether the address is in an allowlisted subnet
+func (a *allowlist) allowsAddr(addr ma.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+	for _, subnet := range a.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows returns whether the peer at the address is allowlisted
+func (a *allowlist) allows(id libp2p_peer.ID, addr ma.Multiaddr) bool {
+	return a.allowsPeer(id) || a.allowsAddr(addr)
+}
+
+// connectionGater refuses the connections of the banned peers and, in
+// allowlist mode, of the peers not allowlisted, both when dialing and when
+// accepting them. It refuses the dials over the outbound connections of a
+// network group.
+type connectionGater struct {
+	reputation *reputationStore
+	allowlist  *allowlist     // nil to allow all peers
+	diversity  *peerDiversity // nil to dial any network group
+}
+
+// InterceptPeerDial refuses to dial banned peers, and peers not allowlisted
+// by their ID when there are no allowlisted subnets their address could be in
+func (g *connectionGater) InterceptPeerDial(id libp2p_peer.ID) bool {
+	if g.reputation.banned(id) {
+		return false
+	}
+	return g.allowlist == nil || len(g.allowlist.subnets) > 0 || g.allowlist.allowsPeer(id)
+}
+
+// InterceptAddrDial refuses to dial the addresses of peers not allowlisted,
+// and the addresses of network groups holding their share of the outbound
+// connections
+func (g *connectionGater) InterceptAddrDial(id libp2p_peer.ID, addr ma.Multiaddr) bool {
+	if g.allowlist != nil && !g.allowlist.allows(id, addr) {
+		return false
+	}
+	return g.diversity.allowsDial(id, addr)
+}
+
+// InterceptAccept refuses the inbound connections from outside the
+// allowlisted subnets when no peer is allowlisted by its ID
+func (g *connectionGater) InterceptAccept(addrs libp2p_network.ConnMultiaddrs) bool {
+	if g.allowlist == nil || len(g.allowlist.peers) > 0 {
+		return true
+	}
+	return g.allowlist.allowsAddr(addrs.RemoteMultiaddr())
+}
+
+// InterceptSecured refuses the connections of banned and not allowlisted
+// peers once their peer ID is authenticated
+func (g *connectionGater) InterceptSecured(
+	_ libp2p_network.Direction, id libp2p_peer.ID, addrs libp2p_network.ConnMultiaddrs,
+) bool {
+	if g.reputation.banned(id) {
+		return false
+	}
+	return g.allowlist == nil || g.allowlist.allows(id, addrs.RemoteMultiaddr())
+}
+
+// InterceptUpgraded allows all secured connections
+func (g *connectionGater) InterceptUpgraded(libp2p_network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+// privateNetworkOptions returns the libp2p option of the private network
+// whose pre-shared key is in the swarm key file at path, no option when the
+// path is empty
+func privateNetworkOptions(path string) ([]libp2p.Option, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open swarm key")
+	}
+	defer f.Close()
+	psk, err := pnet.DecodeV1PSK(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decode swarm key %s", path)
+	}
+	return []libp2p.Option{libp2p.PrivateNetwork(psk)}, nil
+}
@@ -0,0 +1,114 @@
+package p2p
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestNewAllowlist(t *testing.T) {
+	const id = "QmRG8cJkjY6WbHpiRsxoMRqRmuUJzFJ5Ge9sCkVGNQxHEp"
+	tests := []struct {
+		config   nodeconfig.AllowlistConfig
+		disabled bool
+		ok       bool
+	}{
+		{nodeconfig.AllowlistConfig{}, true, true},
+		{nodeconfig.AllowlistConfig{Peers: []string{id}}, false, true},
+		{nodeconfig.AllowlistConfig{Subnets: []string{"10.0.0.0/8"}}, false, true},
+		{nodeconfig.AllowlistConfig{Peers: []string{"peer"}}, false, false},
+		{nodeconfig.AllowlistConfig{Subnets: []string{"10.0.0.0"}}, false, false},
+	}
+	for i, test := range tests {
+		a, err := newAllowlist(test.config)
+		if (err == nil) != test.ok || (err == nil && (a == nil) != test.disabled) {
+			t.Errorf("index %d: got allowlist %v error %v, expect disabled %v ok %v",
+				i, a, err, test.disabled, test.ok,
+			)
+		}
+	}
+}
+
+func TestConnectionGater(t *testing.T) {
+	allowed, err := libp2p_peer.Decode("QmRG8cJkjY6WbHpiRsxoMRqRmuUJzFJ5Ge9sCkVGNQxHEp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := libp2p_peer.ID("other")
+	inside := ma.StringCast("/ip4/10.1.2.3/tcp/9000")
+	outside := ma.StringCast("/ip4/1.2.3.4/tcp/9000")
+	reputation, _ := newReputationStore("")
+
+	open := &connectionGater{reputation: reputation}
+	if !open.InterceptPeerDial(other) || !open.InterceptAddrDial(other, outside) {
+		t.Error("gater without allowlist refused a peer")
+	}
+
+	a, err := newAllowlist(nodeconfig.AllowlistConfig{
+		Peers:   []string{allowed.Pretty()},
+		Subnets: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &connectionGater{reputation: reputation, allowlist: a}
+	tests := []struct {
+		id      libp2p_peer.ID
+		addr    ma.Multiaddr
+		allowed bool
+	}{
+		{allowed, outside, true},
+		{allowed, inside, true},
+		{other, inside, true},
+		{other, outside, false},
+	}
+	for i, test := range tests {
+		if got := g.InterceptAddrDial(test.id, test.addr); got != test.allowed {
+			t.Errorf("index %d: got dial allowed %v, expect %v", i, got, test.allowed)
+		}
+	}
+
+	peersOnly, _ := newAllowlist(nodeconfig.AllowlistConfig{Peers: []string{allowed.Pretty()}})
+	g = &connectionGater{reputation: reputation, allowlist: peersOnly}
+	if !g.InterceptPeerDial(allowed) || g.InterceptPeerDial(other) {
+		t.Error("gater of allowlisted peers dialed the wrong peers")
+	}
+
+	for i := 0; i < reputationBanScore/offensePenalties[OffenseInvalidSender]; i++ {
+		reputation.report(allowed, OffenseInvalidSender)
+	}
+	if g.InterceptPeerDial(allowed) {
+		t.Error("gater dialed a banned allowlisted peer")
+	}
+}
+
+func TestPrivateNetworkOptions(t *testing.T) {
+	if options, err := privateNetworkOptions(""); err != nil || len(options) != 0 {
+		t.Errorf("got %d options %v for the public network", len(options), err)
+	}
+	dir, err := ioutil.TempDir("", "swarm-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "swarm.key")
+	key := "/key/swarm/psk/1.0.0/\n/base16/\n" +
+		"4c1e6e1b7bd2b2bfb4b1fb0d4d84d4bb6ad08b0e3dbbf8a0e2e4c3b4a6e9b2c1\n"
+	if err := ioutil.WriteFile(path, []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if options, err := privateNetworkOptions(path); err != nil || len(options) != 1 {
+		t.Errorf("got %d options %v for the swarm key", len(options), err)
+	}
+	if err := ioutil.WriteFile(path, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := privateNetworkOptions(path); err == nil {
+		t.Error("decoded an invalid swarm key")
+	}
+}
@@ -0,0 +1,260 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/helpers"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
+)
+
+// Constants of the handshake. The dialing side of a new connection opens
+// a handshake stream and sends its handshake, the other side replies with its
+// own handshake and the reason it rejects the dialing side if it does. Both
+// sides close the connection to an incompatible peer.
+const (
+	handshakeProtocol  = protocol.ID("/harmony/handshake/1.0.0")
+	handshakeTimeout   = 10 * time.Second
+	maxHandshakeSize   = 64 * 1024
+	rejectedCloseDelay = time.Second
+	handshakePeerstore = "harmony/handshake"
+)
+
+// Results of the handshakes in the metrics
+const (
+	handshakeAccepted    = "accepted"
+	handshakeRejected    = "rejected"
+	handshakeFailed      = "failed"
+	handshakeUnsupported = "unsupported"
+)
+
+// HeadState is the head of the chain of a shard, with the blocks and states
+// the node serves to syncing peers
+type HeadState struct {
+	ShardID     uint32 `json:"shardID"`
+	Genesis     string `json:"genesis"`
+	Number      uint64 `json:"number"`
+	Hash        string `json:"hash"`
+	OldestBlock uint64 `json:"oldestBlock"` // oldest block with its body and receipts, 0 for all blocks
+	Archival    bool   `json:"archival"`    // the states of all blocks are kept
+	SnapServer  bool   `json:"snapServer"`  // the state ranges of snap sync are served
+}
+
+// ServesBlock returns whether the body and receipts of the block are served
+func (head HeadState) ServesBlock(number uint64) bool {
+	return number >= head.OldestBlock
+}
+
+// Handshake is the status exchanged with the peers on connection, so that
+// incompatible peers are disconnected right away
+type Handshake struct {
+	Network            string      `json:"network"`
+	Version            string      `json:"version"`
+	ConsensusProtocols []int       `json:"consensusProtocols"`
+	SyncProtocols      []string    `json:"syncProtocols"`
+	Heads              []HeadState `json:"heads"`
+}
+
+// Head returns the head of the chain of the shard, if the shard is served
+func (h Handshake) Head(shardID uint32) (HeadState, bool) {
+	for _, head := range h.Heads {
+		if head.ShardID == shardID {
+			return head, true
+		}
+	}
+	return HeadState{}, false
+}
+
+// handshakeMessage is the handshake sent on the handshake stream, with the
+// reason of the rejection of the peer in the reply
+type handshakeMessage struct {
+	Handshake
+	Reject string `json:"reject,omitempty"`
+}
+
+// Compatible returns why the peer with the remote handshake is incompatible,
+// nil when it is compatible. The checks are skipped for what either side
+// does not announce.
+func (local Handshake) Compatible(remote Handshake) error {
+	if local.Network != "" && remote.Network != "" && local.Network != remote.Network {
+		return errors.Errorf("network %s, expect %s", remote.Network, local.Network)
+	}
+	if !intersects(len(local.ConsensusProtocols), len(remote.ConsensusProtocols), func(i, j int) bool {
+		return local.ConsensusProtocols[i] == remote.ConsensusProtocols[j]
+	}) {
+		return errors.Errorf("consensus protocols %v, expect any of %v",
+			remote.ConsensusProtocols, local.ConsensusProtocols)
+	}
+	if !intersects(len(local.SyncProtocols), len(remote.SyncProtocols), func(i, j int) bool {
+		return local.SyncProtocols[i] == remote.SyncProtocols[j]
+	}) {
+		return errors.Errorf("sync protocols %v, expect any of %v",
+			remote.SyncProtocols, local.SyncProtocols)
+	}
+	for _, head := range local.Heads {
+		for _, remoteHead := range remote.Heads {
+			if head.ShardID == remoteHead.ShardID && head.Genesis != remoteHead.Genesis {
+				return errors.Errorf("genesis %s of shard %d, expect %s",
+					remoteHead.Genesis, head.ShardID, head.Genesis)
+			}
+		}
+	}
+	return nil
+}
+
+// intersects returns whether two lists share an element, true when either
+// list is empty
+func intersects(n, m int, equal func(i, j int) bool) bool {
+	if n == 0 || m == 0 {
+		return true
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			if equal(i, j) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetHandshake sets the function returning the handshake of the node
+func (host *HostV2) SetHandshake(handshake func() Handshake) {
+	host.lock.Lock()
+	host.handshake = handshake
+	host.lock.Unlock()
+}
+
+// localHandshake returns the handshake of the node, empty until it is set
+func (host *HostV2) localHandshake() Handshake {
+	host.lock.Lock()
+	handshake := host.handshake
+	host.lock.Unlock()
+	if handshake == nil {
+		return Handshake{}
+	}
+	return handshake()
+}
+
+// PeerHandshake returns the handshake of the peer, if it completed one
+func (host *HostV2) PeerHandshake(id libp2p_peer.ID) (Handshake, bool) {
+	handshake, err := host.h.Peerstore().Get(id, handshakePeerstore)
+	if err != nil {
+		return Handshake{}, false
+	}
+	h, ok := handshake.(Handshake)
+	return h, ok
+}
+
+// handshakeNotifiee starts the handshake of the new outbound connections
+func (host *HostV2) handshakeNotifiee() libp2p_network.Notifiee {
+	return &libp2p_network.NotifyBundle{
+		ConnectedF: func(n libp2p_network.Network, conn libp2p_network.Conn) {
+			if conn.Stat().Direction == libp2p_network.DirOutbound && len(n.ConnsToPeer(conn.RemotePeer())) == 1 {
+				go host.sendHandshake(conn.RemotePeer())
+			}
+		},
+	}
+}
+
+// sendHandshake sends the handshake of the node to the peer, and closes
+// the connection when the peer rejects it or is incompatible. The peers
+// without the handshake protocol are kept.
+func (host *HostV2) sendHandshake(id libp2p_peer.ID) {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	stream, err := host.h.NewStream(ctx, id, handshakeProtocol)
+	if err == libp2p_network.ErrNoConn {
+		return
+	}
+	if err != nil {
+		handshakesCounter.Inc(handshakeUnsupported)
+		host.logger.Debug().Err(err).Str("peer", id.Pretty()).Msg("no handshake with peer")
+		return
+	}
+	stream.SetDeadline(time.Now().Add(handshakeTimeout))
+	local := host.localHandshake()
+	var reply handshakeMessage
+	if err := json.NewEncoder(stream).Encode(handshakeMessage{Handshake: local}); err != nil {
+		host.failHandshake(stream, id, err)
+		return
+	}
+	if err := json.NewDecoder(io.LimitReader(stream, maxHandshakeSize)).Decode(&reply); err != nil {
+		host.failHandshake(stream, id, err)
+		return
+	}
+	go helpers.FullClose(stream)
+
+	reason := reply.Reject
+	if reason == "" {
+		if err := local.Compatible(reply.Handshake); err != nil {
+			reason = err.Error()
+		}
+	}
+	host.completeHandshake(id, reply.Handshake, reason)
+}
+
+// handleHandshake replies to the handshake of a peer with the handshake of
+// the node, and the reason it rejects the peer if it is incompatible
+func (host *HostV2) handleHandshake(stream libp2p_network.Stream) {
+	id := stream.Conn().RemotePeer()
+	stream.SetDeadline(time.Now().Add(handshakeTimeout))
+	var remote handshakeMessage
+	if err := json.NewDecoder(io.LimitReader(stream, maxHandshakeSize)).Decode(&remote); err != nil {
+		host.failHandshake(stream, id, err)
+		return
+	}
+	local := host.localHandshake()
+	reply := handshakeMessage{Handshake: local}
+	if err := local.Compatible(remote.Handshake); err != nil {
+		reply.Reject = err.Error()
+	}
+	if err := json.NewEncoder(stream).Encode(reply); err != nil {
+		host.failHandshake(stream, id, err)
+		return
+	}
+	go helpers.FullClose(stream)
+	host.completeHandshake(id, remote.Handshake, reply.Reject)
+}
+
+// failHandshake resets the handshake stream of a peer which did not
+// complete the handshake, keeping its connection
+func (host *HostV2) failHandshake(stream libp2p_network.Stream, id libp2p_peer.ID, err error) {
+	stream.Reset()
+	handshakesCounter.Inc(handshakeFailed)
+	host.logger.Debug().Err(err).Str("peer", id.Pretty()).Msg("cannot complete handshake with peer")
+}
+
+// completeHandshake records the handshake of the peer, and disconnects from
+// the peer when it is rejected for the given reason. The trusted peers are
+// kept, they have to be removed from the trusted peers first.
+func (host *HostV2) completeHandshake(id libp2p_peer.ID, remote Handshake, reason string) {
+	host.h.Peerstore().Put(id, handshakePeerstore, remote)
+	if reason == "" {
+		handshakesCounter.Inc(handshakeAccepted)
+		return
+	}
+	handshakesCounter.Inc(handshakeRejected)
+	if host.IsTrustedPeer(id) {
+		host.logger.Warn().
+			Str("peer", id.Pretty()).
+			Str("version", remote.Version).
+			Str("reason", reason).
+			Msg("incompatible trusted peer")
+		return
+	}
+	host.logger.Info().
+		Str("peer", id.Pretty()).
+		Str("version", remote.Version).
+		Str("reason", reason).
+		Msg("disconnecting incompatible peer")
+	// leave the time for the rejection to reach the peer
+	time.AfterFunc(rejectedCloseDelay, func() {
+		host.h.Network().ClosePeer(id)
+	})
+}
@@ -0,0 +1,33 @@
+package p2p
+
+import (
+	"testing"
+)
+
+func TestHandshakeCompatible(t *testing.T) {
+	local := Handshake{
+		Network:            "mainnet",
+		ConsensusProtocols: []int{1, 2},
+		SyncProtocols:      []string{"1.0.0"},
+		Heads:              []HeadState{{ShardID: 0, Genesis: "0xa"}, {ShardID: 1, Genesis: "0xb"}},
+	}
+	tests := []struct {
+		remote     Handshake
+		compatible bool
+	}{
+		{Handshake{}, true},
+		{local, true},
+		{Handshake{Network: "testnet"}, false},
+		{Handshake{ConsensusProtocols: []int{2, 3}}, true},
+		{Handshake{ConsensusProtocols: []int{3}}, false},
+		{Handshake{SyncProtocols: []string{"2.0.0"}}, false},
+		{Handshake{Heads: []HeadState{{ShardID: 1, Genesis: "0xb"}, {ShardID: 2, Genesis: "0xc"}}}, true},
+		{Handshake{Heads: []HeadState{{ShardID: 1, Genesis: "0xc"}}}, false},
+	}
+	for i, test := range tests {
+		err := local.Compatible(test.remote)
+		if (err == nil) != test.compatible {
+			t.Errorf("index %d: got %v, expect compatible %v", i, err, test.compatible)
+		}
+	}
+}
@@ -12,10 +12,12 @@ import (
 
 	"github.com/harmony-one/bls/ffi/go/bls"
 	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	"github.com/harmony-one/harmony/internal/metrics"
 	"github.com/harmony-one/harmony/internal/utils"
 	libp2p "github.com/libp2p/go-libp2p"
 	libp2p_crypto "github.com/libp2p/go-libp2p-core/crypto"
 	libp2p_host "github.com/libp2p/go-libp2p-core/host"
+	libp2p_metrics "github.com/libp2p/go-libp2p-core/metrics"
 	libp2p_network "github.com/libp2p/go-libp2p-core/network"
 	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
 	libp2p_peerstore "github.com/libp2p/go-libp2p-core/peerstore"
@@ -38,6 +40,22 @@ type Host interface {
 	PubSub() *libp2p_pubsub.PubSub
 	C() (int, int, int)
 	GetOrJoin(topic string) (*libp2p_pubsub.Topic, error)
+	ListPeers() []PeerInfo
+	ConnectPeer(addr ma.Multiaddr) (libp2p_peer.ID, error)
+	DisconnectPeer(id libp2p_peer.ID) error
+	AddTrustedPeer(addr ma.Multiaddr) (libp2p_peer.ID, error)
+	RemoveTrustedPeer(id libp2p_peer.ID) bool
+	Reachability() libp2p_network.Reachability
+	ReportPeer(id libp2p_peer.ID, offense Offense)
+	PeerReputations() []PeerReputation
+	ClearPeerReputation(id libp2p_peer.ID) bool
+	AllowMessage(kind string, peer libp2p_peer.ID) bool
+	RateLimitDrops() RateLimitDrops
+	SetRateLimits(limits nodeconfig.RateLimits)
+	ValidateMessage(ctx context.Context, topic string, validate ValidateFunc) libp2p_pubsub.ValidationResult
+	Topology() PeerTopology
+	SetHandshake(handshake func() Handshake)
+	PeerHandshake(id libp2p_peer.ID) (Handshake, bool)
 }
 
 // Peer is the object for a p2p peer (node)
@@ -47,6 +65,7 @@ type Peer struct {
 	ConsensusPubKey *bls.PublicKey // Public key of the peer, used for consensus signing
 	Addrs           []ma.Multiaddr // MultiAddress of the peer
 	PeerID          libp2p_peer.ID // PeerID, the pubkey for communication
+	UserAgent       string         // Agent announced to the peers, see UserAgent
 }
 
 const (
@@ -60,21 +79,53 @@ const (
 	MaxMessageSize = 1 << 21
 )
 
-// NewHost ..
-func NewHost(self *Peer, key libp2p_crypto.PrivKey) (Host, error) {
+// NewHost creates the p2p host, its gossipsub peers are scored on the given
+// topics and gossip from peers with a too low score is ignored
+func NewHost(self *Peer, key libp2p_crypto.PrivKey, topics ...ScoredTopic) (Host, error) {
 	listenAddr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/0.0.0.0/tcp/%s", self.Port))
 	if err != nil {
 		return nil, errors.Wrapf(err,
 			"cannot create listen multiaddr from port %#v", self.Port)
 	}
 
+	natOptions, err := natOptions(nodeconfig.GetNATConfig())
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot configure NAT traversal")
+	}
+	reputation, err := newReputationStore(nodeconfig.GetPeerReputationPath())
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := newAllowlist(nodeconfig.GetAllowlistConfig())
+	if err != nil {
+		return nil, err
+	}
+	privateNetwork, err := privateNetworkOptions(nodeconfig.GetAllowlistConfig().SwarmKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	connManager, err := newConnManager(nodeconfig.GetConnManagerConfig())
+	if err != nil {
+		return nil, err
+	}
+	diversity, err := newPeerDiversity(nodeconfig.GetPeerDiversityConfig())
+	if err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
-	p2pHost, err := libp2p.New(ctx,
+	bandwidth := libp2p_metrics.NewBandwidthCounter()
+	p2pHost, err := libp2p.New(ctx, append([]libp2p.Option{
 		libp2p.ListenAddrs(listenAddr),
 		libp2p.Identity(key),
 		libp2p.EnableNATService(),
-		libp2p.ForceReachabilityPublic(),
-	)
+		libp2p.BandwidthReporter(bandwidth),
+		// the connections of the peers banned for their misbehavior or not allowlisted are refused,
+		// and the dials over the outbound connections of a network group
+		libp2p.ConnectionGater(&connectionGater{reputation, allowlist, diversity}),
+		// the connections over the high watermark are pruned, except for the protected peers
+		libp2p.ConnectionManager(connManager),
+	}, append(append(natOptions, privateNetwork...), userAgentOptions(self.UserAgent)...)...)...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot initialize libp2p host")
 	}
@@ -90,10 +141,16 @@ func NewHost(self *Peer, key libp2p_crypto.PrivKey) (Host, error) {
 		// WithMaxMessageSize sets the global maximum message size for pubsub wire messages. The default value is 1MiB (DefaultMaxMessageSize).
 		libp2p_pubsub.WithMaxMessageSize(MaxMessageSize),
 	}
+	if len(topics) > 0 {
+		// WithPeerScore enables the gossipsub v1.1 peer scoring, peers penalized for the messages rejected by the topic validators are pruned and graylisted.
+		options = append(options,
+			libp2p_pubsub.WithPeerScore(peerScoreParams(topics), peerScoreThresholds()),
+		)
+	}
 
+	var tracer libp2p_pubsub.EventTracer
 	traceFile := os.Getenv("P2P_TRACEFILE")
 	if len(traceFile) > 0 {
-		var tracer libp2p_pubsub.EventTracer
 		var tracerErr error
 		if strings.HasPrefix(traceFile, "file:") {
 			tracer, tracerErr = libp2p_pubsub.NewJSONTracer(strings.TrimPrefix(traceFile, "file:"))
@@ -103,14 +160,16 @@ func NewHost(self *Peer, key libp2p_crypto.PrivKey) (Host, error) {
 				tracer, tracerErr = libp2p_pubsub.NewRemoteTracer(ctx, p2pHost, *pi)
 			}
 		}
-		if tracerErr == nil && tracer != nil {
-			options = append(options, libp2p_pubsub.WithEventTracer(tracer))
-		} else {
+		if tracerErr != nil || tracer == nil {
+			tracer = nil
 			utils.Logger().Warn().
 				Str("Tracer", traceFile).
 				Msg("can't add event tracer from P2P_TRACEFILE")
 		}
 	}
+	// the mesh tracer follows the gossipsub meshes for the metrics, forwarding the events to the P2P_TRACEFILE tracer
+	mesh := newMeshTracer(tracer)
+	options = append(options, libp2p_pubsub.WithEventTracer(mesh))
 
 	pubsub, err := libp2p_pubsub.NewGossipSub(ctx, p2pHost, options...)
 	if err != nil {
@@ -122,15 +181,35 @@ func NewHost(self *Peer, key libp2p_crypto.PrivKey) (Host, error) {
 
 	// has to save the private key for host
 	h := &HostV2{
-		h:      p2pHost,
-		pubsub: pubsub,
-		joined: map[string]*libp2p_pubsub.Topic{},
-		self:   *self,
-		priKey: key,
-		logger: &subLogger,
+		h:           p2pHost,
+		pubsub:      pubsub,
+		joined:      map[string]*libp2p_pubsub.Topic{},
+		trusted:     map[libp2p_peer.ID]libp2p_peer.AddrInfo{},
+		self:        *self,
+		priKey:      key,
+		logger:      &subLogger,
+		reputation:  reputation,
+		rateLimiter: newRateLimiter(nodeconfig.GetRateLimits()),
+		validation:  newValidationPool(nodeconfig.GetValidationPool()),
+		mesh:        mesh,
+		bandwidth:   bandwidth,
+		diversity:   diversity,
+	}
+	p2pHost.Network().Notify(h.trustedNotifiee())
+	p2pHost.Network().Notify(diversity.notifiee())
+	p2pHost.Network().Notify(h.handshakeNotifiee())
+	p2pHost.SetStreamHandler(handshakeProtocol, h.handleHandshake)
+	p2pHost.Network().Notify(metricsNotifiee())
+	metrics.DefaultRegistry.OnCollect(h.collectMetrics)
+	if reputation.path != "" {
+		go h.saveReputations()
+	}
+	if diversity.maxAnchors > 0 {
+		h.connectAnchors()
+		go h.updateAnchors()
 	}
 
-	if err != nil {
+	if err := h.trackReachability(); err != nil {
 		return nil, err
 	}
 
@@ -144,13 +223,22 @@ func NewHost(self *Peer, key libp2p_crypto.PrivKey) (Host, error) {
 
 // HostV2 is the version 2 p2p host
 type HostV2 struct {
-	h      libp2p_host.Host
-	pubsub *libp2p_pubsub.PubSub
-	joined map[string]*libp2p_pubsub.Topic
-	self   Peer
-	priKey libp2p_crypto.PrivKey
-	lock   sync.Mutex
-	logger *zerolog.Logger
+	h            libp2p_host.Host
+	pubsub       *libp2p_pubsub.PubSub
+	joined       map[string]*libp2p_pubsub.Topic
+	trusted      map[libp2p_peer.ID]libp2p_peer.AddrInfo
+	self         Peer
+	priKey       libp2p_crypto.PrivKey
+	lock         sync.Mutex
+	logger       *zerolog.Logger
+	reachability int32 // libp2p_network.Reachability, accessed atomically
+	reputation   *reputationStore
+	rateLimiter  *rateLimiter
+	validation   *validationPool
+	mesh         *meshTracer
+	bandwidth    *libp2p_metrics.BandwidthCounter
+	diversity    *peerDiversity
+	handshake    func() Handshake
 }
 
 // PubSub ..
@@ -208,6 +296,7 @@ func (host *HostV2) SendMessageToGroups(groups []nodeconfig.GroupID, msg []byte)
 			err = e
 			continue
 		}
+		countSentMessage(string(group), len(msg))
 	}
 
 	return err
@@ -0,0 +1,189 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/harmony-one/harmony/internal/metrics"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+// Directions of the messages and connections in the metrics
+const (
+	directionIn  = "in"
+	directionOut = "out"
+)
+
+// p2p metrics exported to Prometheus
+var (
+	connectedPeersGauge = metrics.DefaultRegistry.NewGauge(
+		"p2p_connected_peers", "Peers connected to the host",
+	)
+	topicPeersGauge = metrics.DefaultRegistry.NewGauge(
+		"p2p_topic_peers", "Connected peers subscribed to the gossip topic", "topic",
+	)
+	meshPeersGauge = metrics.DefaultRegistry.NewGauge(
+		"p2p_mesh_peers", "Peers in the gossipsub mesh of the topic", "topic",
+	)
+	messagesCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_messages_total", "Gossip messages of the topic", "topic", "direction",
+	)
+	messageBytesCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_message_bytes_total", "Bytes of the gossip messages of the topic", "topic", "direction",
+	)
+	protocolBytesCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_protocol_bytes_total", "Bytes of the streams of the protocol", "protocol", "direction",
+	)
+	propagationLatencySummary = metrics.DefaultRegistry.NewSummary(
+		"p2p_propagation_latency_seconds", "Delay between the creation and the reception of gossip messages", "kind",
+	)
+	validationQueueGauge = metrics.DefaultRegistry.NewGauge(
+		"p2p_validation_queue", "Gossip messages of the topic waiting for a validation worker", "topic",
+	)
+	validationDropsCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_validation_dropped_total", "Gossip messages of the topic ignored over a full validation queue", "topic",
+	)
+	connectionsOpenedCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_connections_opened_total", "Connections opened with peers", "direction",
+	)
+	connectionsClosedCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_connections_closed_total", "Connections closed with peers", "direction",
+	)
+	diverseDialsRefusedCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_diverse_dials_refused_total", "Dials refused over the outbound connections of a network group",
+	)
+	handshakesCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_handshakes_total", "Handshakes with peers by result", "result",
+	)
+	prunedConnectionsCounter = metrics.DefaultRegistry.NewCounter(
+		"p2p_connections_pruned_total", "Connections closed by the connection manager over the high watermark", "direction",
+	)
+)
+
+// CountReceivedMessage counts a gossip message of the topic received from a peer
+func CountReceivedMessage(topic string, size int) {
+	messagesCounter.Inc(topic, directionIn)
+	messageBytesCounter.Add(float64(size), topic, directionIn)
+}
+
+// ObservePropagationLatency records the delay between the creation of a
+// gossip message of the given kind and its reception
+func ObservePropagationLatency(kind string, latency time.Duration) {
+	propagationLatencySummary.Observe(latency.Seconds(), kind)
+}
+
+// countSentMessage counts a gossip message of the topic published by the host
+func countSentMessage(topic string, size int) {
+	messagesCounter.Inc(topic, directionOut)
+	messageBytesCounter.Add(float64(size), topic, directionOut)
+}
+
+// directionOf returns the metric direction of a connection
+func directionOf(conn libp2p_network.Conn) string {
+	if conn.Stat().Direction == libp2p_network.DirOutbound {
+		return directionOut
+	}
+	return directionIn
+}
+
+// metricsNotifiee counts the connections opened and closed, the churn of
+// the connections of the host
+func metricsNotifiee() libp2p_network.Notifiee {
+	return &libp2p_network.NotifyBundle{
+		ConnectedF: func(_ libp2p_network.Network, conn libp2p_network.Conn) {
+			connectionsOpenedCounter.Inc(directionOf(conn))
+		},
+		DisconnectedF: func(_ libp2p_network.Network, conn libp2p_network.Conn) {
+			connectionsClosedCounter.Inc(directionOf(conn))
+		},
+	}
+}
+
+// meshTracer keeps the gossipsub mesh of each topic up to date with the
+// pubsub trace events, and forwards the events to the next tracer if any
+type meshTracer struct {
+	lock sync.Mutex
+	mesh map[string]map[libp2p_peer.ID]struct{}
+	next libp2p_pubsub.EventTracer
+}
+
+func newMeshTracer(next libp2p_pubsub.EventTracer) *meshTracer {
+	return &meshTracer{mesh: map[string]map[libp2p_peer.ID]struct{}{}, next: next}
+}
+
+// Trace updates the mesh with a pubsub trace event
+func (t *meshTracer) Trace(evt *pubsub_pb.TraceEvent) {
+	t.lock.Lock()
+	switch evt.GetType() {
+	case pubsub_pb.TraceEvent_GRAFT:
+		topic := evt.GetGraft().GetTopic()
+		if _, ok := t.mesh[topic]; !ok {
+			t.mesh[topic] = map[libp2p_peer.ID]struct{}{}
+		}
+		t.mesh[topic][libp2p_peer.ID(evt.GetGraft().GetPeerID())] = struct{}{}
+	case pubsub_pb.TraceEvent_PRUNE:
+		delete(t.mesh[evt.GetPrune().GetTopic()], libp2p_peer.ID(evt.GetPrune().GetPeerID()))
+	case pubsub_pb.TraceEvent_REMOVE_PEER:
+		for _, peers := range t.mesh {
+			delete(peers, libp2p_peer.ID(evt.GetRemovePeer().GetPeerID()))
+		}
+	case pubsub_pb.TraceEvent_LEAVE:
+		delete(t.mesh, evt.GetLeave().GetTopic())
+	}
+	t.lock.Unlock()
+	if t.next != nil {
+		t.next.Trace(evt)
+	}
+}
+
+// meshSizes returns the number of peers in the mesh of each topic
+func (t *meshTracer) meshSizes() map[string]int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	sizes := make(map[string]int, len(t.mesh))
+	for topic, peers := range t.mesh {
+		sizes[topic] = len(peers)
+	}
+	return sizes
+}
+
+// meshTopics returns the topics of the meshes of each peer
+func (t *meshTracer) meshTopics() map[libp2p_peer.ID][]string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	topics := map[libp2p_peer.ID][]string{}
+	for topic, peers := range t.mesh {
+		for id := range peers {
+			topics[id] = append(topics[id], topic)
+		}
+	}
+	return topics
+}
+
+// collectMetrics updates the p2p metrics read from the host
+func (host *HostV2) collectMetrics() {
+	connectedPeersGauge.Set(float64(len(host.h.Network().Peers())))
+
+	topicPeersGauge.Reset()
+	for _, topic := range host.pubsub.GetTopics() {
+		topicPeersGauge.Set(float64(len(host.pubsub.ListPeers(topic))), topic)
+	}
+
+	meshPeersGauge.Reset()
+	for topic, size := range host.mesh.meshSizes() {
+		meshPeersGauge.Set(float64(size), topic)
+	}
+
+	validationQueueGauge.Reset()
+	for topic, length := range host.validation.queueLengths() {
+		validationQueueGauge.Set(float64(length), topic)
+	}
+
+	for protocol, stats := range host.bandwidth.GetBandwidthByProtocol() {
+		protocolBytesCounter.Set(float64(stats.TotalIn), string(protocol), directionIn)
+		protocolBytesCounter.Set(float64(stats.TotalOut), string(protocol), directionOut)
+	}
+}
@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"testing"
+
+	pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+func graftEvent(peer, topic string) *pubsub_pb.TraceEvent {
+	typ := pubsub_pb.TraceEvent_GRAFT
+	return &pubsub_pb.TraceEvent{
+		Type:  &typ,
+		Graft: &pubsub_pb.TraceEvent_Graft{PeerID: []byte(peer), Topic: &topic},
+	}
+}
+
+func pruneEvent(peer, topic string) *pubsub_pb.TraceEvent {
+	typ := pubsub_pb.TraceEvent_PRUNE
+	return &pubsub_pb.TraceEvent{
+		Type:  &typ,
+		Prune: &pubsub_pb.TraceEvent_Prune{PeerID: []byte(peer), Topic: &topic},
+	}
+}
+
+func removePeerEvent(peer string) *pubsub_pb.TraceEvent {
+	typ := pubsub_pb.TraceEvent_REMOVE_PEER
+	return &pubsub_pb.TraceEvent{
+		Type:       &typ,
+		RemovePeer: &pubsub_pb.TraceEvent_RemovePeer{PeerID: []byte(peer)},
+	}
+}
+
+type countingTracer int
+
+func (c *countingTracer) Trace(*pubsub_pb.TraceEvent) { *c++ }
+
+func TestMeshTracer(t *testing.T) {
+	var next countingTracer
+	tracer := newMeshTracer(&next)
+	for _, evt := range []*pubsub_pb.TraceEvent{
+		graftEvent("a", "shard"),
+		graftEvent("b", "shard"),
+		graftEvent("a", "beacon"),
+		graftEvent("a", "shard"),
+		pruneEvent("b", "shard"),
+		graftEvent("c", "beacon"),
+		removePeerEvent("a"),
+	} {
+		tracer.Trace(evt)
+	}
+	sizes := tracer.meshSizes()
+	if sizes["shard"] != 0 || sizes["beacon"] != 1 {
+		t.Errorf("got mesh sizes %v, expect shard 0 and beacon 1", sizes)
+	}
+	if next != 7 {
+		t.Errorf("forwarded %d events, expect 7", next)
+	}
+}
@@ -0,0 +1,66 @@
+package p2p
+
+import (
+	"sync/atomic"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p "github.com/libp2p/go-libp2p"
+	libp2p_event "github.com/libp2p/go-libp2p-core/event"
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+// natOptions returns the libp2p options of the NAT traversal. AutoNAT asks
+// the peers to dial back the host to detect whether it is publicly reachable,
+// the port is mapped on the gateway with UPnP or NAT-PMP, and while the host
+// is not reachable it advertises its addresses through the given relays.
+func natOptions(config nodeconfig.NATConfig) ([]libp2p.Option, error) {
+	if !config.Enabled {
+		return []libp2p.Option{libp2p.ForceReachabilityPublic()}, nil
+	}
+	options := []libp2p.Option{libp2p.NATPortMap(), libp2p.EnableRelay()}
+	if len(config.Relays) == 0 {
+		return options, nil
+	}
+	relays := make([]libp2p_peer.AddrInfo, 0, len(config.Relays))
+	for _, relay := range config.Relays {
+		addr, err := ma.NewMultiaddr(relay)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid relay multiaddr %#v", relay)
+		}
+		info, err := libp2p_peer.AddrInfoFromP2pAddr(addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid relay multiaddr %#v", relay)
+		}
+		relays = append(relays, *info)
+	}
+	return append(options, libp2p.EnableAutoRelay(), libp2p.StaticRelays(relays)), nil
+}
+
+// trackReachability keeps the reachability of the host up to date with the
+// reachability detected by AutoNAT
+func (host *HostV2) trackReachability() error {
+	sub, err := host.h.EventBus().Subscribe(new(libp2p_event.EvtLocalReachabilityChanged))
+	if err != nil {
+		return errors.Wrap(err, "cannot subscribe to reachability events")
+	}
+	go func() {
+		defer sub.Close()
+		for e := range sub.Out() {
+			reachability := e.(libp2p_event.EvtLocalReachabilityChanged).Reachability
+			atomic.StoreInt32(&host.reachability, int32(reachability))
+			host.logger.Info().
+				Str("reachability", reachability.String()).
+				Msg("p2p reachability changed")
+		}
+	}()
+	return nil
+}
+
+// Reachability returns whether the host is publicly reachable, as detected
+// by AutoNAT
+func (host *HostV2) Reachability() libp2p_network.Reachability {
+	return libp2p_network.Reachability(atomic.LoadInt32(&host.reachability))
+}
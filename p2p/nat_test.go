@@ -0,0 +1,30 @@
+package p2p
+
+import (
+	"testing"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+)
+
+func TestNATOptions(t *testing.T) {
+	const relay = "/ip4/1.2.3.4/tcp/9000/p2p/QmRG8cJkjY6WbHpiRsxoMRqRmuUJzFJ5Ge9sCkVGNQxHEp"
+	tests := []struct {
+		config  nodeconfig.NATConfig
+		options int
+		ok      bool
+	}{
+		{nodeconfig.NATConfig{}, 1, true},
+		{nodeconfig.NATConfig{Enabled: true}, 2, true},
+		{nodeconfig.NATConfig{Enabled: true, Relays: []string{relay}}, 4, true},
+		{nodeconfig.NATConfig{Enabled: true, Relays: []string{"/ip4/1.2.3.4/tcp/9000"}}, 0, false},
+		{nodeconfig.NATConfig{Enabled: true, Relays: []string{"relay"}}, 0, false},
+	}
+	for i, test := range tests {
+		options, err := natOptions(test.config)
+		if len(options) != test.options || (err == nil) != test.ok {
+			t.Errorf("index %d: got %d options %v, expect %d options ok %v",
+				i, len(options), err, test.options, test.ok,
+			)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package p2p
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	libp2p_network "github.com/libp2p/go-libp2p-core/network"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	libp2p_peerstore "github.com/libp2p/go-libp2p-core/peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+)
+
+const (
+	trustedPeerTag        = "trusted"
+	trustedReconnectDelay = 5 * time.Second
+	connectTimeout        = 10 * time.Second
+)
+
+// PeerInfo describes a connected peer
+type PeerInfo struct {
+	ID        libp2p_peer.ID `json:"id"`
+	Addrs     []string       `json:"addrs"`
+	Direction string         `json:"direction"`
+	Protocols []string       `json:"protocols"`
+	Topics    []string       `json:"topics"`
+	ShardIDs  []uint32       `json:"shardIDs"`
+	LatencyMs int64          `json:"latencyMs"`
+	Trusted   bool           `json:"trusted"`
+}
+
+// ErrPeerNotConnected is returned when disconnecting from a peer which is not connected
+var ErrPeerNotConnected = errors.New("peer is not connected")
+
+// shardIDOfTopic returns the shard whose node group gossips on topic
+func shardIDOfTopic(topic string) (uint32, bool) {
+	if strings.HasSuffix(topic, "/node/beacon") {
+		return 0, true
+	}
+	i := strings.LastIndex(topic, "/node/shard/")
+	if i < 0 {
+		return 0, false
+	}
+	shardID, err := strconv.ParseUint(topic[i+len("/node/shard/"):], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(shardID), true
+}
+
+// ListPeers returns information about the connected peers
+func (host *HostV2) ListPeers() []PeerInfo {
+	topics := map[libp2p_peer.ID][]string{}
+	host.lock.Lock()
+	for name, topic := range host.joined {
+		for _, id := range topic.ListPeers() {
+			topics[id] = append(topics[id], name)
+		}
+	}
+	host.lock.Unlock()
+
+	ids := host.h.Network().Peers()
+	peers := make([]PeerInfo, 0, len(ids))
+	for _, id := range ids {
+		info := PeerInfo{
+			ID:        id,
+			Addrs:     []string{},
+			Protocols: []string{},
+			Topics:    []string{},
+			ShardIDs:  []uint32{},
+			LatencyMs: host.h.Peerstore().LatencyEWMA(id).Milliseconds(),
+			Trusted:   host.IsTrustedPeer(id),
+		}
+		for _, conn := range host.h.Network().ConnsToPeer(id) {
+			info.Addrs = append(info.Addrs, conn.RemoteMultiaddr().String())
+			info.Direction = conn.Stat().Direction.String()
+		}
+		if protocols, err := host.h.Peerstore().GetProtocols(id); err == nil {
+			info.Protocols = protocols
+		}
+		seen := map[uint32]struct{}{}
+		for _, topic := range topics[id] {
+			info.Topics = append(info.Topics, topic)
+			if shardID, ok := shardIDOfTopic(topic); ok {
+				if _, dup := seen[shardID]; !dup {
+					seen[shardID] = struct{}{}
+					info.ShardIDs = append(info.ShardIDs, shardID)
+				}
+			}
+		}
+		peers = append(peers, info)
+	}
+	return peers
+}
+
+// ConnectPeer connects to the peer at addr, which must include the peer ID
+func (host *HostV2) ConnectPeer(addr ma.Multiaddr) (libp2p_peer.ID, error) {
+	info, err := libp2p_peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid peer address %s", addr)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := host.h.Connect(ctx, *info); err != nil {
+		return "", errors.Wrapf(err, "cannot connect to peer %s", info.ID)
+	}
+	host.logger.Info().Str("peer", addr.String()).Msg("connected to peer")
+	return info.ID, nil
+}
+
+// DisconnectPeer closes the connections to the peer. Trusted peers are
+// reconnected, they have to be removed from the trusted peers first.
+func (host *HostV2) DisconnectPeer(id libp2p_peer.ID) error {
+	if host.h.Network().Connectedness(id) != libp2p_network.Connected {
+		return ErrPeerNotConnected
+	}
+	return host.h.Network().ClosePeer(id)
+}
+
+// AddTrustedPeer connects to the peer at addr and keeps reconnecting to it
+// whenever the connection drops
+func (host *HostV2) AddTrustedPeer(addr ma.Multiaddr) (libp2p_peer.ID, error) {
+	info, err := libp2p_peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid peer address %s", addr)
+	}
+	host.lock.Lock()
+	host.trusted[info.ID] = *info
+	host.lock.Unlock()
+	host.diversity.reserve(info.ID)
+	host.h.Peerstore().AddAddrs(info.ID, info.Addrs, libp2p_peerstore.PermanentAddrTTL)
+	host.h.ConnManager().Protect(info.ID, trustedPeerTag)
+	host.logger.Info().Str("peer", addr.String()).Msg("added trusted peer")
+	if host.h.Network().Connectedness(info.ID) != libp2p_network.Connected {
+		go host.reconnectTrusted(info.ID)
+	}
+	return info.ID, nil
+}
+
+// RemoveTrustedPeer stops keeping the connection to the peer alive.
+// It returns whether the peer was trusted.
+func (host *HostV2) RemoveTrustedPeer(id libp2p_peer.ID) bool {
+	host.lock.Lock()
+	_, ok := host.trusted[id]
+	delete(host.trusted, id)
+	host.lock.Unlock()
+	if ok {
+		host.diversity.release(id)
+		host.h.ConnManager().Unprotect(id, trustedPeerTag)
+		host.logger.Info().Str("peer", id.Pretty()).Msg("removed trusted peer")
+	}
+	return ok
+}
+
+// IsTrustedPeer returns whether the peer was added as trusted peer
+func (host *HostV2) IsTrustedPeer(id libp2p_peer.ID) bool {
+	host.lock.Lock()
+	defer host.lock.Unlock()
+	_, ok := host.trusted[id]
+	return ok
+}
+
+// reconnectTrusted connects to the trusted peer id until it succeeds
+// or the peer is no longer trusted
+func (host *HostV2) reconnectTrusted(id libp2p_peer.ID) {
+	for {
+		host.lock.Lock()
+		info, ok := host.trusted[id]
+		host.lock.Unlock()
+		if !ok || host.h.Network().Connectedness(id) == libp2p_network.Connected {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		err := host.h.Connect(ctx, info)
+		cancel()
+		if err == nil {
+			return
+		}
+		host.logger.Warn().Err(err).Str("peer", id.Pretty()).
+			Msg("cannot reconnect to trusted peer")
+		time.Sleep(trustedReconnectDelay)
+	}
+}
+
+// trustedNotifiee reconnects to trusted peers when their last connection is closed
+func (host *HostV2) trustedNotifiee() libp2p_network.Notifiee {
+	return &libp2p_network.NotifyBundle{
+		DisconnectedF: func(n libp2p_network.Network, conn libp2p_network.Conn) {
+			id := conn.RemotePeer()
+			if host.IsTrustedPeer(id) && n.Connectedness(id) != libp2p_network.Connected {
+				go func() {
+					time.Sleep(trustedReconnectDelay)
+					host.reconnectTrusted(id)
+				}()
+			}
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package p2p
+
+import "testing"
+
+func TestShardIDOfTopic(t *testing.T) {
+	tests := []struct {
+		topic   string
+		shardID uint32
+		ok      bool
+	}{
+		{"harmony/0.0.1/node/beacon", 0, true},
+		{"harmony/0.0.1/node/shard/3", 3, true},
+		{"hmy/testnet/0.0.1/node/shard/1", 1, true},
+		{"harmony/0.0.1/client/shard/2", 0, false},
+		{"harmony/0.0.1/node/global", 0, false},
+		{"harmony/0.0.1/node/shard/x", 0, false},
+	}
+	for i, test := range tests {
+		shardID, ok := shardIDOfTopic(test.topic)
+		if shardID != test.shardID || ok != test.ok {
+			t.Errorf("index %d: got %d %v, expect %d %v",
+				i, shardID, ok, test.shardID, test.ok,
+			)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Constants for the inbound message rate limits
+const (
+	// ConsensusMessageKind is the kind of the consensus messages, which are
+	// not limited per peer
+	ConsensusMessageKind = "consensus"
+	// peer buckets are pruned once there are more, the full ones are idle
+	maxPeerBuckets = 4096
+)
+
+// tokenBucket allows rate events per second, with bursts of up to one
+// second of events
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+// refill adds the tokens accrued since the last refill
+func (b *tokenBucket) refill(now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
+
+// allow takes a token if there is one
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter limits the inbound messages by kind and by peer
+type rateLimiter struct {
+	lock      sync.Mutex
+	kinds     map[string]*tokenBucket
+	peerRate  float64
+	peers     map[libp2p_peer.ID]*tokenBucket
+	kindDrops map[string]uint64
+	peerDrops map[string]uint64
+	now       func() time.Time
+}
+
+func newRateLimiter(limits nodeconfig.RateLimits) *rateLimiter {
+	l := &rateLimiter{
+		kindDrops: map[string]uint64{},
+		peerDrops: map[string]uint64{},
+		now:       time.Now,
+	}
+	l.setLimits(limits)
+	return l
+}
+
+// setLimits replaces the limits, the buckets of the peers are refilled at
+// the new peer rate
+func (l *rateLimiter) setLimits(limits nodeconfig.RateLimits) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	l.kinds = map[string]*tokenBucket{}
+	for kind, rate := range limits.Kinds {
+		if rate > 0 {
+			l.kinds[kind] = newTokenBucket(rate, now)
+		}
+	}
+	l.peerRate = limits.Peer
+	l.peers = map[libp2p_peer.ID]*tokenBucket{}
+}
+
+// allow returns whether a message of the kind from the peer is within the limits
+func (l *rateLimiter) allow(kind string, peer libp2p_peer.ID) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	if kind != ConsensusMessageKind && l.peerRate > 0 {
+		b, ok := l.peers[peer]
+		if !ok {
+			if len(l.peers) >= maxPeerBuckets {
+				l.prunePeers(now)
+			}
+			b = newTokenBucket(l.peerRate, now)
+			l.peers[peer] = b
+		}
+		if !b.allow(now) {
+			l.peerDrops[kind]++
+			return false
+		}
+	}
+	if b, ok := l.kinds[kind]; ok && !b.allow(now) {
+		l.kindDrops[kind]++
+		return false
+	}
+	return true
+}
+
+// prunePeers forgets the buckets of the idle peers
+func (l *rateLimiter) prunePeers(now time.Time) {
+	for peer, b := range l.peers {
+		if b.refill(now); b.tokens >= b.rate {
+			delete(l.peers, peer)
+		}
+	}
+}
+
+// drops returns the number of dropped messages of each kind, over the limit
+// of the kind and over the limit of their peer
+func (l *rateLimiter) drops() (map[string]uint64, map[string]uint64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	kindDrops := make(map[string]uint64, len(l.kindDrops))
+	for kind, n := range l.kindDrops {
+		kindDrops[kind] = n
+	}
+	peerDrops := make(map[string]uint64, len(l.peerDrops))
+	for kind, n := range l.peerDrops {
+		peerDrops[kind] = n
+	}
+	return kindDrops, peerDrops
+}
+
+// RateLimitDrops counts the inbound messages dropped by kind, over the limit
+// of their kind and over the limit of their peer
+type RateLimitDrops struct {
+	ByKind map[string]uint64 `json:"by-kind"`
+	ByPeer map[string]uint64 `json:"by-peer"`
+}
+
+// AllowMessage returns whether a message of the given kind forwarded by the
+// peer is within the inbound rate limits, messages over them are dropped
+func (host *HostV2) AllowMessage(kind string, peer libp2p_peer.ID) bool {
+	return host.rateLimiter.allow(kind, peer)
+}
+
+// SetRateLimits replaces the inbound rate limits of the gossip messages
+func (host *HostV2) SetRateLimits(limits nodeconfig.RateLimits) {
+	host.rateLimiter.setLimits(limits)
+}
+
+// RateLimitDrops returns the number of inbound messages dropped over the
+// rate limits
+func (host *HostV2) RateLimitDrops() RateLimitDrops {
+	byKind, byPeer := host.rateLimiter.drops()
+	return RateLimitDrops{byKind, byPeer}
+}
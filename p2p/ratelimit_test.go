@@ -0,0 +1,71 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(nodeconfig.RateLimits{
+		Kinds: map[string]float64{"transaction": 10},
+		Peer:  4,
+	})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	alice, bob := libp2p_peer.ID("alice"), libp2p_peer.ID("bob")
+
+	for i := 0; i < 4; i++ {
+		if !l.allow("transaction", alice) {
+			t.Fatalf("message %d of alice dropped", i)
+		}
+	}
+	if l.allow("transaction", alice) {
+		t.Fatal("message over the peer limit allowed")
+	}
+	// consensus messages are only limited by their kind
+	for i := 0; i < 10; i++ {
+		if !l.allow(ConsensusMessageKind, alice) {
+			t.Fatalf("consensus message %d dropped", i)
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if !l.allow("transaction", libp2p_peer.ID(rune('a'+i))) {
+			t.Fatalf("message %d of other peers dropped", i)
+		}
+	}
+	if l.allow("transaction", bob) {
+		t.Fatal("message over the kind limit allowed")
+	}
+
+	now = now.Add(time.Second)
+	if !l.allow("transaction", alice) {
+		t.Fatal("message dropped after the refill")
+	}
+
+	byKind, byPeer := l.drops()
+	if byKind["transaction"] != 1 || byPeer["transaction"] != 1 {
+		t.Errorf("got drops %v by kind and %v by peer", byKind, byPeer)
+	}
+}
+
+func TestRateLimiterSetLimits(t *testing.T) {
+	l := newRateLimiter(nodeconfig.RateLimits{Kinds: map[string]float64{"transaction": 1}})
+	alice := libp2p_peer.ID("alice")
+	if !l.allow("transaction", alice) || l.allow("transaction", alice) {
+		t.Fatal("expect one transaction allowed")
+	}
+	l.setLimits(nodeconfig.RateLimits{Kinds: map[string]float64{"staking": 1}, Peer: 2})
+	if !l.allow("transaction", alice) {
+		t.Fatal("expect the transactions no longer limited by their kind")
+	}
+	if !l.allow("staking", alice) || l.allow("staking", alice) {
+		t.Fatal("expect the new kind limit and peer limit applied")
+	}
+	byKind, byPeer := l.drops()
+	if byKind["transaction"] != 1 || byPeer["staking"] != 1 {
+		t.Errorf("expect the drops kept, got %v by kind and %v by peer", byKind, byPeer)
+	}
+}
@@ -0,0 +1,255 @@
+package p2p
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// Offense is a misbehavior lowering the reputation of a peer
+type Offense int
+
+const (
+	// OffenseInvalidMessage is a gossip message rejected by a topic validator
+	OffenseInvalidMessage Offense = iota
+	// OffenseInvalidSender is a consensus message without a valid sender key
+	OffenseInvalidSender
+)
+
+var offenseNames = map[Offense]string{
+	OffenseInvalidMessage: "invalid-message",
+	OffenseInvalidSender:  "invalid-sender",
+}
+
+// penalties added to the score of a peer for its offenses
+var offensePenalties = map[Offense]int{
+	OffenseInvalidMessage: 10,
+	OffenseInvalidSender:  25,
+}
+
+func (o Offense) String() string {
+	if name, ok := offenseNames[o]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Constants for the peer reputation. A peer is banned once its score
+// reaches reputationBanScore, each further ban of the peer lasts twice as
+// long up to reputationMaxBanTime.
+const (
+	reputationBanScore     = 100
+	reputationRecovery     = 10 // score recovered per hour
+	reputationBanTime      = time.Hour
+	reputationMaxBanTime   = 7 * 24 * time.Hour
+	reputationSaveInterval = time.Minute
+)
+
+// PeerReputation is the record of the offenses of a peer
+type PeerReputation struct {
+	ID          libp2p_peer.ID `json:"id"`
+	Score       int            `json:"score"`
+	Offenses    map[string]int `json:"offenses"`
+	Bans        int            `json:"bans"`
+	BannedUntil time.Time      `json:"bannedUntil"`
+	Updated     time.Time      `json:"updated"`
+}
+
+// Banned returns whether the peer is banned at the given time
+func (r *PeerReputation) Banned(now time.Time) bool {
+	return now.Before(r.BannedUntil)
+}
+
+// recover lowers the score by the recovery since the last update
+func (r *PeerReputation) recover(now time.Time) {
+	if recovered := int(now.Sub(r.Updated).Hours() * reputationRecovery); recovered > 0 {
+		r.Score -= recovered
+		if r.Score < 0 {
+			r.Score = 0
+		}
+		r.Updated = now
+	}
+}
+
+// reputationStore keeps the reputation of the peers, saved to a JSON file
+// so that banned peers stay banned across restarts
+type reputationStore struct {
+	path  string // empty to keep the reputations in memory only
+	lock  sync.Mutex
+	peers map[libp2p_peer.ID]*PeerReputation
+	dirty bool
+	now   func() time.Time
+}
+
+// newReputationStore loads the reputations saved at path
+func newReputationStore(path string) (*reputationStore, error) {
+	s := &reputationStore{
+		path:  path,
+		peers: map[libp2p_peer.ID]*PeerReputation{},
+		now:   time.Now,
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read peer reputations")
+	}
+	var reputations []*PeerReputation
+	if err := json.Unmarshal(data, &reputations); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode peer reputations in %s", path)
+	}
+	for _, r := range reputations {
+		s.peers[r.ID] = r
+	}
+	return s, nil
+}
+
+// report records the offense of the peer and returns whether it got banned
+func (s *reputationStore) report(id libp2p_peer.ID, offense Offense) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	r, ok := s.peers[id]
+	if !ok {
+		r = &PeerReputation{ID: id, Offenses: map[string]int{}, Updated: now}
+		s.peers[id] = r
+	}
+	r.recover(now)
+	r.Score += offensePenalties[offense]
+	r.Offenses[offense.String()]++
+	r.Updated = now
+	s.dirty = true
+	if r.Score < reputationBanScore || r.Banned(now) {
+		return false
+	}
+	r.Bans++
+	banTime := reputationMaxBanTime
+	if r.Bans <= 8 && reputationBanTime<<uint(r.Bans-1) < banTime {
+		banTime = reputationBanTime << uint(r.Bans-1)
+	}
+	r.BannedUntil = now.Add(banTime)
+	r.Score = 0
+	return true
+}
+
+// banned returns whether the peer is currently banned
+func (s *reputationStore) banned(id libp2p_peer.ID) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	r, ok := s.peers[id]
+	return ok && r.Banned(s.now())
+}
+
+// list returns the reputations of the peers sorted by peer ID
+func (s *reputationStore) list() []PeerReputation {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	reputations := make([]PeerReputation, 0, len(s.peers))
+	for _, r := range s.peers {
+		r.recover(now)
+		copied := *r
+		copied.Offenses = map[string]int{}
+		for name, count := range r.Offenses {
+			copied.Offenses[name] = count
+		}
+		reputations = append(reputations, copied)
+	}
+	sort.Slice(reputations, func(i, j int) bool {
+		return reputations[i].ID < reputations[j].ID
+	})
+	return reputations
+}
+
+// clear forgets the reputation of the peer, lifting its ban.
+// It returns whether the peer had a reputation.
+func (s *reputationStore) clear(id libp2p_peer.ID) bool {
+	s.lock.Lock()
+	_, ok := s.peers[id]
+	delete(s.peers, id)
+	s.dirty = s.dirty || ok
+	s.lock.Unlock()
+	return ok
+}
+
+// save writes the reputations to the file of the store if they changed
+func (s *reputationStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.lock.Lock()
+	dirty := s.dirty
+	s.dirty = false
+	s.lock.Unlock()
+	if !dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return errors.Wrapf(err, "cannot create peer reputation directory")
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "cannot write peer reputations")
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// ReportPeer records the offense of the peer, which is disconnected once its
+// reputation gets it banned. Trusted peers are never banned.
+func (host *HostV2) ReportPeer(id libp2p_peer.ID, offense Offense) {
+	if id == host.h.ID() || host.IsTrustedPeer(id) {
+		return
+	}
+	if !host.reputation.report(id, offense) {
+		return
+	}
+	host.logger.Warn().
+		Str("peer", id.Pretty()).
+		Str("offense", offense.String()).
+		Msg("banned peer")
+	if err := host.reputation.save(); err != nil {
+		host.logger.Warn().Err(err).Msg("cannot save peer reputations")
+	}
+	host.h.Network().ClosePeer(id)
+}
+
+// PeerReputations returns the reputation of the peers which misbehaved
+func (host *HostV2) PeerReputations() []PeerReputation {
+	return host.reputation.list()
+}
+
+// ClearPeerReputation forgets the offenses of the peer, lifting its ban.
+// It returns whether the peer had misbehaved.
+func (host *HostV2) ClearPeerReputation(id libp2p_peer.ID) bool {
+	if !host.reputation.clear(id) {
+		return false
+	}
+	if err := host.reputation.save(); err != nil {
+		host.logger.Warn().Err(err).Msg("cannot save peer reputations")
+	}
+	return true
+}
+
+// saveReputations periodically saves the changed peer reputations
+func (host *HostV2) saveReputations() {
+	for range time.Tick(reputationSaveInterval) {
+		if err := host.reputation.save(); err != nil {
+			host.logger.Warn().Err(err).Msg("cannot save peer reputations")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package p2p
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestReputationStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reputation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "peers", "reputation.json")
+
+	s, err := newReputationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1600000000, 0)
+	s.now = func() time.Time { return now }
+	id, err := libp2p_peer.Decode("QmRG8cJkjY6WbHpiRsxoMRqRmuUJzFJ5Ge9sCkVGNQxHEp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < reputationBanScore/offensePenalties[OffenseInvalidMessage]-1; i++ {
+		if s.report(id, OffenseInvalidMessage) {
+			t.Fatalf("banned after %d offenses", i+1)
+		}
+	}
+	if !s.report(id, OffenseInvalidMessage) {
+		t.Fatal("not banned at the ban score")
+	}
+	if !s.banned(id) {
+		t.Fatal("banned peer is allowed")
+	}
+
+	// the ban survives a restart
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := newReputationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.now = s.now
+	reputations := loaded.list()
+	if len(reputations) != 1 || reputations[0].ID != id || reputations[0].Bans != 1 {
+		t.Fatalf("got reputations %+v", reputations)
+	}
+	if !loaded.banned(id) {
+		t.Fatal("ban lost after restart")
+	}
+
+	// the ban expires and the next one lasts twice as long
+	now = now.Add(reputationBanTime)
+	if loaded.banned(id) {
+		t.Fatal("ban did not expire")
+	}
+	for !loaded.report(id, OffenseInvalidSender) {
+	}
+	if until := loaded.list()[0].BannedUntil; !until.Equal(now.Add(2 * reputationBanTime)) {
+		t.Errorf("got second ban until %v, expect %v", until, now.Add(2*reputationBanTime))
+	}
+
+	if !loaded.clear(id) || loaded.banned(id) || loaded.clear(id) {
+		t.Error("cannot clear peer reputation")
+	}
+}
+
+func TestReputationRecovery(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	r := &PeerReputation{Score: 25, Updated: now}
+	r.recover(now.Add(30 * time.Minute))
+	if r.Score != 20 {
+		t.Errorf("got score %d after half an hour, expect 20", r.Score)
+	}
+	r.recover(now.Add(10 * time.Hour))
+	if r.Score != 0 {
+		t.Errorf("got score %d after ten hours, expect 0", r.Score)
+	}
+}
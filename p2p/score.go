@@ -0,0 +1,110 @@
+package p2p
+
+import (
+	"time"
+
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// Constants for the gossipsub peer scoring. The invalid message penalty
+// dominates the score, a handful of rejected messages graylists a peer.
+const (
+	// weights of the topics in the peer score
+	consensusTopicWeight = 1
+	crossLinkTopicWeight = 1
+	clientTopicWeight    = 0.5
+	// time in mesh, up to 10 points after an hour
+	timeInMeshWeight  = 10.0 / 3600
+	timeInMeshQuantum = time.Second
+	timeInMeshCap     = 3600
+	// first deliveries of valid messages
+	firstDeliveriesWeight = 1
+	firstDeliveriesDecay  = 10 * time.Minute
+	firstDeliveriesCap    = 20
+	// deliveries of rejected messages
+	invalidDeliveriesWeight = -100
+	invalidDeliveriesDecay  = time.Hour
+	// score thresholds
+	gossipThreshold             = -500
+	publishThreshold            = -1000
+	graylistThreshold           = -2500
+	acceptPXThreshold           = 1000
+	opportunisticGraftThreshold = 3.5
+)
+
+// ScoredTopic is a pubsub topic with the parameters scoring its peers
+type ScoredTopic struct {
+	Name   string
+	Params *libp2p_pubsub.TopicScoreParams
+}
+
+// ConsensusTopic scores the peers of a shard topic, which carries the
+// consensus and transaction messages of the shard
+func ConsensusTopic(name string) ScoredTopic {
+	return ScoredTopic{name, topicScoreParams(consensusTopicWeight)}
+}
+
+// CrossLinkTopic scores the peers of the cross-link topic, which carries the
+// cross-links sent by the shard leaders to the beacon chain
+func CrossLinkTopic(name string) ScoredTopic {
+	return ScoredTopic{name, topicScoreParams(crossLinkTopicWeight)}
+}
+
+// ClientTopic scores the peers of a client topic, which carries the new
+// blocks of a shard
+func ClientTopic(name string) ScoredTopic {
+	return ScoredTopic{name, topicScoreParams(clientTopicWeight)}
+}
+
+// topicScoreParams rewards the peers staying in the mesh and first
+// delivering valid messages, and penalizes the peers delivering messages
+// rejected by the topic validator. The mesh delivery rate is not scored as
+// the traffic of a topic depends on the block time and the pending
+// transactions.
+func topicScoreParams(weight float64) *libp2p_pubsub.TopicScoreParams {
+	return &libp2p_pubsub.TopicScoreParams{
+		TopicWeight:                    weight,
+		TimeInMeshWeight:               timeInMeshWeight,
+		TimeInMeshQuantum:              timeInMeshQuantum,
+		TimeInMeshCap:                  timeInMeshCap,
+		FirstMessageDeliveriesWeight:   firstDeliveriesWeight,
+		FirstMessageDeliveriesDecay:    libp2p_pubsub.ScoreParameterDecay(firstDeliveriesDecay),
+		FirstMessageDeliveriesCap:      firstDeliveriesCap,
+		InvalidMessageDeliveriesWeight: invalidDeliveriesWeight,
+		InvalidMessageDeliveriesDecay:  libp2p_pubsub.ScoreParameterDecay(invalidDeliveriesDecay),
+	}
+}
+
+// peerScoreParams returns the peer score parameters of the given topics.
+// The IP colocation factor is disabled as operators run several nodes
+// behind the same address.
+func peerScoreParams(topics []ScoredTopic) *libp2p_pubsub.PeerScoreParams {
+	params := &libp2p_pubsub.PeerScoreParams{
+		Topics:                 map[string]*libp2p_pubsub.TopicScoreParams{},
+		TopicScoreCap:          timeInMeshWeight*timeInMeshCap + firstDeliveriesWeight*firstDeliveriesCap,
+		AppSpecificScore:       func(libp2p_peer.ID) float64 { return 0 },
+		AppSpecificWeight:      1,
+		BehaviourPenaltyWeight: -10,
+		BehaviourPenaltyDecay:  libp2p_pubsub.ScoreParameterDecay(10 * time.Minute),
+		DecayInterval:          libp2p_pubsub.DefaultDecayInterval,
+		DecayToZero:            libp2p_pubsub.DefaultDecayToZero,
+		RetainScore:            time.Hour,
+	}
+	for _, topic := range topics {
+		params.Topics[topic.Name] = topic.Params
+	}
+	return params
+}
+
+// peerScoreThresholds returns the thresholds of the peer score below which
+// gossip, publishing and then any message of a peer are suppressed
+func peerScoreThresholds() *libp2p_pubsub.PeerScoreThresholds {
+	return &libp2p_pubsub.PeerScoreThresholds{
+		GossipThreshold:             gossipThreshold,
+		PublishThreshold:            publishThreshold,
+		GraylistThreshold:           graylistThreshold,
+		AcceptPXThreshold:           acceptPXThreshold,
+		OpportunisticGraftThreshold: opportunisticGraftThreshold,
+	}
+}
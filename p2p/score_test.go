@@ -0,0 +1,37 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+func TestPeerScoreParams(t *testing.T) {
+	topics := []ScoredTopic{
+		ConsensusTopic("harmony/0.0.1/node/shard/1"),
+		ClientTopic("harmony/0.0.1/client/shard/1"),
+		ClientTopic("harmony/0.0.1/client/beacon"),
+	}
+	params := peerScoreParams(topics)
+	if len(params.Topics) != len(topics) {
+		t.Fatalf("got %d scored topics, expect %d", len(params.Topics), len(topics))
+	}
+	if w := params.Topics[topics[0].Name].TopicWeight; w != consensusTopicWeight {
+		t.Errorf("got consensus topic weight %v, expect %v", w, consensusTopicWeight)
+	}
+
+	// gossipsub refuses invalid score parameters and thresholds
+	mn := mocknet.New(context.Background())
+	host, err := mn.GenPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := libp2p_pubsub.NewGossipSub(
+		context.Background(), host,
+		libp2p_pubsub.WithPeerScore(params, peerScoreThresholds()),
+	); err != nil {
+		t.Fatalf("invalid peer score parameters: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package p2p
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	libp2p "github.com/libp2p/go-libp2p"
+	libp2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	userAgentPrefix = "harmony/"
+	// unknownAgent is the role and version of the peers not announcing a
+	// harmony agent
+	unknownAgent = "unknown"
+)
+
+// UserAgent returns the agent announced to the peers by a node of the role
+// running the version, harmony/<version>/<role>
+func UserAgent(version, role string) string {
+	return fmt.Sprintf("%s%s/%s", userAgentPrefix, version, role)
+}
+
+// userAgentOptions announces the agent to the peers, libp2p announces its
+// own agent when none is given
+func userAgentOptions(agent string) []libp2p.Option {
+	if agent == "" {
+		return nil
+	}
+	return []libp2p.Option{libp2p.UserAgent(agent)}
+}
+
+// parseUserAgent returns the version and role announced in a harmony agent
+func parseUserAgent(agent string) (version, role string, ok bool) {
+	if !strings.HasPrefix(agent, userAgentPrefix) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(agent, userAgentPrefix), "/")
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// TopologyPeer is a connected peer as seen in the peer topology
+type TopologyPeer struct {
+	ID              libp2p_peer.ID `json:"id"`
+	Role            string         `json:"role"`
+	Version         string         `json:"version"`
+	AgentVersion    string         `json:"agentVersion"`
+	ProtocolVersion string         `json:"protocolVersion"`
+	Topics          []string       `json:"topics"`
+	MeshTopics      []string       `json:"meshTopics"`
+	Heads           []HeadState    `json:"heads"`
+}
+
+// ShardTopology is the view of the peers gossiping on the node topic of a
+// shard. Peers subscribed to the topic but out of the mesh of the node only
+// reach it through gossip, a shard with peers but no mesh peers is isolated.
+type ShardTopology struct {
+	ShardID   uint32         `json:"shardID"`
+	Peers     []TopologyPeer `json:"peers"`
+	MeshPeers int            `json:"meshPeers"`
+	Roles     map[string]int `json:"roles"`
+	Versions  map[string]int `json:"versions"`
+}
+
+// PeerTopology is the view of the connected peers grouped by shard, the
+// peers on no shard topic are unassigned
+type PeerTopology struct {
+	Shards     []ShardTopology `json:"shards"`
+	Unassigned []TopologyPeer  `json:"unassigned"`
+}
+
+// newPeerTopology groups the peers by the shards of their node topics,
+// a peer on the topics of several shards is counted in each of them
+func newPeerTopology(peers []TopologyPeer) PeerTopology {
+	topology := PeerTopology{Shards: []ShardTopology{}, Unassigned: []TopologyPeer{}}
+	shards := map[uint32]*ShardTopology{}
+	for _, peer := range peers {
+		inMesh := map[string]struct{}{}
+		for _, topic := range peer.MeshTopics {
+			inMesh[topic] = struct{}{}
+		}
+		assigned := false
+		for _, topic := range peer.Topics {
+			shardID, ok := shardIDOfTopic(topic)
+			if !ok {
+				continue
+			}
+			shard, ok := shards[shardID]
+			if !ok {
+				shard = &ShardTopology{
+					ShardID:  shardID,
+					Peers:    []TopologyPeer{},
+					Roles:    map[string]int{},
+					Versions: map[string]int{},
+				}
+				shards[shardID] = shard
+			}
+			shard.Peers = append(shard.Peers, peer)
+			shard.Roles[peer.Role]++
+			shard.Versions[peer.Version]++
+			if _, ok := inMesh[topic]; ok {
+				shard.MeshPeers++
+			}
+			assigned = true
+		}
+		if !assigned {
+			topology.Unassigned = append(topology.Unassigned, peer)
+		}
+	}
+	for _, shard := range shards {
+		topology.Shards = append(topology.Shards, *shard)
+	}
+	sort.Slice(topology.Shards, func(i, j int) bool {
+		return topology.Shards[i].ShardID < topology.Shards[j].ShardID
+	})
+	return topology
+}
+
+// Topology returns the view of the connected peers grouped by shard, with
+// their role and version announced in their agent, their protocol version,
+// their membership of the gossipsub mesh of the node and the chain heads of
+// their handshake
+func (host *HostV2) Topology() PeerTopology {
+	topics := map[libp2p_peer.ID][]string{}
+	host.lock.Lock()
+	for name, topic := range host.joined {
+		for _, id := range topic.ListPeers() {
+			topics[id] = append(topics[id], name)
+		}
+	}
+	host.lock.Unlock()
+	meshTopics := host.mesh.meshTopics()
+
+	ids := host.h.Network().Peers()
+	peers := make([]TopologyPeer, 0, len(ids))
+	for _, id := range ids {
+		peer := TopologyPeer{
+			ID:         id,
+			Role:       unknownAgent,
+			Version:    unknownAgent,
+			Topics:     topics[id],
+			MeshTopics: meshTopics[id],
+			Heads:      []HeadState{},
+		}
+		if peer.Topics == nil {
+			peer.Topics = []string{}
+		}
+		if peer.MeshTopics == nil {
+			peer.MeshTopics = []string{}
+		}
+		if agent, err := host.h.Peerstore().Get(id, "AgentVersion"); err == nil {
+			peer.AgentVersion, _ = agent.(string)
+		}
+		if version, role, ok := parseUserAgent(peer.AgentVersion); ok {
+			peer.Version, peer.Role = version, role
+		}
+		if version, err := host.h.Peerstore().Get(id, "ProtocolVersion"); err == nil {
+			peer.ProtocolVersion, _ = version.(string)
+		}
+		if handshake, ok := host.PeerHandshake(id); ok {
+			peer.Heads = handshake.Heads
+		}
+		sort.Strings(peer.Topics)
+		sort.Strings(peer.MeshTopics)
+		peers = append(peers, peer)
+	}
+	return newPeerTopology(peers)
+}
@@ -0,0 +1,59 @@
+package p2p
+
+import (
+	"testing"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		agent   string
+		version string
+		role    string
+		ok      bool
+	}{
+		{UserAgent("v6000-abc", "validator"), "v6000-abc", "validator", true},
+		{UserAgent("-", "explorer"), "-", "explorer", true},
+		{"go-libp2p/0.9.2", "", "", false},
+		{"harmony/v6000", "", "", false},
+		{"harmony/v6000/", "", "", false},
+	}
+	for i, test := range tests {
+		version, role, ok := parseUserAgent(test.agent)
+		if version != test.version || role != test.role || ok != test.ok {
+			t.Errorf("index %d: got %s %s %v, expect %s %s %v",
+				i, version, role, ok, test.version, test.role, test.ok,
+			)
+		}
+	}
+}
+
+func TestNewPeerTopology(t *testing.T) {
+	const (
+		beacon = "harmony/0.0.1/node/beacon"
+		shard1 = "harmony/0.0.1/node/shard/1"
+		client = "harmony/0.0.1/client/beacon"
+	)
+	topology := newPeerTopology([]TopologyPeer{
+		{ID: "a", Role: "validator", Version: "v1", Topics: []string{beacon, client}, MeshTopics: []string{beacon}},
+		{ID: "b", Role: "explorer", Version: "v2", Topics: []string{beacon}},
+		{ID: "c", Role: "validator", Version: "v1", Topics: []string{beacon, shard1}, MeshTopics: []string{shard1}},
+		{ID: "d", Role: unknownAgent, Version: unknownAgent, Topics: []string{client}},
+	})
+	if len(topology.Shards) != 2 {
+		t.Fatalf("got %d shards, expect 2", len(topology.Shards))
+	}
+	beaconShard, shard := topology.Shards[0], topology.Shards[1]
+	if beaconShard.ShardID != 0 || len(beaconShard.Peers) != 3 || beaconShard.MeshPeers != 1 {
+		t.Errorf("got beacon shard %+v", beaconShard)
+	}
+	if beaconShard.Roles["validator"] != 2 || beaconShard.Roles["explorer"] != 1 ||
+		beaconShard.Versions["v1"] != 2 || beaconShard.Versions["v2"] != 1 {
+		t.Errorf("got beacon roles %v versions %v", beaconShard.Roles, beaconShard.Versions)
+	}
+	if shard.ShardID != 1 || len(shard.Peers) != 1 || shard.Peers[0].ID != "c" || shard.MeshPeers != 1 {
+		t.Errorf("got shard 1 %+v", shard)
+	}
+	if len(topology.Unassigned) != 1 || topology.Unassigned[0].ID != "d" {
+		t.Errorf("got unassigned peers %+v", topology.Unassigned)
+	}
+}
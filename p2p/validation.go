@@ -0,0 +1,113 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// ValidateFunc validates a gossip message, within the deadline of ctx
+type ValidateFunc func(ctx context.Context) libp2p_pubsub.ValidationResult
+
+// validationJob is a gossip message waiting for a validation worker
+type validationJob struct {
+	ctx      context.Context
+	validate ValidateFunc
+	result   chan libp2p_pubsub.ValidationResult
+}
+
+// validationPool validates the gossip messages on a bounded number of
+// workers per topic, with a bounded queue per topic. A burst of messages on a
+// topic fills its queue without delaying the validation of the other topics,
+// and the messages over a full queue are ignored instead of piling up
+// validation goroutines.
+type validationPool struct {
+	lock      sync.Mutex
+	workers   int
+	queueSize int
+	queues    map[string]chan validationJob
+}
+
+func newValidationPool(config nodeconfig.ValidationPool) *validationPool {
+	return &validationPool{
+		workers:   config.Workers,
+		queueSize: config.QueueSize,
+		queues:    map[string]chan validationJob{},
+	}
+}
+
+// queue returns the queue of the topic, starting its workers on first use
+func (p *validationPool) queue(topic string) chan validationJob {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	queue, ok := p.queues[topic]
+	if !ok {
+		queue = make(chan validationJob, p.queueSize)
+		p.queues[topic] = queue
+		for i := 0; i < p.workers; i++ {
+			go p.work(queue)
+		}
+	}
+	return queue
+}
+
+// work validates the messages of a queue, skipping the messages whose
+// deadline passed while they were waiting
+func (p *validationPool) work(queue chan validationJob) {
+	for job := range queue {
+		if job.ctx.Err() != nil {
+			job.result <- libp2p_pubsub.ValidationIgnore
+			continue
+		}
+		job.result <- job.validate(job.ctx)
+	}
+}
+
+// validate runs the validation of a message of the topic on a worker of the
+// topic and waits for its result. The message is ignored when the queue of
+// the topic is full or when ctx is done first. With no workers, the
+// validation runs inline.
+func (p *validationPool) validate(
+	ctx context.Context, topic string, validate ValidateFunc,
+) libp2p_pubsub.ValidationResult {
+	if p.workers <= 0 {
+		return validate(ctx)
+	}
+	job := validationJob{ctx, validate, make(chan libp2p_pubsub.ValidationResult, 1)}
+	select {
+	case p.queue(topic) <- job:
+	default:
+		validationDropsCounter.Inc(topic)
+		return libp2p_pubsub.ValidationIgnore
+	}
+	select {
+	case result := <-job.result:
+		return result
+	case <-ctx.Done():
+		return libp2p_pubsub.ValidationIgnore
+	}
+}
+
+// queueLengths returns the number of messages waiting for a worker in the
+// queue of each topic
+func (p *validationPool) queueLengths() map[string]int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	lengths := make(map[string]int, len(p.queues))
+	for topic, queue := range p.queues {
+		lengths[topic] = len(queue)
+	}
+	return lengths
+}
+
+// ValidateMessage runs the validation of a gossip message of the topic on the
+// validation workers of the topic, off the pubsub validation goroutine. The
+// message is ignored when the validation queue of the topic is full or when
+// the deadline of ctx passes before its validation.
+func (host *HostV2) ValidateMessage(
+	ctx context.Context, topic string, validate ValidateFunc,
+) libp2p_pubsub.ValidationResult {
+	return host.validation.validate(ctx, topic, validate)
+}
@@ -0,0 +1,75 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	nodeconfig "github.com/harmony-one/harmony/internal/configs/node"
+	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+func TestValidationPool(t *testing.T) {
+	pool := newValidationPool(nodeconfig.ValidationPool{Workers: 1, QueueSize: 1})
+	ctx := context.Background()
+	reject := func(context.Context) libp2p_pubsub.ValidationResult {
+		return libp2p_pubsub.ValidationReject
+	}
+	if result := pool.validate(ctx, "a", reject); result != libp2p_pubsub.ValidationReject {
+		t.Errorf("got %v, expect the result of the validation", result)
+	}
+
+	// block the worker of topic a and fill its queue
+	release, started := make(chan struct{}), make(chan struct{})
+	blocked := func(context.Context) libp2p_pubsub.ValidationResult {
+		close(started)
+		<-release
+		return libp2p_pubsub.ValidationAccept
+	}
+	done := make(chan libp2p_pubsub.ValidationResult, 2)
+	go func() { done <- pool.validate(ctx, "a", blocked) }()
+	<-started
+	go func() { done <- pool.validate(ctx, "a", reject) }()
+	for pool.queueLengths()["a"] != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if result := pool.validate(ctx, "a", reject); result != libp2p_pubsub.ValidationIgnore {
+		t.Errorf("got %v, expect the message over the full queue ignored", result)
+	}
+	// the other topics are not delayed
+	if result := pool.validate(ctx, "b", reject); result != libp2p_pubsub.ValidationReject {
+		t.Errorf("got %v, expect the result of the validation on topic b", result)
+	}
+	// the deadline passes while waiting in the queue of topic c
+	running, pending := make(chan struct{}), make(chan struct{})
+	go pool.validate(ctx, "c", func(context.Context) libp2p_pubsub.ValidationResult {
+		close(running)
+		<-release
+		close(pending)
+		return libp2p_pubsub.ValidationAccept
+	})
+	<-running
+	expired, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if result := pool.validate(expired, "c", reject); result != libp2p_pubsub.ValidationIgnore {
+		t.Errorf("got %v, expect the message past its deadline ignored", result)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		<-done
+	}
+	<-pending
+}
+
+func TestValidationPoolInline(t *testing.T) {
+	pool := newValidationPool(nodeconfig.ValidationPool{})
+	accept := func(context.Context) libp2p_pubsub.ValidationResult {
+		return libp2p_pubsub.ValidationAccept
+	}
+	if result := pool.validate(context.Background(), "a", accept); result != libp2p_pubsub.ValidationAccept {
+		t.Errorf("got %v, expect the result of the inline validation", result)
+	}
+	if len(pool.queueLengths()) != 0 {
+		t.Error("expect no queue without workers")
+	}
+}
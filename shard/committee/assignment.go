@@ -39,6 +39,9 @@ type StakingCandidatesReader interface {
 	ReadValidatorInformation(addr common.Address) (*staking.ValidatorWrapper, error)
 	ReadValidatorSnapshot(addr common.Address) (*staking.ValidatorSnapshot, error)
 	ValidatorCandidates() []common.Address
+	ReadGovernedExternalSlots() (int, bool)
+	ReadValidatorShardPreference(addr common.Address) staking.ShardPreference
+	Config() *params.ChainConfig
 }
 
 // CandidatesForEPoS ..
@@ -80,13 +83,11 @@ func (p CandidateOrder) MarshalJSON() ([]byte, error) {
 func NewEPoSRound(epoch *big.Int, stakedReader StakingCandidatesReader) (
 	*CompletedEPoSRound, error,
 ) {
-	eligibleCandidate, err := prepareOrders(stakedReader)
+	eligibleCandidate, err := prepareOrders(stakedReader, nil)
 	if err != nil {
 		return nil, err
 	}
-	maxExternalSlots := shard.ExternalSlotsAvailableForEpoch(
-		epoch,
-	)
+	maxExternalSlots := ExternalSlotsForEpoch(epoch, stakedReader)
 	median, winners := effective.Apply(
 		eligibleCandidate, maxExternalSlots,
 	)
@@ -121,8 +122,20 @@ func NewEPoSRound(epoch *big.Int, stakedReader StakingCandidatesReader) (
 	}, nil
 }
 
+// ExternalSlotsForEpoch returns the external slots of the committees of the
+// epoch, from the slots of each committee set by the governance if any or
+// else from the sharding schedule
+func ExternalSlotsForEpoch(epoch *big.Int, stakedReader StakingCandidatesReader) int {
+	if slots, ok := stakedReader.ReadGovernedExternalSlots(); ok {
+		return slots * int(shard.Schedule.InstanceForEpoch(epoch).NumShards())
+	}
+	return shard.ExternalSlotsAvailableForEpoch(epoch)
+}
+
+// prepareOrders returns the slot orders of the candidates eligible to the
+// auction, with the reasons of the others in excluded when not nil
 func prepareOrders(
-	stakedReader StakingCandidatesReader,
+	stakedReader StakingCandidatesReader, excluded map[common.Address]string,
 ) (map[common.Address]*effective.SlotOrder, error) {
 	candidates := stakedReader.ValidatorCandidates()
 	blsKeys := map[shard.BLSPublicKey]struct{}{}
@@ -130,7 +143,9 @@ func prepareOrders(
 	totalStaked, tempZero := big.NewInt(0), numeric.ZeroDec()
 
 	// Avoid duplicate BLS keys as harmony nodes
-	instance := shard.Schedule.InstanceForEpoch(stakedReader.CurrentBlock().Epoch())
+	epoch := stakedReader.CurrentBlock().Epoch()
+	instance := shard.Schedule.InstanceForEpoch(epoch)
+	threshold := stakedReader.Config().AvailabilityThreshold(epoch)
 	for _, account := range instance.HmyAccounts() {
 		pub := &bls.PublicKey{}
 		if err := pub.DeserializeHexStr(account.BLSPublicKey); err != nil {
@@ -156,7 +171,10 @@ func prepareOrders(
 		if err != nil {
 			return nil, err
 		}
-		if !IsEligibleForEPoSAuction(snapshot, validator) {
+		if !IsEligibleForEPoSAuction(snapshot, validator, threshold) {
+			if excluded != nil {
+				excluded[candidates[i]] = ExcludedNotEligible
+			}
 			continue
 		}
 
@@ -170,6 +188,9 @@ func prepareOrders(
 		}
 
 		if found {
+			if excluded != nil {
+				excluded[candidates[i]] = ExcludedDuplicateKey
+			}
 			continue
 		}
 
@@ -198,7 +219,9 @@ func prepareOrders(
 }
 
 // IsEligibleForEPoSAuction ..
-func IsEligibleForEPoSAuction(snapshot *staking.ValidatorSnapshot, validator *staking.ValidatorWrapper) bool {
+func IsEligibleForEPoSAuction(
+	snapshot *staking.ValidatorSnapshot, validator *staking.ValidatorWrapper, threshold numeric.Dec,
+) bool {
 	// This original condition to check whether a validator is in last committee is not stable
 	// because cross-links may arrive after the epoch ends and it still got counted into the
 	// NumBlocksToSign, making this condition to be true when the validator is actually not in committee
@@ -209,7 +232,7 @@ func IsEligibleForEPoSAuction(snapshot *staking.ValidatorSnapshot, validator *st
 		// validator was in last epoch's committee
 		// validator with below-threshold signing activity won't be considered for next epoch
 		// and their status will be turned to inactive in FinalizeNewBlock
-		computed := availability.ComputeCurrentSigning(snapshot.Validator, validator)
+		computed := availability.ComputeCurrentSigning(snapshot.Validator, validator, threshold)
 		if computed.IsBelowThreshold {
 			return false
 		}
@@ -305,27 +328,12 @@ func eposStakedCommittee(
 	shardCount := int(s.NumShards())
 	shardState := &shard.State{}
 	shardState.Shards = make([]shard.Committee, shardCount)
-	hAccounts := s.HmyAccounts()
-	shardHarmonyNodes := s.NumHarmonyOperatedNodesPerShard()
-
+	internal, err := internalSlots(epoch, s, stakerReader)
+	if err != nil {
+		return nil, err
+	}
 	for i := 0; i < shardCount; i++ {
-		shardState.Shards[i] = shard.Committee{uint32(i), shard.SlotList{}}
-		for j := 0; j < shardHarmonyNodes; j++ {
-			index := i + j*shardCount
-			pub := &bls.PublicKey{}
-			if err := pub.DeserializeHexStr(hAccounts[index].BLSPublicKey); err != nil {
-				return nil, err
-			}
-			pubKey := shard.BLSPublicKey{}
-			if err := pubKey.FromLibBLSPublicKey(pub); err != nil {
-				return nil, err
-			}
-			shardState.Shards[i].Slots = append(shardState.Shards[i].Slots, shard.Slot{
-				common2.ParseAddr(hAccounts[index].Address),
-				pubKey,
-				nil,
-			})
-		}
+		shardState.Shards[i] = shard.Committee{ShardID: uint32(i), Slots: internal[i]}
 	}
 
 	// TODO(audit): make sure external validator BLS key are also not duplicate to Harmony's keys
@@ -335,10 +343,14 @@ func eposStakedCommittee(
 		return nil, err
 	}
 
-	shardBig := big.NewInt(int64(shardCount))
+	shardIDs := assignShards(
+		completedEPoSRound.AuctionWinners, shardCount,
+		ExternalSlotsForEpoch(epoch, stakerReader)/shardCount,
+		stickyShards(epoch, stakerReader),
+	)
 	for i := range completedEPoSRound.AuctionWinners {
 		purchasedSlot := completedEPoSRound.AuctionWinners[i]
-		shardID := int(new(big.Int).Mod(purchasedSlot.Key.Big(), shardBig).Int64())
+		shardID := shardIDs[i]
 		shardState.Shards[shardID].Slots = append(
 			shardState.Shards[shardID].Slots, shard.Slot{
 				purchasedSlot.Addr,
@@ -351,6 +363,175 @@ func eposStakedCommittee(
 	return shardState, nil
 }
 
+// harmonySlots returns the slots of the harmony accounts of the instance
+func harmonySlots(s shardingconfig.Instance) (shard.SlotList, error) {
+	hAccounts := s.HmyAccounts()
+	slots := make(shard.SlotList, len(hAccounts))
+	for i := range hAccounts {
+		pub := &bls.PublicKey{}
+		if err := pub.DeserializeHexStr(hAccounts[i].BLSPublicKey); err != nil {
+			return nil, err
+		}
+		pubKey := shard.BLSPublicKey{}
+		if err := pubKey.FromLibBLSPublicKey(pub); err != nil {
+			return nil, err
+		}
+		slots[i] = shard.Slot{
+			common2.ParseAddr(hAccounts[i].Address),
+			pubKey,
+			nil,
+		}
+	}
+	return slots, nil
+}
+
+// internalSlots returns the harmony operated slots of each shard. They are
+// the ones of the previous epoch with a part of them rotated when the chain
+// config schedules a rotation, and the first harmony accounts otherwise.
+func internalSlots(
+	epoch *big.Int, s shardingconfig.Instance, stakerReader DataProvider,
+) ([]shard.SlotList, error) {
+	shardCount := int(s.NumShards())
+	shardHarmonyNodes := s.NumHarmonyOperatedNodesPerShard()
+	pool, err := harmonySlots(s)
+	if err != nil {
+		return nil, err
+	}
+	config := stakerReader.Config()
+	rotated := shardHarmonyNodes * int(config.InternalRotationPercent(epoch)) / 100
+	if rotated > 0 && epoch.Sign() > 0 {
+		prevEpoch := new(big.Int).Sub(epoch, common.Big1)
+		if prev, err := stakerReader.ReadShardState(prevEpoch); err == nil && prev != nil &&
+			config.IsStaking(prevEpoch) && len(prev.Shards) == shardCount {
+			previous := make([]shard.SlotList, shardCount)
+			for i, committee := range prev.Shards {
+				for _, slot := range committee.Slots {
+					if slot.EffectiveStake == nil {
+						previous[i] = append(previous[i], slot)
+					}
+				}
+			}
+			if slots, ok := rotateInternalSlots(previous, pool, shardHarmonyNodes, rotated); ok {
+				return slots, nil
+			}
+		}
+	}
+
+	slots := make([]shard.SlotList, shardCount)
+	for i := 0; i < shardCount; i++ {
+		slots[i] = shard.SlotList{}
+		for j := 0; j < shardHarmonyNodes; j++ {
+			slots[i] = append(slots[i], pool[i+j*shardCount])
+		}
+	}
+	return slots, nil
+}
+
+// rotateInternalSlots replaces the first rotated harmony operated slots of
+// each shard, which are the oldest ones, with the harmony accounts following
+// the last account given a slot, then with the replaced slots of the other
+// shards once all the harmony accounts have a slot. It returns false when the
+// previous slots do not match the per shard count or the harmony accounts.
+func rotateInternalSlots(
+	previous []shard.SlotList, pool shard.SlotList, perShard, rotated int,
+) ([]shard.SlotList, bool) {
+	if len(previous) == 0 || rotated > perShard {
+		return nil, false
+	}
+	index := make(map[shard.BLSPublicKey]int, len(pool))
+	for i, slot := range pool {
+		index[slot.BLSPublicKey] = i
+	}
+	used := make(map[int]struct{}, len(previous)*perShard)
+	last := 0
+	for _, slots := range previous {
+		if len(slots) != perShard {
+			return nil, false
+		}
+		for _, slot := range slots {
+			i, ok := index[slot.BLSPublicKey]
+			if !ok {
+				return nil, false
+			}
+			used[i] = struct{}{}
+			last = i
+		}
+	}
+	if len(used) != len(previous)*perShard {
+		return nil, false
+	}
+
+	queue := shard.SlotList{}
+	for n := 1; n <= len(pool); n++ {
+		i := (last + n) % len(pool)
+		if _, ok := used[i]; !ok {
+			queue = append(queue, pool[i])
+		}
+	}
+	// the replaced slots of a shard are not given back to the same shard
+	start := len(queue)/rotated + 1
+	for n := 0; n < len(previous); n++ {
+		queue = append(queue, previous[(start+n)%len(previous)][:rotated]...)
+	}
+	slots := make([]shard.SlotList, len(previous))
+	for i := range previous {
+		slots[i] = append(shard.SlotList{}, previous[i][rotated:]...)
+		slots[i] = append(slots[i], queue[i*rotated:(i+1)*rotated]...)
+	}
+	return slots, true
+}
+
+// stickyShards returns the shards of the previous epoch of the keys of the
+// validators preferring to stay on their shard
+func stickyShards(epoch *big.Int, stakerReader DataProvider) map[shard.BLSPublicKey]int {
+	sticky := map[shard.BLSPublicKey]int{}
+	if !stakerReader.Config().IsShardPreference(epoch) || epoch.Sign() == 0 {
+		return sticky
+	}
+	prev, err := stakerReader.ReadShardState(new(big.Int).Sub(epoch, common.Big1))
+	if err != nil || prev == nil {
+		return sticky
+	}
+	prefs := map[common.Address]staking.ShardPreference{}
+	for _, committee := range prev.Shards {
+		for _, slot := range committee.Slots {
+			if slot.EffectiveStake == nil {
+				continue
+			}
+			pref, ok := prefs[slot.EcdsaAddress]
+			if !ok {
+				pref = stakerReader.ReadValidatorShardPreference(slot.EcdsaAddress)
+				prefs[slot.EcdsaAddress] = pref
+			}
+			if pref == staking.StayOnShard {
+				sticky[slot.BLSPublicKey] = int(committee.ShardID)
+			}
+		}
+	}
+	return sticky
+}
+
+// assignShards returns the shards of the auction winners, the shard of
+// their key unless they stay on their shard of the previous epoch. A winner
+// stays on its shard while the shard has less than perShard external slots.
+func assignShards(
+	winners []effective.SlotPurchase, shardCount, perShard int,
+	sticky map[shard.BLSPublicKey]int,
+) []int {
+	shardBig := big.NewInt(int64(shardCount))
+	external := make([]int, shardCount)
+	shardIDs := make([]int, len(winners))
+	for i := range winners {
+		shardID := int(new(big.Int).Mod(winners[i].Key.Big(), shardBig).Int64())
+		if prev, ok := sticky[winners[i].Key]; ok && prev < shardCount && external[prev] < perShard {
+			shardID = prev
+		}
+		external[shardID]++
+		shardIDs[i] = shardID
+	}
+	return shardIDs
+}
+
 // ReadFromDB is a wrapper on ReadShardState
 func (def partialStakingEnabled) ReadFromDB(
 	epoch *big.Int, reader DataProvider,
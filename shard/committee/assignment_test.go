@@ -0,0 +1,87 @@
+package committee
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/effective"
+)
+
+func TestAssignShards(t *testing.T) {
+	winners := make([]effective.SlotPurchase, 4)
+	for i := range winners {
+		// keys 1, 2, 3 and 4 belong to shards 1, 0, 1 and 0
+		winners[i].Key[shard.PublicKeySizeInBytes-1] = byte(i + 1)
+	}
+	tests := []struct {
+		sticky map[shard.BLSPublicKey]int
+		expect []int
+	}{
+		{map[shard.BLSPublicKey]int{}, []int{1, 0, 1, 0}},
+		// key 2 stays on shard 1
+		{map[shard.BLSPublicKey]int{winners[1].Key: 1}, []int{1, 1, 1, 0}},
+		// shard 1 is full for key 4
+		{map[shard.BLSPublicKey]int{winners[1].Key: 1, winners[3].Key: 1}, []int{1, 1, 1, 0}},
+		// shard 0 has room for keys 1 and 3, and shard 2 is gone
+		{map[shard.BLSPublicKey]int{winners[0].Key: 0, winners[2].Key: 2}, []int{0, 0, 1, 0}},
+	}
+	for i, test := range tests {
+		shardIDs := assignShards(winners, 2, 2, test.sticky)
+		for j := range shardIDs {
+			if shardIDs[j] != test.expect[j] {
+				t.Errorf("test %d: got shards %v, expect %v", i, shardIDs, test.expect)
+				break
+			}
+		}
+	}
+}
+
+func TestRotateInternalSlots(t *testing.T) {
+	pool := make(shard.SlotList, 6)
+	for i := range pool {
+		pool[i].BLSPublicKey[shard.PublicKeySizeInBytes-1] = byte(i)
+	}
+	keys := func(slots []shard.SlotList) [][]byte {
+		result := make([][]byte, len(slots))
+		for i := range slots {
+			for _, slot := range slots[i] {
+				result[i] = append(result[i], slot.BLSPublicKey[shard.PublicKeySizeInBytes-1])
+			}
+		}
+		return result
+	}
+	tests := []struct {
+		previous [][]int
+		rotated  int
+		expect   [][]byte
+		ok       bool
+	}{
+		// the accounts 4 and 5 have no slot
+		{[][]int{{0, 2}, {1, 3}}, 1, [][]byte{{2, 4}, {3, 5}}, true},
+		// the accounts after the last one given a slot come first
+		{[][]int{{2, 4}, {3, 5}}, 1, [][]byte{{4, 0}, {5, 1}}, true},
+		{[][]int{{0, 1}, {2, 3}}, 2, [][]byte{{4, 5}, {0, 1}}, true},
+		// all the accounts have a slot
+		{[][]int{{0, 2, 4}, {1, 3, 5}}, 1, [][]byte{{2, 4, 1}, {3, 5, 0}}, true},
+		// the slots do not match the harmony accounts
+		{[][]int{{0, 2}, {1}}, 1, nil, false},
+		{[][]int{{0, 2}, {2, 3}}, 1, nil, false},
+	}
+	for i, test := range tests {
+		previous := make([]shard.SlotList, len(test.previous))
+		for j := range test.previous {
+			for _, k := range test.previous[j] {
+				previous[j] = append(previous[j], pool[k])
+			}
+		}
+		slots, ok := rotateInternalSlots(previous, pool, len(test.previous[0]), test.rotated)
+		if ok != test.ok {
+			t.Errorf("test %d: got %v, expect %v", i, ok, test.ok)
+			continue
+		}
+		if got := keys(slots); !reflect.DeepEqual(got, test.expect) && ok {
+			t.Errorf("test %d: got keys %v, expect %v", i, got, test.expect)
+		}
+	}
+}
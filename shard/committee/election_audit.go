@@ -0,0 +1,122 @@
+package committee
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/effective"
+)
+
+// Reasons of the candidates left out of the auction of an election
+const (
+	ExcludedNotEligible  = "not eligible"
+	ExcludedDuplicateKey = "duplicate key"
+)
+
+// ElectionAudit is the record of an election of the external slots of the
+// committees of an epoch, which is enough to reproduce its result.
+type ElectionAudit struct {
+	Epoch            *big.Int           `json:"epoch"`
+	BlockNumber      uint64             `json:"block-number"`
+	MedianStake      numeric.Dec        `json:"median-stake"`
+	MaxExternalSlots int                `json:"max-external-slots"`
+	Candidates       []AuditedCandidate `json:"candidates"`
+	Slots            []AuditedSlot      `json:"slots"`
+}
+
+// AuditedCandidate is a validator candidate of an election. The candidates
+// left out of the auction have the reason why and no stake.
+type AuditedCandidate struct {
+	Validator common.Address       `json:"validator"`
+	Stake     *big.Int             `json:"stake,omitempty"`
+	Keys      []shard.BLSPublicKey `json:"keys,omitempty"`
+	Excluded  string               `json:"excluded,omitempty"`
+}
+
+// AuditedSlot is a slot at auction in an election, in auction order. The
+// ties are broken by the validator address and then by the order of the
+// keys of the validator, and TieBreak marks the slots of the raw stake of a
+// tie between elected and not elected slots. The elected slots have their
+// effective stake and their position in the elected committees.
+type AuditedSlot struct {
+	Validator common.Address     `json:"validator"`
+	Key       shard.BLSPublicKey `json:"key"`
+	RawStake  numeric.Dec        `json:"raw-stake"`
+	EPoSStake *numeric.Dec       `json:"epos-stake,omitempty"`
+	Elected   bool               `json:"elected"`
+	TieBreak  bool               `json:"tie-break,omitempty"`
+	ShardID   *uint32            `json:"shard-id,omitempty"`
+	Index     *int               `json:"index,omitempty"`
+}
+
+// NewElectionAudit runs the auction of the election of the epoch again from
+// the current data, and records it together with the position of the elected
+// slots in the elected committees.
+func NewElectionAudit(
+	epoch *big.Int, stakedReader StakingCandidatesReader, elected *shard.State,
+) (*ElectionAudit, error) {
+	excluded := map[common.Address]string{}
+	orders, err := prepareOrders(stakedReader, excluded)
+	if err != nil {
+		return nil, err
+	}
+	maxExternalSlots := ExternalSlotsForEpoch(epoch, stakedReader)
+	ordered := effective.Order(orders)
+	median, winners := effective.Apply(orders, maxExternalSlots)
+
+	audit := &ElectionAudit{
+		Epoch:            new(big.Int).Set(epoch),
+		MedianStake:      median,
+		MaxExternalSlots: maxExternalSlots,
+		Candidates:       []AuditedCandidate{},
+		Slots:            make([]AuditedSlot, len(ordered)),
+	}
+	for _, addr := range stakedReader.ValidatorCandidates() {
+		candidate := AuditedCandidate{Validator: addr}
+		if order, ok := orders[addr]; ok {
+			candidate.Stake = order.Stake
+			candidate.Keys = order.SpreadAmong
+		} else {
+			candidate.Excluded = excluded[addr]
+		}
+		audit.Candidates = append(audit.Candidates, candidate)
+	}
+
+	type position struct {
+		shardID uint32
+		index   int
+	}
+	positions := map[shard.BLSPublicKey]position{}
+	if elected != nil {
+		for _, committee := range elected.Shards {
+			for i, slot := range committee.Slots {
+				if slot.EffectiveStake != nil {
+					positions[slot.BLSPublicKey] = position{committee.ShardID, i}
+				}
+			}
+		}
+	}
+	tie := len(winners) > 0 && len(ordered) > len(winners) &&
+		ordered[len(winners)].RawStake.Equal(winners[len(winners)-1].RawStake)
+	for i, slot := range ordered {
+		audited := AuditedSlot{
+			Validator: slot.Addr,
+			Key:       slot.Key,
+			RawStake:  slot.RawStake,
+			Elected:   i < len(winners),
+			TieBreak:  tie && slot.RawStake.Equal(winners[len(winners)-1].RawStake),
+		}
+		if audited.Elected {
+			stake := winners[i].EPoSStake
+			audited.EPoSStake = &stake
+			if p, ok := positions[slot.Key]; ok {
+				shardID, index := p.shardID, p.index
+				audited.ShardID, audited.Index = &shardID, &index
+			}
+		}
+		audit.Slots[i] = audited
+	}
+	return audit, nil
+}
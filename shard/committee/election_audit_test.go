@@ -0,0 +1,129 @@
+package committee
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/harmony-one/harmony/staking/effective"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+type auditReader struct {
+	validators map[common.Address]*staking.ValidatorWrapper
+	candidates []common.Address
+}
+
+func (r auditReader) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(blockfactory.NewTestHeader())
+}
+
+func (r auditReader) ReadValidatorInformation(addr common.Address) (*staking.ValidatorWrapper, error) {
+	return r.validators[addr], nil
+}
+
+func (r auditReader) ReadValidatorSnapshot(addr common.Address) (*staking.ValidatorSnapshot, error) {
+	return &staking.ValidatorSnapshot{Validator: r.validators[addr], Epoch: big.NewInt(1)}, nil
+}
+
+func (r auditReader) ValidatorCandidates() []common.Address {
+	return r.candidates
+}
+
+func (r auditReader) ReadGovernedExternalSlots() (int, bool) {
+	// one external slot on each of the 4 shards of the epoch 0
+	return 1, true
+}
+
+func (r auditReader) ReadValidatorShardPreference(addr common.Address) staking.ShardPreference {
+	return staking.NoShardPreference
+}
+
+func (r auditReader) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+func TestNewElectionAudit(t *testing.T) {
+	key := func(b byte) shard.BLSPublicKey {
+		k := shard.BLSPublicKey{}
+		k[0], k[shard.PublicKeySizeInBytes-1] = 0xff, b
+		return k
+	}
+	reader := auditReader{validators: map[common.Address]*staking.ValidatorWrapper{}}
+	add := func(addr byte, stake int64, status effective.Eligibility, keys ...shard.BLSPublicKey) {
+		address := common.BytesToAddress([]byte{addr})
+		reader.validators[address] = &staking.ValidatorWrapper{
+			Validator: staking.Validator{
+				Address:              address,
+				SlotPubKeys:          keys,
+				LastEpochInCommittee: big.NewInt(0),
+				Status:               status,
+			},
+			Delegations: staking.Delegations{{Amount: big.NewInt(stake)}},
+		}
+		reader.candidates = append(reader.candidates, address)
+	}
+	add(3, 300, effective.Active, key(3))
+	add(1, 200, effective.Active, key(1), key(2))
+	add(2, 100, effective.Active, key(4))
+	add(4, 100, effective.Inactive, key(5))
+	add(5, 100, effective.Active, key(3))
+	add(6, 100, effective.Active, key(6))
+
+	elected := &shard.State{Shards: []shard.Committee{{
+		ShardID: 2,
+		Slots:   shard.SlotList{{}, {BLSPublicKey: key(4), EffectiveStake: &numeric.Dec{}}},
+	}}}
+	audit, err := NewElectionAudit(big.NewInt(0), reader, elected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.MaxExternalSlots != 4 || len(audit.Candidates) != 6 {
+		t.Fatalf("got %d slots and %d candidates, expect 4 and 6", audit.MaxExternalSlots, len(audit.Candidates))
+	}
+	if audit.Candidates[3].Excluded != ExcludedNotEligible || audit.Candidates[4].Excluded != ExcludedDuplicateKey {
+		t.Errorf("got exclusions %q %q", audit.Candidates[3].Excluded, audit.Candidates[4].Excluded)
+	}
+	if audit.Candidates[1].Stake.Int64() != 200 || len(audit.Candidates[1].Keys) != 2 {
+		t.Errorf("got candidate %+v", audit.Candidates[1])
+	}
+
+	// the slots of 100 are ordered by validator address, the last one is not elected
+	expect := []struct {
+		key      byte
+		elected  bool
+		tieBreak bool
+	}{{3, true, false}, {1, true, true}, {2, true, true}, {4, true, true}, {6, false, true}}
+	if len(audit.Slots) != len(expect) {
+		t.Fatalf("got %d slots, expect %d", len(audit.Slots), len(expect))
+	}
+	for i, slot := range audit.Slots {
+		if slot.Key != key(expect[i].key) || slot.Elected != expect[i].elected ||
+			slot.TieBreak != expect[i].tieBreak || (slot.EPoSStake != nil) != slot.Elected {
+			t.Errorf("slot %d: got %+v", i, slot)
+		}
+	}
+	if slot := audit.Slots[3]; slot.ShardID == nil || *slot.ShardID != 2 || *slot.Index != 1 {
+		t.Errorf("got position %v %v, expect slot 1 of shard 2", slot.ShardID, slot.Index)
+	}
+
+	// the audit logs are stored in JSON
+	data, err := json.Marshal(audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &ElectionAudit{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, audit) {
+		t.Errorf("got decoded audit %+v, expect %+v", decoded, audit)
+	}
+}
@@ -0,0 +1,110 @@
+package shard
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/crypto/hash"
+	"github.com/pkg/errors"
+)
+
+// ErrStateDeltaBase is returned applying a shard state delta to another shard
+// state than the one it was made against
+var ErrStateDeltaBase = errors.New("shard state delta made against another shard state")
+
+// StateDelta is a shard state encoded against the shard state of the
+// previous epoch. The committees change little from an epoch to the next,
+// so that most slots are a reference to a slot of the previous committee.
+// Base is the RLP hash of the shard state of the previous epoch, so that a
+// delta is not applied to a shard state rewritten since, on a fork.
+type StateDelta struct {
+	Epoch  *big.Int `rlp:"nil"`
+	Base   common.Hash
+	Shards []CommitteeDelta
+}
+
+// CommitteeDelta is a committee encoded against the committee of the same
+// shard in the previous epoch
+type CommitteeDelta struct {
+	ShardID uint32
+	Slots   []SlotDelta
+}
+
+// SlotDelta is either the index+1 of a slot of the previous committee, or
+// a new slot with a zero index
+type SlotDelta struct {
+	Index uint32
+	New   SlotList // the new slot, empty for a slot of the previous committee
+}
+
+// slotKey identifies a slot, with its effective stake
+func slotKey(slot Slot) string {
+	stake := "nil"
+	if slot.EffectiveStake != nil {
+		stake = slot.EffectiveStake.String()
+	}
+	return string(slot.EcdsaAddress[:]) + string(slot.BLSPublicKey[:]) + stake
+}
+
+// NewStateDelta returns the delta of the shard state next against the shard
+// state prev of the previous epoch
+func NewStateDelta(prev, next *State) *StateDelta {
+	previous := map[uint32]map[string]int{}
+	for _, committee := range prev.Shards {
+		indexes := make(map[string]int, len(committee.Slots))
+		for i, slot := range committee.Slots {
+			if _, ok := indexes[slotKey(slot)]; !ok {
+				indexes[slotKey(slot)] = i
+			}
+		}
+		previous[committee.ShardID] = indexes
+	}
+	delta := &StateDelta{
+		Epoch:  next.Epoch,
+		Base:   hash.FromRLP(prev),
+		Shards: make([]CommitteeDelta, len(next.Shards)),
+	}
+	for i, committee := range next.Shards {
+		slots := make([]SlotDelta, len(committee.Slots))
+		for j, slot := range committee.Slots {
+			if index, ok := previous[committee.ShardID][slotKey(slot)]; ok {
+				slots[j] = SlotDelta{Index: uint32(index + 1)}
+			} else {
+				slots[j] = SlotDelta{New: SlotList{slot}}
+			}
+		}
+		delta.Shards[i] = CommitteeDelta{ShardID: committee.ShardID, Slots: slots}
+	}
+	return delta
+}
+
+// Apply returns the shard state of the delta against the shard state prev
+// of the previous epoch
+func (d *StateDelta) Apply(prev *State) (*State, error) {
+	if base := hash.FromRLP(prev); base != d.Base {
+		return nil, errors.Wrapf(ErrStateDeltaBase, "base %x, not %x", base, d.Base)
+	}
+	previous := map[uint32]SlotList{}
+	for _, committee := range prev.Shards {
+		previous[committee.ShardID] = committee.Slots
+	}
+	state := &State{Epoch: d.Epoch, Shards: make([]Committee, len(d.Shards))}
+	for i, committee := range d.Shards {
+		slots := make(SlotList, len(committee.Slots))
+		for j, slot := range committee.Slots {
+			switch {
+			case slot.Index == 0 && len(slot.New) == 1:
+				slots[j] = slot.New[0]
+			case slot.Index > 0 && int(slot.Index) <= len(previous[committee.ShardID]):
+				slots[j] = previous[committee.ShardID][slot.Index-1]
+			default:
+				return nil, errors.Errorf(
+					"invalid slot %d of shard %d in shard state delta", j, committee.ShardID,
+				)
+			}
+		}
+		state.Shards[i] = Committee{ShardID: committee.ShardID, Slots: slots}
+	}
+	state.BuildIndex()
+	return state, nil
+}
@@ -0,0 +1,75 @@
+package shard
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Resharding is a change of the number of shards from one epoch to the next.
+// The shards keep their IDs across it: a split adds shards which start with
+// an empty state, and a merge retires the shards over the new number of
+// shards, the balances of the accounts the chain config lists for them moving
+// to their destination shard in the last block of the epoch before the
+// resharding.
+type Resharding struct {
+	Epoch *big.Int // first epoch with the new number of shards
+	From  uint32   // number of shards before the resharding
+	To    uint32   // number of shards from Epoch on
+}
+
+// ReshardingAt returns the resharding at epoch, if the schedule changes the
+// number of shards at epoch
+func ReshardingAt(epoch *big.Int) (Resharding, bool) {
+	if epoch == nil || epoch.Sign() <= 0 {
+		return Resharding{}, false
+	}
+	prev := new(big.Int).Sub(epoch, common.Big1)
+	r := Resharding{
+		Epoch: new(big.Int).Set(epoch),
+		From:  Schedule.InstanceForEpoch(prev).NumShards(),
+		To:    Schedule.InstanceForEpoch(epoch).NumShards(),
+	}
+	return r, r.From != r.To
+}
+
+// Retires returns whether the shard is merged into another shard by the
+// resharding
+func (r Resharding) Retires(shardID uint32) bool {
+	return shardID >= r.To && shardID < r.From
+}
+
+// Destination returns the shard the accounts of the shard belong to after
+// the resharding
+func (r Resharding) Destination(shardID uint32) uint32 {
+	if shardID < r.To || r.To == 0 {
+		return shardID
+	}
+	return shardID % r.To
+}
+
+// Sources returns the shards whose accounts belong to the shard after the
+// resharding, the shard itself first if it existed before
+func (r Resharding) Sources(shardID uint32) []uint32 {
+	sources := []uint32{}
+	if shardID < r.From {
+		sources = append(sources, shardID)
+	}
+	for i := r.To; i < r.From; i++ {
+		if r.Destination(i) == shardID {
+			sources = append(sources, i)
+		}
+	}
+	return sources
+}
+
+// CrossLinkShards returns the number of shards the beacon chain tracks the
+// cross links of at epoch, which includes the shards retired at epoch as
+// their last cross links are only received after the resharding
+func CrossLinkShards(epoch *big.Int) uint32 {
+	numShards := Schedule.InstanceForEpoch(epoch).NumShards()
+	if r, ok := ReshardingAt(epoch); ok && r.From > numShards {
+		return r.From
+	}
+	return numShards
+}
@@ -0,0 +1,78 @@
+package shard
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	shardingconfig "github.com/harmony-one/harmony/internal/configs/sharding"
+)
+
+type testInstance struct {
+	shardingconfig.Instance
+	numShards uint32
+}
+
+func (i testInstance) NumShards() uint32 {
+	return i.numShards
+}
+
+// testSchedule merges 4 shards into 2 at epoch 5, and splits them into 3 at epoch 8
+type testSchedule struct {
+	shardingconfig.Schedule
+}
+
+func (s testSchedule) InstanceForEpoch(epoch *big.Int) shardingconfig.Instance {
+	switch {
+	case epoch.Cmp(big.NewInt(8)) >= 0:
+		return testInstance{numShards: 3}
+	case epoch.Cmp(big.NewInt(5)) >= 0:
+		return testInstance{numShards: 2}
+	default:
+		return testInstance{numShards: 4}
+	}
+}
+
+func TestResharding(t *testing.T) {
+	defer func(s shardingconfig.Schedule) { Schedule = s }(Schedule)
+	Schedule = testSchedule{}
+
+	if _, ok := ReshardingAt(big.NewInt(4)); ok {
+		t.Error("expect no resharding at epoch 4")
+	}
+	merge, ok := ReshardingAt(big.NewInt(5))
+	if !ok || merge.From != 4 || merge.To != 2 {
+		t.Fatalf("got resharding %+v, expect 4 shards merged into 2", merge)
+	}
+	for shardID, retired := range []bool{false, false, true, true} {
+		if merge.Retires(uint32(shardID)) != retired {
+			t.Errorf("shard %d: expect retired %v", shardID, retired)
+		}
+	}
+	if dest := merge.Destination(3); dest != 1 {
+		t.Errorf("got destination %d of shard 3, expect 1", dest)
+	}
+	if sources := merge.Sources(0); !reflect.DeepEqual(sources, []uint32{0, 2}) {
+		t.Errorf("got sources %v of shard 0, expect [0 2]", sources)
+	}
+
+	split, ok := ReshardingAt(big.NewInt(8))
+	if !ok || split.Retires(1) || split.Retires(2) {
+		t.Errorf("got resharding %+v, expect a split retiring no shard", split)
+	}
+	if sources := split.Sources(2); len(sources) != 0 {
+		t.Errorf("got sources %v of the new shard, expect none", sources)
+	}
+
+	tests := []struct {
+		epoch  int64
+		shards uint32
+	}{
+		{4, 4}, {5, 4}, {6, 2}, {8, 3},
+	}
+	for _, test := range tests {
+		if shards := CrossLinkShards(big.NewInt(test.epoch)); shards != test.shards {
+			t.Errorf("epoch %d: got %d cross link shards, expect %d", test.epoch, shards, test.shards)
+		}
+	}
+}
@@ -35,6 +35,7 @@ const (
 type State struct {
 	Epoch  *big.Int    `json:"epoch"`
 	Shards []Committee `json:"shards"`
+	index  map[uint32]int
 }
 
 // BLSPublicKey defines the bls public key
@@ -58,6 +59,7 @@ type SlotList []Slot
 type Committee struct {
 	ShardID uint32   `json:"shard-id"`
 	Slots   SlotList `json:"subcommittee"`
+	index   map[BLSPublicKey]int
 }
 
 func (l SlotList) String() string {
@@ -102,6 +104,7 @@ func DecodeWrapper(shardState []byte) (*State, error) {
 	)
 	err1 = rlp.DecodeBytes(shardState, &newSS)
 	if err1 == nil {
+		newSS.BuildIndex()
 		return &newSS, nil
 	}
 	err2 = rlp.DecodeBytes(shardState, &oldSS)
@@ -117,6 +120,7 @@ func DecodeWrapper(shardState []byte) (*State, error) {
 			}
 		}
 		newSS.Epoch = nil // Make sure for legacy state, the epoch is nil
+		newSS.BuildIndex()
 		return &newSS, nil
 	}
 	return nil, err2
@@ -274,6 +278,9 @@ func (ss *State) FindCommitteeByID(shardID uint32) (*Committee, error) {
 	if ss == nil {
 		return nil, ErrShardIDNotInSuperCommittee
 	}
+	if i, ok := ss.index[shardID]; ok && i < len(ss.Shards) && ss.Shards[i].ShardID == shardID {
+		return &ss.Shards[i], nil
+	}
 	for committee := range ss.Shards {
 		if ss.Shards[committee].ShardID == shardID {
 			return &ss.Shards[committee], nil
@@ -282,6 +289,29 @@ func (ss *State) FindCommitteeByID(shardID uint32) (*Committee, error) {
 	return nil, ErrShardIDNotInSuperCommittee
 }
 
+// BuildIndex indexes the committees by shard ID and their slots by BLS key,
+// for the lookups of FindCommitteeByID and AddressForBLSKey. The index is
+// only a hint checked on lookup, so the state should not be modified after.
+func (ss *State) BuildIndex() {
+	ss.index = make(map[uint32]int, len(ss.Shards))
+	for i := range ss.Shards {
+		if _, ok := ss.index[ss.Shards[i].ShardID]; !ok {
+			ss.index[ss.Shards[i].ShardID] = i
+		}
+		ss.Shards[i].buildIndex()
+	}
+}
+
+// buildIndex indexes the slots of the committee by BLS key
+func (c *Committee) buildIndex() {
+	c.index = make(map[BLSPublicKey]int, len(c.Slots))
+	for i := range c.Slots {
+		if _, ok := c.index[c.Slots[i].BLSPublicKey]; !ok {
+			c.index[c.Slots[i].BLSPublicKey] = i
+		}
+	}
+}
+
 // DeepCopy returns a deep copy of the receiver.
 func (ss *State) DeepCopy() *State {
 	var r State
@@ -317,6 +347,15 @@ func (pk BLSPublicKey) MarshalText() (text []byte, err error) {
 	return text, nil
 }
 
+// UnmarshalText decodes the hex encoding of MarshalText
+func (pk *BLSPublicKey) UnmarshalText(text []byte) error {
+	if len(text) != 2*PublicKeySizeInBytes {
+		return errors.Errorf("invalid BLS public key length %d", len(text))
+	}
+	_, err := hex.Decode(pk[:], text)
+	return err
+}
+
 // FromLibBLSPublicKeyUnsafe could give back nil, use only in cases when
 // have invariant that return value won't be nil
 func FromLibBLSPublicKeyUnsafe(key *bls.PublicKey) *BLSPublicKey {
@@ -455,6 +494,9 @@ func (c *Committee) AddressForBLSKey(key BLSPublicKey) (*common.Address, error)
 	if c == nil {
 		return nil, ErrSubCommitteeNil
 	}
+	if i, ok := c.index[key]; ok && i < len(c.Slots) && c.Slots[i].BLSPublicKey == key {
+		return &c.Slots[i].EcdsaAddress, nil
+	}
 
 	for _, slot := range c.Slots {
 		if CompareBLSPublicKey(slot.BLSPublicKey, key) == 0 {
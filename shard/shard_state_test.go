@@ -73,7 +73,7 @@ func TestHash(t *testing.T) {
 			{common.Address{0x66}, blsPubKey6, nil},
 		},
 	}
-	shardState1 := State{nil, []Committee{com1, com2}}
+	shardState1 := State{Shards: []Committee{com1, com2}}
 	h1 := shardState1.Hash()
 
 	com3 := Committee{
@@ -93,7 +93,7 @@ func TestHash(t *testing.T) {
 		},
 	}
 
-	shardState2 := State{nil, []Committee{com3, com4}}
+	shardState2 := State{Shards: []Committee{com3, com4}}
 	h2 := shardState2.Hash()
 
 	if !bytes.Equal(h1[:], h2[:]) {
@@ -120,7 +120,7 @@ func TestCompatibilityOldShardStateIntoNew(t *testing.T) {
 		}},
 	}
 
-	postStakingState := State{nil, []Committee{
+	postStakingState := State{Shards: []Committee{
 		Committee{ShardID: 0, Slots: SlotList{
 			Slot{junkA, blsPubKey1, nil},
 			Slot{junkA, blsPubKey2, nil},
@@ -164,3 +164,39 @@ func TestCompatibilityOldShardStateIntoNew(t *testing.T) {
 	}
 
 }
+
+func TestStateIndex(t *testing.T) {
+	state := State{Shards: []Committee{
+		{ShardID: 1, Slots: SlotList{{common.Address{0x11}, blsPubKey1, nil}}},
+		{ShardID: 0, Slots: SlotList{
+			{common.Address{0x22}, blsPubKey2, nil}, {common.Address{0x33}, blsPubKey3, nil},
+		}},
+	}}
+	data, err := rlp.EncodeToBytes(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeWrapper(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	committee, err := decoded.FindCommitteeByID(0)
+	if err != nil || committee.ShardID != 0 {
+		t.Fatalf("got committee %v %v, expect shard 0", committee, err)
+	}
+	if addr, err := committee.AddressForBLSKey(blsPubKey3); err != nil || *addr != (common.Address{0x33}) {
+		t.Errorf("got address %v %v, expect 0x33", addr, err)
+	}
+	if _, err := committee.AddressForBLSKey(blsPubKey1); err != ErrValidNotInCommittee {
+		t.Errorf("got %v, expect the key of another committee not found", err)
+	}
+	if _, err := decoded.FindCommitteeByID(2); err != ErrShardIDNotInSuperCommittee {
+		t.Errorf("got %v, expect shard 2 not found", err)
+	}
+
+	// the index is checked against the modified committees
+	decoded.Shards[0], decoded.Shards[1] = decoded.Shards[1], decoded.Shards[0]
+	if committee, err := decoded.FindCommitteeByID(1); err != nil || committee.ShardID != 1 {
+		t.Errorf("got committee %v %v, expect shard 1", committee, err)
+	}
+}
@@ -14,7 +14,6 @@ import (
 )
 
 var (
-	measure = numeric.NewDec(2).Quo(numeric.NewDec(3))
 	// ErrDivByZero ..
 	ErrDivByZero = errors.New("toSign of availability cannot be 0, mistake in protocol")
 )
@@ -125,9 +124,10 @@ func IncrementValidatorSigningCounts(
 	)
 }
 
-// ComputeCurrentSigning returns (signed, toSign, quotient, error)
+// ComputeCurrentSigning returns (signed, toSign, quotient, error), the
+// quotient being below the signing threshold when at or below threshold
 func ComputeCurrentSigning(
-	snapshot, wrapper *staking.ValidatorWrapper,
+	snapshot, wrapper *staking.ValidatorWrapper, threshold numeric.Dec,
 ) *staking.Computed {
 	statsNow, snapSigned, snapToSign :=
 		wrapper.Counters,
@@ -141,6 +141,7 @@ func ComputeCurrentSigning(
 	computed := staking.NewComputed(
 		signed, toSign, 0, numeric.ZeroDec(), true,
 	)
+	computed.Threshold = threshold
 
 	if toSign.Cmp(common.Big0) == 0 {
 		return computed
@@ -158,24 +159,26 @@ func ComputeCurrentSigning(
 
 	s1, s2 := numeric.NewDecFromBigInt(signed), numeric.NewDecFromBigInt(toSign)
 	computed.Percentage = s1.Quo(s2)
-	computed.IsBelowThreshold = IsBelowSigningThreshold(computed.Percentage)
+	computed.IsBelowThreshold = IsBelowSigningThreshold(computed.Percentage, threshold)
 	return computed
 }
 
 // IsBelowSigningThreshold ..
-func IsBelowSigningThreshold(quotient numeric.Dec) bool {
-	return quotient.LTE(measure)
+func IsBelowSigningThreshold(quotient, threshold numeric.Dec) bool {
+	return quotient.LTE(threshold)
 }
 
 // ComputeAndMutateEPOSStatus sets the validator to
 // inactive and thereby keeping it out of
 // consideration in the pool of validators for
 // whenever committee selection happens in future, the
-// signing threshold is 66%
+// signing threshold being that of the chain config
+// at the epoch
 func ComputeAndMutateEPOSStatus(
 	bc Reader,
 	state ValidatorState,
 	addr common.Address,
+	threshold numeric.Dec,
 ) error {
 	utils.Logger().Info().Msg("begin compute for availability")
 
@@ -193,7 +196,7 @@ func ComputeAndMutateEPOSStatus(
 		return err
 	}
 
-	computed := ComputeCurrentSigning(snapshot.Validator, wrapper)
+	computed := ComputeCurrentSigning(snapshot.Validator, wrapper, threshold)
 
 	utils.Logger().
 		Info().Msg("check if signing percent is meeting required threshold")
@@ -204,7 +207,7 @@ func ComputeAndMutateEPOSStatus(
 	case missedTooManyBlocks:
 		wrapper.Status = effective.Inactive
 		utils.Logger().Info().
-			Str("threshold", measure.String()).
+			Str("threshold", threshold.String()).
 			Interface("computed", computed).
 			Msg("validator failed availability threshold, set to inactive")
 	default:
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/internal/params"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/effective"
@@ -187,7 +188,9 @@ func TestComputeCurrentSigning(t *testing.T) {
 		snapWrapper := makeTestWrapper(common.Address{}, test.snapSigned, test.snapToSign)
 		curWrapper := makeTestWrapper(common.Address{}, test.curSigned, test.curToSign)
 
-		computed := ComputeCurrentSigning(&snapWrapper, &curWrapper)
+		computed := ComputeCurrentSigning(
+			&snapWrapper, &curWrapper, params.DefaultAvailabilityThreshold,
+		)
 
 		if computed.Signed.Cmp(new(big.Int).SetInt64(test.diffSigned)) != 0 {
 			t.Errorf("test %v: computed signed not expected: %v / %v",
@@ -209,6 +212,27 @@ func TestComputeCurrentSigning(t *testing.T) {
 	}
 }
 
+func TestComputeCurrentSigningThreshold(t *testing.T) {
+	snapWrapper := makeTestWrapper(common.Address{}, 100, 200)
+	curWrapper := makeTestWrapper(common.Address{}, 190, 300)
+	// 90 of the 100 blocks to sign signed
+	for _, test := range []struct {
+		threshold        numeric.Dec
+		isBelowThreshold bool
+	}{
+		{params.DefaultAvailabilityThreshold, false},
+		{numeric.MustNewDecFromStr("0.9"), true},
+		{numeric.MustNewDecFromStr("0.95"), true},
+		{numeric.MustNewDecFromStr("0.5"), false},
+	} {
+		computed := ComputeCurrentSigning(&snapWrapper, &curWrapper, test.threshold)
+		if computed.IsBelowThreshold != test.isBelowThreshold || !computed.Threshold.Equal(test.threshold) {
+			t.Errorf("threshold %v: got below threshold %v, threshold %v",
+				test.threshold, computed.IsBelowThreshold, computed.Threshold)
+		}
+	}
+}
+
 func TestComputeAndMutateEPOSStatus(t *testing.T) {
 	tests := []struct {
 		ctx       *computeEPOSTestCtx
@@ -320,7 +344,9 @@ func TestComputeAndMutateEPOSStatus(t *testing.T) {
 		ctx := test.ctx
 		ctx.makeStateAndReader()
 
-		err := ComputeAndMutateEPOSStatus(ctx.reader, ctx.state, ctx.addr)
+		err := ComputeAndMutateEPOSStatus(
+			ctx.reader, ctx.state, ctx.addr, params.DefaultAvailabilityThreshold,
+		)
 		if err != nil {
 			if test.expErr == nil {
 				t.Errorf("Test %v: unexpected error: %v", i, err)
@@ -79,13 +79,12 @@ func Median(stakes []SlotPurchase) numeric.Dec {
 	}
 }
 
-// Compute ..
-func Compute(
-	shortHand map[common.Address]*SlotOrder, pull int,
-) (numeric.Dec, []SlotPurchase) {
+// Order returns all the slots at auction in auction order, by raw stake
+// and then by validator address and order of the keys of the validator.
+func Order(shortHand map[common.Address]*SlotOrder) []SlotPurchase {
 	eposedSlots := []SlotPurchase{}
 	if len(shortHand) == 0 {
-		return numeric.ZeroDec(), eposedSlots
+		return eposedSlots
 	}
 
 	type t struct {
@@ -132,6 +131,14 @@ func Compute(
 			return eposedSlots[i].RawStake.GT(eposedSlots[j].RawStake)
 		},
 	)
+	return eposedSlots
+}
+
+// Compute ..
+func Compute(
+	shortHand map[common.Address]*SlotOrder, pull int,
+) (numeric.Dec, []SlotPurchase) {
+	eposedSlots := Order(shortHand)
 
 	if l := len(eposedSlots); l < pull {
 		pull = l
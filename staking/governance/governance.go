@@ -0,0 +1,140 @@
+// Package governance applies the protocol parameter updates signed by the
+// governance keys of the chain config. An update is the RLP encoded data of a
+// transaction sent to Address on the beacon chain, the parameters in force are
+// kept in the state of Address and the nonce of Address is the nonce of the
+// last update applied.
+package governance
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/harmony/block"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/params"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+var (
+	// Address is the account receiving the parameter updates and keeping the
+	// parameters in force
+	Address = common.BytesToAddress([]byte("harmony-governance"))
+
+	externalSlotsKey = common.BytesToHash([]byte("external-slots"))
+)
+
+// ParamUpdate is a change of the protocol parameters, signed by the
+// governance keys
+type ParamUpdate struct {
+	Nonce         uint64   // one more than the nonce of the last update applied
+	ExternalSlots uint64   // external slots of each shard committee, from the next election on
+	Signatures    [][]byte // signatures of Hash by the governance keys
+}
+
+// Hash returns the hash the governance keys sign, binding the update to the
+// chain
+func (u ParamUpdate) Hash(chainID *big.Int) common.Hash {
+	data, _ := rlp.EncodeToBytes([]interface{}{chainID, Address, u.Nonce, u.ExternalSlots})
+	return crypto.Keccak256Hash(data)
+}
+
+// Sign adds the signature of the update by the key
+func (u *ParamUpdate) Sign(chainID *big.Int, key *ecdsa.PrivateKey) error {
+	hash := u.Hash(chainID)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return err
+	}
+	u.Signatures = append(u.Signatures, sig)
+	return nil
+}
+
+// Verify checks the update is signed by GovernanceThreshold distinct
+// governance keys and follows the last update applied
+func Verify(config *params.ChainConfig, db *state.DB, u ParamUpdate) error {
+	if len(config.GovernanceKeys) == 0 || config.GovernanceThreshold == 0 {
+		return errors.New("no governance keys in the chain config")
+	}
+	if last := db.GetNonce(Address); u.Nonce != last+1 {
+		return errors.Errorf("update nonce %d, expect %d", u.Nonce, last+1)
+	}
+	if u.ExternalSlots == 0 {
+		return errors.New("no external slot")
+	}
+	keys := map[common.Address]bool{}
+	for _, key := range config.GovernanceKeys {
+		keys[key] = false
+	}
+	hash := u.Hash(config.ChainID)
+	signers := uint32(0)
+	for _, sig := range u.Signatures {
+		pub, err := crypto.SigToPub(hash[:], sig)
+		if err != nil {
+			return errors.Wrap(err, "invalid signature")
+		}
+		signer := crypto.PubkeyToAddress(*pub)
+		signed, ok := keys[signer]
+		if !ok {
+			return errors.Errorf("signer %s is not a governance key", signer.Hex())
+		}
+		if !signed {
+			keys[signer] = true
+			signers++
+		}
+	}
+	if signers < config.GovernanceThreshold {
+		return errors.Errorf("signed by %d governance keys, expect %d", signers, config.GovernanceThreshold)
+	}
+	return nil
+}
+
+// ApplyUpdates applies the valid parameter updates of the successful
+// transactions of a beacon chain block, the invalid updates are ignored
+func ApplyUpdates(
+	config *params.ChainConfig, db *state.DB, header *block.Header,
+	txs types.Transactions, receipts types.Receipts,
+) {
+	if header.ShardID() != shard.BeaconChainShardID || !config.IsGovernance(header.Epoch()) {
+		return
+	}
+	for i, tx := range txs {
+		to := tx.To()
+		if to == nil || *to != Address || tx.ShardID() != tx.ToShardID() ||
+			i >= len(receipts) || receipts[i].Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		var u ParamUpdate
+		if err := rlp.DecodeBytes(tx.Data(), &u); err != nil {
+			utils.Logger().Info().Err(err).Str("tx", tx.Hash().Hex()).
+				Msg("[Governance] ignoring undecodable parameter update")
+			continue
+		}
+		if err := Verify(config, db, u); err != nil {
+			utils.Logger().Info().Err(err).Str("tx", tx.Hash().Hex()).
+				Msg("[Governance] ignoring invalid parameter update")
+			continue
+		}
+		db.SetNonce(Address, u.Nonce)
+		db.SetState(Address, externalSlotsKey, common.BigToHash(new(big.Int).SetUint64(u.ExternalSlots)))
+		utils.Logger().Info().
+			Uint64("nonce", u.Nonce).
+			Uint64("externalSlots", u.ExternalSlots).
+			Msg("[Governance] applied parameter update")
+	}
+}
+
+// ExternalSlots returns the external slots of each shard committee set by
+// the governance, false when no update set them
+func ExternalSlots(db *state.DB) (uint64, bool) {
+	slots := db.GetState(Address, externalSlotsKey).Big()
+	if slots.Sign() == 0 {
+		return 0, false
+	}
+	return slots.Uint64(), true
+}
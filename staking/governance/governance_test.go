@@ -0,0 +1,115 @@
+package governance
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	blockfactory "github.com/harmony-one/harmony/block/factory"
+	"github.com/harmony-one/harmony/core/state"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/internal/params"
+)
+
+func newTestConfig(t *testing.T, n int, threshold uint32) (*params.ChainConfig, []*ecdsa.PrivateKey) {
+	config := *params.TestChainConfig
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+		config.GovernanceKeys = append(config.GovernanceKeys, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	config.GovernanceThreshold = threshold
+	return &config, keys
+}
+
+func TestVerify(t *testing.T) {
+	config, keys := newTestConfig(t, 3, 2)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	outsider, _ := crypto.GenerateKey()
+
+	tests := []struct {
+		nonce   uint64
+		signers []*ecdsa.PrivateKey
+		valid   bool
+	}{
+		{1, []*ecdsa.PrivateKey{keys[0], keys[2]}, true},
+		{1, []*ecdsa.PrivateKey{keys[0]}, false},
+		{1, []*ecdsa.PrivateKey{keys[0], keys[0]}, false},
+		{1, []*ecdsa.PrivateKey{keys[0], outsider}, false},
+		{2, []*ecdsa.PrivateKey{keys[0], keys[1]}, false},
+	}
+	for i, test := range tests {
+		u := ParamUpdate{Nonce: test.nonce, ExternalSlots: 100}
+		for _, key := range test.signers {
+			if err := u.Sign(config.ChainID, key); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := Verify(config, db, u); (err == nil) != test.valid {
+			t.Errorf("index %d: got %v, expect valid %v", i, err, test.valid)
+		}
+	}
+
+	// the signatures are bound to the chain
+	u := ParamUpdate{Nonce: 1, ExternalSlots: 100}
+	u.Sign(big.NewInt(1), keys[0])
+	u.Sign(big.NewInt(1), keys[1])
+	if err := Verify(config, db, u); err == nil {
+		t.Error("expect the update signed for another chain refused")
+	}
+}
+
+func TestApplyUpdates(t *testing.T) {
+	config, keys := newTestConfig(t, 2, 2)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	header := blockfactory.ForTest.NewHeader(big.NewInt(1)).With().ShardID(0).Header()
+	if _, ok := ExternalSlots(db); ok {
+		t.Fatal("expect no external slots before any update")
+	}
+
+	newUpdateTx := func(nonce, slots uint64, signers ...*ecdsa.PrivateKey) *types.Transaction {
+		u := ParamUpdate{Nonce: nonce, ExternalSlots: slots}
+		for _, key := range signers {
+			u.Sign(config.ChainID, key)
+		}
+		data, err := rlp.EncodeToBytes(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.NewTransaction(0, Address, 0, big.NewInt(0), 100000, big.NewInt(1), data)
+	}
+	txs := types.Transactions{
+		newUpdateTx(1, 80, keys[0], keys[1]),
+		newUpdateTx(2, 90, keys[0]),          // under the threshold
+		newUpdateTx(2, 95, keys[0], keys[1]), // failed transaction
+		types.NewTransaction(0, Address, 0, big.NewInt(0), 100000, big.NewInt(1), []byte{1}),
+	}
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful},
+		{Status: types.ReceiptStatusSuccessful},
+		{Status: types.ReceiptStatusFailed},
+		{Status: types.ReceiptStatusSuccessful},
+	}
+	ApplyUpdates(config, db, header, txs, receipts)
+	if slots, ok := ExternalSlots(db); !ok || slots != 80 {
+		t.Errorf("got %d external slots, expect 80", slots)
+	}
+	if nonce := db.GetNonce(Address); nonce != 1 {
+		t.Errorf("got nonce %d, expect 1", nonce)
+	}
+
+	// the updates are ignored on the shard chains
+	shardHeader := blockfactory.ForTest.NewHeader(big.NewInt(1)).With().ShardID(1).Header()
+	ApplyUpdates(config, db, shardHeader, types.Transactions{newUpdateTx(2, 70, keys...)}, receipts[:1])
+	if slots, _ := ExternalSlots(db); slots != 80 {
+		t.Errorf("got %d external slots, expect the update of the shard chain ignored", slots)
+	}
+}
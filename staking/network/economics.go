@@ -0,0 +1,57 @@
+package network
+
+import (
+	"math/big"
+
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/staking/effective"
+)
+
+// Economics is a snapshot of the economics of the network at the current
+// block of the beacon chain, the amounts in atto
+type Economics struct {
+	Epoch *big.Int
+	// TotalStaked is the total delegation of the elected validators
+	TotalStaked numeric.Dec
+	// MedianEffectiveStake is the median of the effective stakes of the
+	// slots of the elected validators
+	MedianEffectiveStake numeric.Dec
+	CirculatingSupply    numeric.Dec
+	// BlockReward is the reward issued for each block height across the
+	// shards
+	BlockReward       numeric.Dec
+	ElectedValidators int
+}
+
+// NewEconomicsSnapshot computes the economics of the network at the current
+// block of the beacon chain, whose reward issued for each block height is
+// blockReward.
+func NewEconomicsSnapshot(
+	beaconchain SupplyReader, blockReward numeric.Dec,
+) (*Economics, error) {
+	header := beaconchain.CurrentHeader()
+	active, err := beaconchain.ReadShardState(header.Epoch())
+	if err != nil {
+		return nil, err
+	}
+	supply, err := NewSupply(beaconchain, GetSupplySchedule())
+	if err != nil {
+		return nil, err
+	}
+	stakes := []effective.SlotPurchase{}
+	for _, committee := range active.Shards {
+		for _, slot := range committee.Slots {
+			if slot.EffectiveStake != nil {
+				stakes = append(stakes, effective.SlotPurchase{RawStake: *slot.EffectiveStake})
+			}
+		}
+	}
+	return &Economics{
+		Epoch:                header.Epoch(),
+		TotalStaked:          supply.Staked,
+		MedianEffectiveStake: effective.Median(stakes),
+		CirculatingSupply:    supply.Circulating,
+		BlockReward:          blockReward,
+		ElectedValidators:    active.StakedValidators().CountStakedValidator,
+	}, nil
+}
@@ -35,6 +35,25 @@ var (
 	EmptyPayout = noReward{}
 )
 
+// baseStakedShards is the number of shards BaseStakedReward is the block
+// reward of each shard for
+const baseStakedShards = 4
+
+// StakedRewardForShards returns the block reward of each shard of a network
+// of numShards shards issuing networkReward for each block height, or the
+// same rewards as BaseStakedReward for each of baseStakedShards shards when
+// networkReward is nil
+func StakedRewardForShards(networkReward *big.Int, numShards uint32) numeric.Dec {
+	total := BaseStakedReward.MulInt64(baseStakedShards)
+	if networkReward != nil {
+		total = numeric.NewDecFromBigInt(networkReward)
+	}
+	if numShards == 0 {
+		return total
+	}
+	return total.QuoInt64(int64(numShards))
+}
+
 type ignoreMissing struct{}
 
 func (ignoreMissing) MissingSigners() shard.SlotList {
@@ -46,11 +65,17 @@ type noReward struct{ ignoreMissing }
 func (noReward) ReadRoundResult() *reward.CompletedRound {
 	return &reward.CompletedRound{
 		Total:            big.NewInt(0),
+		Remainder:        big.NewInt(0),
 		BeaconchainAward: []reward.Payout{},
 		ShardChainAward:  []reward.Payout{},
 	}
 }
 
+// ReadBreakdown ..
+func (n noReward) ReadBreakdown() *reward.Breakdown {
+	return reward.NewBreakdown(n.ReadRoundResult())
+}
+
 type preStakingEra struct {
 	ignoreMissing
 	payout *big.Int
@@ -64,11 +89,17 @@ func NewPreStakingEraRewarded(totalAmount *big.Int) reward.Reader {
 func (p *preStakingEra) ReadRoundResult() *reward.CompletedRound {
 	return &reward.CompletedRound{
 		Total:            p.payout,
+		Remainder:        big.NewInt(0),
 		BeaconchainAward: []reward.Payout{},
 		ShardChainAward:  []reward.Payout{},
 	}
 }
 
+// ReadBreakdown ..
+func (p *preStakingEra) ReadBreakdown() *reward.Breakdown {
+	return reward.NewBreakdown(p.ReadRoundResult())
+}
+
 type stakingEra struct {
 	reward.CompletedRound
 	missingSigners shard.SlotList
@@ -76,13 +107,14 @@ type stakingEra struct {
 
 // NewStakingEraRewardForRound ..
 func NewStakingEraRewardForRound(
-	totalPayout *big.Int,
+	totalPayout, remainder *big.Int,
 	mia shard.SlotList,
 	beaconP, shardP []reward.Payout,
 ) reward.Reader {
 	return &stakingEra{
 		CompletedRound: reward.CompletedRound{
 			Total:            totalPayout,
+			Remainder:        remainder,
 			BeaconchainAward: beaconP,
 			ShardChainAward:  shardP,
 		},
@@ -100,6 +132,11 @@ func (r *stakingEra) ReadRoundResult() *reward.CompletedRound {
 	return &r.CompletedRound
 }
 
+// ReadBreakdown ..
+func (r *stakingEra) ReadBreakdown() *reward.Breakdown {
+	return reward.NewBreakdown(&r.CompletedRound)
+}
+
 func adjust(amount numeric.Dec) numeric.Dec {
 	return amount.MulTruncate(
 		numeric.NewDecFromBigInt(big.NewInt(denominations.One)),
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+)
+
+// delegatorShareCacheSize is the number of validator snapshots the shares of
+// the delegations are cached for, those of the elected validators of a few
+// epochs
+const delegatorShareCacheSize = 4096
+
+// DelegatorShares is the cache of the shares of the delegators shared by the
+// reward payouts of the beacon and shard blocks, invalidated by the chain
+// writing the snapshots
+var DelegatorShares = NewDelegatorShareCache(delegatorShareCacheSize)
+
+// DelegatorShareCache caches the shares of the stake of the delegators of the
+// validator snapshots, by epoch and validator. The snapshot writers
+// invalidate the shares of the snapshots they rewrite or delete.
+type DelegatorShareCache struct {
+	lock    sync.Mutex
+	version uint64
+	cache   *lru.Cache
+	group   singleflight.Group
+}
+
+// NewDelegatorShareCache returns a cache of the shares of size snapshots
+func NewDelegatorShareCache(size int) *DelegatorShareCache {
+	cache, _ := lru.New(size)
+	return &DelegatorShareCache{cache: cache}
+}
+
+// delegatorShareKey returns the key of the shares of the validator at epoch
+func delegatorShareKey(epoch *big.Int, addr common.Address) string {
+	return fmt.Sprintf("%s-%s", epoch.String(), addr.Hex())
+}
+
+// Shares returns the share of the total delegation of each delegator of the
+// validator snapshot, by the delegator address. The map is shared by all the
+// callers and must not be changed.
+func (c *DelegatorShareCache) Shares(
+	snapshot *staking.ValidatorSnapshot,
+) (map[common.Address]numeric.Dec, error) {
+	key := delegatorShareKey(snapshot.Epoch, snapshot.Validator.Address)
+	if shares, ok := c.cache.Get(key); ok {
+		return shares.(map[common.Address]numeric.Dec), nil
+	}
+	c.lock.Lock()
+	version := c.version
+	c.lock.Unlock()
+	shares, err, _ := c.group.Do(key, func() (interface{}, error) {
+		shares := computeDelegatorShares(snapshot.Validator)
+		// not cached if the snapshot was invalidated while computing
+		c.lock.Lock()
+		if c.version == version {
+			c.cache.Add(key, shares)
+		}
+		c.lock.Unlock()
+		return shares, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shares.(map[common.Address]numeric.Dec), nil
+}
+
+// Invalidate drops the shares of the snapshot of the validator at epoch
+func (c *DelegatorShareCache) Invalidate(epoch *big.Int, addr common.Address) {
+	key := delegatorShareKey(epoch, addr)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.version++
+	c.group.Forget(key)
+	c.cache.Remove(key)
+}
+
+// Purge drops the shares of all the snapshots
+func (c *DelegatorShareCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.version++
+	c.cache.Purge()
+}
+
+// computeDelegatorShares returns the share of the total delegation of each
+// delegator of the validator, none if nothing is delegated
+func computeDelegatorShares(
+	validator *staking.ValidatorWrapper,
+) map[common.Address]numeric.Dec {
+	result := map[common.Address]numeric.Dec{}
+	totalDelegationDec := numeric.NewDecFromBigInt(validator.TotalDelegation())
+	if totalDelegationDec.IsZero() {
+		utils.Logger().Info().
+			RawJSON("validator-snapshot", []byte(validator.String())).
+			Msg("zero total delegation during AddReward delegation payout")
+		return result
+	}
+	for i := range validator.Delegations {
+		delegation := validator.Delegations[i]
+		// NOTE percentage = <this_delegator_amount>/<total_delegation>
+		percentage := numeric.NewDecFromBigInt(delegation.Amount).Quo(totalDelegationDec)
+		result[delegation.DelegatorAddress] = percentage
+	}
+	return result
+}
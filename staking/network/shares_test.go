@@ -0,0 +1,58 @@
+package network
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+func newShareTestSnapshot(epoch int64, amounts ...int64) *staking.ValidatorSnapshot {
+	wrapper := &staking.ValidatorWrapper{}
+	wrapper.Address = common.Address{0x0a}
+	for i, amount := range amounts {
+		wrapper.Delegations = append(wrapper.Delegations, staking.Delegation{
+			DelegatorAddress: common.BytesToAddress([]byte{byte(i + 1)}),
+			Amount:           big.NewInt(amount),
+		})
+	}
+	return &staking.ValidatorSnapshot{Validator: wrapper, Epoch: big.NewInt(epoch)}
+}
+
+func TestDelegatorShareCache(t *testing.T) {
+	cache := NewDelegatorShareCache(16)
+	first := common.BytesToAddress([]byte{1})
+
+	shares, err := cache.Shares(newShareTestSnapshot(5, 1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shares[first].Equal(numeric.NewDecWithPrec(25, 2)) {
+		t.Errorf("got share %v, expected 0.25", shares[first])
+	}
+
+	// the shares of the epoch are cached until the snapshot is rewritten
+	rewritten := newShareTestSnapshot(5, 1, 1)
+	if shares, _ := cache.Shares(rewritten); !shares[first].Equal(numeric.NewDecWithPrec(25, 2)) {
+		t.Errorf("got share %v, expected the cached 0.25", shares[first])
+	}
+	cache.Invalidate(big.NewInt(5), rewritten.Validator.Address)
+	if shares, _ := cache.Shares(rewritten); !shares[first].Equal(numeric.NewDecWithPrec(5, 1)) {
+		t.Errorf("got share %v, expected 0.5", shares[first])
+	}
+
+	// the other epochs are separate
+	if shares, _ := cache.Shares(newShareTestSnapshot(6, 3, 1)); !shares[first].Equal(numeric.NewDecWithPrec(75, 2)) {
+		t.Errorf("got share %v, expected 0.75", shares[first])
+	}
+	cache.Purge()
+	if shares, _ := cache.Shares(newShareTestSnapshot(6, 1, 0)); !shares[first].Equal(numeric.OneDec()) {
+		t.Errorf("got share %v, expected 1", shares[first])
+	}
+
+	if shares, _ := cache.Shares(newShareTestSnapshot(7, 0, 0)); len(shares) != 0 {
+		t.Errorf("got shares %v without delegation", shares)
+	}
+}
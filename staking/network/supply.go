@@ -0,0 +1,226 @@
+package network
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/harmony-one/harmony/common/denominations"
+	"github.com/harmony-one/harmony/consensus/engine"
+	"github.com/harmony-one/harmony/consensus/reward"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// SupplySchedule is the schedule of the release of the genesis supply,
+// shares of it released at dates, and of the lockups of the released tokens
+// vesting over time, the amounts in ONE
+type SupplySchedule struct {
+	GenesisSupply numeric.Dec     `json:"genesis-supply"`
+	Releases      []SupplyRelease `json:"releases"`
+	Lockups       []SupplyLockup  `json:"lockups,omitempty"`
+}
+
+// SupplyRelease is the share of the genesis supply released from Time on
+type SupplyRelease struct {
+	Time  time.Time   `json:"time"`
+	Share numeric.Dec `json:"share"`
+}
+
+// SupplyLockup is an amount of the released tokens which vests linearly from
+// Start to End, no part of it vesting before Cliff if set
+type SupplyLockup struct {
+	Name   string      `json:"name"`
+	Amount numeric.Dec `json:"amount"`
+	Start  time.Time   `json:"start"`
+	End    time.Time   `json:"end"`
+	Cliff  *time.Time  `json:"cliff,omitempty"`
+}
+
+// DefaultSupplySchedule returns the token release schedule of Harmony, the
+// genesis supply of 12.6 billion ONE released following reward.ReleasePlan
+func DefaultSupplySchedule() *SupplySchedule {
+	plan := reward.ReleasePlan()
+	s := &SupplySchedule{
+		GenesisSupply: numeric.NewDec(12600000000),
+		Releases:      make([]SupplyRelease, len(plan)),
+	}
+	for i, release := range plan {
+		s.Releases[i] = SupplyRelease{Time: time.Unix(release.Time, 0).UTC(), Share: release.Share}
+	}
+	return s
+}
+
+// LoadSupplySchedule loads the supply schedule of the JSON file
+func LoadSupplySchedule(path string) (*SupplySchedule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &SupplySchedule{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "invalid supply schedule %s", path)
+	}
+	if err := s.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid supply schedule %s", path)
+	}
+	sort.SliceStable(s.Releases, func(i, j int) bool {
+		return s.Releases[i].Time.Before(s.Releases[j].Time)
+	})
+	return s, nil
+}
+
+func (s *SupplySchedule) validate() error {
+	if s.GenesisSupply.Int == nil || s.GenesisSupply.IsNegative() {
+		return errors.New("no genesis supply")
+	}
+	for _, release := range s.Releases {
+		if release.Share.Int == nil || release.Share.IsNegative() || release.Share.GT(numeric.OneDec()) {
+			return errors.Errorf("release share of %s not between 0 and 1", release.Time)
+		}
+	}
+	for _, lockup := range s.Lockups {
+		if lockup.Amount.Int == nil || lockup.Amount.IsNegative() {
+			return errors.Errorf("no amount of lockup %s", lockup.Name)
+		}
+		if lockup.End.Before(lockup.Start) {
+			return errors.Errorf("lockup %s ends before its start", lockup.Name)
+		}
+	}
+	return nil
+}
+
+// Released returns the share of the genesis supply released at the time
+func (s *SupplySchedule) Released(t time.Time) numeric.Dec {
+	share := numeric.ZeroDec()
+	for _, release := range s.Releases {
+		if t.Before(release.Time) {
+			break
+		}
+		share = release.Share
+	}
+	return share
+}
+
+// Locked returns the amount of the lockups not vested yet at the time, in ONE
+func (s *SupplySchedule) Locked(t time.Time) numeric.Dec {
+	locked := numeric.ZeroDec()
+	for _, lockup := range s.Lockups {
+		locked = locked.Add(lockup.locked(t))
+	}
+	return locked
+}
+
+func (l *SupplyLockup) locked(t time.Time) numeric.Dec {
+	switch {
+	case !t.After(l.Start) || l.Cliff != nil && t.Before(*l.Cliff):
+		return l.Amount
+	case !t.Before(l.End):
+		return numeric.ZeroDec()
+	}
+	left := numeric.NewDec(int64(l.End.Sub(t)))
+	return l.Amount.Mul(left).QuoInt64(int64(l.End.Sub(l.Start)))
+}
+
+var (
+	supplyScheduleLock sync.RWMutex
+	supplySchedule     = DefaultSupplySchedule()
+)
+
+// SetSupplySchedule sets the supply schedule the supply is computed with
+func SetSupplySchedule(s *SupplySchedule) {
+	supplyScheduleLock.Lock()
+	supplySchedule = s
+	supplyScheduleLock.Unlock()
+}
+
+// GetSupplySchedule returns the supply schedule the supply is computed with,
+// DefaultSupplySchedule unless set
+func GetSupplySchedule() *SupplySchedule {
+	supplyScheduleLock.RLock()
+	defer supplyScheduleLock.RUnlock()
+	return supplySchedule
+}
+
+// SupplyReader is the beacon chain the supply is computed from
+type SupplyReader interface {
+	engine.ChainReader
+	// ReadBurnedFees returns the transaction fees burned up to the block
+	ReadBurnedFees(number uint64) (*big.Int, error)
+}
+
+// Supply is the supply of ONE at a block of the beacon chain, in atto. The
+// total supply is the genesis supply with the block rewards issued since the
+// staking launch, less the transaction fees burned by the beacon chain. The
+// circulating supply is the part of it released by the schedule and not
+// locked by its lockups.
+type Supply struct {
+	Epoch       *big.Int
+	Total       numeric.Dec
+	Circulating numeric.Dec
+	// Staked is the total delegation of the elected validators
+	Staked numeric.Dec
+	Issued numeric.Dec
+	Burned numeric.Dec
+	Locked numeric.Dec
+}
+
+// NewSupply computes the supply of ONE at the current block of the beacon
+// chain with the schedule
+func NewSupply(beaconchain SupplyReader, schedule *SupplySchedule) (*Supply, error) {
+	header := beaconchain.CurrentHeader()
+	issued, err := beaconchain.ReadBlockRewardAccumulator(header.Number().Uint64())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read the block rewards issued")
+	}
+	burned, err := beaconchain.ReadBurnedFees(header.Number().Uint64())
+	if err != nil {
+		// the node started tracking the burned fees after the block
+		burned = big.NewInt(0)
+	}
+	active, err := beaconchain.ReadShardState(header.Epoch())
+	if err != nil {
+		return nil, err
+	}
+	staked, err := totalStaked(beaconchain, active)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Unix(header.Time().Int64(), 0)
+	one := numeric.NewDecFromBigInt(big.NewInt(denominations.One))
+	genesis := schedule.GenesisSupply.Mul(one)
+	locked := schedule.Locked(now).Mul(one)
+	onChain := numeric.NewDecFromBigInt(new(big.Int).Sub(issued, burned))
+	circulating := genesis.Mul(schedule.Released(now)).Sub(locked).Add(onChain)
+	if circulating.IsNegative() {
+		circulating = numeric.ZeroDec()
+	}
+	return &Supply{
+		Epoch:       header.Epoch(),
+		Total:       genesis.Add(onChain),
+		Circulating: circulating,
+		Staked:      staked,
+		Issued:      numeric.NewDecFromBigInt(issued),
+		Burned:      numeric.NewDecFromBigInt(burned),
+		Locked:      locked,
+	}, nil
+}
+
+// totalStaked returns the total delegation of the elected validators of the
+// shard state
+func totalStaked(beaconchain engine.ChainReader, active *shard.State) (numeric.Dec, error) {
+	staked := numeric.ZeroDec()
+	for _, addr := range active.StakedValidators().Addrs {
+		wrapper, err := beaconchain.ReadValidatorInformation(addr)
+		if err != nil {
+			return numeric.ZeroDec(), err
+		}
+		staked = staked.Add(numeric.NewDecFromBigInt(wrapper.TotalDelegation()))
+	}
+	return staked, nil
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/harmony/numeric"
+)
+
+func TestSupplyScheduleReleased(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &SupplySchedule{
+		GenesisSupply: numeric.NewDec(1000),
+		Releases: []SupplyRelease{
+			{Time: start, Share: numeric.NewDecWithPrec(25, 2)},
+			{Time: start.AddDate(0, 6, 0), Share: numeric.NewDecWithPrec(75, 2)},
+		},
+	}
+	tests := []struct {
+		at   time.Time
+		want numeric.Dec
+	}{
+		{start.Add(-time.Second), numeric.ZeroDec()},
+		{start, numeric.NewDecWithPrec(25, 2)},
+		{start.AddDate(0, 3, 0), numeric.NewDecWithPrec(25, 2)},
+		{start.AddDate(1, 0, 0), numeric.NewDecWithPrec(75, 2)},
+	}
+	for i, test := range tests {
+		if got := s.Released(test.at); !got.Equal(test.want) {
+			t.Errorf("test %d: released %s, expected %s", i, got, test.want)
+		}
+	}
+}
+
+func TestSupplyScheduleLocked(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cliff := start.AddDate(0, 0, 25)
+	s := &SupplySchedule{
+		Lockups: []SupplyLockup{
+			{Name: "team", Amount: numeric.NewDec(100), Start: start, End: start.AddDate(0, 0, 100), Cliff: &cliff},
+			{Name: "foundation", Amount: numeric.NewDec(40), Start: start, End: start.AddDate(0, 0, 10)},
+		},
+	}
+	tests := []struct {
+		at   time.Time
+		want numeric.Dec
+	}{
+		{start, numeric.NewDec(140)},
+		{start.AddDate(0, 0, 5), numeric.NewDec(120)},
+		{start.AddDate(0, 0, 20), numeric.NewDec(100)},
+		{cliff, numeric.NewDec(75)},
+		{start.AddDate(0, 0, 100), numeric.ZeroDec()},
+	}
+	for i, test := range tests {
+		if got := s.Locked(test.at); !got.Equal(test.want) {
+			t.Errorf("test %d: locked %s, expected %s", i, got, test.want)
+		}
+	}
+}
+
+func TestLoadSupplySchedule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		schedule string
+		valid    bool
+	}{
+		{`{"genesis-supply": "1000", "releases": [
+			{"time": "2021-01-01T00:00:00Z", "share": "1"},
+			{"time": "2020-01-01T00:00:00Z", "share": "0.5"}]}`, true},
+		{`{"releases": []}`, false},
+		{`{"genesis-supply": "1000", "releases": [{"time": "2020-01-01T00:00:00Z", "share": "1.5"}]}`, false},
+		{`{"genesis-supply": "1000", "lockups": [{"name": "team",
+			"start": "2020-01-01T00:00:00Z", "end": "2021-01-01T00:00:00Z"}]}`, false},
+		{`{"genesis-supply": "1000", "lockups": [{"name": "team", "amount": "10",
+			"start": "2021-01-01T00:00:00Z", "end": "2020-01-01T00:00:00Z"}]}`, false},
+	}
+	for i, test := range tests {
+		file := path.Join(dir, "schedule.json")
+		if err := ioutil.WriteFile(file, []byte(test.schedule), 0600); err != nil {
+			t.Fatal(err)
+		}
+		s, err := LoadSupplySchedule(file)
+		if (err == nil) != test.valid {
+			t.Errorf("test %d: got error %v", i, err)
+			continue
+		}
+		if err == nil && !s.Releases[0].Time.Before(s.Releases[1].Time) {
+			t.Errorf("test %d: releases not sorted", i)
+		}
+	}
+}
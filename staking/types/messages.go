@@ -5,6 +5,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/effective"
@@ -25,6 +26,8 @@ const (
 	DirectiveUndelegate
 	// DirectiveCollectRewards ...
 	DirectiveCollectRewards
+	// DirectiveBindSlotKey ...
+	DirectiveBindSlotKey
 )
 
 var (
@@ -34,6 +37,7 @@ var (
 		DirectiveDelegate:        "Delegate",
 		DirectiveUndelegate:      "Undelegate",
 		DirectiveCollectRewards:  "CollectRewards",
+		DirectiveBindSlotKey:     "BindSlotKey",
 	}
 	// ErrInvalidStakingKind given when caller gives bad staking message kind
 	ErrInvalidStakingKind = errors.New("bad staking kind")
@@ -46,6 +50,16 @@ func (d Directive) String() string {
 	return fmt.Sprintf("Directive %+v", byte(d))
 }
 
+// ParseDirective returns the directive of the name, as returned by String
+func ParseDirective(name string) (Directive, error) {
+	for d, n := range directiveNames {
+		if n == name {
+			return d, nil
+		}
+	}
+	return 0, errors.Wrapf(ErrInvalidStakingKind, "%q", name)
+}
+
 // StakeMsg defines the interface of Stake Message
 type StakeMsg interface {
 	Type() Directive
@@ -108,6 +122,9 @@ type EditValidator struct {
 	SlotKeyToAdd       *shard.BLSPublicKey   `json:"slot-key-to_add" rlp:"nil"`
 	SlotKeyToAddSig    *shard.BLSSignature   `json:"slot-key-to-add-sig" rlp:"nil"`
 	EPOSStatus         effective.Eligibility `json:"epos-eligibility-status" rlp:"nil"`
+	// ShardPreference holds the new shard preference of the validator, if
+	// any, so that the edits without it keep their encoding
+	ShardPreference []ShardPreference `json:"shard-preference,omitempty" rlp:"tail"`
 }
 
 // Type of EditValidator
@@ -144,6 +161,9 @@ func (v EditValidator) Copy() StakeMsg {
 		sigAdd := *v.SlotKeyToAddSig
 		cp.SlotKeyToAddSig = &sigAdd
 	}
+	if v.ShardPreference != nil {
+		cp.ShardPreference = append([]ShardPreference{}, v.ShardPreference...)
+	}
 	return cp
 }
 
@@ -211,3 +231,24 @@ func (v CollectRewards) Copy() StakeMsg {
 		DelegatorAddress: v.DelegatorAddress,
 	}
 }
+
+// BindSlotKey - type for binding the key of another BLS curve to a slot key
+// of the validator, both keys signing the binding
+type BindSlotKey struct {
+	ValidatorAddress common.Address     `json:"validator-address"`
+	SlotPubKey       shard.BLSPublicKey `json:"slot-pub-key"`
+	SlotKeySig       shard.BLSSignature `json:"slot-key-sig"`
+	Curve            bls.CurveID        `json:"curve"`
+	BoundPubKey      shard.BLSPublicKey `json:"bound-pub-key"`
+	BoundKeySig      shard.BLSSignature `json:"bound-key-sig"`
+}
+
+// Type of BindSlotKey
+func (v BindSlotKey) Type() Directive {
+	return DirectiveBindSlotKey
+}
+
+// Copy returns a deep copy of the BindSlotKey as a StakeMsg interface
+func (v BindSlotKey) Copy() StakeMsg {
+	return v
+}
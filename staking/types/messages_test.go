@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/shard"
 	"github.com/harmony-one/harmony/staking/effective"
 )
@@ -34,6 +35,7 @@ func TestDirective_String(t *testing.T) {
 		{DirectiveDelegate, "Delegate"},
 		{DirectiveUndelegate, "Undelegate"},
 		{DirectiveCollectRewards, "CollectRewards"},
+		{DirectiveBindSlotKey, "BindSlotKey"},
 		{0xff, "Directive 255"},
 	}
 	for i, test := range tests {
@@ -99,6 +101,37 @@ func TestEditValidator_Copy(t *testing.T) {
 	}
 }
 
+func TestEditValidator_ShardPreference(t *testing.T) {
+	tests := []struct {
+		pref   []ShardPreference
+		expect ShardPreference
+		set    bool
+		err    error
+	}{
+		{nil, NoShardPreference, false, nil},
+		{[]ShardPreference{StayOnShard}, StayOnShard, true, nil},
+		{[]ShardPreference{NoShardPreference}, NoShardPreference, true, nil},
+		{[]ShardPreference{2}, NoShardPreference, false, errInvalidShardPreference},
+		{[]ShardPreference{StayOnShard, StayOnShard}, NoShardPreference, false, errInvalidShardPreference},
+	}
+	for i, test := range tests {
+		ev := EditValidator{ShardPreference: test.pref}
+		data, err := rlp.EncodeToBytes(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := EditValidator{}
+		if err := rlp.DecodeBytes(data, &decoded); err != nil {
+			t.Fatalf("Test %v: %v", i, err)
+		}
+		pref, set, err := decoded.ShardPreferenceUpdate()
+		if pref != test.expect || set != test.set || err != test.err {
+			t.Errorf("Test %v: got %v %v %v, expect %v %v %v",
+				i, pref, set, err, test.expect, test.set, test.err)
+		}
+	}
+}
+
 func TestDelegate_Copy(t *testing.T) {
 	tests := []struct {
 		d Delegate
@@ -237,6 +270,9 @@ func assertEditValidatorDeepCopy(ev1, ev2 EditValidator) error {
 	if ev1.SlotKeyToAddSig != nil && ev1.SlotKeyToAddSig == ev2.SlotKeyToAddSig {
 		return fmt.Errorf("SlotKeyToAddSig same pointer")
 	}
+	if len(ev1.ShardPreference) > 0 && &ev1.ShardPreference[0] == &ev2.ShardPreference[0] {
+		return fmt.Errorf("ShardPreference same pointer")
+	}
 	return nil
 }
 
@@ -334,6 +370,7 @@ func cpTestDataSetup() {
 		SlotKeyToAdd:       &blsPubSigPairs[0].pub,
 		SlotKeyToAddSig:    &blsPubSigPairs[0].sig,
 		EPOSStatus:         effective.Active,
+		ShardPreference:    []ShardPreference{StayOnShard},
 	}
 	zeroEditValidator = EditValidator{
 		CommissionRate:     &zeroDec,
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ShardPreference is the preference of a validator about the shard its
+// slots are assigned to at the elections
+type ShardPreference uint32
+
+const (
+	// NoShardPreference assigns the slots of the validator to the shard of
+	// their key, the default
+	NoShardPreference ShardPreference = iota
+	// StayOnShard keeps the slots of the validator on their shard of the
+	// previous epoch when the external slots of the shard allow it
+	StayOnShard
+)
+
+// ShardPreferenceKey is the storage key of the shard preference in the
+// account of the validator
+var ShardPreferenceKey = crypto.Keccak256Hash([]byte("shard-preference"))
+
+var errInvalidShardPreference = errors.New("invalid shard preference")
+
+// ShardPreferenceUpdate returns the shard preference set by the edit, false
+// when the edit does not change it
+func (v EditValidator) ShardPreferenceUpdate() (ShardPreference, bool, error) {
+	switch len(v.ShardPreference) {
+	case 0:
+		return NoShardPreference, false, nil
+	case 1:
+	default:
+		return NoShardPreference, false, errInvalidShardPreference
+	}
+	switch pref := v.ShardPreference[0]; pref {
+	case NoShardPreference, StayOnShard:
+		return pref, true, nil
+	default:
+		return NoShardPreference, false, errInvalidShardPreference
+	}
+}
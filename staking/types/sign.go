@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/harmony/crypto/hash"
 )
 
@@ -148,14 +149,25 @@ func (s EIP155Signer) SignatureValues(
 // Hash returns the hash to be signed by the sender.
 // It does not uniquely identify the transaction.
 func (s EIP155Signer) Hash(tx *StakingTransaction) common.Hash {
-	return hash.FromRLP([]interface{}{
+	return hash.FromRLP(s.signedFields(tx))
+}
+
+// Payload returns the RLP encoding of the fields whose hash is signed by the
+// sender, which is what the signers hashing it themselves, such as the
+// hardware wallets, are given.
+func (s EIP155Signer) Payload(tx *StakingTransaction) ([]byte, error) {
+	return rlp.EncodeToBytes(s.signedFields(tx))
+}
+
+func (s EIP155Signer) signedFields(tx *StakingTransaction) []interface{} {
+	return []interface{}{
 		tx.data.Directive,
 		tx.data.StakeMsg,
 		tx.data.AccountNonce,
 		tx.data.Price,
 		tx.data.GasLimit,
 		s.chainID, uint(0), uint(0),
-	})
+	}
 }
 
 func recoverPlain(
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+// bindSlotKeyPrefix prefixes the message of the key binding signatures
+const bindSlotKeyPrefix = "harmony-one-bind-slot-key"
+
+var (
+	// BoundKeyIndexAddress is the account indexing the validator each key of
+	// another curve is bound for
+	BoundKeyIndexAddress = common.BytesToAddress([]byte("harmony-bound-keys"))
+
+	// boundSlotKeyPrefix prefixes the storage keys of the bound keys in the
+	// account of the validator
+	boundSlotKeyPrefix = []byte("bound-slot-key")
+	// boundKeyOwnerPrefix prefixes the storage keys of the validators in the
+	// account BoundKeyIndexAddress
+	boundKeyOwnerPrefix = []byte("bound-key-owner")
+
+	errBindSameCurve = errors.New("slot keys are already on the mcl curve")
+)
+
+// Hash returns the message signed by the slot key and the bound key, binding
+// them for the validator.
+func (v BindSlotKey) Hash() common.Hash {
+	return crypto.Keccak256Hash(
+		[]byte(bindSlotKeyPrefix),
+		v.ValidatorAddress.Bytes(),
+		v.SlotPubKey[:],
+		[]byte{byte(v.Curve)},
+		v.BoundPubKey[:],
+	)
+}
+
+// VerifyBinding checks the signatures of the binding by both keys, the
+// signature by the bound key also proving its possession.
+func (v BindSlotKey) VerifyBinding() error {
+	if v.Curve == bls.CurveMCL {
+		return errBindSameCurve
+	}
+	curve, err := bls.CurveByID(v.Curve)
+	if err != nil {
+		return err
+	}
+	mcl, _ := bls.CurveByID(bls.CurveMCL)
+	msg := v.Hash()
+	if err := mcl.Verify(v.SlotPubKey[:], v.SlotKeySig[:], msg[:]); err != nil {
+		return errors.Wrapf(err, "slot key %x", v.SlotPubKey)
+	}
+	if err := curve.Verify(v.BoundPubKey[:], v.BoundKeySig[:], msg[:]); err != nil {
+		return errors.Wrapf(err, "%s key %x", curve, v.BoundPubKey)
+	}
+	return nil
+}
+
+// BoundSlotKeyStorage returns the storage keys of the two words of the key
+// bound on the curve to the slot key, in the account of the validator.
+func BoundSlotKeyStorage(curve bls.CurveID, slotKey shard.BLSPublicKey) [2]common.Hash {
+	key := crypto.Keccak256Hash(boundSlotKeyPrefix, []byte{byte(curve)}, slotKey[:])
+	next := new(big.Int).Add(key.Big(), common.Big1)
+	return [2]common.Hash{key, common.BigToHash(next)}
+}
+
+// BoundKeyOwnerStorage returns the storage key of the validator the key on
+// the curve is bound for, in the account BoundKeyIndexAddress.
+func BoundKeyOwnerStorage(curve bls.CurveID, boundKey shard.BLSPublicKey) common.Hash {
+	return crypto.Keccak256Hash(boundKeyOwnerPrefix, []byte{byte(curve)}, boundKey[:])
+}
+
+// EncodeBoundSlotKey returns the two storage words of the bound key
+func EncodeBoundSlotKey(key shard.BLSPublicKey) [2]common.Hash {
+	words := [2]common.Hash{}
+	copy(words[0][:], key[:common.HashLength])
+	copy(words[1][:], key[common.HashLength:])
+	return words
+}
+
+// DecodeBoundSlotKey returns the bound key of its storage words, false if
+// no key is bound.
+func DecodeBoundSlotKey(words [2]common.Hash) (shard.BLSPublicKey, bool) {
+	key := shard.BLSPublicKey{}
+	copy(key[:common.HashLength], words[0][:])
+	copy(key[common.HashLength:], words[1][:])
+	return key, key != shard.BLSPublicKey{}
+}
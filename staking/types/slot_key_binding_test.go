@@ -0,0 +1,72 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/shard"
+	"github.com/pkg/errors"
+)
+
+func makeBindSlotKey(t *testing.T) BindSlotKey {
+	mcl, _ := bls.CurveByID(bls.CurveMCL)
+	bls12381, _ := bls.CurveByID(bls.CurveBLS12381)
+	slotKey, _ := mcl.RandSecretKey()
+	boundKey, err := bls12381.RandSecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := BindSlotKey{ValidatorAddress: validatorAddr, Curve: bls.CurveBLS12381}
+	copy(msg.SlotPubKey[:], slotKey.PublicKey())
+	copy(msg.BoundPubKey[:], boundKey.PublicKey())
+	hash := msg.Hash()
+	copy(msg.SlotKeySig[:], slotKey.Sign(hash[:]))
+	copy(msg.BoundKeySig[:], boundKey.Sign(hash[:]))
+	return msg
+}
+
+func TestBindSlotKey_VerifyBinding(t *testing.T) {
+	msg := makeBindSlotKey(t)
+	if err := msg.VerifyBinding(); err != nil {
+		t.Fatal(err)
+	}
+	if cp := msg.Copy().(BindSlotKey); cp != msg {
+		t.Error("copy differs")
+	}
+
+	// the signatures are of the validator
+	other := msg
+	other.ValidatorAddress[0] ^= 1
+	if err := other.VerifyBinding(); errors.Cause(err) != bls.ErrInvalidSignature {
+		t.Errorf("got error %v binding the keys to another validator", err)
+	}
+	other = msg
+	other.BoundKeySig = msg.SlotKeySig
+	if err := other.VerifyBinding(); err == nil {
+		t.Error("binding without the signature of the bound key verified")
+	}
+	other = msg
+	other.Curve = bls.CurveMCL
+	if err := other.VerifyBinding(); err != errBindSameCurve {
+		t.Errorf("got error %v binding a key of the mcl curve", err)
+	}
+	other.Curve = bls.CurveID(9)
+	if err := other.VerifyBinding(); errors.Cause(err) != bls.ErrUnknownCurve {
+		t.Errorf("got error %v binding a key of an unknown curve", err)
+	}
+}
+
+func TestBoundSlotKeyStorage(t *testing.T) {
+	msg := makeBindSlotKey(t)
+	storage := BoundSlotKeyStorage(msg.Curve, msg.SlotPubKey)
+	if storage[0] == storage[1] ||
+		storage == BoundSlotKeyStorage(bls.CurveMCL, msg.SlotPubKey) {
+		t.Errorf("got storage keys %x", storage)
+	}
+	if key, ok := DecodeBoundSlotKey(EncodeBoundSlotKey(msg.BoundPubKey)); !ok || key != msg.BoundPubKey {
+		t.Errorf("bound key changed to %x by its storage", key)
+	}
+	if _, ok := DecodeBoundSlotKey(EncodeBoundSlotKey(shard.BLSPublicKey{})); ok {
+		t.Error("got a bound key of empty storage")
+	}
+}
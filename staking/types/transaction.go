@@ -281,6 +281,8 @@ func RLPDecodeStakeMsg(payload []byte, d Directive) (interface{}, error) {
 			ds = &Undelegate{}
 		case DirectiveCollectRewards:
 			ds = &CollectRewards{}
+		case DirectiveBindSlotKey:
+			ds = &BindSlotKey{}
 		default:
 			return nil, nil
 		}
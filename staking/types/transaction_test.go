@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/harmony-one/bls/ffi/go/bls"
 	common2 "github.com/harmony-one/harmony/internal/common"
 	numeric "github.com/harmony-one/harmony/numeric"
@@ -127,6 +128,21 @@ func TestHash(t *testing.T) {
 	}
 }
 
+func TestSignerPayload(t *testing.T) {
+	stakingTx, err := CreateTestNewTransaction()
+	if err != nil {
+		t.Fatalf("cannot create new staking transaction, %v\n", err)
+	}
+	signer := NewEIP155Signer(big.NewInt(2))
+	payload, err := signer.Payload(stakingTx)
+	if err != nil {
+		t.Fatalf("cannot encode the payload of staking transaction, %v\n", err)
+	}
+	if hash := crypto.Keccak256Hash(payload); hash != signer.Hash(stakingTx) {
+		t.Errorf("payload hash %x does not match the signing hash %x", hash, signer.Hash(stakingTx))
+	}
+}
+
 func TestGasCost(t *testing.T) {
 	stakingTx, err := CreateTestNewTransaction()
 	if err != nil {
@@ -105,6 +105,7 @@ type Computed struct {
 	ToSign            *big.Int    `json:"current-epoch-to-sign"`
 	BlocksLeftInEpoch uint64      `json:"-"`
 	Percentage        numeric.Dec `json:"current-epoch-signing-percentage"`
+	Threshold         numeric.Dec `json:"current-epoch-signing-threshold"`
 	IsBelowThreshold  bool        `json:"-"`
 }
 
@@ -119,7 +120,13 @@ func NewComputed(
 	blocksLeft uint64,
 	percent numeric.Dec,
 	isBelowNow bool) *Computed {
-	return &Computed{signed, toSign, blocksLeft, percent, isBelowNow}
+	return &Computed{
+		Signed:            signed,
+		ToSign:            toSign,
+		BlocksLeftInEpoch: blocksLeft,
+		Percentage:        percent,
+		IsBelowThreshold:  isBelowNow,
+	}
 }
 
 // NewEmptyStats ..
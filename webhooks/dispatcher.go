@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/harmony-one/harmony/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// The events posted by the dispatcher
+const (
+	EventElected      = "elected"
+	EventUnelected    = "unelected"
+	EventJailed       = "jailed"
+	EventMissedBlocks = "missed-blocks"
+	EventViewChange   = "view-change"
+	EventSyncStalled  = "sync-stalled"
+	EventLowDiskSpace = "low-disk-space"
+)
+
+const (
+	dispatchQueueSize = 64
+	dispatchTimeout   = 10 * time.Second
+)
+
+// Event is the JSON body of the posts of the dispatcher
+type Event struct {
+	Type    string      `json:"event"`
+	Time    time.Time   `json:"time"`
+	ShardID uint32      `json:"shard-id"`
+	Data    interface{} `json:"data"`
+}
+
+type delivery struct {
+	url   string
+	event Event
+}
+
+// Dispatcher posts the events of the node to their hooks in the order they
+// are dispatched, without blocking the node on slow or down hooks.
+type Dispatcher struct {
+	shardID uint32
+	client  *http.Client
+	queue   chan delivery
+}
+
+// NewDispatcher returns a dispatcher of the events of the shard, posting them
+// until the process exits.
+func NewDispatcher(shardID uint32) *Dispatcher {
+	d := &Dispatcher{
+		shardID: shardID,
+		client:  &http.Client{Timeout: dispatchTimeout},
+		queue:   make(chan delivery, dispatchQueueSize),
+	}
+	go d.run()
+	return d
+}
+
+// Dispatch queues the event of the type for the hook at url, dropping it if
+// the queue is full. An empty url has no hook.
+func (d *Dispatcher) Dispatch(url, eventType string, data interface{}) {
+	if d == nil || url == "" {
+		return
+	}
+	event := Event{eventType, time.Now().UTC(), d.shardID, data}
+	select {
+	case d.queue <- delivery{url, event}:
+	default:
+		utils.Logger().Warn().
+			Str("event", eventType).
+			Msg("[Webhooks] queue full, event dropped")
+	}
+}
+
+func (d *Dispatcher) run() {
+	for delivery := range d.queue {
+		if err := d.post(delivery.url, delivery.event); err != nil {
+			utils.Logger().Warn().Err(err).
+				Str("event", delivery.event.Type).
+				Msg("[Webhooks] cannot post event")
+		}
+	}
+}
+
+func (d *Dispatcher) post(url string, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("hook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	events := make(chan Event, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := Event{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events <- event
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(1)
+	d.Dispatch("", EventJailed, nil)
+	d.Dispatch(server.URL, EventMissedBlocks, map[string]interface{}{"missed-blocks": 10})
+	d.Dispatch(server.URL, EventSyncStalled, nil)
+	for _, expected := range []string{EventMissedBlocks, EventSyncStalled} {
+		select {
+		case event := <-events:
+			if event.Type != expected || event.ShardID != 1 {
+				t.Errorf("got event %s of shard %d, expected %s", event.Type, event.ShardID, expected)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s not posted", expected)
+		}
+	}
+	var nilDispatcher *Dispatcher
+	nilDispatcher.Dispatch(server.URL, EventJailed, nil)
+}
+
+func TestNewWebHooksFromPathDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "hooks.yaml")
+	yaml := "validator-hooks:\n  on-jailed: http://localhost/jailed\n" +
+		"sync-hooks:\n  on-sync-stalled: http://localhost/stalled\n" +
+		"consensus-hooks:\n  on-view-change: http://localhost/view-change\n"
+	if err := ioutil.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := NewWebHooksFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks.Validator.MissedBlocks != DefaultMissedBlocks || hooks.Sync.StallTimeout != DefaultStallTimeout {
+		t.Errorf("got missed blocks %d, stall timeout %v", hooks.Validator.MissedBlocks, hooks.Sync.StallTimeout)
+	}
+	if _, err := NewWebHooksFromPath("webhook.example.yaml"); err != nil {
+		t.Errorf("example yaml: %v", err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/harmony-one/harmony/internal/utils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -29,13 +31,54 @@ type BadBlockHooks struct {
 	OnCannotCommit string `yaml:"on-cannot-commit-block"`
 }
 
+// DiskHooks ..
+type DiskHooks struct {
+	OnLowDiskSpace string `yaml:"on-low-disk-space"`
+}
+
+// ValidatorHooks are posted the events of the validators of the keys of the
+// node. OnMissedBlocks is posted once the keys missed MissedBlocks blocks in a
+// row.
+type ValidatorHooks struct {
+	OnElected      string `yaml:"on-elected"`
+	OnUnelected    string `yaml:"on-unelected"`
+	OnJailed       string `yaml:"on-jailed"`
+	OnMissedBlocks string `yaml:"on-missed-blocks"`
+	MissedBlocks   uint64 `yaml:"missed-blocks"`
+}
+
+// ConsensusHooks are posted the view changes from or to a key of the node
+type ConsensusHooks struct {
+	OnViewChange string `yaml:"on-view-change"`
+}
+
+// SyncHooks is posted once the chain of the node did not grow for
+// StallTimeout
+type SyncHooks struct {
+	OnSyncStalled string        `yaml:"on-sync-stalled"`
+	StallTimeout  time.Duration `yaml:"stall-timeout"`
+}
+
 // Hooks ..
 type Hooks struct {
 	Slashing       *DoubleSignWebHooks `yaml:"slashing-hooks"`
 	Availability   *AvailabilityHooks  `yaml:"availability-hooks"`
 	ProtocolIssues *BadBlockHooks      `yaml:"protocol-hooks"`
+	Disk           *DiskHooks          `yaml:"disk-hooks"`
+	Validator      *ValidatorHooks     `yaml:"validator-hooks"`
+	Consensus      *ConsensusHooks     `yaml:"consensus-hooks"`
+	Sync           *SyncHooks          `yaml:"sync-hooks"`
 }
 
+const (
+	// DefaultMissedBlocks is the number of blocks missed in a row posted to
+	// the missed blocks hook if the yaml does not set it
+	DefaultMissedBlocks = 10
+	// DefaultStallTimeout is the time without a new block posted to the sync
+	// stalled hook if the yaml does not set it
+	DefaultStallTimeout = 5 * time.Minute
+)
+
 // ReportResult ..
 type ReportResult struct {
 	Result  string `json:"result"`
@@ -84,5 +127,52 @@ func NewWebHooksFromPath(yamlPath string) (*Hooks, error) {
 	if err := yaml.UnmarshalStrict(rawYAML, &t); err != nil {
 		return nil, err
 	}
+	if err := t.expandSecrets(); err != nil {
+		return nil, err
+	}
+	if t.Validator != nil && t.Validator.MissedBlocks == 0 {
+		t.Validator.MissedBlocks = DefaultMissedBlocks
+	}
+	if t.Sync != nil && t.Sync.StallTimeout <= 0 {
+		t.Sync.StallTimeout = DefaultStallTimeout
+	}
 	return &t, nil
 }
+
+// expandSecrets expands the secret references of the hook URLs, such as
+// ${env:SLACK_TOKEN}, so the yaml file can be distributed without them
+func (h *Hooks) expandSecrets() error {
+	urls := []*string{}
+	if h.Slashing != nil {
+		urls = append(urls, &h.Slashing.OnNoticeDoubleSign)
+	}
+	if h.Availability != nil {
+		urls = append(urls, &h.Availability.OnDroppedBelowThreshold)
+	}
+	if h.ProtocolIssues != nil {
+		urls = append(urls, &h.ProtocolIssues.OnCannotCommit)
+	}
+	if h.Disk != nil {
+		urls = append(urls, &h.Disk.OnLowDiskSpace)
+	}
+	if h.Validator != nil {
+		urls = append(urls,
+			&h.Validator.OnElected, &h.Validator.OnUnelected,
+			&h.Validator.OnJailed, &h.Validator.OnMissedBlocks,
+		)
+	}
+	if h.Consensus != nil {
+		urls = append(urls, &h.Consensus.OnViewChange)
+	}
+	if h.Sync != nil {
+		urls = append(urls, &h.Sync.OnSyncStalled)
+	}
+	for _, url := range urls {
+		expanded, err := utils.ExpandSecrets(*url)
+		if err != nil {
+			return err
+		}
+		*url = expanded
+	}
+	return nil
+}